@@ -0,0 +1,61 @@
+package outcomes
+
+// nolint
+// autogenerated code using github.com/haasted/alias-generator.
+// based on functionality in github.com/rigelrozanski/multitool
+
+import (
+	"github.com/warmage-sports/peyote/x/outcomes/internal/keeper"
+	"github.com/warmage-sports/peyote/x/outcomes/internal/types"
+)
+
+const (
+	SuccessResult = types.SuccessResult
+	FailureResult = types.FailureResult
+	PartialResult = types.PartialResult
+
+	ModuleName   = types.ModuleName
+	StoreKey     = types.StoreKey
+	QuerierRoute = types.QuerierRoute
+	RouterKey    = types.RouterKey
+
+	DefaultCodespace = types.DefaultCodespace
+
+	QueryAttestations = keeper.QueryAttestations
+)
+
+var (
+	// functions aliases
+	NewKeeper  = keeper.NewKeeper
+	NewQuerier = keeper.NewQuerier
+
+	RegisterCodec = types.RegisterCodec
+
+	NewAttestation        = types.NewAttestation
+	GetAttestedPercentage = types.GetAttestedPercentage
+	CheckResult           = types.CheckResult
+
+	NewGenesisState     = types.NewGenesisState
+	ValidateGenesis     = types.ValidateGenesis
+	DefaultGenesisState = types.DefaultGenesisState
+
+	NewMsgSubmitAttestation = types.NewMsgSubmitAttestation
+
+	// variable aliases
+	ModuleCdc = types.ModuleCdc
+
+	ErrArgumentCannotBeEmpty        = types.ErrArgumentCannotBeEmpty
+	ErrInvalidAttestationResult     = types.ErrInvalidAttestationResult
+	ErrInvalidAttestationPercentage = types.ErrInvalidAttestationPercentage
+	ErrBondHasNoEvaluators          = types.ErrBondHasNoEvaluators
+	ErrAttestationAlreadySubmitted  = types.ErrAttestationAlreadySubmitted
+)
+
+type (
+	Keeper = keeper.Keeper
+
+	Attestation  = types.Attestation
+	GenesisState = types.GenesisState
+
+	MsgSubmitAttestation = types.MsgSubmitAttestation
+)
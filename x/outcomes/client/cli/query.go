@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/spf13/cobra"
+	"github.com/warmage-sports/peyote/x/outcomes/internal/keeper"
+	"github.com/warmage-sports/peyote/x/outcomes/internal/types"
+)
+
+func GetQueryCmd(storeKey string, cdc *codec.Codec) *cobra.Command {
+	outcomesQueryCmd := &cobra.Command{
+		Use:                        types.ModuleName,
+		Short:                      "Outcomes querying subcommands",
+		DisableFlagParsing:         true,
+		SuggestionsMinimumDistance: 2,
+		RunE:                       client.ValidateCmd,
+	}
+
+	outcomesQueryCmd.AddCommand(flags.GetCommands(
+		GetCmdAttestations(storeKey, cdc),
+	)...)
+
+	return outcomesQueryCmd
+}
+
+func GetCmdAttestations(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "attestations [bond-token]",
+		Short: "Query the attestations of a bond's outcome payments",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			bondToken := args[0]
+
+			res, _, err := cliCtx.QueryWithData(
+				fmt.Sprintf("custom/%s/%s/%s",
+					queryRoute, keeper.QueryAttestations, bondToken), nil)
+			if err != nil {
+				fmt.Printf("%s", err.Error())
+				return nil
+			}
+
+			var out []types.Attestation
+			cdc.MustUnmarshalJSON(res, &out)
+			return cliCtx.PrintOutput(out)
+		},
+	}
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"bufio"
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/cosmos/cosmos-sdk/x/auth/client/utils"
+	"github.com/spf13/cobra"
+	"github.com/warmage-sports/peyote/x/outcomes/internal/types"
+)
+
+func GetTxCmd(cdc *codec.Codec) *cobra.Command {
+	outcomesTxCmd := &cobra.Command{
+		Use:                        types.ModuleName,
+		Short:                      "Outcomes transaction subcommands",
+		DisableFlagParsing:         true,
+		SuggestionsMinimumDistance: 2,
+		RunE:                       client.ValidateCmd,
+	}
+
+	outcomesTxCmd.AddCommand(flags.PostCommands(
+		GetCmdSubmitAttestation(cdc),
+	)...)
+
+	return outcomesTxCmd
+}
+
+func GetCmdSubmitAttestation(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "submit-attestation [bond-token] [result] [percentage]",
+		Example: "submit-attestation abc partial 40",
+		Short:   "Attest the result (success, failure or partial) of a bond's next outcome payment",
+		Args:    cobra.RangeArgs(2, 3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			// The percentage is only specified for partial results
+			percentage := sdk.ZeroDec()
+			if len(args) == 3 {
+				var err error
+				percentage, err = sdk.NewDecFromStr(args[2])
+				if err != nil {
+					return err
+				}
+			}
+
+			msg := types.NewMsgSubmitAttestation(
+				cliCtx.GetFromAddress(), args[0], args[1], percentage)
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	_ = cmd.MarkFlagRequired(flags.FlagFrom)
+	return cmd
+}
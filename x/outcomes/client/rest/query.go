@@ -0,0 +1,34 @@
+package rest
+
+import (
+	"fmt"
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/types/rest"
+	"github.com/gorilla/mux"
+	"github.com/warmage-sports/peyote/x/outcomes/internal/keeper"
+	"net/http"
+)
+
+func registerQueryRoutes(cliCtx context.CLIContext, r *mux.Router, queryRoute string) {
+	r.HandleFunc(
+		fmt.Sprintf("/outcomes/{%s}/attestations", RestBondToken),
+		queryAttestationsHandler(cliCtx, queryRoute),
+	).Methods("GET")
+}
+
+func queryAttestationsHandler(cliCtx context.CLIContext, queryRoute string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		bondToken := vars[RestBondToken]
+
+		res, _, err := cliCtx.QueryWithData(
+			fmt.Sprintf("custom/%s/%s/%s",
+				queryRoute, keeper.QueryAttestations, bondToken), nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
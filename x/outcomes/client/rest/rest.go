@@ -0,0 +1,19 @@
+package rest
+
+import (
+	"github.com/gorilla/mux"
+
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/codec"
+)
+
+// REST variable names
+// noinspection GoNameStartsWithPackageName
+const (
+	RestBondToken = "bond_token"
+)
+
+func RegisterRoutes(cliCtx context.CLIContext, r *mux.Router, cdc *codec.Codec, queryRoute string) {
+	registerQueryRoutes(cliCtx, r, queryRoute)
+	registerTxRoutes(cliCtx, r)
+}
@@ -0,0 +1,28 @@
+package outcomes
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/warmage-sports/peyote/x/outcomes/internal/types"
+)
+
+func InitGenesis(ctx sdk.Context, keeper Keeper, data GenesisState) {
+	// Initialise attestations
+	for _, a := range data.Attestations {
+		keeper.SetAttestation(ctx, a)
+	}
+}
+
+func ExportGenesis(ctx sdk.Context, k Keeper) GenesisState {
+	// Export attestations
+	var attestations []types.Attestation
+	iterator := k.GetAttestationsIterator(ctx)
+	for ; iterator.Valid(); iterator.Next() {
+		attestation := k.MustGetAttestationByKey(ctx, iterator.Key())
+		attestations = append(attestations, attestation)
+	}
+	iterator.Close()
+
+	return GenesisState{
+		Attestations: attestations,
+	}
+}
@@ -0,0 +1,75 @@
+package outcomes
+
+import (
+	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/warmage-sports/peyote/x/outcomes/internal/keeper"
+	"github.com/warmage-sports/peyote/x/outcomes/internal/types"
+	"github.com/warmage-sports/peyote/x/peyote"
+)
+
+func NewHandler(keeper keeper.Keeper) sdk.Handler {
+	return func(ctx sdk.Context, msg sdk.Msg) (*sdk.Result, error) {
+		ctx = ctx.WithEventManager(sdk.NewEventManager())
+		switch msg := msg.(type) {
+		case types.MsgSubmitAttestation:
+			return handleMsgSubmitAttestation(ctx, keeper, msg)
+		default:
+			return nil, sdkerrors.Wrapf(sdkerrors.ErrUnknownRequest, "Unrecognized outcomes Msg type: %v", msg.Type())
+		}
+	}
+}
+
+func handleMsgSubmitAttestation(ctx sdk.Context, keeper keeper.Keeper, msg types.MsgSubmitAttestation) (*sdk.Result, error) {
+
+	bond, found := keeper.BondsKeeper.GetBond(ctx, msg.BondToken)
+	if !found {
+		return nil, sdkerrors.Wrap(peyote.ErrBondDoesNotExist, msg.BondToken)
+	}
+
+	// Confirm that state is OPEN, since outcome payments can only be made
+	// while the bond is open
+	if bond.State != peyote.OpenState {
+		return nil, sdkerrors.Wrap(peyote.ErrInvalidStateForAction, bond.State)
+	}
+
+	// Check that the evaluator is one of the bond's evaluators
+	if !bond.HasEvaluators() {
+		return nil, sdkerrors.Wrap(types.ErrBondHasNoEvaluators, msg.BondToken)
+	} else if !bond.IsEvaluator(msg.Evaluator) {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrUnauthorized,
+			"%s is not an evaluator of %s", msg.Evaluator, msg.BondToken)
+	}
+
+	// The attestation is for the bond's next outcome payment, i.e. the next
+	// outcome tranche, if the bond has outcome tranches, or otherwise the
+	// bond's outcome payment. Attestations are final, so the evaluator cannot
+	// replace an attestation that it already submitted.
+	if keeper.HasAttestation(ctx, msg.BondToken, bond.TranchesPaid, msg.Evaluator) {
+		return nil, sdkerrors.Wrapf(types.ErrAttestationAlreadySubmitted,
+			"%s already attested outcome payment %d of %s",
+			msg.Evaluator, bond.TranchesPaid, msg.BondToken)
+	}
+	attestation := types.NewAttestation(msg.BondToken, bond.TranchesPaid,
+		msg.Evaluator, msg.Result, msg.Percentage, ctx.BlockHeight())
+	keeper.SetAttestation(ctx, attestation)
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			types.EventTypeSubmitAttestation,
+			sdk.NewAttribute(types.AttributeKeyBond, msg.BondToken),
+			sdk.NewAttribute(types.AttributeKeyEvaluator, msg.Evaluator.String()),
+			sdk.NewAttribute(types.AttributeKeyPayment, fmt.Sprint(attestation.Payment)),
+			sdk.NewAttribute(types.AttributeKeyResult, attestation.Result),
+			sdk.NewAttribute(types.AttributeKeyPercentage, attestation.Percentage.String()),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Evaluator.String()),
+		),
+	})
+
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
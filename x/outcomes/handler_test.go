@@ -0,0 +1,122 @@
+package outcomes_test
+
+import (
+	"errors"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/warmage-sports/peyote/x/outcomes"
+	"github.com/warmage-sports/peyote/x/peyote"
+)
+
+func TestInvalidMsgFails(t *testing.T) {
+	_, ctx := createTestApp(false)
+	h := outcomes.NewHandler(outcomes.Keeper{})
+
+	msg := sdk.NewTestMsg()
+	_, err := h(ctx, msg)
+
+	require.Error(t, err)
+}
+
+func TestSubmitAttestationForNonExistentBondFails(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := outcomes.NewHandler(app.OutcomesKeeper)
+
+	_, err := h(ctx, outcomes.NewMsgSubmitAttestation(
+		evaluatorAddress, token, outcomes.SuccessResult, sdk.ZeroDec()))
+	require.Error(t, err)
+	require.True(t, errors.Is(err, peyote.ErrBondDoesNotExist))
+}
+
+func TestSubmitAttestationForBondThatIsNotOpenFails(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := outcomes.NewHandler(app.OutcomesKeeper)
+
+	bond := newOpenBondWithEvaluators(evaluatorAddress)
+	bond.State = peyote.SettleState
+	app.BondsKeeper.SetBond(ctx, token, bond)
+
+	_, err := h(ctx, outcomes.NewMsgSubmitAttestation(
+		evaluatorAddress, token, outcomes.SuccessResult, sdk.ZeroDec()))
+	require.Error(t, err)
+	require.True(t, errors.Is(err, peyote.ErrInvalidStateForAction))
+}
+
+func TestSubmitAttestationByNonEvaluatorFails(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := outcomes.NewHandler(app.OutcomesKeeper)
+
+	// Bond without evaluators
+	app.BondsKeeper.SetBond(ctx, token, newOpenBondWithEvaluators())
+	_, err := h(ctx, outcomes.NewMsgSubmitAttestation(
+		evaluatorAddress, token, outcomes.SuccessResult, sdk.ZeroDec()))
+	require.Error(t, err)
+	require.True(t, errors.Is(err, outcomes.ErrBondHasNoEvaluators))
+
+	// Address that is not one of the bond's evaluators
+	app.BondsKeeper.SetBond(ctx, token, newOpenBondWithEvaluators(evaluatorAddress))
+	_, err = h(ctx, outcomes.NewMsgSubmitAttestation(
+		anotherAddress, token, outcomes.SuccessResult, sdk.ZeroDec()))
+	require.Error(t, err)
+	require.True(t, errors.Is(err, sdkerrors.ErrUnauthorized))
+	require.Len(t, app.OutcomesKeeper.GetAttestations(ctx, token), 0)
+}
+
+func TestSubmitAttestationAttestsNextOutcomePayment(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := outcomes.NewHandler(app.OutcomesKeeper)
+	app.BondsKeeper.SetBond(ctx, token,
+		newOpenBondWithEvaluators(evaluatorAddress, evaluatorAddress2))
+
+	// Attestations of the first outcome payment
+	_, err := h(ctx, outcomes.NewMsgSubmitAttestation(
+		evaluatorAddress, token, outcomes.PartialResult, sdk.NewDec(30)))
+	require.NoError(t, err)
+	_, err = h(ctx, outcomes.NewMsgSubmitAttestation(
+		evaluatorAddress2, token, outcomes.FailureResult, sdk.ZeroDec()))
+	require.NoError(t, err)
+	percentage, found := app.OutcomesKeeper.GetAttestedPercentage(ctx, token, 0)
+	require.True(t, found)
+	require.Equal(t, sdk.NewDec(15), percentage)
+
+	// Attestations are final, so an evaluator cannot change their attestation
+	// of the first outcome payment
+	_, err = h(ctx, outcomes.NewMsgSubmitAttestation(
+		evaluatorAddress2, token, outcomes.SuccessResult, sdk.ZeroDec()))
+	require.Error(t, err)
+	require.True(t, errors.Is(err, outcomes.ErrAttestationAlreadySubmitted))
+	percentage, _ = app.OutcomesKeeper.GetAttestedPercentage(ctx, token, 0)
+	require.Equal(t, sdk.NewDec(15), percentage)
+
+	// Once the first payment has been made, attestations are for the second
+	app.BondsKeeper.SetTranchesPaid(ctx, token, 1)
+	_, err = h(ctx, outcomes.NewMsgSubmitAttestation(
+		evaluatorAddress, token, outcomes.SuccessResult, sdk.ZeroDec()))
+	require.NoError(t, err)
+	_, err = h(ctx, outcomes.NewMsgSubmitAttestation(
+		evaluatorAddress2, token, outcomes.SuccessResult, sdk.ZeroDec()))
+	require.NoError(t, err)
+	percentage, found = app.OutcomesKeeper.GetAttestedPercentage(ctx, token, 1)
+	require.True(t, found)
+	require.Equal(t, sdk.NewDec(100), percentage)
+	require.Len(t, app.OutcomesKeeper.GetAttestations(ctx, token), 4)
+}
+
+func TestExportAndInitGenesis(t *testing.T) {
+	app, ctx := createTestApp(false)
+	app.OutcomesKeeper.SetAttestation(ctx, outcomes.NewAttestation(
+		token, 0, evaluatorAddress, outcomes.PartialResult, sdk.NewDec(40), 1))
+	app.OutcomesKeeper.SetAttestation(ctx, outcomes.NewAttestation(
+		token, 1, evaluatorAddress, outcomes.SuccessResult, sdk.ZeroDec(), 2))
+
+	genesis := outcomes.ExportGenesis(ctx, app.OutcomesKeeper)
+	require.Len(t, genesis.Attestations, 2)
+	require.Nil(t, outcomes.ValidateGenesis(genesis))
+
+	newApp, newCtx := createTestApp(false)
+	outcomes.InitGenesis(newCtx, newApp.OutcomesKeeper, genesis)
+	require.Equal(t, genesis, outcomes.ExportGenesis(newCtx, newApp.OutcomesKeeper))
+}
@@ -0,0 +1,131 @@
+package keeper
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/warmage-sports/peyote/x/outcomes/internal/types"
+)
+
+func (k Keeper) GetAttestationsIterator(ctx sdk.Context) sdk.Iterator {
+	store := ctx.KVStore(k.storeKey)
+	return sdk.KVStorePrefixIterator(store, types.AttestationsKeyPrefix)
+}
+
+func (k Keeper) GetAttestation(ctx sdk.Context, token string, payment uint64,
+	evaluator sdk.AccAddress) (attestation types.Attestation, found bool) {
+	store := ctx.KVStore(k.storeKey)
+	key := types.GetAttestationKey(token, payment, evaluator)
+	if !store.Has(key) {
+		return types.Attestation{}, false
+	}
+
+	bz := store.Get(key)
+	k.cdc.MustUnmarshalBinaryBare(bz, &attestation)
+
+	return attestation, true
+}
+
+// HasAttestation returns true if the evaluator has already attested the
+// bond's outcome payment.
+func (k Keeper) HasAttestation(ctx sdk.Context, token string, payment uint64,
+	evaluator sdk.AccAddress) bool {
+	store := ctx.KVStore(k.storeKey)
+	return store.Has(types.GetAttestationKey(token, payment, evaluator))
+}
+
+func (k Keeper) MustGetAttestationByKey(ctx sdk.Context, key []byte) types.Attestation {
+	store := ctx.KVStore(k.storeKey)
+	if !store.Has(key) {
+		panic("attestation not found")
+	}
+
+	bz := store.Get(key)
+	var attestation types.Attestation
+	k.cdc.MustUnmarshalBinaryBare(bz, &attestation)
+
+	return attestation
+}
+
+// SetAttestation stores the attestation, replacing any attestation that the
+// evaluator already submitted for the same outcome payment. Since attestations
+// are final, evaluators cannot replace their attestations by submitting new
+// ones (see HasAttestation).
+func (k Keeper) SetAttestation(ctx sdk.Context, attestation types.Attestation) {
+	store := ctx.KVStore(k.storeKey)
+	key := types.GetAttestationKey(
+		attestation.BondToken, attestation.Payment, attestation.Evaluator)
+	store.Set(key, k.cdc.MustMarshalBinaryBare(attestation))
+}
+
+// GetAttestations returns the bond's attestations by outcome payment and then
+// by evaluator address.
+func (k Keeper) GetAttestations(ctx sdk.Context, token string) (attestations []types.Attestation) {
+	store := ctx.KVStore(k.storeKey)
+	iterator := sdk.KVStorePrefixIterator(store, types.GetAttestationsPrefixKey(token))
+	defer iterator.Close()
+	for ; iterator.Valid(); iterator.Next() {
+		var attestation types.Attestation
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &attestation)
+		attestations = append(attestations, attestation)
+	}
+	return attestations
+}
+
+// GetPaymentAttestations returns the attestations of one of the bond's outcome
+// payments by evaluator address.
+func (k Keeper) GetPaymentAttestations(ctx sdk.Context, token string,
+	payment uint64) (attestations []types.Attestation) {
+	store := ctx.KVStore(k.storeKey)
+	iterator := sdk.KVStorePrefixIterator(store,
+		types.GetPaymentAttestationsPrefixKey(token, payment))
+	defer iterator.Close()
+	for ; iterator.Valid(); iterator.Next() {
+		var attestation types.Attestation
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &attestation)
+		attestations = append(attestations, attestation)
+	}
+	return attestations
+}
+
+// RemoveAttestations removes all of the bond's attestations, so that none of
+// them carry over to a new bond that reuses the bond's token.
+func (k Keeper) RemoveAttestations(ctx sdk.Context, token string) {
+	store := ctx.KVStore(k.storeKey)
+
+	var keys [][]byte
+	iterator := sdk.KVStorePrefixIterator(store, types.GetAttestationsPrefixKey(token))
+	for ; iterator.Valid(); iterator.Next() {
+		keys = append(keys, iterator.Key())
+	}
+	iterator.Close()
+
+	for _, key := range keys {
+		store.Delete(key)
+	}
+}
+
+// GetAttestedPercentage returns the percentage of one of the bond's outcome
+// payments that has been attested by the bond's evaluators, i.e. the average
+// of the percentages of the payment's attestations. Only the attestations of
+// the bond's current evaluators are counted. If the payment has not been
+// attested by a quorum of the evaluators (see types.GetQuorum), or the bond
+// does not exist, found is false.
+func (k Keeper) GetAttestedPercentage(ctx sdk.Context, token string,
+	payment uint64) (percentage sdk.Dec, found bool) {
+	bond, found := k.BondsKeeper.GetBond(ctx, token)
+	if !found {
+		return sdk.Dec{}, false
+	}
+
+	total := sdk.ZeroDec()
+	var count int64
+	for _, a := range k.GetPaymentAttestations(ctx, token, payment) {
+		if bond.IsEvaluator(a.Evaluator) {
+			total = total.Add(a.Percentage)
+			count++
+		}
+	}
+	if count < int64(types.GetQuorum(len(bond.Evaluators))) {
+		return sdk.Dec{}, false
+	}
+	return total.QuoInt64(count), true
+}
@@ -0,0 +1,119 @@
+package keeper_test
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+	"github.com/warmage-sports/peyote/x/outcomes/internal/types"
+)
+
+func TestSetAttestationReplacesEvaluatorsAttestation(t *testing.T) {
+	app, ctx := createTestApp(false)
+
+	app.OutcomesKeeper.SetAttestation(ctx, types.NewAttestation(
+		token, 0, evaluatorAddress, types.FailureResult, sdk.ZeroDec(), 1))
+	app.OutcomesKeeper.SetAttestation(ctx, types.NewAttestation(
+		token, 0, evaluatorAddress, types.SuccessResult, sdk.ZeroDec(), 2))
+
+	attestation, found := app.OutcomesKeeper.GetAttestation(ctx, token, 0, evaluatorAddress)
+	require.True(t, found)
+	require.Equal(t, types.SuccessResult, attestation.Result)
+	require.Equal(t, int64(2), attestation.Height)
+	require.Len(t, app.OutcomesKeeper.GetAttestations(ctx, token), 1)
+
+	_, found = app.OutcomesKeeper.GetAttestation(ctx, token, 1, evaluatorAddress)
+	require.False(t, found)
+}
+
+func TestGetAttestationsByPayment(t *testing.T) {
+	app, ctx := createTestApp(false)
+
+	app.OutcomesKeeper.SetAttestation(ctx, types.NewAttestation(
+		token, 1, evaluatorAddress, types.SuccessResult, sdk.ZeroDec(), 1))
+	app.OutcomesKeeper.SetAttestation(ctx, types.NewAttestation(
+		token, 0, evaluatorAddress, types.FailureResult, sdk.ZeroDec(), 1))
+	app.OutcomesKeeper.SetAttestation(ctx, types.NewAttestation(
+		token2, 0, evaluatorAddress, types.SuccessResult, sdk.ZeroDec(), 1))
+
+	// Attestations are returned by payment and exclude other bonds
+	attestations := app.OutcomesKeeper.GetAttestations(ctx, token)
+	require.Len(t, attestations, 2)
+	require.Equal(t, uint64(0), attestations[0].Payment)
+	require.Equal(t, uint64(1), attestations[1].Payment)
+
+	require.Len(t, app.OutcomesKeeper.GetPaymentAttestations(ctx, token, 1), 1)
+	require.Len(t, app.OutcomesKeeper.GetPaymentAttestations(ctx, token2, 1), 0)
+}
+
+func TestHasAttestation(t *testing.T) {
+	app, ctx := createTestApp(false)
+
+	require.False(t, app.OutcomesKeeper.HasAttestation(ctx, token, 0, evaluatorAddress))
+
+	app.OutcomesKeeper.SetAttestation(ctx, types.NewAttestation(
+		token, 0, evaluatorAddress, types.SuccessResult, sdk.ZeroDec(), 1))
+	require.True(t, app.OutcomesKeeper.HasAttestation(ctx, token, 0, evaluatorAddress))
+	require.False(t, app.OutcomesKeeper.HasAttestation(ctx, token, 1, evaluatorAddress))
+	require.False(t, app.OutcomesKeeper.HasAttestation(ctx, token, 0, evaluatorAddress2))
+	require.False(t, app.OutcomesKeeper.HasAttestation(ctx, token2, 0, evaluatorAddress))
+}
+
+func TestRemoveAttestations(t *testing.T) {
+	app, ctx := createTestApp(false)
+
+	app.OutcomesKeeper.SetAttestation(ctx, types.NewAttestation(
+		token, 0, evaluatorAddress, types.SuccessResult, sdk.ZeroDec(), 1))
+	app.OutcomesKeeper.SetAttestation(ctx, types.NewAttestation(
+		token, 1, evaluatorAddress2, types.FailureResult, sdk.ZeroDec(), 1))
+	app.OutcomesKeeper.SetAttestation(ctx, types.NewAttestation(
+		token2, 0, evaluatorAddress, types.SuccessResult, sdk.ZeroDec(), 1))
+
+	// Only the bond's attestations are removed
+	app.OutcomesKeeper.RemoveAttestations(ctx, token)
+	require.Len(t, app.OutcomesKeeper.GetAttestations(ctx, token), 0)
+	require.Len(t, app.OutcomesKeeper.GetAttestations(ctx, token2), 1)
+}
+
+func TestGetAttestedPercentageIsAverageOfAttestations(t *testing.T) {
+	app, ctx := createTestApp(false)
+	app.BondsKeeper.SetBond(ctx, token, newBondWithEvaluators(
+		evaluatorAddress, evaluatorAddress2))
+
+	// Payment that has not been attested
+	_, found := app.OutcomesKeeper.GetAttestedPercentage(ctx, token, 0)
+	require.False(t, found)
+
+	// A single attestation is not a majority of the two evaluators
+	app.OutcomesKeeper.SetAttestation(ctx, types.NewAttestation(
+		token, 0, evaluatorAddress, types.PartialResult, sdk.NewDec(25), 1))
+	_, found = app.OutcomesKeeper.GetAttestedPercentage(ctx, token, 0)
+	require.False(t, found)
+
+	app.OutcomesKeeper.SetAttestation(ctx, types.NewAttestation(
+		token, 0, evaluatorAddress2, types.SuccessResult, sdk.ZeroDec(), 1))
+	percentage, found := app.OutcomesKeeper.GetAttestedPercentage(ctx, token, 0)
+	require.True(t, found)
+	require.Equal(t, sdk.MustNewDecFromStr("62.5"), percentage)
+}
+
+func TestGetAttestedPercentageIgnoresNonEvaluators(t *testing.T) {
+	app, ctx := createTestApp(false)
+	app.BondsKeeper.SetBond(ctx, token, newBondWithEvaluators(evaluatorAddress))
+
+	// Attestation by an account that is no longer an evaluator is ignored
+	app.OutcomesKeeper.SetAttestation(ctx, types.NewAttestation(
+		token, 0, evaluatorAddress2, types.SuccessResult, sdk.ZeroDec(), 1))
+	_, found := app.OutcomesKeeper.GetAttestedPercentage(ctx, token, 0)
+	require.False(t, found)
+
+	app.OutcomesKeeper.SetAttestation(ctx, types.NewAttestation(
+		token, 0, evaluatorAddress, types.FailureResult, sdk.ZeroDec(), 1))
+	percentage, found := app.OutcomesKeeper.GetAttestedPercentage(ctx, token, 0)
+	require.True(t, found)
+	require.Equal(t, sdk.ZeroDec(), percentage)
+
+	// Bond that does not exist has no attested percentage
+	_, found = app.OutcomesKeeper.GetAttestedPercentage(ctx, token2, 0)
+	require.False(t, found)
+}
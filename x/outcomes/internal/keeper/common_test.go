@@ -0,0 +1,60 @@
+package keeper_test
+
+import (
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/libs/log"
+	dbm "github.com/tendermint/tm-db"
+	"github.com/warmage-sports/peyote/x/peyote"
+	"github.com/warmage-sports/peyote/x/peyote/app"
+)
+
+var (
+	token  = "testtoken"
+	token2 = "testtoken2"
+
+	evaluatorAddress  = sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	evaluatorAddress2 = sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+)
+
+func Setup(isCheckTx bool) *simapp.SimApp {
+	db := dbm.NewMemDB()
+	app := simapp.NewSimApp(log.NewNopLogger(), db, nil, true, map[int64]bool{}, 0)
+	cdc := simapp.MakeCodec()
+	if !isCheckTx {
+		// init chain must be called to stop deliverState from being nil
+		genesisState := simapp.NewDefaultGenesisState()
+		stateBytes, err := codec.MarshalJSONIndent(cdc, genesisState)
+		if err != nil {
+			panic(err)
+		}
+
+		// Initialize the chain
+		app.InitChain(
+			abci.RequestInitChain{
+				Validators:    []abci.ValidatorUpdate{},
+				AppStateBytes: stateBytes,
+			},
+		)
+	}
+
+	return app
+}
+
+func createTestApp(isCheckTx bool) (*simapp.SimApp, sdk.Context) {
+	app := Setup(isCheckTx)
+
+	ctx := app.BaseApp.NewContext(isCheckTx, abci.Header{})
+
+	return app, ctx
+}
+
+func newBondWithEvaluators(evaluators ...sdk.AccAddress) peyote.Bond {
+	return peyote.Bond{
+		Token:      token,
+		Evaluators: evaluators,
+		State:      peyote.OpenState,
+	}
+}
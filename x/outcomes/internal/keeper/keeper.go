@@ -0,0 +1,24 @@
+package keeper
+
+import (
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/warmage-sports/peyote/x/outcomes/internal/types"
+)
+
+type Keeper struct {
+	BondsKeeper types.BondsKeeper
+
+	storeKey sdk.StoreKey
+
+	cdc *codec.Codec
+}
+
+func NewKeeper(bondsKeeper types.BondsKeeper, storeKey sdk.StoreKey,
+	cdc *codec.Codec) Keeper {
+	return Keeper{
+		BondsKeeper: bondsKeeper,
+		storeKey:    storeKey,
+		cdc:         cdc,
+	}
+}
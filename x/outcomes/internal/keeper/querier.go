@@ -0,0 +1,41 @@
+package keeper
+
+import (
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/warmage-sports/peyote/x/outcomes/internal/types"
+)
+
+const (
+	QueryAttestations = "attestations"
+)
+
+// NewQuerier is the module level router for state queries
+func NewQuerier(keeper Keeper) sdk.Querier {
+	return func(ctx sdk.Context, path []string, req abci.RequestQuery) (res []byte, err error) {
+		switch path[0] {
+		case QueryAttestations:
+			return queryAttestations(ctx, path[1:], keeper)
+		default:
+			return nil, sdkerrors.Wrap(sdkerrors.ErrUnknownRequest, "unknown outcomes query endpoint")
+		}
+	}
+}
+
+func queryAttestations(ctx sdk.Context, path []string, keeper Keeper) (res []byte, err error) {
+	bondToken := path[0]
+
+	attestations := keeper.GetAttestations(ctx, bondToken)
+	if attestations == nil {
+		attestations = []types.Attestation{}
+	}
+
+	bz, err2 := codec.MarshalJSONIndent(keeper.cdc, attestations)
+	if err2 != nil {
+		panic("could not marshal result to JSON")
+	}
+
+	return bz, nil
+}
@@ -0,0 +1,118 @@
+package types
+
+import (
+	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+)
+
+const (
+	SuccessResult = "success"
+	FailureResult = "failure"
+	PartialResult = "partial"
+)
+
+var (
+	maxPercentage = sdk.NewDec(100)
+)
+
+// Attestation is an evaluator's attestation of the result of one of a bond's
+// outcome payments, i.e. whether the outcome that the payment is for was
+// achieved in full (success), not at all (failure), or in part (partial). The
+// percentage is the percentage of the outcome payment that the attestation
+// entitles the bond to: 100 for a success, 0 for a failure, or the attested
+// percentage for a partial result.
+type Attestation struct {
+	BondToken  string         `json:"bond_token" yaml:"bond_token"`
+	Payment    uint64         `json:"payment" yaml:"payment"`
+	Evaluator  sdk.AccAddress `json:"evaluator" yaml:"evaluator"`
+	Result     string         `json:"result" yaml:"result"`
+	Percentage sdk.Dec        `json:"percentage" yaml:"percentage"`
+	Height     int64          `json:"height" yaml:"height"`
+}
+
+func NewAttestation(bondToken string, payment uint64, evaluator sdk.AccAddress,
+	result string, percentage sdk.Dec, height int64) Attestation {
+	return Attestation{
+		BondToken:  bondToken,
+		Payment:    payment,
+		Evaluator:  evaluator,
+		Result:     result,
+		Percentage: GetAttestedPercentage(result, percentage),
+		Height:     height,
+	}
+}
+
+// GetQuorum returns the number of a bond's evaluators that have to attest an
+// outcome payment before it can be made, i.e. a strict majority of them.
+func GetQuorum(evaluators int) int {
+	return evaluators/2 + 1
+}
+
+// GetAttestedPercentage returns the percentage of the outcome payment that a
+// result entitles the bond to. The percentage is only used for partial results.
+func GetAttestedPercentage(result string, percentage sdk.Dec) sdk.Dec {
+	switch result {
+	case SuccessResult:
+		return maxPercentage
+	case PartialResult:
+		return percentage
+	default:
+		return sdk.ZeroDec()
+	}
+}
+
+// CheckResult checks that the result is a valid result and that the
+// percentage is valid for the result. A partial result requires a percentage
+// strictly between 0 and 100, whereas the other results take no percentage.
+func CheckResult(result string, percentage sdk.Dec) error {
+	switch result {
+	case SuccessResult, FailureResult:
+		if !percentage.IsNil() && !percentage.IsZero() {
+			return sdkerrors.Wrapf(ErrInvalidAttestationPercentage,
+				"a %s result does not take a percentage", result)
+		}
+	case PartialResult:
+		if percentage.IsNil() || !percentage.IsPositive() || percentage.GTE(maxPercentage) {
+			return sdkerrors.Wrapf(ErrInvalidAttestationPercentage,
+				"a %s result requires a percentage between 0 and 100 (exclusive)", result)
+		}
+	default:
+		return sdkerrors.Wrapf(ErrInvalidAttestationResult,
+			"%s is not one of %s, %s, %s", result, SuccessResult, FailureResult, PartialResult)
+	}
+	return nil
+}
+
+// Validate checks that the attestation is a valid stored attestation, i.e.
+// that its percentage is the percentage that its result entitles the bond to.
+func (a Attestation) Validate() error {
+	if a.Evaluator.Empty() {
+		return sdkerrors.Wrap(ErrArgumentCannotBeEmpty, "Evaluator")
+	} else if a.Percentage.IsNil() {
+		return sdkerrors.Wrap(ErrArgumentCannotBeEmpty, "Percentage")
+	} else if err := sdk.ValidateDenom(a.BondToken); err != nil {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidCoins, err.Error())
+	}
+
+	if a.Result == PartialResult {
+		return CheckResult(a.Result, a.Percentage)
+	} else if err := CheckResult(a.Result, sdk.ZeroDec()); err != nil {
+		return err
+	} else if expected := GetAttestedPercentage(a.Result, a.Percentage); !a.Percentage.Equal(expected) {
+		return sdkerrors.Wrapf(ErrInvalidAttestationPercentage,
+			"a %s result must have a percentage of %s", a.Result, expected)
+	}
+	return nil
+}
+
+func (a Attestation) String() string {
+	return fmt.Sprintf(`Attestation:
+  Bond Token: %s
+  Payment:    %d
+  Evaluator:  %s
+  Result:     %s
+  Percentage: %s
+  Height:     %d
+`, a.BondToken, a.Payment, a.Evaluator, a.Result, a.Percentage, a.Height)
+}
@@ -0,0 +1,41 @@
+package types
+
+import (
+	"errors"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestNewAttestationSetsPercentageOfResult(t *testing.T) {
+	testCases := []struct {
+		result     string
+		percentage sdk.Dec
+		expected   sdk.Dec
+	}{
+		{SuccessResult, sdk.ZeroDec(), sdk.NewDec(100)},
+		{FailureResult, sdk.ZeroDec(), sdk.ZeroDec()},
+		{PartialResult, sdk.NewDec(40), sdk.NewDec(40)},
+	}
+	for _, tc := range testCases {
+		attestation := NewAttestation(token, 0, evaluator, tc.result, tc.percentage, 1)
+		require.Equal(t, tc.expected, attestation.Percentage)
+		require.Nil(t, attestation.Validate())
+	}
+}
+
+func TestAttestationValidateChecksPercentageOfResult(t *testing.T) {
+	attestation := NewAttestation(token, 0, evaluator, SuccessResult, sdk.ZeroDec(), 1)
+
+	// A success must entitle the bond to the entire payment
+	attestation.Percentage = sdk.NewDec(50)
+	err := attestation.Validate()
+	require.NotNil(t, err)
+	require.True(t, errors.Is(err, ErrInvalidAttestationPercentage))
+
+	// A stored attestation must have a percentage
+	attestation.Percentage = sdk.Dec{}
+	err = attestation.Validate()
+	require.NotNil(t, err)
+	require.True(t, errors.Is(err, ErrArgumentCannotBeEmpty))
+}
@@ -0,0 +1,19 @@
+package types
+
+import (
+	"github.com/cosmos/cosmos-sdk/codec"
+)
+
+// ModuleCdc is the codec for the module
+var ModuleCdc *codec.Codec
+
+func init() {
+	ModuleCdc = codec.New()
+	RegisterCodec(ModuleCdc)
+	ModuleCdc.Seal()
+}
+
+func RegisterCodec(cdc *codec.Codec) {
+	cdc.RegisterConcrete(&Attestation{}, "outcomes/Attestation", nil)
+	cdc.RegisterConcrete(MsgSubmitAttestation{}, "outcomes/MsgSubmitAttestation", nil)
+}
@@ -0,0 +1,17 @@
+package types
+
+import (
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+)
+
+const (
+	DefaultCodespace = ModuleName
+)
+
+var (
+	ErrArgumentCannotBeEmpty        = sdkerrors.Register(ModuleName, 301, "argument cannot be empty")
+	ErrInvalidAttestationResult     = sdkerrors.Register(ModuleName, 302, "invalid attestation result")
+	ErrInvalidAttestationPercentage = sdkerrors.Register(ModuleName, 303, "invalid attestation percentage")
+	ErrBondHasNoEvaluators          = sdkerrors.Register(ModuleName, 304, "bond does not have evaluators")
+	ErrAttestationAlreadySubmitted  = sdkerrors.Register(ModuleName, 305, "attestation already submitted")
+)
@@ -0,0 +1,13 @@
+package types
+
+const (
+	EventTypeSubmitAttestation = "submit_attestation"
+
+	AttributeKeyBond       = "bond"
+	AttributeKeyEvaluator  = "evaluator"
+	AttributeKeyPayment    = "payment"
+	AttributeKeyResult     = "result"
+	AttributeKeyPercentage = "percentage"
+
+	AttributeValueCategory = ModuleName
+)
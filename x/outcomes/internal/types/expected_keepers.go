@@ -0,0 +1,12 @@
+package types
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/warmage-sports/peyote/x/peyote"
+)
+
+// BondsKeeper defines the expected bonds keeper, which holds the bonds whose
+// outcome payments are attested.
+type BondsKeeper interface {
+	GetBond(ctx sdk.Context, token string) (bond peyote.Bond, found bool)
+}
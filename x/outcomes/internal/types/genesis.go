@@ -0,0 +1,26 @@
+package types
+
+type GenesisState struct {
+	Attestations []Attestation `json:"attestations" yaml:"attestations"`
+}
+
+func NewGenesisState(attestations []Attestation) GenesisState {
+	return GenesisState{
+		Attestations: attestations,
+	}
+}
+
+func ValidateGenesis(data GenesisState) error {
+	for _, a := range data.Attestations {
+		if err := a.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func DefaultGenesisState() GenesisState {
+	return GenesisState{
+		Attestations: nil,
+	}
+}
@@ -0,0 +1,43 @@
+package types
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	// ModuleName is the name of this module
+	ModuleName = "outcomes"
+
+	// StoreKey is the default store key for this module
+	StoreKey = ModuleName
+
+	// QuerierRoute is the querier route for this module's store.
+	QuerierRoute = ModuleName
+
+	// RouterKey is the message route for this module
+	RouterKey = ModuleName
+)
+
+// Attestations are stored as follow:
+//
+// - Attestations: 0x00<bond_token_bytes>0x00<payment_bytes><evaluator_address_bytes>
+var (
+	AttestationsKeyPrefix = []byte{0x00} // key for attestations
+)
+
+// GetAttestationsPrefixKey returns the prefix of the keys of all of the
+// bond's attestations.
+func GetAttestationsPrefixKey(token string) []byte {
+	key := append(AttestationsKeyPrefix, []byte(token)...)
+	return append(key, 0x00)
+}
+
+// GetPaymentAttestationsPrefixKey returns the prefix of the keys of the
+// attestations of one of the bond's outcome payments.
+func GetPaymentAttestationsPrefixKey(token string, payment uint64) []byte {
+	return append(GetAttestationsPrefixKey(token), sdk.Uint64ToBigEndian(payment)...)
+}
+
+func GetAttestationKey(token string, payment uint64, evaluator sdk.AccAddress) []byte {
+	return append(GetPaymentAttestationsPrefixKey(token, payment), evaluator.Bytes()...)
+}
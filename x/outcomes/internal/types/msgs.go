@@ -0,0 +1,63 @@
+package types
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"strings"
+)
+
+const (
+	TypeMsgSubmitAttestation = "submit_attestation"
+)
+
+// MsgSubmitAttestation submits an evaluator's attestation of the result of the
+// bond's next outcome payment. The percentage is only specified for partial
+// results. An evaluator's attestation replaces any attestation that it already
+// submitted for the same outcome payment.
+type MsgSubmitAttestation struct {
+	Evaluator  sdk.AccAddress `json:"evaluator" yaml:"evaluator"`
+	BondToken  string         `json:"bond_token" yaml:"bond_token"`
+	Result     string         `json:"result" yaml:"result"`
+	Percentage sdk.Dec        `json:"percentage" yaml:"percentage"`
+}
+
+func NewMsgSubmitAttestation(evaluator sdk.AccAddress, bondToken, result string,
+	percentage sdk.Dec) MsgSubmitAttestation {
+	return MsgSubmitAttestation{
+		Evaluator:  evaluator,
+		BondToken:  bondToken,
+		Result:     result,
+		Percentage: percentage,
+	}
+}
+
+func (msg MsgSubmitAttestation) ValidateBasic() error {
+	// Check if empty
+	if msg.Evaluator.Empty() {
+		return sdkerrors.Wrap(ErrArgumentCannotBeEmpty, "Evaluator")
+	} else if strings.TrimSpace(msg.BondToken) == "" {
+		return sdkerrors.Wrap(ErrArgumentCannotBeEmpty, "BondToken")
+	} else if strings.TrimSpace(msg.Result) == "" {
+		return sdkerrors.Wrap(ErrArgumentCannotBeEmpty, "Result")
+	}
+
+	// Validate bond token
+	if err := sdk.ValidateDenom(msg.BondToken); err != nil {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidCoins, err.Error())
+	}
+
+	// Validate result and percentage
+	return CheckResult(msg.Result, msg.Percentage)
+}
+
+func (msg MsgSubmitAttestation) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+func (msg MsgSubmitAttestation) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Evaluator}
+}
+
+func (msg MsgSubmitAttestation) Route() string { return RouterKey }
+
+func (msg MsgSubmitAttestation) Type() string { return TypeMsgSubmitAttestation }
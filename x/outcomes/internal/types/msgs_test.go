@@ -0,0 +1,94 @@
+package types
+
+import (
+	"errors"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"testing"
+)
+
+var (
+	token     = "testtoken"
+	evaluator = sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+)
+
+// MsgSubmitAttestation: Missing arguments
+
+func TestValidateBasicMsgSubmitAttestationEvaluatorMissingGivesError(t *testing.T) {
+	message := NewMsgSubmitAttestation(nil, token, SuccessResult, sdk.ZeroDec())
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+	require.True(t, errors.Is(err, ErrArgumentCannotBeEmpty))
+}
+
+func TestValidateBasicMsgSubmitAttestationBondTokenMissingGivesError(t *testing.T) {
+	message := NewMsgSubmitAttestation(evaluator, "", SuccessResult, sdk.ZeroDec())
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+	require.True(t, errors.Is(err, ErrArgumentCannotBeEmpty))
+}
+
+func TestValidateBasicMsgSubmitAttestationResultMissingGivesError(t *testing.T) {
+	message := NewMsgSubmitAttestation(evaluator, token, "", sdk.ZeroDec())
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+	require.True(t, errors.Is(err, ErrArgumentCannotBeEmpty))
+}
+
+// MsgSubmitAttestation: Invalid arguments
+
+func TestValidateBasicMsgSubmitAttestationInvalidBondTokenGivesError(t *testing.T) {
+	message := NewMsgSubmitAttestation(evaluator, "123abc", SuccessResult, sdk.ZeroDec())
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+func TestValidateBasicMsgSubmitAttestationInvalidResultGivesError(t *testing.T) {
+	message := NewMsgSubmitAttestation(evaluator, token, "succeeded", sdk.ZeroDec())
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+	require.True(t, errors.Is(err, ErrInvalidAttestationResult))
+}
+
+func TestValidateBasicMsgSubmitAttestationSuccessWithPercentageGivesError(t *testing.T) {
+	message := NewMsgSubmitAttestation(evaluator, token, SuccessResult, sdk.NewDec(50))
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+	require.True(t, errors.Is(err, ErrInvalidAttestationPercentage))
+}
+
+func TestValidateBasicMsgSubmitAttestationPartialWithoutPercentageGivesError(t *testing.T) {
+	message := NewMsgSubmitAttestation(evaluator, token, PartialResult, sdk.ZeroDec())
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+	require.True(t, errors.Is(err, ErrInvalidAttestationPercentage))
+}
+
+func TestValidateBasicMsgSubmitAttestationPartialWith100PercentGivesError(t *testing.T) {
+	message := NewMsgSubmitAttestation(evaluator, token, PartialResult, sdk.NewDec(100))
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+	require.True(t, errors.Is(err, ErrInvalidAttestationPercentage))
+}
+
+// MsgSubmitAttestation: Valid arguments
+
+func TestValidateBasicMsgSubmitAttestationCorrectlyGivesNoError(t *testing.T) {
+	for _, message := range []MsgSubmitAttestation{
+		NewMsgSubmitAttestation(evaluator, token, SuccessResult, sdk.ZeroDec()),
+		NewMsgSubmitAttestation(evaluator, token, FailureResult, sdk.Dec{}),
+		NewMsgSubmitAttestation(evaluator, token, PartialResult, sdk.MustNewDecFromStr("99.5")),
+	} {
+		err := message.ValidateBasic()
+		require.Nil(t, err)
+	}
+}
@@ -15,10 +15,19 @@ const (
 	SigmoidFunction   = types.SigmoidFunction
 	SwapperFunction   = types.SwapperFunction
 	AugmentedFunction = types.AugmentedFunction
+	BancorFunction    = types.BancorFunction
 
-	HatchState  = types.HatchState
-	OpenState   = types.OpenState
-	SettleState = types.SettleState
+	HatchState      = types.HatchState
+	OpenState       = types.OpenState
+	SettleState     = types.SettleState
+	QuarantineState = types.QuarantineState
+	ClosedState     = types.ClosedState
+
+	LimitBuyOrderType  = types.LimitBuyOrderType
+	LimitSellOrderType = types.LimitSellOrderType
+
+	CommitPhase = types.CommitPhase
+	RevealPhase = types.RevealPhase
 
 	DoNotModifyField = types.DoNotModifyField
 
@@ -41,8 +50,9 @@ const (
 var (
 	// functions aliases
 
-	NewQuerier = keeper.NewQuerier
-	NewKeeper  = keeper.NewKeeper
+	NewQuerier                  = keeper.NewQuerier
+	NewKeeper                   = keeper.NewKeeper
+	NewHolderTrackingBankKeeper = keeper.NewHolderTrackingBankKeeper
 
 	RegisterInvariants = keeper.RegisterInvariants
 	AllInvariants      = keeper.AllInvariants
@@ -51,13 +61,32 @@ var (
 
 	RegisterCodec = types.RegisterCodec
 
-	NewBatch         = types.NewBatch
-	NewBaseOrder     = types.NewBaseOrder
-	NewBuyOrder      = types.NewBuyOrder
-	NewSellOrder     = types.NewSellOrder
-	NewSwapOrder     = types.NewSwapOrder
-	NewFunctionParam = types.NewFunctionParam
-	NewBond          = types.NewBond
+	NewBatch            = types.NewBatch
+	NewBatchRecord      = types.NewBatchRecord
+	NewRolledOverOrders = types.NewRolledOverOrders
+	NewBaseOrder        = types.NewBaseOrder
+	NewBuyOrder         = types.NewBuyOrder
+	NewSpendBuyOrder    = types.NewSpendBuyOrder
+	NewSellOrder        = types.NewSellOrder
+	NewSwapOrder        = types.NewSwapOrder
+	NewFunctionParam    = types.NewFunctionParam
+	NewBond             = types.NewBond
+	NewLimitOrder       = types.NewLimitOrder
+	NewRecurringOrder   = types.NewRecurringOrder
+
+	NewSettlementSnapshot  = types.NewSettlementSnapshot
+	NewHolderSnapshot      = types.NewHolderSnapshot
+	NewShareDistribution   = types.NewShareDistribution
+	NewTrancheDistribution = types.NewTrancheDistribution
+	NewOutcomeTranche      = types.NewOutcomeTranche
+
+	NewOrderCommitment = types.NewOrderCommitment
+	NewRevealedOrder   = types.NewRevealedOrder
+
+	RegisterFunctionType = types.RegisterFunctionType
+	GetFunction          = types.GetFunction
+	MustGetFunction      = types.MustGetFunction
+	FunctionTypes        = types.FunctionTypes
 
 	RoundReservePrice     = types.RoundReservePrice
 	RoundReserveReturn    = types.RoundReserveReturn
@@ -76,17 +105,37 @@ var (
 	ValidateGenesis     = types.ValidateGenesis
 	DefaultGenesisState = types.DefaultGenesisState
 
-	GetBondKey      = types.GetBondKey
-	GetBatchKey     = types.GetBatchKey
-	GetLastBatchKey = types.GetLastBatchKey
+	GetBondKey                   = types.GetBondKey
+	GetBatchKey                  = types.GetBatchKey
+	GetLastBatchKey              = types.GetLastBatchKey
+	GetLimitOrderKey             = types.GetLimitOrderKey
+	GetLimitOrderBookPrefixKey   = types.GetLimitOrderBookPrefixKey
+	GetLimitOrderBookKey         = types.GetLimitOrderBookKey
+	GetLimitOrderExpiryPrefixKey = types.GetLimitOrderExpiryPrefixKey
+	GetLimitOrderExpiryKey       = types.GetLimitOrderExpiryKey
+	GetRecurringOrderKey         = types.GetRecurringOrderKey
+	GetSettlementSnapshotKey     = types.GetSettlementSnapshotKey
+	GetHolderSnapshotKey         = types.GetHolderSnapshotKey
+	GetShareDistributionKey      = types.GetShareDistributionKey
+	GetTrancheDistributionKey    = types.GetTrancheDistributionKey
+	GetClaimDeadlineKey          = types.GetClaimDeadlineKey
 
-	NewMsgCreateBond         = types.NewMsgCreateBond
-	NewMsgEditBond           = types.NewMsgEditBond
-	NewMsgBuy                = types.NewMsgBuy
-	NewMsgSell               = types.NewMsgSell
-	NewMsgSwap               = types.NewMsgSwap
-	NewMsgMakeOutcomePayment = types.NewMsgMakeOutcomePayment
-	NewMsgWithdrawShare      = types.NewMsgWithdrawShare
+	NewMsgCreateBond           = types.NewMsgCreateBond
+	NewMsgEditBond             = types.NewMsgEditBond
+	NewMsgBuy                  = types.NewMsgBuy
+	NewMsgSell                 = types.NewMsgSell
+	NewMsgSwap                 = types.NewMsgSwap
+	NewMsgMakeOutcomePayment   = types.NewMsgMakeOutcomePayment
+	NewMsgWithdrawShare        = types.NewMsgWithdrawShare
+	NewMsgSettleBond           = types.NewMsgSettleBond
+	NewMsgLimitBuy             = types.NewMsgLimitBuy
+	NewMsgLimitSell            = types.NewMsgLimitSell
+	NewMsgSpendBuy             = types.NewMsgSpendBuy
+	NewMsgCancelOrder          = types.NewMsgCancelOrder
+	NewMsgCommitOrder          = types.NewMsgCommitOrder
+	NewMsgRevealOrder          = types.NewMsgRevealOrder
+	NewMsgCreateRecurringOrder = types.NewMsgCreateRecurringOrder
+	NewMsgCancelRecurringOrder = types.NewMsgCancelRecurringOrder
 
 	ParseFunctionParams = client.ParseFunctionParams
 	ParseSigners        = client.ParseSigners
@@ -96,10 +145,6 @@ var (
 
 	ModuleCdc = types.ModuleCdc
 
-	RequiredParamsForFunctionType    = types.RequiredParamsForFunctionType
-	NoOfReserveTokensForFunctionType = types.NoOfReserveTokensForFunctionType
-	ExtraParameterRestrictions       = types.ExtraParameterRestrictions
-
 	ErrArgumentMustBePositive               = types.ErrArgumentMustBePositive
 	ErrArgumentMustBeInteger                = types.ErrArgumentMustBeInteger
 	ErrArgumentMustBeBetween                = types.ErrArgumentMustBeBetween
@@ -139,34 +184,88 @@ var (
 	ErrInvalidFunctionParameter             = types.ErrInvalidFunctionParameter
 	ErrArgumentMissingOrNonUInteger         = types.ErrArgumentMissingOrNonUInteger
 	ErrArgumentMissingOrNonBoolean          = types.ErrArgumentMissingOrNonBoolean
+	ErrOrderFailed                          = types.ErrOrderFailed
+	ErrBondAccountingInconsistent           = types.ErrBondAccountingInconsistent
+	ErrExpiryHeightMustBeInFuture           = types.ErrExpiryHeightMustBeInFuture
+	ErrLimitPriceNotMet                     = types.ErrLimitPriceNotMet
+	ErrSpendTooSmallToBuyAnyTokens          = types.ErrSpendTooSmallToBuyAnyTokens
+	ErrMinReturnsNotMet                     = types.ErrMinReturnsNotMet
+	ErrOrderNotFound                        = types.ErrOrderNotFound
+	ErrOrderAlreadyCancelled                = types.ErrOrderAlreadyCancelled
+	ErrOrderNotOwnedBySender                = types.ErrOrderNotOwnedBySender
+	ErrBudgetDoesNotCoverOrder              = types.ErrBudgetDoesNotCoverOrder
+	ErrEndHeightMustBeInFuture              = types.ErrEndHeightMustBeInFuture
+	ErrRecurringOrderNotFound               = types.ErrRecurringOrderNotFound
+	ErrSettlementSnapshotNotFound           = types.ErrSettlementSnapshotNotFound
+	ErrAmountExceedsShare                   = types.ErrAmountExceedsShare
+	ErrCannotReuseBondToken                 = types.ErrCannotReuseBondToken
+	ErrInvalidOutcomeTranche                = types.ErrInvalidOutcomeTranche
+	ErrOutcomePaymentAndTranches            = types.ErrOutcomePaymentAndTranches
+	ErrBondHasNoOutcomeTranches             = types.ErrBondHasNoOutcomeTranches
+	ErrOutcomePaymentNotAttested            = types.ErrOutcomePaymentNotAttested
 
-	BondsKeyPrefix       = types.BondsKeyPrefix
-	BatchesKeyPrefix     = types.BatchesKeyPrefix
-	LastBatchesKeyPrefix = types.LastBatchesKeyPrefix
+	BondsKeyPrefix               = types.BondsKeyPrefix
+	BatchesKeyPrefix             = types.BatchesKeyPrefix
+	LastBatchesKeyPrefix         = types.LastBatchesKeyPrefix
+	LimitOrdersKeyPrefix         = types.LimitOrdersKeyPrefix
+	LimitOrderBookKeyPrefix      = types.LimitOrderBookKeyPrefix
+	LimitOrderExpiryKeyPrefix    = types.LimitOrderExpiryKeyPrefix
+	LastOrderIdKey               = types.LastOrderIdKey
+	RecurringOrdersKeyPrefix     = types.RecurringOrdersKeyPrefix
+	LastRecurringOrderIdKey      = types.LastRecurringOrderIdKey
+	SettlementSnapshotsKeyPrefix = types.SettlementSnapshotsKeyPrefix
+	HolderSnapshotsKeyPrefix     = types.HolderSnapshotsKeyPrefix
+	ShareDistributionsKeyPrefix  = types.ShareDistributionsKeyPrefix
+	ClaimDeadlinesKeyPrefix      = types.ClaimDeadlinesKeyPrefix
 )
 
 type (
-	Keeper = keeper.Keeper
+	Keeper         = keeper.Keeper
+	OutcomesKeeper = types.OutcomesKeeper
+
+	Batch            = types.Batch
+	BatchRecord      = types.BatchRecord
+	RolledOverOrders = types.RolledOverOrders
+	BaseOrder        = types.BaseOrder
+	BuyOrder         = types.BuyOrder
+	SellOrder        = types.SellOrder
+	SwapOrder        = types.SwapOrder
+
+	LimitOrder     = types.LimitOrder
+	RecurringOrder = types.RecurringOrder
+
+	SettlementSnapshot  = types.SettlementSnapshot
+	HolderSnapshot      = types.HolderSnapshot
+	ShareDistribution   = types.ShareDistribution
+	TrancheDistribution = types.TrancheDistribution
+	OutcomeTranche      = types.OutcomeTranche
 
-	Batch     = types.Batch
-	BaseOrder = types.BaseOrder
-	BuyOrder  = types.BuyOrder
-	SellOrder = types.SellOrder
-	SwapOrder = types.SwapOrder
+	OrderCommitment = types.OrderCommitment
+	RevealedOrder   = types.RevealedOrder
 
 	FunctionParamRestrictions = types.FunctionParamRestrictions
 	FunctionParam             = types.FunctionParam
 	FunctionParams            = types.FunctionParams
+	BondingFunction           = types.BondingFunction
 
 	Bond = types.Bond
 
 	GenesisState = types.GenesisState
 
-	MsgCreateBond         = types.MsgCreateBond
-	MsgEditBond           = types.MsgEditBond
-	MsgBuy                = types.MsgBuy
-	MsgSell               = types.MsgSell
-	MsgSwap               = types.MsgSwap
-	MsgMakeOutcomePayment = types.MsgMakeOutcomePayment
-	MsgWithdrawShare      = types.MsgWithdrawShare
+	MsgCreateBond           = types.MsgCreateBond
+	MsgEditBond             = types.MsgEditBond
+	MsgBuy                  = types.MsgBuy
+	MsgSell                 = types.MsgSell
+	MsgSwap                 = types.MsgSwap
+	MsgMakeOutcomePayment   = types.MsgMakeOutcomePayment
+	MsgWithdrawShare        = types.MsgWithdrawShare
+	MsgSettleBond           = types.MsgSettleBond
+	MsgLimitBuy             = types.MsgLimitBuy
+	MsgLimitSell            = types.MsgLimitSell
+	MsgSpendBuy             = types.MsgSpendBuy
+	MsgCancelOrder          = types.MsgCancelOrder
+	MsgCommitOrder          = types.MsgCommitOrder
+	MsgRevealOrder          = types.MsgRevealOrder
+	MsgCreateRecurringOrder = types.MsgCreateRecurringOrder
+	MsgCancelRecurringOrder = types.MsgCancelRecurringOrder
 )
@@ -22,6 +22,7 @@ import (
 	"github.com/cosmos/cosmos-sdk/x/slashing"
 	"github.com/cosmos/cosmos-sdk/x/staking"
 	"github.com/cosmos/cosmos-sdk/x/supply"
+	"github.com/warmage-sports/peyote/x/outcomes"
 	"github.com/warmage-sports/peyote/x/peyote"
 	abci "github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/libs/log"
@@ -62,6 +63,7 @@ var (
 		evidence.AppModuleBasic{},
 
 		peyote.AppModuleBasic{},
+		outcomes.AppModuleBasic{},
 	)
 
 	// module account permissions
@@ -129,7 +131,8 @@ type SimApp struct {
 	paramsKeeper   params.Keeper
 	evidenceKeeper evidence.Keeper
 
-	BondsKeeper peyote.Keeper
+	BondsKeeper    peyote.Keeper
+	OutcomesKeeper outcomes.Keeper
 
 	// Module Manager
 	mm *module.Manager
@@ -155,7 +158,7 @@ func NewSimApp(
 		supply.StoreKey, mint.StoreKey, distr.StoreKey, slashing.StoreKey,
 		gov.StoreKey, upgrade.StoreKey, params.StoreKey, evidence.StoreKey,
 
-		peyote.StoreKey,
+		peyote.StoreKey, outcomes.StoreKey,
 	)
 	tkeys := sdk.NewTransientStoreKeys(params.TStoreKey)
 
@@ -185,9 +188,11 @@ func NewSimApp(
 	app.AccountKeeper = auth.NewAccountKeeper(
 		app.cdc, keys[auth.StoreKey], app.subspaces[auth.ModuleName], auth.ProtoBaseAccount,
 	)
-	app.BankKeeper = bank.NewBaseKeeper(
+	// NOTE: The bank keeper indexes the holders of bond tokens, so it has to
+	// be used by every module that can move bond tokens
+	app.BankKeeper = peyote.NewHolderTrackingBankKeeper(bank.NewBaseKeeper(
 		app.AccountKeeper, app.subspaces[bank.ModuleName], app.BlacklistedAccAddrs(),
-	)
+	), app.AccountKeeper, keys[peyote.StoreKey], app.cdc)
 	app.SupplyKeeper = supply.NewKeeper(
 		app.cdc, keys[supply.StoreKey], app.AccountKeeper, app.BankKeeper, maccPerms,
 	)
@@ -232,15 +237,23 @@ func NewSimApp(
 		staking.NewMultiStakingHooks(app.distrKeeper.Hooks(), app.slashingKeeper.Hooks()),
 	)
 
-	app.BondsKeeper = peyote.NewKeeper(
+	bondsKeeper := peyote.NewKeeper(
 		app.BankKeeper,
 		app.SupplyKeeper,
 		app.AccountKeeper,
 		app.StakingKeeper,
+		app.distrKeeper,
 		keys[peyote.StoreKey],
 		app.subspaces[peyote.ModuleName],
 		app.cdc,
 	)
+	app.OutcomesKeeper = outcomes.NewKeeper(
+		bondsKeeper, keys[outcomes.StoreKey], app.cdc,
+	)
+
+	// NOTE: BondsKeeper above is passed by reference, so that it will contain
+	// the outcomes keeper, which holds the attestations of outcome payments
+	app.BondsKeeper = *bondsKeeper.SetOutcomesKeeper(app.OutcomesKeeper)
 
 	// NOTE: Any module instantiated in the module manager that is later modified
 	// must be passed by reference here.
@@ -258,6 +271,7 @@ func NewSimApp(
 		upgrade.NewAppModule(app.upgradeKeeper),
 		evidence.NewAppModule(app.evidenceKeeper),
 		peyote.NewAppModule(app.BondsKeeper, app.AccountKeeper),
+		outcomes.NewAppModule(app.OutcomesKeeper),
 	)
 
 	// During begin block slashing happens after distr.BeginBlocker so that
@@ -275,7 +289,7 @@ func NewSimApp(
 	app.mm.SetOrderInitGenesis(
 		auth.ModuleName, distr.ModuleName, staking.ModuleName, bank.ModuleName,
 		slashing.ModuleName, gov.ModuleName, evidence.ModuleName, mint.ModuleName,
-		peyote.ModuleName,
+		peyote.ModuleName, outcomes.ModuleName,
 		supply.ModuleName, crisis.ModuleName, genutil.ModuleName,
 	)
 
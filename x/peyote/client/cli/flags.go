@@ -22,7 +22,25 @@ const (
 	FlagAllowSells             = "allow-sells"
 	FlagSigners                = "signers"
 	FlagBatchBlocks            = "batch-blocks"
+	FlagRevealBlocks           = "reveal-blocks"
+	FlagForfeitUnrevealed      = "forfeit-unrevealed"
+	FlagMaxBatchOrders         = "max-batch-orders"
+	FlagMaxBatchVolume         = "max-batch-volume"
+	FlagRollOverOrders         = "roll-over-orders"
 	FlagOutcomePayment         = "outcome-payment"
+	FlagOutcomeTranches        = "outcome-tranches"
+	FlagOutcomePayers          = "outcome-payers"
+	FlagEvaluators             = "evaluators"
+	FlagAutoDistribute         = "auto-distribute"
+	FlagClaimBlocks            = "claim-blocks"
+	FlagSweepToCommunityPool   = "sweep-to-community-pool"
+	FlagMinReturns             = "min-returns"
+	FlagPrices                 = "prices"
+	FlagToToken                = "to-token"
+	FlagSalt                   = "salt"
+	FlagRecipient              = "recipient"
+	FlagEndHeight              = "end-height"
+	FlagAmount                 = "amount"
 )
 
 var (
@@ -50,7 +68,18 @@ func init() {
 	fsBondCreate.String(FlagSanityMarginPercentage, "", "For swappers, this is the acceptable deviation from the sanity rate")
 	fsBondCreate.Bool(FlagAllowSells, false, "Whether or not sells will be allowed")
 	fsBondCreate.String(FlagBatchBlocks, "", "The duration in terms of blocks of each orders batch")
+	fsBondCreate.String(FlagRevealBlocks, "0", "The number of final blocks of each batch in which order commitments are revealed")
+	fsBondCreate.Bool(FlagForfeitUnrevealed, false, "Whether or not the deposits of unrevealed order commitments are forfeited")
+	fsBondCreate.String(FlagMaxBatchOrders, "0", "The max number of orders in each batch (0 for the module's limit)")
+	fsBondCreate.String(FlagMaxBatchVolume, "0", "The max number of tokens bought and sold in each batch (0 for the module's limit)")
+	fsBondCreate.Bool(FlagRollOverOrders, false, "Whether or not orders that do not fit in a batch are rolled over to the next batch")
 	fsBondCreate.String(FlagOutcomePayment, "", "The payment that would be required to transition the bond to settlement")
+	fsBondCreate.String(FlagOutcomeTranches, "", "The outcome payment tranches, paid one after the other, of which the last transitions the bond to settlement (e.g. \"10res;20res:<payer>;30res::distribute\")")
+	fsBondCreate.String(FlagOutcomePayers, "", "The list of addresses authorised to make outcome payments (empty for any address)")
+	fsBondCreate.String(FlagEvaluators, "", "The list of evaluators whose attestations outcome payments are scaled by (empty for no attestations)")
+	fsBondCreate.Bool(FlagAutoDistribute, false, "Whether or not the reserve is distributed to all holders automatically once the bond is settled")
+	fsBondCreate.String(FlagClaimBlocks, "0", "The number of blocks after settlement in which holders can withdraw their share (0 for no deadline)")
+	fsBondCreate.Bool(FlagSweepToCommunityPool, false, "Whether or not the reserve unclaimed by the deadline is sent to the community pool rather than the fee address")
 
 	fsBondEdit.String(FlagName, types.DoNotModifyField, "The bond's name")
 	fsBondEdit.String(FlagDescription, types.DoNotModifyField, "The bond's description")
@@ -15,6 +15,7 @@ import (
 	"github.com/warmage-sports/peyote/x/peyote/internal/types"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"strconv"
 	"strings"
 )
 
@@ -35,6 +36,15 @@ func GetTxCmd(cdc *codec.Codec) *cobra.Command {
 		GetCmdSwap(cdc),
 		GetCmdMakeOutcomePayment(cdc),
 		GetCmdWithdrawShare(cdc),
+		GetCmdSettleBond(cdc),
+		GetCmdLimitBuy(cdc),
+		GetCmdLimitSell(cdc),
+		GetCmdSpendBuy(cdc),
+		GetCmdCancelOrder(cdc),
+		GetCmdCommitOrder(cdc),
+		GetCmdRevealOrder(cdc),
+		GetCmdCreateRecurringOrder(cdc),
+		GetCmdCancelRecurringOrder(cdc),
 	)...)
 
 	return peyoteTxCmd
@@ -61,7 +71,18 @@ func GetCmdCreateBond(cdc *codec.Codec) *cobra.Command {
 			_allowSells := viper.GetBool(FlagAllowSells)
 			_signers := viper.GetString(FlagSigners)
 			_batchBlocks := viper.GetString(FlagBatchBlocks)
+			_revealBlocks := viper.GetString(FlagRevealBlocks)
+			_forfeitUnrevealed := viper.GetBool(FlagForfeitUnrevealed)
+			_maxBatchOrders := viper.GetString(FlagMaxBatchOrders)
+			_maxBatchVolume := viper.GetString(FlagMaxBatchVolume)
+			_rollOverOrders := viper.GetBool(FlagRollOverOrders)
 			_outcomePayment := viper.GetString(FlagOutcomePayment)
+			_outcomeTranches := viper.GetString(FlagOutcomeTranches)
+			_outcomePayers := viper.GetString(FlagOutcomePayers)
+			_evaluators := viper.GetString(FlagEvaluators)
+			_autoDistribute := viper.GetBool(FlagAutoDistribute)
+			_claimBlocks := viper.GetString(FlagClaimBlocks)
+			_sweepToCommunityPool := viper.GetBool(FlagSweepToCommunityPool)
 
 			inBuf := bufio.NewReader(cmd.InOrStdin())
 			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
@@ -130,17 +151,62 @@ func GetCmdCreateBond(cdc *codec.Codec) *cobra.Command {
 				return sdkerrors.Wrap(types.ErrArgumentMissingOrNonUInteger, "max batch blocks")
 			}
 
+			// Parse reveal blocks
+			revealBlocks, err := sdk.ParseUint(_revealBlocks)
+			if err != nil {
+				return sdkerrors.Wrap(types.ErrArgumentMissingOrNonUInteger, "reveal blocks")
+			}
+
+			// Parse max batch orders
+			maxBatchOrders, err := sdk.ParseUint(_maxBatchOrders)
+			if err != nil {
+				return sdkerrors.Wrap(types.ErrArgumentMissingOrNonUInteger, "max batch orders")
+			}
+
+			// Parse max batch volume
+			maxBatchVolume, err := sdk.ParseUint(_maxBatchVolume)
+			if err != nil {
+				return sdkerrors.Wrap(types.ErrArgumentMissingOrNonUInteger, "max batch volume")
+			}
+
 			// Parse order quantity limits
 			outcomePayment, err := sdk.ParseCoins(_outcomePayment)
 			if err != nil {
 				return err
 			}
 
+			// Parse outcome tranches
+			outcomeTranches, err := client2.ParseOutcomeTranches(_outcomeTranches)
+			if err != nil {
+				return err
+			}
+
+			// Parse outcome payers
+			outcomePayers, err := client2.ParseOptionalAddresses(_outcomePayers)
+			if err != nil {
+				return err
+			}
+
+			// Parse evaluators
+			evaluators, err := client2.ParseOptionalAddresses(_evaluators)
+			if err != nil {
+				return err
+			}
+
+			// Parse claim blocks
+			claimBlocks, err := sdk.ParseUint(_claimBlocks)
+			if err != nil {
+				return sdkerrors.Wrap(types.ErrArgumentMissingOrNonUInteger, "claim blocks")
+			}
+
 			msg := types.NewMsgCreateBond(_token, _name, _description,
 				cliCtx.GetFromAddress(), _functionType, functionParams,
 				reserveTokens, txFeePercentage, exitFeePercentage, feeAddress,
 				maxSupply, orderQuantityLimits, sanityRate, sanityMarginPercentage,
-				_allowSells, signers, batchBlocks, outcomePayment)
+				_allowSells, signers, batchBlocks, revealBlocks, _forfeitUnrevealed,
+				maxBatchOrders, maxBatchVolume, _rollOverOrders, outcomePayment,
+				outcomeTranches, outcomePayers, evaluators, _autoDistribute,
+				claimBlocks, _sweepToCommunityPool)
 			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
 		},
 	}
@@ -213,7 +279,8 @@ func GetCmdBuy(cdc *codec.Codec) *cobra.Command {
 		Use: "buy [bond-token-with-amount] [max-prices]",
 		Example: "" +
 			"buy 10abc 1000res1\n" +
-			"buy 10abc 1000res1,1000res2",
+			"buy 10abc 1000res1,1000res2\n" +
+			"buy 10abc 1000res1 --recipient=[address]",
 		Short: "Buy from a bond",
 		Args:  cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -232,11 +299,17 @@ func GetCmdBuy(cdc *codec.Codec) *cobra.Command {
 				return err
 			}
 
+			recipient, err := parseRecipient()
+			if err != nil {
+				return err
+			}
+
 			msg := types.NewMsgBuy(cliCtx.GetFromAddress(),
-				bondCoinWithAmount, maxPrices)
+				bondCoinWithAmount, maxPrices, recipient)
 			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
 		},
 	}
+	cmd.Flags().String(FlagRecipient, "", "The address to send the bond tokens bought to, if not the buyer")
 	_ = cmd.MarkFlagRequired(flags.FlagFrom)
 	return cmd
 }
@@ -244,7 +317,7 @@ func GetCmdBuy(cdc *codec.Codec) *cobra.Command {
 func GetCmdSell(cdc *codec.Codec) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "sell [bond-token-with-amount]",
-		Example: "sell 10abc",
+		Example: "sell 10abc --min-returns=1000res1",
 		Short:   "Sell from a bond",
 		Args:    cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -258,10 +331,22 @@ func GetCmdSell(cdc *codec.Codec) *cobra.Command {
 				return err
 			}
 
-			msg := types.NewMsgSell(cliCtx.GetFromAddress(), bondCoinWithAmount)
+			minReturns, err := sdk.ParseCoins(viper.GetString(FlagMinReturns))
+			if err != nil {
+				return err
+			}
+
+			recipient, err := parseRecipient()
+			if err != nil {
+				return err
+			}
+
+			msg := types.NewMsgSell(cliCtx.GetFromAddress(), bondCoinWithAmount, minReturns, recipient)
 			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
 		},
 	}
+	cmd.Flags().String(FlagMinReturns, "", "The minimum reserve tokens to receive after fees, otherwise the sell is cancelled")
+	cmd.Flags().String(FlagRecipient, "", "The address to send the returns to, if not the seller")
 	_ = cmd.MarkFlagRequired(flags.FlagFrom)
 	return cmd
 }
@@ -271,7 +356,7 @@ func GetCmdSwap(cdc *codec.Codec) *cobra.Command {
 		Use: "swap [bond-token] [from-amount] [from-token] [to-token]",
 		Example: "" +
 			"swap abc 100 res1 res2\n" +
-			"swap abc 100 res2 res1",
+			"swap abc 100 res2 res1 --min-returns=90res1",
 		Short: "Perform a swap between two tokens",
 		Args:  cobra.ExactArgs(4),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -286,10 +371,22 @@ func GetCmdSwap(cdc *codec.Codec) *cobra.Command {
 				return err
 			}
 
-			msg := types.NewMsgSwap(cliCtx.GetFromAddress(), args[0], from, args[3])
+			minReturns, err := sdk.ParseCoins(viper.GetString(FlagMinReturns))
+			if err != nil {
+				return err
+			}
+
+			recipient, err := parseRecipient()
+			if err != nil {
+				return err
+			}
+
+			msg := types.NewMsgSwap(cliCtx.GetFromAddress(), args[0], from, args[3], minReturns, recipient)
 			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
 		},
 	}
+	cmd.Flags().String(FlagMinReturns, "", "The minimum to-tokens to receive, otherwise the swap is cancelled")
+	cmd.Flags().String(FlagRecipient, "", "The address to send the to-tokens to, if not the swapper")
 	_ = cmd.MarkFlagRequired(flags.FlagFrom)
 	return cmd
 }
@@ -317,7 +414,7 @@ func GetCmdMakeOutcomePayment(cdc *codec.Codec) *cobra.Command {
 func GetCmdWithdrawShare(cdc *codec.Codec) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "withdraw-share [bond-token]",
-		Example: "withdraw-share abc",
+		Example: "withdraw-share abc --amount 10abc",
 		Short:   "Withdraw share from a bond that is in settlement state",
 		Args:    cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -326,7 +423,338 @@ func GetCmdWithdrawShare(cdc *codec.Codec) *cobra.Command {
 			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
 			cliCtx := context.NewCLIContext().WithCodec(cdc)
 
-			msg := types.NewMsgWithdrawShare(cliCtx.GetFromAddress(), args[0])
+			amount, err := sdk.ParseCoins(viper.GetString(FlagAmount))
+			if err != nil {
+				return err
+			}
+
+			msg := types.NewMsgWithdrawShare(cliCtx.GetFromAddress(), args[0], amount)
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().String(FlagAmount, "", "The amount of bond tokens held at settlement to withdraw the share of, if not the entire remaining share")
+	_ = cmd.MarkFlagRequired(flags.FlagFrom)
+	return cmd
+}
+
+func GetCmdSettleBond(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "settle-bond [bond-token]",
+		Example: "settle-bond abc --signers <signer-address>",
+		Short:   "Settle a bond before all of its outcome tranches have been paid",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			// Parse signers
+			signers, err := client2.ParseSigners(viper.GetString(FlagSigners))
+			if err != nil {
+				return err
+			}
+
+			msg := types.NewMsgSettleBond(args[0], cliCtx.GetFromAddress(), signers)
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().String(FlagSigners, "", "The list of signers of the bond")
+	_ = cmd.MarkFlagRequired(flags.FlagFrom)
+	_ = cmd.MarkFlagRequired(FlagSigners)
+	return cmd
+}
+
+func GetCmdLimitBuy(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "limit-buy [bond-token-with-amount] [limit-price] [expiry-height]",
+		Example: "limit-buy 10abc 12.5 1000",
+		Short:   "Place a limit buy for a bond that is kept until the expiry height",
+		Args:    cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			bondCoinWithAmount, err := sdk.ParseCoin(args[0])
+			if err != nil {
+				return err
+			}
+
+			limitPrice, err := sdk.NewDecFromStr(args[1])
+			if err != nil {
+				return sdkerrors.Wrap(types.ErrArgumentMissingOrNonFloat, "limit price")
+			}
+
+			expiryHeight, err := strconv.ParseInt(args[2], 10, 64)
+			if err != nil {
+				return sdkerrors.Wrap(types.ErrArgumentMissingOrNonUInteger, "expiry height")
+			}
+
+			msg := types.NewMsgLimitBuy(cliCtx.GetFromAddress(),
+				bondCoinWithAmount, limitPrice, expiryHeight)
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	_ = cmd.MarkFlagRequired(flags.FlagFrom)
+	return cmd
+}
+
+func GetCmdLimitSell(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "limit-sell [bond-token-with-amount] [limit-price] [expiry-height]",
+		Example: "limit-sell 10abc 12.5 1000",
+		Short:   "Place a limit sell for a bond that is kept until the expiry height",
+		Args:    cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			bondCoinWithAmount, err := sdk.ParseCoin(args[0])
+			if err != nil {
+				return err
+			}
+
+			limitPrice, err := sdk.NewDecFromStr(args[1])
+			if err != nil {
+				return sdkerrors.Wrap(types.ErrArgumentMissingOrNonFloat, "limit price")
+			}
+
+			expiryHeight, err := strconv.ParseInt(args[2], 10, 64)
+			if err != nil {
+				return sdkerrors.Wrap(types.ErrArgumentMissingOrNonUInteger, "expiry height")
+			}
+
+			msg := types.NewMsgLimitSell(cliCtx.GetFromAddress(),
+				bondCoinWithAmount, limitPrice, expiryHeight)
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	_ = cmd.MarkFlagRequired(flags.FlagFrom)
+	return cmd
+}
+
+func GetCmdSpendBuy(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use: "spend-buy [bond-token] [spend]",
+		Example: "" +
+			"spend-buy abc 1000res1\n" +
+			"spend-buy abc 1000res1,1000res2",
+		Short: "Buy as many tokens from a bond as the spend can pay for (including fees)",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			spend, err := sdk.ParseCoins(args[1])
+			if err != nil {
+				return err
+			}
+
+			msg := types.NewMsgSpendBuy(cliCtx.GetFromAddress(), args[0], spend)
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	_ = cmd.MarkFlagRequired(flags.FlagFrom)
+	return cmd
+}
+
+func GetCmdCancelOrder(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "cancel-order [bond-token] [order-id]",
+		Example: "cancel-order abc 12",
+		Short:   "Cancel a buy, sell or swap that is pending in a bond's current batch",
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			orderId, err := strconv.ParseUint(args[1], 10, 64)
+			if err != nil {
+				return sdkerrors.Wrap(types.ErrArgumentMissingOrNonUInteger, "order id")
+			}
+
+			msg := types.NewMsgCancelOrder(cliCtx.GetFromAddress(), args[0], orderId)
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	_ = cmd.MarkFlagRequired(flags.FlagFrom)
+	return cmd
+}
+
+// parseRecipient parses the optional recipient flag of buy, sell and swap,
+// returning an empty address if no recipient is specified.
+func parseRecipient() (sdk.AccAddress, error) {
+	recipientStr := viper.GetString(FlagRecipient)
+	if recipientStr == "" {
+		return nil, nil
+	}
+	return sdk.AccAddressFromBech32(recipientStr)
+}
+
+// parseRevealedOrder parses the order type and amount arguments and the order
+// flags of commit-order and reveal-order into the order being committed to.
+func parseRevealedOrder(orderType, amountStr string) (types.RevealedOrder, error) {
+	amount, err := sdk.ParseCoin(amountStr)
+	if err != nil {
+		return types.RevealedOrder{}, err
+	}
+
+	prices, err := sdk.ParseCoins(viper.GetString(FlagPrices))
+	if err != nil {
+		return types.RevealedOrder{}, err
+	}
+
+	return types.NewRevealedOrder(orderType, amount, prices,
+		viper.GetString(FlagToToken), viper.GetString(FlagSalt)), nil
+}
+
+func addRevealedOrderFlags(cmd *cobra.Command) {
+	cmd.Flags().String(FlagPrices, "", "The max prices of a buy, or the min returns of a sell or swap")
+	cmd.Flags().String(FlagToToken, "", "The token that a swap is to")
+	cmd.Flags().String(FlagSalt, "", "A secret value that prevents the order from being guessed from the commitment")
+	_ = cmd.MarkFlagRequired(FlagSalt)
+}
+
+func GetCmdCommitOrder(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use: "commit-order [bond-token] [order-type] [amount] [deposit]",
+		Example: "" +
+			"commit-order abc buy 10abc 5res --prices=1000res --salt=secret\n" +
+			"commit-order abc swap 100res1 5res1 --to-token=res2 --salt=secret",
+		Short: "Commit to a hidden buy, sell or swap that is revealed before the batch ends",
+		Args:  cobra.ExactArgs(4),
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			order, err := parseRevealedOrder(args[1], args[2])
+			if err != nil {
+				return err
+			}
+
+			deposit, err := sdk.ParseCoins(args[3])
+			if err != nil {
+				return err
+			}
+
+			// Check that the order is valid before committing to it
+			_, err = order.ToMsg(cliCtx.GetFromAddress(), args[0])
+			if err != nil {
+				return err
+			}
+
+			hash := order.GetCommitmentHash(cliCtx.GetFromAddress(), args[0])
+			msg := types.NewMsgCommitOrder(cliCtx.GetFromAddress(), args[0], hash, deposit)
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	addRevealedOrderFlags(cmd)
+	_ = cmd.MarkFlagRequired(flags.FlagFrom)
+	return cmd
+}
+
+func GetCmdRevealOrder(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use: "reveal-order [bond-token] [commitment-id] [order-type] [amount]",
+		Example: "" +
+			"reveal-order abc 12 buy 10abc --prices=1000res --salt=secret\n" +
+			"reveal-order abc 13 swap 100res1 --to-token=res2 --salt=secret",
+		Short: "Reveal the order behind an order commitment",
+		Args:  cobra.ExactArgs(4),
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			commitmentId, err := strconv.ParseUint(args[1], 10, 64)
+			if err != nil {
+				return sdkerrors.Wrap(types.ErrArgumentMissingOrNonUInteger, "commitment id")
+			}
+
+			order, err := parseRevealedOrder(args[2], args[3])
+			if err != nil {
+				return err
+			}
+
+			msg := types.NewMsgRevealOrder(cliCtx.GetFromAddress(), args[0], commitmentId, order)
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	addRevealedOrderFlags(cmd)
+	_ = cmd.MarkFlagRequired(flags.FlagFrom)
+	return cmd
+}
+
+func GetCmdCreateRecurringOrder(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use: "create-recurring-order [bond-token] [order-type] [amount] [budget] [interval]",
+		Example: "" +
+			"create-recurring-order abc buy 100res 1000res 2\n" +
+			"create-recurring-order abc sell 10abc 100abc 1 --end-height=5000",
+		Short: "Place a buy (spending the amount) or a sell (of the amount) into every Nth batch of a bond until the budget is spent",
+		Args:  cobra.ExactArgs(5),
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			amount, err := sdk.ParseCoins(args[2])
+			if err != nil {
+				return err
+			}
+
+			budget, err := sdk.ParseCoins(args[3])
+			if err != nil {
+				return err
+			}
+
+			interval, err := sdk.ParseUint(args[4])
+			if err != nil {
+				return sdkerrors.Wrap(types.ErrArgumentMissingOrNonUInteger, "interval")
+			}
+
+			endHeight := viper.GetInt64(FlagEndHeight)
+
+			msg := types.NewMsgCreateRecurringOrder(cliCtx.GetFromAddress(),
+				args[0], args[1], amount, budget, interval, endHeight)
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().Int64(FlagEndHeight, 0, "The height at which the recurring order ends, if not only once the budget is spent")
+	_ = cmd.MarkFlagRequired(flags.FlagFrom)
+	return cmd
+}
+
+func GetCmdCancelRecurringOrder(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "cancel-recurring-order [order-id]",
+		Example: "cancel-recurring-order 3",
+		Short:   "Cancel a recurring order and refund its remaining budget",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			orderId, err := strconv.ParseUint(args[0], 10, 64)
+			if err != nil {
+				return sdkerrors.Wrap(types.ErrArgumentMissingOrNonUInteger, "order id")
+			}
+
+			msg := types.NewMsgCancelRecurringOrder(cliCtx.GetFromAddress(), orderId)
 			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
 		},
 	}
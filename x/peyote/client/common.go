@@ -76,6 +76,15 @@ func ParseSigners(signersStr string) (signers []sdk.AccAddress, err error) {
 	return signers, nil
 }
 
+// ParseOptionalAddresses parses a list of comma-separated account addresses,
+// which can be empty.
+func ParseOptionalAddresses(addressesStr string) ([]sdk.AccAddress, error) {
+	if strings.TrimSpace(addressesStr) == "" {
+		return nil, nil
+	}
+	return ParseSigners(addressesStr)
+}
+
 func ParseTwoPartCoin(amount, denom string) (coin sdk.Coin, err error) {
 	coin, err = sdk.ParseCoin(amount + denom)
 	if err != nil {
@@ -85,3 +94,45 @@ func ParseTwoPartCoin(amount, denom string) (coin sdk.Coin, err error) {
 	}
 	return coin, nil
 }
+
+// ParseOutcomeTranches parses a list of outcome tranches separated by
+// semicolons, where each tranche is of the form "<amount>[:<payer>[:distribute]]"
+// (e.g. "10res;20res:<payer>;30res::distribute").
+func ParseOutcomeTranches(tranchesStr string) (tranches []types.OutcomeTranche, err error) {
+	// If empty, just return empty list
+	if strings.TrimSpace(tranchesStr) == "" {
+		return nil, nil
+	}
+
+	for _, t := range strings.Split(tranchesStr, ";") {
+		// Split each "10res:<payer>:distribute" into its parts
+		parts := strings.Split(t, ":")
+		if len(parts) > 3 {
+			return nil, sdkerrors.Wrap(types.ErrInvalidOutcomeTranche, t)
+		}
+
+		amount, err := sdk.ParseCoins(parts[0])
+		if err != nil {
+			return nil, err
+		}
+
+		var payer sdk.AccAddress
+		if len(parts) > 1 && parts[1] != "" {
+			payer, err = sdk.AccAddressFromBech32(parts[1])
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		distribute := false
+		if len(parts) > 2 {
+			if parts[2] != "distribute" {
+				return nil, sdkerrors.Wrap(types.ErrInvalidOutcomeTranche, t)
+			}
+			distribute = true
+		}
+
+		tranches = append(tranches, types.NewOutcomeTranche(amount, payer, distribute))
+	}
+	return tranches, nil
+}
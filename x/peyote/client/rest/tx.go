@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"encoding/hex"
 	"github.com/cosmos/cosmos-sdk/client/context"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
@@ -10,6 +11,7 @@ import (
 	"github.com/warmage-sports/peyote/x/peyote/client"
 	"github.com/warmage-sports/peyote/x/peyote/internal/types"
 	"net/http"
+	"strconv"
 	"strings"
 )
 
@@ -21,6 +23,15 @@ func registerTxRoutes(cliCtx context.CLIContext, r *mux.Router) {
 	r.HandleFunc("/peyote/swap", swapRequestHandler(cliCtx)).Methods("POST")
 	r.HandleFunc("/peyote/make_outcome_payment", makeOutcomePaymentRequestHandler(cliCtx)).Methods("POST")
 	r.HandleFunc("/peyote/withdraw_share", withdrawShareRequestHandler(cliCtx)).Methods("POST")
+	r.HandleFunc("/peyote/settle_bond", settleBondRequestHandler(cliCtx)).Methods("POST")
+	r.HandleFunc("/peyote/limit_buy", limitBuyRequestHandler(cliCtx)).Methods("POST")
+	r.HandleFunc("/peyote/limit_sell", limitSellRequestHandler(cliCtx)).Methods("POST")
+	r.HandleFunc("/peyote/spend_buy", spendBuyRequestHandler(cliCtx)).Methods("POST")
+	r.HandleFunc("/peyote/cancel_order", cancelOrderRequestHandler(cliCtx)).Methods("POST")
+	r.HandleFunc("/peyote/commit_order", commitOrderRequestHandler(cliCtx)).Methods("POST")
+	r.HandleFunc("/peyote/reveal_order", revealOrderRequestHandler(cliCtx)).Methods("POST")
+	r.HandleFunc("/peyote/create_recurring_order", createRecurringOrderRequestHandler(cliCtx)).Methods("POST")
+	r.HandleFunc("/peyote/cancel_recurring_order", cancelRecurringOrderRequestHandler(cliCtx)).Methods("POST")
 }
 
 type createBondReq struct {
@@ -41,7 +52,18 @@ type createBondReq struct {
 	AllowSells             string       `json:"allow_sells" yaml:"allow_sells"`
 	Signers                string       `json:"signers" yaml:"signers"`
 	BatchBlocks            string       `json:"batch_blocks" yaml:"batch_blocks"`
+	RevealBlocks           string       `json:"reveal_blocks" yaml:"reveal_blocks"`
+	ForfeitUnrevealed      string       `json:"forfeit_unrevealed" yaml:"forfeit_unrevealed"`
+	MaxBatchOrders         string       `json:"max_batch_orders" yaml:"max_batch_orders"`
+	MaxBatchVolume         string       `json:"max_batch_volume" yaml:"max_batch_volume"`
+	RollOverOrders         string       `json:"roll_over_orders" yaml:"roll_over_orders"`
 	OutcomePayment         string       `json:"outcome_payment" yaml:"outcome_payment"`
+	OutcomeTranches        string       `json:"outcome_tranches" yaml:"outcome_tranches"`
+	OutcomePayers          string       `json:"outcome_payers" yaml:"outcome_payers"`
+	Evaluators             string       `json:"evaluators" yaml:"evaluators"`
+	AutoDistribute         string       `json:"auto_distribute" yaml:"auto_distribute"`
+	ClaimBlocks            string       `json:"claim_blocks" yaml:"claim_blocks"`
+	SweepToCommunityPool   string       `json:"sweep_to_community_pool" yaml:"sweep_to_community_pool"`
 }
 
 func createBondRequestHandler(cliCtx context.CLIContext) http.HandlerFunc {
@@ -153,6 +175,61 @@ func createBondRequestHandler(cliCtx context.CLIContext) http.HandlerFunc {
 			return
 		}
 
+		// Parse reveal blocks (optional, no reveal phase by default)
+		revealBlocks := sdk.ZeroUint()
+		if req.RevealBlocks != "" {
+			revealBlocks, err2 = sdk.ParseUint(req.RevealBlocks)
+			if err2 != nil {
+				err := sdkerrors.Wrap(types.ErrArgumentMissingOrNonUInteger, "reveal blocks")
+				rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+
+		// Parse forfeitUnrevealed (optional, false by default)
+		var forfeitUnrevealed bool
+		forfeitUnrevealedStrLower := strings.ToLower(req.ForfeitUnrevealed)
+		if forfeitUnrevealedStrLower == "true" {
+			forfeitUnrevealed = true
+		} else if forfeitUnrevealedStrLower != "false" && forfeitUnrevealedStrLower != "" {
+			err := sdkerrors.Wrap(types.ErrArgumentMissingOrNonBoolean, "forfeit_unrevealed")
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Parse max batch orders (optional, module's limit by default)
+		maxBatchOrders := sdk.ZeroUint()
+		if req.MaxBatchOrders != "" {
+			maxBatchOrders, err2 = sdk.ParseUint(req.MaxBatchOrders)
+			if err2 != nil {
+				err := sdkerrors.Wrap(types.ErrArgumentMissingOrNonUInteger, "max batch orders")
+				rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+
+		// Parse max batch volume (optional, module's limit by default)
+		maxBatchVolume := sdk.ZeroUint()
+		if req.MaxBatchVolume != "" {
+			maxBatchVolume, err2 = sdk.ParseUint(req.MaxBatchVolume)
+			if err2 != nil {
+				err := sdkerrors.Wrap(types.ErrArgumentMissingOrNonUInteger, "max batch volume")
+				rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+
+		// Parse rollOverOrders (optional, false by default)
+		var rollOverOrders bool
+		rollOverOrdersStrLower := strings.ToLower(req.RollOverOrders)
+		if rollOverOrdersStrLower == "true" {
+			rollOverOrders = true
+		} else if rollOverOrdersStrLower != "false" && rollOverOrdersStrLower != "" {
+			err := sdkerrors.Wrap(types.ErrArgumentMissingOrNonBoolean, "roll_over_orders")
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
 		// Parse outcome payment
 		outcomePayment, err2 := sdk.ParseCoins(req.OutcomePayment)
 		if err2 != nil {
@@ -160,11 +237,68 @@ func createBondRequestHandler(cliCtx context.CLIContext) http.HandlerFunc {
 			return
 		}
 
+		// Parse outcome tranches (optional)
+		outcomeTranches, err2 := client.ParseOutcomeTranches(req.OutcomeTranches)
+		if err2 != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err2.Error())
+			return
+		}
+
+		// Parse outcome payers (optional)
+		outcomePayers, err2 := client.ParseOptionalAddresses(req.OutcomePayers)
+		if err2 != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err2.Error())
+			return
+		}
+
+		// Parse evaluators (optional)
+		evaluators, err2 := client.ParseOptionalAddresses(req.Evaluators)
+		if err2 != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err2.Error())
+			return
+		}
+
+		// Parse autoDistribute (optional, false by default)
+		var autoDistribute bool
+		autoDistributeStrLower := strings.ToLower(req.AutoDistribute)
+		if autoDistributeStrLower == "true" {
+			autoDistribute = true
+		} else if autoDistributeStrLower != "false" && autoDistributeStrLower != "" {
+			err := sdkerrors.Wrap(types.ErrArgumentMissingOrNonBoolean, "auto_distribute")
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Parse claim blocks (optional, no deadline by default)
+		claimBlocks := sdk.ZeroUint()
+		if req.ClaimBlocks != "" {
+			claimBlocks, err2 = sdk.ParseUint(req.ClaimBlocks)
+			if err2 != nil {
+				err := sdkerrors.Wrap(types.ErrArgumentMissingOrNonUInteger, "claim blocks")
+				rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+
+		// Parse sweepToCommunityPool (optional, false by default)
+		var sweepToCommunityPool bool
+		sweepToCommunityPoolStrLower := strings.ToLower(req.SweepToCommunityPool)
+		if sweepToCommunityPoolStrLower == "true" {
+			sweepToCommunityPool = true
+		} else if sweepToCommunityPoolStrLower != "false" && sweepToCommunityPoolStrLower != "" {
+			err := sdkerrors.Wrap(types.ErrArgumentMissingOrNonBoolean, "sweep_to_community_pool")
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
 		msg := types.NewMsgCreateBond(req.Token, req.Name, req.Description,
 			creator, req.FunctionType, functionParams, reserveTokens,
 			txFeePercentageDec, exitFeePercentageDec, feeAddress, maxSupply,
 			orderQuantityLimits, sanityRate, sanityMarginPercentage,
-			allowSells, signers, batchBlocks, outcomePayment)
+			allowSells, signers, batchBlocks, revealBlocks, forfeitUnrevealed,
+			maxBatchOrders, maxBatchVolume, rollOverOrders, outcomePayment,
+			outcomeTranches, outcomePayers, evaluators, autoDistribute,
+			claimBlocks, sweepToCommunityPool)
 
 		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
 	}
@@ -221,6 +355,7 @@ type buyReq struct {
 	BondToken  string       `json:"bond_token" yaml:"bond_token"`
 	BondAmount string       `json:"bond_amount" yaml:"bond_amount"`
 	MaxPrices  string       `json:"max_prices" yaml:"max_prices"`
+	Recipient  string       `json:"recipient" yaml:"recipient"`
 }
 
 func buyRequestHandler(cliCtx context.CLIContext) http.HandlerFunc {
@@ -255,7 +390,13 @@ func buyRequestHandler(cliCtx context.CLIContext) http.HandlerFunc {
 			return
 		}
 
-		msg := types.NewMsgBuy(buyer, bondCoin, maxPrices)
+		recipient, err := parseRecipient(req.Recipient)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		msg := types.NewMsgBuy(buyer, bondCoin, maxPrices, recipient)
 		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
 	}
 }
@@ -264,6 +405,8 @@ type sellReq struct {
 	BaseReq    rest.BaseReq `json:"base_req" yaml:"base_req"`
 	BondToken  string       `json:"bond_token" yaml:"bond_token"`
 	BondAmount string       `json:"bond_amount" yaml:"bond_amount"`
+	MinReturns string       `json:"min_returns" yaml:"min_returns"`
+	Recipient  string       `json:"recipient" yaml:"recipient"`
 }
 
 func sellRequestHandler(cliCtx context.CLIContext) http.HandlerFunc {
@@ -292,7 +435,19 @@ func sellRequestHandler(cliCtx context.CLIContext) http.HandlerFunc {
 			return
 		}
 
-		msg := types.NewMsgSell(seller, bondCoin)
+		minReturns, err := sdk.ParseCoins(req.MinReturns)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		recipient, err := parseRecipient(req.Recipient)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		msg := types.NewMsgSell(seller, bondCoin, minReturns, recipient)
 		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
 	}
 }
@@ -303,6 +458,8 @@ type swapReq struct {
 	FromAmount string       `json:"from_amount" yaml:"from_amount"`
 	FromToken  string       `json:"from_token" yaml:"from_token"`
 	ToToken    string       `json:"to_token" yaml:"to_token"`
+	MinReturns string       `json:"min_returns" yaml:"min_returns"`
+	Recipient  string       `json:"recipient" yaml:"recipient"`
 }
 
 func swapRequestHandler(cliCtx context.CLIContext) http.HandlerFunc {
@@ -332,11 +489,32 @@ func swapRequestHandler(cliCtx context.CLIContext) http.HandlerFunc {
 			return
 		}
 
-		msg := types.NewMsgSwap(swapper, req.BondToken, fromCoin, req.ToToken)
+		minReturns, err := sdk.ParseCoins(req.MinReturns)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		recipient, err := parseRecipient(req.Recipient)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		msg := types.NewMsgSwap(swapper, req.BondToken, fromCoin, req.ToToken, minReturns, recipient)
 		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
 	}
 }
 
+// parseRecipient parses the optional recipient of a buy, sell or swap request,
+// returning an empty address if no recipient is specified.
+func parseRecipient(recipientStr string) (sdk.AccAddress, error) {
+	if recipientStr == "" {
+		return nil, nil
+	}
+	return sdk.AccAddressFromBech32(recipientStr)
+}
+
 type makeOutcomePaymentReq struct {
 	BaseReq   rest.BaseReq `json:"base_req" yaml:"base_req"`
 	BondToken string       `json:"bond_token" yaml:"bond_token"`
@@ -368,8 +546,9 @@ func makeOutcomePaymentRequestHandler(cliCtx context.CLIContext) http.HandlerFun
 }
 
 type withdrawShareReq struct {
-	BaseReq   rest.BaseReq `json:"base_req" yaml:"base_req"`
-	BondToken string       `json:"bond_token" yaml:"bond_token"`
+	BaseReq    rest.BaseReq `json:"base_req" yaml:"base_req"`
+	BondToken  string       `json:"bond_token" yaml:"bond_token"`
+	BondAmount string       `json:"bond_amount" yaml:"bond_amount"`
 }
 
 func withdrawShareRequestHandler(cliCtx context.CLIContext) http.HandlerFunc {
@@ -392,7 +571,409 @@ func withdrawShareRequestHandler(cliCtx context.CLIContext) http.HandlerFunc {
 			return
 		}
 
-		msg := types.NewMsgWithdrawShare(recipient, req.BondToken)
+		var amount sdk.Coins
+		if req.BondAmount != "" {
+			bondCoin, err := client.ParseTwoPartCoin(req.BondAmount, req.BondToken)
+			if err != nil {
+				rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			amount = sdk.NewCoins(bondCoin)
+		}
+
+		msg := types.NewMsgWithdrawShare(recipient, req.BondToken, amount)
+		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
+	}
+}
+
+type settleBondReq struct {
+	BaseReq   rest.BaseReq `json:"base_req" yaml:"base_req"`
+	BondToken string       `json:"bond_token" yaml:"bond_token"`
+	Signers   string       `json:"signers" yaml:"signers"`
+}
+
+func settleBondRequestHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req settleBondReq
+
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
+			return
+		}
+
+		baseReq := req.BaseReq.Sanitize()
+		if !baseReq.ValidateBasic(w) {
+			return
+		}
+
+		settler, err := sdk.AccAddressFromBech32(req.BaseReq.From)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Parse signers
+		signers, err := client.ParseSigners(req.Signers)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		msg := types.NewMsgSettleBond(req.BondToken, settler, signers)
+		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
+	}
+}
+
+type limitOrderReq struct {
+	BaseReq      rest.BaseReq `json:"base_req" yaml:"base_req"`
+	BondToken    string       `json:"bond_token" yaml:"bond_token"`
+	BondAmount   string       `json:"bond_amount" yaml:"bond_amount"`
+	LimitPrice   string       `json:"limit_price" yaml:"limit_price"`
+	ExpiryHeight string       `json:"expiry_height" yaml:"expiry_height"`
+}
+
+func parseLimitOrderReq(w http.ResponseWriter, r *http.Request, cliCtx context.CLIContext) (
+	req limitOrderReq, address sdk.AccAddress, bondCoin sdk.Coin,
+	limitPrice sdk.Dec, expiryHeight int64, ok bool) {
+
+	if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+		rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
+		return
+	}
+
+	req.BaseReq = req.BaseReq.Sanitize()
+	if !req.BaseReq.ValidateBasic(w) {
+		return
+	}
+
+	address, err := sdk.AccAddressFromBech32(req.BaseReq.From)
+	if err != nil {
+		rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	bondCoin, err = client.ParseTwoPartCoin(req.BondAmount, req.BondToken)
+	if err != nil {
+		rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	limitPrice, err = sdk.NewDecFromStr(req.LimitPrice)
+	if err != nil {
+		err = sdkerrors.Wrap(types.ErrArgumentMissingOrNonFloat, "limit price")
+		rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	expiryHeight, err = strconv.ParseInt(req.ExpiryHeight, 10, 64)
+	if err != nil {
+		err = sdkerrors.Wrap(types.ErrArgumentMissingOrNonUInteger, "expiry height")
+		rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	return req, address, bondCoin, limitPrice, expiryHeight, true
+}
+
+func limitBuyRequestHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, buyer, bondCoin, limitPrice, expiryHeight, ok := parseLimitOrderReq(w, r, cliCtx)
+		if !ok {
+			return
+		}
+
+		msg := types.NewMsgLimitBuy(buyer, bondCoin, limitPrice, expiryHeight)
+		utils.WriteGenerateStdTxResponse(w, cliCtx, req.BaseReq, []sdk.Msg{msg})
+	}
+}
+
+func limitSellRequestHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, seller, bondCoin, limitPrice, expiryHeight, ok := parseLimitOrderReq(w, r, cliCtx)
+		if !ok {
+			return
+		}
+
+		msg := types.NewMsgLimitSell(seller, bondCoin, limitPrice, expiryHeight)
+		utils.WriteGenerateStdTxResponse(w, cliCtx, req.BaseReq, []sdk.Msg{msg})
+	}
+}
+
+type spendBuyReq struct {
+	BaseReq   rest.BaseReq `json:"base_req" yaml:"base_req"`
+	BondToken string       `json:"bond_token" yaml:"bond_token"`
+	Spend     string       `json:"spend" yaml:"spend"`
+}
+
+func spendBuyRequestHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req spendBuyReq
+
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
+			return
+		}
+
+		baseReq := req.BaseReq.Sanitize()
+		if !baseReq.ValidateBasic(w) {
+			return
+		}
+
+		buyer, err := sdk.AccAddressFromBech32(req.BaseReq.From)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		spend, err := sdk.ParseCoins(req.Spend)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		msg := types.NewMsgSpendBuy(buyer, req.BondToken, spend)
+		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
+	}
+}
+
+type cancelOrderReq struct {
+	BaseReq   rest.BaseReq `json:"base_req" yaml:"base_req"`
+	BondToken string       `json:"bond_token" yaml:"bond_token"`
+	OrderId   string       `json:"order_id" yaml:"order_id"`
+}
+
+func cancelOrderRequestHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req cancelOrderReq
+
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
+			return
+		}
+
+		baseReq := req.BaseReq.Sanitize()
+		if !baseReq.ValidateBasic(w) {
+			return
+		}
+
+		sender, err := sdk.AccAddressFromBech32(req.BaseReq.From)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		orderId, err := strconv.ParseUint(req.OrderId, 10, 64)
+		if err != nil {
+			err = sdkerrors.Wrap(types.ErrArgumentMissingOrNonUInteger, "order id")
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		msg := types.NewMsgCancelOrder(sender, req.BondToken, orderId)
+		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
+	}
+}
+
+type commitOrderReq struct {
+	BaseReq   rest.BaseReq `json:"base_req" yaml:"base_req"`
+	BondToken string       `json:"bond_token" yaml:"bond_token"`
+	Hash      string       `json:"hash" yaml:"hash"`
+	Deposit   string       `json:"deposit" yaml:"deposit"`
+}
+
+func commitOrderRequestHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req commitOrderReq
+
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
+			return
+		}
+
+		baseReq := req.BaseReq.Sanitize()
+		if !baseReq.ValidateBasic(w) {
+			return
+		}
+
+		sender, err := sdk.AccAddressFromBech32(req.BaseReq.From)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		hash, err := hex.DecodeString(req.Hash)
+		if err != nil {
+			err = sdkerrors.Wrap(types.ErrInvalidOrderCommitmentHash, err.Error())
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		deposit, err := sdk.ParseCoins(req.Deposit)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		msg := types.NewMsgCommitOrder(sender, req.BondToken, hash, deposit)
+		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
+	}
+}
+
+type revealOrderReq struct {
+	BaseReq      rest.BaseReq `json:"base_req" yaml:"base_req"`
+	BondToken    string       `json:"bond_token" yaml:"bond_token"`
+	CommitmentId string       `json:"commitment_id" yaml:"commitment_id"`
+	OrderType    string       `json:"order_type" yaml:"order_type"`
+	Amount       string       `json:"amount" yaml:"amount"`
+	Prices       string       `json:"prices" yaml:"prices"`
+	ToToken      string       `json:"to_token" yaml:"to_token"`
+	Salt         string       `json:"salt" yaml:"salt"`
+}
+
+func revealOrderRequestHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req revealOrderReq
+
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
+			return
+		}
+
+		baseReq := req.BaseReq.Sanitize()
+		if !baseReq.ValidateBasic(w) {
+			return
+		}
+
+		sender, err := sdk.AccAddressFromBech32(req.BaseReq.From)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		commitmentId, err := strconv.ParseUint(req.CommitmentId, 10, 64)
+		if err != nil {
+			err = sdkerrors.Wrap(types.ErrArgumentMissingOrNonUInteger, "commitment id")
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		amount, err := sdk.ParseCoin(req.Amount)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		prices, err := sdk.ParseCoins(req.Prices)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		order := types.NewRevealedOrder(req.OrderType, amount, prices, req.ToToken, req.Salt)
+		msg := types.NewMsgRevealOrder(sender, req.BondToken, commitmentId, order)
+		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
+	}
+}
+
+type createRecurringOrderReq struct {
+	BaseReq   rest.BaseReq `json:"base_req" yaml:"base_req"`
+	BondToken string       `json:"bond_token" yaml:"bond_token"`
+	OrderType string       `json:"order_type" yaml:"order_type"`
+	Amount    string       `json:"amount" yaml:"amount"`
+	Budget    string       `json:"budget" yaml:"budget"`
+	Interval  string       `json:"interval" yaml:"interval"`
+	EndHeight string       `json:"end_height" yaml:"end_height"`
+}
+
+func createRecurringOrderRequestHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req createRecurringOrderReq
+
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
+			return
+		}
+
+		baseReq := req.BaseReq.Sanitize()
+		if !baseReq.ValidateBasic(w) {
+			return
+		}
+
+		owner, err := sdk.AccAddressFromBech32(req.BaseReq.From)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		amount, err := sdk.ParseCoins(req.Amount)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		budget, err := sdk.ParseCoins(req.Budget)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		interval, err := sdk.ParseUint(req.Interval)
+		if err != nil {
+			err = sdkerrors.Wrap(types.ErrArgumentMissingOrNonUInteger, "interval")
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// End height is optional
+		var endHeight int64
+		if req.EndHeight != "" {
+			endHeight, err = strconv.ParseInt(req.EndHeight, 10, 64)
+			if err != nil {
+				err = sdkerrors.Wrap(types.ErrArgumentMissingOrNonUInteger, "end height")
+				rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+
+		msg := types.NewMsgCreateRecurringOrder(owner, req.BondToken,
+			req.OrderType, amount, budget, interval, endHeight)
+		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
+	}
+}
+
+type cancelRecurringOrderReq struct {
+	BaseReq rest.BaseReq `json:"base_req" yaml:"base_req"`
+	OrderId string       `json:"order_id" yaml:"order_id"`
+}
+
+func cancelRecurringOrderRequestHandler(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req cancelRecurringOrderReq
+
+		if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
+			return
+		}
+
+		baseReq := req.BaseReq.Sanitize()
+		if !baseReq.ValidateBasic(w) {
+			return
+		}
+
+		owner, err := sdk.AccAddressFromBech32(req.BaseReq.From)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		orderId, err := strconv.ParseUint(req.OrderId, 10, 64)
+		if err != nil {
+			err = sdkerrors.Wrap(types.ErrArgumentMissingOrNonUInteger, "order id")
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		msg := types.NewMsgCancelRecurringOrder(owner, orderId)
 		utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
 	}
 }
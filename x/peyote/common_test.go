@@ -3,6 +3,7 @@ package peyote_test
 import (
 	"github.com/cosmos/cosmos-sdk/codec"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/warmage-sports/peyote/x/peyote"
 	"github.com/warmage-sports/peyote/x/peyote/app"
 	"github.com/warmage-sports/peyote/x/peyote/internal/types"
 	abci "github.com/tendermint/tendermint/abci/types"
@@ -37,7 +38,18 @@ var (
 	initAllowSell              = true
 	initSigners                = []sdk.AccAddress{initCreator}
 	initBatchBlocks            = sdk.OneUint()
+	initRevealBlocks           = sdk.ZeroUint()
+	initForfeitUnrevealed      = false
+	initMaxBatchOrders         = sdk.ZeroUint()
+	initMaxBatchVolume         = sdk.ZeroUint()
+	initRollOverOrders         = false
 	initOutcomePayment         = sdk.Coins(nil)
+	initOutcomeTranches        = []types.OutcomeTranche(nil)
+	initOutcomePayers          = []sdk.AccAddress(nil)
+	initEvaluators             = []sdk.AccAddress(nil)
+	initAutoDistribute         = false
+	initClaimBlocks            = sdk.ZeroUint()
+	initSweepToCommunityPool   = false
 
 	amountLTMaxSupply = initMaxSupply.Amount.Sub(sdk.OneInt()).Int64()
 	amountGTMaxSupply = initMaxSupply.Amount.Add(sdk.OneInt()).Int64()
@@ -95,6 +107,13 @@ func createTestApp(isCheckTx bool) (*simapp.SimApp, sdk.Context) {
 	return app, ctx
 }
 
+// endBlock runs the EndBlocker at the context's height and returns a
+// context for the next block.
+func endBlock(app *simapp.SimApp, ctx sdk.Context) sdk.Context {
+	peyote.EndBlocker(ctx, app.BondsKeeper)
+	return ctx.WithBlockHeight(ctx.BlockHeight() + 1)
+}
+
 // Helpers
 
 func newSimpleBond() types.Bond {
@@ -129,23 +148,68 @@ func newValidMsgCreateBond() types.MsgCreateBond {
 		functionType, functionParams, reserveTokens, initTxFeePercentage,
 		initExitFeePercentage, initFeeAddress, initMaxSupply,
 		initOrderQuantityLimits, initSanityRate, initSanityMarginPercentage,
-		initAllowSell, initSigners, initBatchBlocks, initOutcomePayment)
+		initAllowSell, initSigners, initBatchBlocks, initRevealBlocks,
+		initForfeitUnrevealed, initMaxBatchOrders, initMaxBatchVolume,
+		initRollOverOrders, initOutcomePayment, initOutcomeTranches,
+		initOutcomePayers, initEvaluators, initAutoDistribute, initClaimBlocks, initSweepToCommunityPool)
+}
+
+func newValidMsgCreateCommitRevealBond(forfeitUnrevealed bool) types.MsgCreateBond {
+	msg := newValidMsgCreateBond()
+	msg.BatchBlocks = sdk.NewUint(3)
+	msg.RevealBlocks = sdk.OneUint()
+	msg.ForfeitUnrevealed = forfeitUnrevealed
+	return msg
 }
 
 func newValidMsgBuy(amount int64, maxPrice int64) types.MsgBuy {
 	amountCoin := sdk.NewInt64Coin(token, amount)
 	maxPrices := sdk.NewCoins(sdk.NewInt64Coin(reserveToken, maxPrice))
-	return types.NewMsgBuy(userAddress, amountCoin, maxPrices)
+	return types.NewMsgBuy(userAddress, amountCoin, maxPrices, nil)
 }
 
 func newValidMsgSell(amount int64) types.MsgSell {
 	amountCoin := sdk.NewInt64Coin(token, amount)
-	return types.NewMsgSell(userAddress, amountCoin)
+	return types.NewMsgSell(userAddress, amountCoin, nil, nil)
 }
 
 func newValidMsgSwap(fromToken, toToken string, amount int64) types.MsgSwap {
 	fromAmount := sdk.NewInt64Coin(fromToken, amount)
-	return types.NewMsgSwap(userAddress, token, fromAmount, toToken)
+	return types.NewMsgSwap(userAddress, token, fromAmount, toToken, nil, nil)
+}
+
+func newValidMsgLimitBuy(amount int64, limitPrice int64, expiryHeight int64) types.MsgLimitBuy {
+	amountCoin := sdk.NewInt64Coin(token, amount)
+	return types.NewMsgLimitBuy(userAddress, amountCoin, sdk.NewDec(limitPrice), expiryHeight)
+}
+
+func newValidMsgLimitSell(amount int64, limitPrice int64, expiryHeight int64) types.MsgLimitSell {
+	amountCoin := sdk.NewInt64Coin(token, amount)
+	return types.NewMsgLimitSell(userAddress, amountCoin, sdk.NewDec(limitPrice), expiryHeight)
+}
+
+func newValidMsgSpendBuy(spend int64) types.MsgSpendBuy {
+	spendCoins := sdk.NewCoins(sdk.NewInt64Coin(reserveToken, spend))
+	return types.NewMsgSpendBuy(userAddress, token, spendCoins)
+}
+
+func newValidMsgCreateRecurringBuy(spend, budget int64, interval uint64, endHeight int64) types.MsgCreateRecurringOrder {
+	spendCoins := sdk.NewCoins(sdk.NewInt64Coin(reserveToken, spend))
+	budgetCoins := sdk.NewCoins(sdk.NewInt64Coin(reserveToken, budget))
+	return types.NewMsgCreateRecurringOrder(userAddress, token, types.AttributeValueBuyOrder,
+		spendCoins, budgetCoins, sdk.NewUint(interval), endHeight)
+}
+
+func newValidRevealedBuy(amount int64, maxPrice int64, salt string) types.RevealedOrder {
+	amountCoin := sdk.NewInt64Coin(token, amount)
+	maxPrices := sdk.NewCoins(sdk.NewInt64Coin(reserveToken, maxPrice))
+	return types.NewRevealedOrder(types.AttributeValueBuyOrder, amountCoin, maxPrices, "", salt)
+}
+
+func newValidMsgCommitOrder(order types.RevealedOrder, deposit int64) types.MsgCommitOrder {
+	hash := order.GetCommitmentHash(userAddress, token)
+	depositCoins := sdk.NewCoins(sdk.NewInt64Coin(reserveToken, deposit))
+	return types.NewMsgCommitOrder(userAddress, token, hash, depositCoins)
 }
 
 func newValidMsgMakeOutcomePayment() types.MsgMakeOutcomePayment {
@@ -153,7 +217,7 @@ func newValidMsgMakeOutcomePayment() types.MsgMakeOutcomePayment {
 }
 
 func newValidMsgWithdrawShareFrom(from sdk.AccAddress) types.MsgWithdrawShare {
-	return types.NewMsgWithdrawShare(from, token)
+	return types.NewMsgWithdrawShare(from, token, nil)
 }
 
 func addCoinsToUser(app *simapp.SimApp, ctx sdk.Context, coins sdk.Coins) error {
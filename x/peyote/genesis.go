@@ -11,33 +11,180 @@ func InitGenesis(ctx sdk.Context, keeper Keeper, data GenesisState) {
 		keeper.SetBond(ctx, b.Token, b)
 	}
 
-	// Initialise batches
+	// Index the holders of the bonds' tokens, since the index is not exported
+	keeper.IndexHolders(ctx)
+
+	// Initialise batches, limit orders, order commitments and rolled-over
+	// orders (last order ID is the highest ID of any order or order commitment,
+	// since these share the same IDs)
+	var lastOrderId uint64
 	for _, b := range data.Batches {
 		keeper.SetBatch(ctx, b.Token, b)
+		for _, o := range b.Buys {
+			lastOrderId = maxOrderId(lastOrderId, o.Id)
+		}
+		for _, o := range b.Sells {
+			lastOrderId = maxOrderId(lastOrderId, o.Id)
+		}
+		for _, o := range b.Swaps {
+			lastOrderId = maxOrderId(lastOrderId, o.Id)
+		}
+	}
+	for _, c := range data.OrderCommitments {
+		keeper.SetOrderCommitment(ctx, c)
+		lastOrderId = maxOrderId(lastOrderId, c.Id)
+	}
+	for _, r := range data.RolledOverOrders {
+		keeper.SetRolledOverOrders(ctx, r)
+		for _, o := range r.Buys {
+			lastOrderId = maxOrderId(lastOrderId, o.Id)
+		}
+		for _, o := range r.Sells {
+			lastOrderId = maxOrderId(lastOrderId, o.Id)
+		}
+		for _, o := range r.Swaps {
+			lastOrderId = maxOrderId(lastOrderId, o.Id)
+		}
+	}
+	for _, o := range data.LimitOrders {
+		keeper.SetLimitOrder(ctx, o)
+		lastOrderId = maxOrderId(lastOrderId, o.Id)
+	}
+	keeper.SetLastOrderId(ctx, lastOrderId)
+
+	// Initialise recurring orders (last recurring order ID is the highest ID)
+	var lastRecurringOrderId uint64
+	for _, o := range data.RecurringOrders {
+		keeper.SetRecurringOrder(ctx, o)
+		lastRecurringOrderId = maxOrderId(lastRecurringOrderId, o.Id)
+	}
+	keeper.SetLastRecurringOrderId(ctx, lastRecurringOrderId)
+
+	// Initialise batch history
+	for _, r := range data.BatchHistory {
+		keeper.SetBatchRecord(ctx, r)
+	}
+
+	// Initialise settlement snapshots (including their holder snapshots)
+	for _, s := range data.SettlementSnapshots {
+		keeper.SetSettlementSnapshot(ctx, s)
+	}
+
+	// Initialise share distributions that are in progress
+	for _, d := range data.ShareDistributions {
+		keeper.SetShareDistribution(ctx, d)
+	}
+
+	// Initialise tranche distributions that are in progress (including their
+	// holders)
+	for _, d := range data.TrancheDistributions {
+		keeper.SetTrancheDistribution(ctx, d)
+	}
+
+	// Schedule the batches of bonds with pending orders
+	for _, b := range data.Bonds {
+		if keeper.HasPendingOrders(ctx, b.Token) {
+			keeper.ScheduleBatch(ctx, b.Token)
+		}
 	}
 
 	// Initialise params
 	keeper.SetParams(ctx, data.Params)
 }
 
+func maxOrderId(a, b uint64) uint64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
 func ExportGenesis(ctx sdk.Context, k Keeper) GenesisState {
-	// Export peyote and batches
+	// Export peyote, batches and rolled-over orders
 	var peyote []types.Bond
 	var batches []types.Batch
+	var rolledOverOrders []types.RolledOverOrders
 	iterator := k.GetBondIterator(ctx)
 	for ; iterator.Valid(); iterator.Next() {
 		bond := k.MustGetBondByKey(ctx, iterator.Key())
 		batch := k.MustGetBatch(ctx, bond.Token)
 		peyote = append(peyote, bond)
 		batches = append(batches, batch)
+		if orders := k.GetRolledOverOrders(ctx, bond.Token); !orders.IsEmpty() {
+			rolledOverOrders = append(rolledOverOrders, orders)
+		}
+	}
+
+	// Export limit orders
+	var limitOrders []types.LimitOrder
+	limitOrdersIterator := k.GetLimitOrdersIterator(ctx)
+	for ; limitOrdersIterator.Valid(); limitOrdersIterator.Next() {
+		order := k.MustGetLimitOrderByKey(ctx, limitOrdersIterator.Key())
+		limitOrders = append(limitOrders, order)
+	}
+	limitOrdersIterator.Close()
+
+	// Export order commitments
+	var orderCommitments []types.OrderCommitment
+	commitmentsIterator := k.GetOrderCommitmentsIterator(ctx)
+	for ; commitmentsIterator.Valid(); commitmentsIterator.Next() {
+		commitment := k.MustGetOrderCommitmentByKey(ctx, commitmentsIterator.Key())
+		orderCommitments = append(orderCommitments, commitment)
+	}
+	commitmentsIterator.Close()
+
+	// Export recurring orders
+	var recurringOrders []types.RecurringOrder
+	recurringOrdersIterator := k.GetRecurringOrdersIterator(ctx)
+	for ; recurringOrdersIterator.Valid(); recurringOrdersIterator.Next() {
+		order := k.MustGetRecurringOrderByKey(ctx, recurringOrdersIterator.Key())
+		recurringOrders = append(recurringOrders, order)
+	}
+	recurringOrdersIterator.Close()
+
+	// Export batch history
+	var batchHistory []types.BatchRecord
+	historyIterator := k.GetBatchHistoryIterator(ctx)
+	for ; historyIterator.Valid(); historyIterator.Next() {
+		record := k.MustGetBatchRecordByKey(ctx, historyIterator.Key())
+		batchHistory = append(batchHistory, record)
+	}
+	historyIterator.Close()
+
+	// Export settlement snapshots (including their holder snapshots)
+	var settlementSnapshots []types.SettlementSnapshot
+	snapshotsIterator := k.GetSettlementSnapshotsIterator(ctx)
+	for ; snapshotsIterator.Valid(); snapshotsIterator.Next() {
+		snapshot := k.MustGetSettlementSnapshotByKey(ctx, snapshotsIterator.Key())
+		snapshot.Holders = k.GetHolderSnapshots(ctx, snapshot.BondToken)
+		settlementSnapshots = append(settlementSnapshots, snapshot)
+	}
+	snapshotsIterator.Close()
+
+	// Export share distributions that are in progress
+	shareDistributions := k.GetShareDistributions(ctx)
+
+	// Export tranche distributions that are in progress (including their
+	// holders)
+	trancheDistributions := k.GetTrancheDistributions(ctx)
+	for i, d := range trancheDistributions {
+		trancheDistributions[i].Holders = k.GetTrancheHolders(ctx, d.BondToken, d.Tranche, 0)
 	}
 
 	// Export params
 	params := k.GetParams(ctx)
 
 	return GenesisState{
-		Bonds:   peyote,
-		Batches: batches,
-		Params:  params,
+		Bonds:                peyote,
+		Batches:              batches,
+		LimitOrders:          limitOrders,
+		OrderCommitments:     orderCommitments,
+		BatchHistory:         batchHistory,
+		RolledOverOrders:     rolledOverOrders,
+		RecurringOrders:      recurringOrders,
+		SettlementSnapshots:  settlementSnapshots,
+		ShareDistributions:   shareDistributions,
+		TrancheDistributions: trancheDistributions,
+		Params:               params,
 	}
 }
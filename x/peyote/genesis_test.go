@@ -49,11 +49,49 @@ func TestInitAndExportGenesis(t *testing.T) {
 	bond := types.NewBond(token, name, description, creator, functionType,
 		functionParameters, reserveTokens, txFeePercentage, exitFeePercentage,
 		feeAddress, maxSupply, orderQuantityLimits, sanityRate, sanityMarginPercentage,
-		allowSell, signers, batchBlocks, outcomePayment, state)
-	batch := types.NewBatch(bond.Token, bond.BatchBlocks)
+		allowSell, signers, batchBlocks, sdk.ZeroUint(), false, sdk.ZeroUint(),
+		sdk.ZeroUint(), false, outcomePayment, nil, nil, nil, false, sdk.ZeroUint(), false,
+		state)
+	batch := types.NewBatch(bond.Token, int64(bond.BatchBlocks.Uint64()),
+		bond.BatchBlocks, bond.RevealBlocks)
+	sellOrder := types.NewSellOrder(creator, sdk.NewInt64Coin(token, 10), nil)
+	sellOrder.Id = 5
+	batch.Sells = []types.SellOrder{sellOrder}
+	batch.TotalSellAmount = sellOrder.Amount
+	limitOrder := types.NewLimitOrder(types.LimitBuyOrderType, creator,
+		sdk.NewInt64Coin(token, 10), sdk.NewDec(5),
+		sdk.NewCoins(sdk.NewInt64Coin("reservetoken", 55)), 100)
+	limitOrder.Id = 7
+	commitment := types.NewOrderCommitment(token, creator,
+		make([]byte, 32), sdk.NewCoins(sdk.NewInt64Coin("reservetoken", 1)))
+	commitment.Id = 3
+	batch.OrderCount = 2 // sell order and commitment
+	record := types.NewBatchRecord(batch)
+	rolledOverOrders := types.NewRolledOverOrders(token)
+	rolledOverBuy := types.NewBuyOrder(creator, sdk.NewInt64Coin(token, 10),
+		sdk.NewCoins(sdk.NewInt64Coin("reservetoken", 100)))
+	rolledOverBuy.Id = 9
+	rolledOverOrders.Buys = []types.BuyOrder{rolledOverBuy}
+	recurringOrder := types.NewRecurringOrder(types.AttributeValueBuyOrder, creator,
+		token, sdk.NewCoins(sdk.NewInt64Coin("reservetoken", 10)),
+		sdk.NewCoins(sdk.NewInt64Coin("reservetoken", 100)), sdk.NewUint(2), 200)
+	recurringOrder.Id = 4
+	snapshot := types.NewSettlementSnapshot(token, 50)
+	snapshot.TotalBalance = sdk.NewInt(10)
+	snapshot.ClaimDeadline = 60
+	snapshot.Holders = []types.HolderSnapshot{types.NewHolderSnapshot(creator, sdk.NewInt(10))}
+	distribution := types.NewShareDistribution(token)
+	trancheDistribution := types.NewTrancheDistribution(token, 1,
+		sdk.NewCoins(sdk.NewInt64Coin("reservetoken", 100)))
+	trancheDistribution.TotalBalance = sdk.NewInt(10)
+	trancheDistribution.Holders = []types.HolderSnapshot{types.NewHolderSnapshot(creator, sdk.NewInt(10))}
 
-	genesisState = peyote.NewGenesisState(
-		[]types.Bond{bond}, []types.Batch{batch}, types.DefaultParams())
+	genesisState = peyote.NewGenesisState([]types.Bond{bond}, []types.Batch{batch},
+		[]types.LimitOrder{limitOrder}, []types.OrderCommitment{commitment},
+		[]types.BatchRecord{record}, []types.RolledOverOrders{rolledOverOrders},
+		[]types.RecurringOrder{recurringOrder}, []types.SettlementSnapshot{snapshot},
+		[]types.ShareDistribution{distribution},
+		[]types.TrancheDistribution{trancheDistribution}, types.DefaultParams())
 
 	peyote.InitGenesis(ctx, app.BondsKeeper, genesisState)
 
@@ -62,8 +100,49 @@ func TestInitAndExportGenesis(t *testing.T) {
 
 	returnedBatch := app.BondsKeeper.MustGetBatch(ctx, token)
 	require.Equal(t, batch, returnedBatch)
+	require.Equal(t, rolledOverBuy.Id, app.BondsKeeper.GetLastOrderId(ctx))
+
+	returnedLimitOrder := app.BondsKeeper.MustGetLimitOrder(ctx, limitOrder.Id)
+	require.Equal(t, limitOrder, returnedLimitOrder)
+
+	returnedCommitment, found := app.BondsKeeper.GetOrderCommitment(ctx, token, commitment.Id)
+	require.True(t, found)
+	require.Equal(t, commitment, returnedCommitment)
+
+	returnedRecord, found := app.BondsKeeper.GetBatchRecord(ctx, token, record.Height)
+	require.True(t, found)
+	require.Equal(t, record, returnedRecord)
+
+	returnedRolledOverOrders := app.BondsKeeper.GetRolledOverOrders(ctx, token)
+	require.Equal(t, rolledOverOrders, returnedRolledOverOrders)
+
+	returnedRecurringOrder := app.BondsKeeper.MustGetRecurringOrder(ctx, recurringOrder.Id)
+	require.Equal(t, recurringOrder, returnedRecurringOrder)
+	require.Equal(t, recurringOrder.Id, app.BondsKeeper.GetLastRecurringOrderId(ctx))
+
+	returnedHolder, found := app.BondsKeeper.GetHolderSnapshot(ctx, token, creator)
+	require.True(t, found)
+	require.Equal(t, snapshot.Holders[0], returnedHolder)
+
+	returnedDistribution, found := app.BondsKeeper.GetShareDistribution(ctx, token)
+	require.True(t, found)
+	require.Equal(t, distribution, returnedDistribution)
+
+	returnedTrancheDistribution, found := app.BondsKeeper.GetTrancheDistribution(ctx, token, 1)
+	require.True(t, found)
+	require.Nil(t, returnedTrancheDistribution.Holders)
+	require.Equal(t, trancheDistribution.Holders,
+		app.BondsKeeper.GetTrancheHolders(ctx, token, 1, 0))
 
 	exportedGenesisState := peyote.ExportGenesis(ctx, app.BondsKeeper)
 	require.Equal(t, genesisState.Bonds, exportedGenesisState.Bonds)
 	require.Equal(t, genesisState.Batches, exportedGenesisState.Batches)
+	require.Equal(t, genesisState.LimitOrders, exportedGenesisState.LimitOrders)
+	require.Equal(t, genesisState.OrderCommitments, exportedGenesisState.OrderCommitments)
+	require.Equal(t, genesisState.BatchHistory, exportedGenesisState.BatchHistory)
+	require.Equal(t, genesisState.RolledOverOrders, exportedGenesisState.RolledOverOrders)
+	require.Equal(t, genesisState.RecurringOrders, exportedGenesisState.RecurringOrders)
+	require.Equal(t, genesisState.SettlementSnapshots, exportedGenesisState.SettlementSnapshots)
+	require.Equal(t, genesisState.ShareDistributions, exportedGenesisState.ShareDistributions)
+	require.Equal(t, genesisState.TrancheDistributions, exportedGenesisState.TrancheDistributions)
 }
@@ -1,6 +1,7 @@
 package peyote
 
 import (
+	"bytes"
 	"fmt"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
@@ -14,6 +15,9 @@ import (
 func NewHandler(keeper keeper.Keeper) sdk.Handler {
 	return func(ctx sdk.Context, msg sdk.Msg) (*sdk.Result, error) {
 		ctx = ctx.WithEventManager(sdk.NewEventManager())
+		if err := checkBatchPhase(ctx, keeper, msg); err != nil {
+			return nil, err
+		}
 		switch msg := msg.(type) {
 		case types.MsgCreateBond:
 			return handleMsgCreateBond(ctx, keeper, msg)
@@ -29,6 +33,24 @@ func NewHandler(keeper keeper.Keeper) sdk.Handler {
 			return handleMsgMakeOutcomePayment(ctx, keeper, msg)
 		case types.MsgWithdrawShare:
 			return handleMsgWithdrawShare(ctx, keeper, msg)
+		case types.MsgSettleBond:
+			return handleMsgSettleBond(ctx, keeper, msg)
+		case types.MsgLimitBuy:
+			return handleMsgLimitBuy(ctx, keeper, msg)
+		case types.MsgLimitSell:
+			return handleMsgLimitSell(ctx, keeper, msg)
+		case types.MsgSpendBuy:
+			return handleMsgSpendBuy(ctx, keeper, msg)
+		case types.MsgCancelOrder:
+			return handleMsgCancelOrder(ctx, keeper, msg)
+		case types.MsgCommitOrder:
+			return handleMsgCommitOrder(ctx, keeper, msg)
+		case types.MsgRevealOrder:
+			return handleMsgRevealOrder(ctx, keeper, msg)
+		case types.MsgCreateRecurringOrder:
+			return handleMsgCreateRecurringOrder(ctx, keeper, msg)
+		case types.MsgCancelRecurringOrder:
+			return handleMsgCancelRecurringOrder(ctx, keeper, msg)
 		default:
 			return nil, sdkerrors.Wrapf(sdkerrors.ErrUnknownRequest, "Unrecognized peyote Msg type: %v", msg.Type())
 		}
@@ -37,33 +59,59 @@ func NewHandler(keeper keeper.Keeper) sdk.Handler {
 
 func EndBlocker(ctx sdk.Context, keeper keeper.Keeper) []abci.ValidatorUpdate {
 
-	iterator := keeper.GetBondIterator(ctx)
-	for ; iterator.Valid(); iterator.Next() {
-		bond := keeper.MustGetBondByKey(ctx, iterator.Key())
-		batch := keeper.MustGetBatch(ctx, bond.Token)
+	// Only the bonds whose batch is due, or whose batch's reveal phase starts,
+	// at the current height are queued (and only if they have pending orders)
+	for _, token := range keeper.GetQueuedBatches(ctx) {
+		bond := keeper.MustGetBond(ctx, token)
 
-		// Subtract one block
-		batch.BlocksRemaining = batch.BlocksRemaining.SubUint64(1)
-		keeper.SetBatch(ctx, bond.Token, batch)
+		// Quarantined bonds are not processed any further
+		if bond.State == types.QuarantineState {
+			continue
+		}
 
-		// If blocks remaining > 0 do not perform orders
-		if !batch.BlocksRemaining.IsZero() {
+		batch := keeper.MustGetBatchHeader(ctx, bond.Token)
+
+		// If the batch is not due, the reveal phase starts once only the
+		// bond's reveal blocks remain
+		if batch.ExecutionHeight != ctx.BlockHeight() {
+			remaining := batch.BlocksRemaining(ctx.BlockHeight()) - 1
+			if bond.AcceptsOrderCommitments() && remaining == int64(bond.RevealBlocks.Uint64()) {
+				ctx.EventManager().EmitEvent(sdk.NewEvent(
+					types.EventTypeRevealPhase,
+					sdk.NewAttribute(types.AttributeKeyBond, bond.Token),
+					sdk.NewAttribute(types.AttributeKeyRevealBlocks, bond.RevealBlocks.String()),
+				))
+			}
 			continue
 		}
 
-		// Perform orders
-		keeper.PerformOrders(ctx, bond.Token)
+		// Settle any order commitments that were not revealed
+		keeper.SettleUnrevealedOrderCommitments(ctx, bond.Token)
+
+		// Add the orders of any recurring orders that are due to the batch
+		keeper.PlaceRecurringOrders(ctx, bond.Token)
+
+		// Add any matching limit orders to the batch
+		keeper.MatchLimitOrders(ctx, bond.Token)
+
+		// Perform orders, and quarantine the bond if any failed order could
+		// not be refunded or if the bond's accounting is otherwise inconsistent,
+		// in which case none of the orders are performed and all are refunded
+		err := keeper.PerformBatch(ctx, bond.Token)
+		if err != nil {
+			keeper.QuarantineBond(ctx, bond.Token, err)
+			continue
+		}
 
 		// Get bond again just in case current supply was updated
 		// Get batch again just in case orders were cancelled
 		bond = keeper.MustGetBond(ctx, bond.Token)
 		batch = keeper.MustGetBatch(ctx, bond.Token)
 
-		// For augmented, if hatch phase and newSupply >= S0, go to open phase
-		if bond.FunctionType == types.AugmentedFunction &&
+		// If hatch phase and newSupply >= hatch supply, go to open phase
+		if fn, ok := types.GetHatchFunction(bond.FunctionType); ok &&
 			bond.State == types.HatchState {
-			args := bond.FunctionParameters.AsMap()
-			if bond.CurrentSupply.Amount.ToDec().GTE(args["S0"]) {
+			if bond.CurrentSupply.Amount.ToDec().GTE(fn.HatchSupply(bond)) {
 				keeper.SetBondState(ctx, bond.Token, types.OpenState)
 				bond = keeper.MustGetBond(ctx, bond.Token) // get bond again
 				bond.AllowSells = true                     // enable sells
@@ -71,21 +119,97 @@ func EndBlocker(ctx sdk.Context, keeper keeper.Keeper) []abci.ValidatorUpdate {
 			}
 		}
 
-		// Save current batch as last batch and reset current batch
+		// Save current batch as last batch (and in the batch history) and reset
+		// current batch, which is due at the end of the block that is batch
+		// blocks after this one
+		nextExecutionHeight := ctx.BlockHeight() + int64(bond.BatchBlocks.Uint64())
 		keeper.SetLastBatch(ctx, bond.Token, batch)
-		keeper.SetBatch(ctx, bond.Token, types.NewBatch(bond.Token, bond.BatchBlocks))
+		keeper.ArchiveBatch(ctx, batch)
+		keeper.SetBatch(ctx, bond.Token, types.NewBatch(bond.Token, nextExecutionHeight,
+			bond.BatchBlocks, bond.RevealBlocks))
+
+		// Add any rolled-over orders to the next batch, and quarantine the bond
+		// if any of these had to be cancelled but could not be refunded
+		err = keeper.AddRolledOverOrders(ctx, bond.Token)
+		if err != nil {
+			keeper.QuarantineBond(ctx, bond.Token, err)
+			continue
+		}
+
+		// Schedule the next batch if any orders are still pending (e.g. limit
+		// orders that were not matched, or orders that are still rolled over)
+		if keeper.HasPendingOrders(ctx, bond.Token) {
+			keeper.ScheduleBatch(ctx, bond.Token)
+		}
 	}
+	keeper.RemoveQueuedBatches(ctx)
+
+	// Prune batch records that are older than the batch history retention
+	keeper.PruneBatchHistory(ctx)
+
+	// Refund limit orders that have expired
+	keeper.CancelExpiredLimitOrders(ctx)
+
+	// Refund the remaining budgets of recurring orders that have ended
+	keeper.EndRecurringOrdersAtEndHeight(ctx)
+
+	// Distribute outcome tranches and the reserves of settled bonds to their
+	// holders
+	keeper.DistributeShares(ctx)
+
+	// Sweep the unclaimed reserves of settled bonds whose claim deadline has
+	// been reached, and close the bonds
+	keeper.SweepUnclaimedReserves(ctx)
+
 	return []abci.ValidatorUpdate{}
 }
 
+// checkBatchPhase checks that the message can be handled in the current phase
+// of its bond's batch. No orders can be placed or cancelled while the batch is
+// in its reveal phase, so that revealed orders cannot be reacted to before the
+// batch is performed.
+func checkBatchPhase(ctx sdk.Context, keeper keeper.Keeper, msg sdk.Msg) error {
+	var token string
+	switch msg := msg.(type) {
+	case types.MsgBuy:
+		token = msg.Amount.Denom
+	case types.MsgSell:
+		token = msg.Amount.Denom
+	case types.MsgSwap:
+		token = msg.BondToken
+	case types.MsgLimitBuy:
+		token = msg.Amount.Denom
+	case types.MsgLimitSell:
+		token = msg.Amount.Denom
+	case types.MsgSpendBuy:
+		token = msg.BondToken
+	case types.MsgCancelOrder:
+		token = msg.BondToken
+	case types.MsgCommitOrder:
+		token = msg.BondToken
+	default:
+		return nil
+	}
+
+	// A missing batch (i.e. bond) is reported by the message's handler
+	if keeper.BatchExists(ctx, token) && keeper.MustGetBatchHeader(ctx, token).IsInRevealPhase() {
+		return sdkerrors.Wrapf(types.ErrInvalidBatchPhase, "batch for %s is in the %s phase", token, types.RevealPhase)
+	}
+	return nil
+}
+
 func handleMsgCreateBond(ctx sdk.Context, keeper keeper.Keeper, msg types.MsgCreateBond) (*sdk.Result, error) {
 	if keeper.BankKeeper.BlacklistedAddr(msg.FeeAddress) {
 		return nil, sdkerrors.Wrapf(sdkerrors.ErrUnauthorized, "%s is not allowed to receive transactions", msg.FeeAddress)
 	}
 
-	// Check that bond and bond DID do not already exist
+	// Check that bond does not already exist, unless it is a closed bond
+	// whose token can be reused
 	if keeper.BondExists(ctx, msg.Token) {
-		return nil, sdkerrors.Wrap(types.ErrBondAlreadyExists, msg.Token)
+		err := keeper.ReleaseBondToken(ctx, msg.Token)
+		if err != nil {
+			return nil, err
+		}
 	} else if msg.Token == keeper.StakingKeeper.GetParams(ctx).BondDenom {
 		return nil, sdkerrors.Wrap(types.ErrBondTokenCannotBeStakingToken, msg.Token)
 	}
@@ -95,32 +219,19 @@ func handleMsgCreateBond(ctx sdk.Context, keeper keeper.Keeper, msg types.MsgCre
 		return nil, types.ErrReservedBondToken
 	}
 
-	// Set state to open by default (overridden below if augmented function)
+	// Set state to open by default (overridden below if hatch function)
 	state := types.OpenState
 
-	// If augmented, add R0, S0, V0 as parameters for quick access
-	// Also, override AllowSells and set to False if S0 > 0
-	if msg.FunctionType == types.AugmentedFunction {
-		paramsMap := msg.FunctionParameters.AsMap()
-		d0, _ := paramsMap["d0"]
-		p0, _ := paramsMap["p0"]
-		theta, _ := paramsMap["theta"]
-		kappa, _ := paramsMap["kappa"]
-
-		R0 := d0.Mul(sdk.OneDec().Sub(theta))
-		S0 := d0.Quo(p0)
-		V0 := types.Invariant(R0, S0, kappa.TruncateInt64())
-		// TODO: consider calculating these on-the-fly, especially R0 and S0
-
-		msg.FunctionParameters = append(msg.FunctionParameters,
-			types.FunctionParams{
-				types.NewFunctionParam("R0", R0),
-				types.NewFunctionParam("S0", S0),
-				types.NewFunctionParam("V0", V0),
-			}...)
-
-		// Set state to Hatch and disable sells. Note that it is never the case
-		// that we start with OpenState because S0>0, since S0=d0/p0 and d0>0
+	// If the function has a hatch phase, add the parameters derived from the
+	// bond's parameters (e.g. R0, S0, V0 for augmented) for quick access
+	if fn, ok := types.GetHatchFunction(msg.FunctionType); ok {
+		hatchParams, err := fn.HatchParams(msg.FunctionParameters.AsMap())
+		if err != nil {
+			return nil, err
+		}
+		msg.FunctionParameters = append(msg.FunctionParameters, hatchParams...)
+
+		// Set state to Hatch and disable sells
 		state = types.HatchState
 		msg.AllowSells = false
 	}
@@ -130,10 +241,24 @@ func handleMsgCreateBond(ctx sdk.Context, keeper keeper.Keeper, msg types.MsgCre
 		msg.TxFeePercentage, msg.ExitFeePercentage, msg.FeeAddress,
 		msg.MaxSupply, msg.OrderQuantityLimits, msg.SanityRate,
 		msg.SanityMarginPercentage, msg.AllowSells, msg.Signers,
-		msg.BatchBlocks, msg.OutcomePayment, state)
+		msg.BatchBlocks, msg.RevealBlocks, msg.ForfeitUnrevealed,
+		msg.MaxBatchOrders, msg.MaxBatchVolume, msg.RollOverOrders,
+		msg.OutcomePayment, msg.OutcomeTranches, msg.OutcomePayers,
+		msg.Evaluators, msg.AutoDistribute, msg.ClaimBlocks,
+		msg.SweepToCommunityPool, state)
+
+	// Check that the curve can be evaluated up to the max supply
+	err := bond.ValidateCurve()
+	if err != nil {
+		return nil, err
+	}
 
+	// The first batch is due at the end of the block that is batch blocks
+	// after the previous one, since the current block counts as the first
+	executionHeight := ctx.BlockHeight() + int64(msg.BatchBlocks.Uint64()) - 1
 	keeper.SetBond(ctx, msg.Token, bond)
-	keeper.SetBatch(ctx, msg.Token, types.NewBatch(bond.Token, msg.BatchBlocks))
+	keeper.SetBatch(ctx, msg.Token, types.NewBatch(bond.Token, executionHeight,
+		bond.BatchBlocks, bond.RevealBlocks))
 
 	logger := keeper.Logger(ctx)
 	logger.Info(fmt.Sprintf("bond %s [%s] with reserve(s) [%s] created by %s", msg.Token,
@@ -158,7 +283,18 @@ func handleMsgCreateBond(ctx sdk.Context, keeper keeper.Keeper, msg types.MsgCre
 			sdk.NewAttribute(types.AttributeKeyAllowSells, strconv.FormatBool(msg.AllowSells)),
 			sdk.NewAttribute(types.AttributeKeySigners, types.AccAddressesToString(msg.Signers)),
 			sdk.NewAttribute(types.AttributeKeyBatchBlocks, msg.BatchBlocks.String()),
+			sdk.NewAttribute(types.AttributeKeyRevealBlocks, msg.RevealBlocks.String()),
+			sdk.NewAttribute(types.AttributeKeyForfeitUnrevealed, strconv.FormatBool(msg.ForfeitUnrevealed)),
+			sdk.NewAttribute(types.AttributeKeyMaxBatchOrders, msg.MaxBatchOrders.String()),
+			sdk.NewAttribute(types.AttributeKeyMaxBatchVolume, msg.MaxBatchVolume.String()),
+			sdk.NewAttribute(types.AttributeKeyRollOverOrders, strconv.FormatBool(msg.RollOverOrders)),
 			sdk.NewAttribute(types.AttributeKeyOutcomePayment, msg.OutcomePayment.String()),
+			sdk.NewAttribute(types.AttributeKeyOutcomeTranches, fmt.Sprint(len(msg.OutcomeTranches))),
+			sdk.NewAttribute(types.AttributeKeyOutcomePayers, types.AccAddressesToString(msg.OutcomePayers)),
+			sdk.NewAttribute(types.AttributeKeyEvaluators, types.AccAddressesToString(msg.Evaluators)),
+			sdk.NewAttribute(types.AttributeKeyAutoDistribute, strconv.FormatBool(msg.AutoDistribute)),
+			sdk.NewAttribute(types.AttributeKeyClaimBlocks, msg.ClaimBlocks.String()),
+			sdk.NewAttribute(types.AttributeKeySweepToCommunityPool, strconv.FormatBool(msg.SweepToCommunityPool)),
 			sdk.NewAttribute(types.AttributeKeyState, state),
 		),
 		sdk.NewEvent(
@@ -265,15 +401,28 @@ func handleMsgBuy(ctx sdk.Context, keeper keeper.Keeper, msg types.MsgBuy) (*sdk
 		return nil, sdkerrors.Wrap(types.ErrOrderQuantityLimitExceeded, msg.Amount.String())
 	}
 
-	// For the swapper, the first buy is the initialisation of the reserves
-	// The max prices are used as the actual prices and one token is minted
-	// The amount of token serves to define the price of adding more liquidity
-	if bond.CurrentSupply.IsZero() && bond.FunctionType == types.SwapperFunction {
+	// Check that the recipient (if any) can receive the bond tokens bought
+	err := checkRecipient(keeper, msg.Recipient)
+	if err != nil {
+		return nil, err
+	}
+
+	// For liquidity pools (e.g. the swapper), the first buy is the
+	// initialisation of the reserves. The max prices are used as the actual
+	// prices and one token is minted. The amount of token serves to define the
+	// price of adding more liquidity
+	if bond.CurrentSupply.IsZero() && types.MustGetFunction(bond.FunctionType).IsLiquidityPool() {
 		return performFirstSwapperFunctionBuy(ctx, keeper, msg)
 	}
 
+	// Check that the order fits in the batch (or is to be rolled over)
+	rollOver, err := keeper.CheckBatchCapacity(ctx, token, msg.Amount.Amount)
+	if err != nil {
+		return nil, err
+	}
+
 	// Take max that buyer is willing to pay (enforces maxPrice <= balance)
-	err := keeper.SupplyKeeper.SendCoinsFromAccountToModule(ctx, msg.Buyer,
+	err = keeper.SupplyKeeper.SendCoinsFromAccountToModule(ctx, msg.Buyer,
 		types.BatchesIntermediaryAccount, msg.MaxPrices)
 	if err != nil {
 		return nil, err
@@ -281,25 +430,37 @@ func handleMsgBuy(ctx sdk.Context, keeper keeper.Keeper, msg types.MsgBuy) (*sdk
 
 	// Create order
 	order := types.NewBuyOrder(msg.Buyer, msg.Amount, msg.MaxPrices)
+	order.Recipient = msg.Recipient
+
+	if rollOver {
+		// Roll buy order over to a following batch
+		order = keeper.RollOverBuyOrder(ctx, token, order)
+	} else {
+		// Get buy price and check if can add buy order to batch
+		buyPrices, sellPrices, err := keeper.GetUpdatedBatchPricesAfterBuy(ctx, token, order)
+		if err != nil {
+			return nil, err
+		}
 
-	// Get buy price and check if can add buy order to batch
-	buyPrices, sellPrices, err := keeper.GetUpdatedBatchPricesAfterBuy(ctx, token, order)
-	if err != nil {
-		return nil, err
-	}
-
-	// Add buy order to batch
-	keeper.AddBuyOrder(ctx, token, order, buyPrices, sellPrices)
+		// Add buy order to batch
+		order = keeper.AddBuyOrder(ctx, token, order, buyPrices, sellPrices)
 
-	// Cancel unfulfillable orders
-	keeper.CancelUnfulfillableOrders(ctx, token)
+		// Cancel unfulfillable orders
+		_, err = keeper.CancelUnfulfillableOrders(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	ctx.EventManager().EmitEvents(sdk.Events{
 		sdk.NewEvent(
 			types.EventTypeBuy,
 			sdk.NewAttribute(types.AttributeKeyBond, msg.Amount.Denom),
+			sdk.NewAttribute(types.AttributeKeyOrderId, fmt.Sprint(order.Id)),
 			sdk.NewAttribute(sdk.AttributeKeyAmount, msg.Amount.Amount.String()),
 			sdk.NewAttribute(types.AttributeKeyMaxPrices, msg.MaxPrices.String()),
+			sdk.NewAttribute(types.AttributeKeyRecipient, order.GetRecipient().String()),
+			sdk.NewAttribute(types.AttributeKeyRolledOver, strconv.FormatBool(rollOver)),
 		),
 		sdk.NewEvent(
 			sdk.EventTypeMessage,
@@ -339,9 +500,13 @@ func performFirstSwapperFunctionBuy(ctx sdk.Context, keeper keeper.Keeper, msg t
 		return nil, err
 	}
 
-	// Send bond tokens to buyer
+	// Send bond tokens to recipient (the buyer unless specified)
+	recipient := msg.Buyer
+	if !msg.Recipient.Empty() {
+		recipient = msg.Recipient
+	}
 	err = keeper.SupplyKeeper.SendCoinsFromModuleToAccount(ctx,
-		types.BondsMintBurnAccount, msg.Buyer, sdk.Coins{msg.Amount})
+		types.BondsMintBurnAccount, recipient, sdk.Coins{msg.Amount})
 	if err != nil {
 		return nil, err
 	}
@@ -355,6 +520,7 @@ func performFirstSwapperFunctionBuy(ctx sdk.Context, keeper keeper.Keeper, msg t
 			sdk.NewAttribute(types.AttributeKeyBond, msg.Amount.Denom),
 			sdk.NewAttribute(sdk.AttributeKeyAmount, msg.Amount.Amount.String()),
 			sdk.NewAttribute(types.AttributeKeyChargedPrices, msg.MaxPrices.String()),
+			sdk.NewAttribute(types.AttributeKeyRecipient, recipient.String()),
 		),
 		sdk.NewEvent(
 			sdk.EventTypeMessage,
@@ -366,6 +532,16 @@ func performFirstSwapperFunctionBuy(ctx sdk.Context, keeper keeper.Keeper, msg t
 	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
 }
 
+// checkRecipient returns an error if the recipient of an order is specified
+// but is not allowed to receive tokens (e.g. since it is a module account)
+func checkRecipient(keeper keeper.Keeper, recipient sdk.AccAddress) error {
+	if !recipient.Empty() && keeper.BankKeeper.BlacklistedAddr(recipient) {
+		return sdkerrors.Wrapf(sdkerrors.ErrUnauthorized,
+			"%s is not allowed to receive transactions", recipient)
+	}
+	return nil
+}
+
 func handleMsgSell(ctx sdk.Context, keeper keeper.Keeper, msg types.MsgSell) (*sdk.Result, error) {
 
 	token := msg.Amount.Denom
@@ -374,49 +550,85 @@ func handleMsgSell(ctx sdk.Context, keeper keeper.Keeper, msg types.MsgSell) (*s
 		return nil, sdkerrors.Wrap(types.ErrBondDoesNotExist, token)
 	}
 
-	// Check sells allowed, current state is OPEN, and order limits not exceeded
+	// Check sells allowed, current state is OPEN, order limits not exceeded,
+	// and min returns (if any) are in the bond's reserve tokens
 	if !bond.AllowSells {
 		return nil, sdkerrors.Wrap(types.ErrBondDoesNotAllowSelling, token)
 	} else if bond.State != types.OpenState {
 		return nil, sdkerrors.Wrap(types.ErrInvalidStateForAction, bond.State)
 	} else if bond.AnyOrderQuantityLimitsExceeded(sdk.Coins{msg.Amount}) {
 		return nil, sdkerrors.Wrap(types.ErrOrderQuantityLimitExceeded, msg.Amount.String())
+	} else if !bond.ReserveDenomsInclude(msg.MinReturns) {
+		return nil, sdkerrors.Wrapf(types.ErrReserveDenomsMismatch, "%s do not match reserve; expected: %s", msg.MinReturns.String(), strings.Join(bond.ReserveTokens, ","))
 	}
 
-	// Send coins to be burned from seller (enforces sellAmount <= balance)
-	err := keeper.SupplyKeeper.SendCoinsFromAccountToModule(ctx, msg.Seller,
-		types.BondsMintBurnAccount, sdk.Coins{msg.Amount})
+	// Check that the recipient (if any) can receive the returns
+	err := checkRecipient(keeper, msg.Recipient)
 	if err != nil {
 		return nil, err
 	}
 
-	// Burn bond tokens to be sold
-	err = keeper.SupplyKeeper.BurnCoins(ctx, types.BondsMintBurnAccount,
-		sdk.Coins{msg.Amount})
+	// Check that the order fits in the batch (or is to be rolled over)
+	rollOver, err := keeper.CheckBatchCapacity(ctx, token, msg.Amount.Amount)
 	if err != nil {
 		return nil, err
 	}
 
 	// Create order
-	order := types.NewSellOrder(msg.Seller, msg.Amount)
+	order := types.NewSellOrder(msg.Seller, msg.Amount, msg.MinReturns)
+	order.Recipient = msg.Recipient
+
+	if rollOver {
+		// Escrow bond tokens to be sold (enforces sellAmount <= balance),
+		// which are only burned once the sell is added to a batch
+		err = keeper.SupplyKeeper.SendCoinsFromAccountToModule(ctx, msg.Seller,
+			types.BatchesIntermediaryAccount, sdk.Coins{msg.Amount})
+		if err != nil {
+			return nil, err
+		}
 
-	// Get sell price and check if can add sell order to batch
-	buyPrices, sellPrices, err := keeper.GetUpdatedBatchPricesAfterSell(ctx, token, order)
-	if err != nil {
-		return nil, err
-	}
+		// Roll sell order over to a following batch
+		order = keeper.RollOverSellOrder(ctx, token, order)
+	} else {
+		// Send coins to be burned from seller (enforces sellAmount <= balance)
+		err = keeper.SupplyKeeper.SendCoinsFromAccountToModule(ctx, msg.Seller,
+			types.BondsMintBurnAccount, sdk.Coins{msg.Amount})
+		if err != nil {
+			return nil, err
+		}
 
-	// Add sell order to batch
-	keeper.AddSellOrder(ctx, token, order, buyPrices, sellPrices)
+		// Burn bond tokens to be sold
+		err = keeper.SupplyKeeper.BurnCoins(ctx, types.BondsMintBurnAccount,
+			sdk.Coins{msg.Amount})
+		if err != nil {
+			return nil, err
+		}
 
-	//// Cancel unfulfillable orders (Note: no need)
-	//keeper.CancelUnfulfillableOrders(ctx, token)
+		// Get sell price and check if can add sell order to batch
+		buyPrices, sellPrices, err := keeper.GetUpdatedBatchPricesAfterSell(ctx, token, order)
+		if err != nil {
+			return nil, err
+		}
+
+		// Add sell order to batch
+		order = keeper.AddSellOrder(ctx, token, order, buyPrices, sellPrices)
+
+		// Cancel unfulfillable orders (i.e. sells whose min returns are not met)
+		_, err = keeper.CancelUnfulfillableOrders(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	ctx.EventManager().EmitEvents(sdk.Events{
 		sdk.NewEvent(
 			types.EventTypeSell,
 			sdk.NewAttribute(types.AttributeKeyBond, msg.Amount.Denom),
+			sdk.NewAttribute(types.AttributeKeyOrderId, fmt.Sprint(order.Id)),
 			sdk.NewAttribute(sdk.AttributeKeyAmount, msg.Amount.Amount.String()),
+			sdk.NewAttribute(types.AttributeKeyMinReturns, msg.MinReturns.String()),
+			sdk.NewAttribute(types.AttributeKeyRecipient, order.GetRecipient().String()),
+			sdk.NewAttribute(types.AttributeKeyRolledOver, strconv.FormatBool(rollOver)),
 		),
 		sdk.NewEvent(
 			sdk.EventTypeMessage,
@@ -435,8 +647,8 @@ func handleMsgSwap(ctx sdk.Context, keeper keeper.Keeper, msg types.MsgSwap) (*s
 		return nil, sdkerrors.Wrap(types.ErrBondDoesNotExist, msg.BondToken)
 	}
 
-	// Confirm that function type is swapper_function and state is OPEN
-	if bond.FunctionType != types.SwapperFunction {
+	// Confirm that function type is a liquidity pool and state is OPEN
+	if !types.MustGetFunction(bond.FunctionType).IsLiquidityPool() {
 		return nil, sdkerrors.Wrap(types.ErrFunctionNotAvailableForFunctionType, bond.FunctionType)
 	} else if bond.State != types.OpenState {
 		return nil, sdkerrors.Wrap(types.ErrInvalidStateForAction, bond.State)
@@ -454,18 +666,36 @@ func handleMsgSwap(ctx sdk.Context, keeper keeper.Keeper, msg types.MsgSwap) (*s
 		return nil, sdkerrors.Wrap(types.ErrOrderQuantityLimitExceeded, msg.From.String())
 	}
 
+	// Check that the recipient (if any) can receive the returns
+	err := checkRecipient(keeper, msg.Recipient)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check that the order fits in the batch (or is to be rolled over)
+	rollOver, err := keeper.CheckBatchCapacity(ctx, msg.BondToken, sdk.ZeroInt())
+	if err != nil {
+		return nil, err
+	}
+
 	// Take coins to be swapped from swapper (enforces swapAmount <= balance)
-	err := keeper.SupplyKeeper.SendCoinsFromAccountToModule(ctx, msg.Swapper,
+	err = keeper.SupplyKeeper.SendCoinsFromAccountToModule(ctx, msg.Swapper,
 		types.BatchesIntermediaryAccount, sdk.Coins{msg.From})
 	if err != nil {
 		return nil, err
 	}
 
 	// Create order
-	order := types.NewSwapOrder(msg.Swapper, msg.From, msg.ToToken)
-
-	// Add swap order to batch
-	keeper.AddSwapOrder(ctx, msg.BondToken, order)
+	order := types.NewSwapOrder(msg.Swapper, msg.From, msg.ToToken, msg.MinReturns)
+	order.Recipient = msg.Recipient
+
+	if rollOver {
+		// Roll swap order over to a following batch
+		order = keeper.RollOverSwapOrder(ctx, msg.BondToken, order)
+	} else {
+		// Add swap order to batch
+		order = keeper.AddSwapOrder(ctx, msg.BondToken, order)
+	}
 
 	//// Cancel unfulfillable orders (Note: no need)
 	//keeper.CancelUnfulfillableOrders(ctx, token)
@@ -474,9 +704,13 @@ func handleMsgSwap(ctx sdk.Context, keeper keeper.Keeper, msg types.MsgSwap) (*s
 		sdk.NewEvent(
 			types.EventTypeSwap,
 			sdk.NewAttribute(types.AttributeKeyBond, msg.BondToken),
+			sdk.NewAttribute(types.AttributeKeyOrderId, fmt.Sprint(order.Id)),
 			sdk.NewAttribute(sdk.AttributeKeyAmount, msg.From.Amount.String()),
 			sdk.NewAttribute(types.AttributeKeySwapFromToken, msg.From.Denom),
 			sdk.NewAttribute(types.AttributeKeySwapToToken, msg.ToToken),
+			sdk.NewAttribute(types.AttributeKeyMinReturns, msg.MinReturns.String()),
+			sdk.NewAttribute(types.AttributeKeyRecipient, order.GetRecipient().String()),
+			sdk.NewAttribute(types.AttributeKeyRolledOver, strconv.FormatBool(rollOver)),
 		),
 		sdk.NewEvent(
 			sdk.EventTypeMessage,
@@ -498,24 +732,86 @@ func handleMsgMakeOutcomePayment(ctx sdk.Context, keeper keeper.Keeper, msg type
 	// Confirm that state is OPEN and that outcome payment is not nil
 	if bond.State != types.OpenState {
 		return nil, sdkerrors.Wrap(types.ErrInvalidStateForAction, bond.State)
-	} else if bond.OutcomePayment.Empty() {
+	} else if !bond.HasOutcomePayment() {
 		return nil, types.ErrCannotMakeZeroOutcomePayment
 	}
 
-	// Send outcome payment to reserve
-	err := keeper.DepositReserve(ctx, bond.Token, msg.Sender, bond.OutcomePayment)
+	// Check that the sender is authorised to make outcome payments
+	if !bond.IsOutcomePayer(msg.Sender) {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrUnauthorized,
+			"%s is not an authorised outcome payer", msg.Sender)
+	}
+
+	// The payment is either the entire outcome payment, which settles the
+	// bond, or the next outcome tranche, which only settles the bond if it is
+	// the last tranche
+	payment := bond.OutcomePayment
+	settle := true
+	distribute := false
+	if bond.HasOutcomeTranches() {
+		tranche, last, found := bond.GetNextOutcomeTranche()
+		if !found {
+			return nil, sdkerrors.Wrap(types.ErrCannotMakeZeroOutcomePayment,
+				"all outcome tranches have already been paid")
+		} else if !tranche.CanBePaidBy(msg.Sender) {
+			return nil, sdkerrors.Wrapf(sdkerrors.ErrUnauthorized,
+				"%s is not the payer of outcome tranche %d", msg.Sender, bond.TranchesPaid)
+		}
+		payment = tranche.Amount
+		settle = last
+		distribute = tranche.DistributeToHolders
+	}
+
+	// If the bond has evaluators, the payment can only be made once a quorum
+	// of them has attested it, and is scaled by the result that they attested
+	// (e.g. nothing is paid if the outcome failed)
+	attestedPercentage := sdk.NewDec(100)
+	if bond.HasEvaluators() {
+		attestedPercentage, found = keeper.GetAttestedPercentage(ctx, bond.Token, bond.TranchesPaid)
+		if !found {
+			return nil, sdkerrors.Wrapf(types.ErrOutcomePaymentNotAttested,
+				"outcome payment %d", bond.TranchesPaid)
+		}
+		payment = types.ScaleCoinsByPercentage(payment, attestedPercentage)
+	}
+
+	// If nothing is left to be paid once the payment is scaled (e.g. it was
+	// attested as 0%), the outcome failed. Nothing is paid, but the payment
+	// still counts as made (and settles the bond if it is the last one), and
+	// an outcome_failed event is emitted instead of a make_outcome_payment one
+	eventType := types.EventTypeMakeOutcomePayment
+	if payment.Empty() {
+		eventType = types.EventTypeOutcomeFailed
+	}
+
+	// Send outcome payment to reserve, or start distributing it to the
+	// holders (once their balances have been recorded)
+	var err error
+	if payment.Empty() {
+		// Nothing to be paid
+	} else if distribute {
+		err = keeper.DistributeOutcomeTranche(ctx, bond.Token,
+			bond.TranchesPaid, msg.Sender, payment)
+	} else {
+		err = keeper.DepositReserve(ctx, bond.Token, msg.Sender, payment)
+	}
 	if err != nil {
 		return nil, err
 	}
-
-	// Set bond state to SETTLE
-	keeper.SetBondState(ctx, bond.Token, types.SettleState)
+	tranchesPaid := bond.TranchesPaid
+	if bond.HasOutcomeTranches() {
+		tranchesPaid++
+		keeper.SetTranchesPaid(ctx, bond.Token, tranchesPaid)
+	}
 
 	ctx.EventManager().EmitEvents(sdk.Events{
 		sdk.NewEvent(
-			types.EventTypeMakeOutcomePayment,
+			eventType,
 			sdk.NewAttribute(types.AttributeKeyBond, msg.BondToken),
 			sdk.NewAttribute(types.AttributeKeyAddress, msg.Sender.String()),
+			sdk.NewAttribute(sdk.AttributeKeyAmount, payment.String()),
+			sdk.NewAttribute(types.AttributeKeyAttestedPercentage, attestedPercentage.String()),
+			sdk.NewAttribute(types.AttributeKeyTranchesPaid, fmt.Sprint(tranchesPaid)),
 		),
 		sdk.NewEvent(
 			sdk.EventTypeMessage,
@@ -524,9 +820,39 @@ func handleMsgMakeOutcomePayment(ctx sdk.Context, keeper keeper.Keeper, msg type
 		),
 	})
 
+	// Set bond state to SETTLE, refund any pending orders and snapshot the
+	// balances of the bond's holders
+	if settle {
+		err = settleBond(ctx, keeper, bond.Token)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
 }
 
+// settleBond settles the bond, which refunds its pending orders and starts a
+// snapshot of its holders, whose balances are recorded at the end of this and
+// the following blocks (after which its reserve is distributed to them if the
+// bond does so automatically), and emits a settle_bond event.
+func settleBond(ctx sdk.Context, keeper keeper.Keeper, token string) error {
+	snapshot, err := keeper.SettleBond(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		types.EventTypeSettleBond,
+		sdk.NewAttribute(types.AttributeKeyBond, token),
+		sdk.NewAttribute(types.AttributeKeyTranchesPaid, fmt.Sprint(keeper.MustGetBond(ctx, token).TranchesPaid)),
+		sdk.NewAttribute(types.AttributeKeySnapshotHeight, fmt.Sprint(snapshot.Height)),
+		sdk.NewAttribute(types.AttributeKeyClaimDeadline, fmt.Sprint(snapshot.ClaimDeadline)),
+	))
+
+	return nil
+}
+
 func handleMsgWithdrawShare(ctx sdk.Context, keeper keeper.Keeper, msg types.MsgWithdrawShare) (*sdk.Result, error) {
 
 	bond, found := keeper.GetBond(ctx, msg.BondToken)
@@ -539,53 +865,512 @@ func handleMsgWithdrawShare(ctx sdk.Context, keeper keeper.Keeper, msg types.Msg
 		return nil, sdkerrors.Wrap(types.ErrInvalidStateForAction, bond.State)
 	}
 
-	// Get number of bond tokens owned by the recipient
-	bondTokensOwnedAmount := keeper.BankKeeper.GetCoins(ctx, msg.Recipient).AmountOf(msg.BondToken)
-	if bondTokensOwnedAmount.IsZero() {
-		return nil, sdkerrors.Wrap(types.ErrNoBondTokensOwned, bondTokensOwnedAmount.String())
+	// Withdraw the share for the amount specified, or the entire remaining
+	// share (based on the holder snapshot taken when the bond was settled)
+	amount := sdk.ZeroInt()
+	if !msg.Amount.Empty() {
+		amount = msg.Amount.AmountOf(bond.Token)
+	}
+	reserveOwed, withdrawn, err := keeper.WithdrawShare(ctx, bond.Token, msg.Recipient, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			types.EventTypeWithdrawShare,
+			sdk.NewAttribute(types.AttributeKeyBond, msg.BondToken),
+			sdk.NewAttribute(types.AttributeKeyAddress, msg.Recipient.String()),
+			sdk.NewAttribute(types.AttributeKeyWithdrawnShare, withdrawn.String()),
+			sdk.NewAttribute(sdk.AttributeKeyAmount, reserveOwed.String()),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Recipient.String()),
+		),
+	})
+
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
+
+func handleMsgSettleBond(ctx sdk.Context, keeper keeper.Keeper, msg types.MsgSettleBond) (*sdk.Result, error) {
+
+	bond, found := keeper.GetBond(ctx, msg.BondToken)
+	if !found {
+		return nil, sdkerrors.Wrap(types.ErrBondDoesNotExist, msg.BondToken)
+	}
+
+	if !bond.SignersEqualTo(msg.Signers) {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, "list of signers does not match the one in the bond")
+	}
+
+	// Only bonds that pay their outcome in tranches can be settled early,
+	// since the outcome payment of any other bond is what settles it. Any
+	// quarantined bond can be settled, so that its holders can withdraw their
+	// shares of its reserve
+	if bond.State != types.OpenState && bond.State != types.QuarantineState {
+		return nil, sdkerrors.Wrap(types.ErrInvalidStateForAction, bond.State)
+	} else if bond.State == types.OpenState && !bond.HasOutcomeTranches() {
+		return nil, sdkerrors.Wrap(types.ErrBondHasNoOutcomeTranches, msg.BondToken)
+	}
+
+	logger := keeper.Logger(ctx)
+	logger.Info(fmt.Sprintf("bond %s settled by %s after %d of %d outcome tranches",
+		msg.BondToken, msg.Settler.String(), bond.TranchesPaid, len(bond.OutcomeTranches)))
+
+	err := settleBond(ctx, keeper, bond.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		sdk.EventTypeMessage,
+		sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+		sdk.NewAttribute(sdk.AttributeKeySender, msg.Settler.String()),
+	))
+
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
+
+func handleMsgLimitBuy(ctx sdk.Context, keeper keeper.Keeper, msg types.MsgLimitBuy) (*sdk.Result, error) {
+
+	token := msg.Amount.Denom
+	bond, found := keeper.GetBond(ctx, token)
+	if !found {
+		return nil, sdkerrors.Wrap(types.ErrBondDoesNotExist, token)
+	}
+
+	// Check function type is not swapper, current state is HATCH/OPEN, order
+	// amount not below the minimum, order quantity limits and max supply not
+	// exceeded, and expiry height
+	minAmount := sdk.NewIntFromUint64(keeper.GetParams(ctx).MinLimitOrderAmount)
+	if types.MustGetFunction(bond.FunctionType).IsLiquidityPool() {
+		return nil, sdkerrors.Wrap(types.ErrFunctionNotAvailableForFunctionType, bond.FunctionType)
+	} else if bond.State != types.OpenState && bond.State != types.HatchState {
+		return nil, sdkerrors.Wrap(types.ErrInvalidStateForAction, bond.State)
+	} else if msg.Amount.Amount.LT(minAmount) {
+		return nil, sdkerrors.Wrapf(types.ErrLimitOrderAmountTooSmall, "%s < %s", msg.Amount.Amount, minAmount)
+	} else if bond.AnyOrderQuantityLimitsExceeded(sdk.Coins{msg.Amount}) {
+		return nil, sdkerrors.Wrap(types.ErrOrderQuantityLimitExceeded, msg.Amount.String())
+	} else if bond.MaxSupply.IsLT(msg.Amount) {
+		return nil, sdkerrors.Wrap(types.ErrCannotMintMoreThanMaxSupply, bond.MaxSupply.String())
+	} else if msg.ExpiryHeight <= ctx.BlockHeight() {
+		return nil, sdkerrors.Wrapf(types.ErrExpiryHeightMustBeInFuture, "%d", msg.ExpiryHeight)
+	} else if maxExpiry := keeper.GetParams(ctx).MaxLimitOrderExpiry; uint64(msg.ExpiryHeight-ctx.BlockHeight()) > maxExpiry {
+		return nil, sdkerrors.Wrapf(types.ErrExpiryHeightTooFar, "%d blocks", maxExpiry)
+	}
+
+	// Take the most that the buy can cost at the limit price (enforces escrow <= balance)
+	escrow := bond.GetLimitBuyEscrow(msg.Amount.Amount, msg.LimitPrice)
+	err := keeper.SupplyKeeper.SendCoinsFromAccountToModule(ctx, msg.Buyer,
+		types.BatchesIntermediaryAccount, escrow)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add limit buy to order book
+	order := keeper.AddLimitOrder(ctx, types.NewLimitOrder(types.LimitBuyOrderType,
+		msg.Buyer, msg.Amount, msg.LimitPrice, escrow, msg.ExpiryHeight))
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			types.EventTypeLimitBuy,
+			sdk.NewAttribute(types.AttributeKeyBond, msg.Amount.Denom),
+			sdk.NewAttribute(types.AttributeKeyOrderId, fmt.Sprint(order.Id)),
+			sdk.NewAttribute(sdk.AttributeKeyAmount, msg.Amount.Amount.String()),
+			sdk.NewAttribute(types.AttributeKeyLimitPrice, msg.LimitPrice.String()),
+			sdk.NewAttribute(types.AttributeKeyExpiryHeight, fmt.Sprint(msg.ExpiryHeight)),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Buyer.String()),
+		),
+	})
+
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
+
+func handleMsgLimitSell(ctx sdk.Context, keeper keeper.Keeper, msg types.MsgLimitSell) (*sdk.Result, error) {
+
+	token := msg.Amount.Denom
+	bond, found := keeper.GetBond(ctx, token)
+	if !found {
+		return nil, sdkerrors.Wrap(types.ErrBondDoesNotExist, token)
+	}
+
+	// Check function type is not swapper, sells allowed, current state is
+	// OPEN, order amount not below the minimum, order quantity limits not
+	// exceeded, and expiry height
+	minAmount := sdk.NewIntFromUint64(keeper.GetParams(ctx).MinLimitOrderAmount)
+	if types.MustGetFunction(bond.FunctionType).IsLiquidityPool() {
+		return nil, sdkerrors.Wrap(types.ErrFunctionNotAvailableForFunctionType, bond.FunctionType)
+	} else if !bond.AllowSells {
+		return nil, sdkerrors.Wrap(types.ErrBondDoesNotAllowSelling, token)
+	} else if bond.State != types.OpenState {
+		return nil, sdkerrors.Wrap(types.ErrInvalidStateForAction, bond.State)
+	} else if msg.Amount.Amount.LT(minAmount) {
+		return nil, sdkerrors.Wrapf(types.ErrLimitOrderAmountTooSmall, "%s < %s", msg.Amount.Amount, minAmount)
+	} else if bond.AnyOrderQuantityLimitsExceeded(sdk.Coins{msg.Amount}) {
+		return nil, sdkerrors.Wrap(types.ErrOrderQuantityLimitExceeded, msg.Amount.String())
+	} else if msg.ExpiryHeight <= ctx.BlockHeight() {
+		return nil, sdkerrors.Wrapf(types.ErrExpiryHeightMustBeInFuture, "%d", msg.ExpiryHeight)
+	} else if maxExpiry := keeper.GetParams(ctx).MaxLimitOrderExpiry; uint64(msg.ExpiryHeight-ctx.BlockHeight()) > maxExpiry {
+		return nil, sdkerrors.Wrapf(types.ErrExpiryHeightTooFar, "%d blocks", maxExpiry)
+	}
+
+	// Take bond tokens to be sold (enforces sellAmount <= balance); these are
+	// only burned once the limit sell is added to a batch
+	escrow := sdk.Coins{msg.Amount}
+	err := keeper.SupplyKeeper.SendCoinsFromAccountToModule(ctx, msg.Seller,
+		types.BatchesIntermediaryAccount, escrow)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add limit sell to order book
+	order := keeper.AddLimitOrder(ctx, types.NewLimitOrder(types.LimitSellOrderType,
+		msg.Seller, msg.Amount, msg.LimitPrice, escrow, msg.ExpiryHeight))
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			types.EventTypeLimitSell,
+			sdk.NewAttribute(types.AttributeKeyBond, msg.Amount.Denom),
+			sdk.NewAttribute(types.AttributeKeyOrderId, fmt.Sprint(order.Id)),
+			sdk.NewAttribute(sdk.AttributeKeyAmount, msg.Amount.Amount.String()),
+			sdk.NewAttribute(types.AttributeKeyLimitPrice, msg.LimitPrice.String()),
+			sdk.NewAttribute(types.AttributeKeyExpiryHeight, fmt.Sprint(msg.ExpiryHeight)),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Seller.String()),
+		),
+	})
+
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
+
+func handleMsgSpendBuy(ctx sdk.Context, keeper keeper.Keeper, msg types.MsgSpendBuy) (*sdk.Result, error) {
+
+	token := msg.BondToken
+	bond, found := keeper.GetBond(ctx, token)
+	if !found {
+		return nil, sdkerrors.Wrap(types.ErrBondDoesNotExist, token)
+	}
+
+	// Check current state is HATCH/OPEN and spend denoms match reserve
+	if bond.State != types.OpenState && bond.State != types.HatchState {
+		return nil, sdkerrors.Wrap(types.ErrInvalidStateForAction, bond.State)
+	} else if !bond.ReserveDenomsEqualTo(msg.Spend) {
+		return nil, sdkerrors.Wrapf(types.ErrReserveDenomsMismatch, "%s do not match reserve; expected: %s", msg.Spend.String(), strings.Join(bond.ReserveTokens, ","))
+	}
+
+	// Get the amount of bond tokens that the spend can buy (including fees)
+	amount, err := keeper.GetSpendBuyAmount(ctx, token, msg.Buyer, msg.Spend)
+	if err != nil {
+		return nil, err
+	} else if amount.IsZero() {
+		return nil, sdkerrors.Wrap(types.ErrSpendTooSmallToBuyAnyTokens, msg.Spend.String())
 	}
-	bondTokensOwned := sdk.NewCoin(msg.BondToken, bondTokensOwnedAmount)
 
-	// Send coins to be burned from recipient
-	err := keeper.SupplyKeeper.SendCoinsFromAccountToModule(
-		ctx, msg.Recipient, types.BondsMintBurnAccount, sdk.NewCoins(bondTokensOwned))
+	// Check that the order fits in the batch (or is to be rolled over)
+	rollOver, err := keeper.CheckBatchCapacity(ctx, token, amount)
 	if err != nil {
 		return nil, err
 	}
 
-	// Burn bond tokens
-	err = keeper.SupplyKeeper.BurnCoins(ctx, types.BondsMintBurnAccount,
-		sdk.NewCoins(sdk.NewCoin(msg.BondToken, bondTokensOwnedAmount)))
+	// Take spend (enforces spend <= balance)
+	err = keeper.SupplyKeeper.SendCoinsFromAccountToModule(ctx, msg.Buyer,
+		types.BatchesIntermediaryAccount, msg.Spend)
 	if err != nil {
 		return nil, err
 	}
 
-	// Calculate amount owned
-	remainingReserve := keeper.GetReserveBalances(ctx, bond.Token)
-	bondTokensShare := bondTokensOwnedAmount.ToDec().QuoInt(bond.CurrentSupply.Amount)
-	reserveOwedDec := sdk.NewDecCoinsFromCoins(remainingReserve...).MulDec(bondTokensShare)
-	reserveOwed, _ := reserveOwedDec.TruncateDecimal()
+	// Create order, with the spend as the max prices
+	order := types.NewSpendBuyOrder(msg.Buyer, sdk.NewCoin(token, amount), msg.Spend)
+
+	if rollOver {
+		// Roll buy order over to a following batch
+		order = keeper.RollOverBuyOrder(ctx, token, order)
+	} else {
+		// Get buy price and check if can add buy order to batch
+		buyPrices, sellPrices, err := keeper.GetUpdatedBatchPricesAfterBuy(ctx, token, order)
+		if err != nil {
+			return nil, err
+		}
+
+		// Add buy order to batch
+		order = keeper.AddBuyOrder(ctx, token, order, buyPrices, sellPrices)
+
+		// Cancel unfulfillable orders
+		_, err = keeper.CancelUnfulfillableOrders(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			types.EventTypeSpendBuy,
+			sdk.NewAttribute(types.AttributeKeyBond, token),
+			sdk.NewAttribute(types.AttributeKeyOrderId, fmt.Sprint(order.Id)),
+			sdk.NewAttribute(sdk.AttributeKeyAmount, amount.String()),
+			sdk.NewAttribute(types.AttributeKeySpend, msg.Spend.String()),
+			sdk.NewAttribute(types.AttributeKeyRolledOver, strconv.FormatBool(rollOver)),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Buyer.String()),
+		),
+	})
+
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
+
+func handleMsgCancelOrder(ctx sdk.Context, keeper keeper.Keeper, msg types.MsgCancelOrder) (*sdk.Result, error) {
+
+	bond, found := keeper.GetBond(ctx, msg.BondToken)
+	if !found {
+		return nil, sdkerrors.Wrap(types.ErrBondDoesNotExist, msg.BondToken)
+	}
+
+	// Check current state is HATCH/OPEN (i.e. bond is not quarantined)
+	if bond.State != types.OpenState && bond.State != types.HatchState {
+		return nil, sdkerrors.Wrap(types.ErrInvalidStateForAction, bond.State)
+	}
 
-	// Send coins owed to recipient
-	err = keeper.WithdrawReserve(ctx, bond.Token, msg.Recipient, reserveOwed)
+	// Cancel and refund order, and cancel any orders that become unfulfillable
+	orderType, err := keeper.CancelOrder(ctx, msg.BondToken, msg.Sender, msg.OrderId)
 	if err != nil {
 		return nil, err
 	}
 
-	// Update supply
-	keeper.SetCurrentSupply(ctx, bond.Token, bond.CurrentSupply.Sub(bondTokensOwned))
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			types.EventTypeCancelOrder,
+			sdk.NewAttribute(types.AttributeKeyBond, msg.BondToken),
+			sdk.NewAttribute(types.AttributeKeyOrderId, fmt.Sprint(msg.OrderId)),
+			sdk.NewAttribute(types.AttributeKeyOrderType, orderType),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Sender.String()),
+		),
+	})
+
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
+
+func handleMsgCommitOrder(ctx sdk.Context, keeper keeper.Keeper, msg types.MsgCommitOrder) (*sdk.Result, error) {
+
+	bond, found := keeper.GetBond(ctx, msg.BondToken)
+	if !found {
+		return nil, sdkerrors.Wrap(types.ErrBondDoesNotExist, msg.BondToken)
+	}
+
+	// Check current state is HATCH/OPEN and that bond accepts commitments
+	if bond.State != types.OpenState && bond.State != types.HatchState {
+		return nil, sdkerrors.Wrap(types.ErrInvalidStateForAction, bond.State)
+	} else if !bond.AcceptsOrderCommitments() {
+		return nil, sdkerrors.Wrap(types.ErrOrderCommitmentsNotAccepted, msg.BondToken)
+	}
+
+	// Check that the deposit is at least the minimum deposit (commitments do
+	// not count towards the batch's orders, so the batch is only checked for
+	// room once the order is revealed)
+	minDeposit := keeper.GetParams(ctx).MinCommitmentDeposit
+	if !msg.Deposit.IsAllGTE(minDeposit) {
+		return nil, sdkerrors.Wrapf(types.ErrDepositBelowMinimum,
+			"%s is less than %s", msg.Deposit, minDeposit)
+	}
+
+	// Take deposit from sender
+	err := keeper.SupplyKeeper.SendCoinsFromAccountToModule(ctx, msg.Sender,
+		types.BatchesIntermediaryAccount, msg.Deposit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add order commitment
+	commitment := keeper.AddOrderCommitment(ctx, types.NewOrderCommitment(
+		msg.BondToken, msg.Sender, msg.Hash, msg.Deposit))
 
 	ctx.EventManager().EmitEvents(sdk.Events{
 		sdk.NewEvent(
-			types.EventTypeWithdrawShare,
+			types.EventTypeCommitOrder,
 			sdk.NewAttribute(types.AttributeKeyBond, msg.BondToken),
-			sdk.NewAttribute(types.AttributeKeyAddress, msg.Recipient.String()),
-			sdk.NewAttribute(sdk.AttributeKeyAmount, reserveOwed.String()),
+			sdk.NewAttribute(types.AttributeKeyCommitmentId, fmt.Sprint(commitment.Id)),
+			sdk.NewAttribute(types.AttributeKeyCommitmentHash, msg.Hash.String()),
+			sdk.NewAttribute(types.AttributeKeyDeposit, msg.Deposit.String()),
 		),
 		sdk.NewEvent(
 			sdk.EventTypeMessage,
 			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
-			sdk.NewAttribute(sdk.AttributeKeySender, msg.Recipient.String()),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Sender.String()),
+		),
+	})
+
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
+
+func handleMsgRevealOrder(ctx sdk.Context, keeper keeper.Keeper, msg types.MsgRevealOrder) (*sdk.Result, error) {
+
+	bond, found := keeper.GetBond(ctx, msg.BondToken)
+	if !found {
+		return nil, sdkerrors.Wrap(types.ErrBondDoesNotExist, msg.BondToken)
+	}
+
+	// Check current state is HATCH/OPEN and that batch is in reveal phase
+	if bond.State != types.OpenState && bond.State != types.HatchState {
+		return nil, sdkerrors.Wrap(types.ErrInvalidStateForAction, bond.State)
+	} else if !keeper.MustGetBatchHeader(ctx, msg.BondToken).IsInRevealPhase() {
+		return nil, sdkerrors.Wrapf(types.ErrInvalidBatchPhase,
+			"batch for %s is not in the %s phase", msg.BondToken, types.RevealPhase)
+	}
+
+	// Check that commitment exists, belongs to sender, and matches the order
+	commitment, found := keeper.GetOrderCommitment(ctx, msg.BondToken, msg.CommitmentId)
+	if !found {
+		return nil, sdkerrors.Wrapf(types.ErrOrderCommitmentNotFound, "%d", msg.CommitmentId)
+	} else if !commitment.Address.Equals(msg.Sender) {
+		return nil, sdkerrors.Wrapf(types.ErrOrderNotOwnedBySender,
+			"commitment %d does not belong to %s", msg.CommitmentId, msg.Sender)
+	} else if !bytes.Equal(msg.Order.GetCommitmentHash(msg.Sender, msg.BondToken), commitment.Hash) {
+		return nil, sdkerrors.Wrapf(types.ErrOrderCommitmentHashMismatch, "%d", msg.CommitmentId)
+	}
+
+	// Remove commitment and return deposit to sender
+	keeper.RemoveOrderCommitment(ctx, commitment)
+	err := keeper.SupplyKeeper.SendCoinsFromModuleToAccount(ctx,
+		types.BatchesIntermediaryAccount, msg.Sender, commitment.Deposit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Place the revealed order as if it had been placed directly
+	orderMsg, err := msg.Order.ToMsg(msg.Sender, msg.BondToken)
+	if err != nil {
+		return nil, err
+	}
+	switch orderMsg := orderMsg.(type) {
+	case types.MsgBuy:
+		_, err = handleMsgBuy(ctx, keeper, orderMsg)
+	case types.MsgSell:
+		_, err = handleMsgSell(ctx, keeper, orderMsg)
+	case types.MsgSwap:
+		_, err = handleMsgSwap(ctx, keeper, orderMsg)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		types.EventTypeRevealOrder,
+		sdk.NewAttribute(types.AttributeKeyBond, msg.BondToken),
+		sdk.NewAttribute(types.AttributeKeyCommitmentId, fmt.Sprint(msg.CommitmentId)),
+		sdk.NewAttribute(types.AttributeKeyOrderType, msg.Order.OrderType),
+	))
+
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
+
+func handleMsgCreateRecurringOrder(ctx sdk.Context, keeper keeper.Keeper, msg types.MsgCreateRecurringOrder) (*sdk.Result, error) {
+
+	token := msg.BondToken
+	bond, found := keeper.GetBond(ctx, token)
+	if !found {
+		return nil, sdkerrors.Wrap(types.ErrBondDoesNotExist, token)
+	}
+
+	// Check function type is not swapper and end height (if any)
+	if types.MustGetFunction(bond.FunctionType).IsLiquidityPool() {
+		return nil, sdkerrors.Wrap(types.ErrFunctionNotAvailableForFunctionType, bond.FunctionType)
+	} else if msg.EndHeight != 0 && msg.EndHeight <= ctx.BlockHeight() {
+		return nil, sdkerrors.Wrapf(types.ErrEndHeightMustBeInFuture, "%d", msg.EndHeight)
+	}
+
+	// For buys, check current state is HATCH/OPEN and spend denoms match
+	// reserve. For sells, check sells allowed, current state is OPEN, and
+	// order quantity limits not exceeded.
+	if msg.OrderType == types.AttributeValueBuyOrder {
+		if bond.State != types.OpenState && bond.State != types.HatchState {
+			return nil, sdkerrors.Wrap(types.ErrInvalidStateForAction, bond.State)
+		} else if !bond.ReserveDenomsEqualTo(msg.Amount) {
+			return nil, sdkerrors.Wrapf(types.ErrReserveDenomsMismatch, "%s do not match reserve; expected: %s", msg.Amount.String(), strings.Join(bond.ReserveTokens, ","))
+		}
+	} else {
+		if !bond.AllowSells {
+			return nil, sdkerrors.Wrap(types.ErrBondDoesNotAllowSelling, token)
+		} else if bond.State != types.OpenState {
+			return nil, sdkerrors.Wrap(types.ErrInvalidStateForAction, bond.State)
+		} else if bond.AnyOrderQuantityLimitsExceeded(msg.Amount) {
+			return nil, sdkerrors.Wrap(types.ErrOrderQuantityLimitExceeded, msg.Amount.String())
+		}
+	}
+
+	// Check that the bond has not reached its maximum number of recurring orders
+	maxOrders := keeper.GetParams(ctx).MaxRecurringOrders
+	if keeper.GetBondRecurringOrderCount(ctx, token) >= maxOrders {
+		return nil, sdkerrors.Wrapf(types.ErrMaxRecurringOrdersReached,
+			"bond %s has %d recurring orders", token, maxOrders)
+	}
+
+	// Take budget (enforces budget <= balance)
+	err := keeper.SupplyKeeper.SendCoinsFromAccountToModule(ctx, msg.Owner,
+		types.BatchesIntermediaryAccount, msg.Budget)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add recurring order, which places its first order in the bond's next batch
+	order := keeper.AddRecurringOrder(ctx, types.NewRecurringOrder(msg.OrderType,
+		msg.Owner, token, msg.Amount, msg.Budget, msg.Interval, msg.EndHeight))
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			types.EventTypeCreateRecurringOrder,
+			sdk.NewAttribute(types.AttributeKeyBond, token),
+			sdk.NewAttribute(types.AttributeKeyRecurringOrderId, fmt.Sprint(order.Id)),
+			sdk.NewAttribute(types.AttributeKeyOrderType, msg.OrderType),
+			sdk.NewAttribute(sdk.AttributeKeyAmount, msg.Amount.String()),
+			sdk.NewAttribute(types.AttributeKeyBudget, msg.Budget.String()),
+			sdk.NewAttribute(types.AttributeKeyInterval, msg.Interval.String()),
+			sdk.NewAttribute(types.AttributeKeyEndHeight, fmt.Sprint(msg.EndHeight)),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Owner.String()),
+		),
+	})
+
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
+
+func handleMsgCancelRecurringOrder(ctx sdk.Context, keeper keeper.Keeper, msg types.MsgCancelRecurringOrder) (*sdk.Result, error) {
+
+	// Remove recurring order and refund remaining budget
+	order, err := keeper.CancelRecurringOrder(ctx, msg.Owner, msg.OrderId)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			types.EventTypeCancelRecurringOrder,
+			sdk.NewAttribute(types.AttributeKeyBond, order.BondToken),
+			sdk.NewAttribute(types.AttributeKeyRecurringOrderId, fmt.Sprint(order.Id)),
+			sdk.NewAttribute(types.AttributeKeyOrderType, order.OrderType),
+			sdk.NewAttribute(types.AttributeKeyReturnedToAddress, order.Budget.String()),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Owner.String()),
 		),
 	})
 
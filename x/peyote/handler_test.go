@@ -1,11 +1,17 @@
 package peyote_test
 
 import (
+	"errors"
+	"github.com/warmage-sports/peyote/x/outcomes"
 	"github.com/warmage-sports/peyote/x/peyote"
+	"github.com/warmage-sports/peyote/x/peyote/app"
 	"github.com/warmage-sports/peyote/x/peyote/internal/types"
 	"testing"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/x/bank"
+	"github.com/cosmos/cosmos-sdk/x/supply"
 
 	"github.com/stretchr/testify/require"
 )
@@ -64,7 +70,7 @@ func TestCreateValidAugmentedBondHatchState(t *testing.T) {
 
 	R0 := d0.Mul(sdk.OneDec().Sub(theta))
 	S0 := d0.Quo(p0)
-	V0 := types.Invariant(R0, S0, kappa.TruncateInt64())
+	V0, _ := types.Invariant(R0, S0, kappa)
 
 	require.Equal(t, R0, paramsMap["R0"])
 	require.Equal(t, S0, paramsMap["S0"])
@@ -72,6 +78,23 @@ func TestCreateValidAugmentedBondHatchState(t *testing.T) {
 	require.Len(t, bond.FunctionParameters, 7)
 }
 
+func TestCreateBondWithCurveThatOverflowsFails(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create bond with x^101 evaluated at a max supply of 10^18
+	msg := newValidMsgCreateBond()
+	msg.FunctionParameters = types.FunctionParams{
+		types.NewFunctionParam("m", sdk.NewDec(1)),
+		types.NewFunctionParam("n", sdk.NewDec(100)),
+		types.NewFunctionParam("c", sdk.NewDec(0))}
+	msg.MaxSupply = sdk.NewCoin(token, sdk.NewInt(1000000000000000000))
+	_, err := h(ctx, msg)
+
+	require.Error(t, err)
+	require.False(t, app.BondsKeeper.BondExists(ctx, token))
+}
+
 func TestCreateBondThatAlreadyExistsFails(t *testing.T) {
 	app, ctx := createTestApp(false)
 	h := peyote.NewHandler(app.BondsKeeper)
@@ -423,7 +446,7 @@ func TestBuyingABondCorrectlyPasses(t *testing.T) {
 
 	// Buy 2 tokens
 	_, err = h(ctx, newValidMsgBuy(2, 4000))
-	peyote.EndBlocker(ctx, app.BondsKeeper)
+	ctx = endBlock(app, ctx)
 
 	userBalance := app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress)
 	reserveBalance := app.BondsKeeper.GetReserveBalances(ctx, initToken)
@@ -463,7 +486,7 @@ func TestSellingABondWhichCannotBeSoldFails(t *testing.T) {
 
 	// Buy 10 tokens
 	h(ctx, newValidMsgBuy(10, 10000))
-	peyote.EndBlocker(ctx, app.BondsKeeper)
+	ctx = endBlock(app, ctx)
 
 	// Sell 10 tokens
 	bondPreSell := app.BondsKeeper.MustGetBond(ctx, token)
@@ -489,7 +512,7 @@ func TestSellBondExceedingOrderQuantityLimitFails(t *testing.T) {
 
 	// Buy 10 tokens
 	h(ctx, newValidMsgBuy(10, 10000))
-	peyote.EndBlocker(ctx, app.BondsKeeper)
+	ctx = endBlock(app, ctx)
 
 	// Sell 10 tokens
 	bondPreSell := app.BondsKeeper.MustGetBond(ctx, token)
@@ -513,7 +536,7 @@ func TestSellingABondWithAmountGreaterThanBalanceFails(t *testing.T) {
 
 	// Buy 10 tokens
 	h(ctx, newValidMsgBuy(10, 10000))
-	peyote.EndBlocker(ctx, app.BondsKeeper)
+	ctx = endBlock(app, ctx)
 
 	// Sell 11 tokens
 	bondPreSell := app.BondsKeeper.MustGetBond(ctx, token)
@@ -544,7 +567,7 @@ func TestSellingABondWhichSellerDoesNotOwnFails(t *testing.T) {
 
 	// Buy 10 tokens
 	h(ctx, newValidMsgBuy(10, 10000))
-	peyote.EndBlocker(ctx, app.BondsKeeper)
+	ctx = endBlock(app, ctx)
 
 	// Sell 11 of a different bond
 	msg := newValidMsgSell(0) // 0 amount replaced below
@@ -570,7 +593,7 @@ func TestSellingMoreTokensThanThereIsSupplyFails(t *testing.T) {
 
 	// Buy 10 tokens
 	h(ctx, newValidMsgBuy(10, 10000))
-	peyote.EndBlocker(ctx, app.BondsKeeper)
+	ctx = endBlock(app, ctx)
 
 	// Sell an amount greater than the max supply
 	bondPreSell := app.BondsKeeper.MustGetBond(ctx, token)
@@ -596,12 +619,12 @@ func TestSellingABondCorrectlyPasses(t *testing.T) {
 
 	// Buy 2 tokens
 	h(ctx, newValidMsgBuy(2, 4000))
-	peyote.EndBlocker(ctx, app.BondsKeeper)
+	ctx = endBlock(app, ctx)
 
 	// Sell 2 tokens
 	msg := newValidMsgSell(2)
 	_, err = h(ctx, msg)
-	peyote.EndBlocker(ctx, app.BondsKeeper)
+	ctx = endBlock(app, ctx)
 
 	userBalance := app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress)
 	reserveBalance := app.BondsKeeper.GetReserveBalances(ctx, initToken)
@@ -615,6 +638,83 @@ func TestSellingABondCorrectlyPasses(t *testing.T) {
 	require.Equal(t, sdk.ZeroInt(), currentSupply.Amount)
 }
 
+func TestSellingWithMinReturnsInNonReserveDenomFails(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create bond
+	h(ctx, newValidMsgCreateBond())
+
+	// Buy 2 tokens
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 4000)})
+	require.Nil(t, err)
+	_, err = h(ctx, newValidMsgBuy(2, 4000))
+	require.NoError(t, err)
+	ctx = endBlock(app, ctx)
+
+	msg := newValidMsgSell(2)
+	msg.MinReturns = sdk.NewCoins(sdk.NewInt64Coin(reserveToken2, 1))
+	_, err = h(ctx, msg)
+	require.True(t, errors.Is(err, types.ErrReserveDenomsMismatch))
+}
+
+func TestSellingWithMinReturnsNotMetFails(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create bond
+	h(ctx, newValidMsgCreateBond())
+
+	// Buy 10 tokens
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 10000)})
+	require.Nil(t, err)
+	_, err = h(ctx, newValidMsgBuy(10, 10000))
+	require.NoError(t, err)
+	ctx = endBlock(app, ctx)
+
+	// Selling 10 tokens returns 5000 minus 5 tx fee and 5 exit fee
+	msg := newValidMsgSell(10)
+	msg.MinReturns = sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 4991))
+	_, err = h(ctx, msg)
+	require.True(t, errors.Is(err, types.ErrMinReturnsNotMet))
+}
+
+func TestSellingCancelsSellsWithMinReturnsNoLongerMet(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create bond
+	h(ctx, newValidMsgCreateBond())
+
+	// Buy 10 tokens
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 10000)})
+	require.Nil(t, err)
+	_, err = h(ctx, newValidMsgBuy(10, 10000))
+	require.NoError(t, err)
+	ctx = endBlock(app, ctx)
+
+	// Selling 5 tokens on its own returns 4000 (minus fees)
+	msg := newValidMsgSell(5)
+	msg.MinReturns = sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 3000))
+	_, err = h(ctx, msg)
+	require.NoError(t, err)
+
+	// Selling 5 more lowers the returns of the first sell to 2500 (minus
+	// fees), so the first sell is cancelled and its tokens are returned
+	_, err = h(ctx, newValidMsgSell(5))
+	require.NoError(t, err)
+
+	batch := app.BondsKeeper.MustGetBatch(ctx, token)
+	require.True(t, batch.Sells[0].Cancelled)
+	require.False(t, batch.Sells[1].Cancelled)
+	require.Equal(t, sdk.NewInt64Coin(token, 5), batch.TotalSellAmount)
+	userBalance := app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress)
+	require.Equal(t, sdk.NewInt(5), userBalance.AmountOf(token))
+
+	// Batch prices recomputed, so the remaining sell returns 4000 (minus fees)
+	require.Equal(t, sdk.NewDec(800), batch.SellPrices.AmountOf(reserveToken))
+}
+
 func TestSwapBondDoesNotExistFails(t *testing.T) {
 	app, ctx := createTestApp(false)
 	h := peyote.NewHandler(app.BondsKeeper)
@@ -648,11 +748,11 @@ func TestSwapOrderInvalidReserveDenomsFails(t *testing.T) {
 		sdk.NewInt64Coin(reserveToken2, 10000),
 	)
 	h(ctx, buyMsg)
-	peyote.EndBlocker(ctx, app.BondsKeeper)
+	ctx = endBlock(app, ctx)
 
 	// Perform swap (invalid instead of reserveToken)
 	_, err = h(ctx, newValidMsgSwap("invalid", reserveToken2, 10))
-	peyote.EndBlocker(ctx, app.BondsKeeper)
+	ctx = endBlock(app, ctx)
 
 	userBalance := app.AccountKeeper.GetAccount(ctx, userAddress).GetCoins()
 	require.Error(t, err)
@@ -660,7 +760,7 @@ func TestSwapOrderInvalidReserveDenomsFails(t *testing.T) {
 
 	// Perform swap (invalid instead of reserveToken2)
 	_, err = h(ctx, newValidMsgSwap(reserveToken, "invalid", 10))
-	peyote.EndBlocker(ctx, app.BondsKeeper)
+	ctx = endBlock(app, ctx)
 
 	userBalance = app.AccountKeeper.GetAccount(ctx, userAddress).GetCoins()
 	require.Error(t, err)
@@ -691,10 +791,10 @@ func TestSwapOrderQuantityLimitExceededFails(t *testing.T) {
 		sdk.NewInt64Coin(reserveToken2, 10000),
 	)
 	h(ctx, buyMsg)
-	peyote.EndBlocker(ctx, app.BondsKeeper)
+	ctx = endBlock(app, ctx)
 
 	// Perform swap
-	msg := types.NewMsgSwap(userAddress, token, sdk.NewInt64Coin(reserveToken, 5), reserveToken2)
+	msg := types.NewMsgSwap(userAddress, token, sdk.NewInt64Coin(reserveToken, 5), reserveToken2, nil, nil)
 	_, err = h(ctx, msg)
 
 	userBalance := app.AccountKeeper.GetAccount(ctx, userAddress).GetCoins()
@@ -722,10 +822,10 @@ func TestSwapInvalidAmount(t *testing.T) {
 		sdk.NewInt64Coin(reserveToken2, 10000),
 	)
 	h(ctx, buyMsg)
-	peyote.EndBlocker(ctx, app.BondsKeeper)
+	ctx = endBlock(app, ctx)
 
 	// Perform swap
-	msg := types.NewMsgSwap(userAddress, token, tenReserveTokens, reserveToken2)
+	msg := types.NewMsgSwap(userAddress, token, tenReserveTokens, reserveToken2, nil, nil)
 	_, err = h(ctx, msg)
 
 	require.Error(t, err)
@@ -753,11 +853,11 @@ func TestSwapValidAmount(t *testing.T) {
 		sdk.NewInt64Coin(reserveToken2, 10000),
 	)
 	h(ctx, buyMsg)
-	peyote.EndBlocker(ctx, app.BondsKeeper)
+	ctx = endBlock(app, ctx)
 
 	// Perform swap
 	_, err = h(ctx, newValidMsgSwap(reserveToken, reserveToken2, 10))
-	peyote.EndBlocker(ctx, app.BondsKeeper)
+	ctx = endBlock(app, ctx)
 
 	userBalance := app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress)
 	reserveBalance := app.BondsKeeper.GetReserveBalances(ctx, initToken)
@@ -793,11 +893,11 @@ func TestSwapValidAmountReversed(t *testing.T) {
 		sdk.NewInt64Coin(reserveToken2, 10000),
 	)
 	h(ctx, buyMsg)
-	peyote.EndBlocker(ctx, app.BondsKeeper)
+	ctx = endBlock(app, ctx)
 
 	// Perform swap
 	_, err = h(ctx, newValidMsgSwap(reserveToken2, reserveToken, 10))
-	peyote.EndBlocker(ctx, app.BondsKeeper)
+	ctx = endBlock(app, ctx)
 
 	userBalance := app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress)
 	reserveBalance := app.BondsKeeper.GetReserveBalances(ctx, initToken)
@@ -811,6 +911,247 @@ func TestSwapValidAmountReversed(t *testing.T) {
 	require.Equal(t, sdk.OneInt(), feeBalance.AmountOf(reserveToken2))
 }
 
+func TestSwapWithMinReturnsNotMetIsCancelled(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create swapper bond
+	h(ctx, newValidMsgCreateSwapperBond())
+
+	// Add reserve tokens to user
+	coins := sdk.NewCoins(
+		sdk.NewInt64Coin(reserveToken, 100000),
+		sdk.NewInt64Coin(reserveToken2, 100000),
+	)
+	err := addCoinsToUser(app, ctx, coins)
+	require.Nil(t, err)
+
+	// Buy 2 tokens
+	buyMsg := newValidMsgBuy(2, 0) // 0 max prices replaced below
+	buyMsg.MaxPrices = sdk.NewCoins(
+		sdk.NewInt64Coin(reserveToken, 10000),
+		sdk.NewInt64Coin(reserveToken2, 10000),
+	)
+	h(ctx, buyMsg)
+	ctx = endBlock(app, ctx)
+
+	// Swapping 100res returns less than 100rez
+	msg := newValidMsgSwap(reserveToken, reserveToken2, 100)
+	msg.MinReturns = sdk.NewCoins(sdk.NewInt64Coin(reserveToken2, 100))
+	_, err = h(ctx, msg)
+	require.NoError(t, err)
+	ctx = endBlock(app, ctx)
+
+	// Swap cancelled and refunded
+	lastBatch := app.BondsKeeper.MustGetLastBatch(ctx, token)
+	require.True(t, lastBatch.Swaps[0].Cancelled)
+	userBalance := app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress)
+	require.Equal(t, sdk.NewInt(90000), userBalance.AmountOf(reserveToken))
+	require.Equal(t, sdk.NewInt(90000), userBalance.AmountOf(reserveToken2))
+}
+
+func TestLimitBuyWithExpiryHeightInPastFails(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+	ctx = ctx.WithBlockHeight(10)
+
+	// Create bond
+	h(ctx, newValidMsgCreateBond())
+
+	// Add reserve tokens to user
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 10000)})
+	require.Nil(t, err)
+
+	// Limit buy expiring at the current height
+	_, err = h(ctx, newValidMsgLimitBuy(10, 600, 10))
+	require.True(t, errors.Is(err, types.ErrExpiryHeightMustBeInFuture))
+}
+
+func TestLimitBuyWithExpiryHeightTooFarFails(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+	ctx = ctx.WithBlockHeight(10)
+
+	// Set maximum limit order expiry
+	params := app.BondsKeeper.GetParams(ctx)
+	params.MaxLimitOrderExpiry = 100
+	app.BondsKeeper.SetParams(ctx, params)
+
+	// Create bond
+	h(ctx, newValidMsgCreateBond())
+
+	// Add reserve tokens to user
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 10000)})
+	require.Nil(t, err)
+
+	// Limit buy expiring after the maximum expiry fails
+	_, err = h(ctx, newValidMsgLimitBuy(10, 600, 111))
+	require.True(t, errors.Is(err, types.ErrExpiryHeightTooFar))
+
+	// Limit buy expiring at the maximum expiry passes
+	_, err = h(ctx, newValidMsgLimitBuy(10, 600, 110))
+	require.NoError(t, err)
+}
+
+func TestLimitBuyWithoutSufficientFundsForEscrowFails(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create bond
+	h(ctx, newValidMsgCreateBond())
+
+	// Add reserve tokens to user (less than 10 * 600 plus fees)
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 6000)})
+	require.Nil(t, err)
+
+	_, err = h(ctx, newValidMsgLimitBuy(10, 600, 100))
+	require.Error(t, err)
+	require.Len(t, app.BondsKeeper.GetLimitOrderBook(ctx, token, true), 0)
+}
+
+func TestLimitBuyBelowMinimumAmountFails(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Set minimum limit order amount
+	params := app.BondsKeeper.GetParams(ctx)
+	params.MinLimitOrderAmount = 20
+	app.BondsKeeper.SetParams(ctx, params)
+
+	// Create bond
+	h(ctx, newValidMsgCreateBond())
+
+	// Add reserve tokens to user
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 10000)})
+	require.Nil(t, err)
+
+	// Limit buy 10 tokens (less than the minimum of 20)
+	_, err = h(ctx, newValidMsgLimitBuy(10, 600, 100))
+	require.True(t, errors.Is(err, types.ErrLimitOrderAmountTooSmall))
+	require.Len(t, app.BondsKeeper.GetLimitOrderBook(ctx, token, true), 0)
+}
+
+func TestLimitBuyCorrectlyPasses(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create bond
+	h(ctx, newValidMsgCreateBond())
+
+	// Add reserve tokens to user
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 10000)})
+	require.Nil(t, err)
+
+	// Limit buy 10 tokens at 600 (escrow is 6000 plus 6 fee)
+	_, err = h(ctx, newValidMsgLimitBuy(10, 600, 100))
+	require.NoError(t, err)
+
+	userBalance := app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress)
+	require.Equal(t, sdk.NewInt(3994), userBalance.AmountOf(reserveToken))
+	buys := app.BondsKeeper.GetLimitOrderBook(ctx, token, true)
+	require.Len(t, buys, 1)
+	require.Equal(t, sdk.NewInt(6006), buys[0].Escrow.AmountOf(reserveToken))
+}
+
+func TestLimitSellWhichCannotBeSoldFails(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create bond with sells not allowed
+	createMsg := newValidMsgCreateBond()
+	createMsg.AllowSells = false
+	h(ctx, createMsg)
+
+	_, err := h(ctx, newValidMsgLimitSell(10, 400, 100))
+	require.True(t, errors.Is(err, types.ErrBondDoesNotAllowSelling))
+}
+
+func TestLimitSellCorrectlyPasses(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create bond
+	h(ctx, newValidMsgCreateBond())
+
+	// Buy 10 tokens
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 10000)})
+	require.Nil(t, err)
+	_, err = h(ctx, newValidMsgBuy(10, 10000))
+	require.NoError(t, err)
+	ctx = endBlock(app, ctx)
+
+	// Limit sell 10 tokens, which are escrowed but not burned
+	_, err = h(ctx, newValidMsgLimitSell(10, 600, 100))
+	require.NoError(t, err)
+
+	userBalance := app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress)
+	require.True(t, userBalance.AmountOf(token).IsZero())
+	require.Len(t, app.BondsKeeper.GetLimitOrderBook(ctx, token, false), 1)
+	require.Equal(t, int64(10), app.SupplyKeeper.GetSupply(ctx).GetTotal().AmountOf(token).Int64())
+}
+
+func TestSpendBuyInvalidReserveDenomsFails(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create bond
+	h(ctx, newValidMsgCreateBond())
+
+	// Spend a token which is not a reserve token
+	msg := newValidMsgSpendBuy(1000)
+	msg.Spend = sdk.NewCoins(sdk.NewInt64Coin(reserveToken2, 1000))
+	_, err := h(ctx, msg)
+	require.True(t, errors.Is(err, types.ErrReserveDenomsMismatch))
+}
+
+func TestSpendBuyTooSmallToBuyAnyTokensFails(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create bond
+	h(ctx, newValidMsgCreateBond())
+
+	// Add reserve tokens to user
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 10000)})
+	require.Nil(t, err)
+
+	// Spend 100 (price for 1 token is 104 plus 1 fee)
+	_, err = h(ctx, newValidMsgSpendBuy(100))
+	require.True(t, errors.Is(err, types.ErrSpendTooSmallToBuyAnyTokens))
+}
+
+func TestSpendBuyCorrectlyPasses(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create bond
+	h(ctx, newValidMsgCreateBond())
+
+	// Add reserve tokens to user
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 10000)})
+	require.Nil(t, err)
+
+	// Spend 6000, which buys 10 tokens (5000 plus 5 fee, whereas 11 tokens
+	// cost 6424 plus 7 fee)
+	_, err = h(ctx, newValidMsgSpendBuy(6000))
+	require.NoError(t, err)
+
+	// Whole spend is taken and the buy is added to the batch
+	userBalance := app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress)
+	require.Equal(t, sdk.NewInt(4000), userBalance.AmountOf(reserveToken))
+	batch := app.BondsKeeper.MustGetBatch(ctx, token)
+	require.Len(t, batch.Buys, 1)
+	require.True(t, batch.Buys[0].Spend)
+	require.Equal(t, sdk.NewInt64Coin(token, 10), batch.Buys[0].Amount)
+	require.Equal(t, sdk.NewInt(6000), batch.Buys[0].MaxPrices.AmountOf(reserveToken))
+
+	// Remainder of the spend refunded when the batch is performed
+	ctx = endBlock(app, ctx)
+	userBalance = app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress)
+	require.Equal(t, sdk.NewInt(10), userBalance.AmountOf(token))
+	require.Equal(t, sdk.NewInt(4995), userBalance.AmountOf(reserveToken))
+}
+
 func TestMakeOutcomePayment(t *testing.T) {
 	app, ctx := createTestApp(false)
 	h := peyote.NewHandler(app.BondsKeeper)
@@ -826,7 +1167,7 @@ func TestMakeOutcomePayment(t *testing.T) {
 
 	// Make outcome payment
 	_, err = h(ctx, newValidMsgMakeOutcomePayment())
-	peyote.EndBlocker(ctx, app.BondsKeeper)
+	ctx = endBlock(app, ctx)
 
 	// Check that outcome payment is now in the bond reserve
 	userBalance := app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress)
@@ -864,17 +1205,21 @@ func TestWithdrawShare(t *testing.T) {
 	require.Nil(t, err)
 
 	// Simulate outcome payment by depositing (freshly minted) 100k into reserve
+	// and taking a snapshot of the holders' balances (which are recorded at
+	// the end of the block)
 	hundredK := sdk.NewCoins(sdk.NewCoin(reserveToken, sdk.NewInt(100000)))
 	err = app.SupplyKeeper.MintCoins(ctx, types.BondsMintBurnAccount, hundredK)
 	require.Nil(t, err)
 	err = app.BondsKeeper.DepositReserveFromModule(
 		ctx, bond.Token, types.BondsMintBurnAccount, hundredK)
 	require.Nil(t, err)
+	app.BondsKeeper.SnapshotHolders(ctx, token)
+	ctx = endBlock(app, ctx)
 
 	// User 1 withdraws share
 	_, err = h(ctx, newValidMsgWithdrawShareFrom(userAddress))
 	require.NoError(t, err)
-	peyote.EndBlocker(ctx, app.BondsKeeper)
+	ctx = endBlock(app, ctx)
 
 	// User 1 had 2 tokens out of the supply of 3 tokens, so user 1 gets 2/3
 	user1Balance := app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress)
@@ -891,7 +1236,7 @@ func TestWithdrawShare(t *testing.T) {
 	// User 2 withdraws share
 	_, err = h(ctx, newValidMsgWithdrawShareFrom(anotherAddress))
 	require.NoError(t, err)
-	peyote.EndBlocker(ctx, app.BondsKeeper)
+	ctx = endBlock(app, ctx)
 
 	// User 2 had 1 token out of the remaining supply of 1 token, so user 2 gets all remaining
 	user2Balance := app.BondsKeeper.BankKeeper.GetCoins(ctx, anotherAddress)
@@ -901,217 +1246,1551 @@ func TestWithdrawShare(t *testing.T) {
 	require.Equal(t, sdk.ZeroInt(), reserveBalance.AmountOf(reserveToken))
 }
 
-func TestDecrementRemainingBlocksCountAfterEndBlock(t *testing.T) {
+// settleBondWithHolders creates a bond with a 100k outcome payment, buys 2
+// tokens for user 1 and 1 token for user 2, and makes the outcome payment.
+func settleBondWithHolders(t *testing.T, autoDistribute bool) (*simapp.SimApp, sdk.Context, sdk.Handler) {
+	bondMsg := newValidMsgCreateBond()
+	bondMsg.AutoDistribute = autoDistribute
+	return settleBond(t, bondMsg)
+}
+
+func settleBondWithClaimWindow(t *testing.T, claimBlocks uint64,
+	sweepToCommunityPool bool) (*simapp.SimApp, sdk.Context, sdk.Handler) {
+	bondMsg := newValidMsgCreateBond()
+	bondMsg.ClaimBlocks = sdk.NewUint(claimBlocks)
+	bondMsg.SweepToCommunityPool = sweepToCommunityPool
+	return settleBond(t, bondMsg)
+}
+
+func settleBond(t *testing.T, bondMsg types.MsgCreateBond) (*simapp.SimApp, sdk.Context, sdk.Handler) {
 	app, ctx := createTestApp(false)
 	h := peyote.NewHandler(app.BondsKeeper)
 
-	two := sdk.NewUint(2)
-	one := sdk.NewUint(1)
+	// Create bond with 100k outcome payment and no fees
+	bondMsg.TxFeePercentage = sdk.ZeroDec()
+	bondMsg.ExitFeePercentage = sdk.ZeroDec()
+	bondMsg.OutcomePayment = sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 100000))
+	h(ctx, bondMsg)
+
+	// Buy 2 tokens for user 1 and 1 token for user 2
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 200000)})
+	require.Nil(t, err)
+	buyMsg := newValidMsgBuy(2, 10000)
+	_, err = h(ctx, buyMsg)
+	require.NoError(t, err)
+	buyMsg = newValidMsgBuy(1, 10000)
+	buyMsg.Recipient = anotherAddress
+	_, err = h(ctx, buyMsg)
+	require.NoError(t, err)
+	ctx = endBlock(app, ctx)
 
-	// Create bond
-	createMsg := newValidMsgCreateBond()
-	createMsg.BatchBlocks = two
-	h(ctx, createMsg)
+	// Make outcome payment (reserve is now the outcome payment plus the cost
+	// of the 3 tokens)
+	_, err = h(ctx, newValidMsgMakeOutcomePayment())
+	require.NoError(t, err)
+	return app, ctx, h
+}
 
-	require.Equal(t, two, app.BondsKeeper.MustGetBatch(ctx, token).BlocksRemaining)
-	peyote.EndBlocker(ctx, app.BondsKeeper)
-	require.Equal(t, one, app.BondsKeeper.MustGetBatch(ctx, token).BlocksRemaining)
+func TestMakeOutcomePaymentSnapshotsHolders(t *testing.T) {
+	app, ctx, h := settleBondWithHolders(t, false)
+	height := ctx.BlockHeight()
+
+	// Holders are only recorded at the end of the block, so no shares can be
+	// withdrawn until then
+	snapshot, found := app.BondsKeeper.GetSettlementSnapshot(ctx, token)
+	require.True(t, found)
+	require.True(t, snapshot.Recording.InProgress)
+	_, err := h(ctx, newValidMsgWithdrawShareFrom(userAddress))
+	require.True(t, errors.Is(err, types.ErrHoldersBeingRecorded))
+	ctx = endBlock(app, ctx)
+
+	snapshot, found = app.BondsKeeper.GetSettlementSnapshot(ctx, token)
+	require.True(t, found)
+	require.False(t, snapshot.Recording.InProgress)
+	require.Equal(t, uint64(2), snapshot.Recording.HolderCount)
+	require.Equal(t, height, snapshot.Height)
+	require.Equal(t, sdk.NewInt(3), snapshot.TotalBalance)
+
+	holders := app.BondsKeeper.GetHolderSnapshots(ctx, token)
+	require.Len(t, holders, 2)
+	holder, found := app.BondsKeeper.GetHolderSnapshot(ctx, token, userAddress)
+	require.True(t, found)
+	require.Equal(t, sdk.NewInt(2), holder.Balance)
+	require.True(t, holder.Withdrawn.IsZero())
 }
 
-func TestEndBlockerDoesNotPerformOrdersBeforeASpecifiedNumberOfBlocks(t *testing.T) {
+func TestMakeOutcomePaymentSnapshotsHoldersThatReceivedTokensBySend(t *testing.T) {
 	app, ctx := createTestApp(false)
 	h := peyote.NewHandler(app.BondsKeeper)
 
-	// Create bond with batch blocks set to 2
-	createMsg := newValidMsgCreateBond()
-	createMsg.BatchBlocks = sdk.NewUint(2)
-	h(ctx, createMsg)
+	// Create bond with 100k outcome payment and no fees
+	bondMsg := newValidMsgCreateBond()
+	bondMsg.TxFeePercentage = sdk.ZeroDec()
+	bondMsg.ExitFeePercentage = sdk.ZeroDec()
+	bondMsg.OutcomePayment = sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 100000))
+	_, err := h(ctx, bondMsg)
+	require.NoError(t, err)
 
-	// Add reserve tokens to user
-	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 1000000)})
+	// Buy 3 tokens for user 1
+	err = addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 200000)})
 	require.Nil(t, err)
+	_, err = h(ctx, newValidMsgBuy(3, 10000))
+	require.NoError(t, err)
+	ctx = endBlock(app, ctx)
 
-	// Buy 4 tokens
-	h(ctx, newValidMsgBuy(2, 10000))
-	h(ctx, newValidMsgBuy(2, 10000))
-	peyote.EndBlocker(ctx, app.BondsKeeper)
+	// User 1 sends 1 token to user 2 using the bank module
+	sendMsg := bank.NewMsgSend(userAddress, anotherAddress,
+		sdk.Coins{sdk.NewInt64Coin(token, 1)})
+	_, err = bank.NewHandler(app.BankKeeper)(ctx, sendMsg)
+	require.NoError(t, err)
 
-	require.Equal(t, len(app.BondsKeeper.MustGetBatch(ctx, token).Buys), 2)
+	// Make outcome payment
+	_, err = h(ctx, newValidMsgMakeOutcomePayment())
+	require.NoError(t, err)
+	ctx = endBlock(app, ctx)
+
+	// Both users are in the snapshot
+	snapshot, found := app.BondsKeeper.GetSettlementSnapshot(ctx, token)
+	require.True(t, found)
+	require.Equal(t, sdk.NewInt(3), snapshot.TotalBalance)
+	holder, found := app.BondsKeeper.GetHolderSnapshot(ctx, token, anotherAddress)
+	require.True(t, found)
+	require.Equal(t, sdk.NewInt(1), holder.Balance)
 }
 
-func TestEndBlockerPerformsOrdersAfterASpecifiedNumberOfBlocks(t *testing.T) {
+func TestMakeOutcomePaymentRefundsPendingOrders(t *testing.T) {
 	app, ctx := createTestApp(false)
 	h := peyote.NewHandler(app.BondsKeeper)
 
-	// Create bond
-	createMsg := newValidMsgCreateBond()
-	createMsg.BatchBlocks = sdk.NewUint(3)
-	h(ctx, createMsg)
+	// Create bond with 100k outcome payment and no fees
+	bondMsg := newValidMsgCreateBond()
+	bondMsg.TxFeePercentage = sdk.ZeroDec()
+	bondMsg.ExitFeePercentage = sdk.ZeroDec()
+	bondMsg.OutcomePayment = sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 100000))
+	_, err := h(ctx, bondMsg)
+	require.NoError(t, err)
 
-	// Add reserve tokens to user
-	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 1000000)})
+	// Buy 2 tokens for user 1
+	err = addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 200000)})
 	require.Nil(t, err)
+	_, err = h(ctx, newValidMsgBuy(2, 10000))
+	require.NoError(t, err)
+	ctx = endBlock(app, ctx)
+	reserveBalanceBefore := app.BankKeeper.GetCoins(ctx, userAddress).AmountOf(reserveToken)
 
-	// Buy 4 tokens
-	h(ctx, newValidMsgBuy(2, 10000))
-	h(ctx, newValidMsgBuy(2, 10000))
-
-	// Run EndBlocker for N times, where N = BatchBlocks
-	batchBlocksInt := int(createMsg.BatchBlocks.Uint64())
-	for i := 0; i <= batchBlocksInt; i++ {
-		peyote.EndBlocker(ctx, app.BondsKeeper)
-	}
+	// Queue a buy for 1 more token, and make the outcome payment before the
+	// batch is performed
+	_, err = h(ctx, newValidMsgBuy(1, 10000))
+	require.NoError(t, err)
+	_, err = h(ctx, newValidMsgMakeOutcomePayment())
+	require.NoError(t, err)
 
-	// Buys have been performed
-	require.Equal(t, 0, len(app.BondsKeeper.MustGetBatch(ctx, token).Buys))
+	// Buy was cancelled and refunded, so only the outcome payment was paid
+	buys := app.BondsKeeper.GetBatchBuyOrders(ctx, token)
+	require.Len(t, buys, 1)
+	require.True(t, buys[0].IsCancelled())
+	require.Equal(t, uint64(0), app.BondsKeeper.MustGetBatchHeader(ctx, token).OrderCount)
+	reserveBalance := app.BankKeeper.GetCoins(ctx, userAddress).AmountOf(reserveToken)
+	require.Equal(t, reserveBalanceBefore.SubRaw(100000), reserveBalance)
+
+	// Batch is still performed without the cancelled buy
+	ctx = endBlock(app, ctx)
+	require.Equal(t, sdk.NewInt(2), app.BankKeeper.GetCoins(ctx, userAddress).AmountOf(token))
+
+	// Snapshot does not include the cancelled buy
+	snapshot, found := app.BondsKeeper.GetSettlementSnapshot(ctx, token)
+	require.True(t, found)
+	require.Equal(t, sdk.NewInt(2), snapshot.TotalBalance)
 }
 
-func TestEndBlockerAugmentedFunction(t *testing.T) {
+func openBondWithTranches(t *testing.T, tranches []types.OutcomeTranche) (*simapp.SimApp, sdk.Context, sdk.Handler) {
 	app, ctx := createTestApp(false)
 	h := peyote.NewHandler(app.BondsKeeper)
 
-	// Create bond with augmented function type
-	createMsg := newValidMsgCreateAugmentedBond()
-	h(ctx, createMsg)
+	// Create bond with outcome tranches and no fees
+	bondMsg := newValidMsgCreateBond()
+	bondMsg.TxFeePercentage = sdk.ZeroDec()
+	bondMsg.ExitFeePercentage = sdk.ZeroDec()
+	bondMsg.OutcomeTranches = tranches
+	_, err := h(ctx, bondMsg)
+	require.NoError(t, err)
 
-	// Add reserve tokens to user
-	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 1000000)})
+	// Buy 2 tokens for user 1 and 1 token for user 2
+	err = addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 200000)})
 	require.Nil(t, err)
+	buyMsg := newValidMsgBuy(2, 10000)
+	_, err = h(ctx, buyMsg)
+	require.NoError(t, err)
+	buyMsg = newValidMsgBuy(1, 10000)
+	buyMsg.Recipient = anotherAddress
+	_, err = h(ctx, buyMsg)
+	require.NoError(t, err)
+	ctx = endBlock(app, ctx)
 
-	// Get bond to confirm allowSells==false, S0==50000, state==hatch
+	return app, ctx, h
+}
+
+func TestMakeOutcomePaymentPaysTranchesInOrder(t *testing.T) {
+	app, ctx, h := openBondWithTranches(t, []types.OutcomeTranche{
+		types.NewOutcomeTranche(sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 100)), nil, false),
+		types.NewOutcomeTranche(sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 30)), nil, true),
+		types.NewOutcomeTranche(sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 50)), anotherAddress, false),
+	})
+	reserve := app.BondsKeeper.GetReserveBalances(ctx, token).AmountOf(reserveToken)
+	userBalance := app.BankKeeper.GetCoins(ctx, userAddress).AmountOf(reserveToken)
+
+	// First tranche is sent to the reserve and does not settle the bond
+	_, err := h(ctx, newValidMsgMakeOutcomePayment())
+	require.NoError(t, err)
 	bond := app.BondsKeeper.MustGetBond(ctx, token)
-	require.False(t, bond.AllowSells)
-	require.Equal(t, sdk.NewDec(50000), bond.FunctionParameters.AsMap()["S0"])
-	require.Equal(t, types.HatchState, bond.State)
+	require.Equal(t, types.OpenState, bond.State)
+	require.Equal(t, uint64(1), bond.TranchesPaid)
+	require.Equal(t, reserve.AddRaw(100), app.BondsKeeper.GetReserveBalances(ctx, token).AmountOf(reserveToken))
 
-	// - Buy 49999 tokens; just below S0
-	// - Cannot buy 2 tokens in the meantime, since this exceeds S0
-	// - Cannot sell tokens (not even 1) in hatch state
-	_, err = h(ctx, newValidMsgBuy(49999, 100000))
+	// Second tranche is distributed to the holders (20 to user 1 and 10 to
+	// user 2) at the end of the block rather than sent to the reserve
+	_, err = h(ctx, newValidMsgMakeOutcomePayment())
 	require.NoError(t, err)
-	_, err = h(ctx, newValidMsgBuy(2, 100000))
-	require.Error(t, err)
-	_, err = h(ctx, newValidMsgSell(1))
+	bond = app.BondsKeeper.MustGetBond(ctx, token)
+	require.Equal(t, types.OpenState, bond.State)
+	require.Equal(t, uint64(2), bond.TranchesPaid)
+	require.Equal(t, userBalance.SubRaw(100+30), app.BankKeeper.GetCoins(ctx, userAddress).AmountOf(reserveToken))
+	_, found := app.BondsKeeper.GetTrancheDistribution(ctx, token, 1)
+	require.True(t, found)
+	ctx = endBlock(app, ctx)
+	require.Equal(t, reserve.AddRaw(100), app.BondsKeeper.GetReserveBalances(ctx, token).AmountOf(reserveToken))
+	require.Equal(t, userBalance.SubRaw(100+30-20), app.BankKeeper.GetCoins(ctx, userAddress).AmountOf(reserveToken))
+	require.Equal(t, sdk.NewInt(10), app.BankKeeper.GetCoins(ctx, anotherAddress).AmountOf(reserveToken))
+	require.False(t, app.BondsKeeper.HasTrancheDistributions(ctx, token))
+
+	// Last tranche can only be paid by its payer
+	_, err = h(ctx, newValidMsgMakeOutcomePayment())
 	require.Error(t, err)
-	peyote.EndBlocker(ctx, app.BondsKeeper)
+	require.True(t, errors.Is(err, sdkerrors.ErrUnauthorized))
 
-	// Confirm allowSells and state still the same
+	// Last tranche settles the bond
+	err = addCoinsToUser2(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 40)})
+	require.Nil(t, err)
+	_, err = h(ctx, types.NewMsgMakeOutcomePayment(anotherAddress, token))
+	require.NoError(t, err)
 	bond = app.BondsKeeper.MustGetBond(ctx, token)
-	require.False(t, bond.AllowSells)
-	require.Equal(t, types.HatchState, bond.State)
+	require.Equal(t, types.SettleState, bond.State)
+	require.Equal(t, uint64(3), bond.TranchesPaid)
+	require.Equal(t, reserve.AddRaw(150), app.BondsKeeper.GetReserveBalances(ctx, token).AmountOf(reserveToken))
+	_, found = app.BondsKeeper.GetSettlementSnapshot(ctx, token)
+	require.True(t, found)
 
-	// Buy 1 more token, to reach S0 => state is now open
-	h(ctx, newValidMsgBuy(1, 100000))
-	peyote.EndBlocker(ctx, app.BondsKeeper)
+	// No further payments can be made
+	_, err = h(ctx, newValidMsgMakeOutcomePayment())
+	require.Error(t, err)
+	require.True(t, errors.Is(err, types.ErrInvalidStateForAction))
+}
 
-	// Confirm allowSells==true, state==open
-	bond = app.BondsKeeper.MustGetBond(ctx, token)
-	require.True(t, bond.AllowSells)
-	require.Equal(t, types.OpenState, bond.State)
+func TestSettleBondBeforeAllTranchesArePaid(t *testing.T) {
+	app, ctx, h := openBondWithTranches(t, []types.OutcomeTranche{
+		types.NewOutcomeTranche(sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 100)), nil, false),
+		types.NewOutcomeTranche(sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 50)), nil, false),
+	})
+	_, err := h(ctx, newValidMsgMakeOutcomePayment())
+	require.NoError(t, err)
 
-	// Check user balance of tokens
-	balance := app.BankKeeper.GetCoins(ctx, userAddress).AmountOf(token).Int64()
-	require.Equal(t, int64(50000), balance)
+	// Bond cannot be settled by anyone other than its signers
+	_, err = h(ctx, types.NewMsgSettleBond(token, userAddress, []sdk.AccAddress{userAddress}))
+	require.Error(t, err)
+	require.Equal(t, types.OpenState, app.BondsKeeper.MustGetBond(ctx, token).State)
 
-	// Can now sell tokens (all 50,000 of them)
-	_, err = h(ctx, newValidMsgSell(50000))
+	// Bond is settled by its signers after the first tranche
+	_, err = h(ctx, types.NewMsgSettleBond(token, initCreator, initSigners))
 	require.NoError(t, err)
-	peyote.EndBlocker(ctx, app.BondsKeeper)
-	balance = app.BankKeeper.GetCoins(ctx, userAddress).AmountOf(token).Int64()
-	require.Equal(t, int64(0), balance)
+	bond := app.BondsKeeper.MustGetBond(ctx, token)
+	require.Equal(t, types.SettleState, bond.State)
+	require.Equal(t, uint64(1), bond.TranchesPaid)
+	ctx = endBlock(app, ctx)
+	snapshot, found := app.BondsKeeper.GetSettlementSnapshot(ctx, token)
+	require.True(t, found)
+	require.Equal(t, sdk.NewInt(3), snapshot.TotalBalance)
+
+	// The remaining tranche can no longer be paid
+	_, err = h(ctx, newValidMsgMakeOutcomePayment())
+	require.Error(t, err)
+	require.True(t, errors.Is(err, types.ErrInvalidStateForAction))
 }
 
-func TestEndBlockerAugmentedFunctionDecimalS0(t *testing.T) {
+func TestSettleBondWithoutOutcomeTranchesFails(t *testing.T) {
 	app, ctx := createTestApp(false)
 	h := peyote.NewHandler(app.BondsKeeper)
 
-	// Create bond with augmented function type
-	createMsg := newValidMsgCreateAugmentedBond()
-	h(ctx, createMsg)
+	bondMsg := newValidMsgCreateBond()
+	bondMsg.OutcomePayment = sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 100000))
+	_, err := h(ctx, bondMsg)
+	require.NoError(t, err)
 
-	// Add reserve tokens to user
-	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 1000000)})
+	// Bond that is settled by its outcome payment cannot be settled early
+	_, err = h(ctx, types.NewMsgSettleBond(token, initCreator, initSigners))
+	require.Error(t, err)
+	require.True(t, errors.Is(err, types.ErrBondHasNoOutcomeTranches))
+	require.Equal(t, types.OpenState, app.BondsKeeper.MustGetBond(ctx, token).State)
+}
+
+func TestMakeOutcomePaymentByUnauthorisedPayerFails(t *testing.T) {
+	app, ctx, h := openBondWithTranches(t, []types.OutcomeTranche{
+		types.NewOutcomeTranche(sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 100)), nil, false),
+	})
+	bond := app.BondsKeeper.MustGetBond(ctx, token)
+	bond.OutcomePayers = []sdk.AccAddress{anotherAddress}
+	app.BondsKeeper.SetBond(ctx, token, bond)
+
+	// Only the bond's outcome payers can make outcome payments
+	_, err := h(ctx, newValidMsgMakeOutcomePayment())
+	require.Error(t, err)
+	require.True(t, errors.Is(err, sdkerrors.ErrUnauthorized))
+	require.Equal(t, uint64(0), app.BondsKeeper.MustGetBond(ctx, token).TranchesPaid)
+
+	err = addCoinsToUser2(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 100)})
 	require.Nil(t, err)
+	_, err = h(ctx, types.NewMsgMakeOutcomePayment(anotherAddress, token))
+	require.NoError(t, err)
+	require.Equal(t, types.SettleState, app.BondsKeeper.MustGetBond(ctx, token).State)
+}
 
-	// Change bond's S0 parameter to 49999.5
-	decimalS0 := sdk.MustNewDecFromStr("49999.5")
+func TestMakeOutcomePaymentIsScaledByAttestations(t *testing.T) {
+	app, ctx, h := openBondWithTranches(t, []types.OutcomeTranche{
+		types.NewOutcomeTranche(sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 100)), nil, false),
+		types.NewOutcomeTranche(sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 50)), nil, false),
+	})
 	bond := app.BondsKeeper.MustGetBond(ctx, token)
-	for i, p := range bond.FunctionParameters {
-		if p.Param == "S0" {
-			bond.FunctionParameters[i].Value = decimalS0
-			break
-		}
-	}
-	app.BondsKeeper.SetBond(ctx, bond.Token, bond)
+	bond.Evaluators = []sdk.AccAddress{initCreator, anotherAddress}
+	app.BondsKeeper.SetBond(ctx, token, bond)
+	oh := outcomes.NewHandler(app.OutcomesKeeper)
+	reserve := app.BondsKeeper.GetReserveBalances(ctx, token).AmountOf(reserveToken)
 
-	// Get bond to confirm S0==49999.5, allowSells==false, state==hatch
-	bond = app.BondsKeeper.MustGetBond(ctx, token)
-	require.Equal(t, decimalS0, bond.FunctionParameters.AsMap()["S0"])
-	require.False(t, bond.AllowSells)
-	require.Equal(t, types.HatchState, bond.State)
+	// Outcome payment cannot be made before it has been attested
+	_, err := h(ctx, newValidMsgMakeOutcomePayment())
+	require.Error(t, err)
+	require.True(t, errors.Is(err, types.ErrOutcomePaymentNotAttested))
 
-	// Buy 49999 tokens; just below S0
-	h(ctx, newValidMsgBuy(49999, 100000))
-	peyote.EndBlocker(ctx, app.BondsKeeper)
+	// First tranche is scaled by the average attested percentage of 70%
+	_, err = oh(ctx, outcomes.NewMsgSubmitAttestation(
+		initCreator, token, outcomes.PartialResult, sdk.NewDec(40)))
+	require.NoError(t, err)
+	_, err = oh(ctx, outcomes.NewMsgSubmitAttestation(
+		anotherAddress, token, outcomes.SuccessResult, sdk.ZeroDec()))
+	require.NoError(t, err)
+	_, err = h(ctx, newValidMsgMakeOutcomePayment())
+	require.NoError(t, err)
+	require.Equal(t, reserve.AddRaw(70), app.BondsKeeper.GetReserveBalances(ctx, token).AmountOf(reserveToken))
 
-	// Confirm allowSells and state still the same
-	bond = app.BondsKeeper.MustGetBond(ctx, token)
-	require.False(t, bond.AllowSells)
-	require.Equal(t, types.HatchState, bond.State)
+	// Attestations of the first tranche do not apply to the second tranche
+	_, err = h(ctx, newValidMsgMakeOutcomePayment())
+	require.Error(t, err)
+	require.True(t, errors.Is(err, types.ErrOutcomePaymentNotAttested))
 
-	// Buy 1 more token, to exceed S0
-	h(ctx, newValidMsgBuy(1, 100000))
-	peyote.EndBlocker(ctx, app.BondsKeeper)
+	// Second tranche cannot be paid until both evaluators (a majority of
+	// the two) have attested it
+	_, err = oh(ctx, outcomes.NewMsgSubmitAttestation(
+		initCreator, token, outcomes.FailureResult, sdk.ZeroDec()))
+	require.NoError(t, err)
+	_, err = h(ctx, newValidMsgMakeOutcomePayment())
+	require.Error(t, err)
+	require.True(t, errors.Is(err, types.ErrOutcomePaymentNotAttested))
 
-	// Confirm allowSells==true, state==open
-	bond = app.BondsKeeper.MustGetBond(ctx, token)
-	require.True(t, bond.AllowSells)
+	// Second tranche is attested as a failure, so nothing is paid and the
+	// outcome is reported as failed, but the bond is still settled
+	_, err = oh(ctx, outcomes.NewMsgSubmitAttestation(
+		anotherAddress, token, outcomes.FailureResult, sdk.ZeroDec()))
+	require.NoError(t, err)
+	ctx = ctx.WithEventManager(sdk.NewEventManager())
+	res, err := h(ctx, newValidMsgMakeOutcomePayment())
+	require.NoError(t, err)
+	require.Equal(t, types.EventTypeOutcomeFailed, res.Events[0].Type)
+	require.Equal(t, reserve.AddRaw(70), app.BondsKeeper.GetReserveBalances(ctx, token).AmountOf(reserveToken))
+	require.Equal(t, types.SettleState, app.BondsKeeper.MustGetBond(ctx, token).State)
+}
+
+func TestWithdrawShareWithAmountCorrectlyPasses(t *testing.T) {
+	app, ctx, h := settleBondWithHolders(t, false)
+	ctx = endBlock(app, ctx)
+	reserve := app.BondsKeeper.GetReserveBalances(ctx, token).AmountOf(reserveToken)
+	userReserve := app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress).AmountOf(reserveToken)
+
+	// User 1 cannot withdraw more than the 2 tokens that they held
+	msg := newValidMsgWithdrawShareFrom(userAddress)
+	msg.Amount = sdk.NewCoins(sdk.NewInt64Coin(token, 3))
+	_, err := h(ctx, msg)
+	require.True(t, errors.Is(err, types.ErrAmountExceedsShare))
+
+	// User 1 withdraws the share of 1 of their 2 tokens (a third of the reserve)
+	msg.Amount = sdk.NewCoins(sdk.NewInt64Coin(token, 1))
+	_, err = h(ctx, msg)
+	require.NoError(t, err)
+	userBalance := app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress)
+	require.Equal(t, sdk.OneInt(), userBalance.AmountOf(token))
+	require.Equal(t, userReserve.Add(reserve.QuoRaw(3)), userBalance.AmountOf(reserveToken))
+	holder, _ := app.BondsKeeper.GetHolderSnapshot(ctx, token, userAddress)
+	require.Equal(t, sdk.OneInt(), holder.Withdrawn)
+
+	// User 1 withdraws the rest of their share
+	_, err = h(ctx, newValidMsgWithdrawShareFrom(userAddress))
+	require.NoError(t, err)
+	userBalance = app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress)
+	require.True(t, userBalance.AmountOf(token).IsZero())
+	_, err = h(ctx, newValidMsgWithdrawShareFrom(userAddress))
+	require.True(t, errors.Is(err, types.ErrNoBondTokensOwned))
+}
+
+func TestWithdrawShareIsNotAffectedByTransfersAfterSettlement(t *testing.T) {
+	app, ctx, h := settleBondWithHolders(t, false)
+	reserve := app.BondsKeeper.GetReserveBalances(ctx, token).AmountOf(reserveToken)
+	userReserve := app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress).AmountOf(reserveToken)
+
+	// User 2 transfers their token to user 1 after settlement, before the
+	// holders are recorded at the end of the block
+	err := app.BankKeeper.SendCoins(ctx, anotherAddress, userAddress,
+		sdk.NewCoins(sdk.NewInt64Coin(token, 1)))
+	require.NoError(t, err)
+	ctx = endBlock(app, ctx)
+
+	// User 1 is still only owed the share of the 2 tokens that they held
+	_, err = h(ctx, newValidMsgWithdrawShareFrom(userAddress))
+	require.NoError(t, err)
+	userBalance := app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress)
+	require.Equal(t, sdk.OneInt(), userBalance.AmountOf(token))
+	require.Equal(t, userReserve.Add(reserve.MulRaw(2).QuoRaw(3)), userBalance.AmountOf(reserveToken))
+
+	// User 2 is still owed the rest of the reserve despite not holding tokens
+	_, err = h(ctx, newValidMsgWithdrawShareFrom(anotherAddress))
+	require.NoError(t, err)
+	require.True(t, app.BondsKeeper.GetReserveBalances(ctx, token).IsZero())
+	require.Equal(t, sdk.OneInt(), app.BondsKeeper.MustGetBond(ctx, token).CurrentSupply.Amount)
+}
+
+func TestEndBlockerDistributesSharesOfSettledBond(t *testing.T) {
+	app, ctx, h := settleBondWithHolders(t, true)
+	reserve := app.BondsKeeper.GetReserveBalances(ctx, token).AmountOf(reserveToken)
+	userReserve := app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress).AmountOf(reserveToken)
+
+	// Both holders are recorded at the end of the block, after which the
+	// distribution starts
+	params := app.BondsKeeper.GetParams(ctx)
+	params.MaxDistributions = 2
+	app.BondsKeeper.SetParams(ctx, params)
+	ctx = endBlock(app, ctx)
+	distribution, found := app.BondsKeeper.GetShareDistribution(ctx, token)
+	require.True(t, found)
+	require.Equal(t, types.NewShareDistribution(token), distribution)
+
+	// Distribute to one holder per block
+	params.MaxDistributions = 1
+	app.BondsKeeper.SetParams(ctx, params)
+
+	// User 2 withdraws their share themselves before the distribution
+	_, err := h(ctx, newValidMsgWithdrawShareFrom(anotherAddress))
+	require.NoError(t, err)
+	_, found = app.BondsKeeper.GetShareDistribution(ctx, token)
+	require.True(t, found)
+
+	// Each holder is visited in a separate block, and only user 1 is paid
+	ctx = endBlock(app, ctx)
+	ctx = endBlock(app, ctx)
+	distribution, found = app.BondsKeeper.GetShareDistribution(ctx, token)
+	require.True(t, found)
+	require.Equal(t, uint64(1), distribution.HoldersPaid)
+
+	// All bond tokens were burned and the rest of the reserve paid to user 1
+	userBalance := app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress)
+	require.True(t, userBalance.AmountOf(token).IsZero())
+	require.Equal(t, userReserve.Add(reserve.Sub(reserve.QuoRaw(3))), userBalance.AmountOf(reserveToken))
+	require.True(t, app.BondsKeeper.GetReserveBalances(ctx, token).IsZero())
+	require.True(t, app.BondsKeeper.MustGetBond(ctx, token).CurrentSupply.IsZero())
+
+	// Distribution ends once no holders are left
+	ctx = endBlock(app, ctx)
+	_, found = app.BondsKeeper.GetShareDistribution(ctx, token)
+	require.False(t, found)
+	events := ctx.EventManager().Events()
+	require.Equal(t, types.EventTypeDistributionEnd, events[len(events)-1].Type)
+}
+
+func TestEndBlockerSweepsUnclaimedReserveAtClaimDeadline(t *testing.T) {
+	app, ctx, h := settleBondWithClaimWindow(t, 2, false)
+	snapshot, _ := app.BondsKeeper.GetSettlementSnapshot(ctx, token)
+	require.Equal(t, ctx.BlockHeight()+2, snapshot.ClaimDeadline)
+
+	// User 2 withdraws their share before the claim deadline, once the
+	// holders have been recorded
+	ctx = endBlock(app, ctx)
+	_, err := h(ctx, newValidMsgWithdrawShareFrom(anotherAddress))
+	require.NoError(t, err)
+	reserve := app.BondsKeeper.GetReserveBalances(ctx, token)
+
+	// Nothing is swept before the claim deadline
+	ctx = endBlock(app, ctx)
+	require.Equal(t, types.SettleState, app.BondsKeeper.MustGetBond(ctx, token).State)
+	require.True(t, app.BondsKeeper.BankKeeper.GetCoins(ctx, initFeeAddress).IsZero())
+
+	// The rest of the reserve is swept to the fee address at the deadline
+	ctx = endBlock(app, ctx)
+	require.Equal(t, types.ClosedState, app.BondsKeeper.MustGetBond(ctx, token).State)
+	require.True(t, app.BondsKeeper.GetReserveBalances(ctx, token).IsZero())
+	require.Equal(t, reserve, app.BondsKeeper.BankKeeper.GetCoins(ctx, initFeeAddress))
+
+	// User 1 can no longer withdraw their share
+	_, err = h(ctx, newValidMsgWithdrawShareFrom(userAddress))
+	require.True(t, errors.Is(err, types.ErrInvalidStateForAction))
+}
+
+func TestEndBlockerSweepsUnclaimedReserveToCommunityPool(t *testing.T) {
+	app, ctx, _ := settleBondWithClaimWindow(t, 1, true)
+	reserve := app.BondsKeeper.GetReserveBalances(ctx, token)
+	communityPool := app.BondsKeeper.DistrKeeper.GetFeePoolCommunityCoins(ctx)
+
+	ctx = endBlock(app, ctx)
+	ctx = endBlock(app, ctx)
+	require.Equal(t, types.ClosedState, app.BondsKeeper.MustGetBond(ctx, token).State)
+	require.True(t, app.BondsKeeper.BankKeeper.GetCoins(ctx, initFeeAddress).IsZero())
+	require.Equal(t, communityPool.Add(sdk.NewDecCoinsFromCoins(reserve...)...),
+		app.BondsKeeper.DistrKeeper.GetFeePoolCommunityCoins(ctx))
+}
+
+func TestCreateBondReusesTokenOfClosedBond(t *testing.T) {
+	bondMsg := newValidMsgCreateBond()
+	bondMsg.AutoDistribute = true
+	bondMsg.ClaimBlocks = sdk.NewUint(1)
+	app, ctx, h := settleBond(t, bondMsg)
+
+	// All shares are distributed (and bond tokens burned) before the deadline
+	ctx = endBlock(app, ctx)
+	ctx = endBlock(app, ctx)
+	require.Equal(t, types.ClosedState, app.BondsKeeper.MustGetBond(ctx, token).State)
+
+	// The token cannot be reused unless allowed
+	_, err := h(ctx, newValidMsgCreateBond())
+	require.True(t, errors.Is(err, types.ErrBondAlreadyExists))
+
+	params := app.BondsKeeper.GetParams(ctx)
+	params.AllowBondTokenReuse = true
+	app.BondsKeeper.SetParams(ctx, params)
+	app.BondsKeeper.ArchiveBatch(ctx, types.NewBatch(token, ctx.BlockHeight(), sdk.OneUint(), sdk.ZeroUint()))
+	app.OutcomesKeeper.SetAttestation(ctx, outcomes.NewAttestation(
+		token, 0, anotherAddress, outcomes.SuccessResult, sdk.NewDec(100), ctx.BlockHeight()))
+
+	_, err = h(ctx, newValidMsgCreateBond())
+	require.NoError(t, err)
+	bond := app.BondsKeeper.MustGetBond(ctx, token)
 	require.Equal(t, types.OpenState, bond.State)
+	require.True(t, bond.CurrentReserve.IsZero())
+	_, found := app.BondsKeeper.GetSettlementSnapshot(ctx, token)
+	require.False(t, found)
+	require.Len(t, app.BondsKeeper.GetBatchHistory(ctx, token, 1, 10), 0)
+	require.Len(t, app.OutcomesKeeper.GetAttestations(ctx, token), 0)
 }
 
-func TestEndBlockerAugmentedFunctionSmallBuys(t *testing.T) {
+func TestCreateBondCannotReuseTokenStillInCirculation(t *testing.T) {
+	app, ctx, h := settleBondWithClaimWindow(t, 1, false)
+	params := app.BondsKeeper.GetParams(ctx)
+	params.AllowBondTokenReuse = true
+	app.BondsKeeper.SetParams(ctx, params)
+
+	// The token of a bond that is not closed cannot be reused
+	_, err := h(ctx, newValidMsgCreateBond())
+	require.True(t, errors.Is(err, types.ErrBondAlreadyExists))
+
+	// Holders did not withdraw their shares so their tokens are not burned
+	ctx = endBlock(app, ctx)
+	ctx = endBlock(app, ctx)
+	require.Equal(t, types.ClosedState, app.BondsKeeper.MustGetBond(ctx, token).State)
+	_, err = h(ctx, newValidMsgCreateBond())
+	require.True(t, errors.Is(err, types.ErrCannotReuseBondToken))
+}
+
+func TestDecrementRemainingBlocksCountAfterEndBlock(t *testing.T) {
 	app, ctx := createTestApp(false)
 	h := peyote.NewHandler(app.BondsKeeper)
 
-	// Create bond with augmented function type, small params, and zero fees
-	createMsg := newValidMsgCreateAugmentedBond()
-	createMsg.FunctionParameters = types.FunctionParams{
-		types.NewFunctionParam("d0", sdk.MustNewDecFromStr("10.0")),
-		types.NewFunctionParam("p0", sdk.MustNewDecFromStr("1.0")),
-		types.NewFunctionParam("theta", sdk.MustNewDecFromStr("0.9")),
-		types.NewFunctionParam("kappa", sdk.MustNewDecFromStr("3.0"))}
-	createMsg.TxFeePercentage = sdk.ZeroDec()
-	createMsg.ExitFeePercentage = sdk.ZeroDec()
+	// Create bond
+	createMsg := newValidMsgCreateBond()
+	createMsg.BatchBlocks = sdk.NewUint(2)
+	h(ctx, createMsg)
+
+	batch := app.BondsKeeper.MustGetBatch(ctx, token)
+	require.Equal(t, int64(2), batch.BlocksRemaining(ctx.BlockHeight()))
+	ctx = endBlock(app, ctx)
+	batch = app.BondsKeeper.MustGetBatch(ctx, token)
+	require.Equal(t, int64(1), batch.BlocksRemaining(ctx.BlockHeight()))
+}
+
+func TestEndBlockerDoesNotPerformOrdersBeforeASpecifiedNumberOfBlocks(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create bond with batch blocks set to 2
+	createMsg := newValidMsgCreateBond()
+	createMsg.BatchBlocks = sdk.NewUint(2)
 	h(ctx, createMsg)
 
 	// Add reserve tokens to user
 	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 1000000)})
 	require.Nil(t, err)
 
-	// Get bond to confirm allowSells==false, S0==10, R0==1 state==hatch
-	bond := app.BondsKeeper.MustGetBond(ctx, token)
-	require.False(t, bond.AllowSells)
-	require.Equal(t, sdk.NewDec(10), bond.FunctionParameters.AsMap()["S0"])
-	require.Equal(t, sdk.NewDec(1), bond.FunctionParameters.AsMap()["R0"])
-	require.Equal(t, types.HatchState, bond.State)
+	// Buy 4 tokens
+	h(ctx, newValidMsgBuy(2, 10000))
+	h(ctx, newValidMsgBuy(2, 10000))
+	ctx = endBlock(app, ctx)
 
-	// Perform 10 buys of 1 token each
-	for i := 0; i < 10; i++ {
-		_, err := h(ctx, newValidMsgBuy(1, 1))
-		require.NoError(t, err)
-	}
-	peyote.EndBlocker(ctx, app.BondsKeeper)
+	require.Equal(t, len(app.BondsKeeper.MustGetBatch(ctx, token).Buys), 2)
+}
 
-	// Confirm allowSells==true, state==open
-	bond = app.BondsKeeper.MustGetBond(ctx, token)
-	require.True(t, bond.AllowSells)
-	require.Equal(t, types.OpenState, bond.State)
+func TestEndBlockerPerformsOrdersAfterASpecifiedNumberOfBlocks(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
 
-	// Confirm reserve balance is R0 [i.e. d0*(1-theta)] = 1
-	require.Equal(t, int64(1), bond.CurrentReserve[0].Amount.Int64())
+	// Create bond
+	createMsg := newValidMsgCreateBond()
+	createMsg.BatchBlocks = sdk.NewUint(3)
+	h(ctx, createMsg)
 
-	// Confirm fee address balance is d0*theta = 9
-	feeAddressBalance := app.BankKeeper.GetCoins(
-		ctx, bond.FeeAddress).AmountOf(reserveToken).Int64()
-	require.Equal(t, int64(9), feeAddressBalance)
+	// Add reserve tokens to user
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 1000000)})
+	require.Nil(t, err)
+
+	// Buy 4 tokens
+	h(ctx, newValidMsgBuy(2, 10000))
+	h(ctx, newValidMsgBuy(2, 10000))
+
+	// Run EndBlocker for N times, where N = BatchBlocks
+	batchBlocksInt := int(createMsg.BatchBlocks.Uint64())
+	for i := 0; i <= batchBlocksInt; i++ {
+		ctx = endBlock(app, ctx)
+	}
+
+	// Buys have been performed
+	require.Equal(t, 0, len(app.BondsKeeper.MustGetBatch(ctx, token).Buys))
+}
+
+func TestEndBlockerSkipsBatchesWithoutOrders(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create bond
+	createMsg := newValidMsgCreateBond()
+	createMsg.BatchBlocks = sdk.NewUint(2)
+	h(ctx, createMsg)
+
+	// Run EndBlocker for two batches' worth of blocks
+	for i := 0; i < 4; i++ {
+		ctx = endBlock(app, ctx)
+	}
+
+	// Batch was never performed, but is still on schedule
+	require.False(t, app.BondsKeeper.LastBatchExists(ctx, token))
+	batch := app.BondsKeeper.MustGetBatch(ctx, token)
+	require.Equal(t, int64(2), batch.BlocksRemaining(ctx.BlockHeight()))
+
+	// Add reserve tokens to user and buy 2 tokens
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 1000000)})
+	require.Nil(t, err)
+	_, err = h(ctx, newValidMsgBuy(2, 10000))
+	require.NoError(t, err)
+
+	// Buy is performed at the end of the batch
+	ctx = endBlock(app, ctx)
+	require.False(t, app.BondsKeeper.LastBatchExists(ctx, token))
+	ctx = endBlock(app, ctx)
+	require.True(t, app.BondsKeeper.LastBatchExists(ctx, token))
+	require.Equal(t, int64(2), app.BondsKeeper.MustGetBond(ctx, token).CurrentSupply.Amount.Int64())
+}
+
+func TestEndBlockerArchivesPerformedBatch(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create bond
+	h(ctx, newValidMsgCreateBond())
+
+	// Add reserve tokens to user and buy 2 tokens
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 1000000)})
+	require.Nil(t, err)
+	_, err = h(ctx, newValidMsgBuy(2, 10000))
+	require.NoError(t, err)
+	height := ctx.BlockHeight()
+	ctx = endBlock(app, ctx)
+
+	// Performed batch is in the batch history
+	records := app.BondsKeeper.GetBatchHistory(ctx, token, 1, 10)
+	require.Len(t, records, 1)
+	require.Equal(t, height, records[0].Height)
+	require.Equal(t, sdk.NewInt64Coin(token, 2), records[0].TotalBuyAmount)
+	require.Equal(t, uint64(1), records[0].Buys)
+	require.Equal(t, uint64(0), records[0].CancelledBuys)
+	require.Equal(t, app.BondsKeeper.MustGetLastBatch(ctx, token).BuyPrices, records[0].BuyPrices)
+}
+
+func TestEndBlockerQuarantinesBondWithInconsistentAccounting(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create two bonds
+	createMsg := newValidMsgCreateBond()
+	createMsg.BatchBlocks = sdk.OneUint()
+	h(ctx, createMsg)
+	createMsg.Token = token2
+	createMsg.MaxSupply = sdk.NewCoin(token2, createMsg.MaxSupply.Amount)
+	h(ctx, createMsg)
+
+	// Increase first bond's current supply without minting any tokens
+	app.BondsKeeper.SetCurrentSupply(ctx, token, sdk.NewInt64Coin(token, 10))
+
+	// Add buy orders to both bonds
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 1000000)})
+	require.Nil(t, err)
+	_, err = h(ctx, newValidMsgBuy(2, 10000))
+	require.NoError(t, err)
+	buyMsg := newValidMsgBuy(2, 10000)
+	buyMsg.Amount = sdk.NewInt64Coin(token2, 2)
+	_, err = h(ctx, buyMsg)
+	require.NoError(t, err)
+
+	ctx = endBlock(app, ctx)
+
+	// First bond quarantined, second bond processed as usual
+	require.Equal(t, types.QuarantineState, app.BondsKeeper.MustGetBond(ctx, token).State)
+	require.Equal(t, types.OpenState, app.BondsKeeper.MustGetBond(ctx, token2).State)
+	require.Equal(t, int64(2), app.BondsKeeper.MustGetBond(ctx, token2).CurrentSupply.Amount.Int64())
+
+	// Buy for the quarantined bond not performed, but cancelled and refunded
+	buys := app.BondsKeeper.GetBatchBuyOrders(ctx, token)
+	require.True(t, buys[0].Cancelled)
+	require.Equal(t, "bond quarantined", buys[0].CancelReason)
+	require.Equal(t, int64(10), app.BondsKeeper.MustGetBond(ctx, token).CurrentSupply.Amount.Int64())
+	require.True(t, app.BankKeeper.GetCoins(ctx, userAddress).AmountOf(token).IsZero())
+	require.True(t, app.BankKeeper.GetCoins(ctx, userAddress).AmountOf(reserveToken).GT(
+		sdk.NewInt(1000000-10000)))
+
+	// Orders for the quarantined bond are rejected
+	_, err = h(ctx, newValidMsgBuy(2, 10000))
+	require.True(t, errors.Is(err, types.ErrInvalidStateForAction))
+
+	// Quarantined bond can be settled by its signers
+	_, err = h(ctx, types.NewMsgSettleBond(token, initCreator, initSigners))
+	require.NoError(t, err)
+	require.Equal(t, types.SettleState, app.BondsKeeper.MustGetBond(ctx, token).State)
+}
+
+func TestEndBlockerMatchesLimitBuy(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create bond
+	h(ctx, newValidMsgCreateBond())
+
+	// Add reserve tokens to user
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 10000)})
+	require.Nil(t, err)
+
+	// Limit buy 10 tokens at 600 (buy price for 10 tokens is 500)
+	_, err = h(ctx, newValidMsgLimitBuy(10, 600, 100))
+	require.NoError(t, err)
+
+	ctx = endBlock(app, ctx)
+
+	// Tokens bought for 5000 plus 5 fee, and rest of escrow refunded
+	userBalance := app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress)
+	require.Equal(t, sdk.NewInt(10), userBalance.AmountOf(token))
+	require.Equal(t, sdk.NewInt(4995), userBalance.AmountOf(reserveToken))
+	require.Len(t, app.BondsKeeper.GetLimitOrderBook(ctx, token, true), 0)
+}
+
+func TestEndBlockerRefundsExpiredLimitBuy(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create bond
+	h(ctx, newValidMsgCreateBond())
+
+	// Add reserve tokens to user
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 10000)})
+	require.Nil(t, err)
+
+	// Limit buy 10 tokens at 400 (buy price for 10 tokens is 500)
+	_, err = h(ctx, newValidMsgLimitBuy(10, 400, 1))
+	require.NoError(t, err)
+
+	// Limit buy not matched and not yet expired
+	ctx = endBlock(app, ctx)
+	require.Len(t, app.BondsKeeper.GetLimitOrderBook(ctx, token, true), 1)
+
+	// Limit buy expired and escrow refunded
+	ctx = ctx.WithBlockHeight(1)
+	ctx = endBlock(app, ctx)
+	userBalance := app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress)
+	require.True(t, userBalance.AmountOf(token).IsZero())
+	require.Equal(t, sdk.NewInt(10000), userBalance.AmountOf(reserveToken))
+	require.Len(t, app.BondsKeeper.GetLimitOrderBook(ctx, token, true), 0)
+}
+
+func TestEndBlockerAugmentedFunction(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create bond with augmented function type
+	createMsg := newValidMsgCreateAugmentedBond()
+	h(ctx, createMsg)
+
+	// Add reserve tokens to user
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 1000000)})
+	require.Nil(t, err)
+
+	// Get bond to confirm allowSells==false, S0==50000, state==hatch
+	bond := app.BondsKeeper.MustGetBond(ctx, token)
+	require.False(t, bond.AllowSells)
+	require.Equal(t, sdk.NewDec(50000), bond.FunctionParameters.AsMap()["S0"])
+	require.Equal(t, types.HatchState, bond.State)
+
+	// - Buy 49999 tokens; just below S0
+	// - Cannot buy 2 tokens in the meantime, since this exceeds S0
+	// - Cannot sell tokens (not even 1) in hatch state
+	_, err = h(ctx, newValidMsgBuy(49999, 100000))
+	require.NoError(t, err)
+	_, err = h(ctx, newValidMsgBuy(2, 100000))
+	require.Error(t, err)
+	_, err = h(ctx, newValidMsgSell(1))
+	require.Error(t, err)
+	ctx = endBlock(app, ctx)
+
+	// Confirm allowSells and state still the same
+	bond = app.BondsKeeper.MustGetBond(ctx, token)
+	require.False(t, bond.AllowSells)
+	require.Equal(t, types.HatchState, bond.State)
+
+	// Buy 1 more token, to reach S0 => state is now open
+	h(ctx, newValidMsgBuy(1, 100000))
+	ctx = endBlock(app, ctx)
+
+	// Confirm allowSells==true, state==open
+	bond = app.BondsKeeper.MustGetBond(ctx, token)
+	require.True(t, bond.AllowSells)
+	require.Equal(t, types.OpenState, bond.State)
+
+	// Check user balance of tokens
+	balance := app.BankKeeper.GetCoins(ctx, userAddress).AmountOf(token).Int64()
+	require.Equal(t, int64(50000), balance)
+
+	// Can now sell tokens (all 50,000 of them)
+	_, err = h(ctx, newValidMsgSell(50000))
+	require.NoError(t, err)
+	ctx = endBlock(app, ctx)
+	balance = app.BankKeeper.GetCoins(ctx, userAddress).AmountOf(token).Int64()
+	require.Equal(t, int64(0), balance)
+}
+
+func TestEndBlockerAugmentedFunctionDecimalS0(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create bond with augmented function type
+	createMsg := newValidMsgCreateAugmentedBond()
+	h(ctx, createMsg)
+
+	// Add reserve tokens to user
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 1000000)})
+	require.Nil(t, err)
+
+	// Change bond's S0 parameter to 49999.5
+	decimalS0 := sdk.MustNewDecFromStr("49999.5")
+	bond := app.BondsKeeper.MustGetBond(ctx, token)
+	for i, p := range bond.FunctionParameters {
+		if p.Param == "S0" {
+			bond.FunctionParameters[i].Value = decimalS0
+			break
+		}
+	}
+	app.BondsKeeper.SetBond(ctx, bond.Token, bond)
+
+	// Get bond to confirm S0==49999.5, allowSells==false, state==hatch
+	bond = app.BondsKeeper.MustGetBond(ctx, token)
+	require.Equal(t, decimalS0, bond.FunctionParameters.AsMap()["S0"])
+	require.False(t, bond.AllowSells)
+	require.Equal(t, types.HatchState, bond.State)
+
+	// Buy 49999 tokens; just below S0
+	h(ctx, newValidMsgBuy(49999, 100000))
+	ctx = endBlock(app, ctx)
+
+	// Confirm allowSells and state still the same
+	bond = app.BondsKeeper.MustGetBond(ctx, token)
+	require.False(t, bond.AllowSells)
+	require.Equal(t, types.HatchState, bond.State)
+
+	// Buy 1 more token, to exceed S0
+	h(ctx, newValidMsgBuy(1, 100000))
+	ctx = endBlock(app, ctx)
+
+	// Confirm allowSells==true, state==open
+	bond = app.BondsKeeper.MustGetBond(ctx, token)
+	require.True(t, bond.AllowSells)
+	require.Equal(t, types.OpenState, bond.State)
+}
+
+func TestEndBlockerAugmentedFunctionSmallBuys(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create bond with augmented function type, small params, and zero fees
+	createMsg := newValidMsgCreateAugmentedBond()
+	createMsg.FunctionParameters = types.FunctionParams{
+		types.NewFunctionParam("d0", sdk.MustNewDecFromStr("10.0")),
+		types.NewFunctionParam("p0", sdk.MustNewDecFromStr("1.0")),
+		types.NewFunctionParam("theta", sdk.MustNewDecFromStr("0.9")),
+		types.NewFunctionParam("kappa", sdk.MustNewDecFromStr("3.0"))}
+	createMsg.TxFeePercentage = sdk.ZeroDec()
+	createMsg.ExitFeePercentage = sdk.ZeroDec()
+	h(ctx, createMsg)
+
+	// Add reserve tokens to user
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 1000000)})
+	require.Nil(t, err)
+
+	// Get bond to confirm allowSells==false, S0==10, R0==1 state==hatch
+	bond := app.BondsKeeper.MustGetBond(ctx, token)
+	require.False(t, bond.AllowSells)
+	require.Equal(t, sdk.NewDec(10), bond.FunctionParameters.AsMap()["S0"])
+	require.Equal(t, sdk.NewDec(1), bond.FunctionParameters.AsMap()["R0"])
+	require.Equal(t, types.HatchState, bond.State)
+
+	// Perform 10 buys of 1 token each
+	for i := 0; i < 10; i++ {
+		_, err := h(ctx, newValidMsgBuy(1, 1))
+		require.NoError(t, err)
+	}
+	ctx = endBlock(app, ctx)
+
+	// Confirm allowSells==true, state==open
+	bond = app.BondsKeeper.MustGetBond(ctx, token)
+	require.True(t, bond.AllowSells)
+	require.Equal(t, types.OpenState, bond.State)
+
+	// Confirm reserve balance is R0 [i.e. d0*(1-theta)] = 1
+	require.Equal(t, int64(1), bond.CurrentReserve[0].Amount.Int64())
+
+	// Confirm fee address balance is d0*theta = 9
+	feeAddressBalance := app.BankKeeper.GetCoins(
+		ctx, bond.FeeAddress).AmountOf(reserveToken).Int64()
+	require.Equal(t, int64(9), feeAddressBalance)
+}
+
+func TestCancelOrderBondDoesNotExistFails(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	_, err := h(ctx, types.NewMsgCancelOrder(userAddress, token, 1))
+	require.True(t, errors.Is(err, types.ErrBondDoesNotExist))
+}
+
+func TestCancelOrderSellCorrectlyPasses(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create bond
+	h(ctx, newValidMsgCreateBond())
+
+	// Buy 2 tokens (order 1)
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 4000)})
+	require.Nil(t, err)
+	_, err = h(ctx, newValidMsgBuy(2, 4000))
+	require.NoError(t, err)
+	ctx = endBlock(app, ctx)
+
+	// Sell 2 tokens (order 2)
+	_, err = h(ctx, newValidMsgSell(2))
+	require.NoError(t, err)
+	require.True(t, app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress).AmountOf(token).IsZero())
+
+	// Cancel sell
+	_, err = h(ctx, types.NewMsgCancelOrder(userAddress, token, 2))
+	require.NoError(t, err)
+
+	// Sell cancelled and bond tokens re-minted and returned to seller
+	batch := app.BondsKeeper.MustGetBatch(ctx, token)
+	require.True(t, batch.Sells[0].Cancelled)
+	require.True(t, batch.TotalSellAmount.IsZero())
+	require.Equal(t, sdk.NewInt(2), app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress).AmountOf(token))
+
+	// Bond accounting still consistent once the batch is processed
+	ctx = endBlock(app, ctx)
+	require.Equal(t, types.OpenState, app.BondsKeeper.MustGetBond(ctx, token).State)
+	require.Equal(t, sdk.NewInt64Coin(token, 2), app.BondsKeeper.MustGetBond(ctx, token).CurrentSupply)
+}
+
+func TestCancelOrderLimitBuyCorrectlyPasses(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create bond
+	h(ctx, newValidMsgCreateBond())
+
+	// Limit buy 10 tokens at 600 each (order 1)
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 10000)})
+	require.Nil(t, err)
+	_, err = h(ctx, newValidMsgLimitBuy(10, 600, 100))
+	require.NoError(t, err)
+	require.Len(t, app.BondsKeeper.GetLimitOrderBook(ctx, token, true), 1)
+
+	// Cancel limit buy by someone else fails
+	_, err = h(ctx, types.NewMsgCancelOrder(anotherAddress, token, 1))
+	require.True(t, errors.Is(err, types.ErrOrderNotOwnedBySender))
+
+	// Cancel limit buy
+	_, err = h(ctx, types.NewMsgCancelOrder(userAddress, token, 1))
+	require.NoError(t, err)
+
+	// Limit buy removed and escrow returned to buyer
+	_, found := app.BondsKeeper.GetLimitOrder(ctx, 1)
+	require.False(t, found)
+	require.Len(t, app.BondsKeeper.GetLimitOrderBook(ctx, token, true), 0)
+	require.Equal(t, sdk.NewInt(10000), app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress).AmountOf(reserveToken))
+}
+
+func TestCancelOrderSwapCorrectlyPasses(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create swapper bond
+	h(ctx, newValidMsgCreateSwapperBond())
+
+	// Add reserve tokens to user
+	coins := sdk.NewCoins(
+		sdk.NewInt64Coin(reserveToken, 100000),
+		sdk.NewInt64Coin(reserveToken2, 100000),
+	)
+	err := addCoinsToUser(app, ctx, coins)
+	require.Nil(t, err)
+
+	// Buy 2 tokens (initialises reserves, so not added to batch as an order)
+	buyMsg := newValidMsgBuy(2, 0) // 0 max prices replaced below
+	buyMsg.MaxPrices = sdk.NewCoins(
+		sdk.NewInt64Coin(reserveToken, 10000),
+		sdk.NewInt64Coin(reserveToken2, 10000),
+	)
+	h(ctx, buyMsg)
+	ctx = endBlock(app, ctx)
+
+	// Swap (order 1) and cancel by someone else
+	_, err = h(ctx, newValidMsgSwap(reserveToken, reserveToken2, 100))
+	require.NoError(t, err)
+	_, err = h(ctx, types.NewMsgCancelOrder(anotherAddress, token, 1))
+	require.True(t, errors.Is(err, types.ErrOrderNotOwnedBySender))
+
+	// Cancel swap
+	_, err = h(ctx, types.NewMsgCancelOrder(userAddress, token, 1))
+	require.NoError(t, err)
+	ctx = endBlock(app, ctx)
+
+	// Swap cancelled and refunded
+	lastBatch := app.BondsKeeper.MustGetLastBatch(ctx, token)
+	require.True(t, lastBatch.Swaps[0].Cancelled)
+	userBalance := app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress)
+	require.Equal(t, sdk.NewInt(90000), userBalance.AmountOf(reserveToken))
+	require.Equal(t, sdk.NewInt(90000), userBalance.AmountOf(reserveToken2))
+}
+
+func TestCommitOrderBondNotAcceptingCommitmentsFails(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create bond without reveal blocks
+	h(ctx, newValidMsgCreateBond())
+
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 10)})
+	require.Nil(t, err)
+	_, err = h(ctx, newValidMsgCommitOrder(newValidRevealedBuy(2, 4000, "salt"), 10))
+	require.True(t, errors.Is(err, types.ErrOrderCommitmentsNotAccepted))
+}
+
+func TestCommitOrderBelowMinimumDepositFails(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Set minimum commitment deposit
+	params := app.BondsKeeper.GetParams(ctx)
+	params.MinCommitmentDeposit = sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 10))
+	app.BondsKeeper.SetParams(ctx, params)
+
+	// Create bond that accepts commitments
+	h(ctx, newValidMsgCreateCommitRevealBond(false))
+
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 20)})
+	require.Nil(t, err)
+
+	// Deposit below the minimum fails
+	_, err = h(ctx, newValidMsgCommitOrder(newValidRevealedBuy(2, 4000, "salt"), 9))
+	require.True(t, errors.Is(err, types.ErrDepositBelowMinimum))
+
+	// Deposit at the minimum passes
+	_, err = h(ctx, newValidMsgCommitOrder(newValidRevealedBuy(2, 4000, "salt"), 10))
+	require.NoError(t, err)
+}
+
+func TestCommitAndRevealOrderCorrectlyPasses(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create bond with 3 batch blocks, of which the last is the reveal phase
+	h(ctx, newValidMsgCreateCommitRevealBond(false))
+
+	// Commit to buy of 2 tokens (commitment 1) with a deposit of 10
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 4010)})
+	require.Nil(t, err)
+	order := newValidRevealedBuy(2, 4000, "salt")
+	_, err = h(ctx, newValidMsgCommitOrder(order, 10))
+	require.NoError(t, err)
+	require.Equal(t, sdk.NewInt(4000), app.BankKeeper.GetCoins(ctx, userAddress).AmountOf(reserveToken))
+	require.Len(t, app.BondsKeeper.GetOrderCommitments(ctx, token), 1)
+
+	// Order cannot be revealed in the commit phase
+	_, err = h(ctx, types.NewMsgRevealOrder(userAddress, token, 1, order))
+	require.True(t, errors.Is(err, types.ErrInvalidBatchPhase))
+
+	// Reveal phase starts once only the reveal block remains
+	ctx = endBlock(app, ctx)
+	require.Equal(t, types.CommitPhase, app.BondsKeeper.MustGetBatch(ctx, token).Phase)
+	ctx = endBlock(app, ctx)
+	require.Equal(t, types.RevealPhase, app.BondsKeeper.MustGetBatch(ctx, token).Phase)
+
+	// Orders and commitments cannot be placed in the reveal phase
+	_, err = h(ctx, newValidMsgBuy(2, 4000))
+	require.True(t, errors.Is(err, types.ErrInvalidBatchPhase))
+	_, err = h(ctx, newValidMsgCommitOrder(order, 10))
+	require.True(t, errors.Is(err, types.ErrInvalidBatchPhase))
+
+	// Commitment can only be revealed by its owner and with the same order
+	_, err = h(ctx, types.NewMsgRevealOrder(anotherAddress, token, 1, order))
+	require.True(t, errors.Is(err, types.ErrOrderNotOwnedBySender))
+	_, err = h(ctx, types.NewMsgRevealOrder(userAddress, token, 1, newValidRevealedBuy(2, 4000, "other")))
+	require.True(t, errors.Is(err, types.ErrOrderCommitmentHashMismatch))
+
+	// Reveal order, which returns the deposit and adds the buy to the batch
+	_, err = h(ctx, types.NewMsgRevealOrder(userAddress, token, 1, order))
+	require.NoError(t, err)
+	require.Len(t, app.BondsKeeper.GetOrderCommitments(ctx, token), 0)
+	require.Len(t, app.BondsKeeper.MustGetBatch(ctx, token).Buys, 1)
+
+	// Buy performed at the end of the batch and new batch starts in commit phase
+	ctx = endBlock(app, ctx)
+	require.Equal(t, sdk.NewInt(2), app.BankKeeper.GetCoins(ctx, userAddress).AmountOf(token))
+	require.Equal(t, types.CommitPhase, app.BondsKeeper.MustGetBatch(ctx, token).Phase)
+}
+
+func TestUnrevealedOrderCommitmentIsSettledAtEndOfBatch(t *testing.T) {
+	testCases := []struct {
+		forfeitUnrevealed bool
+	}{
+		{false},
+		{true},
+	}
+	for _, tc := range testCases {
+		app, ctx := createTestApp(false)
+		h := peyote.NewHandler(app.BondsKeeper)
+
+		// Create bond and commit to buy with a deposit of 10
+		h(ctx, newValidMsgCreateCommitRevealBond(tc.forfeitUnrevealed))
+		err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 10)})
+		require.Nil(t, err)
+		_, err = h(ctx, newValidMsgCommitOrder(newValidRevealedBuy(2, 4000, "salt"), 10))
+		require.NoError(t, err)
+
+		// Batch ends without the order being revealed
+		ctx = endBlock(app, ctx)
+		ctx = endBlock(app, ctx)
+		ctx = endBlock(app, ctx)
+		require.Len(t, app.BondsKeeper.GetOrderCommitments(ctx, token), 0)
+
+		// Deposit forfeited to fee address or refunded to user
+		userBalance := app.BankKeeper.GetCoins(ctx, userAddress).AmountOf(reserveToken)
+		feeBalance := app.BankKeeper.GetCoins(ctx, initFeeAddress).AmountOf(reserveToken)
+		if tc.forfeitUnrevealed {
+			require.True(t, userBalance.IsZero())
+			require.Equal(t, sdk.NewInt(10), feeBalance)
+		} else {
+			require.Equal(t, sdk.NewInt(10), userBalance)
+			require.True(t, feeBalance.IsZero())
+		}
+	}
+}
+
+func TestBuyingBeyondMaxBatchOrdersFails(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create bond with a maximum of one order per batch
+	msg := newValidMsgCreateBond()
+	msg.MaxBatchOrders = sdk.OneUint()
+	h(ctx, msg)
+
+	// Add reserve tokens to user
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 8000)})
+	require.Nil(t, err)
+
+	// First buy fits in the batch, second buy is rejected
+	_, err = h(ctx, newValidMsgBuy(2, 4000))
+	require.NoError(t, err)
+	_, err = h(ctx, newValidMsgBuy(2, 4000))
+	require.True(t, errors.Is(err, types.ErrBatchOrderLimitReached))
+	require.Equal(t, uint64(1), app.BondsKeeper.MustGetBatch(ctx, token).OrderCount)
+
+	// Next batch has room again
+	ctx = endBlock(app, ctx)
+	_, err = h(ctx, newValidMsgBuy(2, 4000))
+	require.NoError(t, err)
+}
+
+func TestBuyingBeyondMaxBatchVolumeFails(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create bond with a maximum volume of 5 tokens per batch, rolling over
+	msg := newValidMsgCreateBond()
+	msg.MaxBatchVolume = sdk.NewUint(5)
+	msg.RollOverOrders = true
+	h(ctx, msg)
+
+	// Add reserve tokens to user
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 10000)})
+	require.Nil(t, err)
+
+	// Buy larger than the maximum volume is rejected even if rolling over
+	_, err = h(ctx, newValidMsgBuy(6, 10000))
+	require.True(t, errors.Is(err, types.ErrOrderExceedsBatchVolumeLimit))
+
+	// Buy that fits in the batch is added to it
+	_, err = h(ctx, newValidMsgBuy(3, 4000))
+	require.NoError(t, err)
+	require.Equal(t, sdk.NewInt(3), app.BondsKeeper.MustGetBatch(ctx, token).GetVolume())
+	require.False(t, app.BondsKeeper.HasRolledOverOrders(ctx, token))
+}
+
+func TestBuyingBeyondMaxBatchOrdersRollsOver(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create bond with a maximum of one order per batch, rolling over
+	msg := newValidMsgCreateBond()
+	msg.MaxBatchOrders = sdk.OneUint()
+	msg.RollOverOrders = true
+	h(ctx, msg)
+
+	// Add reserve tokens to user
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 8000)})
+	require.Nil(t, err)
+
+	// First buy (order 1) is added to the batch, second (order 2) rolls over
+	_, err = h(ctx, newValidMsgBuy(2, 4000))
+	require.NoError(t, err)
+	_, err = h(ctx, newValidMsgBuy(2, 4000))
+	require.NoError(t, err)
+	require.Len(t, app.BondsKeeper.MustGetBatch(ctx, token).Buys, 1)
+	rolledOver := app.BondsKeeper.GetRolledOverOrders(ctx, token)
+	require.Len(t, rolledOver.Buys, 1)
+	require.Equal(t, uint64(2), rolledOver.Buys[0].Id)
+
+	// Max prices of both buys are held
+	userBalance := app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress)
+	require.True(t, userBalance.AmountOf(reserveToken).IsZero())
+
+	// First buy performed, second buy added to the next batch
+	ctx = endBlock(app, ctx)
+	require.False(t, app.BondsKeeper.HasRolledOverOrders(ctx, token))
+	batch := app.BondsKeeper.MustGetBatch(ctx, token)
+	require.Len(t, batch.Buys, 1)
+	require.Equal(t, uint64(2), batch.Buys[0].Id)
+	require.Equal(t, sdk.NewInt(2), app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress).AmountOf(token))
+
+	// Second buy performed
+	ctx = endBlock(app, ctx)
+	require.Equal(t, sdk.NewInt(4), app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress).AmountOf(token))
+	require.Equal(t, sdk.NewInt64Coin(token, 4), app.BondsKeeper.MustGetBond(ctx, token).CurrentSupply)
+}
+
+func TestCancelOrderRolledOverSellCorrectlyPasses(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create bond with a maximum of one order per batch, rolling over
+	msg := newValidMsgCreateBond()
+	msg.MaxBatchOrders = sdk.OneUint()
+	msg.RollOverOrders = true
+	h(ctx, msg)
+
+	// Buy 4 tokens (order 1)
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 10000)})
+	require.Nil(t, err)
+	_, err = h(ctx, newValidMsgBuy(4, 10000))
+	require.NoError(t, err)
+	ctx = endBlock(app, ctx)
+
+	// Sell 2 tokens (order 2, added) and 2 tokens (order 3, rolled over)
+	_, err = h(ctx, newValidMsgSell(2))
+	require.NoError(t, err)
+	_, err = h(ctx, newValidMsgSell(2))
+	require.NoError(t, err)
+	require.True(t, app.BondsKeeper.IsRolledOverOrder(ctx, token, 3))
+	require.True(t, app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress).AmountOf(token).IsZero())
+
+	// Cancel rolled-over sell by someone else fails
+	_, err = h(ctx, types.NewMsgCancelOrder(anotherAddress, token, 3))
+	require.True(t, errors.Is(err, types.ErrOrderNotOwnedBySender))
+
+	// Cancel rolled-over sell
+	_, err = h(ctx, types.NewMsgCancelOrder(userAddress, token, 3))
+	require.NoError(t, err)
+	require.False(t, app.BondsKeeper.HasRolledOverOrders(ctx, token))
+	require.Equal(t, sdk.NewInt(2), app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress).AmountOf(token))
+
+	// Bond accounting still consistent once the batch is processed
+	ctx = endBlock(app, ctx)
+	require.Equal(t, types.OpenState, app.BondsKeeper.MustGetBond(ctx, token).State)
+	require.Equal(t, sdk.NewInt64Coin(token, 2), app.BondsKeeper.MustGetBond(ctx, token).CurrentSupply)
+}
+
+func TestBuyingForRecipientCorrectlyPasses(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create bond
+	h(ctx, newValidMsgCreateBond())
+
+	// Add reserve tokens to user
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 4000)})
+	require.Nil(t, err)
+
+	// Buy 2 tokens for another address
+	msg := newValidMsgBuy(2, 4000)
+	msg.Recipient = anotherAddress
+	_, err = h(ctx, msg)
+	require.NoError(t, err)
+	ctx = endBlock(app, ctx)
+
+	// Bond tokens sent to recipient, unused max prices returned to buyer
+	userBalance := app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress)
+	recipientBalance := app.BondsKeeper.BankKeeper.GetCoins(ctx, anotherAddress)
+	require.Equal(t, sdk.NewInt(3767), userBalance.AmountOf(reserveToken))
+	require.True(t, userBalance.AmountOf(token).IsZero())
+	require.Equal(t, sdk.NewInt(2), recipientBalance.AmountOf(token))
+	require.True(t, recipientBalance.AmountOf(reserveToken).IsZero())
+}
+
+func TestBuyingForModuleAccountRecipientFails(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create bond
+	h(ctx, newValidMsgCreateBond())
+
+	// Add reserve tokens to user
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 4000)})
+	require.Nil(t, err)
+
+	// Buy 2 tokens for the bonds reserve account
+	msg := newValidMsgBuy(2, 4000)
+	msg.Recipient = supply.NewModuleAddress(types.BondsReserveAccount)
+	_, err = h(ctx, msg)
+	require.True(t, errors.Is(err, sdkerrors.ErrUnauthorized))
+}
+
+func TestSellingForRecipientCorrectlyPasses(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create bond
+	h(ctx, newValidMsgCreateBond())
+
+	// Add reserve tokens to user
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 4000)})
+	require.Nil(t, err)
+
+	// Buy 2 tokens
+	h(ctx, newValidMsgBuy(2, 4000))
+	ctx = endBlock(app, ctx)
+
+	// Sell 2 tokens with returns sent to another address
+	msg := newValidMsgSell(2)
+	msg.Recipient = anotherAddress
+	_, err = h(ctx, msg)
+	require.NoError(t, err)
+	ctx = endBlock(app, ctx)
+
+	// Bond tokens taken from seller, returns sent to recipient
+	userBalance := app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress)
+	recipientBalance := app.BondsKeeper.BankKeeper.GetCoins(ctx, anotherAddress)
+	require.Equal(t, sdk.NewInt(3767), userBalance.AmountOf(reserveToken))
+	require.True(t, userBalance.AmountOf(token).IsZero())
+	require.Equal(t, sdk.NewInt(230), recipientBalance.AmountOf(reserveToken))
+}
+
+func TestSwappingForRecipientCorrectlyPasses(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create swapper bond
+	h(ctx, newValidMsgCreateSwapperBond())
+
+	// Add reserve tokens to user
+	coins := sdk.NewCoins(
+		sdk.NewInt64Coin(reserveToken, 100000),
+		sdk.NewInt64Coin(reserveToken2, 100000),
+	)
+	err := addCoinsToUser(app, ctx, coins)
+	require.Nil(t, err)
+
+	// Buy 2 tokens (initialises reserves) for another address
+	buyMsg := newValidMsgBuy(2, 0) // 0 max prices replaced below
+	buyMsg.MaxPrices = sdk.NewCoins(
+		sdk.NewInt64Coin(reserveToken, 10000),
+		sdk.NewInt64Coin(reserveToken2, 10000),
+	)
+	buyMsg.Recipient = anotherAddress
+	_, err = h(ctx, buyMsg)
+	require.NoError(t, err)
+	require.Equal(t, sdk.NewInt(2), app.BondsKeeper.BankKeeper.GetCoins(ctx, anotherAddress).AmountOf(token))
+	ctx = endBlock(app, ctx)
+
+	// Swap with returns sent to another address
+	msg := newValidMsgSwap(reserveToken, reserveToken2, 100)
+	msg.Recipient = anotherAddress
+	_, err = h(ctx, msg)
+	require.NoError(t, err)
+	ctx = endBlock(app, ctx)
+
+	// From tokens taken from swapper, returns sent to recipient
+	userBalance := app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress)
+	recipientBalance := app.BondsKeeper.BankKeeper.GetCoins(ctx, anotherAddress)
+	require.Equal(t, sdk.NewInt(89900), userBalance.AmountOf(reserveToken))
+	require.Equal(t, sdk.NewInt(90000), userBalance.AmountOf(reserveToken2))
+	require.True(t, recipientBalance.AmountOf(reserveToken2).IsPositive())
+}
+
+func TestCreateRecurringOrderWithEndHeightInPastFails(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+	ctx = ctx.WithBlockHeight(10)
+
+	// Create bond
+	h(ctx, newValidMsgCreateBond())
+
+	// Add reserve tokens to user
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 10000)})
+	require.Nil(t, err)
+
+	// Recurring buy ending at the current height
+	_, err = h(ctx, newValidMsgCreateRecurringBuy(1000, 2000, 1, 10))
+	require.True(t, errors.Is(err, types.ErrEndHeightMustBeInFuture))
+}
+
+func TestCreateRecurringOrderForSwapperBondFails(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create swapper bond
+	h(ctx, newValidMsgCreateSwapperBond())
+
+	// Add reserve tokens to user
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 10000)})
+	require.Nil(t, err)
+
+	_, err = h(ctx, newValidMsgCreateRecurringBuy(1000, 2000, 1, 0))
+	require.True(t, errors.Is(err, types.ErrFunctionNotAvailableForFunctionType))
+}
+
+func TestCreateRecurringOrderBeyondMaximumFails(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Allow a single recurring order per bond
+	params := app.BondsKeeper.GetParams(ctx)
+	params.MaxRecurringOrders = 1
+	app.BondsKeeper.SetParams(ctx, params)
+
+	// Create bond
+	h(ctx, newValidMsgCreateBond())
+
+	// Add reserve tokens to user
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 10000)})
+	require.Nil(t, err)
+
+	_, err = h(ctx, newValidMsgCreateRecurringBuy(1000, 2000, 1, 0))
+	require.NoError(t, err)
+
+	// Second recurring order fails and its budget is not taken
+	_, err = h(ctx, newValidMsgCreateRecurringBuy(1000, 2000, 1, 0))
+	require.True(t, errors.Is(err, types.ErrMaxRecurringOrdersReached))
+	userBalance := app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress)
+	require.Equal(t, sdk.NewInt(8000), userBalance.AmountOf(reserveToken))
+	require.Len(t, app.BondsKeeper.GetRecurringOrdersByOwner(ctx, userAddress), 1)
+}
+
+func TestEndBlockerPlacesRecurringBuys(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create bond
+	h(ctx, newValidMsgCreateBond())
+
+	// Add reserve tokens to user
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 10000)})
+	require.Nil(t, err)
+
+	// Recurring buy spending 1000 in every batch, with a budget of 2000
+	_, err = h(ctx, newValidMsgCreateRecurringBuy(1000, 2000, 1, 0))
+	require.NoError(t, err)
+	userBalance := app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress)
+	require.Equal(t, sdk.NewInt(8000), userBalance.AmountOf(reserveToken))
+
+	// First buy placed and performed, half of the budget remaining
+	ctx = endBlock(app, ctx)
+	userBalance = app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress)
+	firstBuyTokens := userBalance.AmountOf(token)
+	require.True(t, firstBuyTokens.IsPositive())
+	orders := app.BondsKeeper.GetRecurringOrdersByOwner(ctx, userAddress)
+	require.Len(t, orders, 1)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 1000)), orders[0].Budget)
+
+	// Second buy placed and performed, budget spent so schedule ended
+	ctx = endBlock(app, ctx)
+	userBalance = app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress)
+	require.True(t, userBalance.AmountOf(token).GT(firstBuyTokens))
+	require.Len(t, app.BondsKeeper.GetRecurringOrdersByOwner(ctx, userAddress), 0)
+}
+
+func TestCancelRecurringOrderCorrectlyPasses(t *testing.T) {
+	app, ctx := createTestApp(false)
+	h := peyote.NewHandler(app.BondsKeeper)
+
+	// Create bond
+	h(ctx, newValidMsgCreateBond())
+
+	// Add reserve tokens to user
+	err := addCoinsToUser(app, ctx, sdk.Coins{sdk.NewInt64Coin(reserveToken, 10000)})
+	require.Nil(t, err)
+
+	// Recurring buy spending 1000 in every 2nd batch, with a budget of 3000
+	_, err = h(ctx, newValidMsgCreateRecurringBuy(1000, 3000, 2, 0))
+	require.NoError(t, err)
+	ctx = endBlock(app, ctx)
+	orders := app.BondsKeeper.GetRecurringOrdersByOwner(ctx, userAddress)
+	require.Len(t, orders, 1)
+
+	// Another address cannot cancel the recurring order
+	_, err = h(ctx, types.NewMsgCancelRecurringOrder(anotherAddress, orders[0].Id))
+	require.True(t, errors.Is(err, types.ErrOrderNotOwnedBySender))
+
+	// Cancelling refunds the remaining budget
+	userBalance := app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress)
+	_, err = h(ctx, types.NewMsgCancelRecurringOrder(userAddress, orders[0].Id))
+	require.NoError(t, err)
+	newBalance := app.BondsKeeper.BankKeeper.GetCoins(ctx, userAddress)
+	require.Equal(t, userBalance.AmountOf(reserveToken).AddRaw(2000), newBalance.AmountOf(reserveToken))
+	require.Len(t, app.BondsKeeper.GetRecurringOrdersByOwner(ctx, userAddress), 0)
+
+	// Cancelling again fails
+	_, err = h(ctx, types.NewMsgCancelRecurringOrder(userAddress, orders[0].Id))
+	require.True(t, errors.Is(err, types.ErrRecurringOrderNotFound))
 }
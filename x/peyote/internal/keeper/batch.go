@@ -1,13 +1,30 @@
 package keeper
 
 import (
+	"encoding/binary"
 	"fmt"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
 	"github.com/warmage-sports/peyote/x/peyote/internal/types"
 )
 
+// MustGetBatch returns the bond's current batch including all of its orders.
+// Use MustGetBatchHeader if only the batch's phase, totals or prices are needed.
 func (k Keeper) MustGetBatch(ctx sdk.Context, token string) types.Batch {
+	batch := k.MustGetBatchHeader(ctx, token)
+	batch.Buys = k.GetBatchBuyOrders(ctx, token)
+	batch.Sells = k.GetBatchSellOrders(ctx, token)
+	batch.Swaps = k.GetBatchSwapOrders(ctx, token)
+	return batch
+}
+
+// MustGetBatchHeader returns the bond's current batch without its orders. The
+// orders are stored separately from the batch header so that adding an order
+// to a batch does not involve reading or writing all of the other orders. The
+// batch's execution height and phase are brought up to date with the current
+// height, since a batch without any orders is not touched by the EndBlocker.
+// The batch header holds the bond's schedule, so the bond itself is not read.
+func (k Keeper) MustGetBatchHeader(ctx sdk.Context, token string) types.Batch {
 	store := ctx.KVStore(k.storeKey)
 	if !k.BatchExists(ctx, token) {
 		panic(fmt.Sprintf("batch not found for %s\n", token))
@@ -17,7 +34,79 @@ func (k Keeper) MustGetBatch(ctx sdk.Context, token string) types.Batch {
 	var batch types.Batch
 	k.cdc.MustUnmarshalBinaryBare(bz, &batch)
 
-	return batch
+	return batch.UpdateSchedule(ctx.BlockHeight())
+}
+
+func (k Keeper) GetBatchBuyOrders(ctx sdk.Context, token string) (orders []types.BuyOrder) {
+	store := ctx.KVStore(k.storeKey)
+	iterator := sdk.KVStorePrefixIterator(store,
+		types.GetBatchOrdersOfTypePrefixKey(token, types.BatchBuyOrderByte))
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		var order types.BuyOrder
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &order)
+		orders = append(orders, order)
+	}
+	return orders
+}
+
+func (k Keeper) GetBatchSellOrders(ctx sdk.Context, token string) (orders []types.SellOrder) {
+	store := ctx.KVStore(k.storeKey)
+	iterator := sdk.KVStorePrefixIterator(store,
+		types.GetBatchOrdersOfTypePrefixKey(token, types.BatchSellOrderByte))
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		var order types.SellOrder
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &order)
+		orders = append(orders, order)
+	}
+	return orders
+}
+
+func (k Keeper) GetBatchSwapOrders(ctx sdk.Context, token string) (orders []types.SwapOrder) {
+	store := ctx.KVStore(k.storeKey)
+	iterator := sdk.KVStorePrefixIterator(store,
+		types.GetBatchOrdersOfTypePrefixKey(token, types.BatchSwapOrderByte))
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		var order types.SwapOrder
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &order)
+		orders = append(orders, order)
+	}
+	return orders
+}
+
+func (k Keeper) GetBatchBuyOrder(ctx sdk.Context, token string, id uint64) (order types.BuyOrder, found bool) {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(types.GetBatchOrderKey(token, types.BatchBuyOrderByte, id))
+	if bz == nil {
+		return types.BuyOrder{}, false
+	}
+	k.cdc.MustUnmarshalBinaryBare(bz, &order)
+	return order, true
+}
+
+func (k Keeper) GetBatchSellOrder(ctx sdk.Context, token string, id uint64) (order types.SellOrder, found bool) {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(types.GetBatchOrderKey(token, types.BatchSellOrderByte, id))
+	if bz == nil {
+		return types.SellOrder{}, false
+	}
+	k.cdc.MustUnmarshalBinaryBare(bz, &order)
+	return order, true
+}
+
+func (k Keeper) GetBatchSwapOrder(ctx sdk.Context, token string, id uint64) (order types.SwapOrder, found bool) {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(types.GetBatchOrderKey(token, types.BatchSwapOrderByte, id))
+	if bz == nil {
+		return types.SwapOrder{}, false
+	}
+	k.cdc.MustUnmarshalBinaryBare(bz, &order)
+	return order, true
 }
 
 func (k Keeper) MustGetLastBatch(ctx sdk.Context, token string) types.Batch {
@@ -43,51 +132,163 @@ func (k Keeper) LastBatchExists(ctx sdk.Context, token string) bool {
 	return store.Has(types.GetLastBatchKey(token))
 }
 
+// SetBatch sets the bond's current batch, replacing all of its orders.
 func (k Keeper) SetBatch(ctx sdk.Context, token string, batch types.Batch) {
+	k.SetBatchHeader(ctx, token, batch)
+	k.removeBatchOrders(ctx, token)
+	for _, bo := range batch.Buys {
+		k.SetBatchBuyOrder(ctx, token, bo)
+	}
+	for _, so := range batch.Sells {
+		k.SetBatchSellOrder(ctx, token, so)
+	}
+	for _, so := range batch.Swaps {
+		k.SetBatchSwapOrder(ctx, token, so)
+	}
+}
+
+// SetBatchHeader sets the bond's current batch without its orders, i.e. any
+// orders in the batch are ignored and the stored orders are left unchanged.
+func (k Keeper) SetBatchHeader(ctx sdk.Context, token string, batch types.Batch) {
+	batch.Buys, batch.Sells, batch.Swaps = nil, nil, nil
 	store := ctx.KVStore(k.storeKey)
 	store.Set(types.GetBatchKey(token), k.cdc.MustMarshalBinaryBare(batch))
 }
 
+func (k Keeper) SetBatchBuyOrder(ctx sdk.Context, token string, order types.BuyOrder) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(types.GetBatchOrderKey(token, types.BatchBuyOrderByte, order.Id),
+		k.cdc.MustMarshalBinaryBare(order))
+}
+
+func (k Keeper) SetBatchSellOrder(ctx sdk.Context, token string, order types.SellOrder) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(types.GetBatchOrderKey(token, types.BatchSellOrderByte, order.Id),
+		k.cdc.MustMarshalBinaryBare(order))
+}
+
+func (k Keeper) SetBatchSwapOrder(ctx sdk.Context, token string, order types.SwapOrder) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(types.GetBatchOrderKey(token, types.BatchSwapOrderByte, order.Id),
+		k.cdc.MustMarshalBinaryBare(order))
+}
+
+// removeBatchOrders removes all of the orders in the bond's current batch.
+func (k Keeper) removeBatchOrders(ctx sdk.Context, token string) {
+	store := ctx.KVStore(k.storeKey)
+	iterator := sdk.KVStorePrefixIterator(store, types.GetBatchOrdersPrefixKey(token))
+
+	var keys [][]byte
+	for ; iterator.Valid(); iterator.Next() {
+		keys = append(keys, iterator.Key())
+	}
+	iterator.Close()
+
+	for _, key := range keys {
+		store.Delete(key)
+	}
+}
+
 func (k Keeper) SetLastBatch(ctx sdk.Context, token string, batch types.Batch) {
 	store := ctx.KVStore(k.storeKey)
 	store.Set(types.GetLastBatchKey(token), k.cdc.MustMarshalBinaryBare(batch))
 }
 
-func (k Keeper) AddBuyOrder(ctx sdk.Context, token string, bo types.BuyOrder, buyPrices, sellPrices sdk.DecCoins) {
-	batch := k.MustGetBatch(ctx, token)
+func (k Keeper) GetLastOrderId(ctx sdk.Context) uint64 {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(types.LastOrderIdKey)
+	if bz == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint64(bz)
+}
+
+func (k Keeper) SetLastOrderId(ctx sdk.Context, id uint64) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(types.LastOrderIdKey, sdk.Uint64ToBigEndian(id))
+}
+
+// nextOrderId returns the ID to be assigned to the next order added to a batch
+func (k Keeper) nextOrderId(ctx sdk.Context) uint64 {
+	id := k.GetLastOrderId(ctx) + 1
+	k.SetLastOrderId(ctx, id)
+	return id
+}
+
+// AddBuyOrder assigns the next order ID to the buy order and adds it to the
+// bond's current batch, returning the order with the ID assigned.
+func (k Keeper) AddBuyOrder(ctx sdk.Context, token string, bo types.BuyOrder, buyPrices, sellPrices sdk.DecCoins) types.BuyOrder {
+	bo.Id = k.nextOrderId(ctx)
+	k.addBuyOrder(ctx, token, bo, buyPrices, sellPrices)
+	return bo
+}
+
+// addBuyOrder adds the buy order to the bond's current batch without assigning
+// it an order ID, e.g. since it was assigned one when it was rolled over.
+func (k Keeper) addBuyOrder(ctx sdk.Context, token string, bo types.BuyOrder, buyPrices, sellPrices sdk.DecCoins) {
+	batch := k.MustGetBatchHeader(ctx, token)
+	batch.OrderCount++
 	batch.TotalBuyAmount = batch.TotalBuyAmount.Add(bo.Amount)
 	batch.BuyPrices = buyPrices
 	batch.SellPrices = sellPrices
-	batch.Buys = append(batch.Buys, bo)
-	k.SetBatch(ctx, token, batch)
+	k.SetBatchHeader(ctx, token, batch)
+	k.SetBatchBuyOrder(ctx, token, bo)
+	k.ScheduleBatch(ctx, token)
 
 	logger := k.Logger(ctx)
-	logger.Info(fmt.Sprintf("added buy order for %s from %s", bo.Amount.String(), bo.Address.String()))
+	logger.Info(fmt.Sprintf("added buy order %d for %s from %s", bo.Id, bo.Amount.String(), bo.Address.String()))
 }
 
-func (k Keeper) AddSellOrder(ctx sdk.Context, token string, so types.SellOrder, buyPrices, sellPrices sdk.DecCoins) {
-	batch := k.MustGetBatch(ctx, token)
+// AddSellOrder assigns the next order ID to the sell order and adds it to the
+// bond's current batch, returning the order with the ID assigned.
+func (k Keeper) AddSellOrder(ctx sdk.Context, token string, so types.SellOrder, buyPrices, sellPrices sdk.DecCoins) types.SellOrder {
+	so.Id = k.nextOrderId(ctx)
+	k.addSellOrder(ctx, token, so, buyPrices, sellPrices)
+	return so
+}
+
+// addSellOrder adds the sell order to the bond's current batch without
+// assigning it an order ID, e.g. since it was assigned one when it was rolled
+// over.
+func (k Keeper) addSellOrder(ctx sdk.Context, token string, so types.SellOrder, buyPrices, sellPrices sdk.DecCoins) {
+	batch := k.MustGetBatchHeader(ctx, token)
+	batch.OrderCount++
 	batch.TotalSellAmount = batch.TotalSellAmount.Add(so.Amount)
 	batch.BuyPrices = buyPrices
 	batch.SellPrices = sellPrices
-	batch.Sells = append(batch.Sells, so)
-	k.SetBatch(ctx, token, batch)
+	k.SetBatchHeader(ctx, token, batch)
+	k.SetBatchSellOrder(ctx, token, so)
+	k.ScheduleBatch(ctx, token)
 
 	logger := k.Logger(ctx)
-	logger.Info(fmt.Sprintf("added sell order for %s from %s", so.Amount.String(), so.Address.String()))
+	logger.Info(fmt.Sprintf("added sell order %d for %s from %s", so.Id, so.Amount.String(), so.Address.String()))
+}
+
+// AddSwapOrder assigns the next order ID to the swap order and adds it to the
+// bond's current batch, returning the order with the ID assigned.
+func (k Keeper) AddSwapOrder(ctx sdk.Context, token string, so types.SwapOrder) types.SwapOrder {
+	so.Id = k.nextOrderId(ctx)
+	k.addSwapOrder(ctx, token, so)
+	return so
 }
 
-func (k Keeper) AddSwapOrder(ctx sdk.Context, token string, so types.SwapOrder) {
-	batch := k.MustGetBatch(ctx, token)
-	batch.Swaps = append(batch.Swaps, so)
-	k.SetBatch(ctx, token, batch)
+// addSwapOrder adds the swap order to the bond's current batch without
+// assigning it an order ID, e.g. since it was assigned one when it was rolled
+// over.
+func (k Keeper) addSwapOrder(ctx sdk.Context, token string, so types.SwapOrder) {
+	batch := k.MustGetBatchHeader(ctx, token)
+	batch.OrderCount++
+	k.SetBatchHeader(ctx, token, batch)
+	k.SetBatchSwapOrder(ctx, token, so)
+	k.ScheduleBatch(ctx, token)
 
 	logger := k.Logger(ctx)
-	logger.Info(fmt.Sprintf("added swap order for %s to %s from %s", so.Amount.String(), so.ToToken, so.Address.String()))
+	logger.Info(fmt.Sprintf("added swap order %d for %s to %s from %s", so.Id, so.Amount.String(), so.ToToken, so.Address.String()))
 }
 
 func (k Keeper) GetBatchBuySellPrices(ctx sdk.Context, token string, batch types.Batch) (buyPricesPT, sellPricesPT sdk.DecCoins, err error) {
 	bond := k.MustGetBond(ctx, token)
+	ctx.GasMeter().ConsumeGas(types.CurveEvaluationGasCost, "bonding curve evaluation")
 
 	buyAmountDec := batch.TotalBuyAmount.Amount.ToDec()
 	sellAmountDec := batch.TotalSellAmount.Amount.ToDec()
@@ -116,7 +317,10 @@ func (k Keeper) GetBatchBuySellPrices(ctx sdk.Context, token string, batch types
 	} else {
 		matchedAmount = buyAmountDec // since buys < sells, greatest common amount is buys
 		extraSells := batch.TotalSellAmount.Sub(batch.TotalBuyAmount)
-		curvedValues = bond.GetReturnsForBurn(extraSells.Amount, reserveBalances) // sell returns
+		curvedValues, err = bond.GetReturnsForBurn(extraSells.Amount, reserveBalances) // sell returns
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 
 	// Get (actual) matched values
@@ -139,7 +343,7 @@ func (k Keeper) GetBatchBuySellPrices(ctx sdk.Context, token string, batch types
 
 func (k Keeper) GetUpdatedBatchPricesAfterBuy(ctx sdk.Context, token string, bo types.BuyOrder) (buyPrices, sellPrices sdk.DecCoins, err error) {
 	bond := k.MustGetBond(ctx, token)
-	batch := k.MustGetBatch(ctx, token)
+	batch := k.MustGetBatchHeader(ctx, token)
 
 	// Max supply cannot be less than supply (max supply >= supply)
 	adjustedSupply := k.GetSupplyAdjustedForBuy(ctx, token)
@@ -148,17 +352,17 @@ func (k Keeper) GetUpdatedBatchPricesAfterBuy(ctx sdk.Context, token string, bo
 		return nil, nil, sdkerrors.Wrap(types.ErrCannotMintMoreThanMaxSupply, bond.MaxSupply.String())
 	}
 
-	// If augmented in hatch phase and adjusted supply exceeds S0, disallow buy
-	// since it is not allowed for a batch to cross over to the open phase.
+	// If in hatch phase and adjusted supply exceeds the hatch supply (S0 for
+	// augmented), disallow buy since it is not allowed for a batch to cross
+	// over to the open phase.
 	//
 	// S0 is rounded to ceil for the case that it has a decimal, otherwise it
 	// cannot be reached without being exceeded, when using integer buy amounts
 	// (e.g. if supply is 100 and S0=100.5, we cannot reach S0 by performing
 	// the minimum buy of 1 token [101>100.5], so S0 is rounded to ceil; S0=101)
-	if bond.FunctionType == types.AugmentedFunction &&
+	if fn, ok := types.GetHatchFunction(bond.FunctionType); ok &&
 		bond.State == types.HatchState {
-		args := bond.FunctionParameters.AsMap()
-		if adjustedSupplyWithBuy.Amount.ToDec().GT(args["S0"].Ceil()) {
+		if adjustedSupplyWithBuy.Amount.ToDec().GT(fn.HatchSupply(bond).Ceil()) {
 			return nil, nil, sdkerrors.Wrap(sdkerrors.ErrInvalidCoins, "Buy exceeds initial supply S0. Consider buying less tokens.")
 		}
 	}
@@ -179,7 +383,7 @@ func (k Keeper) GetUpdatedBatchPricesAfterBuy(ctx sdk.Context, token string, bo
 }
 
 func (k Keeper) GetUpdatedBatchPricesAfterSell(ctx sdk.Context, token string, so types.SellOrder) (buyPrices, sellPrices sdk.DecCoins, err error) {
-	batch := k.MustGetBatch(ctx, token)
+	batch := k.MustGetBatchHeader(ctx, token)
 
 	// Cannot burn more tokens than what exists
 	adjustedSupply := k.GetSupplyAdjustedForSell(ctx, token)
@@ -194,6 +398,11 @@ func (k Keeper) GetUpdatedBatchPricesAfterSell(ctx sdk.Context, token string, so
 		return nil, nil, err
 	}
 
+	err = k.CheckIfSellOrderFulfillableAtPrice(ctx, token, so, sellPrices)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	return buyPrices, sellPrices, nil
 }
 
@@ -208,9 +417,10 @@ func (k Keeper) PerformBuyAtPrice(ctx sdk.Context, token string, bo types.BuyOrd
 		return err
 	}
 
-	// Send bond tokens bought to buyer
+	// Send bond tokens bought to recipient (the buyer unless specified)
+	recipient := bo.GetRecipient()
 	err = k.SupplyKeeper.SendCoinsFromModuleToAccount(ctx,
-		types.BondsMintBurnAccount, bo.Address, sdk.Coins{bo.Amount})
+		types.BondsMintBurnAccount, recipient, sdk.Coins{bo.Amount})
 	if err != nil {
 		return err
 	}
@@ -221,16 +431,13 @@ func (k Keeper) PerformBuyAtPrice(ctx sdk.Context, token string, bo types.BuyOrd
 	totalPrices := reservePricesRounded.Add(txFees...)
 
 	if totalPrices.IsAnyGT(bo.MaxPrices) {
-		sdkerrors.Wrapf(types.ErrMaxPriceExceeded, "Actual prices %s exceed max prices %s", totalPrices, bo.MaxPrices)
+		return sdkerrors.Wrapf(types.ErrMaxPriceExceeded, "Actual prices %s exceed max prices %s", totalPrices, bo.MaxPrices)
 	}
 
 	// Add new reserve to reserve (reservePricesRounded should never be zero)
 	// TODO: investigate possibility of zero reservePricesRounded
-	if bond.FunctionType == types.AugmentedFunction &&
+	if fn, ok := types.GetHatchFunction(bond.FunctionType); ok &&
 		bond.State == types.HatchState {
-		args := bond.FunctionParameters.AsMap()
-		theta := args["theta"]
-
 		// Get current reserve
 		var currentReserve sdk.Int
 		if bond.CurrentReserve.Empty() {
@@ -242,11 +449,10 @@ func (k Keeper) PerformBuyAtPrice(ctx sdk.Context, token string, bo types.BuyOrd
 			currentReserve = k.GetReserveBalances(ctx, token)[0].Amount
 		}
 
-		// Calculate expected new reserve (as fraction 1-theta of new total raise)
+		// Calculate expected new reserve (e.g. as fraction 1-theta of new total
+		// raise for augmented)
 		newSupply := bond.CurrentSupply.Add(bo.Amount).Amount
-		newTotalRaise := args["p0"].Mul(newSupply.ToDec())
-		newReserve := newTotalRaise.Mul(
-			sdk.OneDec().Sub(theta)).Ceil().TruncateInt()
+		newReserve := fn.HatchReserve(bond, newSupply).Ceil().TruncateInt()
 
 		// Calculate amount that should go into initial reserve
 		toInitialReserve := newReserve.Sub(currentReserve)
@@ -309,17 +515,28 @@ func (k Keeper) PerformBuyAtPrice(ctx sdk.Context, token string, bo types.BuyOrd
 	k.SetCurrentSupply(ctx, token, bond.CurrentSupply.Add(bo.Amount))
 
 	logger := k.Logger(ctx)
-	logger.Info(fmt.Sprintf("performed buy order for %s from %s", bo.Amount.String(), bo.Address.String()))
+	logger.Info(fmt.Sprintf("performed buy order for %s from %s to %s",
+		bo.Amount.String(), bo.Address.String(), recipient.String()))
 
-	// Get new bond token balance
-	bondTokenBalance := k.BankKeeper.GetCoins(ctx, bo.Address).AmountOf(bond.Token)
+	// Get new bond token balance of recipient
+	bondTokenBalance := k.BankKeeper.GetCoins(ctx, recipient).AmountOf(bond.Token)
+
+	// Orders from before partial fills were introduced have no requested amount
+	requestedAmount := bo.RequestedAmount
+	if requestedAmount.Denom == "" {
+		requestedAmount = bo.Amount
+	}
 
 	event := sdk.NewEvent(
 		types.EventTypeOrderFulfill,
 		sdk.NewAttribute(types.AttributeKeyBond, bond.Token),
+		sdk.NewAttribute(types.AttributeKeyOrderId, fmt.Sprint(bo.Id)),
 		sdk.NewAttribute(types.AttributeKeyOrderType, types.AttributeValueBuyOrder),
 		sdk.NewAttribute(types.AttributeKeyAddress, bo.Address.String()),
+		sdk.NewAttribute(types.AttributeKeyRecipient, recipient.String()),
 		sdk.NewAttribute(types.AttributeKeyTokensMinted, bo.Amount.Amount.String()),
+		sdk.NewAttribute(types.AttributeKeyFilledAmount, bo.Amount.Amount.String()),
+		sdk.NewAttribute(types.AttributeKeyRequestedAmount, requestedAmount.Amount.String()),
 		sdk.NewAttribute(types.AttributeKeyChargedPrices, reservePricesRounded.String()),
 		sdk.NewAttribute(types.AttributeKeyChargedFees, txFees.String()),
 		sdk.NewAttribute(types.AttributeKeyReturnedToAddress, returnToBuyer.String()),
@@ -344,9 +561,16 @@ func (k Keeper) PerformSellAtPrice(ctx sdk.Context, token string, so types.SellO
 	totalFees := types.AdjustFees(txFees.Add(exitFees...), reserveReturnsRounded) // calculate actual total fees
 	totalReturns := reserveReturnsRounded.Sub(totalFees)                          // calculate actual reserveReturns
 
-	// Send total returns to seller (totalReturns should never be zero)
+	// Check that min returns met (also checked when adding the order to batch)
+	if !totalReturns.IsAllGTE(so.MinReturns) {
+		return sdkerrors.Wrapf(types.ErrMinReturnsNotMet,
+			"actual returns %s are less than min returns %s", totalReturns, so.MinReturns)
+	}
+
+	// Send total returns to recipient (the seller unless specified)
 	// TODO: investigate possibility of zero totalReturns
-	err = k.WithdrawReserve(ctx, bond.Token, so.Address, totalReturns)
+	recipient := so.GetRecipient()
+	err = k.WithdrawReserve(ctx, bond.Token, recipient, totalReturns)
 	if err != nil {
 		return err
 	}
@@ -363,7 +587,8 @@ func (k Keeper) PerformSellAtPrice(ctx sdk.Context, token string, so types.SellO
 	k.SetCurrentSupply(ctx, token, bond.CurrentSupply.Sub(so.Amount))
 
 	logger := k.Logger(ctx)
-	logger.Info(fmt.Sprintf("performed sell order for %s from %s", so.Amount.String(), so.Address.String()))
+	logger.Info(fmt.Sprintf("performed sell order for %s from %s to %s",
+		so.Amount.String(), so.Address.String(), recipient.String()))
 
 	// Get new bond token balance
 	bondTokenBalance := k.BankKeeper.GetCoins(ctx, so.Address).AmountOf(bond.Token)
@@ -371,8 +596,10 @@ func (k Keeper) PerformSellAtPrice(ctx sdk.Context, token string, so types.SellO
 	ctx.EventManager().EmitEvent(sdk.NewEvent(
 		types.EventTypeOrderFulfill,
 		sdk.NewAttribute(types.AttributeKeyBond, bond.Token),
+		sdk.NewAttribute(types.AttributeKeyOrderId, fmt.Sprint(so.Id)),
 		sdk.NewAttribute(types.AttributeKeyOrderType, types.AttributeValueSellOrder),
 		sdk.NewAttribute(types.AttributeKeyAddress, so.Address.String()),
+		sdk.NewAttribute(types.AttributeKeyRecipient, recipient.String()),
 		sdk.NewAttribute(types.AttributeKeyTokensBurned, so.Amount.Amount.String()),
 		sdk.NewAttribute(types.AttributeKeyChargedFees, txFees.String()),
 		sdk.NewAttribute(types.AttributeKeyReturnedToAddress, totalReturns.String()),
@@ -382,150 +609,388 @@ func (k Keeper) PerformSellAtPrice(ctx sdk.Context, token string, so types.SellO
 	return nil
 }
 
-func (k Keeper) PerformSwap(ctx sdk.Context, token string, so types.SwapOrder) (err error, ok bool) {
-	bond := k.MustGetBond(ctx, token)
+// getTotalSwapInputs returns the total fee-deducted inputs of the swaps that
+// have not been cancelled, per reserve token.
+func getTotalSwapInputs(bond types.Bond, swaps []types.SwapOrder) sdk.Coins {
+	totalInputs := sdk.Coins{}
+	for _, so := range swaps {
+		if !so.IsCancelled() {
+			txFee := bond.GetTxFee(sdk.NewDecCoinFromCoin(so.Amount))
+			totalInputs = totalInputs.Add(so.Amount.Sub(txFee))
+		}
+	}
+	return totalInputs
+}
 
-	// WARNING: do not return ok=true if money has already been transferred when error occurs
+// checkSwapOrders checks that the swaps that have not been cancelled can all
+// be performed at the clearing rates given by their total inputs. If not, the
+// index of a swap that should be cancelled is returned alongside the error.
+func checkSwapOrders(bond types.Bond, swaps []types.SwapOrder, reserveBalances sdk.Coins) (int, error) {
+	totalInputs := getTotalSwapInputs(bond, swaps)
+	if totalInputs.IsZero() {
+		return -1, nil
+	}
 
-	// Get return for swap
-	reserveBalances := k.GetReserveBalances(ctx, token)
-	reserveReturns, txFee, err := bond.GetReturnsForSwap(so.Amount, so.ToToken, reserveBalances)
-	if err != nil {
-		return err, true
+	totalReturns := sdk.Coins{}
+	for i, so := range swaps {
+		if so.IsCancelled() {
+			continue
+		}
+
+		returns, _, err := bond.GetReturnsForBatchSwap(
+			so.Amount, so.ToToken, totalInputs, reserveBalances)
+		if err != nil {
+			return i, err
+		}
+
+		// Check that min returns met
+		if !returns.IsAllGTE(so.MinReturns) {
+			return i, sdkerrors.Wrapf(types.ErrMinReturnsNotMet,
+				"actual returns %s are less than min returns %s", returns, so.MinReturns)
+		}
+		totalReturns = totalReturns.Add(returns...)
 	}
-	adjustedInput := so.Amount.Sub(txFee) // same as during GetReturnsForSwap
 
-	// Check if new rates violate sanity rate
-	newReserveBalances := reserveBalances.Add(adjustedInput).Sub(reserveReturns)
+	// Check if new rates violate sanity rate, in which case the latest swap in
+	// the direction that the batch moved the rate in is the one to cancel
+	newReserveBalances := reserveBalances.Add(totalInputs...).Sub(totalReturns)
 	if bond.ReservesViolateSanityRate(newReserveBalances) {
-		return sdkerrors.Wrap(types.ErrValuesViolateSanityRate, newReserveBalances.String()), true
+		err := sdkerrors.Wrap(types.ErrValuesViolateSanityRate, newReserveBalances.String())
+
+		res1, res2 := bond.ReserveTokens[0], bond.ReserveTokens[1]
+		oldRate := reserveBalances.AmountOf(res1).Mul(newReserveBalances.AmountOf(res2))
+		newRate := newReserveBalances.AmountOf(res1).Mul(reserveBalances.AmountOf(res2))
+		latest := -1
+		for i := len(swaps) - 1; i >= 0; i-- {
+			if swaps[i].IsCancelled() {
+				continue
+			} else if latest == -1 {
+				latest = i
+			}
+			from := swaps[i].Amount.Denom
+			if (from == res1 && newRate.GT(oldRate)) || (from == res2 && newRate.LT(oldRate)) {
+				return i, err
+			}
+		}
+		return latest, err
 	}
 
-	// Give resultant tokens to swapper (reserveReturns should never be zero)
-	err = k.WithdrawReserve(ctx, bond.Token, so.Address, reserveReturns)
+	return -1, nil
+}
+
+// PerformSwaps performs the swaps that have not been cancelled at the clearing
+// rates given by their total inputs. The fee-deducted inputs of all of the
+// swaps are added to the reserve before any returns are given out, since the
+// returns in one direction can exceed the reserve balance until the swaps in
+// the opposite direction have been added.
+func (k Keeper) PerformSwaps(ctx sdk.Context, token string, swaps []types.SwapOrder) error {
+	bond := k.MustGetBond(ctx, token)
+	reserveBalances := k.GetReserveBalances(ctx, token)
+
+	// Check that the swaps can be performed (e.g. min returns met)
+	_, err := checkSwapOrders(bond, swaps, reserveBalances)
 	if err != nil {
-		return err, false
+		return err
 	}
+	totalInputs := getTotalSwapInputs(bond, swaps)
 
-	// Add fee-reduced coins to be swapped to reserve (adjustedInput should never be zero)
-	err = k.DepositReserveFromModule(
-		ctx, bond.Token, types.BatchesIntermediaryAccount, sdk.Coins{adjustedInput})
-	if err != nil {
-		return err, false
+	reserveReturns := make([]sdk.Coins, len(swaps))
+	txFees := make([]sdk.Coin, len(swaps))
+	for i, so := range swaps {
+		if so.IsCancelled() {
+			continue
+		}
+
+		// Get return for swap
+		reserveReturns[i], txFees[i], err = bond.GetReturnsForBatchSwap(
+			so.Amount, so.ToToken, totalInputs, reserveBalances)
+		if err != nil {
+			return err
+		}
+		adjustedInput := so.Amount.Sub(txFees[i]) // same as during GetReturnsForBatchSwap
+
+		// Add fee-reduced coins to be swapped to reserve (adjustedInput should never be zero)
+		err = k.DepositReserveFromModule(
+			ctx, bond.Token, types.BatchesIntermediaryAccount, sdk.Coins{adjustedInput})
+		if err != nil {
+			return err
+		}
+
+		// Add fee (taken from swapper) to fee address
+		if !txFees[i].IsZero() {
+			err = k.SupplyKeeper.SendCoinsFromModuleToAccount(ctx,
+				types.BatchesIntermediaryAccount, bond.FeeAddress, sdk.Coins{txFees[i]})
+			if err != nil {
+				return err
+			}
+		}
 	}
 
-	// Add fee (taken from swapper) to fee address
-	if !txFee.IsZero() {
-		err = k.SupplyKeeper.SendCoinsFromModuleToAccount(ctx,
-			types.BatchesIntermediaryAccount, bond.FeeAddress, sdk.Coins{txFee})
+	for i, so := range swaps {
+		if so.IsCancelled() {
+			continue
+		}
+
+		// Give resultant tokens to recipient (the swapper unless specified)
+		// (reserveReturns should never be zero)
+		recipient := so.GetRecipient()
+		err = k.WithdrawReserve(ctx, bond.Token, recipient, reserveReturns[i])
 		if err != nil {
-			return err, false
+			return err
+		}
+
+		logger := k.Logger(ctx)
+		logger.Info(fmt.Sprintf("performed swap order for %s to %s from %s to %s",
+			so.Amount.String(), reserveReturns[i], so.Address.String(), recipient.String()))
+
+		ctx.EventManager().EmitEvent(sdk.NewEvent(
+			types.EventTypeOrderFulfill,
+			sdk.NewAttribute(types.AttributeKeyBond, bond.Token),
+			sdk.NewAttribute(types.AttributeKeyOrderId, fmt.Sprint(so.Id)),
+			sdk.NewAttribute(types.AttributeKeyOrderType, types.AttributeValueSwapOrder),
+			sdk.NewAttribute(types.AttributeKeyAddress, so.Address.String()),
+			sdk.NewAttribute(types.AttributeKeyRecipient, recipient.String()),
+			sdk.NewAttribute(types.AttributeKeyTokensSwapped, so.Amount.Sub(txFees[i]).String()),
+			sdk.NewAttribute(types.AttributeKeyChargedFees, txFees[i].String()),
+			sdk.NewAttribute(types.AttributeKeyReturnedToAddress, reserveReturns[i].String()),
+		))
+	}
+
+	return nil
+}
+
+// performInCacheContext runs the specified function in a cached sub-context so
+// that any state changes (e.g. a partial transfer) are rolled back if it fails.
+// Panics are recovered as errors, and events are only emitted on success.
+func performInCacheContext(ctx sdk.Context, f func(ctx sdk.Context) error) (err error) {
+	cacheCtx, writeCache := ctx.CacheContext()
+	cacheCtx = cacheCtx.WithEventManager(sdk.NewEventManager())
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = sdkerrors.Wrapf(types.ErrOrderFailed, "%v", r)
 		}
+	}()
+
+	err = f(cacheCtx)
+	if err != nil {
+		return err
+	}
+
+	writeCache()
+	ctx.EventManager().EmitEvents(cacheCtx.EventManager().Events())
+	return nil
+}
+
+// cancelFailedOrder cancels an order that failed to be performed, using the
+// specified function to refund the order. An error is only returned if the
+// refund itself fails, in which case the bond's accounting is inconsistent.
+func (k Keeper) cancelFailedOrder(ctx sdk.Context, token, orderType string,
+	order types.BaseOrder, reason string, refund func(ctx sdk.Context) error) error {
+
+	err := performInCacheContext(ctx, refund)
+	if err != nil {
+		return sdkerrors.Wrapf(types.ErrBondAccountingInconsistent,
+			"could not refund failed %s order %d from %s: %s", orderType, order.Id, order.Address, err)
 	}
 
 	logger := k.Logger(ctx)
-	logger.Info(fmt.Sprintf("performed swap order for %s to %s from %s",
-		so.Amount.String(), reserveReturns, so.Address.String()))
+	logger.Info(fmt.Sprintf("cancelled failed %s order %d from %s", orderType, order.Id, order.Address.String()))
+	logger.Debug(fmt.Sprintf("cancellation reason: %s", reason))
 
 	ctx.EventManager().EmitEvent(sdk.NewEvent(
-		types.EventTypeOrderFulfill,
-		sdk.NewAttribute(types.AttributeKeyBond, bond.Token),
-		sdk.NewAttribute(types.AttributeKeyOrderType, types.AttributeValueSwapOrder),
-		sdk.NewAttribute(types.AttributeKeyAddress, so.Address.String()),
-		sdk.NewAttribute(types.AttributeKeyTokensSwapped, adjustedInput.String()),
-		sdk.NewAttribute(types.AttributeKeyChargedFees, txFee.String()),
-		sdk.NewAttribute(types.AttributeKeyReturnedToAddress, reserveReturns.String()),
+		types.EventTypeOrderCancel,
+		sdk.NewAttribute(types.AttributeKeyBond, token),
+		sdk.NewAttribute(types.AttributeKeyOrderId, fmt.Sprint(order.Id)),
+		sdk.NewAttribute(types.AttributeKeyOrderType, orderType),
+		sdk.NewAttribute(types.AttributeKeyAddress, order.Address.String()),
+		sdk.NewAttribute(types.AttributeKeyCancelReason, reason),
 	))
 
-	return nil, true
+	return nil
 }
 
-func (k Keeper) PerformBuyOrders(ctx sdk.Context, token string) {
-	batch := k.MustGetBatch(ctx, token)
+func (k Keeper) PerformBuyOrders(ctx sdk.Context, token string) error {
+	batch := k.MustGetBatchHeader(ctx, token)
+	batch.Buys = k.GetBatchBuyOrders(ctx, token)
 
-	// Perform buys or return to buyer
-	for _, bo := range batch.Buys {
+	// Perform buys or cancel and return reserve to buyer
+	for i, bo := range batch.Buys {
 		if !bo.IsCancelled() {
-			err := k.PerformBuyAtPrice(ctx, token, bo, batch.BuyPrices)
+			err := performInCacheContext(ctx, func(ctx sdk.Context) error {
+				return k.PerformBuyAtPrice(ctx, token, bo, batch.BuyPrices)
+			})
 			if err != nil {
-				// Panic here since all calculations should have been done
-				// correctly to prevent any errors during the buy
-				panic(err)
+				// Cancel (important to use batch.Buys[i] and not bo!)
+				batch.Buys[i].Cancelled = true
+				batch.Buys[i].CancelReason = err.Error()
+				batch.TotalBuyAmount = batch.TotalBuyAmount.Sub(bo.Amount)
+				batch.OrderCount--
+				k.SetBatchBuyOrder(ctx, token, batch.Buys[i])
+
+				// Return reserve to buyer
+				err = k.cancelFailedOrder(ctx, token, types.AttributeValueBuyOrder,
+					bo.BaseOrder, batch.Buys[i].CancelReason, func(ctx sdk.Context) error {
+						return k.SupplyKeeper.SendCoinsFromModuleToAccount(ctx,
+							types.BatchesIntermediaryAccount, bo.Address, bo.MaxPrices)
+					})
+				if err != nil {
+					k.SetBatchHeader(ctx, token, batch)
+					return err
+				}
 			}
 		}
 	}
 
-	// Update batch with any new changes (shouldn't be any)
-	k.SetBatch(ctx, token, batch)
+	// Update batch totals with any new cancellations
+	k.SetBatchHeader(ctx, token, batch)
+	return nil
 }
 
-func (k Keeper) PerformSellOrders(ctx sdk.Context, token string) {
-	batch := k.MustGetBatch(ctx, token)
+func (k Keeper) PerformSellOrders(ctx sdk.Context, token string) error {
+	batch := k.MustGetBatchHeader(ctx, token)
+	batch.Sells = k.GetBatchSellOrders(ctx, token)
 
-	// Perform sells or return to seller
-	for _, so := range batch.Sells {
+	// Perform sells or cancel and return bond tokens to seller
+	for i, so := range batch.Sells {
 		if !so.IsCancelled() {
-			err := k.PerformSellAtPrice(ctx, token, so, batch.SellPrices)
+			err := performInCacheContext(ctx, func(ctx sdk.Context) error {
+				return k.PerformSellAtPrice(ctx, token, so, batch.SellPrices)
+			})
 			if err != nil {
-				// Panic here since all calculations should have been done
-				// correctly to prevent any errors during the sell
-				panic(err)
+				// Cancel (important to use batch.Sells[i] and not so!)
+				batch.Sells[i].Cancelled = true
+				batch.Sells[i].CancelReason = err.Error()
+				batch.TotalSellAmount = batch.TotalSellAmount.Sub(so.Amount)
+				batch.OrderCount--
+				k.SetBatchSellOrder(ctx, token, batch.Sells[i])
+
+				// Re-mint bond tokens (burned during MsgSell) and return to seller
+				err = k.cancelFailedOrder(ctx, token, types.AttributeValueSellOrder,
+					so.BaseOrder, batch.Sells[i].CancelReason, func(ctx sdk.Context) error {
+						err := k.SupplyKeeper.MintCoins(ctx,
+							types.BondsMintBurnAccount, sdk.Coins{so.Amount})
+						if err != nil {
+							return err
+						}
+						return k.SupplyKeeper.SendCoinsFromModuleToAccount(ctx,
+							types.BondsMintBurnAccount, so.Address, sdk.Coins{so.Amount})
+					})
+				if err != nil {
+					k.SetBatchHeader(ctx, token, batch)
+					return err
+				}
 			}
 		}
 	}
 
-	// Update batch with any new changes (shouldn't be any)
-	k.SetBatch(ctx, token, batch)
+	// Update batch totals with any new cancellations
+	k.SetBatchHeader(ctx, token, batch)
+	return nil
 }
 
-func (k Keeper) PerformSwapOrders(ctx sdk.Context, token string) {
-	logger := ctx.Logger()
-	batch := k.MustGetBatch(ctx, token)
+// PerformSwapOrders performs the swaps in the bond's batch as a batch auction.
+// Swaps in opposite directions are netted against each other and the residual
+// is settled against the reserve, so that all swaps in the same direction get
+// a single clearing rate irrespective of their order in the batch. Any swap
+// that cannot be performed at the clearing rates (e.g. since its min returns
+// are not met) is cancelled and the clearing rates are recalculated without it.
+func (k Keeper) PerformSwapOrders(ctx sdk.Context, token string) error {
+	swaps := k.GetBatchSwapOrders(ctx, token)
+	bond := k.MustGetBond(ctx, token)
+	reserveBalances := k.GetReserveBalances(ctx, token)
 
-	// Perform swaps
-	// TODO: implement swaps front-running prevention
-	for i, so := range batch.Swaps {
-		if !so.IsCancelled() {
-			err, ok := k.PerformSwap(ctx, token, so)
-			if err != nil {
-				if ok {
-					batch.Swaps[i].Cancelled = true
-					batch.Swaps[i].CancelReason = err.Error()
-
-					logger.Info(fmt.Sprintf("cancelled swap order for %s to %s from %s", so.Amount.String(), so.ToToken, so.Address.String()))
-					logger.Debug(fmt.Sprintf("cancellation reason: %s", err.Error()))
-
-					ctx.EventManager().EmitEvent(sdk.NewEvent(
-						types.EventTypeOrderCancel,
-						sdk.NewAttribute(types.AttributeKeyBond, token),
-						sdk.NewAttribute(types.AttributeKeyOrderType, types.AttributeValueSwapOrder),
-						sdk.NewAttribute(types.AttributeKeyAddress, so.Address.String()),
-						sdk.NewAttribute(types.AttributeKeyCancelReason, batch.Swaps[i].CancelReason),
-					))
-
-					// Return from amount to swapper
-					err := k.SupplyKeeper.SendCoinsFromModuleToAccount(ctx,
-						types.BatchesIntermediaryAccount, so.Address, sdk.Coins{so.Amount})
-					if err != nil {
-						panic(err)
-					}
-				} else {
-					// Panic here since all calculations should have been done
-					// correctly to prevent any errors during the swap
-					panic(err)
+	// Cancel swaps one by one until the remaining swaps can all be performed
+	for {
+		i, err := checkSwapOrders(bond, swaps, reserveBalances)
+		if err == nil {
+			break
+		}
+
+		err = k.cancelFailedSwapOrder(ctx, token, &swaps[i], err.Error())
+		if err != nil {
+			return err
+		}
+	}
+
+	// Perform swaps or cancel all of them and return from amounts to swappers
+	err := performInCacheContext(ctx, func(ctx sdk.Context) error {
+		return k.PerformSwaps(ctx, token, swaps)
+	})
+	if err != nil {
+		reason := err.Error()
+		for i, so := range swaps {
+			if !so.IsCancelled() {
+				err = k.cancelFailedSwapOrder(ctx, token, &swaps[i], reason)
+				if err != nil {
+					return err
 				}
 			}
 		}
 	}
 
-	// Update batch with any new cancellations
-	k.SetBatch(ctx, token, batch)
+	return nil
+}
+
+// cancelFailedSwapOrder cancels the swap and returns the from amount to the
+// swapper.
+func (k Keeper) cancelFailedSwapOrder(ctx sdk.Context, token string, so *types.SwapOrder, reason string) error {
+	so.Cancelled = true
+	so.CancelReason = reason
+	k.SetBatchSwapOrder(ctx, token, *so)
+
+	batch := k.MustGetBatchHeader(ctx, token)
+	batch.OrderCount--
+	k.SetBatchHeader(ctx, token, batch)
+
+	return k.cancelFailedOrder(ctx, token, types.AttributeValueSwapOrder,
+		so.BaseOrder, reason, func(ctx sdk.Context) error {
+			return k.SupplyKeeper.SendCoinsFromModuleToAccount(ctx,
+				types.BatchesIntermediaryAccount, so.Address, sdk.Coins{so.Amount})
+		})
+}
+
+// PerformOrders performs the orders in the bond's batch, cancelling and
+// refunding any order that fails. An error is returned if a failed order could
+// not be refunded, in which case the bond's accounting is inconsistent.
+func (k Keeper) PerformOrders(ctx sdk.Context, token string) error {
+	err := k.PerformBuyOrders(ctx, token)
+	if err != nil {
+		return err
+	}
+	err = k.PerformSellOrders(ctx, token)
+	if err != nil {
+		return err
+	}
+	return k.PerformSwapOrders(ctx, token)
+}
+
+// PerformBatch performs the orders in the bond's batch and then checks the
+// bond's accounting. The orders are performed in a cached context, so that if
+// a failed order could not be refunded or if the bond's accounting is
+// inconsistent, none of the orders are performed and the error is returned.
+func (k Keeper) PerformBatch(ctx sdk.Context, token string) error {
+	return performInCacheContext(ctx, func(ctx sdk.Context) error {
+		err := k.PerformOrders(ctx, token)
+		if err != nil {
+			return err
+		}
+		return k.CheckBondAccounting(ctx, token)
+	})
 }
 
-func (k Keeper) PerformOrders(ctx sdk.Context, token string) {
-	k.PerformBuyOrders(ctx, token)
-	k.PerformSellOrders(ctx, token)
-	k.PerformSwapOrders(ctx, token)
+// CheckBondAccounting checks that the bond's accounting is consistent after
+// all of the orders in its batch have been performed, i.e. that the bond's
+// current supply matches the total supply of the bond token.
+func (k Keeper) CheckBondAccounting(ctx sdk.Context, token string) error {
+	bond := k.MustGetBond(ctx, token)
+	totalSupply := k.SupplyKeeper.GetSupply(ctx).GetTotal().AmountOf(token)
+	if !bond.CurrentSupply.Amount.Equal(totalSupply) {
+		return sdkerrors.Wrapf(types.ErrBondAccountingInconsistent,
+			"current supply %s does not match total supply %s",
+			bond.CurrentSupply.Amount, totalSupply)
+	}
+	return nil
 }
 
 func (k Keeper) CheckIfBuyOrderFulfillableAtPrice(ctx sdk.Context, token string, bo types.BuyOrder, prices sdk.DecCoins) error {
@@ -544,9 +1009,148 @@ func (k Keeper) CheckIfBuyOrderFulfillableAtPrice(ctx sdk.Context, token string,
 	return nil
 }
 
-func (k Keeper) CancelUnfulfillableBuys(ctx sdk.Context, token string) (cancelledOrders int) {
+func (k Keeper) CheckIfSellOrderFulfillableAtPrice(ctx sdk.Context, token string, so types.SellOrder, prices sdk.DecCoins) error {
+	bond := k.MustGetBond(ctx, token)
+
+	reserveReturns := types.MultiplyDecCoinsByInt(prices, so.Amount.Amount)
+	reserveReturnsRounded := types.RoundReserveReturns(reserveReturns)
+	txFees := bond.GetTxFees(reserveReturns)
+	exitFees := bond.GetExitFees(reserveReturns)
+	totalFees := types.AdjustFees(txFees.Add(exitFees...), reserveReturnsRounded)
+	totalReturns := reserveReturnsRounded.Sub(totalFees)
+
+	// Check that min returns met
+	if !totalReturns.IsAllGTE(so.MinReturns) {
+		return sdkerrors.Wrapf(types.ErrMinReturnsNotMet, "Actual returns %s are less than min returns %s", totalReturns, so.MinReturns)
+	}
+
+	return nil
+}
+
+// GetSpendBuyAmount returns the largest amount of bond tokens that can be
+// added to the bond's current batch as a buy by spending at most the specified
+// reserve tokens (including tx fees). The amount is first estimated using the
+// inverse of the bonding curve and is then adjusted by searching for the
+// largest amount that the spend can pay for at the updated batch prices. The
+// amount is capped by the max supply and the bond's order quantity limits.
+func (k Keeper) GetSpendBuyAmount(ctx sdk.Context, token string, buyer sdk.AccAddress, spend sdk.Coins) (sdk.Int, error) {
+	bond := k.MustGetBond(ctx, token)
+	reserveBalances := k.GetReserveBalances(ctx, token)
+
+	// Estimate amount from the spend excluding tx fees
+	ctx.GasMeter().ConsumeGas(types.CurveEvaluationGasCost, "bonding curve evaluation")
+	estimate, err := bond.GetAmountToMint(bond.GetSpendExcludingTxFees(spend), reserveBalances)
+	if err != nil {
+		return sdk.Int{}, err
+	}
+
+	// Amount cannot exceed the max supply or the order quantity limit
+	limit := bond.MaxSupply.Sub(k.GetSupplyAdjustedForBuy(ctx, token)).Amount
+	if quantityLimit := bond.OrderQuantityLimits.AmountOf(token); quantityLimit.IsPositive() {
+		limit = sdk.MinInt(limit, quantityLimit)
+	}
+	if !limit.IsPositive() {
+		return sdk.ZeroInt(), nil
+	}
+
+	fulfillable := func(amount sdk.Int) bool {
+		bo := types.NewSpendBuyOrder(buyer, sdk.NewCoin(token, amount), spend)
+		_, _, err := k.GetUpdatedBatchPricesAfterBuy(ctx, token, bo)
+		return err == nil
+	}
+
+	// Keep the largest amount known to be fulfillable (lo) and the smallest
+	// amount known to be unfulfillable (hi), starting from the estimate
+	lo := sdk.ZeroInt()
+	hi := limit.AddRaw(1)
+	if estimate = sdk.MinInt(estimate, limit); estimate.IsPositive() {
+		if fulfillable(estimate) {
+			lo = estimate
+		} else {
+			hi = estimate
+		}
+	}
+
+	// If the estimate is fulfillable, it might be an underestimate (e.g. if
+	// the batch already has sells), so keep doubling until an upper bound
+	for lo.IsPositive() && lo.LT(limit) && hi.GT(limit) {
+		next := sdk.MinInt(lo.MulRaw(2), limit)
+		if fulfillable(next) {
+			lo = next
+		} else {
+			hi = next
+		}
+	}
+
+	// Binary search for the largest fulfillable amount
+	for hi.Sub(lo).GT(sdk.OneInt()) {
+		mid := lo.Add(hi).QuoRaw(2)
+		if fulfillable(mid) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	return lo, nil
+}
+
+// AdjustUnfulfillableBuys partially fills any buy that is not fulfillable at
+// the batch's buy prices by reducing its amount to the largest amount that its
+// max prices can pay for at those prices. The unspent reserve is returned to
+// the buyer when the reduced order is performed. Buys that cannot pay for any
+// amount are left unchanged so that they are cancelled by CancelUnfulfillableBuys.
+func (k Keeper) AdjustUnfulfillableBuys(ctx sdk.Context, token string) (adjustedOrders int) {
 	logger := k.Logger(ctx)
-	batch := k.MustGetBatch(ctx, token)
+	batch := k.MustGetBatchHeader(ctx, token)
+	batch.Buys = k.GetBatchBuyOrders(ctx, token)
+
+	for i, bo := range batch.Buys {
+		if bo.IsCancelled() ||
+			k.CheckIfBuyOrderFulfillableAtPrice(ctx, token, bo, batch.BuyPrices) == nil {
+			continue
+		}
+
+		// Binary search for the largest fulfillable amount below the current one
+		lo := sdk.ZeroInt()
+		hi := bo.Amount.Amount
+		for hi.Sub(lo).GT(sdk.OneInt()) {
+			mid := lo.Add(hi).QuoRaw(2)
+			reduced := bo
+			reduced.Amount = sdk.NewCoin(token, mid)
+			if k.CheckIfBuyOrderFulfillableAtPrice(ctx, token, reduced, batch.BuyPrices) == nil {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		if lo.IsZero() {
+			continue
+		}
+
+		// Adjust (important to use batch.Buys[i] and not bo!)
+		reduction := bo.Amount.Sub(sdk.NewCoin(token, lo))
+		batch.Buys[i].Amount = sdk.NewCoin(token, lo)
+		batch.TotalBuyAmount = batch.TotalBuyAmount.Sub(reduction)
+		k.SetBatchBuyOrder(ctx, token, batch.Buys[i])
+		adjustedOrders += 1
+
+		logger.Info(fmt.Sprintf("reduced buy order %d from %s to %s from %s",
+			bo.Id, bo.Amount.String(), batch.Buys[i].Amount.String(), bo.Address.String()))
+	}
+
+	// Save batch totals and return number of adjusted orders
+	k.SetBatchHeader(ctx, token, batch)
+	return adjustedOrders
+}
+
+// CancelUnfulfillableBuys cancels any buy that is not fulfillable at the batch's
+// buy prices and refunds its max prices to the buyer. An error is returned if
+// a refund fails, in which case the bond should be quarantined.
+func (k Keeper) CancelUnfulfillableBuys(ctx sdk.Context, token string) (cancelledOrders int, err error) {
+	logger := k.Logger(ctx)
+	batch := k.MustGetBatchHeader(ctx, token)
+	batch.Buys = k.GetBatchBuyOrders(ctx, token)
 
 	// Cancel unfulfillable buys
 	for i, bo := range batch.Buys {
@@ -557,14 +1161,17 @@ func (k Keeper) CancelUnfulfillableBuys(ctx sdk.Context, token string) (cancelle
 				batch.Buys[i].Cancelled = true
 				batch.Buys[i].CancelReason = err.Error()
 				batch.TotalBuyAmount = batch.TotalBuyAmount.Sub(bo.Amount)
+				batch.OrderCount--
+				k.SetBatchBuyOrder(ctx, token, batch.Buys[i])
 				cancelledOrders += 1
 
-				logger.Info(fmt.Sprintf("cancelled buy order for %s from %s", bo.Amount.String(), bo.Address.String()))
+				logger.Info(fmt.Sprintf("cancelled buy order %d for %s from %s", bo.Id, bo.Amount.String(), bo.Address.String()))
 				logger.Debug(fmt.Sprintf("cancellation reason: %s", err.Error()))
 
 				ctx.EventManager().EmitEvent(sdk.NewEvent(
 					types.EventTypeOrderCancel,
 					sdk.NewAttribute(types.AttributeKeyBond, token),
+					sdk.NewAttribute(types.AttributeKeyOrderId, fmt.Sprint(bo.Id)),
 					sdk.NewAttribute(types.AttributeKeyOrderType, types.AttributeValueBuyOrder),
 					sdk.NewAttribute(types.AttributeKeyAddress, bo.Address.String()),
 					sdk.NewAttribute(types.AttributeKeyCancelReason, batch.Buys[i].CancelReason),
@@ -574,37 +1181,236 @@ func (k Keeper) CancelUnfulfillableBuys(ctx sdk.Context, token string) (cancelle
 				err := k.SupplyKeeper.SendCoinsFromModuleToAccount(ctx,
 					types.BatchesIntermediaryAccount, bo.Address, bo.MaxPrices)
 				if err != nil {
-					panic(err)
+					return 0, err
+				}
+			}
+		}
+	}
+
+	// Save batch totals and return number of cancelled orders
+	k.SetBatchHeader(ctx, token, batch)
+	return cancelledOrders, nil
+}
+
+// CancelUnfulfillableSells cancels any sell that is not fulfillable at the
+// batch's sell prices and returns the bond tokens to the seller. An error is
+// returned if this fails, in which case the bond should be quarantined.
+func (k Keeper) CancelUnfulfillableSells(ctx sdk.Context, token string) (cancelledOrders int, err error) {
+	logger := k.Logger(ctx)
+	batch := k.MustGetBatchHeader(ctx, token)
+	batch.Sells = k.GetBatchSellOrders(ctx, token)
+
+	// Cancel unfulfillable sells
+	for i, so := range batch.Sells {
+		if !so.IsCancelled() {
+			err := k.CheckIfSellOrderFulfillableAtPrice(ctx, token, so, batch.SellPrices)
+			if err != nil {
+				// Cancel (important to use batch.Sells[i] and not so!)
+				batch.Sells[i].Cancelled = true
+				batch.Sells[i].CancelReason = err.Error()
+				batch.TotalSellAmount = batch.TotalSellAmount.Sub(so.Amount)
+				batch.OrderCount--
+				k.SetBatchSellOrder(ctx, token, batch.Sells[i])
+				cancelledOrders += 1
+
+				logger.Info(fmt.Sprintf("cancelled sell order %d for %s from %s", so.Id, so.Amount.String(), so.Address.String()))
+				logger.Debug(fmt.Sprintf("cancellation reason: %s", err.Error()))
+
+				ctx.EventManager().EmitEvent(sdk.NewEvent(
+					types.EventTypeOrderCancel,
+					sdk.NewAttribute(types.AttributeKeyBond, token),
+					sdk.NewAttribute(types.AttributeKeyOrderId, fmt.Sprint(so.Id)),
+					sdk.NewAttribute(types.AttributeKeyOrderType, types.AttributeValueSellOrder),
+					sdk.NewAttribute(types.AttributeKeyAddress, so.Address.String()),
+					sdk.NewAttribute(types.AttributeKeyCancelReason, batch.Sells[i].CancelReason),
+				))
+
+				// Re-mint bond tokens (burned during MsgSell) and return to seller
+				err := k.SupplyKeeper.MintCoins(ctx, types.BondsMintBurnAccount, sdk.Coins{so.Amount})
+				if err != nil {
+					return 0, err
+				}
+				err = k.SupplyKeeper.SendCoinsFromModuleToAccount(ctx,
+					types.BondsMintBurnAccount, so.Address, sdk.Coins{so.Amount})
+				if err != nil {
+					return 0, err
 				}
 			}
 		}
 	}
 
-	// Save batch and return number of cancelled orders
-	k.SetBatch(ctx, token, batch)
-	return cancelledOrders
+	// Save batch totals and return number of cancelled orders
+	k.SetBatchHeader(ctx, token, batch)
+	return cancelledOrders, nil
 }
 
-func (k Keeper) CancelUnfulfillableOrders(ctx sdk.Context, token string) (cancelledOrders int) {
-	batch := k.MustGetBatch(ctx, token)
+func (k Keeper) CancelUnfulfillableOrders(ctx sdk.Context, token string) (cancelledOrders int, err error) {
 	cancelledOrders = 0
 
-	cancelledOrders += k.CancelUnfulfillableBuys(ctx, token)
-	//cancelledOrders += k.CancelUnfulfillableSells(ctx, token) // Sells always fulfillable
-	//cancelledOrders += k.CancelUnfulfillableSwaps(ctx, token) // Swaps only cancelled while they are being performed
+	// Since updating the prices after an adjustment or cancellation can make
+	// other orders unfulfillable (e.g. cancelling a buy lowers the sell price
+	// when there are more sells than buys), repeat until no orders are affected.
+	// Buy amounts only ever decrease, so the batch prices converge.
+	for {
+		adjustedOrders := k.AdjustUnfulfillableBuys(ctx, token)
+		cancelledBuys, err := k.CancelUnfulfillableBuys(ctx, token)
+		if err != nil {
+			return 0, err
+		}
+		cancelledSells, err := k.CancelUnfulfillableSells(ctx, token)
+		if err != nil {
+			return 0, err
+		}
+		cancelled := cancelledBuys + cancelledSells
+		//cancelled += k.CancelUnfulfillableSwaps(ctx, token) // Swaps only cancelled while they are being performed
+
+		if adjustedOrders == 0 && cancelled == 0 {
+			break
+		}
+		cancelledOrders += cancelled
 
-	// Update buy and sell prices if any cancellation took place
-	if cancelledOrders > 0 {
-		batch = k.MustGetBatch(ctx, token) // get batch again
+		// Update buy and sell prices since an adjustment or cancellation took place
+		batch := k.MustGetBatchHeader(ctx, token) // get batch again
 		buyPrices, sellPrices, err := k.GetBatchBuySellPrices(ctx, token, batch)
 		if err != nil {
-			panic(err)
+			return 0, err
 		}
 		batch.BuyPrices = buyPrices
 		batch.SellPrices = sellPrices
+		k.SetBatchHeader(ctx, token, batch)
+	}
+
+	return cancelledOrders, nil
+}
+
+// cancelOwnedOrder marks the order as cancelled on behalf of its owner, or
+// returns an error if the order belongs to someone else or is already cancelled.
+func cancelOwnedOrder(order *types.BaseOrder, owner sdk.AccAddress) error {
+	if !order.Address.Equals(owner) {
+		return sdkerrors.Wrapf(types.ErrOrderNotOwnedBySender, "order %d", order.Id)
+	} else if order.IsCancelled() {
+		return sdkerrors.Wrapf(types.ErrOrderAlreadyCancelled, "order %d", order.Id)
+	}
+	order.Cancelled = true
+	order.CancelReason = "cancelled by owner"
+	return nil
+}
+
+// refundBuyOrder returns the max prices of the buy to the buyer
+func (k Keeper) refundBuyOrder(ctx sdk.Context, bo types.BuyOrder) error {
+	return k.SupplyKeeper.SendCoinsFromModuleToAccount(ctx,
+		types.BatchesIntermediaryAccount, bo.Address, bo.MaxPrices)
+}
+
+// refundSellOrder mints the bond tokens burned by the sell and returns them to
+// the seller
+func (k Keeper) refundSellOrder(ctx sdk.Context, so types.SellOrder) error {
+	err := k.SupplyKeeper.MintCoins(ctx, types.BondsMintBurnAccount, sdk.Coins{so.Amount})
+	if err != nil {
+		return err
+	}
+	return k.SupplyKeeper.SendCoinsFromModuleToAccount(ctx,
+		types.BondsMintBurnAccount, so.Address, sdk.Coins{so.Amount})
+}
+
+// refundSwapOrder returns the from amount of the swap to the swapper
+func (k Keeper) refundSwapOrder(ctx sdk.Context, so types.SwapOrder) error {
+	return k.SupplyKeeper.SendCoinsFromModuleToAccount(ctx,
+		types.BatchesIntermediaryAccount, so.Address, sdk.Coins{so.Amount})
+}
+
+// CancelOrder cancels the order with the specified ID in the bond's current
+// batch (or among its rolled-over orders or limit orders) on behalf of the
+// order's owner and refunds it, i.e. the max prices of a buy, the from amount
+// of a swap and the escrow of a limit order are returned, and the bond tokens
+// burned by a sell are minted and returned. If a buy or sell in the batch is
+// cancelled, the batch prices are recomputed and any orders that become
+// unfulfillable as a result are also cancelled. The type of the cancelled
+// order is returned.
+func (k Keeper) CancelOrder(ctx sdk.Context, token string, owner sdk.AccAddress, id uint64) (orderType string, err error) {
+	if k.IsRolledOverOrder(ctx, token, id) {
+		return k.cancelRolledOverOrder(ctx, token, owner, id)
+	} else if order, found := k.GetLimitOrder(ctx, id); found && order.Amount.Denom == token {
+		return k.cancelLimitOrder(ctx, order, owner)
+	}
+
+	batch := k.MustGetBatchHeader(ctx, token)
+
+	var order types.BaseOrder
+	if bo, found := k.GetBatchBuyOrder(ctx, token, id); found {
+		err = cancelOwnedOrder(&bo.BaseOrder, owner)
+		if err != nil {
+			return "", err
+		}
+		batch.TotalBuyAmount = batch.TotalBuyAmount.Sub(bo.Amount)
+		k.SetBatchBuyOrder(ctx, token, bo)
+		order, orderType = bo.BaseOrder, types.AttributeValueBuyOrder
+
+		// Return reserve to buyer
+		err = k.refundBuyOrder(ctx, bo)
+		if err != nil {
+			return "", err
+		}
+	} else if so, found := k.GetBatchSellOrder(ctx, token, id); found {
+		err = cancelOwnedOrder(&so.BaseOrder, owner)
+		if err != nil {
+			return "", err
+		}
+		batch.TotalSellAmount = batch.TotalSellAmount.Sub(so.Amount)
+		k.SetBatchSellOrder(ctx, token, so)
+		order, orderType = so.BaseOrder, types.AttributeValueSellOrder
+
+		// Re-mint bond tokens (burned during MsgSell) and return to seller
+		err = k.refundSellOrder(ctx, so)
+		if err != nil {
+			return "", err
+		}
+	} else if so, found := k.GetBatchSwapOrder(ctx, token, id); found {
+		err = cancelOwnedOrder(&so.BaseOrder, owner)
+		if err != nil {
+			return "", err
+		}
+		k.SetBatchSwapOrder(ctx, token, so)
+		order, orderType = so.BaseOrder, types.AttributeValueSwapOrder
+
+		// Return from amount to swapper
+		err = k.refundSwapOrder(ctx, so)
+		if err != nil {
+			return "", err
+		}
+	}
+	if orderType == "" {
+		return "", sdkerrors.Wrapf(types.ErrOrderNotFound, "order %d in batch of %s", id, token)
+	}
+	batch.OrderCount-- // cancelled orders no longer count towards the maximum
+
+	// Update buy and sell prices if a buy or sell was cancelled
+	if orderType != types.AttributeValueSwapOrder {
+		batch.BuyPrices, batch.SellPrices, err = k.GetBatchBuySellPrices(ctx, token, batch)
+		if err != nil {
+			return "", err
+		}
+	}
+	k.SetBatchHeader(ctx, token, batch)
+
+	logger := k.Logger(ctx)
+	logger.Info(fmt.Sprintf("cancelled %s order %d from %s", orderType, id, owner.String()))
+
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		types.EventTypeOrderCancel,
+		sdk.NewAttribute(types.AttributeKeyBond, token),
+		sdk.NewAttribute(types.AttributeKeyOrderId, fmt.Sprint(order.Id)),
+		sdk.NewAttribute(types.AttributeKeyOrderType, orderType),
+		sdk.NewAttribute(types.AttributeKeyAddress, order.Address.String()),
+		sdk.NewAttribute(types.AttributeKeyCancelReason, order.CancelReason),
+	))
+
+	// Cancel orders that became unfulfillable with the new prices (e.g. sells
+	// whose min returns are no longer met once a buy is cancelled)
+	_, err = k.CancelUnfulfillableOrders(ctx, token)
+	if err != nil {
+		return "", err
 	}
 
-	// Save batch and return number of cancelled orders
-	k.SetBatch(ctx, token, batch)
-	return cancelledOrders
+	return orderType, nil
 }
@@ -0,0 +1,132 @@
+package keeper
+
+import (
+	"encoding/binary"
+	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/warmage-sports/peyote/x/peyote/internal/types"
+)
+
+// maxInt is the largest value of an int, used to check that the number of
+// batch records skipped to reach a page does not overflow.
+const maxInt = int(^uint(0) >> 1)
+
+// ArchiveBatch adds the record of a performed batch to the bond's batch
+// history, unless batch records are not being kept (zero retention).
+func (k Keeper) ArchiveBatch(ctx sdk.Context, batch types.Batch) {
+	if k.GetParams(ctx).BatchHistoryRetention == 0 {
+		return
+	}
+	k.SetBatchRecord(ctx, types.NewBatchRecord(batch))
+}
+
+// SetBatchRecord stores the batch record, indexed by height so that it can be
+// pruned once it is older than the batch history retention.
+func (k Keeper) SetBatchRecord(ctx sdk.Context, record types.BatchRecord) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(types.GetBatchRecordKey(record.Token, record.Height), k.cdc.MustMarshalBinaryBare(record))
+	store.Set(types.GetBatchHistoryHeightKey(record.Token, record.Height), []byte(record.Token))
+}
+
+func (k Keeper) GetBatchRecord(ctx sdk.Context, token string, height int64) (record types.BatchRecord, found bool) {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(types.GetBatchRecordKey(token, height))
+	if bz == nil {
+		return
+	}
+	k.cdc.MustUnmarshalBinaryBare(bz, &record)
+	return record, true
+}
+
+func (k Keeper) GetBatchHistoryIterator(ctx sdk.Context) sdk.Iterator {
+	store := ctx.KVStore(k.storeKey)
+	return sdk.KVStorePrefixIterator(store, types.BatchHistoryKeyPrefix)
+}
+
+func (k Keeper) MustGetBatchRecordByKey(ctx sdk.Context, key []byte) types.BatchRecord {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(key)
+	if bz == nil {
+		panic(fmt.Sprintf("batch record not found for key %X", key))
+	}
+
+	var record types.BatchRecord
+	k.cdc.MustUnmarshalBinaryBare(bz, &record)
+	return record
+}
+
+// GetBatchHistory returns a page of the bond's batch records, from the most
+// recent one backwards. Pages are numbered from one, and at most
+// MaxBatchHistoryLimit records are returned. No records are returned if the
+// page or limit is not positive, or if the page is out of range.
+func (k Keeper) GetBatchHistory(ctx sdk.Context, token string, page, limit int) (records []types.BatchRecord) {
+	if limit > types.MaxBatchHistoryLimit {
+		limit = types.MaxBatchHistoryLimit
+	}
+	if page < 1 || limit < 1 || page-1 > maxInt/limit {
+		return nil
+	}
+
+	store := ctx.KVStore(k.storeKey)
+	iterator := sdk.KVStoreReversePrefixIterator(store, types.GetBatchHistoryPrefixKey(token))
+	defer iterator.Close()
+
+	skip := (page - 1) * limit
+	for ; iterator.Valid() && len(records) < limit; iterator.Next() {
+		if skip > 0 {
+			skip--
+			continue
+		}
+
+		var record types.BatchRecord
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &record)
+		records = append(records, record)
+	}
+	return records
+}
+
+// RemoveBatchHistory removes all of the bond's batch records.
+func (k Keeper) RemoveBatchHistory(ctx sdk.Context, token string) {
+	store := ctx.KVStore(k.storeKey)
+	prefix := types.GetBatchHistoryPrefixKey(token)
+
+	var keys [][]byte
+	iterator := sdk.KVStorePrefixIterator(store, prefix)
+	for ; iterator.Valid(); iterator.Next() {
+		keys = append(keys, iterator.Key())
+	}
+	iterator.Close()
+
+	for _, key := range keys {
+		height := int64(binary.BigEndian.Uint64(key[len(prefix):]))
+		store.Delete(types.GetBatchHistoryHeightKey(token, height))
+		store.Delete(key)
+	}
+}
+
+// PruneBatchHistory removes the batch records of any bond that are at least
+// as many blocks old as the batch history retention.
+func (k Keeper) PruneBatchHistory(ctx sdk.Context) {
+	retention := k.GetParams(ctx).BatchHistoryRetention
+	if uint64(ctx.BlockHeight()) < retention {
+		return
+	}
+	cutoff := ctx.BlockHeight() - int64(retention)
+
+	store := ctx.KVStore(k.storeKey)
+	end := sdk.PrefixEndBytes(types.GetBatchHistoryHeightPrefixKey(cutoff))
+
+	var keys [][]byte
+	iterator := store.Iterator(types.BatchHistoryHeightsPrefix, end)
+	for ; iterator.Valid(); iterator.Next() {
+		keys = append(keys, iterator.Key())
+	}
+	iterator.Close()
+
+	for _, key := range keys {
+		height := int64(binary.BigEndian.Uint64(key[1:9]))
+		token := string(key[9:])
+		store.Delete(types.GetBatchRecordKey(token, height))
+		store.Delete(key)
+	}
+}
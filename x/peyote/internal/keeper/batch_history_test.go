@@ -0,0 +1,122 @@
+package keeper_test
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+	"github.com/warmage-sports/peyote/x/peyote/app"
+	"github.com/warmage-sports/peyote/x/peyote/internal/types"
+	"testing"
+)
+
+func archiveTestBatches(app *simapp.SimApp, ctx sdk.Context, token string, heights ...int64) {
+	for _, h := range heights {
+		app.BondsKeeper.ArchiveBatch(ctx, types.NewBatch(token, h, sdk.OneUint(), sdk.ZeroUint()))
+	}
+}
+
+func getBatchHistoryHeights(app *simapp.SimApp, ctx sdk.Context, token string, page, limit int) (heights []int64) {
+	for _, r := range app.BondsKeeper.GetBatchHistory(ctx, token, page, limit) {
+		heights = append(heights, r.Height)
+	}
+	return heights
+}
+
+func TestArchiveBatchGetBatchHistory(t *testing.T) {
+	app, ctx := createTestApp(false)
+
+	// No batch records initially
+	require.Len(t, app.BondsKeeper.GetBatchHistory(ctx, token, 1, 10), 0)
+
+	// Archive batches
+	archiveTestBatches(app, ctx, token, 1, 2, 3, 4, 5)
+	archiveTestBatches(app, ctx, token+"2", 6)
+
+	// Batch record can be fetched by height
+	record, found := app.BondsKeeper.GetBatchRecord(ctx, token, 3)
+	require.True(t, found)
+	require.Equal(t, types.NewBatchRecord(types.NewBatch(token, 3, sdk.OneUint(), sdk.ZeroUint())), record)
+	_, found = app.BondsKeeper.GetBatchRecord(ctx, token, 6)
+	require.False(t, found)
+
+	// Batch history is paginated from the most recent batch backwards
+	require.Equal(t, []int64{5, 4}, getBatchHistoryHeights(app, ctx, token, 1, 2))
+	require.Equal(t, []int64{3, 2}, getBatchHistoryHeights(app, ctx, token, 2, 2))
+	require.Equal(t, []int64{1}, getBatchHistoryHeights(app, ctx, token, 3, 2))
+	require.Len(t, getBatchHistoryHeights(app, ctx, token, 4, 2), 0)
+
+	// No batch records for a page or limit that is not positive
+	require.Len(t, getBatchHistoryHeights(app, ctx, token, 0, 2), 0)
+	require.Len(t, getBatchHistoryHeights(app, ctx, token, 1, 0), 0)
+	require.Len(t, getBatchHistoryHeights(app, ctx, token, -1, -2), 0)
+
+	// No batch records for a page so large that the skipped records overflow
+	require.Len(t, getBatchHistoryHeights(app, ctx, token, int(^uint(0)>>1), 2), 0)
+}
+
+func TestGetBatchHistoryLimitIsCapped(t *testing.T) {
+	app, ctx := createTestApp(false)
+	for h := int64(1); h <= types.MaxBatchHistoryLimit+1; h++ {
+		archiveTestBatches(app, ctx, token, h)
+	}
+
+	// At most MaxBatchHistoryLimit batch records are returned
+	records := app.BondsKeeper.GetBatchHistory(ctx, token, 1, types.MaxBatchHistoryLimit+1)
+	require.Len(t, records, types.MaxBatchHistoryLimit)
+}
+
+func TestRemoveBatchHistory(t *testing.T) {
+	app, ctx := createTestApp(false)
+	app.BondsKeeper.SetParams(ctx, types.NewParams(nil, 10, types.DefaultMaxBatchOrders, types.DefaultMaxBatchVolume, types.DefaultMaxDistributions, false,
+		types.DefaultMaxLimitOrderMatches, types.DefaultMinLimitOrderAmount, types.DefaultMaxRecurringOrders,
+		types.DefaultMaxLimitOrderExpiry, sdk.Coins{}))
+	archiveTestBatches(app, ctx, token, 1, 5)
+	archiveTestBatches(app, ctx, token+"2", 1)
+
+	// Only the bond's batch records are removed
+	app.BondsKeeper.RemoveBatchHistory(ctx, token)
+	require.Len(t, getBatchHistoryHeights(app, ctx, token, 1, 10), 0)
+	require.Equal(t, []int64{1}, getBatchHistoryHeights(app, ctx, token+"2", 1, 10))
+
+	// Pruning does not find the removed batch records
+	ctx = ctx.WithBlockHeight(20)
+	app.BondsKeeper.PruneBatchHistory(ctx)
+	require.Len(t, getBatchHistoryHeights(app, ctx, token+"2", 1, 10), 0)
+	iterator := app.BondsKeeper.GetBatchHistoryIterator(ctx)
+	require.False(t, iterator.Valid())
+	iterator.Close()
+}
+
+func TestArchiveBatchWithZeroRetention(t *testing.T) {
+	app, ctx := createTestApp(false)
+	app.BondsKeeper.SetParams(ctx, types.NewParams(nil, 0, types.DefaultMaxBatchOrders, types.DefaultMaxBatchVolume, types.DefaultMaxDistributions, false,
+		types.DefaultMaxLimitOrderMatches, types.DefaultMinLimitOrderAmount, types.DefaultMaxRecurringOrders,
+		types.DefaultMaxLimitOrderExpiry, sdk.Coins{}))
+
+	// Batch is not archived
+	archiveTestBatches(app, ctx, token, 1)
+	require.Len(t, app.BondsKeeper.GetBatchHistory(ctx, token, 1, 10), 0)
+}
+
+func TestPruneBatchHistory(t *testing.T) {
+	app, ctx := createTestApp(false)
+	app.BondsKeeper.SetParams(ctx, types.NewParams(nil, 10, types.DefaultMaxBatchOrders, types.DefaultMaxBatchVolume, types.DefaultMaxDistributions, false,
+		types.DefaultMaxLimitOrderMatches, types.DefaultMinLimitOrderAmount, types.DefaultMaxRecurringOrders,
+		types.DefaultMaxLimitOrderExpiry, sdk.Coins{}))
+	archiveTestBatches(app, ctx, token, 1, 5, 12)
+	archiveTestBatches(app, ctx, token+"2", 1)
+
+	// Nothing pruned before the retention has passed
+	ctx = ctx.WithBlockHeight(10)
+	app.BondsKeeper.PruneBatchHistory(ctx)
+	require.Equal(t, []int64{12, 5, 1}, getBatchHistoryHeights(app, ctx, token, 1, 10))
+
+	// Batch records at least as old as the retention are pruned, for all bonds
+	ctx = ctx.WithBlockHeight(11)
+	app.BondsKeeper.PruneBatchHistory(ctx)
+	require.Equal(t, []int64{12, 5}, getBatchHistoryHeights(app, ctx, token, 1, 10))
+	require.Len(t, getBatchHistoryHeights(app, ctx, token+"2", 1, 10), 0)
+
+	ctx = ctx.WithBlockHeight(16)
+	app.BondsKeeper.PruneBatchHistory(ctx)
+	require.Equal(t, []int64{12}, getBatchHistoryHeights(app, ctx, token, 1, 10))
+}
@@ -0,0 +1,83 @@
+package keeper
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/warmage-sports/peyote/x/peyote/internal/types"
+)
+
+// ScheduleBatch adds the bond's current batch to the batch queue, so that it
+// is performed at the end of its execution height. If the bond accepts order
+// commitments, the start of the batch's reveal phase is also queued. Only the
+// batches of bonds with pending orders are scheduled, so that the EndBlocker
+// does not need to visit every bond in every block.
+func (k Keeper) ScheduleBatch(ctx sdk.Context, token string) {
+	bond := k.MustGetBond(ctx, token)
+	batch := k.MustGetBatchHeader(ctx, token)
+
+	store := ctx.KVStore(k.storeKey)
+	store.Set(types.GetBatchQueueKey(batch.ExecutionHeight, token), []byte(token))
+
+	if bond.AcceptsOrderCommitments() {
+		revealHeight := batch.ExecutionHeight - int64(bond.RevealBlocks.Uint64())
+		if revealHeight >= ctx.BlockHeight() {
+			store.Set(types.GetBatchQueueKey(revealHeight, token), []byte(token))
+		}
+	}
+}
+
+// GetQueuedBatches returns the tokens of the bonds that were queued for any
+// height up to and including the current height, by height and then by token.
+func (k Keeper) GetQueuedBatches(ctx sdk.Context) (tokens []string) {
+	store := ctx.KVStore(k.storeKey)
+	end := sdk.PrefixEndBytes(types.GetBatchQueuePrefixKey(ctx.BlockHeight()))
+
+	iterator := store.Iterator(types.BatchQueueKeyPrefix, end)
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		tokens = append(tokens, string(iterator.Value()))
+	}
+	return tokens
+}
+
+// RemoveQueuedBatches removes the entries of the batch queue for any height up
+// to and including the current height.
+func (k Keeper) RemoveQueuedBatches(ctx sdk.Context) {
+	store := ctx.KVStore(k.storeKey)
+	end := sdk.PrefixEndBytes(types.GetBatchQueuePrefixKey(ctx.BlockHeight()))
+
+	var keys [][]byte
+	iterator := store.Iterator(types.BatchQueueKeyPrefix, end)
+	for ; iterator.Valid(); iterator.Next() {
+		keys = append(keys, iterator.Key())
+	}
+	iterator.Close()
+
+	for _, key := range keys {
+		store.Delete(key)
+	}
+}
+
+// HasPendingOrders returns true if the bond has any orders in its current
+// batch, any order commitments, any limit orders in its order book, any
+// rolled-over orders, or any recurring orders.
+func (k Keeper) HasPendingOrders(ctx sdk.Context, token string) bool {
+	store := ctx.KVStore(k.storeKey)
+	prefixes := [][]byte{
+		types.GetBatchOrdersPrefixKey(token),
+		types.GetOrderCommitmentsPrefixKey(token),
+		types.GetLimitOrderBookPrefixKey(token, true),
+		types.GetLimitOrderBookPrefixKey(token, false),
+		types.GetRolledOverOrdersPrefixKey(token),
+		types.GetRecurringOrderBondPrefixKey(token),
+	}
+	for _, prefix := range prefixes {
+		iterator := sdk.KVStorePrefixIterator(store, prefix)
+		valid := iterator.Valid()
+		iterator.Close()
+		if valid {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package keeper_test
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestScheduleBatchGetRemoveQueuedBatches(t *testing.T) {
+	app, ctx := createTestApp(false)
+	app.BondsKeeper.SetBond(ctx, token, getValidBond())
+	app.BondsKeeper.SetBatch(ctx, token, getValidBatch())
+	executionHeight := app.BondsKeeper.MustGetBatchHeader(ctx, token).ExecutionHeight
+
+	// Nothing queued initially
+	require.Len(t, app.BondsKeeper.GetQueuedBatches(ctx), 0)
+
+	// Schedule batch
+	app.BondsKeeper.ScheduleBatch(ctx, token)
+
+	// Batch is not returned before its execution height
+	ctx = ctx.WithBlockHeight(executionHeight - 1)
+	require.Len(t, app.BondsKeeper.GetQueuedBatches(ctx), 0)
+
+	// Batch is returned at its execution height
+	ctx = ctx.WithBlockHeight(executionHeight)
+	require.Equal(t, []string{token}, app.BondsKeeper.GetQueuedBatches(ctx))
+
+	// Batch is no longer returned once removed from the queue
+	app.BondsKeeper.RemoveQueuedBatches(ctx)
+	require.Len(t, app.BondsKeeper.GetQueuedBatches(ctx), 0)
+}
+
+func TestScheduleBatchQueuesRevealPhase(t *testing.T) {
+	app, ctx := createTestApp(false)
+	bond := getValidBond()
+	bond.BatchBlocks = batchBlocks
+	bond.RevealBlocks = sdk.NewUint(2)
+	app.BondsKeeper.SetBond(ctx, token, bond)
+	app.BondsKeeper.SetBatch(ctx, token, getValidBatch())
+	executionHeight := app.BondsKeeper.MustGetBatchHeader(ctx, token).ExecutionHeight
+	revealHeight := executionHeight - int64(bond.RevealBlocks.Uint64())
+
+	app.BondsKeeper.ScheduleBatch(ctx, token)
+
+	// Batch is returned at the start of its reveal phase
+	ctx = ctx.WithBlockHeight(revealHeight)
+	require.Equal(t, []string{token}, app.BondsKeeper.GetQueuedBatches(ctx))
+	app.BondsKeeper.RemoveQueuedBatches(ctx)
+
+	// Batch is returned again at its execution height
+	ctx = ctx.WithBlockHeight(executionHeight)
+	require.Equal(t, []string{token}, app.BondsKeeper.GetQueuedBatches(ctx))
+}
+
+func TestHasPendingOrders(t *testing.T) {
+	app, ctx := createTestApp(false)
+	app.BondsKeeper.SetBond(ctx, token, getValidBond())
+	app.BondsKeeper.SetBatch(ctx, token, getValidBatch())
+
+	// No pending orders initially
+	require.False(t, app.BondsKeeper.HasPendingOrders(ctx, token))
+
+	// Batch order is pending
+	app.BondsKeeper.AddBuyOrder(ctx, token, getValidBuyOrder(), nil, nil)
+	require.True(t, app.BondsKeeper.HasPendingOrders(ctx, token))
+
+	// Order commitment is pending
+	app.BondsKeeper.SetBatch(ctx, token, getValidBatch())
+	require.False(t, app.BondsKeeper.HasPendingOrders(ctx, token))
+	commitment := app.BondsKeeper.AddOrderCommitment(ctx, newTestOrderCommitment(buyerAddress, 10))
+	require.True(t, app.BondsKeeper.HasPendingOrders(ctx, token))
+
+	// Limit order is pending
+	app.BondsKeeper.RemoveOrderCommitment(ctx, commitment)
+	require.False(t, app.BondsKeeper.HasPendingOrders(ctx, token))
+	app.BondsKeeper.AddLimitOrder(ctx, newTestLimitBuy(10, 600, 100))
+	require.True(t, app.BondsKeeper.HasPendingOrders(ctx, token))
+}
@@ -1,6 +1,7 @@
 package keeper_test
 
 import (
+	"errors"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/warmage-sports/peyote/x/peyote/internal/types"
 	"github.com/stretchr/testify/require"
@@ -49,17 +50,65 @@ func TestLastBatchExistsSetGet(t *testing.T) {
 	require.Equal(t, batchAdded, batchFetched)
 }
 
+func TestBatchOrdersStoredSeparatelyFromHeader(t *testing.T) {
+	app, ctx := createTestApp(false)
+
+	// Add batch with orders
+	batchAdded := getValidBatch()
+	bo := getValidBuyOrder()
+	bo.Id = 2
+	so := getValidSellOrder()
+	so.Id = 1
+	swapOrder := getValidSwapOrder()
+	swapOrder.Id = 3
+	batchAdded.Buys = []types.BuyOrder{bo}
+	batchAdded.Sells = []types.SellOrder{so}
+	batchAdded.Swaps = []types.SwapOrder{swapOrder}
+	app.BondsKeeper.SetBatch(ctx, token, batchAdded)
+
+	// Header does not include the orders, but full batch does
+	header := app.BondsKeeper.MustGetBatchHeader(ctx, token)
+	require.Nil(t, header.Buys)
+	require.Nil(t, header.Sells)
+	require.Nil(t, header.Swaps)
+	require.Equal(t, batchAdded, app.BondsKeeper.MustGetBatch(ctx, token))
+
+	// Orders can be fetched individually
+	fetchedBuy, found := app.BondsKeeper.GetBatchBuyOrder(ctx, token, bo.Id)
+	require.True(t, found)
+	require.Equal(t, bo, fetchedBuy)
+	_, found = app.BondsKeeper.GetBatchBuyOrder(ctx, token, so.Id)
+	require.False(t, found)
+
+	// Setting the header leaves the orders unchanged
+	header.TotalBuyAmount = bo.Amount
+	app.BondsKeeper.SetBatchHeader(ctx, token, header)
+	batchFetched := app.BondsKeeper.MustGetBatch(ctx, token)
+	require.Equal(t, bo.Amount, batchFetched.TotalBuyAmount)
+	require.Equal(t, batchAdded.Buys, batchFetched.Buys)
+	require.Equal(t, batchAdded.Sells, batchFetched.Sells)
+	require.Equal(t, batchAdded.Swaps, batchFetched.Swaps)
+
+	// Setting a new batch removes the orders of the previous batch
+	app.BondsKeeper.SetBatch(ctx, token, getValidBatch())
+	require.Equal(t, getValidBatch(), app.BondsKeeper.MustGetBatch(ctx, token))
+	_, found = app.BondsKeeper.GetBatchBuyOrder(ctx, token, bo.Id)
+	require.False(t, found)
+}
+
 func TestBatchAddBuyOrder(t *testing.T) {
 	app, ctx := createTestApp(false)
 
-	// Add batch
+	// Add bond and batch
+	app.BondsKeeper.SetBond(ctx, token, getValidBond())
 	batchAdded := getValidBatch()
 	app.BondsKeeper.SetBatch(ctx, token, batchAdded)
 	require.True(t, app.BondsKeeper.BatchExists(ctx, token))
 
 	// Add buy order
 	bo := getValidBuyOrder()
-	app.BondsKeeper.AddBuyOrder(ctx, token, bo, buyPrices, sellPrices)
+	bo = app.BondsKeeper.AddBuyOrder(ctx, token, bo, buyPrices, sellPrices)
+	require.Equal(t, uint64(1), bo.Id)
 
 	// Get and check batch
 	batchFetched := app.BondsKeeper.MustGetBatch(ctx, token)
@@ -74,14 +123,16 @@ func TestBatchAddBuyOrder(t *testing.T) {
 func TestBatchAddSellOrder(t *testing.T) {
 	app, ctx := createTestApp(false)
 
-	// Add batch
+	// Add bond and batch
+	app.BondsKeeper.SetBond(ctx, token, getValidBond())
 	batchAdded := getValidBatch()
 	app.BondsKeeper.SetBatch(ctx, token, batchAdded)
 	require.True(t, app.BondsKeeper.BatchExists(ctx, token))
 
 	// Add sell order
 	so := getValidSellOrder()
-	app.BondsKeeper.AddSellOrder(ctx, token, so, buyPrices, sellPrices)
+	so = app.BondsKeeper.AddSellOrder(ctx, token, so, buyPrices, sellPrices)
+	require.Equal(t, uint64(1), so.Id)
 
 	// Get and check batch
 	batchFetched := app.BondsKeeper.MustGetBatch(ctx, token)
@@ -96,14 +147,16 @@ func TestBatchAddSellOrder(t *testing.T) {
 func TestBatchAddSwapOrder(t *testing.T) {
 	app, ctx := createTestApp(false)
 
-	// Add batch
+	// Add bond and batch
+	app.BondsKeeper.SetBond(ctx, token, getValidBond())
 	batchAdded := getValidBatch()
 	app.BondsKeeper.SetBatch(ctx, token, batchAdded)
 	require.True(t, app.BondsKeeper.BatchExists(ctx, token))
 
 	// Add swap order
 	swapOrder := getValidSwapOrder()
-	app.BondsKeeper.AddSwapOrder(ctx, token, swapOrder)
+	swapOrder = app.BondsKeeper.AddSwapOrder(ctx, token, swapOrder)
+	require.Equal(t, uint64(1), swapOrder.Id)
 
 	// Get and check batch
 	batchFetched := app.BondsKeeper.MustGetBatch(ctx, token)
@@ -131,7 +184,7 @@ func TestGetBatchBuySellPrices(t *testing.T) {
 	fiveDec := sdk.NewDec(5)
 
 	// Add appropriate amount of reserve tokens (freshly minted) to reserve
-	expectedReserve := bond.ReserveAtSupply(bond.CurrentSupply.Amount)
+	expectedReserve, _ := bond.ReserveAtSupply(bond.CurrentSupply.Amount)
 	expectedRounded := expectedReserve.Ceil().TruncateInt()
 	reserveBalance := sdk.NewCoins(sdk.NewCoin(bond.ReserveTokens[0], expectedRounded))
 	err := app.SupplyKeeper.MintCoins(ctx, types.BondsMintBurnAccount, reserveBalance)
@@ -173,12 +226,12 @@ func TestGetBatchBuySellPrices(t *testing.T) {
 
 	// (Re)Create batch with sell order
 	batch = getValidBatch()
-	so := types.NewSellOrder(sellerAddress, fiveTokens)
+	so := types.NewSellOrder(sellerAddress, fiveTokens, nil)
 	batch.Sells = append(batch.Sells, so)
 	batch.TotalSellAmount = batch.TotalSellAmount.Add(so.Amount)
 
 	// Calculate expected sell price
-	expectedReturns := bond.GetReturnsForBurn(so.Amount.Amount, reserveBalance)
+	expectedReturns, _ := bond.GetReturnsForBurn(so.Amount.Amount, reserveBalance)
 	require.NotNil(t, expectedReturns)
 	expectedSellPricesPerToken := types.DivideDecCoinsByDec(expectedReturns, fiveDec)
 
@@ -194,7 +247,7 @@ func TestGetBatchBuySellPrices(t *testing.T) {
 	batch = getValidBatch()
 	bo1 := types.NewBuyOrder(buyerAddress, fiveTokens, nil)
 	bo2 := types.NewBuyOrder(buyerAddress, fiveTokens, nil) // 5 more
-	so = types.NewSellOrder(sellerAddress, fiveTokens)
+	so = types.NewSellOrder(sellerAddress, fiveTokens, nil)
 	batch.Buys = append(batch.Buys, bo1, bo2)
 	batch.Sells = append(batch.Sells, so)
 	batch.TotalBuyAmount = batch.TotalBuyAmount.Add(bo1.Amount).Add(bo2.Amount)
@@ -219,15 +272,15 @@ func TestGetBatchBuySellPrices(t *testing.T) {
 	// (Re)Create batch with sell amount > buy amount
 	batch = getValidBatch()
 	bo = types.NewBuyOrder(buyerAddress, fiveTokens, nil)
-	so1 := types.NewSellOrder(sellerAddress, fiveTokens)
-	so2 := types.NewSellOrder(sellerAddress, fiveTokens)
+	so1 := types.NewSellOrder(sellerAddress, fiveTokens, nil)
+	so2 := types.NewSellOrder(sellerAddress, fiveTokens, nil)
 	batch.Buys = append(batch.Buys, bo)
 	batch.Sells = append(batch.Sells, so1, so2)
 	batch.TotalBuyAmount = batch.TotalBuyAmount.Add(bo1.Amount)
 	batch.TotalSellAmount = batch.TotalSellAmount.Add(so1.Amount).Add(so2.Amount)
 
 	// Calculate expected sell price (for 5 [burn-price] + 5 [current-price] tokens)
-	expectedReturns1 := bond.GetReturnsForBurn(fiveTokens.Amount, reserveBalance)
+	expectedReturns1, _ := bond.GetReturnsForBurn(fiveTokens.Amount, reserveBalance)
 	require.Nil(t, err)
 	require.NotNil(t, expectedReturns1)
 	expectedReturns2 := currentPrices.MulDec(fiveDec)
@@ -241,6 +294,19 @@ func TestGetBatchBuySellPrices(t *testing.T) {
 	require.Nil(t, err)
 }
 
+func TestGetBatchBuySellPricesConsumesGas(t *testing.T) {
+	app, ctx := createTestApp(false)
+
+	bond := getValidBond()
+	app.BondsKeeper.SetBond(ctx, token, bond)
+
+	gasBefore := ctx.GasMeter().GasConsumed()
+	_, _, err := app.BondsKeeper.GetBatchBuySellPrices(ctx, bond.Token, getValidBatch())
+	require.Nil(t, err)
+	require.GreaterOrEqual(t, ctx.GasMeter().GasConsumed()-gasBefore,
+		uint64(types.CurveEvaluationGasCost))
+}
+
 func TestGetUpdatedBatchPricesAfterBuy(t *testing.T) {
 	app, ctx := createTestApp(false)
 
@@ -290,7 +356,7 @@ func TestGetUpdatedBatchPricesAfterSell(t *testing.T) {
 	sellAmount := sdk.NewCoin(bond.Token, sdk.OneInt())
 
 	// Sell order when current supply is zero is not fulfillable
-	so := types.NewSellOrder(sellerAddress, sellAmount)
+	so := types.NewSellOrder(sellerAddress, sellAmount, nil)
 	_, _, err := app.BondsKeeper.GetUpdatedBatchPricesAfterSell(ctx, bond.Token, so)
 	require.Error(t, err)
 
@@ -304,10 +370,10 @@ func TestGetUpdatedBatchPricesAfterSell(t *testing.T) {
 		ctx, bond.Token, types.BondsMintBurnAccount, reserveBalance)
 
 	// Check sell prices for fulfillable sell order
-	so = types.NewSellOrder(sellerAddress, sellAmount)
+	so = types.NewSellOrder(sellerAddress, sellAmount, nil)
 	buyPrices, sellPrices, err = app.BondsKeeper.GetUpdatedBatchPricesAfterSell(ctx, bond.Token, so)
 	expectedBuyPrices, _ := bond.GetCurrentPricesPT(nil)
-	expectedSellPrices := bond.GetReturnsForBurn(sellAmount.Amount, reserveBalance)
+	expectedSellPrices, _ := bond.GetReturnsForBurn(sellAmount.Amount, reserveBalance)
 	require.Nil(t, err)
 	require.Equal(t, expectedBuyPrices, buyPrices)
 	require.Equal(t, expectedSellPrices, sellPrices)
@@ -384,7 +450,7 @@ func TestPerformBuyAtPrice(t *testing.T) {
 			require.NoError(t, err)
 		} else {
 			require.Error(t, err)
-			continue // app would panic at this stage
+			continue // order would be cancelled at this stage
 		}
 
 		// Calculate increase in buyer balance
@@ -493,7 +559,7 @@ func TestPerformBuyAtPriceAugmentedFunction(t *testing.T) {
 			require.NoError(t, err)
 		} else {
 			require.Error(t, err)
-			continue // app would panic at this stage
+			continue // order would be cancelled at this stage
 		}
 
 		// Calculate increase in buyer balance
@@ -561,7 +627,7 @@ func TestPerformSellAtPrice(t *testing.T) {
 
 	for _, tc := range testCases {
 		// Create sell order
-		so := types.NewSellOrder(sellerAddress, sellAmount)
+		so := types.NewSellOrder(sellerAddress, sellAmount, nil)
 
 		// Set transaction and exit fee and current supply
 		bond.TxFeePercentage = tc.txFee
@@ -675,7 +741,7 @@ func TestPerformSwap(t *testing.T) {
 		fromAmount := sdk.NewCoin(tc.fromToken, swapAmount)
 		fromAmounts := sdk.Coins{fromAmount}
 		fromAmountsDec := sdk.DecCoins{sdk.NewDecCoinFromCoin(fromAmount)}
-		so := types.NewSwapOrder(swapperAddress, fromAmount, tc.toToken)
+		so := types.NewSwapOrder(swapperAddress, fromAmount, tc.toToken, nil)
 
 		// Set transaction fee, sanity rates, and initial reserve balances
 		bond.TxFeePercentage = tc.txFee
@@ -708,13 +774,12 @@ func TestPerformSwap(t *testing.T) {
 		prevSwapperBal := app.BankKeeper.GetCoins(ctx, swapperAddress)
 
 		// Perform swap
-		err, ok := app.BondsKeeper.PerformSwap(ctx, bond.Token, so)
-		require.True(t, ok)
+		err = app.BondsKeeper.PerformSwaps(ctx, bond.Token, []types.SwapOrder{so})
 
 		// Check if error due to violated sanity rate
 		if tc.sanityRateViolated {
 			require.Error(t, err)
-			continue // app would panic at this stage
+			continue // order would be cancelled at this stage
 		} else {
 			require.NoError(t, err)
 		}
@@ -737,6 +802,37 @@ func TestPerformSwap(t *testing.T) {
 	}
 }
 
+func TestPerformSwapMinReturnsNotMet(t *testing.T) {
+	app, ctx := createTestApp(false)
+
+	// Create swapper bond (with no fees) with reserve 200res,300rez
+	bond := getValidSwapperBond()
+	bond.TxFeePercentage = sdk.ZeroDec()
+	app.BondsKeeper.SetBond(ctx, bond.Token, bond)
+	reserves := sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 200), sdk.NewInt64Coin(reserveToken2, 300))
+	require.NoError(t, app.SupplyKeeper.MintCoins(ctx, types.BondsMintBurnAccount, reserves))
+	require.NoError(t, app.BondsKeeper.DepositReserveFromModule(
+		ctx, bond.Token, types.BondsMintBurnAccount, reserves))
+
+	// Add reserve tokens sent by swapper to module account address
+	from := sdk.NewInt64Coin(reserveToken, 100)
+	moduleAcc := app.SupplyKeeper.GetModuleAccount(ctx, types.BatchesIntermediaryAccount)
+	require.NoError(t, app.BankKeeper.SetCoins(ctx, moduleAcc.GetAddress(), sdk.Coins{from}))
+
+	// Swapping 100res gives 300-(200*300)/(200+100)=100rez, which is less than 101rez
+	minReturns := sdk.NewCoins(sdk.NewInt64Coin(reserveToken2, 101))
+	so := types.NewSwapOrder(swapperAddress, from, reserveToken2, minReturns)
+	err := app.BondsKeeper.PerformSwaps(ctx, bond.Token, []types.SwapOrder{so})
+	require.True(t, errors.Is(err, types.ErrMinReturnsNotMet))
+
+	// Swap is performed if the min returns are met
+	minReturns = sdk.NewCoins(sdk.NewInt64Coin(reserveToken2, 100))
+	so = types.NewSwapOrder(swapperAddress, from, reserveToken2, minReturns)
+	err = app.BondsKeeper.PerformSwaps(ctx, bond.Token, []types.SwapOrder{so})
+	require.NoError(t, err)
+	require.Equal(t, minReturns, app.BankKeeper.GetCoins(ctx, swapperAddress))
+}
+
 func TestPerformBuys(t *testing.T) {
 	app, ctx := createTestApp(false)
 
@@ -842,7 +938,7 @@ func TestPerformSells(t *testing.T) {
 	for _, tc := range testCases {
 		// Create and add sell order
 		amount := sdk.NewCoin(bond.Token, tc.amount)
-		so := types.NewSellOrder(sellerAddress, amount)
+		so := types.NewSellOrder(sellerAddress, amount, nil)
 		app.BondsKeeper.AddSellOrder(ctx, token, so, blankBuyPrices, sellPrices)
 
 		// Calculate total return
@@ -877,6 +973,150 @@ func TestPerformSells(t *testing.T) {
 	require.Equal(t, globalTotalReturns, newSellerBal)
 }
 
+func TestPerformBuyOrdersCancelsFailedOrders(t *testing.T) {
+	app, ctx := createTestApp(false)
+
+	// Create bond and batch (with no fees for simpler test)
+	bond := getValidBond()
+	batch := getValidBatch()
+	bond.TxFeePercentage = sdk.ZeroDec()
+	bond.ExitFeePercentage = sdk.ZeroDec()
+	app.BondsKeeper.SetBond(ctx, bond.Token, bond)
+	app.BondsKeeper.SetBatch(ctx, bond.Token, batch)
+
+	// Add buy order with max prices less than the buy prices (10 * 100 > 500)
+	buyPrices := sdk.DecCoins{sdk.NewInt64DecCoin(reserveToken, 100)}
+	maxPrices := sdk.Coins{sdk.NewInt64Coin(reserveToken, 500)}
+	amount := sdk.NewCoin(bond.Token, sdk.NewInt(10))
+	bo := types.NewBuyOrder(buyerAddress, amount, maxPrices)
+	app.BondsKeeper.AddBuyOrder(ctx, token, bo, buyPrices, sdk.DecCoins{})
+
+	// Add reserve tokens paid by buyer to module account address
+	moduleAcc := app.SupplyKeeper.GetModuleAccount(ctx, types.BatchesIntermediaryAccount)
+	_, err := app.BankKeeper.AddCoins(ctx, moduleAcc.GetAddress(), maxPrices)
+	require.NoError(t, err)
+
+	// Perform buys
+	err = app.BondsKeeper.PerformBuyOrders(ctx, token)
+	require.NoError(t, err)
+
+	// Buy order cancelled and max prices returned to buyer
+	batch = app.BondsKeeper.MustGetBatch(ctx, token)
+	require.True(t, batch.Buys[0].Cancelled)
+	require.True(t, batch.TotalBuyAmount.IsZero())
+	require.Equal(t, uint64(0), batch.OrderCount)
+	require.Equal(t, maxPrices, app.BankKeeper.GetCoins(ctx, buyerAddress))
+	require.True(t, app.BankKeeper.GetCoins(ctx, moduleAcc.GetAddress()).IsZero())
+	require.True(t, app.BondsKeeper.MustGetBond(ctx, token).CurrentSupply.IsZero())
+	require.True(t, app.SupplyKeeper.GetSupply(ctx).GetTotal().AmountOf(token).IsZero())
+
+	// Cancellation event emitted
+	events := ctx.EventManager().Events()
+	require.Equal(t, types.EventTypeOrderCancel, events[len(events)-1].Type)
+}
+
+func TestPerformSellOrdersCancelsFailedOrders(t *testing.T) {
+	app, ctx := createTestApp(false)
+
+	// Create bond and batch (with no fees for simpler test)
+	bond := getValidBond()
+	batch := getValidBatch()
+	bond.TxFeePercentage = sdk.ZeroDec()
+	bond.ExitFeePercentage = sdk.ZeroDec()
+	app.BondsKeeper.SetBond(ctx, bond.Token, bond)
+	app.BondsKeeper.SetBatch(ctx, bond.Token, batch)
+
+	// Add sell order (simulating that the sold tokens were bought and burned)
+	sellPrices := sdk.DecCoins{sdk.NewInt64DecCoin(reserveToken, 100)}
+	amount := sdk.NewCoin(bond.Token, sdk.NewInt(10))
+	so := types.NewSellOrder(sellerAddress, amount, nil)
+	app.BondsKeeper.AddSellOrder(ctx, token, so, sdk.DecCoins{}, sellPrices)
+	app.BondsKeeper.SetCurrentSupply(ctx, bond.Token, amount)
+
+	// Perform sells (fails since reserve is empty)
+	err := app.BondsKeeper.PerformSellOrders(ctx, token)
+	require.NoError(t, err)
+
+	// Sell order cancelled and burned tokens re-minted to seller
+	batch = app.BondsKeeper.MustGetBatch(ctx, token)
+	require.True(t, batch.Sells[0].Cancelled)
+	require.True(t, batch.TotalSellAmount.IsZero())
+	require.Equal(t, uint64(0), batch.OrderCount)
+	require.Equal(t, sdk.Coins{amount}, app.BankKeeper.GetCoins(ctx, sellerAddress))
+	require.Equal(t, amount, app.BondsKeeper.MustGetBond(ctx, token).CurrentSupply)
+	require.Equal(t, amount.Amount, app.SupplyKeeper.GetSupply(ctx).GetTotal().AmountOf(token))
+	require.NoError(t, app.BondsKeeper.CheckBondAccounting(ctx, token))
+}
+
+func TestPerformBuyOrdersReturnsErrorIfRefundFails(t *testing.T) {
+	app, ctx := createTestApp(false)
+
+	// Create bond and batch
+	bond := getValidBond()
+	batch := getValidBatch()
+	app.BondsKeeper.SetBond(ctx, bond.Token, bond)
+	app.BondsKeeper.SetBatch(ctx, bond.Token, batch)
+
+	// Add failing buy order without adding max prices to module account
+	buyPrices := sdk.DecCoins{sdk.NewInt64DecCoin(reserveToken, 100)}
+	maxPrices := sdk.Coins{sdk.NewInt64Coin(reserveToken, 500)}
+	amount := sdk.NewCoin(bond.Token, sdk.NewInt(10))
+	bo := types.NewBuyOrder(buyerAddress, amount, maxPrices)
+	app.BondsKeeper.AddBuyOrder(ctx, token, bo, buyPrices, sdk.DecCoins{})
+
+	// Perform buys
+	err := app.BondsKeeper.PerformBuyOrders(ctx, token)
+	require.True(t, errors.Is(err, types.ErrBondAccountingInconsistent))
+}
+
+func TestPerformBatchPerformsNoOrdersIfAccountingInconsistent(t *testing.T) {
+	app, ctx := createTestApp(false)
+
+	// Create bond and batch (with no fees for simpler test)
+	bond := getValidBond()
+	bond.TxFeePercentage = sdk.ZeroDec()
+	app.BondsKeeper.SetBond(ctx, bond.Token, bond)
+	app.BondsKeeper.SetBatch(ctx, bond.Token, getValidBatch())
+
+	// Add buy order that can be performed (10 * 10 <= 500)
+	buyPrices := sdk.DecCoins{sdk.NewInt64DecCoin(reserveToken, 10)}
+	maxPrices := sdk.Coins{sdk.NewInt64Coin(reserveToken, 500)}
+	bo := types.NewBuyOrder(buyerAddress, sdk.NewInt64Coin(token, 10), maxPrices)
+	app.BondsKeeper.AddBuyOrder(ctx, token, bo, buyPrices, sdk.DecCoins{})
+	moduleAcc := app.SupplyKeeper.GetModuleAccount(ctx, types.BatchesIntermediaryAccount)
+	_, err := app.BankKeeper.AddCoins(ctx, moduleAcc.GetAddress(), maxPrices)
+	require.NoError(t, err)
+
+	// Batch is performed if accounting is consistent
+	cacheCtx, _ := ctx.CacheContext()
+	require.NoError(t, app.BondsKeeper.PerformBatch(cacheCtx, token))
+	require.Equal(t, int64(10), app.BondsKeeper.MustGetBond(cacheCtx, token).CurrentSupply.Amount.Int64())
+
+	// Increase current supply without minting any tokens
+	app.BondsKeeper.SetCurrentSupply(ctx, bond.Token, sdk.NewInt64Coin(token, 5))
+
+	// Buy is not performed if accounting is inconsistent
+	err = app.BondsKeeper.PerformBatch(ctx, token)
+	require.True(t, errors.Is(err, types.ErrBondAccountingInconsistent))
+	require.Equal(t, int64(5), app.BondsKeeper.MustGetBond(ctx, token).CurrentSupply.Amount.Int64())
+	require.True(t, app.BankKeeper.GetCoins(ctx, buyerAddress).IsZero())
+	require.False(t, app.BondsKeeper.MustGetBatch(ctx, token).Buys[0].Cancelled)
+}
+
+func TestCheckBondAccounting(t *testing.T) {
+	app, ctx := createTestApp(false)
+
+	// Create bond
+	bond := getValidBond()
+	app.BondsKeeper.SetBond(ctx, bond.Token, bond)
+	require.NoError(t, app.BondsKeeper.CheckBondAccounting(ctx, token))
+
+	// Increase current supply without minting any tokens
+	app.BondsKeeper.SetCurrentSupply(ctx, bond.Token, sdk.NewInt64Coin(token, 10))
+	err := app.BondsKeeper.CheckBondAccounting(ctx, token)
+	require.True(t, errors.Is(err, types.ErrBondAccountingInconsistent))
+}
+
 func TestPerformSwaps(t *testing.T) {
 	app, ctx := createTestApp(false)
 
@@ -891,88 +1131,104 @@ func TestPerformSwaps(t *testing.T) {
 	app.BondsKeeper.SetBatch(ctx, bond.Token, batch)
 
 	// Set initial reserves
-	initialInReserve := sdk.NewInt64Coin(reserveToken, 200)
-	initialOutReserve := sdk.NewInt64Coin(reserveToken2, 300)
-	initialReserves := sdk.NewCoins(initialInReserve, initialOutReserve)
+	initialReserves := sdk.NewCoins(
+		sdk.NewInt64Coin(reserveToken, 200), sdk.NewInt64Coin(reserveToken2, 300))
 	err := app.SupplyKeeper.MintCoins(ctx, types.BondsMintBurnAccount, initialReserves)
 	require.Nil(t, err)
 	err = app.BondsKeeper.DepositReserveFromModule(
 		ctx, bond.Token, types.BondsMintBurnAccount, initialReserves)
 	require.NoError(t, err)
 
+	// Swaps from res to rez by swapperAddress and from rez to res by buyerAddress
 	testCases := []struct {
-		fromToken        string
-		toToken          string
-		amount           sdk.Int
-		willGetCancelled bool
+		address sdk.AccAddress
+		from    sdk.Coin
+		toToken string
 	}{
-		{
-			reserveToken, reserveToken2, sdk.NewInt(100), false,
-		}, // 100res to rez
-		{
-			reserveToken2, reserveToken, sdk.NewInt(200), false,
-		}, // 200rez to res
-		{
-			reserveToken, reserveToken2, sdk.NewInt(300), false,
-		}, // 300res to rez
-		{
-			reserveToken2, reserveToken, sdk.NewInt(400), false,
-		}, // 400rez to res
-		{
-			reserveToken, reserveToken2, sdk.NewInt(1000), true,
-		}, // 1000res to rez, to violate sanity
+		{swapperAddress, sdk.NewInt64Coin(reserveToken, 100), reserveToken2},
+		{buyerAddress, sdk.NewInt64Coin(reserveToken2, 200), reserveToken},
+		{swapperAddress, sdk.NewInt64Coin(reserveToken, 300), reserveToken2},
+		{buyerAddress, sdk.NewInt64Coin(reserveToken2, 400), reserveToken},
+		{swapperAddress, sdk.NewInt64Coin(reserveToken, 10000), reserveToken2},
 	}
 
-	globalTotalReturns := sdk.NewCoins()
-	globalIncreaseInSwapperBal := sdk.NewCoins()
-	globalDecreaseInSwapperBal := sdk.NewCoins()
-	globalReserveBal := initialReserves
-
-	// Previous values
+	// Add swap orders and reserve tokens sent by swappers to module account
 	moduleAcc := app.SupplyKeeper.GetModuleAccount(ctx, types.BatchesIntermediaryAccount)
-
-	// Add swap orders
 	for _, tc := range testCases {
-		// Constant product
-		inReserve := sdk.NewCoin(tc.fromToken, globalReserveBal.AmountOf(tc.fromToken))
-		outReserve := sdk.NewCoin(tc.toToken, globalReserveBal.AmountOf(tc.toToken))
-		cp := inReserve.Amount.Mul(outReserve.Amount).ToDec()
-
-		// Create and add swap order
-		fromAmount := sdk.NewCoin(tc.fromToken, tc.amount)
-		fromAmounts := sdk.Coins{fromAmount}
-		so := types.NewSwapOrder(swapperAddress, fromAmount, tc.toToken)
+		so := types.NewSwapOrder(tc.address, tc.from, tc.toToken, nil)
 		app.BondsKeeper.AddSwapOrder(ctx, token, so)
-
-		// Add reserve tokens sent by swapper to module account address
-		_, err = app.BankKeeper.AddCoins(ctx, moduleAcc.GetAddress(), fromAmounts)
+		_, err = app.BankKeeper.AddCoins(ctx, moduleAcc.GetAddress(), sdk.Coins{tc.from})
 		require.NoError(t, err)
-
-		if tc.willGetCancelled {
-			// Reserve returned back to swapper
-			globalIncreaseInSwapperBal = globalIncreaseInSwapperBal.Add(fromAmounts...)
-		} else {
-			// Calculations
-			newInReserveDec := inReserve.Amount.Add(fromAmount.Amount).ToDec()
-			newOutReserveDec := sdk.NewDecCoinFromDec(tc.toToken, cp.Quo(newInReserveDec))
-			totalOuts := sdk.Coins{types.RoundReserveReturn(sdk.NewDecCoinFromCoin(outReserve).Sub(newOutReserveDec))} // out of reserves (i.e. returns)
-			globalIncreaseInSwapperBal = globalIncreaseInSwapperBal.Add(totalOuts...)
-			globalDecreaseInSwapperBal = globalDecreaseInSwapperBal.Add(fromAmounts...)
-			globalReserveBal = globalReserveBal.Add(fromAmounts...).Sub(totalOuts)
-		}
 	}
 
 	// Perform swaps
-	app.BondsKeeper.PerformSwapOrders(ctx, token)
+	err = app.BondsKeeper.PerformSwapOrders(ctx, token)
+	require.NoError(t, err)
 
-	// New balances
-	newModuleAccBal := app.BankKeeper.GetCoins(ctx, moduleAcc.GetAddress())
-	newReserveBal := app.BondsKeeper.GetReserveBalances(ctx, bond.Token)
-	newSwapperBal := app.BankKeeper.GetCoins(ctx, sellerAddress)
+	// With all swaps, the reserves would become 3534res,18rez (a rate of 196),
+	// violating the sanity rate, so the latest swap from res is cancelled
+	batch = app.BondsKeeper.MustGetBatch(ctx, token)
+	for i, so := range batch.Swaps {
+		require.Equal(t, i == 4, so.IsCancelled())
+	}
+	require.Contains(t, batch.Swaps[4].CancelReason, types.ErrValuesViolateSanityRate.Error())
+
+	// The remaining swaps have total inputs 400res,600rez and are all settled
+	// at the same rates: (300+600)/(200+400)=1.5rez per res and 600/900 res per rez
+	//   100res -> 150rez, 300res -> 450rez, 200rez -> 133res, 400rez -> 266res
+	require.Equal(t, sdk.Coins(nil), app.BankKeeper.GetCoins(ctx, moduleAcc.GetAddress()))
+	require.Equal(t, sdk.NewCoins(
+		sdk.NewInt64Coin(reserveToken, 10000), sdk.NewInt64Coin(reserveToken2, 600)),
+		app.BankKeeper.GetCoins(ctx, swapperAddress))
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 399)),
+		app.BankKeeper.GetCoins(ctx, buyerAddress))
+	require.Equal(t, sdk.NewCoins(
+		sdk.NewInt64Coin(reserveToken, 201), sdk.NewInt64Coin(reserveToken2, 300)),
+		app.BondsKeeper.GetReserveBalances(ctx, bond.Token))
+}
 
-	require.Equal(t, sdk.Coins(nil), newModuleAccBal)
-	require.Equal(t, globalReserveBal, newReserveBal)
-	require.Equal(t, globalTotalReturns, newSwapperBal)
+func TestPerformSwapsOrderIndependent(t *testing.T) {
+	// The same swaps are submitted in opposite orders to two different bonds
+	swaps := []types.SwapOrder{
+		types.NewSwapOrder(swapperAddress, sdk.NewInt64Coin(reserveToken, 500), reserveToken2, nil),
+		types.NewSwapOrder(swapperAddress, sdk.NewInt64Coin(reserveToken, 100), reserveToken2, nil),
+		types.NewSwapOrder(buyerAddress, sdk.NewInt64Coin(reserveToken2, 300), reserveToken, nil),
+	}
+	reversed := []types.SwapOrder{swaps[2], swaps[1], swaps[0]}
+
+	var returns []sdk.Coins
+	for _, orders := range [][]types.SwapOrder{swaps, reversed} {
+		app, ctx := createTestApp(false)
+
+		// Create swapper bond (with no fees) with reserve 1000res,1000rez
+		bond := getValidSwapperBond()
+		bond.TxFeePercentage = sdk.ZeroDec()
+		app.BondsKeeper.SetBond(ctx, bond.Token, bond)
+		app.BondsKeeper.SetBatch(ctx, bond.Token, getValidBatch())
+		reserves := sdk.NewCoins(
+			sdk.NewInt64Coin(reserveToken, 1000), sdk.NewInt64Coin(reserveToken2, 1000))
+		require.NoError(t, app.SupplyKeeper.MintCoins(ctx, types.BondsMintBurnAccount, reserves))
+		require.NoError(t, app.BondsKeeper.DepositReserveFromModule(
+			ctx, bond.Token, types.BondsMintBurnAccount, reserves))
+
+		moduleAcc := app.SupplyKeeper.GetModuleAccount(ctx, types.BatchesIntermediaryAccount)
+		for _, so := range orders {
+			app.BondsKeeper.AddSwapOrder(ctx, token, so)
+			_, err := app.BankKeeper.AddCoins(ctx, moduleAcc.GetAddress(), sdk.Coins{so.Amount})
+			require.NoError(t, err)
+		}
+		require.NoError(t, app.BondsKeeper.PerformSwapOrders(ctx, token))
+
+		returns = append(returns, app.BankKeeper.GetCoins(ctx, swapperAddress),
+			app.BankKeeper.GetCoins(ctx, buyerAddress))
+	}
+
+	// Clearing rate is (1000+300)/(1000+600) rez per res, so 600res -> 487rez
+	// (i.e. 406+81), and 300rez -> 369res, irrespective of the order of swaps
+	require.Equal(t, returns[0], returns[2])
+	require.Equal(t, returns[1], returns[3])
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin(reserveToken2, 487)), returns[0])
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 369)), returns[1])
 }
 
 func TestOrderCancelled(t *testing.T) {
@@ -1090,7 +1346,8 @@ func TestCancelUnfulfillableBuys(t *testing.T) {
 		balanceBefore := app.BankKeeper.GetCoins(ctx, buyerAddress)
 
 		// Cancel unfulfillable buys and check amount of cancellations
-		cancelledOrders := app.BondsKeeper.CancelUnfulfillableBuys(ctx, bond.Token)
+		cancelledOrders, err := app.BondsKeeper.CancelUnfulfillableBuys(ctx, bond.Token)
+		require.Nil(t, err)
 		if tc.orderFulfillable {
 			require.Equal(t, 0, cancelledOrders)
 		} else {
@@ -1122,6 +1379,26 @@ func TestCancelUnfulfillableBuys(t *testing.T) {
 	}
 }
 
+func TestCancelUnfulfillableBuysReturnsErrorIfRefundFails(t *testing.T) {
+	app, ctx := createTestApp(false)
+
+	// Create bond and batch
+	bond := getValidBond()
+	app.BondsKeeper.SetBond(ctx, bond.Token, bond)
+	app.BondsKeeper.SetBatch(ctx, bond.Token, getValidBatch())
+
+	// Add unfulfillable buy order without adding max prices to module account
+	buyPrices := sdk.DecCoins{sdk.NewInt64DecCoin(reserveToken, 100)}
+	maxPrices := sdk.Coins{sdk.NewInt64Coin(reserveToken, 500)}
+	amount := sdk.NewCoin(bond.Token, sdk.NewInt(10))
+	bo := types.NewBuyOrder(buyerAddress, amount, maxPrices)
+	app.BondsKeeper.AddBuyOrder(ctx, bond.Token, bo, buyPrices, sdk.DecCoins{})
+
+	// Refund fails, so an error is returned rather than a panic
+	_, err := app.BondsKeeper.CancelUnfulfillableBuys(ctx, bond.Token)
+	require.Error(t, err)
+}
+
 func TestCancelUnfulfillableOrders(t *testing.T) {
 	app, ctx := createTestApp(false)
 	bond := getValidBond()
@@ -1180,7 +1457,8 @@ func TestCancelUnfulfillableOrders(t *testing.T) {
 		balanceBefore := app.BankKeeper.GetCoins(ctx, buyerAddress)
 
 		// Cancel unfulfillable buys and check amount of cancellations
-		cancelledOrders := app.BondsKeeper.CancelUnfulfillableOrders(ctx, bond.Token)
+		cancelledOrders, err := app.BondsKeeper.CancelUnfulfillableOrders(ctx, bond.Token)
+		require.Nil(t, err)
 		if tc.orderFulfillable {
 			require.Equal(t, 0, cancelledOrders)
 		} else {
@@ -1211,3 +1489,270 @@ func TestCancelUnfulfillableOrders(t *testing.T) {
 		}
 	}
 }
+
+func TestCancelUnfulfillableSells(t *testing.T) {
+	app, ctx := createTestApp(false)
+	bond := getValidBond()
+
+	sellPrices := sdk.DecCoins{sdk.NewInt64DecCoin(reserveToken, 100)}
+	blankBuyPrices := sdk.NewDecCoinsFromCoins() // blank
+
+	testCases := []struct {
+		minReturns       sdk.Coins
+		txFee            sdk.Dec
+		orderFulfillable bool
+	}{
+		{nil, sdk.ZeroDec(), true},
+		{sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 1000)), sdk.ZeroDec(), true},  // 10 * 100 = 1000 >= 1000
+		{sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 1001)), sdk.ZeroDec(), false}, // 10 * 100 = 1000 < 1001
+		{sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 900)), sdk.NewDec(10), true},  // 1000 - 10% = 900 >= 900
+		{sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 901)), sdk.NewDec(10), false}, // 1000 - 10% = 900 < 901
+		{sdk.NewCoins(sdk.NewInt64Coin(reserveToken2, 1)), sdk.ZeroDec(), false},   // no returns in rez
+	}
+	for _, tc := range testCases {
+		// Set up bond (with tx fee, no exit fee, and supply) and new batch
+		bond.TxFeePercentage = tc.txFee
+		bond.ExitFeePercentage = sdk.ZeroDec()
+		bond.CurrentSupply = sdk.NewInt64Coin(bond.Token, 100)
+		app.BondsKeeper.SetBond(ctx, bond.Token, bond)
+		app.BondsKeeper.SetBatch(ctx, bond.Token, getValidBatch())
+
+		// Create and add sell order (tokens are assumed to have been burned)
+		amount := sdk.NewInt64Coin(bond.Token, 10)
+		so := types.NewSellOrder(sellerAddress, amount, tc.minReturns)
+		app.BondsKeeper.AddSellOrder(ctx, bond.Token, so, blankBuyPrices, sellPrices)
+		balanceBefore := app.BankKeeper.GetCoins(ctx, sellerAddress)
+
+		cancelledOrders, err := app.BondsKeeper.CancelUnfulfillableSells(ctx, bond.Token)
+		require.Nil(t, err)
+
+		batch := app.BondsKeeper.MustGetBatch(ctx, bond.Token)
+		if tc.orderFulfillable {
+			require.Equal(t, 0, cancelledOrders)
+			require.False(t, batch.Sells[0].Cancelled)
+			require.Equal(t, amount, batch.TotalSellAmount)
+			require.Equal(t, balanceBefore, app.BankKeeper.GetCoins(ctx, sellerAddress))
+		} else {
+			// Cancelled and bond tokens re-minted and returned to seller
+			require.Equal(t, 1, cancelledOrders)
+			require.True(t, batch.Sells[0].Cancelled)
+			require.True(t, batch.TotalSellAmount.IsZero())
+			require.Equal(t, balanceBefore.Add(amount), app.BankKeeper.GetCoins(ctx, sellerAddress))
+		}
+	}
+}
+
+func TestGetSpendBuyAmount(t *testing.T) {
+	app, ctx := createTestApp(false)
+
+	// Reserve at supply S is 4S^3+100S, so 10 tokens cost 5000, 11 tokens
+	// cost 6424, and 9 tokens cost 3816 (no fees for simpler test)
+	bond := getValidBond()
+	bond.TxFeePercentage = sdk.ZeroDec()
+
+	testCases := []struct {
+		spend               int64
+		orderQuantityLimits sdk.Coins
+		expected            int64
+	}{
+		{5000, nil, 10},
+		{6000, nil, 10},
+		{4999, nil, 9},
+		{100, nil, 0},
+		{6000, sdk.NewCoins(sdk.NewInt64Coin(token, 5)), 5},
+	}
+	for _, tc := range testCases {
+		bond.OrderQuantityLimits = tc.orderQuantityLimits
+		app.BondsKeeper.SetBond(ctx, bond.Token, bond)
+		app.BondsKeeper.SetBatch(ctx, bond.Token, getValidBatch())
+
+		spend := sdk.NewCoins(sdk.NewInt64Coin(reserveToken, tc.spend))
+		amount, err := app.BondsKeeper.GetSpendBuyAmount(ctx, token, buyerAddress, spend)
+		require.Nil(t, err)
+		require.Equal(t, sdk.NewInt(tc.expected), amount)
+	}
+}
+
+func TestGetSpendBuyAmountWithBuysInBatch(t *testing.T) {
+	app, ctx := createTestApp(false)
+
+	// Create bond (with no fees for simpler test) and batch with a buy of 10
+	bond := getValidBond()
+	bond.TxFeePercentage = sdk.ZeroDec()
+	app.BondsKeeper.SetBond(ctx, bond.Token, bond)
+	app.BondsKeeper.SetBatch(ctx, bond.Token, getValidBatch())
+	maxPrices := sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 10000))
+	bo := types.NewBuyOrder(sellerAddress, sdk.NewInt64Coin(token, 10), maxPrices)
+	buyPrices, sellPrices, err := app.BondsKeeper.GetUpdatedBatchPricesAfterBuy(ctx, token, bo)
+	require.Nil(t, err)
+	app.BondsKeeper.AddBuyOrder(ctx, token, bo, buyPrices, sellPrices)
+
+	// Buying 1 more costs 6424/11=584 and 2 more costs 8112/12=676 per token,
+	// so spending 1000 buys 1 token, even though at the current (zero) supply
+	// 1000 is enough to buy 5 tokens from the curve (5 tokens cost 1000)
+	spend := sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 1000))
+	amount, err := app.BondsKeeper.GetSpendBuyAmount(ctx, token, buyerAddress, spend)
+	require.Nil(t, err)
+	require.Equal(t, sdk.NewInt(1), amount)
+}
+
+func TestCancelUnfulfillableOrdersAdjustsSpendBuys(t *testing.T) {
+	app, ctx := createTestApp(false)
+
+	// Create bond (with no fees for simpler test) and batch
+	bond := getValidBond()
+	bond.TxFeePercentage = sdk.ZeroDec()
+	app.BondsKeeper.SetBond(ctx, bond.Token, bond)
+	app.BondsKeeper.SetBatch(ctx, bond.Token, getValidBatch())
+
+	// Spend buy of 10 tokens for 5000, i.e. at exactly 500 per token
+	spend := sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 5000))
+	spendBuy := types.NewSpendBuyOrder(buyerAddress, sdk.NewInt64Coin(token, 10), spend)
+	buyPrices, sellPrices, err := app.BondsKeeper.GetUpdatedBatchPricesAfterBuy(ctx, token, spendBuy)
+	require.Nil(t, err)
+	app.BondsKeeper.AddBuyOrder(ctx, token, spendBuy, buyPrices, sellPrices)
+
+	// Regular buy of 1 token, which increases the price to 6424/11=584
+	maxPrices := sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 1000))
+	bo := types.NewBuyOrder(sellerAddress, sdk.NewInt64Coin(token, 1), maxPrices)
+	buyPrices, sellPrices, err = app.BondsKeeper.GetUpdatedBatchPricesAfterBuy(ctx, token, bo)
+	require.Nil(t, err)
+	app.BondsKeeper.AddBuyOrder(ctx, token, bo, buyPrices, sellPrices)
+
+	// Spend buy reduced to 8 tokens (8*584=4672 <= 5000 < 9*584=5256) and
+	// prices are recomputed for 9 tokens, i.e. 3816/9=424 per token
+	cancelledOrders, err := app.BondsKeeper.CancelUnfulfillableOrders(ctx, token)
+	require.Nil(t, err)
+	require.Equal(t, 0, cancelledOrders)
+
+	batch := app.BondsKeeper.MustGetBatch(ctx, token)
+	require.Equal(t, sdk.NewInt64Coin(token, 8), batch.Buys[0].Amount)
+	require.False(t, batch.Buys[0].Cancelled)
+	require.Equal(t, sdk.NewInt64Coin(token, 9), batch.TotalBuyAmount)
+	require.Equal(t, sdk.NewDec(424), batch.BuyPrices.AmountOf(reserveToken))
+}
+
+func TestCancelUnfulfillableOrdersPartiallyFillsBuys(t *testing.T) {
+	app, ctx := createTestApp(false)
+
+	// Create bond (with no fees for simpler test) and batch
+	bond := getValidBond()
+	bond.TxFeePercentage = sdk.ZeroDec()
+	app.BondsKeeper.SetBond(ctx, bond.Token, bond)
+	app.BondsKeeper.SetBatch(ctx, bond.Token, getValidBatch())
+
+	// Buy of 10 tokens for at most 5000, i.e. at most 500 per token
+	maxPrices1 := sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 5000))
+	bo1 := types.NewBuyOrder(buyerAddress, sdk.NewInt64Coin(token, 10), maxPrices1)
+
+	// Buy of 1 token, which increases the price to 6424/11=584
+	maxPrices2 := sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 1000))
+	bo2 := types.NewBuyOrder(sellerAddress, sdk.NewInt64Coin(token, 1), maxPrices2)
+
+	for _, bo := range []types.BuyOrder{bo1, bo2} {
+		buyPrices, sellPrices, err := app.BondsKeeper.GetUpdatedBatchPricesAfterBuy(ctx, token, bo)
+		require.Nil(t, err)
+		app.BondsKeeper.AddBuyOrder(ctx, token, bo, buyPrices, sellPrices)
+	}
+	moduleAcc := app.SupplyKeeper.GetModuleAccount(ctx, types.BatchesIntermediaryAccount)
+	_, err := app.BankKeeper.AddCoins(ctx, moduleAcc.GetAddress(), maxPrices1.Add(maxPrices2...))
+	require.Nil(t, err)
+
+	// First buy partially filled with 8 tokens (8*584=4672 <= 5000 < 9*584=5256)
+	// instead of being cancelled, and prices are recomputed for 9 tokens, i.e.
+	// 3816/9=424 per token, at which both buys remain fulfillable
+	cancelledOrders, err := app.BondsKeeper.CancelUnfulfillableOrders(ctx, token)
+	require.Nil(t, err)
+	require.Equal(t, 0, cancelledOrders)
+
+	batch := app.BondsKeeper.MustGetBatch(ctx, token)
+	require.False(t, batch.Buys[0].Cancelled)
+	require.False(t, batch.Buys[1].Cancelled)
+	require.Equal(t, sdk.NewInt64Coin(token, 8), batch.Buys[0].Amount)
+	require.Equal(t, sdk.NewInt64Coin(token, 10), batch.Buys[0].RequestedAmount)
+	require.Equal(t, sdk.NewInt64Coin(token, 9), batch.TotalBuyAmount)
+	require.Equal(t, sdk.NewDec(424), batch.BuyPrices.AmountOf(reserveToken))
+
+	// Perform buys
+	err = app.BondsKeeper.PerformBuyOrders(ctx, token)
+	require.Nil(t, err)
+
+	// Buyer gets 8 tokens and unspent reserve 5000-(8*424)=1608 is returned
+	expected := sdk.NewCoins(
+		sdk.NewInt64Coin(token, 8),
+		sdk.NewInt64Coin(reserveToken, 1608))
+	require.Equal(t, expected, app.BankKeeper.GetCoins(ctx, buyerAddress))
+
+	// Fulfilment event reports the filled and requested amounts
+	var found bool
+	for _, event := range ctx.EventManager().Events() {
+		if event.Type != types.EventTypeOrderFulfill {
+			continue
+		}
+		attributes := make(map[string]string)
+		for _, attr := range event.Attributes {
+			attributes[string(attr.Key)] = string(attr.Value)
+		}
+		if attributes[types.AttributeKeyAddress] == buyerAddress.String() {
+			require.Equal(t, "8", attributes[types.AttributeKeyFilledAmount])
+			require.Equal(t, "10", attributes[types.AttributeKeyRequestedAmount])
+			require.Equal(t, "1608"+reserveToken, attributes[types.AttributeKeyReturnedToAddress])
+			found = true
+		}
+	}
+	require.True(t, found)
+}
+
+func TestCancelOrder(t *testing.T) {
+	app, ctx := createTestApp(false)
+
+	// Create bond (with no fees for simpler test) and batch
+	bond := getValidBond()
+	bond.TxFeePercentage = sdk.ZeroDec()
+	app.BondsKeeper.SetBond(ctx, bond.Token, bond)
+	app.BondsKeeper.SetBatch(ctx, bond.Token, getValidBatch())
+
+	// Buy of 10 tokens by buyer and buy of 1 token by seller (11 tokens cost
+	// 6424, i.e. 584 per token), with max prices in module account
+	maxPrices1 := sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 6424))
+	maxPrices2 := sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 1000))
+	bo1 := types.NewBuyOrder(buyerAddress, sdk.NewInt64Coin(token, 10), maxPrices1)
+	bo2 := types.NewBuyOrder(sellerAddress, sdk.NewInt64Coin(token, 1), maxPrices2)
+	for _, bo := range []types.BuyOrder{bo1, bo2} {
+		buyPrices, sellPrices, err := app.BondsKeeper.GetUpdatedBatchPricesAfterBuy(ctx, token, bo)
+		require.Nil(t, err)
+		app.BondsKeeper.AddBuyOrder(ctx, token, bo, buyPrices, sellPrices)
+	}
+	moduleAcc := app.SupplyKeeper.GetModuleAccount(ctx, types.BatchesIntermediaryAccount)
+	_, err := app.BankKeeper.AddCoins(ctx, moduleAcc.GetAddress(), maxPrices1.Add(maxPrices2...))
+	require.Nil(t, err)
+	require.Equal(t, sdk.NewDec(584), app.BondsKeeper.MustGetBatch(ctx, token).BuyPrices.AmountOf(reserveToken))
+
+	// Order not found
+	_, err = app.BondsKeeper.CancelOrder(ctx, token, sellerAddress, 3)
+	require.True(t, errors.Is(err, types.ErrOrderNotFound))
+
+	// Order belongs to someone else
+	_, err = app.BondsKeeper.CancelOrder(ctx, token, buyerAddress, 2)
+	require.True(t, errors.Is(err, types.ErrOrderNotOwnedBySender))
+
+	// Order cancelled and max prices refunded
+	orderType, err := app.BondsKeeper.CancelOrder(ctx, token, sellerAddress, 2)
+	require.Nil(t, err)
+	require.Equal(t, types.AttributeValueBuyOrder, orderType)
+	require.Equal(t, maxPrices2, app.BankKeeper.GetCoins(ctx, sellerAddress))
+
+	// Total buy amount and buy prices updated (10 tokens cost 5000)
+	batch := app.BondsKeeper.MustGetBatch(ctx, token)
+	require.False(t, batch.Buys[0].Cancelled)
+	require.True(t, batch.Buys[1].Cancelled)
+	require.Equal(t, sdk.NewInt64Coin(token, 10), batch.TotalBuyAmount)
+	require.Equal(t, sdk.NewDec(500), batch.BuyPrices.AmountOf(reserveToken))
+
+	events := ctx.EventManager().Events()
+	require.Equal(t, types.EventTypeOrderCancel, events[len(events)-1].Type)
+
+	// Order already cancelled
+	_, err = app.BondsKeeper.CancelOrder(ctx, token, sellerAddress, 2)
+	require.True(t, errors.Is(err, types.ErrOrderAlreadyCancelled))
+}
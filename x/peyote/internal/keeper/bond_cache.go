@@ -0,0 +1,158 @@
+package keeper
+
+import (
+	"sync"
+
+	"github.com/cosmos/cosmos-sdk/store/cachekv"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/warmage-sports/peyote/x/peyote/internal/types"
+)
+
+// bondCache holds the bonds (including their accounting) read or written in
+// the current block, so that a bond that is read many times in a block (e.g.
+// while performing a batch) is only read from the store and decoded once.
+//
+// Bonds are cached separately for each cache-wrapped store that they were read
+// from or written to, i.e. for the block itself and for each transaction and
+// cache context within it, since each of these sees its own version of the
+// bonds. Writing a bond evicts it from every other store's cache, so that a
+// cache context (or transaction) that is written to its parent, or discarded,
+// cannot leave a stale bond behind in either of them. Bonds are only cached
+// while delivering a block, so that CheckTx, simulations and queries (and the
+// gas that they consume) do not depend on or affect the cache. The cache is
+// cleared at the start and end of each block.
+type bondCache struct {
+	mtx    sync.Mutex
+	stores map[*cachekv.Store]map[string]types.Bond
+}
+
+func newBondCache() *bondCache {
+	return &bondCache{stores: make(map[*cachekv.Store]map[string]types.Bond)}
+}
+
+// cacheStore returns the store whose version of the bonds is seen by the
+// context, or nil if bonds cannot be cached in the context.
+func (c *bondCache) cacheStore(ctx sdk.Context, key sdk.StoreKey) *cachekv.Store {
+	if ctx.IsCheckTx() || ctx.MultiStore() == nil {
+		return nil
+	}
+	store, _ := ctx.MultiStore().GetKVStore(key).(*cachekv.Store)
+	return store
+}
+
+// get returns a copy of the bond cached for the key in the store.
+func (c *bondCache) get(store *cachekv.Store, key []byte) (bond types.Bond, found bool) {
+	if store == nil {
+		return
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	bond, found = c.stores[store][string(key)]
+	if !found {
+		return
+	}
+	return copyBond(bond), true
+}
+
+// set caches a copy of the bond for the key in the store.
+func (c *bondCache) set(store *cachekv.Store, key []byte, bond types.Bond) {
+	if store == nil {
+		return
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.stores[store] == nil {
+		c.stores[store] = make(map[string]types.Bond)
+	}
+	c.stores[store][string(key)] = copyBond(bond)
+}
+
+// evict removes the bond cached for the key in any of the stores.
+func (c *bondCache) evict(key []byte) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for _, bonds := range c.stores {
+		delete(bonds, string(key))
+	}
+}
+
+func (c *bondCache) clear() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.stores = make(map[*cachekv.Store]map[string]types.Bond)
+}
+
+// ClearBondCache removes all bonds from the keeper's bond cache. It is called
+// at the start and end of each block.
+func (k Keeper) ClearBondCache() {
+	k.bondCache.clear()
+}
+
+// copyBond returns the bond with deep copies of its slices, so that neither
+// the cached bond nor the bond returned to the caller can be modified through
+// the other. Empty slices are copied as nil, as they are when a bond is
+// decoded. Decimals and integers are not copied, since they are never modified
+// in place.
+func copyBond(bond types.Bond) types.Bond {
+	bond.Creator = copyAddress(bond.Creator)
+	bond.FeeAddress = copyAddress(bond.FeeAddress)
+	if len(bond.FunctionParameters) != 0 {
+		bond.FunctionParameters = append(types.FunctionParams{}, bond.FunctionParameters...)
+	} else {
+		bond.FunctionParameters = nil
+	}
+	if len(bond.ReserveTokens) != 0 {
+		bond.ReserveTokens = append([]string{}, bond.ReserveTokens...)
+	} else {
+		bond.ReserveTokens = nil
+	}
+	bond.OrderQuantityLimits = copyCoins(bond.OrderQuantityLimits)
+	bond.CurrentReserve = copyCoins(bond.CurrentReserve)
+	bond.Signers = copyAddresses(bond.Signers)
+	bond.OutcomePayment = copyCoins(bond.OutcomePayment)
+	bond.OutcomePayers = copyAddresses(bond.OutcomePayers)
+	bond.Evaluators = copyAddresses(bond.Evaluators)
+	if len(bond.OutcomeTranches) == 0 {
+		bond.OutcomeTranches = nil
+	} else {
+		tranches := make([]types.OutcomeTranche, len(bond.OutcomeTranches))
+		for i, tranche := range bond.OutcomeTranches {
+			tranche.Amount = copyCoins(tranche.Amount)
+			tranche.Payer = copyAddress(tranche.Payer)
+			tranches[i] = tranche
+		}
+		bond.OutcomeTranches = tranches
+	}
+	return bond
+}
+
+func copyCoins(coins sdk.Coins) sdk.Coins {
+	if len(coins) == 0 {
+		return nil
+	}
+	return append(sdk.Coins{}, coins...)
+}
+
+func copyAddress(address sdk.AccAddress) sdk.AccAddress {
+	if len(address) == 0 {
+		return nil
+	}
+	return append(sdk.AccAddress{}, address...)
+}
+
+func copyAddresses(addresses []sdk.AccAddress) []sdk.AccAddress {
+	if len(addresses) == 0 {
+		return nil
+	}
+	copies := make([]sdk.AccAddress, len(addresses))
+	for i, address := range addresses {
+		copies[i] = copyAddress(address)
+	}
+	return copies
+}
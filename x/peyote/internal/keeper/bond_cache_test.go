@@ -0,0 +1,51 @@
+package keeper_test
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBondCacheReturnsCopies(t *testing.T) {
+	app, ctx := createTestApp(false)
+	bond := getValidBond()
+	app.BondsKeeper.SetBond(ctx, token, bond)
+
+	// Modifying a returned bond in place does not modify the cached bond
+	returned := app.BondsKeeper.MustGetBond(ctx, token)
+	returned.ReserveTokens[0] = "other"
+	returned.Signers[0][0]++
+	returned.CurrentReserve = append(returned.CurrentReserve, sdk.NewInt64Coin("other", 1))
+
+	require.Equal(t, bond, app.BondsKeeper.MustGetBond(ctx, token))
+}
+
+func TestBondCacheWithCacheContext(t *testing.T) {
+	app, ctx := createTestApp(false)
+	bond := getValidBond()
+	app.BondsKeeper.SetBond(ctx, token, bond)
+	app.BondsKeeper.MustGetBond(ctx, token) // cached in the parent context
+
+	// Bond written in a cache context that is discarded is left unchanged
+	cacheCtx, _ := ctx.CacheContext()
+	edited := app.BondsKeeper.MustGetBond(cacheCtx, token)
+	edited.Name = "edited"
+	app.BondsKeeper.SetBond(cacheCtx, token, edited)
+	app.BondsKeeper.SetCurrentSupply(cacheCtx, token, sdk.NewInt64Coin(token, 10))
+	require.Equal(t, "edited", app.BondsKeeper.MustGetBond(cacheCtx, token).Name)
+	require.Equal(t, bond, app.BondsKeeper.MustGetBond(ctx, token))
+
+	// Bond written in a cache context that is written to its parent is updated
+	cacheCtx, write := ctx.CacheContext()
+	app.BondsKeeper.SetBond(cacheCtx, token, edited)
+	app.BondsKeeper.SetCurrentSupply(cacheCtx, token, sdk.NewInt64Coin(token, 10))
+	write()
+	returned := app.BondsKeeper.MustGetBond(ctx, token)
+	require.Equal(t, "edited", returned.Name)
+	require.Equal(t, sdk.NewInt64Coin(token, 10), returned.CurrentSupply)
+
+	// Bond is read from the store once the cache is cleared
+	app.BondsKeeper.ClearBondCache()
+	require.Equal(t, returned, app.BondsKeeper.MustGetBond(ctx, token))
+}
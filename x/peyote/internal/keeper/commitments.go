@@ -0,0 +1,121 @@
+package keeper
+
+import (
+	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/warmage-sports/peyote/x/peyote/internal/types"
+	"strconv"
+)
+
+func (k Keeper) GetOrderCommitment(ctx sdk.Context, token string, id uint64) (commitment types.OrderCommitment, found bool) {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(types.GetOrderCommitmentKey(token, id))
+	if bz == nil {
+		return types.OrderCommitment{}, false
+	}
+
+	k.cdc.MustUnmarshalBinaryBare(bz, &commitment)
+	return commitment, true
+}
+
+func (k Keeper) MustGetOrderCommitmentByKey(ctx sdk.Context, key []byte) types.OrderCommitment {
+	store := ctx.KVStore(k.storeKey)
+	if !store.Has(key) {
+		panic("order commitment not found")
+	}
+
+	bz := store.Get(key)
+	var commitment types.OrderCommitment
+	k.cdc.MustUnmarshalBinaryBare(bz, &commitment)
+
+	return commitment
+}
+
+// SetOrderCommitment stores the order commitment. The commitment's ID is
+// expected to be already assigned.
+func (k Keeper) SetOrderCommitment(ctx sdk.Context, commitment types.OrderCommitment) {
+	store := ctx.KVStore(k.storeKey)
+	key := types.GetOrderCommitmentKey(commitment.BondToken, commitment.Id)
+	store.Set(key, k.cdc.MustMarshalBinaryBare(commitment))
+}
+
+// AddOrderCommitment assigns the next order ID to the order commitment and
+// stores it. Commitments do not count towards the number of orders in the
+// bond's batch, so that unrevealed commitments cannot fill the batch; a
+// revealed order is only counted once it is added to the batch. The deposit is
+// expected to have already been sent to the batches intermediary account.
+func (k Keeper) AddOrderCommitment(ctx sdk.Context, commitment types.OrderCommitment) types.OrderCommitment {
+	commitment.Id = k.nextOrderId(ctx)
+	k.SetOrderCommitment(ctx, commitment)
+	k.ScheduleBatch(ctx, commitment.BondToken)
+
+	logger := k.Logger(ctx)
+	logger.Info(fmt.Sprintf("added order commitment %d with deposit %s from %s",
+		commitment.Id, commitment.Deposit.String(), commitment.Address.String()))
+
+	return commitment
+}
+
+func (k Keeper) RemoveOrderCommitment(ctx sdk.Context, commitment types.OrderCommitment) {
+	store := ctx.KVStore(k.storeKey)
+	store.Delete(types.GetOrderCommitmentKey(commitment.BondToken, commitment.Id))
+}
+
+func (k Keeper) GetOrderCommitmentsIterator(ctx sdk.Context) sdk.Iterator {
+	store := ctx.KVStore(k.storeKey)
+	return sdk.KVStorePrefixIterator(store, types.OrderCommitmentsKeyPrefix)
+}
+
+// GetOrderCommitments returns the bond's order commitments by ascending ID.
+func (k Keeper) GetOrderCommitments(ctx sdk.Context, token string) (commitments []types.OrderCommitment) {
+	store := ctx.KVStore(k.storeKey)
+	iterator := sdk.KVStorePrefixIterator(store, types.GetOrderCommitmentsPrefixKey(token))
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		var commitment types.OrderCommitment
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &commitment)
+		commitments = append(commitments, commitment)
+	}
+	return commitments
+}
+
+// SettleUnrevealedOrderCommitments removes any of the bond's order commitments
+// that were not revealed during its batch's reveal phase. The deposits are
+// forfeited to the bond's fee address if the bond forfeits unrevealed orders,
+// and are otherwise refunded to the commitment owners.
+func (k Keeper) SettleUnrevealedOrderCommitments(ctx sdk.Context, token string) {
+	bond := k.MustGetBond(ctx, token)
+
+	for _, commitment := range k.GetOrderCommitments(ctx, token) {
+		recipient := commitment.Address
+		if bond.ForfeitUnrevealed {
+			recipient = bond.FeeAddress
+		}
+
+		err := performInCacheContext(ctx, func(ctx sdk.Context) error {
+			k.RemoveOrderCommitment(ctx, commitment)
+			return k.SupplyKeeper.SendCoinsFromModuleToAccount(ctx,
+				types.BatchesIntermediaryAccount, recipient, commitment.Deposit)
+		})
+		if err != nil {
+			// Commitment is kept so that the settlement is retried at the
+			// end of the next batch
+			k.Logger(ctx).Error(fmt.Sprintf("could not settle unrevealed order commitment %d: %s",
+				commitment.Id, err.Error()))
+			continue
+		}
+
+		k.Logger(ctx).Info(fmt.Sprintf("settled unrevealed order commitment %d from %s",
+			commitment.Id, commitment.Address.String()))
+
+		ctx.EventManager().EmitEvent(sdk.NewEvent(
+			types.EventTypeUnrevealedOrder,
+			sdk.NewAttribute(types.AttributeKeyBond, token),
+			sdk.NewAttribute(types.AttributeKeyCommitmentId, fmt.Sprint(commitment.Id)),
+			sdk.NewAttribute(types.AttributeKeyAddress, commitment.Address.String()),
+			sdk.NewAttribute(types.AttributeKeyDeposit, commitment.Deposit.String()),
+			sdk.NewAttribute(types.AttributeKeyForfeited, strconv.FormatBool(bond.ForfeitUnrevealed)),
+		))
+	}
+}
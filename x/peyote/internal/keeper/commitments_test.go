@@ -0,0 +1,97 @@
+package keeper_test
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+	"github.com/warmage-sports/peyote/x/peyote/internal/types"
+)
+
+func newTestOrderCommitment(address sdk.AccAddress, deposit int64) types.OrderCommitment {
+	order := types.NewRevealedOrder(types.AttributeValueBuyOrder,
+		sdk.NewInt64Coin(token, 10), sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 500)), "", "salt")
+	return types.NewOrderCommitment(token, address, order.GetCommitmentHash(address, token),
+		sdk.NewCoins(sdk.NewInt64Coin(reserveToken, deposit)))
+}
+
+func TestOrderCommitmentAddGetRemove(t *testing.T) {
+	app, ctx := createTestApp(false)
+	app.BondsKeeper.SetBond(ctx, token, getValidBond())
+	app.BondsKeeper.SetBatch(ctx, token, getValidBatch())
+
+	_, found := app.BondsKeeper.GetOrderCommitment(ctx, token, 1)
+	require.False(t, found)
+
+	// IDs are shared with batch orders
+	app.BondsKeeper.SetLastOrderId(ctx, 4)
+	commitment1 := app.BondsKeeper.AddOrderCommitment(ctx, newTestOrderCommitment(buyerAddress, 10))
+	commitment2 := app.BondsKeeper.AddOrderCommitment(ctx, newTestOrderCommitment(sellerAddress, 10))
+	require.Equal(t, uint64(5), commitment1.Id)
+	require.Equal(t, uint64(6), commitment2.Id)
+	require.Equal(t, uint64(6), app.BondsKeeper.GetLastOrderId(ctx))
+
+	returned, found := app.BondsKeeper.GetOrderCommitment(ctx, token, commitment1.Id)
+	require.True(t, found)
+	require.Equal(t, commitment1, returned)
+	require.Equal(t, []types.OrderCommitment{commitment1, commitment2},
+		app.BondsKeeper.GetOrderCommitments(ctx, token))
+
+	app.BondsKeeper.RemoveOrderCommitment(ctx, commitment1)
+	_, found = app.BondsKeeper.GetOrderCommitment(ctx, token, commitment1.Id)
+	require.False(t, found)
+	require.Equal(t, []types.OrderCommitment{commitment2},
+		app.BondsKeeper.GetOrderCommitments(ctx, token))
+}
+
+func TestSettleUnrevealedOrderCommitments(t *testing.T) {
+	testCases := []struct {
+		forfeitUnrevealed bool
+	}{
+		{false},
+		{true},
+	}
+	for _, tc := range testCases {
+		app, ctx := createTestApp(false)
+
+		bond := getValidBond()
+		bond.ForfeitUnrevealed = tc.forfeitUnrevealed
+		app.BondsKeeper.SetBond(ctx, token, bond)
+		app.BondsKeeper.SetBatch(ctx, token, getValidBatch())
+
+		// Add commitment, with deposit in module account
+		commitment := app.BondsKeeper.AddOrderCommitment(ctx, newTestOrderCommitment(buyerAddress, 10))
+		moduleAcc := app.SupplyKeeper.GetModuleAccount(ctx, types.BatchesIntermediaryAccount)
+		_, err := app.BankKeeper.AddCoins(ctx, moduleAcc.GetAddress(), commitment.Deposit)
+		require.NoError(t, err)
+
+		app.BondsKeeper.SettleUnrevealedOrderCommitments(ctx, token)
+		require.Len(t, app.BondsKeeper.GetOrderCommitments(ctx, token), 0)
+		require.True(t, app.BankKeeper.GetCoins(ctx, moduleAcc.GetAddress()).IsZero())
+
+		// Deposit forfeited to fee address or refunded to owner
+		if tc.forfeitUnrevealed {
+			require.Equal(t, commitment.Deposit, app.BankKeeper.GetCoins(ctx, bond.FeeAddress))
+			require.True(t, app.BankKeeper.GetCoins(ctx, buyerAddress).IsZero())
+		} else {
+			require.Equal(t, commitment.Deposit, app.BankKeeper.GetCoins(ctx, buyerAddress))
+			require.True(t, app.BankKeeper.GetCoins(ctx, bond.FeeAddress).IsZero())
+		}
+
+		events := ctx.EventManager().Events()
+		require.Equal(t, types.EventTypeUnrevealedOrder, events[len(events)-1].Type)
+	}
+}
+
+func TestSettleUnrevealedOrderCommitmentsKeepsCommitmentIfTransferFails(t *testing.T) {
+	app, ctx := createTestApp(false)
+	app.BondsKeeper.SetBond(ctx, token, getValidBond())
+	app.BondsKeeper.SetBatch(ctx, token, getValidBatch())
+
+	// Add commitment without adding deposit to module account
+	commitment := app.BondsKeeper.AddOrderCommitment(ctx, newTestOrderCommitment(buyerAddress, 10))
+
+	app.BondsKeeper.SettleUnrevealedOrderCommitments(ctx, token)
+	_, found := app.BondsKeeper.GetOrderCommitment(ctx, token, commitment.Id)
+	require.True(t, found)
+}
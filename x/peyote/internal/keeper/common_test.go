@@ -37,7 +37,18 @@ var (
 	initAllowSell              = true
 	initSigners                = []sdk.AccAddress{initCreator}
 	initBatchBlocks            = sdk.NewUint(10)
+	initRevealBlocks           = sdk.ZeroUint()
+	initForfeitUnrevealed      = false
+	initMaxBatchOrders         = sdk.ZeroUint()
+	initMaxBatchVolume         = sdk.ZeroUint()
+	initRollOverOrders         = false
 	initOutcomePayment         = sdk.Coins(nil)
+	initOutcomeTranches        = []types.OutcomeTranche(nil)
+	initOutcomePayers          = []sdk.AccAddress(nil)
+	initEvaluators             = []sdk.AccAddress(nil)
+	initAutoDistribute         = false
+	initClaimBlocks            = sdk.ZeroUint()
+	initSweepToCommunityPool   = false
 	initState                  = types.OpenState
 
 	buyPrices = sdk.NewDecCoinsFromCoins(sdk.NewCoins(
@@ -132,7 +143,10 @@ func getValidPowerFunctionBond() types.Bond {
 		functionType, functionParams, reserveTokens, initTxFeePercentage,
 		initExitFeePercentage, initFeeAddress, initMaxSupply,
 		initOrderQuantityLimits, initSanityRate, initSanityMarginPercentage,
-		initAllowSell, initSigners, initBatchBlocks, initOutcomePayment, initState)
+		initAllowSell, initSigners, initBatchBlocks, initRevealBlocks,
+		initForfeitUnrevealed, initMaxBatchOrders, initMaxBatchVolume,
+		initRollOverOrders, initOutcomePayment, initOutcomeTranches,
+		initOutcomePayers, initEvaluators, initAutoDistribute, initClaimBlocks, initSweepToCommunityPool, initState)
 }
 
 func getValidAugmentedFunctionBond() types.Bond {
@@ -143,7 +157,10 @@ func getValidAugmentedFunctionBond() types.Bond {
 		functionType, functionParams, reserveTokens, initTxFeePercentage,
 		initExitFeePercentage, initFeeAddress, initMaxSupply,
 		initOrderQuantityLimits, initSanityRate, initSanityMarginPercentage,
-		initAllowSell, initSigners, initBatchBlocks, initOutcomePayment, initState)
+		initAllowSell, initSigners, initBatchBlocks, initRevealBlocks,
+		initForfeitUnrevealed, initMaxBatchOrders, initMaxBatchVolume,
+		initRollOverOrders, initOutcomePayment, initOutcomeTranches,
+		initOutcomePayers, initEvaluators, initAutoDistribute, initClaimBlocks, initSweepToCommunityPool, initState)
 }
 
 func getValidSwapperBond() types.Bond {
@@ -154,7 +171,10 @@ func getValidSwapperBond() types.Bond {
 		functionType, functionParams, reserveTokens, initTxFeePercentage,
 		initExitFeePercentage, initFeeAddress, initMaxSupply,
 		initOrderQuantityLimits, initSanityRate, initSanityMarginPercentage,
-		initAllowSell, initSigners, initBatchBlocks, initOutcomePayment, initState)
+		initAllowSell, initSigners, initBatchBlocks, initRevealBlocks,
+		initForfeitUnrevealed, initMaxBatchOrders, initMaxBatchVolume,
+		initRollOverOrders, initOutcomePayment, initOutcomeTranches,
+		initOutcomePayers, initEvaluators, initAutoDistribute, initClaimBlocks, initSweepToCommunityPool, initState)
 }
 
 func getValidBond() types.Bond {
@@ -162,7 +182,7 @@ func getValidBond() types.Bond {
 }
 
 func getValidBatch() types.Batch {
-	return types.NewBatch(token, batchBlocks)
+	return types.NewBatch(token, int64(batchBlocks.Uint64()), batchBlocks, sdk.ZeroUint())
 }
 
 func getValidBaseOrder() types.BaseOrder {
@@ -174,9 +194,9 @@ func getValidBuyOrder() types.BuyOrder {
 }
 
 func getValidSellOrder() types.SellOrder {
-	return types.NewSellOrder(sellerAddress, sellAmount)
+	return types.NewSellOrder(sellerAddress, sellAmount, nil)
 }
 
 func getValidSwapOrder() types.SwapOrder {
-	return types.NewSwapOrder(swapperAddress, swapFrom, swapTo)
+	return types.NewSwapOrder(swapperAddress, swapFrom, swapTo, nil)
 }
@@ -0,0 +1,320 @@
+package keeper
+
+import (
+	"fmt"
+	"strconv"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/warmage-sports/peyote/x/peyote/internal/types"
+)
+
+func (k Keeper) GetShareDistribution(ctx sdk.Context, token string) (distribution types.ShareDistribution, found bool) {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(types.GetShareDistributionKey(token))
+	if bz == nil {
+		return types.ShareDistribution{}, false
+	}
+	k.cdc.MustUnmarshalBinaryBare(bz, &distribution)
+	return distribution, true
+}
+
+func (k Keeper) SetShareDistribution(ctx sdk.Context, distribution types.ShareDistribution) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(types.GetShareDistributionKey(distribution.BondToken),
+		k.cdc.MustMarshalBinaryBare(distribution))
+}
+
+func (k Keeper) RemoveShareDistribution(ctx sdk.Context, token string) {
+	store := ctx.KVStore(k.storeKey)
+	store.Delete(types.GetShareDistributionKey(token))
+}
+
+// GetShareDistributions returns the share distributions that are in progress,
+// in order of bond token.
+func (k Keeper) GetShareDistributions(ctx sdk.Context) (distributions []types.ShareDistribution) {
+	store := ctx.KVStore(k.storeKey)
+	iterator := sdk.KVStorePrefixIterator(store, types.ShareDistributionsKeyPrefix)
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		var distribution types.ShareDistribution
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &distribution)
+		distributions = append(distributions, distribution)
+	}
+	return distributions
+}
+
+// DistributeShares records the holders of any settlement snapshots and
+// outcome tranches whose holders are being recorded (see RecordHolders), pays
+// the holders of any outcome tranches that are being distributed their share
+// of the tranche (see distributeTranches), and then pays the holders in the
+// settlement snapshots of bonds that distribute their reserve automatically
+// their remaining share of the reserve, as if each holder withdrew its entire
+// share. At most MaxDistributions holders are visited per block across all
+// recordings and tranche and share distributions, and each share distribution
+// resumes after the last holder that it visited. Holders
+// that have already withdrawn their entire share are skipped, and holders
+// whose share cannot be paid are left to withdraw it themselves. Share
+// distributions of bonds that are not in the SETTLE state (i.e. quarantined
+// bonds) are paused.
+func (k Keeper) DistributeShares(ctx sdk.Context) {
+	remaining := k.RecordHolders(ctx, k.GetParams(ctx).MaxDistributions)
+	remaining = k.distributeTranches(ctx, remaining)
+
+	for _, distribution := range k.GetShareDistributions(ctx) {
+		if remaining == 0 {
+			return
+		}
+
+		bond := k.MustGetBond(ctx, distribution.BondToken)
+		if bond.State != types.SettleState {
+			continue
+		}
+
+		limit := remaining
+		holders := k.GetHolderSnapshotsAfter(ctx, bond.Token, distribution.LastHolder, limit)
+		for _, holder := range holders {
+			remaining--
+			distribution.LastHolder = holder.Address
+			if holder.Remaining().IsPositive() && k.distributeShare(ctx, bond.Token, holder.Address) {
+				distribution.HoldersPaid++
+			}
+		}
+
+		// If fewer holders than requested were left, all have been visited
+		if uint64(len(holders)) < limit {
+			k.endShareDistribution(ctx, distribution)
+		} else {
+			k.SetShareDistribution(ctx, distribution)
+		}
+	}
+}
+
+// distributeShare pays the holder its entire remaining share of the bond's
+// reserve, and returns whether this was successful.
+func (k Keeper) distributeShare(ctx sdk.Context, token string, address sdk.AccAddress) bool {
+	err := performInCacheContext(ctx, func(ctx sdk.Context) error {
+		reserveOwed, withdrawn, err := k.WithdrawShare(ctx, token, address, sdk.ZeroInt())
+		if err != nil {
+			return err
+		}
+
+		ctx.EventManager().EmitEvent(sdk.NewEvent(
+			types.EventTypeDistributeShare,
+			sdk.NewAttribute(types.AttributeKeyBond, token),
+			sdk.NewAttribute(types.AttributeKeyAddress, address.String()),
+			sdk.NewAttribute(sdk.AttributeKeyAmount, reserveOwed.String()),
+			sdk.NewAttribute(types.AttributeKeyWithdrawnShare, withdrawn.String()),
+		))
+		return nil
+	})
+	if err != nil {
+		k.Logger(ctx).Error(fmt.Sprintf("could not distribute share of %s to %s: %s",
+			token, address, err.Error()))
+		return false
+	}
+	return true
+}
+
+func (k Keeper) endShareDistribution(ctx sdk.Context, distribution types.ShareDistribution) {
+	k.RemoveShareDistribution(ctx, distribution.BondToken)
+
+	logger := k.Logger(ctx)
+	logger.Info(fmt.Sprintf("distributed shares of %s to %d holders",
+		distribution.BondToken, distribution.HoldersPaid))
+
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		types.EventTypeDistributionEnd,
+		sdk.NewAttribute(types.AttributeKeyBond, distribution.BondToken),
+		sdk.NewAttribute(types.AttributeKeyHoldersPaid,
+			strconv.FormatUint(distribution.HoldersPaid, 10)),
+	))
+}
+
+func (k Keeper) GetTrancheDistribution(ctx sdk.Context, token string, tranche uint64) (distribution types.TrancheDistribution, found bool) {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(types.GetTrancheDistributionKey(token, tranche))
+	if bz == nil {
+		return types.TrancheDistribution{}, false
+	}
+	k.cdc.MustUnmarshalBinaryBare(bz, &distribution)
+	return distribution, true
+}
+
+// SetTrancheDistribution sets the tranche distribution along with the holders
+// that it includes.
+func (k Keeper) SetTrancheDistribution(ctx sdk.Context, distribution types.TrancheDistribution) {
+	k.SetTrancheDistributionHeader(ctx, distribution)
+	store := ctx.KVStore(k.storeKey)
+	for _, h := range distribution.Holders {
+		store.Set(types.GetTrancheHolderKey(distribution.BondToken, distribution.Tranche, h.Address),
+			k.cdc.MustMarshalBinaryBare(h))
+	}
+}
+
+// SetTrancheDistributionHeader sets the tranche distribution without its
+// holders, i.e. any holders in the distribution are ignored and the stored
+// holders are left unchanged.
+func (k Keeper) SetTrancheDistributionHeader(ctx sdk.Context, distribution types.TrancheDistribution) {
+	distribution.Holders = nil
+	store := ctx.KVStore(k.storeKey)
+	store.Set(types.GetTrancheDistributionKey(distribution.BondToken, distribution.Tranche),
+		k.cdc.MustMarshalBinaryBare(distribution))
+}
+
+// GetTrancheDistributions returns the tranche distributions that are in
+// progress, in order of bond token and then of tranche, without their holders.
+func (k Keeper) GetTrancheDistributions(ctx sdk.Context) (distributions []types.TrancheDistribution) {
+	store := ctx.KVStore(k.storeKey)
+	iterator := sdk.KVStorePrefixIterator(store, types.TrancheDistributionsKeyPrefix)
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		var distribution types.TrancheDistribution
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &distribution)
+		distributions = append(distributions, distribution)
+	}
+	return distributions
+}
+
+// HasTrancheDistributions returns true if any of the bond's outcome tranches
+// are still being distributed to its holders.
+func (k Keeper) HasTrancheDistributions(ctx sdk.Context, token string) bool {
+	store := ctx.KVStore(k.storeKey)
+	iterator := sdk.KVStorePrefixIterator(store, types.GetTrancheDistributionsPrefixKey(token))
+	defer iterator.Close()
+	return iterator.Valid()
+}
+
+// GetTrancheHolders returns up to limit of the holders that the tranche is
+// still to be distributed to, in order of address, or all of them if the
+// limit is zero.
+func (k Keeper) GetTrancheHolders(ctx sdk.Context, token string, tranche uint64,
+	limit uint64) (holders []types.HolderSnapshot) {
+
+	store := ctx.KVStore(k.storeKey)
+	iterator := sdk.KVStorePrefixIterator(store, types.GetTrancheHoldersPrefixKey(token, tranche))
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		if limit != 0 && uint64(len(holders)) == limit {
+			break
+		}
+		var holder types.HolderSnapshot
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &holder)
+		holders = append(holders, holder)
+	}
+	return holders
+}
+
+// distributeTranches pays up to limit of the holders of the outcome tranches
+// that are being distributed their share of the tranche, in order of bond
+// token, tranche and holder address, and returns how many more holders can be
+// visited in this block. Each holder is removed once it has been visited, and
+// a holder whose share cannot be paid is skipped, in which case its share is
+// sent to the bond's reserve along with the rest of the tranche's remainder
+// once all holders have been visited. Distributions whose holders are still
+// being recorded are skipped, and distributions of bonds that are quarantined
+// are paused.
+func (k Keeper) distributeTranches(ctx sdk.Context, limit uint64) (remaining uint64) {
+	remaining = limit
+	store := ctx.KVStore(k.storeKey)
+
+	for _, distribution := range k.GetTrancheDistributions(ctx) {
+		if remaining == 0 {
+			return 0
+		}
+
+		if distribution.Recording.InProgress {
+			continue
+		}
+		bond := k.MustGetBond(ctx, distribution.BondToken)
+		if bond.State == types.QuarantineState {
+			continue
+		}
+
+		holders := k.GetTrancheHolders(ctx, bond.Token, distribution.Tranche, remaining)
+		for _, holder := range holders {
+			remaining--
+			store.Delete(types.GetTrancheHolderKey(bond.Token, distribution.Tranche, holder.Address))
+			share := distribution.GetShare(holder.Balance)
+			if !share.Empty() && k.distributeTrancheShare(ctx, distribution, holder.Address, share) {
+				distribution.Remaining = distribution.Remaining.Sub(share)
+				distribution.HoldersPaid++
+			}
+		}
+
+		if remaining == 0 && k.hasTrancheHolders(ctx, bond.Token, distribution.Tranche) {
+			k.SetTrancheDistributionHeader(ctx, distribution)
+		} else {
+			k.endTrancheDistribution(ctx, distribution)
+		}
+	}
+	return remaining
+}
+
+func (k Keeper) hasTrancheHolders(ctx sdk.Context, token string, tranche uint64) bool {
+	store := ctx.KVStore(k.storeKey)
+	iterator := sdk.KVStorePrefixIterator(store, types.GetTrancheHoldersPrefixKey(token, tranche))
+	defer iterator.Close()
+	return iterator.Valid()
+}
+
+// distributeTrancheShare pays the holder its share of the escrowed tranche,
+// and returns whether this was successful.
+func (k Keeper) distributeTrancheShare(ctx sdk.Context, distribution types.TrancheDistribution,
+	address sdk.AccAddress, share sdk.Coins) bool {
+
+	err := performInCacheContext(ctx, func(ctx sdk.Context) error {
+		return k.SupplyKeeper.SendCoinsFromModuleToAccount(ctx,
+			types.BatchesIntermediaryAccount, address, share)
+	})
+	if err != nil {
+		k.Logger(ctx).Error(fmt.Sprintf("could not distribute share of tranche %d of %s to %s: %s",
+			distribution.Tranche, distribution.BondToken, address, err.Error()))
+		return false
+	}
+
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		types.EventTypeDistributeTranche,
+		sdk.NewAttribute(types.AttributeKeyBond, distribution.BondToken),
+		sdk.NewAttribute(types.AttributeKeyTranche, strconv.FormatUint(distribution.Tranche, 10)),
+		sdk.NewAttribute(types.AttributeKeyAddress, address.String()),
+		sdk.NewAttribute(sdk.AttributeKeyAmount, share.String()),
+	))
+	return true
+}
+
+// endTrancheDistribution sends the remainder of the tranche to the bond's
+// reserve and removes the distribution. If the remainder cannot be sent, the
+// distribution is kept so that this is retried in the next block.
+func (k Keeper) endTrancheDistribution(ctx sdk.Context, distribution types.TrancheDistribution) {
+	err := performInCacheContext(ctx, func(ctx sdk.Context) error {
+		if distribution.Remaining.Empty() {
+			return nil
+		}
+		return k.DepositReserveFromModule(ctx, distribution.BondToken,
+			types.BatchesIntermediaryAccount, distribution.Remaining)
+	})
+	if err != nil {
+		k.Logger(ctx).Error(fmt.Sprintf("could not send remainder of tranche %d of %s to reserve: %s",
+			distribution.Tranche, distribution.BondToken, err.Error()))
+		k.SetTrancheDistributionHeader(ctx, distribution)
+		return
+	}
+
+	store := ctx.KVStore(k.storeKey)
+	store.Delete(types.GetTrancheDistributionKey(distribution.BondToken, distribution.Tranche))
+
+	logger := k.Logger(ctx)
+	logger.Info(fmt.Sprintf("distributed tranche %d of %s to %d holders",
+		distribution.Tranche, distribution.BondToken, distribution.HoldersPaid))
+
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		types.EventTypeTrancheDistributed,
+		sdk.NewAttribute(types.AttributeKeyBond, distribution.BondToken),
+		sdk.NewAttribute(types.AttributeKeyTranche, strconv.FormatUint(distribution.Tranche, 10)),
+		sdk.NewAttribute(types.AttributeKeyHoldersPaid,
+			strconv.FormatUint(distribution.HoldersPaid, 10)),
+	))
+}
@@ -0,0 +1,99 @@
+package keeper_test
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+	"github.com/warmage-sports/peyote/x/peyote/internal/types"
+)
+
+func TestGetHolderSnapshotsAfter(t *testing.T) {
+	app, ctx := createTestApp(false)
+
+	snapshot := types.NewSettlementSnapshot(token, 50)
+	snapshot.Holders = []types.HolderSnapshot{
+		types.NewHolderSnapshot(buyerAddress, sdk.NewInt(10)),
+		types.NewHolderSnapshot(sellerAddress, sdk.NewInt(20)),
+		types.NewHolderSnapshot(swapperAddress, sdk.NewInt(30)),
+	}
+	app.BondsKeeper.SetSettlementSnapshot(ctx, snapshot)
+	holders := app.BondsKeeper.GetHolderSnapshots(ctx, token)
+	require.Len(t, holders, 3)
+
+	// Holders are paged in order of address
+	require.Equal(t, holders[:2],
+		app.BondsKeeper.GetHolderSnapshotsAfter(ctx, token, nil, 2))
+	require.Equal(t, holders[2:],
+		app.BondsKeeper.GetHolderSnapshotsAfter(ctx, token, holders[1].Address, 2))
+	require.Len(t, app.BondsKeeper.GetHolderSnapshotsAfter(ctx, token, holders[2].Address, 2), 0)
+}
+
+func TestDistributeShares(t *testing.T) {
+	app, ctx := createTestApp(false)
+	bond := getValidBond()
+	bond.CurrentSupply = sdk.NewInt64Coin(token, 40)
+	bond.State = types.QuarantineState
+	app.BondsKeeper.SetBond(ctx, token, bond)
+
+	// Mint bond tokens to two holders and add reserve
+	buyerTokens := sdk.NewCoins(sdk.NewInt64Coin(token, 30))
+	sellerTokens := sdk.NewCoins(sdk.NewInt64Coin(token, 10))
+	reserve := sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 400))
+	err := app.SupplyKeeper.MintCoins(ctx, types.BondsMintBurnAccount,
+		buyerTokens.Add(sellerTokens...).Add(reserve...))
+	require.Nil(t, err)
+	err = app.SupplyKeeper.SendCoinsFromModuleToAccount(
+		ctx, types.BondsMintBurnAccount, buyerAddress, buyerTokens)
+	require.Nil(t, err)
+	err = app.SupplyKeeper.SendCoinsFromModuleToAccount(
+		ctx, types.BondsMintBurnAccount, sellerAddress, sellerTokens)
+	require.Nil(t, err)
+	err = app.BondsKeeper.DepositReserveFromModule(
+		ctx, token, types.BondsMintBurnAccount, reserve)
+	require.Nil(t, err)
+
+	app.BondsKeeper.SnapshotHolders(ctx, token)
+	app.BondsKeeper.RecordHolders(ctx, 10)
+	app.BondsKeeper.SetShareDistribution(ctx, types.NewShareDistribution(token))
+	holders := app.BondsKeeper.GetHolderSnapshots(ctx, token)
+
+	// Distribute to one holder per block
+	params := app.BondsKeeper.GetParams(ctx)
+	params.MaxDistributions = 1
+	app.BondsKeeper.SetParams(ctx, params)
+
+	// Nothing is distributed while the bond is not in the SETTLE state
+	app.BondsKeeper.DistributeShares(ctx)
+	distribution, found := app.BondsKeeper.GetShareDistribution(ctx, token)
+	require.True(t, found)
+	require.Equal(t, types.NewShareDistribution(token), distribution)
+
+	app.BondsKeeper.SetBondState(ctx, token, types.SettleState)
+
+	// First holder is paid
+	app.BondsKeeper.DistributeShares(ctx)
+	distribution, found = app.BondsKeeper.GetShareDistribution(ctx, token)
+	require.True(t, found)
+	require.Equal(t, holders[0].Address, distribution.LastHolder)
+	require.Equal(t, uint64(1), distribution.HoldersPaid)
+	holder, _ := app.BondsKeeper.GetHolderSnapshot(ctx, token, holders[0].Address)
+	require.True(t, holder.Remaining().IsZero())
+
+	// Second holder is paid, and the distribution ends in the following block
+	app.BondsKeeper.DistributeShares(ctx)
+	distribution, found = app.BondsKeeper.GetShareDistribution(ctx, token)
+	require.True(t, found)
+	require.Equal(t, uint64(2), distribution.HoldersPaid)
+	app.BondsKeeper.DistributeShares(ctx)
+	_, found = app.BondsKeeper.GetShareDistribution(ctx, token)
+	require.False(t, found)
+
+	// Entire reserve was distributed and all bond tokens were burned
+	require.True(t, app.BondsKeeper.GetReserveBalances(ctx, token).IsZero())
+	require.True(t, app.BondsKeeper.MustGetBond(ctx, token).CurrentSupply.IsZero())
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 300)),
+		app.BankKeeper.GetCoins(ctx, buyerAddress))
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 100)),
+		app.BankKeeper.GetCoins(ctx, sellerAddress))
+}
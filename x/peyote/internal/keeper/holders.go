@@ -0,0 +1,452 @@
+package keeper
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/cosmos/cosmos-sdk/x/auth/exported"
+	"github.com/cosmos/cosmos-sdk/x/bank"
+	supplyexported "github.com/cosmos/cosmos-sdk/x/supply/exported"
+	"github.com/warmage-sports/peyote/x/peyote/internal/types"
+)
+
+// holderTrackingBankKeeper is a bank keeper that indexes each account that
+// holds any bond tokens as a holder of the bond, so that the holders of a bond
+// can be found without visiting every account. Accounts are removed from the
+// index once they no longer hold any of the bond's tokens. Since bond tokens
+// can be sent by other modules (e.g. using MsgSend), this has to wrap the bank
+// keeper used by the whole app rather than only the one used by this module.
+//
+// While the holders of a bond are being recorded (see RecordHolders), the
+// balance of any holder that has not yet been visited is also recorded before
+// it changes, so that the recorded balance is the holder's balance when the
+// recording started.
+type holderTrackingBankKeeper struct {
+	bank.Keeper
+	keeper Keeper
+}
+
+var _ bank.Keeper = holderTrackingBankKeeper{}
+
+// NewHolderTrackingBankKeeper wraps the bank keeper so that the accounts that
+// hold any bond tokens are indexed as holders of the bond in the store of this
+// module. The returned keeper should be used by all of the app's modules.
+func NewHolderTrackingBankKeeper(bankKeeper bank.Keeper, accountKeeper auth.AccountKeeper,
+	storeKey sdk.StoreKey, cdc *codec.Codec) bank.Keeper {
+
+	// Only the store of the keeper and its account keeper are used, to index
+	// and record the holders of bonds
+	keeper := Keeper{accountKeeper: accountKeeper, storeKey: storeKey, cdc: cdc}
+	return holderTrackingBankKeeper{Keeper: bankKeeper, keeper: keeper}
+}
+
+func (bk holderTrackingBankKeeper) InputOutputCoins(ctx sdk.Context, inputs []bank.Input, outputs []bank.Output) error {
+	for _, in := range inputs {
+		bk.keeper.recordBalanceBeforeChange(ctx, in.Address, in.Coins)
+	}
+	for _, out := range outputs {
+		bk.keeper.recordBalanceBeforeChange(ctx, out.Address, out.Coins)
+	}
+	err := bk.Keeper.InputOutputCoins(ctx, inputs, outputs)
+	if err != nil {
+		return err
+	}
+	for _, in := range inputs {
+		bk.keeper.indexHolder(ctx, in.Address, in.Coins)
+	}
+	for _, out := range outputs {
+		bk.keeper.indexHolder(ctx, out.Address, out.Coins)
+	}
+	return nil
+}
+
+func (bk holderTrackingBankKeeper) SendCoins(ctx sdk.Context, fromAddr sdk.AccAddress, toAddr sdk.AccAddress, amt sdk.Coins) error {
+	bk.keeper.recordBalanceBeforeChange(ctx, fromAddr, amt)
+	bk.keeper.recordBalanceBeforeChange(ctx, toAddr, amt)
+	err := bk.Keeper.SendCoins(ctx, fromAddr, toAddr, amt)
+	if err != nil {
+		return err
+	}
+	bk.keeper.indexHolder(ctx, fromAddr, amt)
+	bk.keeper.indexHolder(ctx, toAddr, amt)
+	return nil
+}
+
+func (bk holderTrackingBankKeeper) SubtractCoins(ctx sdk.Context, addr sdk.AccAddress, amt sdk.Coins) (sdk.Coins, error) {
+	bk.keeper.recordBalanceBeforeChange(ctx, addr, amt)
+	coins, err := bk.Keeper.SubtractCoins(ctx, addr, amt)
+	if err != nil {
+		return nil, err
+	}
+	bk.keeper.indexHolder(ctx, addr, amt)
+	return coins, nil
+}
+
+func (bk holderTrackingBankKeeper) AddCoins(ctx sdk.Context, addr sdk.AccAddress, amt sdk.Coins) (sdk.Coins, error) {
+	bk.keeper.recordBalanceBeforeChange(ctx, addr, amt)
+	coins, err := bk.Keeper.AddCoins(ctx, addr, amt)
+	if err != nil {
+		return nil, err
+	}
+	bk.keeper.indexHolder(ctx, addr, amt)
+	return coins, nil
+}
+
+func (bk holderTrackingBankKeeper) SetCoins(ctx sdk.Context, addr sdk.AccAddress, amt sdk.Coins) error {
+	// Any bond tokens that the account holds but that are not in the amount
+	// are also changed (to zero)
+	affected := append(sdk.Coins{}, bk.Keeper.GetCoins(ctx, addr)...)
+	affected = append(affected, amt...)
+	bk.keeper.recordBalanceBeforeChange(ctx, addr, affected)
+	err := bk.Keeper.SetCoins(ctx, addr, amt)
+	if err != nil {
+		return err
+	}
+	bk.keeper.indexHolder(ctx, addr, affected)
+	return nil
+}
+
+func (bk holderTrackingBankKeeper) DelegateCoins(ctx sdk.Context, delegatorAddr, moduleAccAddr sdk.AccAddress, amt sdk.Coins) error {
+	bk.keeper.recordBalanceBeforeChange(ctx, delegatorAddr, amt)
+	bk.keeper.recordBalanceBeforeChange(ctx, moduleAccAddr, amt)
+	err := bk.Keeper.DelegateCoins(ctx, delegatorAddr, moduleAccAddr, amt)
+	if err != nil {
+		return err
+	}
+	bk.keeper.indexHolder(ctx, delegatorAddr, amt)
+	bk.keeper.indexHolder(ctx, moduleAccAddr, amt)
+	return nil
+}
+
+func (bk holderTrackingBankKeeper) UndelegateCoins(ctx sdk.Context, moduleAccAddr, delegatorAddr sdk.AccAddress, amt sdk.Coins) error {
+	bk.keeper.recordBalanceBeforeChange(ctx, moduleAccAddr, amt)
+	bk.keeper.recordBalanceBeforeChange(ctx, delegatorAddr, amt)
+	err := bk.Keeper.UndelegateCoins(ctx, moduleAccAddr, delegatorAddr, amt)
+	if err != nil {
+		return err
+	}
+	bk.keeper.indexHolder(ctx, moduleAccAddr, amt)
+	bk.keeper.indexHolder(ctx, delegatorAddr, amt)
+	return nil
+}
+
+// indexHolder indexes the account as a holder of each of the bonds whose
+// tokens are in the amount if it holds any of the bond's tokens, or removes it
+// from the bond's holder index otherwise. An account that does not hold any
+// of the tokens of a bond whose holders are being recorded is also removed
+// from the recording if its balance was only recorded as zero, since it will
+// not be visited and has nothing to be recorded.
+func (k Keeper) indexHolder(ctx sdk.Context, address sdk.AccAddress, amt sdk.Coins) {
+	store := ctx.KVStore(k.storeKey)
+	for _, c := range amt {
+		if !k.BondExists(ctx, c.Denom) {
+			continue
+		}
+		balance, _ := k.getHolderBalance(ctx, address, c.Denom)
+		if balance.IsPositive() {
+			store.Set(types.GetHolderKey(c.Denom, address), []byte{})
+			continue
+		}
+		store.Delete(types.GetHolderKey(c.Denom, address))
+		for _, r := range k.getHolderRecordings(ctx, c.Denom) {
+			if holder, found := k.getRecordedHolder(ctx, r, address); found &&
+				!r.recording().Visited(address) && holder.Balance.IsZero() {
+				store.Delete(r.holderKey(address))
+			}
+		}
+	}
+}
+
+// IndexHolders indexes each account that holds any bond tokens as a holder of
+// the bond. This visits every account, so it is only expected to be called
+// when initialising the module from genesis, since the holder index is not
+// exported.
+func (k Keeper) IndexHolders(ctx sdk.Context) {
+	store := ctx.KVStore(k.storeKey)
+	k.accountKeeper.IterateAccounts(ctx, func(acc exported.Account) bool {
+		for _, c := range acc.GetCoins() {
+			if c.IsPositive() && k.BondExists(ctx, c.Denom) {
+				store.Set(types.GetHolderKey(c.Denom, acc.GetAddress()), []byte{})
+			}
+		}
+		return false
+	})
+}
+
+// RemoveHolders removes the bond's holder index.
+func (k Keeper) RemoveHolders(ctx sdk.Context, token string) {
+	store := ctx.KVStore(k.storeKey)
+
+	var keys [][]byte
+	iterator := sdk.KVStorePrefixIterator(store, types.GetHoldersPrefixKey(token))
+	for ; iterator.Valid(); iterator.Next() {
+		keys = append(keys, iterator.Key())
+	}
+	iterator.Close()
+
+	for _, key := range keys {
+		store.Delete(key)
+	}
+}
+
+// getHolderBalance returns the account's balance of the bond's tokens, and
+// false if the account is a module account, since bond tokens held by module
+// accounts (e.g. escrowed for pending orders) are not recorded.
+func (k Keeper) getHolderBalance(ctx sdk.Context, address sdk.AccAddress, token string) (sdk.Int, bool) {
+	acc := k.accountKeeper.GetAccount(ctx, address)
+	if acc == nil {
+		return sdk.ZeroInt(), true
+	} else if _, ok := acc.(supplyexported.ModuleAccountI); ok {
+		return acc.GetCoins().AmountOf(token), false
+	}
+	return acc.GetCoins().AmountOf(token), true
+}
+
+// holderRecording is a settlement snapshot or a tranche distribution whose
+// holders are being recorded.
+type holderRecording struct {
+	snapshot     *types.SettlementSnapshot
+	distribution *types.TrancheDistribution
+}
+
+func (r holderRecording) token() string {
+	if r.snapshot != nil {
+		return r.snapshot.BondToken
+	}
+	return r.distribution.BondToken
+}
+
+func (r holderRecording) recording() *types.HolderRecording {
+	if r.snapshot != nil {
+		return &r.snapshot.Recording
+	}
+	return &r.distribution.Recording
+}
+
+func (r holderRecording) holderKey(address sdk.AccAddress) []byte {
+	if r.snapshot != nil {
+		return types.GetHolderSnapshotKey(r.snapshot.BondToken, address)
+	}
+	return types.GetTrancheHolderKey(r.distribution.BondToken, r.distribution.Tranche, address)
+}
+
+func (r holderRecording) addBalance(balance sdk.Int) {
+	r.recording().HolderCount++
+	if r.snapshot != nil {
+		r.snapshot.TotalBalance = r.snapshot.TotalBalance.Add(balance)
+	} else {
+		r.distribution.TotalBalance = r.distribution.TotalBalance.Add(balance)
+	}
+}
+
+// setHolderRecording sets the settlement snapshot or tranche distribution
+// without its holders.
+func (k Keeper) setHolderRecording(ctx sdk.Context, r holderRecording) {
+	if r.snapshot != nil {
+		k.SetSettlementSnapshotHeader(ctx, *r.snapshot)
+	} else {
+		k.SetTrancheDistributionHeader(ctx, *r.distribution)
+	}
+}
+
+// getHolderRecordings returns the bond's settlement snapshot and tranche
+// distributions whose holders are being recorded.
+func (k Keeper) getHolderRecordings(ctx sdk.Context, token string) (recordings []holderRecording) {
+	store := ctx.KVStore(k.storeKey)
+	if store.Has(types.GetRecordingSnapshotKey(token)) {
+		snapshot, _ := k.GetSettlementSnapshot(ctx, token)
+		recordings = append(recordings, holderRecording{snapshot: &snapshot})
+	}
+
+	iterator := sdk.KVStorePrefixIterator(store, types.GetTrancheDistributionsPrefixKey(token))
+	defer iterator.Close()
+	for ; iterator.Valid(); iterator.Next() {
+		var distribution types.TrancheDistribution
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &distribution)
+		if distribution.Recording.InProgress {
+			recordings = append(recordings, holderRecording{distribution: &distribution})
+		}
+	}
+	return recordings
+}
+
+func (k Keeper) getRecordedHolder(ctx sdk.Context, r holderRecording,
+	address sdk.AccAddress) (holder types.HolderSnapshot, found bool) {
+
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(r.holderKey(address))
+	if bz == nil {
+		return types.HolderSnapshot{}, false
+	}
+	k.cdc.MustUnmarshalBinaryBare(bz, &holder)
+	return holder, true
+}
+
+// recordBalanceBeforeChange records the account's current balance of the
+// tokens of each of the bonds whose tokens are in the amount, in any of the
+// bond's settlement snapshot or tranche distributions whose holders are being
+// recorded and that have not visited or recorded the account yet, since its
+// balance is about to change. A balance of zero is also recorded, so that the
+// account's balance is not recorded once it has changed.
+func (k Keeper) recordBalanceBeforeChange(ctx sdk.Context, address sdk.AccAddress, amt sdk.Coins) {
+	store := ctx.KVStore(k.storeKey)
+	for _, c := range amt {
+		if !k.BondExists(ctx, c.Denom) {
+			continue
+		}
+		for _, r := range k.getHolderRecordings(ctx, c.Denom) {
+			if r.recording().Visited(address) || store.Has(r.holderKey(address)) {
+				continue
+			}
+			balance, ok := k.getHolderBalance(ctx, address, c.Denom)
+			if !ok {
+				continue
+			}
+			holder := types.NewHolderSnapshot(address, balance)
+			store.Set(r.holderKey(address), k.cdc.MustMarshalBinaryBare(holder))
+			if balance.IsPositive() {
+				r.addBalance(balance)
+				k.setHolderRecording(ctx, r)
+			}
+		}
+	}
+}
+
+// RecordHolders records the bond token balances of up to limit of the holders
+// of the bonds whose settlement snapshot or tranche distributions are being
+// recorded, in order of bond token and of holder address, and returns how
+// many more holders can be visited in this block. Only the accounts in the
+// bond's holder index are visited, and accounts that were already recorded
+// before their balance changed keep the balance that was recorded. Once all
+// of a bond's holders have been visited, the recording is complete, and the
+// distribution of the bond's reserve or tranche can start.
+func (k Keeper) RecordHolders(ctx sdk.Context, limit uint64) (remaining uint64) {
+	remaining = limit
+
+	var recordings []holderRecording
+	store := ctx.KVStore(k.storeKey)
+	iterator := sdk.KVStorePrefixIterator(store, types.RecordingSnapshotsKeyPrefix)
+	for ; iterator.Valid(); iterator.Next() {
+		snapshot, _ := k.GetSettlementSnapshot(ctx, string(iterator.Value()))
+		recordings = append(recordings, holderRecording{snapshot: &snapshot})
+	}
+	iterator.Close()
+	for _, distribution := range k.GetTrancheDistributions(ctx) {
+		if distribution.Recording.InProgress {
+			distribution := distribution
+			recordings = append(recordings, holderRecording{distribution: &distribution})
+		}
+	}
+
+	for _, r := range recordings {
+		if remaining == 0 {
+			return 0
+		}
+
+		addresses, more := k.getHoldersAfter(ctx, r.token(), r.recording().LastHolder, remaining)
+		for _, address := range addresses {
+			remaining--
+			k.recordHolder(ctx, r, address)
+			r.recording().LastHolder = address
+		}
+
+		if !more {
+			k.endHolderRecording(ctx, r)
+		} else {
+			k.setHolderRecording(ctx, r)
+		}
+	}
+	return remaining
+}
+
+// getHoldersAfter returns at most limit of the accounts in the bond's holder
+// index, in order of address, starting from the first address after the
+// specified one, or from the first address if the specified one is empty, and
+// whether there are any more accounts after the returned ones.
+func (k Keeper) getHoldersAfter(ctx sdk.Context, token string,
+	after sdk.AccAddress, limit uint64) (addresses []sdk.AccAddress, more bool) {
+
+	store := ctx.KVStore(k.storeKey)
+	prefix := types.GetHoldersPrefixKey(token)
+	start := prefix
+	if !after.Empty() {
+		// Smallest key that is greater than the key of the address
+		start = append(types.GetHolderKey(token, after), 0x00)
+	}
+
+	iterator := store.Iterator(start, sdk.PrefixEndBytes(prefix))
+	defer iterator.Close()
+
+	for ; iterator.Valid() && uint64(len(addresses)) < limit; iterator.Next() {
+		addresses = append(addresses, sdk.AccAddress(iterator.Key()[len(prefix):]))
+	}
+	return addresses, iterator.Valid()
+}
+
+// recordHolder records the balance of the indexed holder as it is visited,
+// unless its balance was already recorded before it changed, or it is a module
+// account. A balance that was recorded as zero is removed, since there is no
+// longer any need to keep it, and so is an index entry of an account that no
+// longer holds any of the bond's tokens.
+func (k Keeper) recordHolder(ctx sdk.Context, r holderRecording, address sdk.AccAddress) {
+	store := ctx.KVStore(k.storeKey)
+	if holder, found := k.getRecordedHolder(ctx, r, address); found {
+		if holder.Balance.IsZero() {
+			store.Delete(r.holderKey(address))
+		}
+		return
+	}
+
+	balance, ok := k.getHolderBalance(ctx, address, r.token())
+	if !balance.IsPositive() {
+		store.Delete(types.GetHolderKey(r.token(), address))
+	} else if ok {
+		holder := types.NewHolderSnapshot(address, balance)
+		store.Set(r.holderKey(address), k.cdc.MustMarshalBinaryBare(holder))
+		r.addBalance(balance)
+	}
+}
+
+// endHolderRecording completes the recording of the holders of the settlement
+// snapshot or tranche distribution. If the bond distributes its reserve
+// automatically, the distribution of its reserve starts once its snapshot is
+// complete. A tranche that has no holders to be distributed to is sent to the
+// bond's reserve instead.
+func (k Keeper) endHolderRecording(ctx sdk.Context, r holderRecording) {
+	r.recording().InProgress = false
+	k.setHolderRecording(ctx, r)
+
+	event := sdk.NewEvent(
+		types.EventTypeHoldersRecorded,
+		sdk.NewAttribute(types.AttributeKeyBond, r.token()),
+		sdk.NewAttribute(types.AttributeKeySnapshotHolders,
+			strconv.FormatUint(r.recording().HolderCount, 10)),
+	)
+
+	logger := k.Logger(ctx)
+	if r.snapshot != nil {
+		logger.Info(fmt.Sprintf("snapshotted %d holders of %s%s at height %d",
+			r.recording().HolderCount, r.snapshot.TotalBalance, r.token(), r.snapshot.Height))
+		ctx.EventManager().EmitEvent(event.AppendAttributes(
+			sdk.NewAttribute(types.AttributeKeySnapshotHeight, fmt.Sprint(r.snapshot.Height)),
+		))
+
+		if k.MustGetBond(ctx, r.token()).AutoDistribute {
+			k.SetShareDistribution(ctx, types.NewShareDistribution(r.token()))
+		}
+		return
+	}
+
+	logger.Info(fmt.Sprintf("recorded %d holders of %s%s for tranche %d",
+		r.recording().HolderCount, r.distribution.TotalBalance, r.token(), r.distribution.Tranche))
+	ctx.EventManager().EmitEvent(event.AppendAttributes(
+		sdk.NewAttribute(types.AttributeKeyTranche, strconv.FormatUint(r.distribution.Tranche, 10)),
+	))
+
+	if r.recording().HolderCount == 0 {
+		k.endTrancheDistribution(ctx, *r.distribution)
+	}
+}
@@ -45,14 +45,13 @@ func SupplyInvariant(k Keeper) sdk.Invariant {
 		for ; iterator.Valid(); iterator.Next() {
 			bond := k.MustGetBondByKey(ctx, iterator.Key())
 			denom := bond.Token
-			batch := k.MustGetBatch(ctx, denom)
 
 			// Add bond current supply
 			supplyInBondsAndBatches := bond.CurrentSupply
 
 			// Subtract amount to be burned (this amount was already burned
 			// in handleMsgSell but is still a part of bond's CurrentSupply)
-			for _, s := range batch.Sells {
+			for _, s := range k.GetBatchSellOrders(ctx, denom) {
 				if !s.Cancelled {
 					supplyInBondsAndBatches = supplyInBondsAndBatches.Sub(
 						s.Amount)
@@ -87,12 +86,18 @@ func ReserveInvariant(k Keeper) sdk.Invariant {
 			bond := k.MustGetBondByKey(ctx, iterator.Key())
 			denom := bond.Token
 
-			if bond.FunctionType == types.AugmentedFunction ||
-				bond.FunctionType == types.SwapperFunction {
-				continue // Check does not apply to augmented/swapper functions
+			if !types.MustGetFunction(bond.FunctionType).EnforcesReserveInvariant() {
+				continue // Check does not apply to e.g. augmented/swapper functions
 			}
 
-			expectedReserve := bond.ReserveAtSupply(bond.CurrentSupply.Amount)
+			expectedReserve, err := bond.ReserveAtSupply(bond.CurrentSupply.Amount)
+			if err != nil {
+				count++
+				msg += fmt.Sprintf("%s reserve invariance:\n"+
+					"\tcould not calculate expected reserve: %s\n",
+					denom, err.Error())
+				continue
+			}
 			expectedRounded := expectedReserve.Ceil().TruncateInt()
 			actualReserve := k.GetReserveBalances(ctx, denom)
 
@@ -6,6 +6,7 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/x/auth"
 	"github.com/cosmos/cosmos-sdk/x/bank"
+	"github.com/cosmos/cosmos-sdk/x/distribution"
 	"github.com/cosmos/cosmos-sdk/x/params"
 	"github.com/cosmos/cosmos-sdk/x/staking"
 	"github.com/cosmos/cosmos-sdk/x/supply"
@@ -18,16 +19,21 @@ type Keeper struct {
 	SupplyKeeper  supply.Keeper
 	accountKeeper auth.AccountKeeper
 	StakingKeeper staking.Keeper
+	DistrKeeper   distribution.Keeper
+
+	outcomesKeeper types.OutcomesKeeper
 
 	storeKey   sdk.StoreKey
 	paramSpace params.Subspace
 
 	cdc *codec.Codec
+
+	bondCache *bondCache
 }
 
 func NewKeeper(bankKeeper bank.Keeper, supplyKeeper supply.Keeper,
 	accountKeeper auth.AccountKeeper, stakingKeeper staking.Keeper,
-	storeKey sdk.StoreKey, paramSpace params.Subspace,
+	distrKeeper distribution.Keeper, storeKey sdk.StoreKey, paramSpace params.Subspace,
 	cdc *codec.Codec) Keeper {
 
 	// ensure batches module account is set
@@ -45,10 +51,23 @@ func NewKeeper(bankKeeper bank.Keeper, supplyKeeper supply.Keeper,
 		SupplyKeeper:  supplyKeeper,
 		accountKeeper: accountKeeper,
 		StakingKeeper: stakingKeeper,
+		DistrKeeper:   distrKeeper,
 		storeKey:      storeKey,
 		paramSpace:    paramSpace,
 		cdc:           cdc,
+		bondCache:     newBondCache(),
+	}
+}
+
+// SetOutcomesKeeper sets the outcomes keeper, which holds the evaluators'
+// attestations of the outcome payments of bonds with evaluators. It has to
+// be set after the outcomes keeper is created, since it depends on this one.
+func (k *Keeper) SetOutcomesKeeper(outcomesKeeper types.OutcomesKeeper) *Keeper {
+	if k.outcomesKeeper != nil {
+		panic("cannot set outcomes keeper twice")
 	}
+	k.outcomesKeeper = outcomesKeeper
+	return k
 }
 
 // GetParams returns the total set of peyote parameters.
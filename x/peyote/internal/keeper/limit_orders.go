@@ -0,0 +1,353 @@
+package keeper
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/warmage-sports/peyote/x/peyote/internal/types"
+)
+
+func (k Keeper) GetLimitOrder(ctx sdk.Context, id uint64) (order types.LimitOrder, found bool) {
+	store := ctx.KVStore(k.storeKey)
+	if !store.Has(types.GetLimitOrderKey(id)) {
+		return types.LimitOrder{}, false
+	}
+
+	bz := store.Get(types.GetLimitOrderKey(id))
+	k.cdc.MustUnmarshalBinaryBare(bz, &order)
+
+	return order, true
+}
+
+func (k Keeper) MustGetLimitOrder(ctx sdk.Context, id uint64) types.LimitOrder {
+	order, found := k.GetLimitOrder(ctx, id)
+	if !found {
+		panic(fmt.Sprintf("limit order %d not found\n", id))
+	}
+	return order
+}
+
+func (k Keeper) MustGetLimitOrderByKey(ctx sdk.Context, key []byte) types.LimitOrder {
+	store := ctx.KVStore(k.storeKey)
+	if !store.Has(key) {
+		panic("limit order not found")
+	}
+
+	bz := store.Get(key)
+	var order types.LimitOrder
+	k.cdc.MustUnmarshalBinaryBare(bz, &order)
+
+	return order
+}
+
+// SetLimitOrder stores the limit order and adds it to the bond's order book
+// and to the expiry queue. The order's ID is expected to be already assigned.
+func (k Keeper) SetLimitOrder(ctx sdk.Context, order types.LimitOrder) {
+	store := ctx.KVStore(k.storeKey)
+	idBz := sdk.Uint64ToBigEndian(order.Id)
+	store.Set(types.GetLimitOrderKey(order.Id), k.cdc.MustMarshalBinaryBare(order))
+	store.Set(types.GetLimitOrderBookKey(order), idBz)
+	store.Set(types.GetLimitOrderExpiryKey(order), idBz)
+}
+
+// AddLimitOrder assigns the next order ID to the order and stores it. Limit
+// orders share IDs with batch orders, so that an order ID in an event always
+// identifies a single order. The escrowed coins are expected to have already
+// been sent to the batches intermediary account.
+func (k Keeper) AddLimitOrder(ctx sdk.Context, order types.LimitOrder) types.LimitOrder {
+	order.Id = k.nextOrderId(ctx)
+	k.SetLimitOrder(ctx, order)
+	k.ScheduleBatch(ctx, order.Amount.Denom)
+
+	logger := k.Logger(ctx)
+	logger.Info(fmt.Sprintf("added %s order %d for %s from %s", order.OrderType,
+		order.Id, order.Amount.String(), order.Address.String()))
+
+	return order
+}
+
+func (k Keeper) RemoveLimitOrder(ctx sdk.Context, order types.LimitOrder) {
+	store := ctx.KVStore(k.storeKey)
+	store.Delete(types.GetLimitOrderKey(order.Id))
+	store.Delete(types.GetLimitOrderBookKey(order))
+	store.Delete(types.GetLimitOrderExpiryKey(order))
+}
+
+// cancelLimitOrder removes the limit order from the order book on behalf of
+// its owner and returns its escrow. The type of the order is returned.
+func (k Keeper) cancelLimitOrder(ctx sdk.Context, order types.LimitOrder, owner sdk.AccAddress) (orderType string, err error) {
+	if !order.Address.Equals(owner) {
+		return "", sdkerrors.Wrapf(types.ErrOrderNotOwnedBySender, "order %d", order.Id)
+	}
+
+	k.RemoveLimitOrder(ctx, order)
+	err = k.SupplyKeeper.SendCoinsFromModuleToAccount(ctx,
+		types.BatchesIntermediaryAccount, order.Address, order.Escrow)
+	if err != nil {
+		return "", err
+	}
+
+	logger := k.Logger(ctx)
+	logger.Info(fmt.Sprintf("cancelled %s order %d from %s", order.OrderType, order.Id, owner.String()))
+
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		types.EventTypeOrderCancel,
+		sdk.NewAttribute(types.AttributeKeyBond, order.Amount.Denom),
+		sdk.NewAttribute(types.AttributeKeyOrderId, fmt.Sprint(order.Id)),
+		sdk.NewAttribute(types.AttributeKeyOrderType, order.OrderType),
+		sdk.NewAttribute(types.AttributeKeyAddress, order.Address.String()),
+		sdk.NewAttribute(types.AttributeKeyCancelReason, "cancelled by owner"),
+	))
+
+	return order.OrderType, nil
+}
+
+func (k Keeper) GetLimitOrdersIterator(ctx sdk.Context) sdk.Iterator {
+	store := ctx.KVStore(k.storeKey)
+	return sdk.KVStorePrefixIterator(store, types.LimitOrdersKeyPrefix)
+}
+
+// GetLimitOrderBook returns the bond's limit buys or limit sells in order of
+// priority, i.e. limit buys by descending limit price and limit sells by
+// ascending limit price. Orders with the same limit price are ordered by ID.
+func (k Keeper) GetLimitOrderBook(ctx sdk.Context, token string, buys bool) []types.LimitOrder {
+	return k.getLimitOrderBookTop(ctx, token, buys, 0)
+}
+
+// getLimitOrderBookTop returns at most limit of the bond's limit buys or limit
+// sells with the highest priority (or all of them if limit is zero), so that
+// only the orders that can be considered are loaded.
+func (k Keeper) getLimitOrderBookTop(ctx sdk.Context, token string, buys bool, limit uint64) (orders []types.LimitOrder) {
+	store := ctx.KVStore(k.storeKey)
+	prefix := types.GetLimitOrderBookPrefixKey(token, buys)
+
+	var iterator sdk.Iterator
+	if buys {
+		iterator = sdk.KVStoreReversePrefixIterator(store, prefix)
+	} else {
+		iterator = sdk.KVStorePrefixIterator(store, prefix)
+	}
+	defer iterator.Close()
+
+	var ids []uint64
+	for ; iterator.Valid(); iterator.Next() {
+		if limit != 0 && uint64(len(ids)) == limit {
+			break
+		}
+		ids = append(ids, binary.BigEndian.Uint64(iterator.Value()))
+	}
+
+	for _, id := range ids {
+		orders = append(orders, k.MustGetLimitOrder(ctx, id))
+	}
+	return orders
+}
+
+// MatchLimitOrders adds any limit orders in the bond's order book whose limit
+// price can be met to the bond's batch, in order of priority. A limit buy is
+// added if the buy price after adding it does not exceed its limit price and
+// does not make any other buy unfulfillable. A limit sell is added if the sell
+// price after adding it is not below its limit price. Limit orders are only
+// added if they fit in the batch, and are otherwise left in the order book.
+//
+// Matching of each side of the order book stops at the first order whose limit
+// price is not met, since the following orders have worse limit prices. At
+// most MaxLimitOrderMatches limit orders are considered for each batch.
+func (k Keeper) MatchLimitOrders(ctx sdk.Context, token string) {
+	bond := k.MustGetBond(ctx, token)
+	if types.MustGetFunction(bond.FunctionType).IsLiquidityPool() {
+		return // limit orders are not available for liquidity pools
+	}
+
+	remaining := k.GetParams(ctx).MaxLimitOrderMatches
+	if bond.State == types.OpenState || bond.State == types.HatchState {
+		remaining = k.matchLimitOrderBook(ctx, token, true, remaining, k.matchLimitBuy)
+	}
+	if bond.State == types.OpenState && bond.AllowSells {
+		k.matchLimitOrderBook(ctx, token, false, remaining, k.matchLimitSell)
+	}
+}
+
+// matchLimitOrderBook tries to match at most limit of the limit buys or limit
+// sells in the bond's order book, and returns how many more orders can still
+// be considered. Matching stops once the batch has its maximum number of
+// orders, or at the first order whose limit price is not met.
+func (k Keeper) matchLimitOrderBook(ctx sdk.Context, token string, buys bool, limit uint64,
+	match func(ctx sdk.Context, token string, order types.LimitOrder) error) (remaining uint64) {
+	remaining = limit
+	if remaining == 0 {
+		return 0
+	}
+
+	for _, order := range k.getLimitOrderBookTop(ctx, token, buys, limit) {
+		if k.CheckBatchHasRoom(ctx, token, sdk.ZeroInt()) != nil {
+			return remaining
+		}
+		remaining--
+
+		err := match(ctx, token, order)
+		if err != nil {
+			k.Logger(ctx).Debug(fmt.Sprintf("did not match %s %d: %s",
+				order.OrderType, order.Id, err.Error()))
+			if errors.Is(err, types.ErrLimitPriceNotMet) {
+				return remaining
+			}
+		}
+	}
+	return remaining
+}
+
+func (k Keeper) matchLimitBuy(ctx sdk.Context, token string, order types.LimitOrder) error {
+	bo := types.NewBuyOrder(order.Address, order.Amount, order.Escrow)
+
+	return performInCacheContext(ctx, func(ctx sdk.Context) error {
+		// Check that the buy fits in the batch (e.g. its maximum volume)
+		err := k.CheckBatchHasRoom(ctx, token, order.Amount.Amount)
+		if err != nil {
+			return err
+		}
+
+		// Get prices after buy (also checks that the buy is fulfillable). The
+		// escrow is the cost of the buy at the limit price, so the max price
+		// being exceeded means that the limit price cannot be met.
+		buyPrices, sellPrices, err := k.GetUpdatedBatchPricesAfterBuy(ctx, token, bo)
+		if errors.Is(err, types.ErrMaxPriceExceeded) {
+			return sdkerrors.Wrap(types.ErrLimitPriceNotMet, err.Error())
+		} else if err != nil {
+			return err
+		}
+
+		// Check that the buy price does not exceed the limit price
+		for _, p := range buyPrices {
+			if p.Amount.GT(order.LimitPrice) {
+				return sdkerrors.Wrapf(types.ErrLimitPriceNotMet,
+					"buy price %s exceeds limit price %s", p, order.LimitPrice)
+			}
+		}
+
+		// Check that all other buys are still fulfillable
+		for _, other := range k.GetBatchBuyOrders(ctx, token) {
+			if !other.IsCancelled() {
+				err = k.CheckIfBuyOrderFulfillableAtPrice(ctx, token, other, buyPrices)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		k.AddBuyOrder(ctx, token, bo, buyPrices, sellPrices)
+		k.RemoveLimitOrder(ctx, order)
+		k.emitLimitOrderMatchEvent(ctx, token, order)
+		return nil
+	})
+}
+
+func (k Keeper) matchLimitSell(ctx sdk.Context, token string, order types.LimitOrder) error {
+	so := types.NewSellOrder(order.Address, order.Amount, nil)
+
+	return performInCacheContext(ctx, func(ctx sdk.Context) error {
+		// Check that the sell fits in the batch (e.g. its maximum volume)
+		err := k.CheckBatchHasRoom(ctx, token, order.Amount.Amount)
+		if err != nil {
+			return err
+		}
+
+		// Get prices after sell
+		buyPrices, sellPrices, err := k.GetUpdatedBatchPricesAfterSell(ctx, token, so)
+		if err != nil {
+			return err
+		}
+
+		// Check that the sell price is not below the limit price
+		bond := k.MustGetBond(ctx, token)
+		for _, r := range bond.ReserveTokens {
+			if sellPrices.AmountOf(r).LT(order.LimitPrice) {
+				return sdkerrors.Wrapf(types.ErrLimitPriceNotMet,
+					"sell price %s%s is below limit price %s",
+					sellPrices.AmountOf(r), r, order.LimitPrice)
+			}
+		}
+
+		// Check that all other sells are still fulfillable
+		for _, other := range k.GetBatchSellOrders(ctx, token) {
+			if !other.IsCancelled() {
+				err = k.CheckIfSellOrderFulfillableAtPrice(ctx, token, other, sellPrices)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		// Burn escrowed bond tokens, as is done for sells in MsgSell
+		err = k.SupplyKeeper.SendCoinsFromModuleToModule(ctx,
+			types.BatchesIntermediaryAccount, types.BondsMintBurnAccount, order.Escrow)
+		if err != nil {
+			return err
+		}
+		err = k.SupplyKeeper.BurnCoins(ctx, types.BondsMintBurnAccount, order.Escrow)
+		if err != nil {
+			return err
+		}
+
+		k.AddSellOrder(ctx, token, so, buyPrices, sellPrices)
+		k.RemoveLimitOrder(ctx, order)
+		k.emitLimitOrderMatchEvent(ctx, token, order)
+		return nil
+	})
+}
+
+func (k Keeper) emitLimitOrderMatchEvent(ctx sdk.Context, token string, order types.LimitOrder) {
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		types.EventTypeLimitOrderMatch,
+		sdk.NewAttribute(types.AttributeKeyBond, token),
+		sdk.NewAttribute(types.AttributeKeyOrderId, fmt.Sprint(order.Id)),
+		sdk.NewAttribute(types.AttributeKeyOrderType, order.OrderType),
+		sdk.NewAttribute(types.AttributeKeyAddress, order.Address.String()),
+		sdk.NewAttribute(sdk.AttributeKeyAmount, order.Amount.String()),
+	))
+}
+
+// CancelExpiredLimitOrders removes any limit orders with an expiry height
+// that has been reached and refunds the escrowed coins to the order owners.
+func (k Keeper) CancelExpiredLimitOrders(ctx sdk.Context) {
+	store := ctx.KVStore(k.storeKey)
+	end := sdk.PrefixEndBytes(types.GetLimitOrderExpiryPrefixKey(ctx.BlockHeight()))
+
+	var ids []uint64
+	iterator := store.Iterator(types.LimitOrderExpiryKeyPrefix, end)
+	for ; iterator.Valid(); iterator.Next() {
+		ids = append(ids, binary.BigEndian.Uint64(iterator.Value()))
+	}
+	iterator.Close()
+
+	for _, id := range ids {
+		order := k.MustGetLimitOrder(ctx, id)
+		reason := fmt.Sprintf("limit order expired at height %d", order.ExpiryHeight)
+
+		err := performInCacheContext(ctx, func(ctx sdk.Context) error {
+			k.RemoveLimitOrder(ctx, order)
+			return k.SupplyKeeper.SendCoinsFromModuleToAccount(ctx,
+				types.BatchesIntermediaryAccount, order.Address, order.Escrow)
+		})
+		if err != nil {
+			// Order is kept so that the refund is retried in the next block
+			k.Logger(ctx).Error(fmt.Sprintf("could not refund expired %s order %d: %s",
+				order.OrderType, order.Id, err.Error()))
+			continue
+		}
+
+		k.Logger(ctx).Info(fmt.Sprintf("cancelled expired %s order %d for %s from %s",
+			order.OrderType, order.Id, order.Amount.String(), order.Address.String()))
+
+		ctx.EventManager().EmitEvent(sdk.NewEvent(
+			types.EventTypeOrderCancel,
+			sdk.NewAttribute(types.AttributeKeyBond, order.Amount.Denom),
+			sdk.NewAttribute(types.AttributeKeyOrderId, fmt.Sprint(order.Id)),
+			sdk.NewAttribute(types.AttributeKeyOrderType, order.OrderType),
+			sdk.NewAttribute(types.AttributeKeyAddress, order.Address.String()),
+			sdk.NewAttribute(types.AttributeKeyCancelReason, reason),
+		))
+	}
+}
@@ -0,0 +1,245 @@
+package keeper_test
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+	"github.com/warmage-sports/peyote/x/peyote/internal/types"
+)
+
+func newTestLimitBuy(amount int64, limitPrice int64, expiryHeight int64) types.LimitOrder {
+	bond := getValidBond()
+	escrow := bond.GetLimitBuyEscrow(sdk.NewInt(amount), sdk.NewDec(limitPrice))
+	return types.NewLimitOrder(types.LimitBuyOrderType, buyerAddress,
+		sdk.NewInt64Coin(token, amount), sdk.NewDec(limitPrice), escrow, expiryHeight)
+}
+
+func newTestLimitSell(amount int64, limitPrice int64, expiryHeight int64) types.LimitOrder {
+	escrow := sdk.NewCoins(sdk.NewInt64Coin(token, amount))
+	return types.NewLimitOrder(types.LimitSellOrderType, sellerAddress,
+		sdk.NewInt64Coin(token, amount), sdk.NewDec(limitPrice), escrow, expiryHeight)
+}
+
+func TestLimitOrderAddGetRemove(t *testing.T) {
+	app, ctx := createTestApp(false)
+	app.BondsKeeper.SetBond(ctx, token, getValidBond())
+	app.BondsKeeper.SetBatch(ctx, token, getValidBatch())
+
+	_, found := app.BondsKeeper.GetLimitOrder(ctx, 1)
+	require.False(t, found)
+	require.Equal(t, uint64(0), app.BondsKeeper.GetLastOrderId(ctx))
+
+	// IDs are assigned incrementally, from the same counter as batch orders
+	order1 := app.BondsKeeper.AddLimitOrder(ctx, newTestLimitBuy(10, 600, 100))
+	order2 := app.BondsKeeper.AddLimitOrder(ctx, newTestLimitSell(10, 400, 100))
+	require.Equal(t, uint64(1), order1.Id)
+	require.Equal(t, uint64(2), order2.Id)
+	require.Equal(t, uint64(2), app.BondsKeeper.GetLastOrderId(ctx))
+
+	returned, found := app.BondsKeeper.GetLimitOrder(ctx, order1.Id)
+	require.True(t, found)
+	require.Equal(t, order1, returned)
+
+	// Removing an order removes it from the order book as well
+	app.BondsKeeper.RemoveLimitOrder(ctx, order1)
+	_, found = app.BondsKeeper.GetLimitOrder(ctx, order1.Id)
+	require.False(t, found)
+	require.Len(t, app.BondsKeeper.GetLimitOrderBook(ctx, token, true), 0)
+	require.Len(t, app.BondsKeeper.GetLimitOrderBook(ctx, token, false), 1)
+}
+
+func TestGetLimitOrderBook(t *testing.T) {
+	app, ctx := createTestApp(false)
+	app.BondsKeeper.SetBond(ctx, token, getValidBond())
+	app.BondsKeeper.SetBatch(ctx, token, getValidBatch())
+
+	// Add limit buys and limit sells with different limit prices
+	buy1 := app.BondsKeeper.AddLimitOrder(ctx, newTestLimitBuy(1, 500, 100))
+	buy2 := app.BondsKeeper.AddLimitOrder(ctx, newTestLimitBuy(1, 700, 100))
+	buy3 := app.BondsKeeper.AddLimitOrder(ctx, newTestLimitBuy(1, 500, 100))
+	sell1 := app.BondsKeeper.AddLimitOrder(ctx, newTestLimitSell(1, 300, 100))
+	sell2 := app.BondsKeeper.AddLimitOrder(ctx, newTestLimitSell(1, 100, 100))
+	sell3 := app.BondsKeeper.AddLimitOrder(ctx, newTestLimitSell(1, 300, 100))
+
+	// Buys by descending limit price, then by ID
+	buys := app.BondsKeeper.GetLimitOrderBook(ctx, token, true)
+	require.Equal(t, []types.LimitOrder{buy2, buy1, buy3}, buys)
+
+	// Sells by ascending limit price, then by ID
+	sells := app.BondsKeeper.GetLimitOrderBook(ctx, token, false)
+	require.Equal(t, []types.LimitOrder{sell2, sell1, sell3}, sells)
+
+	// Other bonds have an empty order book
+	require.Len(t, app.BondsKeeper.GetLimitOrderBook(ctx, token2, true), 0)
+}
+
+func TestMatchLimitBuys(t *testing.T) {
+	app, ctx := createTestApp(false)
+
+	// Create bond and batch (with no fees for simpler test)
+	bond := getValidBond()
+	bond.TxFeePercentage = sdk.ZeroDec()
+	bond.ExitFeePercentage = sdk.ZeroDec()
+	app.BondsKeeper.SetBond(ctx, bond.Token, bond)
+	app.BondsKeeper.SetBatch(ctx, bond.Token, getValidBatch())
+
+	// Buy price for 10 tokens from zero supply is 500 per token, so the limit
+	// buy with a limit price of 600 is matched but the one with 400 is not
+	matched := app.BondsKeeper.AddLimitOrder(ctx, newTestLimitBuy(10, 600, 100))
+	unmatched := app.BondsKeeper.AddLimitOrder(ctx, newTestLimitBuy(10, 400, 100))
+	app.BondsKeeper.MatchLimitOrders(ctx, token)
+
+	// Matched limit buy added to batch with escrow as max prices
+	batch := app.BondsKeeper.MustGetBatch(ctx, token)
+	require.Len(t, batch.Buys, 1)
+	require.Equal(t, matched.Amount, batch.Buys[0].Amount)
+	require.Equal(t, matched.Escrow, batch.Buys[0].MaxPrices)
+	require.Equal(t, matched.Amount, batch.TotalBuyAmount)
+
+	// Matched limit buy removed from order book, unmatched one kept
+	_, found := app.BondsKeeper.GetLimitOrder(ctx, matched.Id)
+	require.False(t, found)
+	_, found = app.BondsKeeper.GetLimitOrder(ctx, unmatched.Id)
+	require.True(t, found)
+
+	events := ctx.EventManager().Events()
+	require.Equal(t, types.EventTypeLimitOrderMatch, events[len(events)-1].Type)
+}
+
+func TestMatchLimitBuysStopsAtFirstPriceMiss(t *testing.T) {
+	app, ctx := createTestApp(false)
+
+	// Create bond and batch (with no fees for simpler test)
+	bond := getValidBond()
+	bond.TxFeePercentage = sdk.ZeroDec()
+	bond.ExitFeePercentage = sdk.ZeroDec()
+	app.BondsKeeper.SetBond(ctx, bond.Token, bond)
+	app.BondsKeeper.SetBatch(ctx, bond.Token, getValidBatch())
+
+	// Buy price for 10 tokens from zero supply is 500 per token, so the limit
+	// buy with a limit price of 400 is not matched. The buy price for 1 token
+	// is 104, but the limit buy with a limit price of 200 is not considered
+	// since it has a lower priority than the order whose price was missed.
+	missed := app.BondsKeeper.AddLimitOrder(ctx, newTestLimitBuy(10, 400, 100))
+	notConsidered := app.BondsKeeper.AddLimitOrder(ctx, newTestLimitBuy(1, 200, 100))
+	app.BondsKeeper.MatchLimitOrders(ctx, token)
+
+	require.Len(t, app.BondsKeeper.MustGetBatch(ctx, token).Buys, 0)
+	_, found := app.BondsKeeper.GetLimitOrder(ctx, missed.Id)
+	require.True(t, found)
+	_, found = app.BondsKeeper.GetLimitOrder(ctx, notConsidered.Id)
+	require.True(t, found)
+}
+
+func TestMatchLimitOrdersConsidersAtMostMaxLimitOrderMatches(t *testing.T) {
+	app, ctx := createTestApp(false)
+
+	// Create bond and batch (with no fees for simpler test)
+	bond := getValidBond()
+	bond.TxFeePercentage = sdk.ZeroDec()
+	bond.ExitFeePercentage = sdk.ZeroDec()
+	app.BondsKeeper.SetBond(ctx, bond.Token, bond)
+	app.BondsKeeper.SetBatch(ctx, bond.Token, getValidBatch())
+
+	params := app.BondsKeeper.GetParams(ctx)
+	params.MaxLimitOrderMatches = 1
+	app.BondsKeeper.SetParams(ctx, params)
+
+	// Both limit buys can be matched, but only one is considered per batch
+	first := app.BondsKeeper.AddLimitOrder(ctx, newTestLimitBuy(10, 600, 100))
+	second := app.BondsKeeper.AddLimitOrder(ctx, newTestLimitBuy(1, 600, 100))
+	app.BondsKeeper.MatchLimitOrders(ctx, token)
+
+	batch := app.BondsKeeper.MustGetBatch(ctx, token)
+	require.Len(t, batch.Buys, 1)
+	require.Equal(t, first.Amount, batch.Buys[0].Amount)
+	_, found := app.BondsKeeper.GetLimitOrder(ctx, second.Id)
+	require.True(t, found)
+}
+
+func TestMatchLimitSells(t *testing.T) {
+	app, ctx := createTestApp(false)
+
+	// Create bond and batch (with no fees for simpler test)
+	bond := getValidBond()
+	bond.TxFeePercentage = sdk.ZeroDec()
+	bond.ExitFeePercentage = sdk.ZeroDec()
+	app.BondsKeeper.SetBond(ctx, bond.Token, bond)
+	app.BondsKeeper.SetBatch(ctx, bond.Token, getValidBatch())
+
+	// Simulate supply of 10 tokens (held in escrow) and reserve of 5000
+	escrowed := sdk.NewCoins(sdk.NewInt64Coin(token, 10))
+	reserve := sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 5000))
+	require.NoError(t, app.SupplyKeeper.MintCoins(ctx, types.BondsMintBurnAccount, escrowed))
+	require.NoError(t, app.SupplyKeeper.SendCoinsFromModuleToModule(ctx,
+		types.BondsMintBurnAccount, types.BatchesIntermediaryAccount, escrowed))
+	require.NoError(t, app.SupplyKeeper.MintCoins(ctx, types.BondsMintBurnAccount, reserve))
+	require.NoError(t, app.BondsKeeper.DepositReserveFromModule(
+		ctx, bond.Token, types.BondsMintBurnAccount, reserve))
+	app.BondsKeeper.SetCurrentSupply(ctx, bond.Token, escrowed[0])
+
+	// Sell price for all 10 tokens is 500 per token, so a limit sell with a
+	// limit price of 600 is not matched but one with 400 is
+	unmatched := app.BondsKeeper.AddLimitOrder(ctx, newTestLimitSell(10, 600, 100))
+	app.BondsKeeper.MatchLimitOrders(ctx, token)
+	require.Len(t, app.BondsKeeper.MustGetBatch(ctx, token).Sells, 0)
+	app.BondsKeeper.RemoveLimitOrder(ctx, unmatched)
+
+	matched := app.BondsKeeper.AddLimitOrder(ctx, newTestLimitSell(10, 400, 100))
+	app.BondsKeeper.MatchLimitOrders(ctx, token)
+
+	// Matched limit sell added to batch and escrowed tokens burned
+	batch := app.BondsKeeper.MustGetBatch(ctx, token)
+	require.Len(t, batch.Sells, 1)
+	require.Equal(t, matched.Amount, batch.Sells[0].Amount)
+	require.True(t, app.SupplyKeeper.GetSupply(ctx).GetTotal().AmountOf(token).IsZero())
+	_, found := app.BondsKeeper.GetLimitOrder(ctx, matched.Id)
+	require.False(t, found)
+}
+
+func TestCancelExpiredLimitOrders(t *testing.T) {
+	app, ctx := createTestApp(false)
+	app.BondsKeeper.SetBond(ctx, token, getValidBond())
+	app.BondsKeeper.SetBatch(ctx, token, getValidBatch())
+
+	// Add limit buys expiring at heights 5 and 6, with escrow in module account
+	expiring := app.BondsKeeper.AddLimitOrder(ctx, newTestLimitBuy(10, 600, 5))
+	notExpiring := app.BondsKeeper.AddLimitOrder(ctx, newTestLimitBuy(10, 600, 6))
+	moduleAcc := app.SupplyKeeper.GetModuleAccount(ctx, types.BatchesIntermediaryAccount)
+	_, err := app.BankKeeper.AddCoins(ctx, moduleAcc.GetAddress(),
+		expiring.Escrow.Add(notExpiring.Escrow...))
+	require.NoError(t, err)
+
+	// Nothing expires before height 5
+	ctx = ctx.WithBlockHeight(4)
+	app.BondsKeeper.CancelExpiredLimitOrders(ctx)
+	require.Len(t, app.BondsKeeper.GetLimitOrderBook(ctx, token, true), 2)
+
+	// First order expires at height 5 and escrow is refunded
+	ctx = ctx.WithBlockHeight(5)
+	app.BondsKeeper.CancelExpiredLimitOrders(ctx)
+	_, found := app.BondsKeeper.GetLimitOrder(ctx, expiring.Id)
+	require.False(t, found)
+	_, found = app.BondsKeeper.GetLimitOrder(ctx, notExpiring.Id)
+	require.True(t, found)
+	require.Equal(t, expiring.Escrow, app.BankKeeper.GetCoins(ctx, buyerAddress))
+	require.Equal(t, notExpiring.Escrow, app.BankKeeper.GetCoins(ctx, moduleAcc.GetAddress()))
+
+	events := ctx.EventManager().Events()
+	require.Equal(t, types.EventTypeOrderCancel, events[len(events)-1].Type)
+}
+
+func TestCancelExpiredLimitOrdersKeepsOrderIfRefundFails(t *testing.T) {
+	app, ctx := createTestApp(false)
+	app.BondsKeeper.SetBond(ctx, token, getValidBond())
+	app.BondsKeeper.SetBatch(ctx, token, getValidBatch())
+
+	// Add limit buy without adding escrow to module account
+	order := app.BondsKeeper.AddLimitOrder(ctx, newTestLimitBuy(10, 600, 5))
+
+	ctx = ctx.WithBlockHeight(5)
+	app.BondsKeeper.CancelExpiredLimitOrders(ctx)
+	_, found := app.BondsKeeper.GetLimitOrder(ctx, order.Id)
+	require.True(t, found)
+}
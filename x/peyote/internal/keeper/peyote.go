@@ -3,6 +3,7 @@ package keeper
 import (
 	"fmt"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
 	"github.com/warmage-sports/peyote/x/peyote/internal/types"
 )
 
@@ -11,14 +12,9 @@ func (k Keeper) GetBondIterator(ctx sdk.Context) sdk.Iterator {
 	return sdk.KVStorePrefixIterator(store, types.BondsKeyPrefix)
 }
 
+// GetBond returns the bond's configuration together with its accounting.
 func (k Keeper) GetBond(ctx sdk.Context, token string) (bond types.Bond, found bool) {
-	store := ctx.KVStore(k.storeKey)
-	if !k.BondExists(ctx, token) {
-		return
-	}
-	bz := store.Get(types.GetBondKey(token))
-	k.cdc.MustUnmarshalBinaryBare(bz, &bond)
-	return bond, true
+	return k.getBondByKey(ctx, types.GetBondKey(token))
 }
 
 func (k Keeper) MustGetBond(ctx sdk.Context, token string) types.Bond {
@@ -30,16 +26,31 @@ func (k Keeper) MustGetBond(ctx sdk.Context, token string) types.Bond {
 }
 
 func (k Keeper) MustGetBondByKey(ctx sdk.Context, key []byte) types.Bond {
-	store := ctx.KVStore(k.storeKey)
-	if !store.Has(key) {
+	bond, found := k.getBondByKey(ctx, key)
+	if !found {
 		panic("bond not found")
 	}
+	return bond
+}
+
+// getBondByKey only reads and decodes the bond if it is not already in the
+// bond cache for the context's store, and caches it otherwise.
+func (k Keeper) getBondByKey(ctx sdk.Context, key []byte) (bond types.Bond, found bool) {
+	cacheStore := k.bondCache.cacheStore(ctx, k.storeKey)
+	if bond, found = k.bondCache.get(cacheStore, key); found {
+		return bond, true
+	}
 
+	store := ctx.KVStore(k.storeKey)
 	bz := store.Get(key)
-	var bond types.Bond
-	k.cdc.MustUnmarshalBinaryBare(bz, &bond)
+	if bz == nil {
+		return
+	}
 
-	return bond
+	k.cdc.MustUnmarshalBinaryBare(bz, &bond)
+	bond = bond.WithAccounting(k.MustGetBondAccounting(ctx, bond.Token))
+	k.bondCache.set(cacheStore, key, bond)
+	return bond, true
 }
 
 func (k Keeper) BondExists(ctx sdk.Context, token string) bool {
@@ -47,9 +58,88 @@ func (k Keeper) BondExists(ctx sdk.Context, token string) bool {
 	return store.Has(types.GetBondKey(token))
 }
 
+// ReleaseBondToken removes a closed bond so that its token can be used by a
+// new bond, if the reuse of closed bonds' tokens is allowed. The token can
+// only be released once none of it is in circulation and the bond has no
+// pending orders or tranche distributions, so that nothing of the closed bond
+// carries over to the new one. The closed bond's batch history, holder index
+// and evaluators' attestations are removed along with the bond.
+func (k Keeper) ReleaseBondToken(ctx sdk.Context, token string) error {
+	bond := k.MustGetBond(ctx, token)
+	if bond.State != types.ClosedState || !k.GetParams(ctx).AllowBondTokenReuse {
+		return sdkerrors.Wrap(types.ErrBondAlreadyExists, token)
+	}
+
+	supply := k.SupplyKeeper.GetSupply(ctx).GetTotal().AmountOf(token)
+	if supply.IsPositive() {
+		return sdkerrors.Wrapf(types.ErrCannotReuseBondToken,
+			"%s%s of closed bond still in circulation", supply, token)
+	} else if k.HasPendingOrders(ctx, token) {
+		return sdkerrors.Wrapf(types.ErrCannotReuseBondToken,
+			"closed bond %s still has pending orders", token)
+	} else if k.HasTrancheDistributions(ctx, token) {
+		return sdkerrors.Wrapf(types.ErrCannotReuseBondToken,
+			"closed bond %s is still distributing an outcome tranche", token)
+	}
+
+	store := ctx.KVStore(k.storeKey)
+	store.Delete(types.GetBondKey(token))
+	store.Delete(types.GetBondAccountingKey(token))
+	k.bondCache.evict(types.GetBondKey(token))
+	store.Delete(types.GetBatchKey(token))
+	store.Delete(types.GetLastBatchKey(token))
+	k.RemoveSettlementSnapshot(ctx, token)
+	k.RemoveShareDistribution(ctx, token)
+	k.RemoveBatchHistory(ctx, token)
+	k.RemoveHolders(ctx, token)
+	if k.outcomesKeeper != nil {
+		k.outcomesKeeper.RemoveAttestations(ctx, token)
+	}
+
+	logger := k.Logger(ctx)
+	logger.Info(fmt.Sprintf("released token of closed bond %s", token))
+
+	return nil
+}
+
+// SetBond stores the bond's configuration and its accounting under separate
+// keys. The accounting fields of the stored configuration are left empty. The
+// bond is also written to the bond cache.
 func (k Keeper) SetBond(ctx sdk.Context, token string, bond types.Bond) {
 	store := ctx.KVStore(k.storeKey)
-	store.Set(types.GetBondKey(token), k.cdc.MustMarshalBinaryBare(bond))
+	key := types.GetBondKey(token)
+	config := bond.WithAccounting(types.BondAccounting{})
+	bz := k.cdc.MustMarshalBinaryBare(config)
+	store.Set(key, bz)
+
+	k.SetBondAccounting(ctx, token, bond.Accounting())
+	k.bondCache.set(k.bondCache.cacheStore(ctx, k.storeKey), key, bond)
+}
+
+func (k Keeper) MustGetBondAccounting(ctx sdk.Context, token string) (accounting types.BondAccounting) {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(types.GetBondAccountingKey(token))
+	if bz == nil {
+		panic(fmt.Sprintf("accounting for bond '%s' not found\n", token))
+	}
+	k.cdc.MustUnmarshalBinaryBare(bz, &accounting)
+	return accounting
+}
+
+// SetBondAccounting stores the bond's accounting. The bond is evicted from the
+// bond cache of any other store, and its accounting is updated in the cache of
+// the context's store.
+func (k Keeper) SetBondAccounting(ctx sdk.Context, token string, accounting types.BondAccounting) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(types.GetBondAccountingKey(token), k.cdc.MustMarshalBinaryBare(accounting))
+
+	key := types.GetBondKey(token)
+	cacheStore := k.bondCache.cacheStore(ctx, k.storeKey)
+	bond, cached := k.bondCache.get(cacheStore, key)
+	k.bondCache.evict(key)
+	if cached {
+		k.bondCache.set(cacheStore, key, bond.WithAccounting(accounting))
+	}
 }
 
 func (k Keeper) DepositReserve(ctx sdk.Context, token string, from sdk.AccAddress, amount sdk.Coins) error {
@@ -62,7 +152,7 @@ func (k Keeper) DepositReserve(ctx sdk.Context, token string, from sdk.AccAddres
 
 	// Update bond reserve
 	k.setReserveBalances(ctx, token,
-		k.MustGetBond(ctx, token).CurrentReserve.Add(amount...))
+		k.GetReserveBalances(ctx, token).Add(amount...))
 	return nil
 }
 
@@ -78,7 +168,7 @@ func (k Keeper) DepositReserveFromModule(ctx sdk.Context, token string,
 
 	// Update bond reserve
 	k.setReserveBalances(ctx, token,
-		k.MustGetBond(ctx, token).CurrentReserve.Add(amount...))
+		k.GetReserveBalances(ctx, token).Add(amount...))
 	return nil
 }
 
@@ -94,31 +184,47 @@ func (k Keeper) WithdrawReserve(ctx sdk.Context, token string,
 
 	// Update bond reserve
 	k.setReserveBalances(ctx, token,
-		k.MustGetBond(ctx, token).CurrentReserve.Sub(amount))
+		k.GetReserveBalances(ctx, token).Sub(amount))
+	return nil
+}
+
+// WithdrawReserveToCommunityPool sends an amount of the bond's reserve to the
+// community pool, rather than to an account.
+func (k Keeper) WithdrawReserveToCommunityPool(ctx sdk.Context, token string,
+	amount sdk.Coins) error {
+
+	// Send tokens from peyote reserve account
+	reserveAddress := k.SupplyKeeper.GetModuleAddress(types.BondsReserveAccount)
+	err := k.DistrKeeper.FundCommunityPool(ctx, amount, reserveAddress)
+	if err != nil {
+		return err
+	}
+
+	// Update bond reserve
+	k.setReserveBalances(ctx, token,
+		k.GetReserveBalances(ctx, token).Sub(amount))
 	return nil
 }
 
 func (k Keeper) setReserveBalances(ctx sdk.Context, token string, balance sdk.Coins) {
-	bond := k.MustGetBond(ctx, token)
-	bond.CurrentReserve = balance
-	k.SetBond(ctx, token, bond)
+	accounting := k.MustGetBondAccounting(ctx, token)
+	accounting.CurrentReserve = balance
+	k.SetBondAccounting(ctx, token, accounting)
 }
 
 func (k Keeper) GetReserveBalances(ctx sdk.Context, token string) sdk.Coins {
-	return k.MustGetBond(ctx, token).CurrentReserve
+	return k.MustGetBondAccounting(ctx, token).CurrentReserve
 }
 
 func (k Keeper) GetSupplyAdjustedForBuy(ctx sdk.Context, token string) sdk.Coin {
-	bond := k.MustGetBond(ctx, token)
-	batch := k.MustGetBatch(ctx, token)
-	supply := bond.CurrentSupply
+	supply := k.MustGetBondAccounting(ctx, token).CurrentSupply
+	batch := k.MustGetBatchHeader(ctx, token)
 	return supply.Add(batch.TotalBuyAmount)
 }
 
 func (k Keeper) GetSupplyAdjustedForSell(ctx sdk.Context, token string) sdk.Coin {
-	bond := k.MustGetBond(ctx, token)
-	batch := k.MustGetBatch(ctx, token)
-	supply := bond.CurrentSupply
+	supply := k.MustGetBondAccounting(ctx, token).CurrentSupply
+	batch := k.MustGetBatchHeader(ctx, token)
 	return supply.Sub(batch.TotalSellAmount)
 }
 
@@ -126,23 +232,29 @@ func (k Keeper) SetCurrentSupply(ctx sdk.Context, token string, currentSupply sd
 	if currentSupply.IsNegative() {
 		panic("current supply cannot be negative")
 	}
-	bond := k.MustGetBond(ctx, token)
-	bond.CurrentSupply = currentSupply
-	k.SetBond(ctx, token, bond)
+	accounting := k.MustGetBondAccounting(ctx, token)
+	accounting.CurrentSupply = currentSupply
+	k.SetBondAccounting(ctx, token, accounting)
+}
+
+func (k Keeper) SetTranchesPaid(ctx sdk.Context, token string, tranchesPaid uint64) {
+	accounting := k.MustGetBondAccounting(ctx, token)
+	accounting.TranchesPaid = tranchesPaid
+	k.SetBondAccounting(ctx, token, accounting)
 }
 
 func (k Keeper) SetBondState(ctx sdk.Context, token string, newState string) {
-	bond := k.MustGetBond(ctx, token)
-	previousState := bond.State
-	bond.State = newState
-	k.SetBond(ctx, token, bond)
+	accounting := k.MustGetBondAccounting(ctx, token)
+	previousState := accounting.State
+	accounting.State = newState
+	k.SetBondAccounting(ctx, token, accounting)
 
 	logger := k.Logger(ctx)
-	logger.Info(fmt.Sprintf("updated state for %s from %s to %s", bond.Token, previousState, newState))
+	logger.Info(fmt.Sprintf("updated state for %s from %s to %s", token, previousState, newState))
 
 	ctx.EventManager().EmitEvent(sdk.NewEvent(
 		types.EventTypeStateChange,
-		sdk.NewAttribute(types.AttributeKeyBond, bond.Token),
+		sdk.NewAttribute(types.AttributeKeyBond, token),
 		sdk.NewAttribute(types.AttributeKeyOldState, previousState),
 		sdk.NewAttribute(types.AttributeKeyNewState, newState),
 	))
@@ -39,6 +39,41 @@ func TestBondExistsSetGet(t *testing.T) {
 	require.True(t, found)
 }
 
+func TestSetBondAccountingKeepsConfiguration(t *testing.T) {
+	app, ctx := createTestApp(false)
+	bondAdded := getValidBond()
+	app.BondsKeeper.SetBond(ctx, token, bondAdded)
+
+	// Accounting of the added bond
+	require.Equal(t, bondAdded.Accounting(), app.BondsKeeper.MustGetBondAccounting(ctx, token))
+
+	// Update accounting
+	accounting := types.NewBondAccounting(sdk.NewInt64Coin(token, 10),
+		sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 20)), 1, types.SettleState)
+	app.BondsKeeper.SetBondAccounting(ctx, token, accounting)
+
+	// Bond reflects the new accounting but is otherwise unchanged
+	bondFetched := app.BondsKeeper.MustGetBond(ctx, token)
+	require.Equal(t, accounting, bondFetched.Accounting())
+	require.Equal(t, bondAdded.WithAccounting(accounting), bondFetched)
+}
+
+func TestGetBondIgnoresDiscardedWrites(t *testing.T) {
+	app, ctx := createTestApp(false)
+	bondAdded := getValidBond()
+	app.BondsKeeper.SetBond(ctx, token, bondAdded)
+
+	// Edit bond in a cached context whose writes are discarded
+	cacheCtx, _ := ctx.CacheContext()
+	bondEdited := bondAdded
+	bondEdited.Name = "edited name"
+	app.BondsKeeper.SetBond(cacheCtx, token, bondEdited)
+	require.Equal(t, bondEdited, app.BondsKeeper.MustGetBond(cacheCtx, token))
+
+	// Bond is unchanged in the original context
+	require.Equal(t, bondAdded, app.BondsKeeper.MustGetBond(ctx, token))
+}
+
 func TestDepositReserve(t *testing.T) {
 	app, ctx := createTestApp(false)
 
@@ -11,17 +11,22 @@ import (
 )
 
 const (
-	QueryBonds          = "peyote"
-	QueryBond           = "bond"
-	QueryBatch          = "batch"
-	QueryLastBatch      = "last_batch"
-	QueryCurrentPrice   = "current_price"
-	QueryCurrentReserve = "current_reserve"
-	QueryCustomPrice    = "custom_price"
-	QueryBuyPrice       = "buy_price"
-	QuerySellReturn     = "sell_return"
-	QuerySwapReturn     = "swap_return"
-	QueryParams         = "params"
+	QueryBonds            = "peyote"
+	QueryBond             = "bond"
+	QueryBatch            = "batch"
+	QueryLastBatch        = "last_batch"
+	QueryCurrentPrice     = "current_price"
+	QueryCurrentReserve   = "current_reserve"
+	QueryCustomPrice      = "custom_price"
+	QueryBuyPrice         = "buy_price"
+	QuerySellReturn       = "sell_return"
+	QuerySwapReturn       = "swap_return"
+	QueryParams           = "params"
+	QueryLimitOrders      = "limit_orders"
+	QueryOrderCommitments = "order_commitments"
+	QueryBatchHistory     = "batch_history"
+	QueryRecurringOrders  = "recurring_orders"
+	QueryHolderSnapshot   = "holder_snapshot"
 )
 
 // NewQuerier is the module level router for state queries
@@ -50,6 +55,16 @@ func NewQuerier(keeper Keeper) sdk.Querier {
 			return querySwapReturn(ctx, path[1:], keeper)
 		case QueryParams:
 			return queryParams(ctx, keeper)
+		case QueryLimitOrders:
+			return queryLimitOrders(ctx, path[1:], keeper)
+		case QueryOrderCommitments:
+			return queryOrderCommitments(ctx, path[1:], keeper)
+		case QueryBatchHistory:
+			return queryBatchHistory(ctx, path[1:], req, keeper)
+		case QueryRecurringOrders:
+			return queryRecurringOrders(ctx, path[1:], keeper)
+		case QueryHolderSnapshot:
+			return queryHolderSnapshot(ctx, path[1:], keeper)
 		default:
 			return nil, sdkerrors.Wrap(sdkerrors.ErrUnknownRequest, "unknown peyote query endpoint")
 		}
@@ -281,7 +296,10 @@ func querySellReturn(ctx sdk.Context, path []string, keeper Keeper) (res []byte,
 	}
 
 	reserveBalances := keeper.GetReserveBalances(ctx, bondToken)
-	reserveReturns := bond.GetReturnsForBurn(bondCoin.Amount, reserveBalances)
+	reserveReturns, err := bond.GetReturnsForBurn(bondCoin.Amount, reserveBalances)
+	if err != nil {
+		return nil, err
+	}
 	reserveReturnsRounded := types.RoundReserveReturns(reserveReturns)
 
 	txFees := bond.GetTxFees(reserveReturns)
@@ -352,3 +370,115 @@ func queryParams(ctx sdk.Context, k Keeper) ([]byte, error) {
 
 	return res, nil
 }
+
+func queryLimitOrders(ctx sdk.Context, path []string, keeper Keeper) (res []byte, err error) {
+	bondToken := path[0]
+
+	if !keeper.BondExists(ctx, bondToken) {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrUnknownRequest, "bond '%s' does not exist", bondToken)
+	}
+
+	limitOrders := types.QueryLimitOrders{
+		Buys:  keeper.GetLimitOrderBook(ctx, bondToken, true),
+		Sells: keeper.GetLimitOrderBook(ctx, bondToken, false),
+	}
+
+	bz, err2 := codec.MarshalJSONIndent(keeper.cdc, limitOrders)
+	if err2 != nil {
+		panic("could not marshal result to JSON")
+	}
+
+	return bz, nil
+}
+
+func queryOrderCommitments(ctx sdk.Context, path []string, keeper Keeper) (res []byte, err error) {
+	bondToken := path[0]
+
+	if !keeper.BondExists(ctx, bondToken) {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrUnknownRequest, "bond '%s' does not exist", bondToken)
+	}
+
+	commitments := keeper.GetOrderCommitments(ctx, bondToken)
+	if commitments == nil {
+		commitments = []types.OrderCommitment{}
+	}
+
+	bz, err2 := codec.MarshalJSONIndent(keeper.cdc, commitments)
+	if err2 != nil {
+		panic("could not marshal result to JSON")
+	}
+
+	return bz, nil
+}
+
+func queryBatchHistory(ctx sdk.Context, path []string, req abci.RequestQuery, keeper Keeper) (res []byte, err error) {
+	bondToken := path[0]
+
+	if !keeper.BondExists(ctx, bondToken) {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrUnknownRequest, "bond '%s' does not exist", bondToken)
+	}
+
+	var params types.QueryBatchHistoryParams
+	err = keeper.cdc.UnmarshalJSON(req.Data, &params)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONUnmarshal, err.Error())
+	} else if params.Page < 1 || params.Limit < 1 {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest,
+			"page and limit must be positive, got %d and %d", params.Page, params.Limit)
+	} else if params.Limit > types.MaxBatchHistoryLimit {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest,
+			"limit cannot exceed %d, got %d", types.MaxBatchHistoryLimit, params.Limit)
+	}
+
+	records := keeper.GetBatchHistory(ctx, bondToken, params.Page, params.Limit)
+	if records == nil {
+		records = []types.BatchRecord{}
+	}
+
+	bz, err2 := codec.MarshalJSONIndent(keeper.cdc, records)
+	if err2 != nil {
+		panic("could not marshal result to JSON")
+	}
+
+	return bz, nil
+}
+
+func queryRecurringOrders(ctx sdk.Context, path []string, keeper Keeper) (res []byte, err error) {
+	address, err := sdk.AccAddressFromBech32(path[0])
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, err.Error())
+	}
+
+	orders := keeper.GetRecurringOrdersByOwner(ctx, address)
+	if orders == nil {
+		orders = []types.RecurringOrder{}
+	}
+
+	bz, err2 := codec.MarshalJSONIndent(keeper.cdc, orders)
+	if err2 != nil {
+		panic("could not marshal result to JSON")
+	}
+
+	return bz, nil
+}
+
+func queryHolderSnapshot(ctx sdk.Context, path []string, keeper Keeper) (res []byte, err error) {
+	bondToken := path[0]
+	address, err := sdk.AccAddressFromBech32(path[1])
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, err.Error())
+	}
+
+	holder, found := keeper.GetHolderSnapshot(ctx, bondToken, address)
+	if !found {
+		return nil, sdkerrors.Wrapf(types.ErrSettlementSnapshotNotFound,
+			"no holder snapshot for %s in %s", address, bondToken)
+	}
+
+	bz, err2 := codec.MarshalJSONIndent(keeper.cdc, holder)
+	if err2 != nil {
+		panic("could not marshal result to JSON")
+	}
+
+	return bz, nil
+}
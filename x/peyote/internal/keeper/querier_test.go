@@ -492,7 +492,7 @@ func TestQuerySellPrice(t *testing.T) {
 	bond, _ = app.BondsKeeper.GetBond(ctx, token)
 	sellAmount := sdk.NewInt(10)
 	reserveBalances := app.BondsKeeper.GetReserveBalances(ctx, token)
-	sellReturns := bond.GetReturnsForBurn(buyAmount, reserveBalances)
+	sellReturns, _ := bond.GetReturnsForBurn(buyAmount, reserveBalances)
 	txFees := bond.GetTxFees(sellReturns)
 	exitFees := bond.GetExitFees(sellReturns)
 	totalFees := txFees.Add(exitFees...)
@@ -581,3 +581,139 @@ func TestQuerySwapReturn(t *testing.T) {
 	require.Equal(t, queryResult.TotalReturns, manualSwapReturns)
 	require.Equal(t, queryResult.TotalFees, sdk.Coins{txFee})
 }
+
+func TestQueryLimitOrders(t *testing.T) {
+	app, ctx := createTestApp(false)
+	querier := keeper.NewQuerier(app.BondsKeeper)
+	req := abci.RequestQuery{}
+	var queryResult types.QueryLimitOrders
+
+	// Initially error since no bond
+	res, err := querier(ctx, []string{keeper.QueryLimitOrders, token}, req)
+	require.Error(t, err)
+	require.Nil(t, res)
+
+	// Add bond and limit orders
+	bond := getValidBond()
+	app.BondsKeeper.SetBond(ctx, token, bond)
+	app.BondsKeeper.SetBatch(ctx, token, getValidBatch())
+	buy := app.BondsKeeper.AddLimitOrder(ctx, newTestLimitBuy(10, 600, 100))
+	sell := app.BondsKeeper.AddLimitOrder(ctx, newTestLimitSell(10, 400, 100))
+
+	// No error and limit orders returned
+	res, err = querier(ctx, []string{keeper.QueryLimitOrders, token}, req)
+	require.NoError(t, err)
+	require.NotNil(t, res)
+	types.ModuleCdc.MustUnmarshalJSON(res, &queryResult)
+	require.Equal(t, []types.LimitOrder{buy}, queryResult.Buys)
+	require.Equal(t, []types.LimitOrder{sell}, queryResult.Sells)
+}
+
+func TestQueryOrderCommitments(t *testing.T) {
+	app, ctx := createTestApp(false)
+	querier := keeper.NewQuerier(app.BondsKeeper)
+	req := abci.RequestQuery{}
+	var queryResult []types.OrderCommitment
+
+	// Initially error since no bond
+	res, err := querier(ctx, []string{keeper.QueryOrderCommitments, token}, req)
+	require.Error(t, err)
+	require.Nil(t, res)
+
+	// Add bond
+	bond := getValidBond()
+	app.BondsKeeper.SetBond(ctx, token, bond)
+	app.BondsKeeper.SetBatch(ctx, token, getValidBatch())
+
+	// No error and no order commitments returned
+	res, err = querier(ctx, []string{keeper.QueryOrderCommitments, token}, req)
+	require.NoError(t, err)
+	require.NotNil(t, res)
+	types.ModuleCdc.MustUnmarshalJSON(res, &queryResult)
+	require.Len(t, queryResult, 0)
+
+	// Add order commitment
+	commitment := app.BondsKeeper.AddOrderCommitment(ctx, newTestOrderCommitment(buyerAddress, 10))
+
+	// No error and order commitment returned
+	res, err = querier(ctx, []string{keeper.QueryOrderCommitments, token}, req)
+	require.NoError(t, err)
+	require.NotNil(t, res)
+	types.ModuleCdc.MustUnmarshalJSON(res, &queryResult)
+	require.Equal(t, []types.OrderCommitment{commitment}, queryResult)
+}
+
+func TestQueryBatchHistory(t *testing.T) {
+	app, ctx := createTestApp(false)
+	querier := keeper.NewQuerier(app.BondsKeeper)
+	params := types.NewQueryBatchHistoryParams(1, 1)
+	req := abci.RequestQuery{Data: types.ModuleCdc.MustMarshalJSON(params)}
+	var queryResult []types.BatchRecord
+
+	// Initially error since no bond
+	res, err := querier(ctx, []string{keeper.QueryBatchHistory, token}, req)
+	require.Error(t, err)
+	require.Nil(t, res)
+
+	// Add bond
+	app.BondsKeeper.SetBond(ctx, token, getValidBond())
+
+	// No error and no batch records returned
+	res, err = querier(ctx, []string{keeper.QueryBatchHistory, token}, req)
+	require.NoError(t, err)
+	require.NotNil(t, res)
+	types.ModuleCdc.MustUnmarshalJSON(res, &queryResult)
+	require.Len(t, queryResult, 0)
+
+	// Archive batches
+	app.BondsKeeper.ArchiveBatch(ctx, types.NewBatch(token, 1, sdk.OneUint(), sdk.ZeroUint()))
+	app.BondsKeeper.ArchiveBatch(ctx, types.NewBatch(token, 2, sdk.OneUint(), sdk.ZeroUint()))
+
+	// No error and most recent batch record returned
+	res, err = querier(ctx, []string{keeper.QueryBatchHistory, token}, req)
+	require.NoError(t, err)
+	require.NotNil(t, res)
+	types.ModuleCdc.MustUnmarshalJSON(res, &queryResult)
+	require.Equal(t, []types.BatchRecord{types.NewBatchRecord(types.NewBatch(token, 2, sdk.OneUint(), sdk.ZeroUint()))}, queryResult)
+
+	// Error if page is not positive
+	params.Page = 0
+	req.Data = types.ModuleCdc.MustMarshalJSON(params)
+	res, err = querier(ctx, []string{keeper.QueryBatchHistory, token}, req)
+	require.Error(t, err)
+	require.Nil(t, res)
+
+	// Error if limit exceeds the maximum
+	params.Page = 1
+	params.Limit = types.MaxBatchHistoryLimit + 1
+	req.Data = types.ModuleCdc.MustMarshalJSON(params)
+	res, err = querier(ctx, []string{keeper.QueryBatchHistory, token}, req)
+	require.Error(t, err)
+	require.Nil(t, res)
+}
+
+func TestQueryHolderSnapshot(t *testing.T) {
+	app, ctx := createTestApp(false)
+	querier := keeper.NewQuerier(app.BondsKeeper)
+	req := abci.RequestQuery{}
+	var queryResult types.HolderSnapshot
+
+	// Initially error since no snapshot
+	res, err := querier(ctx, []string{keeper.QueryHolderSnapshot, token, buyerAddress.String()}, req)
+	require.Error(t, err)
+	require.Nil(t, res)
+
+	// Add snapshot with a holder
+	snapshot := types.NewSettlementSnapshot(token, 50)
+	holder := types.NewHolderSnapshot(buyerAddress, sdk.NewInt(10))
+	snapshot.TotalBalance = holder.Balance
+	snapshot.Holders = []types.HolderSnapshot{holder}
+	app.BondsKeeper.SetSettlementSnapshot(ctx, snapshot)
+
+	// No error and holder snapshot returned
+	res, err = querier(ctx, []string{keeper.QueryHolderSnapshot, token, buyerAddress.String()}, req)
+	require.NoError(t, err)
+	require.NotNil(t, res)
+	types.ModuleCdc.MustUnmarshalJSON(res, &queryResult)
+	require.Equal(t, holder, queryResult)
+}
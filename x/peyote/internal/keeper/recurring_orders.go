@@ -0,0 +1,410 @@
+package keeper
+
+import (
+	"encoding/binary"
+	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/warmage-sports/peyote/x/peyote/internal/types"
+)
+
+func (k Keeper) GetRecurringOrder(ctx sdk.Context, id uint64) (order types.RecurringOrder, found bool) {
+	store := ctx.KVStore(k.storeKey)
+	if !store.Has(types.GetRecurringOrderKey(id)) {
+		return types.RecurringOrder{}, false
+	}
+
+	bz := store.Get(types.GetRecurringOrderKey(id))
+	k.cdc.MustUnmarshalBinaryBare(bz, &order)
+
+	return order, true
+}
+
+func (k Keeper) MustGetRecurringOrder(ctx sdk.Context, id uint64) types.RecurringOrder {
+	order, found := k.GetRecurringOrder(ctx, id)
+	if !found {
+		panic(fmt.Sprintf("recurring order %d not found\n", id))
+	}
+	return order
+}
+
+func (k Keeper) MustGetRecurringOrderByKey(ctx sdk.Context, key []byte) types.RecurringOrder {
+	store := ctx.KVStore(k.storeKey)
+	if !store.Has(key) {
+		panic("recurring order not found")
+	}
+
+	bz := store.Get(key)
+	var order types.RecurringOrder
+	k.cdc.MustUnmarshalBinaryBare(bz, &order)
+
+	return order
+}
+
+func (k Keeper) GetLastRecurringOrderId(ctx sdk.Context) uint64 {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(types.LastRecurringOrderIdKey)
+	if bz == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint64(bz)
+}
+
+func (k Keeper) SetLastRecurringOrderId(ctx sdk.Context, id uint64) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(types.LastRecurringOrderIdKey, sdk.Uint64ToBigEndian(id))
+}
+
+// SetRecurringOrder stores the recurring order and indexes it by owner, by
+// bond and (if it has one) by end height. If the order is new, the bond's
+// recurring order count is incremented. The order's ID is expected to be
+// already assigned.
+func (k Keeper) SetRecurringOrder(ctx sdk.Context, order types.RecurringOrder) {
+	store := ctx.KVStore(k.storeKey)
+	if !store.Has(types.GetRecurringOrderKey(order.Id)) {
+		k.setBondRecurringOrderCount(ctx, order.BondToken,
+			k.GetBondRecurringOrderCount(ctx, order.BondToken)+1)
+	}
+
+	idBz := sdk.Uint64ToBigEndian(order.Id)
+	store.Set(types.GetRecurringOrderKey(order.Id), k.cdc.MustMarshalBinaryBare(order))
+	store.Set(types.GetRecurringOrderOwnerKey(order), idBz)
+	store.Set(types.GetRecurringOrderBondKey(order), idBz)
+	if order.HasEndHeight() {
+		store.Set(types.GetRecurringOrderEndKey(order), idBz)
+	}
+}
+
+// AddRecurringOrder assigns the next recurring order ID to the order and
+// stores it. The budget is expected to have already been sent to the batches
+// intermediary account.
+func (k Keeper) AddRecurringOrder(ctx sdk.Context, order types.RecurringOrder) types.RecurringOrder {
+	order.Id = k.GetLastRecurringOrderId(ctx) + 1
+	k.SetLastRecurringOrderId(ctx, order.Id)
+	k.SetRecurringOrder(ctx, order)
+	k.ScheduleBatch(ctx, order.BondToken)
+
+	logger := k.Logger(ctx)
+	logger.Info(fmt.Sprintf("added recurring %s order %d for %s with budget %s from %s",
+		order.OrderType, order.Id, order.Amount.String(), order.Budget.String(),
+		order.Address.String()))
+
+	return order
+}
+
+// RemoveRecurringOrder removes the recurring order from the store and from all
+// of its indexes, and decrements the bond's recurring order count.
+func (k Keeper) RemoveRecurringOrder(ctx sdk.Context, order types.RecurringOrder) {
+	store := ctx.KVStore(k.storeKey)
+	if store.Has(types.GetRecurringOrderKey(order.Id)) {
+		k.setBondRecurringOrderCount(ctx, order.BondToken,
+			k.GetBondRecurringOrderCount(ctx, order.BondToken)-1)
+	}
+
+	store.Delete(types.GetRecurringOrderKey(order.Id))
+	store.Delete(types.GetRecurringOrderOwnerKey(order))
+	store.Delete(types.GetRecurringOrderBondKey(order))
+	if order.HasEndHeight() {
+		store.Delete(types.GetRecurringOrderEndKey(order))
+	}
+}
+
+func (k Keeper) GetRecurringOrdersIterator(ctx sdk.Context) sdk.Iterator {
+	store := ctx.KVStore(k.storeKey)
+	return sdk.KVStorePrefixIterator(store, types.RecurringOrdersKeyPrefix)
+}
+
+// GetRecurringOrdersByOwner returns the owner's recurring orders by ID.
+func (k Keeper) GetRecurringOrdersByOwner(ctx sdk.Context, owner sdk.AccAddress) []types.RecurringOrder {
+	return k.getRecurringOrdersByIndex(ctx, types.GetRecurringOrderOwnerPrefixKey(owner))
+}
+
+// GetBondRecurringOrders returns the bond's recurring orders by ID.
+func (k Keeper) GetBondRecurringOrders(ctx sdk.Context, token string) []types.RecurringOrder {
+	return k.getRecurringOrdersByIndex(ctx, types.GetRecurringOrderBondPrefixKey(token))
+}
+
+// GetBondRecurringOrderCount returns the number of the bond's recurring orders,
+// which is kept up to date as orders are stored and removed so that the
+// bond's recurring orders do not have to be visited to count them.
+func (k Keeper) GetBondRecurringOrderCount(ctx sdk.Context, token string) uint64 {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(types.GetRecurringOrderCountKey(token))
+	if bz == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint64(bz)
+}
+
+func (k Keeper) setBondRecurringOrderCount(ctx sdk.Context, token string, count uint64) {
+	store := ctx.KVStore(k.storeKey)
+	if count == 0 {
+		store.Delete(types.GetRecurringOrderCountKey(token))
+	} else {
+		store.Set(types.GetRecurringOrderCountKey(token), sdk.Uint64ToBigEndian(count))
+	}
+}
+
+func (k Keeper) getRecurringOrdersByIndex(ctx sdk.Context, prefix []byte) (orders []types.RecurringOrder) {
+	store := ctx.KVStore(k.storeKey)
+
+	var ids []uint64
+	iterator := sdk.KVStorePrefixIterator(store, prefix)
+	for ; iterator.Valid(); iterator.Next() {
+		ids = append(ids, binary.BigEndian.Uint64(iterator.Value()))
+	}
+	iterator.Close()
+
+	for _, id := range ids {
+		orders = append(orders, k.MustGetRecurringOrder(ctx, id))
+	}
+	return orders
+}
+
+// PlaceRecurringOrders adds an order to the bond's batch for each of the
+// bond's recurring orders that is due, i.e. that has skipped interval-1 of the
+// bond's batches since its last order, in order of ID. An order that cannot be
+// added to the batch (e.g. if its volume does not fit in the batch, or if the
+// order would make another order unfulfillable) is skipped, a
+// recurring_order_skip event is emitted, and the order is retried in the
+// bond's next batch. Once the batch is full, no more orders are placed, and the
+// remaining recurring orders are left as they are until the bond's next batch.
+// A recurring order ends once its remaining budget no longer covers another
+// order.
+func (k Keeper) PlaceRecurringOrders(ctx sdk.Context, token string) {
+	for _, order := range k.GetBondRecurringOrders(ctx, token) {
+		if k.CheckBatchHasRoom(ctx, token, sdk.ZeroInt()) != nil {
+			return
+		}
+
+		// No more orders are placed once the end height is reached
+		if order.HasEndHeight() && ctx.BlockHeight() >= order.EndHeight {
+			continue
+		}
+
+		// Retry ending an order that could not be refunded when its budget
+		// was spent
+		if !order.CanPlaceOrder() {
+			k.endRecurringOrder(ctx, order, "budget spent")
+			continue
+		}
+
+		if order.BatchesToSkip > 0 {
+			order.BatchesToSkip--
+			k.SetRecurringOrder(ctx, order)
+			continue
+		}
+
+		// An order that cannot be placed is retried in the next batch, so the
+		// recurring order is left as it is
+		err := performInCacheContext(ctx, func(ctx sdk.Context) error {
+			return k.placeRecurringOrder(ctx, order)
+		})
+		if err != nil {
+			k.Logger(ctx).Debug(fmt.Sprintf("did not place recurring %s order %d: %s",
+				order.OrderType, order.Id, err.Error()))
+			ctx.EventManager().EmitEvent(sdk.NewEvent(
+				types.EventTypeRecurringOrderSkip,
+				sdk.NewAttribute(types.AttributeKeyBond, token),
+				sdk.NewAttribute(types.AttributeKeyRecurringOrderId, fmt.Sprint(order.Id)),
+				sdk.NewAttribute(types.AttributeKeyOrderType, order.OrderType),
+				sdk.NewAttribute(types.AttributeKeyAddress, order.Address.String()),
+				sdk.NewAttribute(sdk.AttributeKeyAmount, order.Amount.String()),
+				sdk.NewAttribute(types.AttributeKeySkipReason, err.Error()),
+			))
+			continue
+		}
+
+		order.BatchesToSkip = order.Interval.Uint64() - 1
+		order.Budget = order.Budget.Sub(order.Amount)
+		order.OrdersPlaced++
+		if order.CanPlaceOrder() {
+			k.SetRecurringOrder(ctx, order)
+		} else {
+			k.endRecurringOrder(ctx, order, "budget spent")
+		}
+	}
+}
+
+// placeRecurringOrder adds an order for the recurring order's amount to the
+// bond's batch, subject to the same checks as a limit order being matched.
+// The order is paid for from the recurring order's escrowed budget.
+func (k Keeper) placeRecurringOrder(ctx sdk.Context, order types.RecurringOrder) error {
+	token := order.BondToken
+	bond := k.MustGetBond(ctx, token)
+
+	var id uint64
+	var amount sdk.Coin
+	if order.IsBuy() {
+		// Check current state is HATCH/OPEN and spend denoms match reserve
+		if bond.State != types.OpenState && bond.State != types.HatchState {
+			return sdkerrors.Wrap(types.ErrInvalidStateForAction, bond.State)
+		} else if !bond.ReserveDenomsEqualTo(order.Amount) {
+			return sdkerrors.Wrap(types.ErrReserveDenomsMismatch, order.Amount.String())
+		}
+
+		// Get the amount of bond tokens that the spend can buy (including fees)
+		buyAmount, err := k.GetSpendBuyAmount(ctx, token, order.Address, order.Amount)
+		if err != nil {
+			return err
+		} else if buyAmount.IsZero() {
+			return sdkerrors.Wrap(types.ErrSpendTooSmallToBuyAnyTokens, order.Amount.String())
+		}
+		amount = sdk.NewCoin(token, buyAmount)
+
+		// Check that the buy fits in the batch (e.g. its maximum volume)
+		err = k.CheckBatchHasRoom(ctx, token, buyAmount)
+		if err != nil {
+			return err
+		}
+
+		// Get prices after buy (also checks that the buy is fulfillable)
+		bo := types.NewSpendBuyOrder(order.Address, amount, order.Amount)
+		buyPrices, sellPrices, err := k.GetUpdatedBatchPricesAfterBuy(ctx, token, bo)
+		if err != nil {
+			return err
+		}
+
+		// Check that all other buys are still fulfillable
+		for _, other := range k.GetBatchBuyOrders(ctx, token) {
+			if !other.IsCancelled() {
+				err = k.CheckIfBuyOrderFulfillableAtPrice(ctx, token, other, buyPrices)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		id = k.AddBuyOrder(ctx, token, bo, buyPrices, sellPrices).Id
+	} else {
+		// Check sells allowed, current state is OPEN and order limits
+		if !bond.AllowSells {
+			return sdkerrors.Wrap(types.ErrBondDoesNotAllowSelling, token)
+		} else if bond.State != types.OpenState {
+			return sdkerrors.Wrap(types.ErrInvalidStateForAction, bond.State)
+		} else if bond.AnyOrderQuantityLimitsExceeded(order.Amount) {
+			return sdkerrors.Wrap(types.ErrOrderQuantityLimitExceeded, order.Amount.String())
+		}
+		amount = order.Amount[0]
+
+		// Check that the sell fits in the batch (e.g. its maximum volume)
+		err := k.CheckBatchHasRoom(ctx, token, amount.Amount)
+		if err != nil {
+			return err
+		}
+
+		// Get prices after sell
+		so := types.NewSellOrder(order.Address, amount, nil)
+		buyPrices, sellPrices, err := k.GetUpdatedBatchPricesAfterSell(ctx, token, so)
+		if err != nil {
+			return err
+		}
+
+		// Check that all other sells are still fulfillable
+		for _, other := range k.GetBatchSellOrders(ctx, token) {
+			if !other.IsCancelled() {
+				err = k.CheckIfSellOrderFulfillableAtPrice(ctx, token, other, sellPrices)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		// Burn escrowed bond tokens, as is done for sells in MsgSell
+		err = k.SupplyKeeper.SendCoinsFromModuleToModule(ctx,
+			types.BatchesIntermediaryAccount, types.BondsMintBurnAccount, order.Amount)
+		if err != nil {
+			return err
+		}
+		err = k.SupplyKeeper.BurnCoins(ctx, types.BondsMintBurnAccount, order.Amount)
+		if err != nil {
+			return err
+		}
+
+		id = k.AddSellOrder(ctx, token, so, buyPrices, sellPrices).Id
+	}
+
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		types.EventTypeRecurringOrderPlace,
+		sdk.NewAttribute(types.AttributeKeyBond, token),
+		sdk.NewAttribute(types.AttributeKeyRecurringOrderId, fmt.Sprint(order.Id)),
+		sdk.NewAttribute(types.AttributeKeyOrderId, fmt.Sprint(id)),
+		sdk.NewAttribute(types.AttributeKeyOrderType, order.OrderType),
+		sdk.NewAttribute(types.AttributeKeyAddress, order.Address.String()),
+		sdk.NewAttribute(sdk.AttributeKeyAmount, amount.String()),
+	))
+	return nil
+}
+
+// CancelRecurringOrder removes the owner's recurring order and refunds its
+// remaining budget. Any orders that it already placed are not affected.
+func (k Keeper) CancelRecurringOrder(ctx sdk.Context, owner sdk.AccAddress, id uint64) (types.RecurringOrder, error) {
+	order, found := k.GetRecurringOrder(ctx, id)
+	if !found {
+		return types.RecurringOrder{}, sdkerrors.Wrapf(types.ErrRecurringOrderNotFound, "%d", id)
+	} else if !order.Address.Equals(owner) {
+		return types.RecurringOrder{}, sdkerrors.Wrapf(types.ErrOrderNotOwnedBySender,
+			"recurring order %d", id)
+	}
+
+	k.RemoveRecurringOrder(ctx, order)
+	err := k.SupplyKeeper.SendCoinsFromModuleToAccount(ctx,
+		types.BatchesIntermediaryAccount, order.Address, order.Budget)
+	if err != nil {
+		return types.RecurringOrder{}, err
+	}
+
+	k.Logger(ctx).Info(fmt.Sprintf("cancelled recurring %s order %d from %s",
+		order.OrderType, order.Id, order.Address.String()))
+
+	return order, nil
+}
+
+// EndRecurringOrdersAtEndHeight ends any recurring orders with an end height
+// that has been reached and refunds their remaining budgets to the owners.
+func (k Keeper) EndRecurringOrdersAtEndHeight(ctx sdk.Context) {
+	store := ctx.KVStore(k.storeKey)
+	end := sdk.PrefixEndBytes(types.GetRecurringOrderEndPrefixKey(ctx.BlockHeight()))
+
+	var ids []uint64
+	iterator := store.Iterator(types.RecurringOrderEndsKeyPrefix, end)
+	for ; iterator.Valid(); iterator.Next() {
+		ids = append(ids, binary.BigEndian.Uint64(iterator.Value()))
+	}
+	iterator.Close()
+
+	for _, id := range ids {
+		order := k.MustGetRecurringOrder(ctx, id)
+		k.endRecurringOrder(ctx, order, fmt.Sprintf("end height %d reached", order.EndHeight))
+	}
+}
+
+// endRecurringOrder removes the recurring order and refunds its remaining
+// budget to the owner. If the refund fails, the order is kept so that the
+// refund is retried in a later block.
+func (k Keeper) endRecurringOrder(ctx sdk.Context, order types.RecurringOrder, reason string) {
+	err := performInCacheContext(ctx, func(ctx sdk.Context) error {
+		k.RemoveRecurringOrder(ctx, order)
+		return k.SupplyKeeper.SendCoinsFromModuleToAccount(ctx,
+			types.BatchesIntermediaryAccount, order.Address, order.Budget)
+	})
+	if err != nil {
+		k.Logger(ctx).Error(fmt.Sprintf("could not refund recurring %s order %d: %s",
+			order.OrderType, order.Id, err.Error()))
+		k.SetRecurringOrder(ctx, order)
+		return
+	}
+
+	k.Logger(ctx).Info(fmt.Sprintf("ended recurring %s order %d from %s: %s",
+		order.OrderType, order.Id, order.Address.String(), reason))
+
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		types.EventTypeRecurringOrderEnd,
+		sdk.NewAttribute(types.AttributeKeyBond, order.BondToken),
+		sdk.NewAttribute(types.AttributeKeyRecurringOrderId, fmt.Sprint(order.Id)),
+		sdk.NewAttribute(types.AttributeKeyOrderType, order.OrderType),
+		sdk.NewAttribute(types.AttributeKeyAddress, order.Address.String()),
+		sdk.NewAttribute(types.AttributeKeyReturnedToAddress, order.Budget.String()),
+		sdk.NewAttribute(types.AttributeKeyCancelReason, reason),
+	))
+}
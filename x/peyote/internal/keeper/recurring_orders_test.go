@@ -0,0 +1,277 @@
+package keeper_test
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+	"github.com/warmage-sports/peyote/x/peyote/internal/types"
+)
+
+func newTestRecurringBuy(spend, budget int64, interval uint64, endHeight int64) types.RecurringOrder {
+	return types.NewRecurringOrder(types.AttributeValueBuyOrder, buyerAddress, token,
+		sdk.NewCoins(sdk.NewInt64Coin(reserveToken, spend)),
+		sdk.NewCoins(sdk.NewInt64Coin(reserveToken, budget)),
+		sdk.NewUint(interval), endHeight)
+}
+
+func newTestRecurringSell(amount, budget int64, interval uint64, endHeight int64) types.RecurringOrder {
+	return types.NewRecurringOrder(types.AttributeValueSellOrder, sellerAddress, token,
+		sdk.NewCoins(sdk.NewInt64Coin(token, amount)),
+		sdk.NewCoins(sdk.NewInt64Coin(token, budget)),
+		sdk.NewUint(interval), endHeight)
+}
+
+func TestRecurringOrderAddGetRemove(t *testing.T) {
+	app, ctx := createTestApp(false)
+	app.BondsKeeper.SetBond(ctx, token, getValidBond())
+	app.BondsKeeper.SetBatch(ctx, token, getValidBatch())
+
+	_, found := app.BondsKeeper.GetRecurringOrder(ctx, 1)
+	require.False(t, found)
+	require.Equal(t, uint64(0), app.BondsKeeper.GetLastRecurringOrderId(ctx))
+
+	// IDs are assigned incrementally
+	order1 := app.BondsKeeper.AddRecurringOrder(ctx, newTestRecurringBuy(100, 1000, 2, 100))
+	order2 := app.BondsKeeper.AddRecurringOrder(ctx, newTestRecurringSell(10, 100, 1, 0))
+	require.Equal(t, uint64(1), order1.Id)
+	require.Equal(t, uint64(2), order2.Id)
+	require.Equal(t, uint64(2), app.BondsKeeper.GetLastRecurringOrderId(ctx))
+
+	returned, found := app.BondsKeeper.GetRecurringOrder(ctx, order1.Id)
+	require.True(t, found)
+	require.Equal(t, order1, returned)
+
+	// Orders are indexed by owner and by bond
+	require.Equal(t, []types.RecurringOrder{order1},
+		app.BondsKeeper.GetRecurringOrdersByOwner(ctx, buyerAddress))
+	require.Equal(t, []types.RecurringOrder{order2},
+		app.BondsKeeper.GetRecurringOrdersByOwner(ctx, sellerAddress))
+	require.Len(t, app.BondsKeeper.GetBondRecurringOrders(ctx, token), 2)
+	require.True(t, app.BondsKeeper.HasPendingOrders(ctx, token))
+
+	// Orders are counted by bond, and updating an order does not count it again
+	require.Equal(t, uint64(2), app.BondsKeeper.GetBondRecurringOrderCount(ctx, token))
+	app.BondsKeeper.SetRecurringOrder(ctx, order1)
+	require.Equal(t, uint64(2), app.BondsKeeper.GetBondRecurringOrderCount(ctx, token))
+	require.Equal(t, uint64(0), app.BondsKeeper.GetBondRecurringOrderCount(ctx, token2))
+
+	// Removed orders are removed from all indexes
+	app.BondsKeeper.RemoveRecurringOrder(ctx, order1)
+	app.BondsKeeper.RemoveRecurringOrder(ctx, order2)
+	_, found = app.BondsKeeper.GetRecurringOrder(ctx, order1.Id)
+	require.False(t, found)
+	require.Len(t, app.BondsKeeper.GetRecurringOrdersByOwner(ctx, buyerAddress), 0)
+	require.Len(t, app.BondsKeeper.GetBondRecurringOrders(ctx, token), 0)
+	require.False(t, app.BondsKeeper.HasPendingOrders(ctx, token))
+	require.Equal(t, uint64(0), app.BondsKeeper.GetBondRecurringOrderCount(ctx, token))
+}
+
+func TestPlaceRecurringBuy(t *testing.T) {
+	app, ctx := createTestApp(false)
+
+	// Create bond and batch (with no fees for simpler test)
+	bond := getValidBond()
+	bond.TxFeePercentage = sdk.ZeroDec()
+	bond.ExitFeePercentage = sdk.ZeroDec()
+	app.BondsKeeper.SetBond(ctx, bond.Token, bond)
+	app.BondsKeeper.SetBatch(ctx, bond.Token, getValidBatch())
+
+	// Add recurring buy with escrowed budget in module account
+	order := app.BondsKeeper.AddRecurringOrder(ctx, newTestRecurringBuy(1000, 1500, 1, 0))
+	moduleAcc := app.SupplyKeeper.GetModuleAccount(ctx, types.BatchesIntermediaryAccount)
+	_, err := app.BankKeeper.AddCoins(ctx, moduleAcc.GetAddress(), order.Budget)
+	require.NoError(t, err)
+
+	app.BondsKeeper.PlaceRecurringOrders(ctx, token)
+
+	// Spend-limited buy added to batch, paid for from the budget
+	batch := app.BondsKeeper.MustGetBatch(ctx, token)
+	require.Len(t, batch.Buys, 1)
+	require.Equal(t, order.Amount, batch.Buys[0].MaxPrices)
+	require.True(t, batch.Buys[0].Spend)
+
+	// Remaining budget no longer covers an order, so it is refunded
+	_, found := app.BondsKeeper.GetRecurringOrder(ctx, order.Id)
+	require.False(t, found)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 500)),
+		app.BankKeeper.GetCoins(ctx, buyerAddress))
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 1000)),
+		app.BankKeeper.GetCoins(ctx, moduleAcc.GetAddress()))
+
+	var eventTypes []string
+	for _, event := range ctx.EventManager().Events() {
+		eventTypes = append(eventTypes, event.Type)
+	}
+	require.Contains(t, eventTypes, types.EventTypeRecurringOrderPlace)
+	require.Equal(t, types.EventTypeRecurringOrderEnd, eventTypes[len(eventTypes)-1])
+}
+
+func TestPlaceRecurringOrdersStopsOnceBatchIsFull(t *testing.T) {
+	app, ctx := createTestApp(false)
+
+	// Create bond (with a maximum of one order per batch) and batch
+	bond := getValidBond()
+	bond.TxFeePercentage = sdk.ZeroDec()
+	bond.ExitFeePercentage = sdk.ZeroDec()
+	bond.MaxBatchOrders = sdk.OneUint()
+	app.BondsKeeper.SetBond(ctx, bond.Token, bond)
+	app.BondsKeeper.SetBatch(ctx, bond.Token, getValidBatch())
+
+	// Add recurring buys with escrowed budgets in module account
+	order1 := app.BondsKeeper.AddRecurringOrder(ctx, newTestRecurringBuy(1000, 3000, 1, 0))
+	order2 := app.BondsKeeper.AddRecurringOrder(ctx, newTestRecurringBuy(1000, 3000, 2, 0))
+	moduleAcc := app.SupplyKeeper.GetModuleAccount(ctx, types.BatchesIntermediaryAccount)
+	_, err := app.BankKeeper.AddCoins(ctx, moduleAcc.GetAddress(), order1.Budget.Add(order2.Budget...))
+	require.NoError(t, err)
+
+	app.BondsKeeper.PlaceRecurringOrders(ctx, token)
+
+	// Only the first buy is placed, and the second recurring order is left
+	// as it is until the next batch
+	require.Len(t, app.BondsKeeper.MustGetBatch(ctx, token).Buys, 1)
+	require.Equal(t, uint64(1), app.BondsKeeper.MustGetRecurringOrder(ctx, order1.Id).OrdersPlaced)
+	require.Equal(t, order2, app.BondsKeeper.MustGetRecurringOrder(ctx, order2.Id))
+}
+
+func TestPlaceRecurringOrdersRetriesSkippedOrderInNextBatch(t *testing.T) {
+	app, ctx := createTestApp(false)
+
+	// Create bond that does not accept buys yet, and batch
+	bond := getValidBond()
+	bond.TxFeePercentage = sdk.ZeroDec()
+	bond.ExitFeePercentage = sdk.ZeroDec()
+	bond.State = types.SettleState
+	app.BondsKeeper.SetBond(ctx, bond.Token, bond)
+	app.BondsKeeper.SetBatch(ctx, bond.Token, getValidBatch())
+
+	// Recurring buy every 2nd batch, with escrowed budget in module account
+	order := app.BondsKeeper.AddRecurringOrder(ctx, newTestRecurringBuy(1000, 3000, 2, 0))
+	moduleAcc := app.SupplyKeeper.GetModuleAccount(ctx, types.BatchesIntermediaryAccount)
+	_, err := app.BankKeeper.AddCoins(ctx, moduleAcc.GetAddress(), order.Budget)
+	require.NoError(t, err)
+
+	// The buy cannot be placed, so it is skipped and the order is left as is
+	ctx = ctx.WithEventManager(sdk.NewEventManager())
+	app.BondsKeeper.PlaceRecurringOrders(ctx, token)
+	require.Len(t, app.BondsKeeper.MustGetBatch(ctx, token).Buys, 0)
+	require.Equal(t, order, app.BondsKeeper.MustGetRecurringOrder(ctx, order.Id))
+	events := ctx.EventManager().Events()
+	require.Len(t, events, 1)
+	require.Equal(t, types.EventTypeRecurringOrderSkip, events[0].Type)
+
+	// The buy is placed in the next batch, rather than after another interval
+	app.BondsKeeper.SetBondState(ctx, token, types.OpenState)
+	app.BondsKeeper.PlaceRecurringOrders(ctx, token)
+	require.Len(t, app.BondsKeeper.MustGetBatch(ctx, token).Buys, 1)
+	order = app.BondsKeeper.MustGetRecurringOrder(ctx, order.Id)
+	require.Equal(t, uint64(1), order.OrdersPlaced)
+	require.Equal(t, uint64(1), order.BatchesToSkip)
+}
+
+func TestPlaceRecurringSellEveryInterval(t *testing.T) {
+	app, ctx := createTestApp(false)
+
+	// Create bond and batch (with no fees for simpler test)
+	bond := getValidBond()
+	bond.TxFeePercentage = sdk.ZeroDec()
+	bond.ExitFeePercentage = sdk.ZeroDec()
+	app.BondsKeeper.SetBond(ctx, bond.Token, bond)
+	app.BondsKeeper.SetBatch(ctx, bond.Token, getValidBatch())
+
+	// Simulate supply of 10 tokens (held in escrow) and reserve of 5000
+	escrowed := sdk.NewCoins(sdk.NewInt64Coin(token, 10))
+	reserve := sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 5000))
+	require.NoError(t, app.SupplyKeeper.MintCoins(ctx, types.BondsMintBurnAccount, escrowed))
+	require.NoError(t, app.SupplyKeeper.SendCoinsFromModuleToModule(ctx,
+		types.BondsMintBurnAccount, types.BatchesIntermediaryAccount, escrowed))
+	require.NoError(t, app.SupplyKeeper.MintCoins(ctx, types.BondsMintBurnAccount, reserve))
+	require.NoError(t, app.BondsKeeper.DepositReserveFromModule(
+		ctx, bond.Token, types.BondsMintBurnAccount, reserve))
+	app.BondsKeeper.SetCurrentSupply(ctx, bond.Token, escrowed[0])
+
+	// Recurring sell of 4 tokens every 2nd batch, with a budget of 10 tokens
+	order := app.BondsKeeper.AddRecurringOrder(ctx, newTestRecurringSell(4, 10, 2, 0))
+
+	// First sell is placed immediately and its tokens are burned
+	app.BondsKeeper.PlaceRecurringOrders(ctx, token)
+	require.Len(t, app.BondsKeeper.MustGetBatch(ctx, token).Sells, 1)
+	order = app.BondsKeeper.MustGetRecurringOrder(ctx, order.Id)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin(token, 6)), order.Budget)
+	require.Equal(t, uint64(1), order.BatchesToSkip)
+	require.Equal(t, uint64(1), order.OrdersPlaced)
+	require.Equal(t, int64(6), app.SupplyKeeper.GetSupply(ctx).GetTotal().AmountOf(token).Int64())
+
+	// Next batch is skipped
+	app.BondsKeeper.PlaceRecurringOrders(ctx, token)
+	require.Len(t, app.BondsKeeper.MustGetBatch(ctx, token).Sells, 1)
+	order = app.BondsKeeper.MustGetRecurringOrder(ctx, order.Id)
+	require.Equal(t, uint64(0), order.BatchesToSkip)
+	require.Equal(t, uint64(1), order.OrdersPlaced)
+
+	// Second sell is placed, after which the remaining 2 tokens are refunded
+	app.BondsKeeper.PlaceRecurringOrders(ctx, token)
+	require.Len(t, app.BondsKeeper.MustGetBatch(ctx, token).Sells, 2)
+	_, found := app.BondsKeeper.GetRecurringOrder(ctx, order.Id)
+	require.False(t, found)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin(token, 2)),
+		app.BankKeeper.GetCoins(ctx, sellerAddress))
+	require.Equal(t, int64(2), app.SupplyKeeper.GetSupply(ctx).GetTotal().AmountOf(token).Int64())
+}
+
+func TestEndRecurringOrdersAtEndHeight(t *testing.T) {
+	app, ctx := createTestApp(false)
+	app.BondsKeeper.SetBond(ctx, token, getValidBond())
+	app.BondsKeeper.SetBatch(ctx, token, getValidBatch())
+
+	// Add recurring buys ending at heights 5 and 6, with escrow in module account
+	ending := app.BondsKeeper.AddRecurringOrder(ctx, newTestRecurringBuy(100, 1000, 1, 5))
+	notEnding := app.BondsKeeper.AddRecurringOrder(ctx, newTestRecurringBuy(100, 1000, 1, 6))
+	noEnd := app.BondsKeeper.AddRecurringOrder(ctx, newTestRecurringBuy(100, 1000, 1, 0))
+	moduleAcc := app.SupplyKeeper.GetModuleAccount(ctx, types.BatchesIntermediaryAccount)
+	_, err := app.BankKeeper.AddCoins(ctx, moduleAcc.GetAddress(),
+		ending.Budget.Add(notEnding.Budget...).Add(noEnd.Budget...))
+	require.NoError(t, err)
+
+	// Nothing ends before height 5
+	ctx = ctx.WithBlockHeight(4)
+	app.BondsKeeper.EndRecurringOrdersAtEndHeight(ctx)
+	require.Len(t, app.BondsKeeper.GetBondRecurringOrders(ctx, token), 3)
+
+	// First order ends at height 5 and its budget is refunded
+	ctx = ctx.WithBlockHeight(5)
+	app.BondsKeeper.EndRecurringOrdersAtEndHeight(ctx)
+	_, found := app.BondsKeeper.GetRecurringOrder(ctx, ending.Id)
+	require.False(t, found)
+	_, found = app.BondsKeeper.GetRecurringOrder(ctx, notEnding.Id)
+	require.True(t, found)
+	require.Equal(t, ending.Budget, app.BankKeeper.GetCoins(ctx, buyerAddress))
+
+	// Orders without an end height never end this way
+	ctx = ctx.WithBlockHeight(1000)
+	app.BondsKeeper.EndRecurringOrdersAtEndHeight(ctx)
+	require.Equal(t, []types.RecurringOrder{noEnd}, app.BondsKeeper.GetBondRecurringOrders(ctx, token))
+}
+
+func TestCancelRecurringOrder(t *testing.T) {
+	app, ctx := createTestApp(false)
+	app.BondsKeeper.SetBond(ctx, token, getValidBond())
+	app.BondsKeeper.SetBatch(ctx, token, getValidBatch())
+
+	order := app.BondsKeeper.AddRecurringOrder(ctx, newTestRecurringBuy(100, 1000, 1, 0))
+	moduleAcc := app.SupplyKeeper.GetModuleAccount(ctx, types.BatchesIntermediaryAccount)
+	_, err := app.BankKeeper.AddCoins(ctx, moduleAcc.GetAddress(), order.Budget)
+	require.NoError(t, err)
+
+	// Only the owner can cancel the order
+	_, err = app.BondsKeeper.CancelRecurringOrder(ctx, sellerAddress, order.Id)
+	require.Error(t, err)
+	_, err = app.BondsKeeper.CancelRecurringOrder(ctx, buyerAddress, order.Id+1)
+	require.Error(t, err)
+
+	_, err = app.BondsKeeper.CancelRecurringOrder(ctx, buyerAddress, order.Id)
+	require.NoError(t, err)
+	_, found := app.BondsKeeper.GetRecurringOrder(ctx, order.Id)
+	require.False(t, found)
+	require.Equal(t, order.Budget, app.BankKeeper.GetCoins(ctx, buyerAddress))
+}
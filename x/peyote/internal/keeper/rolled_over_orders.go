@@ -0,0 +1,398 @@
+package keeper
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/warmage-sports/peyote/x/peyote/internal/types"
+)
+
+// CheckBatchHasRoom returns an error if an order for the amount of bond tokens
+// (zero for a swap) does not fit in the bond's current batch, i.e. if the
+// batch already has its maximum number of (non-cancelled) orders, or if the
+// order would take the batch over its maximum volume.
+func (k Keeper) CheckBatchHasRoom(ctx sdk.Context, token string, amount sdk.Int) error {
+	bond := k.MustGetBond(ctx, token)
+	batch := k.MustGetBatchHeader(ctx, token)
+	params := k.GetParams(ctx)
+
+	maxOrders := bond.GetMaxBatchOrders(params.MaxBatchOrders)
+	if batch.OrderCount >= maxOrders {
+		return sdkerrors.Wrapf(types.ErrBatchOrderLimitReached,
+			"batch of %s has %d orders", token, batch.OrderCount)
+	}
+
+	volume := batch.GetVolume().Add(amount)
+	if volume.GT(bond.GetMaxBatchVolume(params.MaxBatchVolume)) {
+		return sdkerrors.Wrapf(types.ErrBatchVolumeLimitReached,
+			"volume of batch of %s would be %s", token, volume)
+	}
+
+	return nil
+}
+
+// CheckBatchCapacity checks that an order for the amount of bond tokens (zero
+// for a swap) can be placed. If the order does not fit in the bond's current
+// batch, true is returned if the bond rolls orders over to its next batch, and
+// an error is returned otherwise. Orders are also rolled over if the bond
+// already has rolled-over orders, so that these are added to a batch first. An
+// error is always returned if the amount alone exceeds the batch's maximum
+// volume, since the order would never fit in a batch.
+func (k Keeper) CheckBatchCapacity(ctx sdk.Context, token string, amount sdk.Int) (rollOver bool, err error) {
+	bond := k.MustGetBond(ctx, token)
+	maxVolume := bond.GetMaxBatchVolume(k.GetParams(ctx).MaxBatchVolume)
+	if amount.GT(maxVolume) {
+		return false, sdkerrors.Wrapf(types.ErrOrderExceedsBatchVolumeLimit,
+			"%s > %s", amount, maxVolume)
+	}
+
+	if bond.RollOverOrders && k.HasRolledOverOrders(ctx, token) {
+		return true, nil
+	}
+
+	err = k.CheckBatchHasRoom(ctx, token, amount)
+	if err != nil && bond.RollOverOrders {
+		return true, nil
+	}
+	return false, err
+}
+
+func (k Keeper) SetRolledOverBuyOrder(ctx sdk.Context, token string, order types.BuyOrder) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(types.GetRolledOverOrderKey(token, order.Id, types.BatchBuyOrderByte),
+		k.cdc.MustMarshalBinaryBare(order))
+}
+
+func (k Keeper) SetRolledOverSellOrder(ctx sdk.Context, token string, order types.SellOrder) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(types.GetRolledOverOrderKey(token, order.Id, types.BatchSellOrderByte),
+		k.cdc.MustMarshalBinaryBare(order))
+}
+
+func (k Keeper) SetRolledOverSwapOrder(ctx sdk.Context, token string, order types.SwapOrder) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(types.GetRolledOverOrderKey(token, order.Id, types.BatchSwapOrderByte),
+		k.cdc.MustMarshalBinaryBare(order))
+}
+
+// SetRolledOverOrders stores the bond's rolled-over orders, which are expected
+// to have been assigned order IDs already.
+func (k Keeper) SetRolledOverOrders(ctx sdk.Context, orders types.RolledOverOrders) {
+	for _, bo := range orders.Buys {
+		k.SetRolledOverBuyOrder(ctx, orders.Token, bo)
+	}
+	for _, so := range orders.Sells {
+		k.SetRolledOverSellOrder(ctx, orders.Token, so)
+	}
+	for _, so := range orders.Swaps {
+		k.SetRolledOverSwapOrder(ctx, orders.Token, so)
+	}
+}
+
+// RollOverBuyOrder assigns the next order ID to the buy order and stores it as
+// a rolled-over order, returning the order with the ID assigned. The order is
+// added to one of the bond's following batches once there is room for it.
+func (k Keeper) RollOverBuyOrder(ctx sdk.Context, token string, bo types.BuyOrder) types.BuyOrder {
+	bo.Id = k.nextOrderId(ctx)
+	k.SetRolledOverBuyOrder(ctx, token, bo)
+	k.ScheduleBatch(ctx, token)
+
+	logger := k.Logger(ctx)
+	logger.Info(fmt.Sprintf("rolled over buy order %d for %s from %s", bo.Id, bo.Amount.String(), bo.Address.String()))
+
+	return bo
+}
+
+// RollOverSellOrder assigns the next order ID to the sell order and stores it
+// as a rolled-over order, returning the order with the ID assigned. The order
+// is added to one of the bond's following batches once there is room for it.
+// The bond tokens being sold are expected to have been sent to the batches
+// intermediary account, and are only burned once the order is added.
+func (k Keeper) RollOverSellOrder(ctx sdk.Context, token string, so types.SellOrder) types.SellOrder {
+	so.Id = k.nextOrderId(ctx)
+	k.SetRolledOverSellOrder(ctx, token, so)
+	k.ScheduleBatch(ctx, token)
+
+	logger := k.Logger(ctx)
+	logger.Info(fmt.Sprintf("rolled over sell order %d for %s from %s", so.Id, so.Amount.String(), so.Address.String()))
+
+	return so
+}
+
+// RollOverSwapOrder assigns the next order ID to the swap order and stores it
+// as a rolled-over order, returning the order with the ID assigned. The order
+// is added to one of the bond's following batches once there is room for it.
+func (k Keeper) RollOverSwapOrder(ctx sdk.Context, token string, so types.SwapOrder) types.SwapOrder {
+	so.Id = k.nextOrderId(ctx)
+	k.SetRolledOverSwapOrder(ctx, token, so)
+	k.ScheduleBatch(ctx, token)
+
+	logger := k.Logger(ctx)
+	logger.Info(fmt.Sprintf("rolled over swap order %d for %s to %s from %s", so.Id, so.Amount.String(), so.ToToken, so.Address.String()))
+
+	return so
+}
+
+// HasRolledOverOrders returns true if the bond has any rolled-over orders
+func (k Keeper) HasRolledOverOrders(ctx sdk.Context, token string) bool {
+	store := ctx.KVStore(k.storeKey)
+	iterator := sdk.KVStorePrefixIterator(store, types.GetRolledOverOrdersPrefixKey(token))
+	defer iterator.Close()
+	return iterator.Valid()
+}
+
+// GetRolledOverOrders returns the bond's rolled-over buys, sells and swaps,
+// each by ascending order ID.
+func (k Keeper) GetRolledOverOrders(ctx sdk.Context, token string) types.RolledOverOrders {
+	orders := types.NewRolledOverOrders(token)
+
+	store := ctx.KVStore(k.storeKey)
+	iterator := sdk.KVStorePrefixIterator(store, types.GetRolledOverOrdersPrefixKey(token))
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		key := iterator.Key()
+		switch key[len(key)-1] {
+		case types.BatchBuyOrderByte:
+			var bo types.BuyOrder
+			k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &bo)
+			orders.Buys = append(orders.Buys, bo)
+		case types.BatchSellOrderByte:
+			var so types.SellOrder
+			k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &so)
+			orders.Sells = append(orders.Sells, so)
+		case types.BatchSwapOrderByte:
+			var so types.SwapOrder
+			k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &so)
+			orders.Swaps = append(orders.Swaps, so)
+		}
+	}
+	return orders
+}
+
+// refundRolledOverSellOrder returns the bond tokens of the rolled-over sell,
+// which were escrowed rather than burned, to the seller
+func (k Keeper) refundRolledOverSellOrder(ctx sdk.Context, so types.SellOrder) error {
+	return k.SupplyKeeper.SendCoinsFromModuleToAccount(ctx,
+		types.BatchesIntermediaryAccount, so.Address, sdk.Coins{so.Amount})
+}
+
+// IsRolledOverOrder returns true if the order with the specified ID is one of
+// the bond's rolled-over orders
+func (k Keeper) IsRolledOverOrder(ctx sdk.Context, token string, id uint64) bool {
+	store := ctx.KVStore(k.storeKey)
+	return store.Has(types.GetRolledOverOrderKey(token, id, types.BatchBuyOrderByte)) ||
+		store.Has(types.GetRolledOverOrderKey(token, id, types.BatchSellOrderByte)) ||
+		store.Has(types.GetRolledOverOrderKey(token, id, types.BatchSwapOrderByte))
+}
+
+// cancelRolledOverOrder cancels the rolled-over order with the specified ID on
+// behalf of the order's owner and refunds it. Since the order is not in the
+// bond's batch, the batch prices are not affected.
+func (k Keeper) cancelRolledOverOrder(ctx sdk.Context, token string, owner sdk.AccAddress, id uint64) (orderType string, err error) {
+	store := ctx.KVStore(k.storeKey)
+
+	var order types.BaseOrder
+	if bz := store.Get(types.GetRolledOverOrderKey(token, id, types.BatchBuyOrderByte)); bz != nil {
+		var bo types.BuyOrder
+		k.cdc.MustUnmarshalBinaryBare(bz, &bo)
+		err = cancelOwnedOrder(&bo.BaseOrder, owner)
+		if err == nil {
+			store.Delete(types.GetRolledOverOrderKey(token, id, types.BatchBuyOrderByte))
+			err = k.refundBuyOrder(ctx, bo)
+		}
+		order, orderType = bo.BaseOrder, types.AttributeValueBuyOrder
+	} else if bz := store.Get(types.GetRolledOverOrderKey(token, id, types.BatchSellOrderByte)); bz != nil {
+		var so types.SellOrder
+		k.cdc.MustUnmarshalBinaryBare(bz, &so)
+		err = cancelOwnedOrder(&so.BaseOrder, owner)
+		if err == nil {
+			store.Delete(types.GetRolledOverOrderKey(token, id, types.BatchSellOrderByte))
+			err = k.refundRolledOverSellOrder(ctx, so)
+		}
+		order, orderType = so.BaseOrder, types.AttributeValueSellOrder
+	} else if bz := store.Get(types.GetRolledOverOrderKey(token, id, types.BatchSwapOrderByte)); bz != nil {
+		var so types.SwapOrder
+		k.cdc.MustUnmarshalBinaryBare(bz, &so)
+		err = cancelOwnedOrder(&so.BaseOrder, owner)
+		if err == nil {
+			store.Delete(types.GetRolledOverOrderKey(token, id, types.BatchSwapOrderByte))
+			err = k.refundSwapOrder(ctx, so)
+		}
+		order, orderType = so.BaseOrder, types.AttributeValueSwapOrder
+	} else {
+		return "", sdkerrors.Wrapf(types.ErrOrderNotFound, "rolled-over order %d of %s", id, token)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	logger := k.Logger(ctx)
+	logger.Info(fmt.Sprintf("cancelled rolled-over %s order %d from %s", orderType, id, owner.String()))
+
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		types.EventTypeOrderCancel,
+		sdk.NewAttribute(types.AttributeKeyBond, token),
+		sdk.NewAttribute(types.AttributeKeyOrderId, fmt.Sprint(order.Id)),
+		sdk.NewAttribute(types.AttributeKeyOrderType, orderType),
+		sdk.NewAttribute(types.AttributeKeyAddress, order.Address.String()),
+		sdk.NewAttribute(types.AttributeKeyCancelReason, order.CancelReason),
+	))
+
+	return orderType, nil
+}
+
+type rolledOverOrder struct {
+	key   []byte
+	value []byte
+}
+
+// getNextRolledOverOrders returns up to limit of the bond's rolled-over orders
+// with the lowest order IDs.
+func (k Keeper) getNextRolledOverOrders(ctx sdk.Context, token string, limit uint64) (orders []rolledOverOrder) {
+	store := ctx.KVStore(k.storeKey)
+	iterator := sdk.KVStorePrefixIterator(store, types.GetRolledOverOrdersPrefixKey(token))
+	defer iterator.Close()
+
+	for ; iterator.Valid() && uint64(len(orders)) < limit; iterator.Next() {
+		orders = append(orders, rolledOverOrder{key: iterator.Key(), value: iterator.Value()})
+	}
+	return orders
+}
+
+// AddRolledOverOrders adds the bond's rolled-over orders to its current batch
+// by ascending order ID, until an order does not fit in the batch. A buy or a
+// sell that cannot be added (e.g. since the buy's max prices would not cover
+// the updated batch prices) is cancelled and refunded instead, as are all of
+// the rolled-over orders if the bond no longer accepts them (e.g. since it has
+// been settled). At most as many orders as there is room for in the batch are
+// handled, so that the work done is bounded by the maximum number of orders.
+// An error is returned if a cancelled order could not be refunded, in which
+// case the bond's accounting is inconsistent and none of the orders are added.
+func (k Keeper) AddRolledOverOrders(ctx sdk.Context, token string) error {
+	return performInCacheContext(ctx, func(ctx sdk.Context) error {
+		return k.addRolledOverOrders(ctx, token)
+	})
+}
+
+func (k Keeper) addRolledOverOrders(ctx sdk.Context, token string) error {
+	bond := k.MustGetBond(ctx, token)
+	batch := k.MustGetBatchHeader(ctx, token)
+
+	maxOrders := bond.GetMaxBatchOrders(k.GetParams(ctx).MaxBatchOrders)
+	if batch.OrderCount >= maxOrders {
+		return nil
+	}
+
+	store := ctx.KVStore(k.storeKey)
+	for _, order := range k.getNextRolledOverOrders(ctx, token, maxOrders-batch.OrderCount) {
+		var amount sdk.Int
+		var add func() error
+		switch order.key[len(order.key)-1] {
+		case types.BatchBuyOrderByte:
+			var bo types.BuyOrder
+			k.cdc.MustUnmarshalBinaryBare(order.value, &bo)
+			amount = bo.Amount.Amount
+			add = func() error { return k.addRolledOverBuyOrder(ctx, token, bond, bo) }
+		case types.BatchSellOrderByte:
+			var so types.SellOrder
+			k.cdc.MustUnmarshalBinaryBare(order.value, &so)
+			amount = so.Amount.Amount
+			add = func() error { return k.addRolledOverSellOrder(ctx, token, bond, so) }
+		case types.BatchSwapOrderByte:
+			var so types.SwapOrder
+			k.cdc.MustUnmarshalBinaryBare(order.value, &so)
+			amount = sdk.ZeroInt()
+			add = func() error { return k.addRolledOverSwapOrder(ctx, token, bond, so) }
+		}
+
+		// Orders that are being cancelled do not need to fit in the batch
+		accepting := bond.State == types.OpenState || bond.State == types.HatchState
+		if accepting && k.CheckBatchHasRoom(ctx, token, amount) != nil {
+			break
+		}
+
+		store.Delete(order.key)
+		err := add()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Cancel orders that became unfulfillable with the new prices
+	_, err := k.CancelUnfulfillableOrders(ctx, token)
+	return err
+}
+
+func (k Keeper) addRolledOverBuyOrder(ctx sdk.Context, token string, bond types.Bond, bo types.BuyOrder) error {
+	err := sdkerrors.Wrap(types.ErrInvalidStateForAction, bond.State)
+	if bond.State == types.OpenState || bond.State == types.HatchState {
+		err = performInCacheContext(ctx, func(ctx sdk.Context) error {
+			buyPrices, sellPrices, err := k.GetUpdatedBatchPricesAfterBuy(ctx, token, bo)
+			if err != nil {
+				return err
+			}
+			k.addBuyOrder(ctx, token, bo, buyPrices, sellPrices)
+			return nil
+		})
+	}
+	if err == nil {
+		return nil
+	}
+
+	// Return reserve to buyer
+	return k.cancelFailedOrder(ctx, token, types.AttributeValueBuyOrder,
+		bo.BaseOrder, err.Error(), func(ctx sdk.Context) error {
+			return k.refundBuyOrder(ctx, bo)
+		})
+}
+
+func (k Keeper) addRolledOverSellOrder(ctx sdk.Context, token string, bond types.Bond, so types.SellOrder) error {
+	err := sdkerrors.Wrap(types.ErrInvalidStateForAction, bond.State)
+	if bond.State == types.OpenState {
+		err = performInCacheContext(ctx, func(ctx sdk.Context) error {
+			buyPrices, sellPrices, err := k.GetUpdatedBatchPricesAfterSell(ctx, token, so)
+			if err != nil {
+				return err
+			}
+
+			// Burn escrowed bond tokens, as is done for sells in MsgSell
+			err = k.SupplyKeeper.SendCoinsFromModuleToModule(ctx,
+				types.BatchesIntermediaryAccount, types.BondsMintBurnAccount, sdk.Coins{so.Amount})
+			if err != nil {
+				return err
+			}
+			err = k.SupplyKeeper.BurnCoins(ctx, types.BondsMintBurnAccount, sdk.Coins{so.Amount})
+			if err != nil {
+				return err
+			}
+
+			k.addSellOrder(ctx, token, so, buyPrices, sellPrices)
+			return nil
+		})
+	}
+	if err == nil {
+		return nil
+	}
+
+	// Return escrowed bond tokens to seller
+	return k.cancelFailedOrder(ctx, token, types.AttributeValueSellOrder,
+		so.BaseOrder, err.Error(), func(ctx sdk.Context) error {
+			return k.refundRolledOverSellOrder(ctx, so)
+		})
+}
+
+func (k Keeper) addRolledOverSwapOrder(ctx sdk.Context, token string, bond types.Bond, so types.SwapOrder) error {
+	if bond.State == types.OpenState {
+		k.addSwapOrder(ctx, token, so)
+		return nil
+	}
+
+	// Return from amount to swapper
+	return k.cancelFailedOrder(ctx, token, types.AttributeValueSwapOrder,
+		so.BaseOrder, sdkerrors.Wrap(types.ErrInvalidStateForAction, bond.State).Error(),
+		func(ctx sdk.Context) error {
+			return k.refundSwapOrder(ctx, so)
+		})
+}
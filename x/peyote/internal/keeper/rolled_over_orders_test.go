@@ -0,0 +1,187 @@
+package keeper_test
+
+import (
+	"errors"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+	"github.com/warmage-sports/peyote/x/peyote/internal/types"
+)
+
+func TestCheckBatchCapacity(t *testing.T) {
+	testCases := []struct {
+		maxBatchOrders uint64
+		maxBatchVolume uint64
+		rollOverOrders bool
+		amount         int64
+		rollOver       bool
+		expectedErr    error
+	}{
+		{0, 0, false, 100, false, nil},                                   // module maximum not reached
+		{2, 0, false, 1, false, nil},                                     // bond maximum not reached
+		{1, 0, false, 1, false, types.ErrBatchOrderLimitReached},         // bond maximum reached
+		{1, 0, true, 1, true, nil},                                       // bond maximum reached, rolled over
+		{0, 15, false, 5, false, nil},                                    // volume limit not exceeded
+		{0, 15, false, 6, false, types.ErrBatchVolumeLimitReached},       // volume limit exceeded
+		{0, 15, true, 6, true, nil},                                      // volume limit exceeded, rolled over
+		{0, 15, true, 16, false, types.ErrOrderExceedsBatchVolumeLimit},  // order exceeds volume limit
+		{0, 15, false, 16, false, types.ErrOrderExceedsBatchVolumeLimit}, // order exceeds volume limit
+	}
+	for _, tc := range testCases {
+		app, ctx := createTestApp(false)
+
+		bond := getValidBond()
+		bond.MaxBatchOrders = sdk.NewUint(tc.maxBatchOrders)
+		bond.MaxBatchVolume = sdk.NewUint(tc.maxBatchVolume)
+		bond.RollOverOrders = tc.rollOverOrders
+		app.BondsKeeper.SetBond(ctx, token, bond)
+		app.BondsKeeper.SetBatch(ctx, token, getValidBatch())
+
+		// Batch already has a buy of 10 tokens
+		app.BondsKeeper.AddBuyOrder(ctx, token, types.NewBuyOrder(buyerAddress,
+			sdk.NewInt64Coin(token, 10), maxPrices), sdk.DecCoins{}, sdk.DecCoins{})
+
+		rollOver, err := app.BondsKeeper.CheckBatchCapacity(ctx, token, sdk.NewInt(tc.amount))
+		require.Equal(t, tc.rollOver, rollOver)
+		if tc.expectedErr == nil {
+			require.NoError(t, err)
+		} else {
+			require.True(t, errors.Is(err, tc.expectedErr))
+		}
+	}
+}
+
+func TestCheckBatchCapacityRollsOverWhileOrdersRolledOver(t *testing.T) {
+	app, ctx := createTestApp(false)
+
+	bond := getValidBond()
+	bond.RollOverOrders = true
+	app.BondsKeeper.SetBond(ctx, token, bond)
+	app.BondsKeeper.SetBatch(ctx, token, getValidBatch())
+
+	// Batch has room but orders are rolled over so that they stay in order
+	app.BondsKeeper.RollOverBuyOrder(ctx, token, getValidBuyOrder())
+	rollOver, err := app.BondsKeeper.CheckBatchCapacity(ctx, token, sdk.OneInt())
+	require.NoError(t, err)
+	require.True(t, rollOver)
+}
+
+func TestOrderCommitmentsDoNotCountTowardsMaxBatchOrders(t *testing.T) {
+	app, ctx := createTestApp(false)
+
+	bond := getValidBond()
+	bond.MaxBatchOrders = sdk.OneUint()
+	app.BondsKeeper.SetBond(ctx, token, bond)
+	app.BondsKeeper.SetBatch(ctx, token, getValidBatch())
+
+	// Commitments do not take up the batch's only place
+	commitment := app.BondsKeeper.AddOrderCommitment(ctx, newTestOrderCommitment(buyerAddress, 10))
+	app.BondsKeeper.AddOrderCommitment(ctx, newTestOrderCommitment(sellerAddress, 10))
+	require.Equal(t, uint64(0), app.BondsKeeper.MustGetBatch(ctx, token).OrderCount)
+	require.NoError(t, app.BondsKeeper.CheckBatchHasRoom(ctx, token, sdk.ZeroInt()))
+
+	// Removing a commitment (e.g. when revealed) leaves the batch as it is
+	app.BondsKeeper.RemoveOrderCommitment(ctx, commitment)
+	require.Equal(t, uint64(0), app.BondsKeeper.MustGetBatch(ctx, token).OrderCount)
+}
+
+func TestCheckBatchCapacityWithModuleMaxBatchVolume(t *testing.T) {
+	testCases := []struct {
+		moduleMaxBatchVolume uint64
+		bondMaxBatchVolume   uint64
+		amount               int64
+		expectedErr          error
+	}{
+		{15, 0, 5, nil}, // module limit not exceeded
+		{15, 0, 6, types.ErrBatchVolumeLimitReached},        // module limit exceeded
+		{15, 0, 16, types.ErrOrderExceedsBatchVolumeLimit},  // order exceeds module limit
+		{15, 20, 6, types.ErrBatchVolumeLimitReached},       // bond limit capped by module limit
+		{20, 15, 6, types.ErrBatchVolumeLimitReached},       // bond limit lower than module limit
+		{15, 20, 16, types.ErrOrderExceedsBatchVolumeLimit}, // order exceeds capped bond limit
+	}
+	for _, tc := range testCases {
+		app, ctx := createTestApp(false)
+
+		params := app.BondsKeeper.GetParams(ctx)
+		params.MaxBatchVolume = tc.moduleMaxBatchVolume
+		app.BondsKeeper.SetParams(ctx, params)
+
+		bond := getValidBond()
+		bond.MaxBatchVolume = sdk.NewUint(tc.bondMaxBatchVolume)
+		app.BondsKeeper.SetBond(ctx, token, bond)
+		app.BondsKeeper.SetBatch(ctx, token, getValidBatch())
+
+		// Batch already has a buy of 10 tokens
+		app.BondsKeeper.AddBuyOrder(ctx, token, types.NewBuyOrder(buyerAddress,
+			sdk.NewInt64Coin(token, 10), maxPrices), sdk.DecCoins{}, sdk.DecCoins{})
+
+		_, err := app.BondsKeeper.CheckBatchCapacity(ctx, token, sdk.NewInt(tc.amount))
+		if tc.expectedErr == nil {
+			require.NoError(t, err)
+		} else {
+			require.True(t, errors.Is(err, tc.expectedErr))
+		}
+	}
+}
+
+func TestCancelledOrdersDoNotCountTowardsMaxBatchOrders(t *testing.T) {
+	app, ctx := createTestApp(false)
+
+	bond := getValidBond()
+	bond.MaxBatchOrders = sdk.OneUint()
+	app.BondsKeeper.SetBond(ctx, token, bond)
+	app.BondsKeeper.SetBatch(ctx, token, getValidBatch())
+
+	// Batch has a buy, with its max prices in the module account
+	bo := app.BondsKeeper.AddBuyOrder(ctx, token, types.NewBuyOrder(buyerAddress,
+		sdk.NewInt64Coin(token, 10), maxPrices), sdk.DecCoins{}, sdk.DecCoins{})
+	moduleAcc := app.SupplyKeeper.GetModuleAccount(ctx, types.BatchesIntermediaryAccount)
+	_, err := app.BankKeeper.AddCoins(ctx, moduleAcc.GetAddress(), maxPrices)
+	require.Nil(t, err)
+	err = app.BondsKeeper.CheckBatchHasRoom(ctx, token, sdk.ZeroInt())
+	require.True(t, errors.Is(err, types.ErrBatchOrderLimitReached))
+
+	// Cancelling the buy frees up its place
+	_, err = app.BondsKeeper.CancelOrder(ctx, token, buyerAddress, bo.Id)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), app.BondsKeeper.MustGetBatch(ctx, token).OrderCount)
+	require.NoError(t, app.BondsKeeper.CheckBatchHasRoom(ctx, token, sdk.ZeroInt()))
+}
+
+func TestAddRolledOverOrders(t *testing.T) {
+	app, ctx := createTestApp(false)
+
+	bond := getValidBond()
+	bond.MaxBatchOrders = sdk.NewUint(2)
+	bond.RollOverOrders = true
+	app.BondsKeeper.SetBond(ctx, token, bond)
+	app.BondsKeeper.SetBatch(ctx, token, getValidBatch())
+
+	// Roll over three buys (max prices are expected to be held by the module)
+	var buys []types.BuyOrder
+	for i := 0; i < 3; i++ {
+		buy := types.NewBuyOrder(buyerAddress, sdk.NewInt64Coin(token, 1),
+			sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 1000)))
+		buys = append(buys, app.BondsKeeper.RollOverBuyOrder(ctx, token, buy))
+	}
+	require.Equal(t, buys, app.BondsKeeper.GetRolledOverOrders(ctx, token).Buys)
+	require.True(t, app.BondsKeeper.IsRolledOverOrder(ctx, token, buys[0].Id))
+
+	// Only the first two buys fit in the batch
+	err := app.BondsKeeper.AddRolledOverOrders(ctx, token)
+	require.NoError(t, err)
+	batch := app.BondsKeeper.MustGetBatch(ctx, token)
+	require.Len(t, batch.Buys, 2)
+	require.Equal(t, buys[0].Id, batch.Buys[0].Id)
+	require.Equal(t, buys[1].Id, batch.Buys[1].Id)
+	require.Equal(t, uint64(2), batch.OrderCount)
+	require.Equal(t, buys[2:], app.BondsKeeper.GetRolledOverOrders(ctx, token).Buys)
+	require.False(t, app.BondsKeeper.IsRolledOverOrder(ctx, token, buys[0].Id))
+
+	// Nothing added to a full batch
+	err = app.BondsKeeper.AddRolledOverOrders(ctx, token)
+	require.NoError(t, err)
+	require.Len(t, app.BondsKeeper.MustGetBatch(ctx, token).Buys, 2)
+	require.True(t, app.BondsKeeper.HasRolledOverOrders(ctx, token))
+}
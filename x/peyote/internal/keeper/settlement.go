@@ -0,0 +1,537 @@
+package keeper
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/warmage-sports/peyote/x/peyote/internal/types"
+)
+
+func (k Keeper) GetSettlementSnapshotsIterator(ctx sdk.Context) sdk.Iterator {
+	store := ctx.KVStore(k.storeKey)
+	return sdk.KVStorePrefixIterator(store, types.SettlementSnapshotsKeyPrefix)
+}
+
+// GetSettlementSnapshot returns the bond's settlement snapshot without its
+// holders, which can be retrieved using GetHolderSnapshots.
+func (k Keeper) GetSettlementSnapshot(ctx sdk.Context, token string) (snapshot types.SettlementSnapshot, found bool) {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(types.GetSettlementSnapshotKey(token))
+	if bz == nil {
+		return types.SettlementSnapshot{}, false
+	}
+	k.cdc.MustUnmarshalBinaryBare(bz, &snapshot)
+	return snapshot, true
+}
+
+func (k Keeper) MustGetSettlementSnapshotByKey(ctx sdk.Context, key []byte) types.SettlementSnapshot {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(key)
+	if bz == nil {
+		panic("settlement snapshot not found")
+	}
+
+	var snapshot types.SettlementSnapshot
+	k.cdc.MustUnmarshalBinaryBare(bz, &snapshot)
+	return snapshot
+}
+
+// SetSettlementSnapshot sets the bond's settlement snapshot along with the
+// holder snapshots that it includes.
+func (k Keeper) SetSettlementSnapshot(ctx sdk.Context, snapshot types.SettlementSnapshot) {
+	k.SetSettlementSnapshotHeader(ctx, snapshot)
+	for _, h := range snapshot.Holders {
+		k.SetHolderSnapshot(ctx, snapshot.BondToken, h)
+	}
+}
+
+// SetSettlementSnapshotHeader sets the bond's settlement snapshot without its
+// holders, i.e. any holders in the snapshot are ignored and the stored holder
+// snapshots are left unchanged. The snapshot is also indexed by its claim
+// deadline, if it has one, and as being recorded while its holders are being
+// recorded.
+func (k Keeper) SetSettlementSnapshotHeader(ctx sdk.Context, snapshot types.SettlementSnapshot) {
+	snapshot.Holders = nil
+	store := ctx.KVStore(k.storeKey)
+	store.Set(types.GetSettlementSnapshotKey(snapshot.BondToken), k.cdc.MustMarshalBinaryBare(snapshot))
+	if snapshot.HasClaimDeadline() {
+		store.Set(types.GetClaimDeadlineKey(snapshot.ClaimDeadline, snapshot.BondToken),
+			[]byte(snapshot.BondToken))
+	}
+	if snapshot.Recording.InProgress {
+		store.Set(types.GetRecordingSnapshotKey(snapshot.BondToken), []byte(snapshot.BondToken))
+	} else {
+		store.Delete(types.GetRecordingSnapshotKey(snapshot.BondToken))
+	}
+}
+
+// RemoveSettlementSnapshot removes the bond's settlement snapshot along with
+// its holder snapshots and claim deadline.
+func (k Keeper) RemoveSettlementSnapshot(ctx sdk.Context, token string) {
+	k.removeClaimDeadline(ctx, token)
+	store := ctx.KVStore(k.storeKey)
+	store.Delete(types.GetSettlementSnapshotKey(token))
+	store.Delete(types.GetRecordingSnapshotKey(token))
+
+	var keys [][]byte
+	iterator := sdk.KVStorePrefixIterator(store, types.GetHolderSnapshotsPrefixKey(token))
+	for ; iterator.Valid(); iterator.Next() {
+		keys = append(keys, iterator.Key())
+	}
+	iterator.Close()
+
+	for _, key := range keys {
+		store.Delete(key)
+	}
+}
+
+func (k Keeper) GetHolderSnapshot(ctx sdk.Context, token string, address sdk.AccAddress) (holder types.HolderSnapshot, found bool) {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(types.GetHolderSnapshotKey(token, address))
+	if bz == nil {
+		return types.HolderSnapshot{}, false
+	}
+	k.cdc.MustUnmarshalBinaryBare(bz, &holder)
+	return holder, true
+}
+
+func (k Keeper) SetHolderSnapshot(ctx sdk.Context, token string, holder types.HolderSnapshot) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(types.GetHolderSnapshotKey(token, holder.Address), k.cdc.MustMarshalBinaryBare(holder))
+}
+
+// GetHolderSnapshots returns the holder snapshots of the bond, in order of
+// address.
+func (k Keeper) GetHolderSnapshots(ctx sdk.Context, token string) (holders []types.HolderSnapshot) {
+	store := ctx.KVStore(k.storeKey)
+	iterator := sdk.KVStorePrefixIterator(store, types.GetHolderSnapshotsPrefixKey(token))
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		var holder types.HolderSnapshot
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &holder)
+		holders = append(holders, holder)
+	}
+	return holders
+}
+
+// GetHolderSnapshotsAfter returns at most limit holder snapshots of the bond,
+// in order of address, starting from the first address after the specified
+// one, or from the first address if the specified one is empty.
+func (k Keeper) GetHolderSnapshotsAfter(ctx sdk.Context, token string,
+	after sdk.AccAddress, limit uint64) (holders []types.HolderSnapshot) {
+
+	store := ctx.KVStore(k.storeKey)
+	prefix := types.GetHolderSnapshotsPrefixKey(token)
+	start := prefix
+	if !after.Empty() {
+		// Smallest key that is greater than the key of the address
+		start = append(types.GetHolderSnapshotKey(token, after), 0x00)
+	}
+
+	iterator := store.Iterator(start, sdk.PrefixEndBytes(prefix))
+	defer iterator.Close()
+
+	for ; iterator.Valid() && uint64(len(holders)) < limit; iterator.Next() {
+		var holder types.HolderSnapshot
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &holder)
+		holders = append(holders, holder)
+	}
+	return holders
+}
+
+// SnapshotHolders starts a settlement snapshot of the bond token balance of
+// each account that holds any of the bond's tokens at the current height, and
+// returns the snapshot, whose holders are recorded at the end of this and the
+// following blocks (see RecordHolders). Bond tokens held by module accounts
+// (e.g. escrowed for pending orders) are not included. If the bond has a claim
+// window, the snapshot's claim deadline is the last block of the window. This
+// is expected to be called as the bond enters the SETTLE state. Any earlier
+// snapshot of the bond (i.e. if a quarantined bond that had already been
+// settled is settled again) is replaced, along with its claim deadline and any
+// share distribution in progress.
+func (k Keeper) SnapshotHolders(ctx sdk.Context, token string) types.SettlementSnapshot {
+	k.RemoveSettlementSnapshot(ctx, token)
+	k.RemoveShareDistribution(ctx, token)
+
+	snapshot := types.NewSettlementSnapshot(token, ctx.BlockHeight())
+	snapshot.Recording = types.NewHolderRecording()
+	if claimBlocks := k.MustGetBond(ctx, token).ClaimBlocks; !claimBlocks.IsZero() {
+		snapshot.ClaimDeadline = ctx.BlockHeight() + int64(claimBlocks.Uint64())
+	}
+	k.SetSettlementSnapshotHeader(ctx, snapshot)
+
+	logger := k.Logger(ctx)
+	logger.Info(fmt.Sprintf("started snapshot of holders of %s at height %d",
+		token, snapshot.Height))
+
+	return snapshot
+}
+
+// GetAttestedPercentage returns the percentage (from 0 to 100) of the bond's
+// outcome payment with the given index that is attested by the bond's
+// evaluators, and false if the payment has not been attested by a quorum of
+// the evaluators, or if there is no outcomes keeper to hold the attestations.
+func (k Keeper) GetAttestedPercentage(ctx sdk.Context, token string, payment uint64) (sdk.Dec, bool) {
+	if k.outcomesKeeper == nil {
+		return sdk.Dec{}, false
+	}
+	return k.outcomesKeeper.GetAttestedPercentage(ctx, token, payment)
+}
+
+// SettleBond moves the bond to the SETTLE state, cancels and refunds all of
+// the bond's pending orders, and starts a snapshot of the balances of its
+// holders. If the bond distributes its reserve automatically, the distribution
+// of the reserve starts once the snapshot is complete. The started settlement
+// snapshot is returned. An error is returned if any pending order could not
+// be refunded.
+func (k Keeper) SettleBond(ctx sdk.Context, token string) (types.SettlementSnapshot, error) {
+	k.SetBondState(ctx, token, types.SettleState)
+	err := k.cancelPendingOrders(ctx, token, "bond settled")
+	if err != nil {
+		return types.SettlementSnapshot{}, err
+	}
+	return k.SnapshotHolders(ctx, token), nil
+}
+
+// QuarantineBond moves the bond to the QUARANTINE state, since its accounting
+// is inconsistent, and cancels and refunds all of its pending orders, since
+// none of these can be performed any more. If any of the orders could not be
+// refunded, none of them are cancelled, and they are refunded if the bond is
+// later settled by its signers instead.
+func (k Keeper) QuarantineBond(ctx sdk.Context, token string, reason error) {
+	logger := k.Logger(ctx)
+	logger.Error(fmt.Sprintf("quarantining bond %s: %s", token, reason.Error()))
+
+	k.SetBondState(ctx, token, types.QuarantineState)
+	err := performInCacheContext(ctx, func(ctx sdk.Context) error {
+		return k.cancelPendingOrders(ctx, token, "bond quarantined")
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("could not refund pending orders of quarantined bond %s: %s", token, err.Error()))
+	}
+}
+
+// cancelPendingOrders cancels all of the bond's pending orders as the bond is
+// settled or quarantined, since none of these can be performed any more. The
+// orders in the bond's batch and its rolled-over orders are refunded in the
+// same way as when cancelled by their owners, the escrow of its limit orders
+// and the deposits of its order commitments are refunded, and its recurring
+// orders are ended and their remaining budgets refunded. Sells are refunded
+// before the holders are snapshotted, so the refunded bond tokens are included
+// in the snapshot.
+func (k Keeper) cancelPendingOrders(ctx sdk.Context, token, reason string) error {
+	emitCancel := func(orderType string, id uint64, address sdk.AccAddress) {
+		ctx.EventManager().EmitEvent(sdk.NewEvent(
+			types.EventTypeOrderCancel,
+			sdk.NewAttribute(types.AttributeKeyBond, token),
+			sdk.NewAttribute(types.AttributeKeyOrderId, fmt.Sprint(id)),
+			sdk.NewAttribute(types.AttributeKeyOrderType, orderType),
+			sdk.NewAttribute(types.AttributeKeyAddress, address.String()),
+			sdk.NewAttribute(types.AttributeKeyCancelReason, reason),
+		))
+	}
+
+	// Order commitments
+	for _, commitment := range k.GetOrderCommitments(ctx, token) {
+		k.RemoveOrderCommitment(ctx, commitment)
+		err := k.SupplyKeeper.SendCoinsFromModuleToAccount(ctx,
+			types.BatchesIntermediaryAccount, commitment.Address, commitment.Deposit)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Orders in the batch
+	batch := k.MustGetBatchHeader(ctx, token)
+	for _, bo := range k.GetBatchBuyOrders(ctx, token) {
+		if bo.IsCancelled() {
+			continue
+		}
+		bo.Cancelled, bo.CancelReason = true, reason
+		batch.TotalBuyAmount = batch.TotalBuyAmount.Sub(bo.Amount)
+		batch.OrderCount--
+		k.SetBatchBuyOrder(ctx, token, bo)
+		if err := k.refundBuyOrder(ctx, bo); err != nil {
+			return err
+		}
+		emitCancel(types.AttributeValueBuyOrder, bo.Id, bo.Address)
+	}
+	for _, so := range k.GetBatchSellOrders(ctx, token) {
+		if so.IsCancelled() {
+			continue
+		}
+		so.Cancelled, so.CancelReason = true, reason
+		batch.TotalSellAmount = batch.TotalSellAmount.Sub(so.Amount)
+		batch.OrderCount--
+		k.SetBatchSellOrder(ctx, token, so)
+		if err := k.refundSellOrder(ctx, so); err != nil {
+			return err
+		}
+		emitCancel(types.AttributeValueSellOrder, so.Id, so.Address)
+	}
+	for _, so := range k.GetBatchSwapOrders(ctx, token) {
+		if so.IsCancelled() {
+			continue
+		}
+		so.Cancelled, so.CancelReason = true, reason
+		batch.OrderCount--
+		k.SetBatchSwapOrder(ctx, token, so)
+		if err := k.refundSwapOrder(ctx, so); err != nil {
+			return err
+		}
+		emitCancel(types.AttributeValueSwapOrder, so.Id, so.Address)
+	}
+	k.SetBatchHeader(ctx, token, batch)
+
+	// Rolled-over orders
+	store := ctx.KVStore(k.storeKey)
+	rolledOver := k.GetRolledOverOrders(ctx, token)
+	for _, bo := range rolledOver.Buys {
+		store.Delete(types.GetRolledOverOrderKey(token, bo.Id, types.BatchBuyOrderByte))
+		if err := k.refundBuyOrder(ctx, bo); err != nil {
+			return err
+		}
+		emitCancel(types.AttributeValueBuyOrder, bo.Id, bo.Address)
+	}
+	for _, so := range rolledOver.Sells {
+		store.Delete(types.GetRolledOverOrderKey(token, so.Id, types.BatchSellOrderByte))
+		if err := k.refundRolledOverSellOrder(ctx, so); err != nil {
+			return err
+		}
+		emitCancel(types.AttributeValueSellOrder, so.Id, so.Address)
+	}
+	for _, so := range rolledOver.Swaps {
+		store.Delete(types.GetRolledOverOrderKey(token, so.Id, types.BatchSwapOrderByte))
+		if err := k.refundSwapOrder(ctx, so); err != nil {
+			return err
+		}
+		emitCancel(types.AttributeValueSwapOrder, so.Id, so.Address)
+	}
+
+	// Limit orders
+	for _, buys := range []bool{true, false} {
+		for _, order := range k.GetLimitOrderBook(ctx, token, buys) {
+			k.RemoveLimitOrder(ctx, order)
+			err := k.SupplyKeeper.SendCoinsFromModuleToAccount(ctx,
+				types.BatchesIntermediaryAccount, order.Address, order.Escrow)
+			if err != nil {
+				return err
+			}
+			emitCancel(order.OrderType, order.Id, order.Address)
+		}
+	}
+
+	// Recurring orders
+	for _, order := range k.GetBondRecurringOrders(ctx, token) {
+		k.RemoveRecurringOrder(ctx, order)
+		err := k.SupplyKeeper.SendCoinsFromModuleToAccount(ctx,
+			types.BatchesIntermediaryAccount, order.Address, order.Budget)
+		if err != nil {
+			return err
+		}
+		ctx.EventManager().EmitEvent(sdk.NewEvent(
+			types.EventTypeRecurringOrderEnd,
+			sdk.NewAttribute(types.AttributeKeyBond, token),
+			sdk.NewAttribute(types.AttributeKeyRecurringOrderId, fmt.Sprint(order.Id)),
+			sdk.NewAttribute(types.AttributeKeyOrderType, order.OrderType),
+			sdk.NewAttribute(types.AttributeKeyAddress, order.Address.String()),
+			sdk.NewAttribute(types.AttributeKeyReturnedToAddress, order.Budget.String()),
+			sdk.NewAttribute(types.AttributeKeyCancelReason, reason),
+		))
+	}
+
+	return nil
+}
+
+// DistributeOutcomeTranche starts distributing the bond's outcome tranche
+// with the given index to the accounts that currently hold any of the bond's
+// tokens, in proportion to their bond token balances, rather than paying it
+// to the bond's reserve. The tranche is escrowed, and the holders' current
+// balances are recorded and the holders are then paid at the end of this and
+// the following blocks (see RecordHolders and DistributeShares). As with
+// snapshots, bond tokens held by module accounts are not included. If there
+// turn out to be no holders, the tranche is sent to the reserve instead.
+func (k Keeper) DistributeOutcomeTranche(ctx sdk.Context, token string, tranche uint64,
+	from sdk.AccAddress, amount sdk.Coins) error {
+
+	if amount.Empty() {
+		return nil
+	}
+
+	err := k.SupplyKeeper.SendCoinsFromAccountToModule(ctx,
+		from, types.BatchesIntermediaryAccount, amount)
+	if err != nil {
+		return err
+	}
+	distribution := types.NewTrancheDistribution(token, tranche, amount)
+	distribution.Recording = types.NewHolderRecording()
+	k.SetTrancheDistributionHeader(ctx, distribution)
+
+	return nil
+}
+
+// WithdrawShare pays the holder its share of the settled bond's remaining
+// reserve for an amount of the bond tokens that it held when the bond was
+// settled, or for its entire remaining share if the amount is zero. The share
+// is relative to the total amount in the settlement snapshot that has not yet
+// been withdrawn, so it does not depend on the order in which holders
+// withdraw. Any of the withdrawn amount that the holder still holds is
+// burned. The reserve paid out and the amount withdrawn are returned.
+func (k Keeper) WithdrawShare(ctx sdk.Context, token string, address sdk.AccAddress,
+	amount sdk.Int) (reserveOwed sdk.Coins, withdrawn sdk.Int, err error) {
+
+	snapshot, found := k.GetSettlementSnapshot(ctx, token)
+	if !found {
+		return nil, sdk.ZeroInt(), sdkerrors.Wrap(types.ErrSettlementSnapshotNotFound, token)
+	} else if snapshot.Recording.InProgress {
+		return nil, sdk.ZeroInt(), sdkerrors.Wrap(types.ErrHoldersBeingRecorded, token)
+	}
+	holder, found := k.GetHolderSnapshot(ctx, token, address)
+	if !found || !holder.Remaining().IsPositive() {
+		return nil, sdk.ZeroInt(), sdkerrors.Wrap(types.ErrNoBondTokensOwned,
+			"no remaining share of the bond tokens held when the bond was settled")
+	}
+
+	// Withdraw the entire remaining share if no amount specified
+	if amount.IsZero() {
+		amount = holder.Remaining()
+	} else if amount.GT(holder.Remaining()) {
+		return nil, sdk.ZeroInt(), sdkerrors.Wrapf(types.ErrAmountExceedsShare,
+			"%s is greater than remaining %s", amount, holder.Remaining())
+	}
+
+	// Calculate reserve owed as a share of the remaining reserve
+	remainingReserve := k.GetReserveBalances(ctx, token)
+	for _, r := range remainingReserve {
+		owed := r.Amount.Mul(amount).Quo(snapshot.Remaining())
+		if owed.IsPositive() {
+			reserveOwed = reserveOwed.Add(sdk.NewCoin(r.Denom, owed))
+		}
+	}
+
+	// Burn any of the withdrawn amount that is still held by the holder
+	toBurn := sdk.MinInt(amount, k.BankKeeper.GetCoins(ctx, address).AmountOf(token))
+	if toBurn.IsPositive() {
+		burned := sdk.NewCoins(sdk.NewCoin(token, toBurn))
+		err = k.SupplyKeeper.SendCoinsFromAccountToModule(
+			ctx, address, types.BondsMintBurnAccount, burned)
+		if err != nil {
+			return nil, sdk.ZeroInt(), err
+		}
+		err = k.SupplyKeeper.BurnCoins(ctx, types.BondsMintBurnAccount, burned)
+		if err != nil {
+			return nil, sdk.ZeroInt(), err
+		}
+		bond := k.MustGetBond(ctx, token)
+		k.SetCurrentSupply(ctx, token, bond.CurrentSupply.Sub(burned[0]))
+	}
+
+	// Send reserve owed to holder
+	err = k.WithdrawReserve(ctx, token, address, reserveOwed)
+	if err != nil {
+		return nil, sdk.ZeroInt(), err
+	}
+
+	// Record withdrawal in snapshot
+	holder.Withdrawn = holder.Withdrawn.Add(amount)
+	k.SetHolderSnapshot(ctx, token, holder)
+	snapshot.TotalWithdrawn = snapshot.TotalWithdrawn.Add(amount)
+	k.SetSettlementSnapshotHeader(ctx, snapshot)
+
+	return reserveOwed, amount, nil
+}
+
+// SweepUnclaimedReserves sweeps the remaining reserve of any settled bond
+// whose claim deadline has been reached, and closes the bond. Quarantined
+// bonds are not swept. A quarantined bond only returns to the SETTLE state by
+// being settled again, which replaces its claim deadline (see SnapshotHolders).
+// Only the claim deadlines up to the current height are visited.
+func (k Keeper) SweepUnclaimedReserves(ctx sdk.Context) {
+	store := ctx.KVStore(k.storeKey)
+	end := sdk.PrefixEndBytes(types.GetClaimDeadlinePrefixKey(ctx.BlockHeight()))
+
+	var tokens []string
+	iterator := store.Iterator(types.ClaimDeadlinesKeyPrefix, end)
+	for ; iterator.Valid(); iterator.Next() {
+		tokens = append(tokens, string(iterator.Value()))
+	}
+	iterator.Close()
+
+	for _, token := range tokens {
+		switch k.MustGetBond(ctx, token).State {
+		case types.SettleState:
+			// Not swept until the snapshot's holders have been recorded
+			if !k.isSnapshotBeingRecorded(ctx, token) {
+				k.sweepUnclaimedReserve(ctx, token)
+			}
+		case types.ClosedState:
+			// Already swept (e.g. deadline re-indexed when importing genesis)
+			k.removeClaimDeadline(ctx, token)
+		}
+	}
+}
+
+func (k Keeper) isSnapshotBeingRecorded(ctx sdk.Context, token string) bool {
+	store := ctx.KVStore(k.storeKey)
+	return store.Has(types.GetRecordingSnapshotKey(token))
+}
+
+func (k Keeper) removeClaimDeadline(ctx sdk.Context, token string) {
+	snapshot, found := k.GetSettlementSnapshot(ctx, token)
+	if found && snapshot.HasClaimDeadline() {
+		store := ctx.KVStore(k.storeKey)
+		store.Delete(types.GetClaimDeadlineKey(snapshot.ClaimDeadline, token))
+	}
+}
+
+// sweepUnclaimedReserve sends the settled bond's remaining reserve to its fee
+// address or to the community pool, ends any share distribution that is in
+// progress, and closes the bond. If the reserve cannot be sent, the bond is
+// left as is so that the sweep is retried in a later block.
+func (k Keeper) sweepUnclaimedReserve(ctx sdk.Context, token string) {
+	bond := k.MustGetBond(ctx, token)
+	reserve := k.GetReserveBalances(ctx, token)
+	recipient := bond.FeeAddress.String()
+	if bond.SweepToCommunityPool {
+		recipient = types.AttributeValueCommunityPool
+	}
+
+	err := performInCacheContext(ctx, func(ctx sdk.Context) error {
+		if !reserve.Empty() {
+			var err error
+			if bond.SweepToCommunityPool {
+				err = k.WithdrawReserveToCommunityPool(ctx, token, reserve)
+			} else {
+				err = k.WithdrawReserve(ctx, token, bond.FeeAddress, reserve)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		// The settlement snapshot is kept, but its claim deadline is no
+		// longer indexed since the bond has been swept
+		k.removeClaimDeadline(ctx, token)
+		k.RemoveShareDistribution(ctx, token)
+		k.SetBondState(ctx, token, types.ClosedState)
+		return nil
+	})
+	if err != nil {
+		k.Logger(ctx).Error(fmt.Sprintf("could not sweep unclaimed reserve of %s: %s",
+			token, err.Error()))
+		return
+	}
+
+	logger := k.Logger(ctx)
+	logger.Info(fmt.Sprintf("swept unclaimed reserve [%s] of %s to %s",
+		reserve.String(), token, recipient))
+
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		types.EventTypeSweepReserve,
+		sdk.NewAttribute(types.AttributeKeyBond, token),
+		sdk.NewAttribute(types.AttributeKeyRecipient, recipient),
+		sdk.NewAttribute(sdk.AttributeKeyAmount, reserve.String()),
+	))
+}
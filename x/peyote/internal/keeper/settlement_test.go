@@ -0,0 +1,308 @@
+package keeper_test
+
+import (
+	"errors"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+	"github.com/warmage-sports/peyote/x/peyote/internal/types"
+)
+
+func TestSnapshotHolders(t *testing.T) {
+	app, ctx := createTestApp(false)
+	ctx = ctx.WithBlockHeight(50)
+	app.BondsKeeper.SetBond(ctx, token, getValidBond())
+
+	// Mint bond tokens to two holders and leave some in a module account
+	buyerTokens := sdk.NewCoins(sdk.NewInt64Coin(token, 30))
+	sellerTokens := sdk.NewCoins(sdk.NewInt64Coin(token, 10))
+	err := app.SupplyKeeper.MintCoins(ctx, types.BondsMintBurnAccount,
+		buyerTokens.Add(sellerTokens...).Add(sdk.NewInt64Coin(token, 5)))
+	require.Nil(t, err)
+	err = app.SupplyKeeper.SendCoinsFromModuleToAccount(
+		ctx, types.BondsMintBurnAccount, buyerAddress, buyerTokens)
+	require.Nil(t, err)
+	err = app.SupplyKeeper.SendCoinsFromModuleToAccount(
+		ctx, types.BondsMintBurnAccount, sellerAddress, sellerTokens)
+	require.Nil(t, err)
+
+	// Holders are not recorded until RecordHolders is called
+	snapshot := app.BondsKeeper.SnapshotHolders(ctx, token)
+	require.Equal(t, int64(50), snapshot.Height)
+	require.True(t, snapshot.Recording.InProgress)
+	require.Len(t, app.BondsKeeper.GetHolderSnapshots(ctx, token), 0)
+
+	// The two holders and the module account are visited, but tokens held by
+	// the module account are not included
+	require.Equal(t, uint64(7), app.BondsKeeper.RecordHolders(ctx, 10))
+
+	returned, found := app.BondsKeeper.GetSettlementSnapshot(ctx, token)
+	require.True(t, found)
+	require.Nil(t, returned.Holders)
+	require.False(t, returned.Recording.InProgress)
+	require.Equal(t, uint64(2), returned.Recording.HolderCount)
+	require.Equal(t, sdk.NewInt(40), returned.TotalBalance)
+	require.Len(t, app.BondsKeeper.GetHolderSnapshots(ctx, token), 2)
+
+	holder, found := app.BondsKeeper.GetHolderSnapshot(ctx, token, buyerAddress)
+	require.True(t, found)
+	require.Equal(t, types.NewHolderSnapshot(buyerAddress, sdk.NewInt(30)), holder)
+
+	_, found = app.BondsKeeper.GetHolderSnapshot(ctx, token, swapperAddress)
+	require.False(t, found)
+}
+
+func TestSnapshotHoldersRecordsBalancesBeforeTheyChange(t *testing.T) {
+	app, ctx := createTestApp(false)
+	app.BondsKeeper.SetBond(ctx, token, getValidBond())
+
+	// Mint bond tokens to two holders
+	buyerTokens := sdk.NewCoins(sdk.NewInt64Coin(token, 30))
+	sellerTokens := sdk.NewCoins(sdk.NewInt64Coin(token, 10))
+	err := app.SupplyKeeper.MintCoins(ctx, types.BondsMintBurnAccount,
+		buyerTokens.Add(sellerTokens...))
+	require.Nil(t, err)
+	err = app.SupplyKeeper.SendCoinsFromModuleToAccount(
+		ctx, types.BondsMintBurnAccount, buyerAddress, buyerTokens)
+	require.Nil(t, err)
+	err = app.SupplyKeeper.SendCoinsFromModuleToAccount(
+		ctx, types.BondsMintBurnAccount, sellerAddress, sellerTokens)
+	require.Nil(t, err)
+
+	// Buyer sends all of its tokens to swapper before the holders are
+	// recorded, and swapper sends some of them on to seller
+	app.BondsKeeper.SnapshotHolders(ctx, token)
+	err = app.BankKeeper.SendCoins(ctx, buyerAddress, swapperAddress, buyerTokens)
+	require.Nil(t, err)
+	err = app.BankKeeper.SendCoins(ctx, swapperAddress, sellerAddress,
+		sdk.NewCoins(sdk.NewInt64Coin(token, 5)))
+	require.Nil(t, err)
+
+	// Only seller and swapper are still holders (the module account no longer
+	// holds any tokens, and buyer sent all of its tokens)
+	require.Equal(t, uint64(8), app.BondsKeeper.RecordHolders(ctx, 10))
+
+	// Balances are the ones before the transfers
+	snapshot, _ := app.BondsKeeper.GetSettlementSnapshot(ctx, token)
+	require.Equal(t, sdk.NewInt(40), snapshot.TotalBalance)
+	require.Equal(t, uint64(2), snapshot.Recording.HolderCount)
+	holder, found := app.BondsKeeper.GetHolderSnapshot(ctx, token, buyerAddress)
+	require.True(t, found)
+	require.Equal(t, sdk.NewInt(30), holder.Balance)
+	holder, found = app.BondsKeeper.GetHolderSnapshot(ctx, token, sellerAddress)
+	require.True(t, found)
+	require.Equal(t, sdk.NewInt(10), holder.Balance)
+	_, found = app.BondsKeeper.GetHolderSnapshot(ctx, token, swapperAddress)
+	require.False(t, found)
+	require.Len(t, app.BondsKeeper.GetHolderSnapshots(ctx, token), 2)
+}
+
+func TestDistributeOutcomeTranche(t *testing.T) {
+	app, ctx := createTestApp(false)
+	app.BondsKeeper.SetBond(ctx, token, getValidBond())
+
+	// Mint bond tokens to two holders and leave some in a module account
+	buyerTokens := sdk.NewCoins(sdk.NewInt64Coin(token, 30))
+	sellerTokens := sdk.NewCoins(sdk.NewInt64Coin(token, 10))
+	err := app.SupplyKeeper.MintCoins(ctx, types.BondsMintBurnAccount,
+		buyerTokens.Add(sellerTokens...).Add(sdk.NewInt64Coin(token, 5)))
+	require.Nil(t, err)
+	err = app.SupplyKeeper.SendCoinsFromModuleToAccount(
+		ctx, types.BondsMintBurnAccount, buyerAddress, buyerTokens)
+	require.Nil(t, err)
+	err = app.SupplyKeeper.SendCoinsFromModuleToAccount(
+		ctx, types.BondsMintBurnAccount, sellerAddress, sellerTokens)
+	require.Nil(t, err)
+
+	// Payer pays a tranche of 103 reserve tokens
+	tranche := sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 103))
+	err = app.BankKeeper.SetCoins(ctx, swapperAddress, tranche)
+	require.Nil(t, err)
+
+	err = app.BondsKeeper.DistributeOutcomeTranche(
+		ctx, token, 1, swapperAddress, tranche)
+	require.Nil(t, err)
+
+	// Tranche is escrowed until it is distributed
+	require.True(t, app.BankKeeper.GetCoins(ctx, swapperAddress).IsZero())
+	distribution, found := app.BondsKeeper.GetTrancheDistribution(ctx, token, 1)
+	require.True(t, found)
+	require.True(t, distribution.Recording.InProgress)
+
+	// Holders (and the module account) are visited and recorded in the first
+	// block, and tokens held by the module account are not included
+	params := app.BondsKeeper.GetParams(ctx)
+	params.MaxDistributions = 3
+	app.BondsKeeper.SetParams(ctx, params)
+	app.BondsKeeper.DistributeShares(ctx)
+	require.True(t, app.BankKeeper.GetCoins(ctx, buyerAddress).AmountOf(reserveToken).IsZero())
+	distribution, found = app.BondsKeeper.GetTrancheDistribution(ctx, token, 1)
+	require.True(t, found)
+	require.False(t, distribution.Recording.InProgress)
+	require.Equal(t, sdk.NewInt(40), distribution.TotalBalance)
+	require.Len(t, app.BondsKeeper.GetTrancheHolders(ctx, token, 1, 0), 2)
+
+	// Distribute to one holder per block
+	params.MaxDistributions = 1
+	app.BondsKeeper.SetParams(ctx, params)
+
+	app.BondsKeeper.DistributeShares(ctx)
+	distribution, found = app.BondsKeeper.GetTrancheDistribution(ctx, token, 1)
+	require.True(t, found)
+	require.Equal(t, uint64(1), distribution.HoldersPaid)
+	require.Len(t, app.BondsKeeper.GetTrancheHolders(ctx, token, 1, 0), 1)
+
+	// Shares are rounded down (103*30/40=77.25 and 103*10/40=25.75), and the
+	// remaining 1 reserve token is sent to the reserve once all holders have
+	// been paid
+	app.BondsKeeper.DistributeShares(ctx)
+	_, found = app.BondsKeeper.GetTrancheDistribution(ctx, token, 1)
+	require.False(t, found)
+	require.False(t, app.BondsKeeper.HasTrancheDistributions(ctx, token))
+	require.Equal(t, int64(77), app.BankKeeper.GetCoins(ctx, buyerAddress).AmountOf(reserveToken).Int64())
+	require.Equal(t, int64(25), app.BankKeeper.GetCoins(ctx, sellerAddress).AmountOf(reserveToken).Int64())
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 1)),
+		app.BondsKeeper.GetReserveBalances(ctx, token))
+}
+
+func TestDistributeOutcomeTrancheWithoutHoldersPaysReserve(t *testing.T) {
+	app, ctx := createTestApp(false)
+	app.BondsKeeper.SetBond(ctx, token, getValidBond())
+
+	tranche := sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 100))
+	err := app.BankKeeper.SetCoins(ctx, swapperAddress, tranche)
+	require.Nil(t, err)
+
+	err = app.BondsKeeper.DistributeOutcomeTranche(
+		ctx, token, 0, swapperAddress, tranche)
+	require.Nil(t, err)
+	require.True(t, app.BondsKeeper.HasTrancheDistributions(ctx, token))
+
+	// Tranche is sent to the reserve once no holders were recorded
+	app.BondsKeeper.DistributeShares(ctx)
+	require.Equal(t, tranche, app.BondsKeeper.GetReserveBalances(ctx, token))
+	require.False(t, app.BondsKeeper.HasTrancheDistributions(ctx, token))
+}
+
+func TestWithdrawShare(t *testing.T) {
+	app, ctx := createTestApp(false)
+	bond := getValidBond()
+	bond.CurrentSupply = sdk.NewInt64Coin(token, 40)
+	app.BondsKeeper.SetBond(ctx, token, bond)
+
+	// Mint bond tokens to two holders and add reserve
+	buyerTokens := sdk.NewCoins(sdk.NewInt64Coin(token, 30))
+	sellerTokens := sdk.NewCoins(sdk.NewInt64Coin(token, 10))
+	reserve := sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 400))
+	err := app.SupplyKeeper.MintCoins(ctx, types.BondsMintBurnAccount,
+		buyerTokens.Add(sellerTokens...).Add(reserve...))
+	require.Nil(t, err)
+	err = app.SupplyKeeper.SendCoinsFromModuleToAccount(
+		ctx, types.BondsMintBurnAccount, buyerAddress, buyerTokens)
+	require.Nil(t, err)
+	err = app.SupplyKeeper.SendCoinsFromModuleToAccount(
+		ctx, types.BondsMintBurnAccount, sellerAddress, sellerTokens)
+	require.Nil(t, err)
+	err = app.BondsKeeper.DepositReserveFromModule(
+		ctx, token, types.BondsMintBurnAccount, reserve)
+	require.Nil(t, err)
+
+	// Withdrawal fails if there is no snapshot
+	_, _, err = app.BondsKeeper.WithdrawShare(ctx, token, buyerAddress, sdk.ZeroInt())
+	require.True(t, errors.Is(err, types.ErrSettlementSnapshotNotFound))
+
+	// Withdrawal fails until the holders have been recorded
+	app.BondsKeeper.SnapshotHolders(ctx, token)
+	_, _, err = app.BondsKeeper.WithdrawShare(ctx, token, buyerAddress, sdk.ZeroInt())
+	require.True(t, errors.Is(err, types.ErrHoldersBeingRecorded))
+
+	// Buyer transfers all of its tokens after the snapshot
+	err = app.BankKeeper.SendCoins(ctx, buyerAddress, swapperAddress, buyerTokens)
+	require.Nil(t, err)
+	app.BondsKeeper.RecordHolders(ctx, 10)
+
+	// New holder has no share; buyer cannot withdraw more than its share
+	_, _, err = app.BondsKeeper.WithdrawShare(ctx, token, swapperAddress, sdk.ZeroInt())
+	require.True(t, errors.Is(err, types.ErrNoBondTokensOwned))
+	_, _, err = app.BondsKeeper.WithdrawShare(ctx, token, buyerAddress, sdk.NewInt(31))
+	require.True(t, errors.Is(err, types.ErrAmountExceedsShare))
+
+	// Buyer withdraws 10 of 40 without burning any tokens
+	owed, withdrawn, err := app.BondsKeeper.WithdrawShare(ctx, token, buyerAddress, sdk.NewInt(10))
+	require.Nil(t, err)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 100)), owed)
+	require.Equal(t, sdk.NewInt(10), withdrawn)
+	require.Equal(t, owed, app.BankKeeper.GetCoins(ctx, buyerAddress))
+	require.Equal(t, sdk.NewInt64Coin(token, 40), app.BondsKeeper.MustGetBond(ctx, token).CurrentSupply)
+
+	// Seller withdraws its entire share (10 of remaining 30), burning its tokens
+	owed, withdrawn, err = app.BondsKeeper.WithdrawShare(ctx, token, sellerAddress, sdk.ZeroInt())
+	require.Nil(t, err)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 100)), owed)
+	require.Equal(t, sdk.NewInt(10), withdrawn)
+	require.Equal(t, owed, app.BankKeeper.GetCoins(ctx, sellerAddress))
+	require.Equal(t, sdk.NewInt64Coin(token, 30), app.BondsKeeper.MustGetBond(ctx, token).CurrentSupply)
+
+	// Withdrawals are recorded in the snapshot
+	snapshot, _ := app.BondsKeeper.GetSettlementSnapshot(ctx, token)
+	require.Equal(t, sdk.NewInt(20), snapshot.TotalWithdrawn)
+	holder, _ := app.BondsKeeper.GetHolderSnapshot(ctx, token, sellerAddress)
+	require.True(t, holder.Remaining().IsZero())
+	_, _, err = app.BondsKeeper.WithdrawShare(ctx, token, sellerAddress, sdk.ZeroInt())
+	require.True(t, errors.Is(err, types.ErrNoBondTokensOwned))
+}
+
+func TestSweepUnclaimedReserves(t *testing.T) {
+	app, ctx := createTestApp(false)
+	ctx = ctx.WithBlockHeight(50)
+	bond := getValidBond()
+	bond.ClaimBlocks = sdk.NewUint(10)
+	bond.State = types.SettleState
+	app.BondsKeeper.SetBond(ctx, token, bond)
+
+	reserve := sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 400))
+	err := app.SupplyKeeper.MintCoins(ctx, types.BondsMintBurnAccount, reserve)
+	require.Nil(t, err)
+	err = app.BondsKeeper.DepositReserveFromModule(
+		ctx, token, types.BondsMintBurnAccount, reserve)
+	require.Nil(t, err)
+
+	snapshot := app.BondsKeeper.SnapshotHolders(ctx, token)
+	require.Equal(t, int64(60), snapshot.ClaimDeadline)
+
+	// Nothing is swept before the claim deadline
+	app.BondsKeeper.SweepUnclaimedReserves(ctx.WithBlockHeight(59))
+	require.Equal(t, reserve, app.BondsKeeper.GetReserveBalances(ctx, token))
+
+	// Bonds whose holders are still being recorded are not swept
+	ctx = ctx.WithBlockHeight(60)
+	app.BondsKeeper.SweepUnclaimedReserves(ctx)
+	require.Equal(t, reserve, app.BondsKeeper.GetReserveBalances(ctx, token))
+	app.BondsKeeper.RecordHolders(ctx, 10)
+
+	// Quarantined bonds are not swept
+	app.BondsKeeper.SetBondState(ctx, token, types.QuarantineState)
+	app.BondsKeeper.SweepUnclaimedReserves(ctx)
+	require.Equal(t, reserve, app.BondsKeeper.GetReserveBalances(ctx, token))
+
+	// Settling the quarantined bond again replaces its claim deadline, so it
+	// is only swept at the end of the new claim window
+	ctx = ctx.WithBlockHeight(61)
+	app.BondsKeeper.SetBondState(ctx, token, types.SettleState)
+	snapshot = app.BondsKeeper.SnapshotHolders(ctx, token)
+	require.Equal(t, int64(71), snapshot.ClaimDeadline)
+	app.BondsKeeper.RecordHolders(ctx, 10)
+	app.BondsKeeper.SweepUnclaimedReserves(ctx)
+	require.Equal(t, reserve, app.BondsKeeper.GetReserveBalances(ctx, token))
+
+	ctx = ctx.WithBlockHeight(71)
+	app.BondsKeeper.SweepUnclaimedReserves(ctx)
+	require.True(t, app.BondsKeeper.GetReserveBalances(ctx, token).IsZero())
+	require.Equal(t, reserve, app.BankKeeper.GetCoins(ctx, initFeeAddress))
+	require.Equal(t, types.ClosedState, app.BondsKeeper.MustGetBond(ctx, token).State)
+
+	// The snapshot is kept after the bond is closed
+	_, found := app.BondsKeeper.GetSettlementSnapshot(ctx, token)
+	require.True(t, found)
+}
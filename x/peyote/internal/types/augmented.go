@@ -2,45 +2,267 @@ package types
 
 import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/x/simulation"
+	"math/rand"
 )
 
 // Inspired by work from BlockScience:
 // https://github.com/BlockScience/cadCAD-Tutorials/tree/master/00-Reference-Mechanisms
 
 // value function for a given state (R,S)
-func Invariant(R, S sdk.Dec, kappa int64) sdk.Dec {
-	return S.Power(uint64(kappa)).Quo(R)
+func Invariant(R, S sdk.Dec, kappa sdk.Dec) (sdk.Dec, error) {
+	temp, err := ApproxPower(S, kappa)
+	if err != nil {
+		return sdk.Dec{}, curveEvaluationFailed(err)
+	}
+	return quoDec(temp, R)
 }
 
 // given a value function (parameterized by kappa)
 // and an invariant coeficient V0
 // return Supply S as a function of reserve R
-func Supply(R sdk.Dec, kappa int64, V0 sdk.Dec) sdk.Dec {
-	result, err := (V0.Mul(R)).ApproxRoot(uint64(kappa))
+func Supply(R sdk.Dec, kappa sdk.Dec, V0 sdk.Dec) (sdk.Dec, error) {
+	temp, err := mulDec(V0, R)
 	if err != nil {
-		panic(err)
+		return sdk.Dec{}, err
 	}
-	return result
+	result, err := ApproxRoot(temp, kappa)
+	if err != nil {
+		return sdk.Dec{}, curveEvaluationFailed(err)
+	}
+	return result, nil
 }
 
 // This is the reverse of Supply(...) function
-func Reserve(S sdk.Dec, kappa int64, V0 sdk.Dec) sdk.Dec {
-	return S.Power(uint64(kappa)).Quo(V0)
+func Reserve(S sdk.Dec, kappa sdk.Dec, V0 sdk.Dec) (sdk.Dec, error) {
+	temp, err := ApproxPower(S, kappa)
+	if err != nil {
+		return sdk.Dec{}, curveEvaluationFailed(err)
+	}
+	return quoDec(temp, V0)
 }
 
 // given a value function (parameterized by kappa)
 // and an invariant coeficient V0
 // return a spot price P as a function of reserve R
-func SpotPrice(R sdk.Dec, kappa int64, V0 sdk.Dec) sdk.Dec {
-	kappaDec := sdk.NewInt(kappa).ToDec()
+func SpotPrice(R sdk.Dec, kappa sdk.Dec, V0 sdk.Dec) (sdk.Dec, error) {
+	temp1, err := ApproxRoot(V0, kappa)
+	if err != nil {
+		return sdk.Dec{}, curveEvaluationFailed(err)
+	}
+	temp2, err := ApproxPower(R, kappa.Sub(sdk.OneDec()))
+	if err != nil {
+		return sdk.Dec{}, curveEvaluationFailed(err)
+	}
+	temp3, err := ApproxRoot(temp2, kappa)
+	if err != nil {
+		return sdk.Dec{}, curveEvaluationFailed(err)
+	}
+	temp4, err := mulDec(kappa, temp3)
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+	return quoDec(temp4, temp1)
+}
+
+// augmentedFunction is the augmented bonding curve, which starts off with a
+// hatch phase at a fixed price p0 and then continues along the curve defined
+// by the invariant V0 = S^kappa/R
+type augmentedFunction struct{}
+
+var _ HatchFunction = augmentedFunction{}
+
+func (augmentedFunction) RequiredParams() []string {
+	return []string{"d0", "p0", "theta", "kappa"}
+}
+
+func (augmentedFunction) NoOfReserveTokens() int { return AnyNumberOfReserveTokens }
+
+func (augmentedFunction) ValidateParams(paramsMap map[string]sdk.Dec) error {
+	// Augmented exception 1.1: d0 must be an integer, since it is a token amount
+	// Augmented exception 1.2: d0 != 0, otherwise we run into divisions by zero
+	val, ok := paramsMap["d0"]
+	if !ok {
+		panic("did not find parameter d0 for augmented function")
+	} else if !val.TruncateDec().Equal(val) {
+		return sdkerrors.Wrap(ErrArgumentMustBeInteger, "FunctionParams:d0")
+	} else if !val.IsPositive() {
+		return sdkerrors.Wrap(ErrArgumentMustBePositive, "FunctionParams:d0")
+	}
+
+	// Augmented exception 2: p0 != 0, otherwise we run into divisions by zero
+	val, ok = paramsMap["p0"]
+	if !ok {
+		panic("did not find parameter p0 for augmented function")
+	} else if !val.IsPositive() {
+		return sdkerrors.Wrap(ErrArgumentMustBePositive, "FunctionParams:p0")
+	}
+
+	// Augmented exception 3: theta must be from 0 to 1 (excluding 1)
+	val, ok = paramsMap["theta"]
+	if !ok {
+		panic("did not find parameter theta for augmented function")
+	} else if val.LT(sdk.ZeroDec()) || val.GTE(sdk.OneDec()) {
+		return sdkerrors.Wrapf(ErrArgumentMustBeBetween, "%s argument must be between %s and %s", "FunctionParams:theta", "0", "1")
+	}
+
+	// Augmented exception 4: kappa != 0, otherwise we run into divisions by zero
+	val, ok = paramsMap["kappa"]
+	if !ok {
+		panic("did not find parameter kappa for augmented function")
+	} else if !val.IsPositive() {
+		return sdkerrors.Wrap(ErrArgumentMustBePositive, "FunctionParams:kappa")
+	}
+
+	return nil
+}
+
+func (augmentedFunction) PricesAtSupply(bond Bond, supply sdk.Int) (sdk.DecCoins, error) {
+	args := bond.FunctionParameters.AsMap()
+	x := supply.ToDec()
+
+	// Note: during the hatch phase, this function returns the hatch price
+	// p0 even if the supply argument is greater than the initial supply S0
+	switch bond.State {
+	case HatchState:
+		return bond.GetNewReserveDecCoins(args["p0"]), nil
+	case OpenState:
+		kappa := args["kappa"]
+		res, err := Reserve(x, kappa, args["V0"])
+		if err != nil {
+			return nil, err
+		}
+		// If reserve < 1, default to zero price to avoid calculation issues
+		if res.LT(sdk.OneDec()) {
+			return bond.GetNewReserveDecCoins(sdk.ZeroDec()), nil
+		}
+		spotPrice, err := SpotPrice(res, kappa, args["V0"])
+		if err != nil {
+			return nil, err
+		}
+		return bond.GetNewReserveDecCoins(spotPrice), nil
+	default:
+		return nil, sdkerrors.Wrap(ErrInvalidStateForAction, bond.State)
+	}
+}
+
+func (fn augmentedFunction) CurrentPricesPT(bond Bond, _ sdk.Coins) (sdk.DecCoins, error) {
+	return fn.PricesAtSupply(bond, bond.CurrentSupply.Amount)
+}
+
+func (augmentedFunction) ReserveAtSupply(bond Bond, supply sdk.Int) (sdk.Dec, error) {
+	args := bond.FunctionParameters.AsMap()
+	kappa := args["kappa"]
+	return Reserve(supply.ToDec(), kappa, args["V0"])
+}
+
+func (augmentedFunction) SupplyAtReserve(bond Bond, reserve sdk.Dec) (sdk.Dec, error) {
+	args := bond.FunctionParameters.AsMap()
+	kappa := args["kappa"]
+	return Supply(reserve, kappa, args["V0"])
+}
+
+func (fn augmentedFunction) PricesToMint(bond Bond, mint sdk.Int, reserveBalances sdk.Coins) (sdk.DecCoins, error) {
+	// If hatch phase, use fixed p0 price
+	if bond.State == HatchState {
+		price, err := mulDec(bond.FunctionParameters.AsMap()["p0"], mint.ToDec())
+		if err != nil {
+			return nil, err
+		}
+		return bond.GetNewReserveDecCoins(price), nil
+	}
 
-	temp1, err := V0.ApproxRoot(uint64(kappa))
+	newSupply := bond.CurrentSupply.Amount.Add(mint)
+	reserveAtNewSupply, err := fn.ReserveAtSupply(bond, newSupply)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-	temp2, err := R.Power(uint64(kappa) - 1).ApproxRoot(uint64(kappa))
+	return curvePricesToMint(bond, reserveAtNewSupply, reserveBalances), nil
+}
+
+func (fn augmentedFunction) AmountToMint(bond Bond, prices sdk.DecCoins, reserveBalances sdk.Coins) (sdk.Int, error) {
+	// If hatch phase, use fixed p0 price
+	if bond.State == HatchState {
+		p0 := bond.FunctionParameters.AsMap()["p0"]
+		amount, err := quoDec(commonPrice(bond, prices), p0)
+		if err != nil {
+			return sdk.Int{}, err
+		}
+		return amount.TruncateInt(), nil
+	}
+
+	return curveAmountToMint(bond, fn, prices, reserveBalances)
+}
+
+func (fn augmentedFunction) ReturnsForBurn(bond Bond, burn sdk.Int, reserveBalances sdk.Coins) (sdk.DecCoins, error) {
+	newSupply := bond.CurrentSupply.Amount.Sub(burn)
+	reserveAtNewSupply, err := fn.ReserveAtSupply(bond, newSupply)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-	return (kappaDec.Mul(temp2)).Quo(temp1)
+	return curveReturnsForBurn(bond, reserveAtNewSupply, reserveBalances)
+}
+
+func (augmentedFunction) ReturnsForSwap(bond Bond, _ sdk.Coin, _ string, _ sdk.Coins) (sdk.Coins, sdk.Coin, error) {
+	return swapsNotAvailable(bond)
+}
+
+func (augmentedFunction) ReturnsForBatchSwap(bond Bond, _ sdk.Coin, _ string, _, _ sdk.Coins) (sdk.Coins, sdk.Coin, error) {
+	return swapsNotAvailable(bond)
+}
+
+// The reserve of an augmented bond does not follow the reserve function during
+// the hatch phase, since a fraction theta of each buy goes to the funding pool
+func (augmentedFunction) EnforcesReserveInvariant() bool { return false }
+
+func (augmentedFunction) IsLiquidityPool() bool { return false }
+
+func (augmentedFunction) RandomParams(r *rand.Rand) FunctionParams {
+	d0 := sdk.NewDec(int64(simulation.RandIntBetween(r, 1, 1000000)))
+	p0 := simulation.RandomDecAmount(r, sdk.NewDec(10)).Add(sdk.SmallestDec())
+	theta := simulation.RandomDecAmount(r, sdk.MustNewDecFromStr("0.9")).Add(sdk.SmallestDec())
+	kappa := sdk.NewDec(int64(simulation.RandIntBetween(r, 1, 4)))
+	return FunctionParams{
+		NewFunctionParam("d0", d0),
+		NewFunctionParam("p0", p0),
+		NewFunctionParam("theta", theta),
+		NewFunctionParam("kappa", kappa)}
+}
+
+// HatchParams returns the initial reserve R0, initial supply S0 and invariant
+// V0, which are stored with the bond's parameters for quick access
+func (augmentedFunction) HatchParams(paramsMap map[string]sdk.Dec) (FunctionParams, error) {
+	d0 := paramsMap["d0"]
+	p0 := paramsMap["p0"]
+	theta := paramsMap["theta"]
+	kappa := paramsMap["kappa"]
+
+	R0 := d0.Mul(sdk.OneDec().Sub(theta))
+	S0 := d0.Quo(p0)
+	V0, err := Invariant(R0, S0, kappa)
+	if err != nil {
+		return nil, err
+	}
+	// TODO: consider calculating these on-the-fly, especially R0 and S0
+
+	return FunctionParams{
+		NewFunctionParam("R0", R0),
+		NewFunctionParam("S0", S0),
+		NewFunctionParam("V0", V0),
+	}, nil
+}
+
+// The hatch phase ends once the initial supply S0 is reached. Note that a bond
+// never starts off open, since S0=d0/p0 and d0>0
+func (augmentedFunction) HatchSupply(bond Bond) sdk.Dec {
+	return bond.FunctionParameters.AsMap()["S0"]
+}
+
+// During the hatch phase, a fraction 1-theta of the total raise p0*supply goes
+// to the reserve and the rest goes to the funding pool
+func (augmentedFunction) HatchReserve(bond Bond, supply sdk.Int) sdk.Dec {
+	args := bond.FunctionParameters.AsMap()
+	totalRaise := args["p0"].Mul(supply.ToDec())
+	return totalRaise.Mul(sdk.OneDec().Sub(args["theta"]))
 }
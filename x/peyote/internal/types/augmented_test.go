@@ -41,8 +41,8 @@ func TestExample1(t *testing.T) {
 	R0 := d0.Mul(sdk.OneDec().Sub(theta)) // initial reserve (raise minus funding)
 	S0 := d0.Quo(p0)                      // initial supply
 
-	kappa := int64(3)              // price exponent
-	V0 := Invariant(R0, S0, kappa) // invariant
+	kappa := sdk.NewDec(3)            // price exponent
+	V0, _ := Invariant(R0, S0, kappa) // invariant
 
 	expectedR0 := sdk.MustNewDecFromStr("300.0")
 	expectedS0 := sdk.MustNewDecFromStr("50000.0")
@@ -60,12 +60,12 @@ func TestExample1(t *testing.T) {
 
 	supp := make([]sdk.Dec, len(reserve))
 	for i, r := range reserve {
-		supp[i] = Supply(r, kappa, V0)
+		supp[i], _ = Supply(r, kappa, V0)
 	}
 
 	price := make([]sdk.Dec, len(reserve))
 	for i, r := range reserve {
-		price[i] = SpotPrice(r, kappa, V0)
+		price[i], _ = SpotPrice(r, kappa, V0)
 	}
 
 	printLines("reserve", reserve)
@@ -77,19 +77,21 @@ func TestReserve(t *testing.T) {
 	decimals := sdk.NewDec(100000) // 10^5
 	testCases := []struct {
 		reserve sdk.Dec
-		kappa   int64
+		kappa   sdk.Dec
 		V0      sdk.Dec
 	}{
-		{sdk.MustNewDecFromStr("0.05"), 1, sdk.MustNewDecFromStr("12345678.12345678")},
-		{sdk.MustNewDecFromStr("5"), 2, sdk.MustNewDecFromStr("123456.123456")},
-		{sdk.MustNewDecFromStr("500.500"), 3, sdk.MustNewDecFromStr("50000.50000")},
-		{sdk.MustNewDecFromStr("50000.50000"), 4, sdk.MustNewDecFromStr("500.500")},
-		{sdk.MustNewDecFromStr("123456.123456"), 5, sdk.MustNewDecFromStr("5")},
-		{sdk.MustNewDecFromStr("12345678.12345678"), 6, sdk.MustNewDecFromStr("0.05")},
+		{sdk.MustNewDecFromStr("0.05"), sdk.NewDec(1), sdk.MustNewDecFromStr("12345678.12345678")},
+		{sdk.MustNewDecFromStr("5"), sdk.NewDec(2), sdk.MustNewDecFromStr("123456.123456")},
+		{sdk.MustNewDecFromStr("500.500"), sdk.NewDec(3), sdk.MustNewDecFromStr("50000.50000")},
+		{sdk.MustNewDecFromStr("50000.50000"), sdk.NewDec(4), sdk.MustNewDecFromStr("500.500")},
+		{sdk.MustNewDecFromStr("123456.123456"), sdk.NewDec(5), sdk.MustNewDecFromStr("5")},
+		{sdk.MustNewDecFromStr("12345678.12345678"), sdk.NewDec(6), sdk.MustNewDecFromStr("0.05")},
 	}
 	for _, tc := range testCases {
-		calculatedSupply := Supply(tc.reserve, tc.kappa, tc.V0)
-		calculatedReserve := Reserve(calculatedSupply, tc.kappa, tc.V0)
+		calculatedSupply, err := Supply(tc.reserve, tc.kappa, tc.V0)
+		require.Nil(t, err)
+		calculatedReserve, err := Reserve(calculatedSupply, tc.kappa, tc.V0)
+		require.Nil(t, err)
 
 		tc.reserve = tc.reserve.Mul(decimals).TruncateDec()
 		calculatedReserve = calculatedReserve.Mul(decimals).TruncateDec()
@@ -97,3 +99,25 @@ func TestReserve(t *testing.T) {
 		require.Equal(t, tc.reserve, calculatedReserve)
 	}
 }
+
+func TestReserveFractionalKappa(t *testing.T) {
+	tolerance := sdk.NewDecWithPrec(1, 12)
+	testCases := []struct {
+		reserve sdk.Dec
+		kappa   sdk.Dec
+		V0      sdk.Dec
+	}{
+		{sdk.MustNewDecFromStr("5"), sdk.MustNewDecFromStr("0.5"), sdk.MustNewDecFromStr("123456.123456")},
+		{sdk.MustNewDecFromStr("500.500"), sdk.MustNewDecFromStr("2.5"), sdk.MustNewDecFromStr("50000.50000")},
+		{sdk.MustNewDecFromStr("50000.50000"), sdk.MustNewDecFromStr("1.75"), sdk.MustNewDecFromStr("500.500")},
+	}
+	for _, tc := range testCases {
+		calculatedSupply, err := Supply(tc.reserve, tc.kappa, tc.V0)
+		require.Nil(t, err)
+		calculatedReserve, err := Reserve(calculatedSupply, tc.kappa, tc.V0)
+		require.Nil(t, err)
+
+		diff := calculatedReserve.Sub(tc.reserve).Abs()
+		require.True(t, diff.LTE(tc.reserve.Mul(tolerance)))
+	}
+}
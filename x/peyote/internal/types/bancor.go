@@ -0,0 +1,217 @@
+package types
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/x/simulation"
+	"math/rand"
+)
+
+// bancorFunction is the Bancor v1 style constant reserve ratio (CRR) curve,
+// where the price is defined by the live reserve balance R, the current
+// supply S, and the reserve ratio (connector weight) crr as y = R/(S*crr).
+// Until the bond has a supply and reserve, tokens are priced such that the
+// price after the first mint is p0.
+type bancorFunction struct{}
+
+var _ BondingFunction = bancorFunction{}
+
+func (bancorFunction) RequiredParams() []string { return []string{"crr", "p0"} }
+
+func (bancorFunction) NoOfReserveTokens() int { return AnyNumberOfReserveTokens }
+
+func (bancorFunction) ValidateParams(paramsMap map[string]sdk.Dec) error {
+	// Bancor exception 1: 0 < crr <= 1
+	crr, ok := paramsMap["crr"]
+	if !ok {
+		return sdkerrors.Wrap(ErrFunctionParameterMissingOrNonFloat, "crr")
+	} else if !crr.IsPositive() || crr.GT(sdk.OneDec()) {
+		return sdkerrors.Wrap(ErrArgumentMustBeBetween, "FunctionParams:crr must be between 0 (excl.) and 1 (incl.)")
+	}
+
+	// Bancor exception 2: p0 != 0, otherwise the first mint would be free
+	p0, ok := paramsMap["p0"]
+	if !ok {
+		return sdkerrors.Wrap(ErrFunctionParameterMissingOrNonFloat, "p0")
+	} else if !p0.IsPositive() {
+		return sdkerrors.Wrap(ErrArgumentMustBePositive, "FunctionParams:p0")
+	}
+
+	return nil
+}
+
+// bancorReserveAtSupply returns the reserve that backs the specified supply,
+// given the live reserve balance. If the bond has no supply or reserve yet,
+// the reserve is crr*p0*supply, so that the price at that supply is p0.
+func bancorReserveAtSupply(bond Bond, supply sdk.Int, reserve sdk.Dec) (sdk.Dec, error) {
+	args := bond.FunctionParameters.AsMap()
+	crr := args["crr"]
+	p0 := args["p0"]
+	currentSupply := bond.CurrentSupply.Amount
+
+	if currentSupply.IsZero() || !reserve.IsPositive() {
+		temp, err := mulDec(supply.ToDec(), p0)
+		if err != nil {
+			return sdk.Dec{}, err
+		}
+		return mulDec(temp, crr)
+	}
+
+	// R' = R * (S'/S)^(1/crr)
+	ratio, err := quoDec(supply.ToDec(), currentSupply.ToDec())
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+	temp, err := ApproxRoot(ratio, crr)
+	if err != nil {
+		return sdk.Dec{}, curveEvaluationFailed(err)
+	}
+	return mulDec(reserve, temp)
+}
+
+// bancorSupplyAtReserve returns the supply backed by the specified reserve,
+// given the live reserve balance, i.e. the inverse of bancorReserveAtSupply.
+func bancorSupplyAtReserve(bond Bond, reserveAtSupply sdk.Dec, reserve sdk.Dec) (sdk.Dec, error) {
+	args := bond.FunctionParameters.AsMap()
+	crr := args["crr"]
+	p0 := args["p0"]
+	currentSupply := bond.CurrentSupply.Amount
+
+	if currentSupply.IsZero() || !reserve.IsPositive() {
+		temp, err := mulDec(p0, crr)
+		if err != nil {
+			return sdk.Dec{}, err
+		}
+		return quoDec(reserveAtSupply, temp)
+	}
+
+	// S' = S * (R'/R)^crr
+	ratio, err := quoDec(reserveAtSupply, reserve)
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+	temp, err := ApproxPower(ratio, crr)
+	if err != nil {
+		return sdk.Dec{}, curveEvaluationFailed(err)
+	}
+	return mulDec(currentSupply.ToDec(), temp)
+}
+
+func bancorPrice(bond Bond, reserve sdk.Dec) (sdk.Dec, error) {
+	args := bond.FunctionParameters.AsMap()
+	crr := args["crr"]
+	currentSupply := bond.CurrentSupply.Amount
+
+	if currentSupply.IsZero() || !reserve.IsPositive() {
+		return args["p0"], nil
+	}
+
+	// P = R / (S * crr)
+	return bancorPriceAt(reserve, currentSupply, crr)
+}
+
+// bancorPriceAt returns R / (S * crr) for a non-zero supply S.
+func bancorPriceAt(reserve sdk.Dec, supply sdk.Int, crr sdk.Dec) (sdk.Dec, error) {
+	temp, err := mulDec(supply.ToDec(), crr)
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+	return quoDec(reserve, temp)
+}
+
+// PricesAtSupply returns the price at the specified supply. At zero supply,
+// the price is p0, which is also the current price of a bond without supply.
+func (bancorFunction) PricesAtSupply(bond Bond, supply sdk.Int) (sdk.DecCoins, error) {
+	args := bond.FunctionParameters.AsMap()
+	crr := args["crr"]
+	if supply.IsZero() {
+		return bond.GetNewReserveDecCoins(args["p0"]), nil
+	}
+
+	// P(S') = R(S') / (S' * crr)
+	reserve, err := bancorReserveAtSupply(bond, supply, commonReserveBalance(bond.CurrentReserve))
+	if err != nil {
+		return nil, err
+	}
+	price, err := bancorPriceAt(reserve, supply, crr)
+	if err != nil {
+		return nil, err
+	}
+	return bond.GetNewReserveDecCoins(price), nil
+}
+
+func (bancorFunction) CurrentPricesPT(bond Bond, reserveBalances sdk.Coins) (sdk.DecCoins, error) {
+	price, err := bancorPrice(bond, commonReserveBalance(reserveBalances))
+	if err != nil {
+		return nil, err
+	}
+	return bond.GetNewReserveDecCoins(price), nil
+}
+
+func (bancorFunction) ReserveAtSupply(bond Bond, supply sdk.Int) (sdk.Dec, error) {
+	return bancorReserveAtSupply(bond, supply, commonReserveBalance(bond.CurrentReserve))
+}
+
+func (bancorFunction) SupplyAtReserve(bond Bond, reserve sdk.Dec) (sdk.Dec, error) {
+	return bancorSupplyAtReserve(bond, reserve, commonReserveBalance(bond.CurrentReserve))
+}
+
+func (bancorFunction) PricesToMint(bond Bond, mint sdk.Int, reserveBalances sdk.Coins) (sdk.DecCoins, error) {
+	// Cost = R * ((1 + mint/S)^(1/crr) - 1)
+	newSupply := bond.CurrentSupply.Amount.Add(mint)
+	reserve := commonReserveBalance(reserveBalances)
+	reserveAtNewSupply, err := bancorReserveAtSupply(bond, newSupply, reserve)
+	if err != nil {
+		return nil, err
+	}
+	return curvePricesToMint(bond, reserveAtNewSupply, reserveBalances), nil
+}
+
+func (bancorFunction) AmountToMint(bond Bond, prices sdk.DecCoins, reserveBalances sdk.Coins) (sdk.Int, error) {
+	// Mint = S * ((1 + cost/R)^crr - 1)
+	price := commonPrice(bond, prices)
+	if !price.IsPositive() {
+		return sdk.ZeroInt(), nil
+	}
+	reserve := commonReserveBalance(reserveBalances)
+	newSupply, err := bancorSupplyAtReserve(bond, reserve.Add(price), reserve)
+	if err != nil {
+		return sdk.Int{}, err
+	}
+	mint := newSupply.TruncateInt().Sub(bond.CurrentSupply.Amount)
+	if mint.IsNegative() {
+		return sdk.ZeroInt(), nil
+	}
+	return mint, nil
+}
+
+func (bancorFunction) ReturnsForBurn(bond Bond, burn sdk.Int, reserveBalances sdk.Coins) (sdk.DecCoins, error) {
+	// Return = R * (1 - (1 - burn/S)^(1/crr))
+	newSupply := bond.CurrentSupply.Amount.Sub(burn)
+	reserve := commonReserveBalance(reserveBalances)
+	reserveAtNewSupply, err := bancorReserveAtSupply(bond, newSupply, reserve)
+	if err != nil {
+		return nil, err
+	}
+	return curveReturnsForBurn(bond, reserveAtNewSupply, reserveBalances)
+}
+
+func (bancorFunction) ReturnsForSwap(bond Bond, _ sdk.Coin, _ string, _ sdk.Coins) (sdk.Coins, sdk.Coin, error) {
+	return swapsNotAvailable(bond)
+}
+
+func (bancorFunction) ReturnsForBatchSwap(bond Bond, _ sdk.Coin, _ string, _, _ sdk.Coins) (sdk.Coins, sdk.Coin, error) {
+	return swapsNotAvailable(bond)
+}
+
+func (bancorFunction) EnforcesReserveInvariant() bool { return true }
+
+func (bancorFunction) IsLiquidityPool() bool { return false }
+
+func (bancorFunction) RandomParams(r *rand.Rand) FunctionParams {
+	crr := sdk.NewDecWithPrec(int64(simulation.RandIntBetween(r, 1, 11)), 1)
+	p0 := sdk.NewDec(int64(simulation.RandIntBetween(r, 1, 10)))
+	return FunctionParams{
+		NewFunctionParam("crr", crr),
+		NewFunctionParam("p0", p0)}
+}
@@ -0,0 +1,106 @@
+package types
+
+import (
+	"errors"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func getValidBancorFunctionBond(supply, reserve int64) Bond {
+	bond := getValidBond()
+	bond.FunctionType = BancorFunction
+	bond.FunctionParameters = functionParametersBancor()
+	bond.CurrentSupply = sdk.NewInt64Coin(bond.Token, supply)
+	bond.CurrentReserve = sdk.NewCoins(sdk.NewInt64Coin(reserveToken, reserve))
+	return bond
+}
+
+func TestBancorPricesWithoutSupply(t *testing.T) {
+	bond := getValidBancorFunctionBond(0, 0)
+	reserveBalances := sdk.Coins(nil)
+
+	// Before any supply, the price is p0
+	prices, err := bond.GetCurrentPricesPT(reserveBalances)
+	require.Nil(t, err)
+	require.Equal(t, sdk.NewDec(2), prices.AmountOf(reserveToken))
+	prices, err = bond.GetPricesAtSupply(sdk.ZeroInt())
+	require.Nil(t, err)
+	require.Equal(t, sdk.NewDec(2), prices.AmountOf(reserveToken))
+
+	// First mint costs crr*p0*mint, so that the price after minting is p0
+	prices, err = bond.GetPricesToMint(sdk.NewInt(100), reserveBalances)
+	require.Nil(t, err)
+	require.Equal(t, sdk.NewDec(100), prices.AmountOf(reserveToken))
+}
+
+func TestBancorPricesWithSupply(t *testing.T) {
+	// Price = R/(S*crr) = 100/(100*0.5) = 2
+	bond := getValidBancorFunctionBond(100, 100)
+	reserveBalances := bond.CurrentReserve
+
+	prices, err := bond.GetCurrentPricesPT(reserveBalances)
+	require.Nil(t, err)
+	require.Equal(t, sdk.NewDec(2), prices.AmountOf(reserveToken))
+
+	prices, err = bond.GetPricesAtSupply(bond.CurrentSupply.Amount)
+	require.Nil(t, err)
+	require.Equal(t, sdk.NewDec(2), prices.AmountOf(reserveToken))
+
+	// Reserve at current supply is the live reserve
+	reserve, err := bond.ReserveAtSupply(bond.CurrentSupply.Amount)
+	require.Nil(t, err)
+	require.Equal(t, sdk.NewDec(100), reserve)
+
+	// Cost = R*((1+mint/S)^(1/crr)-1) = 100*((1+100/100)^2-1) = 300
+	prices, err = bond.GetPricesToMint(sdk.NewInt(100), reserveBalances)
+	require.Nil(t, err)
+	require.Equal(t, sdk.NewDec(300), prices.AmountOf(reserveToken))
+
+	// Return = R*(1-(1-burn/S)^(1/crr)) = 100*(1-(1-50/100)^2) = 75
+	returns, err := bond.GetReturnsForBurn(sdk.NewInt(50), reserveBalances)
+	require.Nil(t, err)
+	require.Equal(t, sdk.NewDec(75), returns.AmountOf(reserveToken))
+
+	// Burning the whole supply returns the whole reserve
+	returns, err = bond.GetReturnsForBurn(sdk.NewInt(100), reserveBalances)
+	require.Nil(t, err)
+	require.Equal(t, sdk.NewDec(100), returns.AmountOf(reserveToken))
+}
+
+func TestBancorValidateParamsMissingParamGivesError(t *testing.T) {
+	params := functionParametersBancor().AsMap()
+	delete(params, "crr")
+
+	err := bancorFunction{}.ValidateParams(params)
+	require.Error(t, err)
+	require.True(t, errors.Is(err, ErrFunctionParameterMissingOrNonFloat))
+}
+
+func TestBancorSwapsNotAvailable(t *testing.T) {
+	bond := getValidBancorFunctionBond(100, 100)
+	_, _, err := bond.GetReturnsForSwap(
+		sdk.NewInt64Coin(reserveToken, 10), reserveToken2, bond.CurrentReserve)
+	require.Error(t, err)
+}
+
+func TestBancorAmountToMint(t *testing.T) {
+	// Before any supply, 100 (=crr*p0*mint) mints 100 tokens
+	bond := getValidBancorFunctionBond(0, 0)
+	prices := sdk.NewDecCoinsFromCoins(sdk.NewInt64Coin(reserveToken, 100))
+	amount, err := bond.GetAmountToMint(prices, nil)
+	require.Nil(t, err)
+	require.Equal(t, sdk.NewInt(100), amount)
+
+	// Mint = S*((1+cost/R)^crr-1) = 100*((1+300/100)^0.5-1) = 100
+	bond = getValidBancorFunctionBond(100, 100)
+	prices = sdk.NewDecCoinsFromCoins(sdk.NewInt64Coin(reserveToken, 300))
+	amount, err = bond.GetAmountToMint(prices, bond.CurrentReserve)
+	require.Nil(t, err)
+	require.Equal(t, sdk.NewInt(100), amount)
+
+	// Supply at the live reserve is the current supply
+	supply, err := bond.SupplyAtReserve(sdk.NewDec(100))
+	require.Nil(t, err)
+	require.Equal(t, sdk.NewDec(100), supply)
+}
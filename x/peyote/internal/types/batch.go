@@ -4,9 +4,26 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
+const (
+	CommitPhase = "COMMIT"
+	RevealPhase = "REVEAL"
+)
+
+// Batch is the set of orders for a bond that are performed together at the end
+// of the batch's execution height. For a bond that accepts order commitments,
+// the batch is in the reveal phase for its final RevealBlocks blocks, and in
+// the commit phase before that. The bond's BatchBlocks and RevealBlocks are
+// kept in the batch so that its schedule can be updated without reading the
+// bond. The orders of a bond's current batch are stored
+// separately from the rest of the batch (i.e. the batch header). The order
+// count excludes cancelled orders and outstanding order commitments.
 type Batch struct {
 	Token           string       `json:"token" yaml:"token"`
-	BlocksRemaining sdk.Uint     `json:"blocks_remaining" yaml:"blocks_remaining"`
+	ExecutionHeight int64        `json:"execution_height" yaml:"execution_height"`
+	Phase           string       `json:"phase" yaml:"phase"`
+	BatchBlocks     sdk.Uint     `json:"batch_blocks" yaml:"batch_blocks"`
+	RevealBlocks    sdk.Uint     `json:"reveal_blocks" yaml:"reveal_blocks"`
+	OrderCount      uint64       `json:"order_count" yaml:"order_count"`
 	TotalBuyAmount  sdk.Coin     `json:"total_buy_amount" yaml:"total_buy_amount"`
 	TotalSellAmount sdk.Coin     `json:"total_sell_amount" yaml:"total_sell_amount"`
 	BuyPrices       sdk.DecCoins `json:"buy_prices" yaml:"buy_prices"`
@@ -19,18 +36,112 @@ type Batch struct {
 func (b Batch) MoreBuysThanSells() bool { return b.TotalSellAmount.IsLT(b.TotalBuyAmount) }
 func (b Batch) MoreSellsThanBuys() bool { return b.TotalBuyAmount.IsLT(b.TotalSellAmount) }
 func (b Batch) EqualBuysAndSells() bool { return b.TotalBuyAmount.IsEqual(b.TotalSellAmount) }
+func (b Batch) IsInRevealPhase() bool   { return b.Phase == RevealPhase }
+
+// GetVolume returns the total amount of bond tokens bought and sold in the batch
+func (b Batch) GetVolume() sdk.Int {
+	return b.TotalBuyAmount.Amount.Add(b.TotalSellAmount.Amount)
+}
+
+// BlocksRemaining returns the number of blocks, including the block at the
+// specified height, at the end of which the batch has not yet been performed.
+func (b Batch) BlocksRemaining(height int64) int64 {
+	return b.ExecutionHeight - height + 1
+}
+
+// UpdateSchedule returns the batch with its execution height and phase as at
+// the specified height. A batch without any orders is not performed, so its
+// execution height is moved forward by whole batch periods until it is not
+// before the specified height. The batch is in the reveal phase if at most
+// RevealBlocks blocks remain (including the one at the specified height).
+func (b Batch) UpdateSchedule(height int64) Batch {
+	if b.ExecutionHeight < height {
+		period := int64(b.BatchBlocks.Uint64())
+		periods := (height - b.ExecutionHeight + period - 1) / period
+		b.ExecutionHeight += periods * period
+	}
 
-func NewBatch(token string, blocks sdk.Uint) Batch {
+	if !b.RevealBlocks.IsZero() && b.BlocksRemaining(height) <= int64(b.RevealBlocks.Uint64()) {
+		b.Phase = RevealPhase
+	} else {
+		b.Phase = CommitPhase
+	}
+	return b
+}
+
+func NewBatch(token string, executionHeight int64, batchBlocks, revealBlocks sdk.Uint) Batch {
 	return Batch{
 		Token:           token,
-		BlocksRemaining: blocks,
+		ExecutionHeight: executionHeight,
+		Phase:           CommitPhase,
+		BatchBlocks:     batchBlocks,
+		RevealBlocks:    revealBlocks,
 		TotalBuyAmount:  sdk.NewInt64Coin(token, 0),
 		TotalSellAmount: sdk.NewInt64Coin(token, 0),
 	}
 }
 
+// BatchRecord summarises a batch that was performed, as kept in the bond's
+// batch history. The prices are those at which the batch's buys and sells were
+// performed, and the total amounts exclude any orders that were cancelled.
+type BatchRecord struct {
+	Token           string       `json:"token" yaml:"token"`
+	Height          int64        `json:"height" yaml:"height"`
+	BuyPrices       sdk.DecCoins `json:"buy_prices" yaml:"buy_prices"`
+	SellPrices      sdk.DecCoins `json:"sell_prices" yaml:"sell_prices"`
+	TotalBuyAmount  sdk.Coin     `json:"total_buy_amount" yaml:"total_buy_amount"`
+	TotalSellAmount sdk.Coin     `json:"total_sell_amount" yaml:"total_sell_amount"`
+	TotalSwapAmount sdk.Coins    `json:"total_swap_amount" yaml:"total_swap_amount"`
+	Buys            uint64       `json:"buys" yaml:"buys"`
+	Sells           uint64       `json:"sells" yaml:"sells"`
+	Swaps           uint64       `json:"swaps" yaml:"swaps"`
+	CancelledBuys   uint64       `json:"cancelled_buys" yaml:"cancelled_buys"`
+	CancelledSells  uint64       `json:"cancelled_sells" yaml:"cancelled_sells"`
+	CancelledSwaps  uint64       `json:"cancelled_swaps" yaml:"cancelled_swaps"`
+}
+
+// NewBatchRecord returns the record of a batch that was performed at the end
+// of its execution height, including all of its orders.
+func NewBatchRecord(batch Batch) BatchRecord {
+	record := BatchRecord{
+		Token:           batch.Token,
+		Height:          batch.ExecutionHeight,
+		BuyPrices:       batch.BuyPrices,
+		SellPrices:      batch.SellPrices,
+		TotalBuyAmount:  batch.TotalBuyAmount,
+		TotalSellAmount: batch.TotalSellAmount,
+		Buys:            uint64(len(batch.Buys)),
+		Sells:           uint64(len(batch.Sells)),
+		Swaps:           uint64(len(batch.Swaps)),
+	}
+	for _, o := range batch.Buys {
+		if o.IsCancelled() {
+			record.CancelledBuys++
+		}
+	}
+	for _, o := range batch.Sells {
+		if o.IsCancelled() {
+			record.CancelledSells++
+		}
+	}
+	for _, o := range batch.Swaps {
+		if o.IsCancelled() {
+			record.CancelledSwaps++
+		} else {
+			record.TotalSwapAmount = record.TotalSwapAmount.Add(o.Amount)
+		}
+	}
+	return record
+}
+
+// BaseOrder contains the fields common to all orders in a batch. The ID is
+// assigned when the order is added to a batch and is unique across batches.
+// The recipient, if any, receives the tokens bought, or the returns of a sell
+// or swap, in place of the order's address, which is refunded if cancelled.
 type BaseOrder struct {
+	Id           uint64         `json:"id" yaml:"id"`
 	Address      sdk.AccAddress `json:"address" yaml:"address"`
+	Recipient    sdk.AccAddress `json:"recipient,omitempty" yaml:"recipient"`
 	Amount       sdk.Coin       `json:"amount" yaml:"amount"`
 	Cancelled    bool           `json:"cancelled" yaml:"cancelled"`
 	CancelReason string         `json:"cancel_reason" yaml:"cancel_reason"`
@@ -49,36 +160,90 @@ func (bo BaseOrder) IsCancelled() bool {
 	return bo.Cancelled == true
 }
 
+// GetRecipient returns the address that receives the tokens bought, or the
+// returns of a sell or swap, which is the order's address unless the order
+// specifies a different recipient
+func (bo BaseOrder) GetRecipient() sdk.AccAddress {
+	if bo.Recipient.Empty() {
+		return bo.Address
+	}
+	return bo.Recipient
+}
+
+// BuyOrder is an order to buy an amount of bond tokens for at most the max
+// prices. For a spend buy, the max prices are the reserve tokens that the
+// buyer wants to spend. If the max prices can no longer pay for the amount at
+// the batch prices, the amount is reduced (partially filling the order) and
+// the requested amount keeps track of the amount originally ordered.
 type BuyOrder struct {
 	BaseOrder
-	MaxPrices sdk.Coins `json:"max_prices" yaml:"max_prices"`
+	MaxPrices       sdk.Coins `json:"max_prices" yaml:"max_prices"`
+	Spend           bool      `json:"spend" yaml:"spend"`
+	RequestedAmount sdk.Coin  `json:"requested_amount" yaml:"requested_amount"`
 }
 
 func NewBuyOrder(address sdk.AccAddress, amount sdk.Coin, maxPrices sdk.Coins) BuyOrder {
 	return BuyOrder{
-		BaseOrder: NewBaseOrder(address, amount),
-		MaxPrices: maxPrices,
+		BaseOrder:       NewBaseOrder(address, amount),
+		MaxPrices:       maxPrices,
+		RequestedAmount: amount,
+	}
+}
+
+func NewSpendBuyOrder(address sdk.AccAddress, amount sdk.Coin, spend sdk.Coins) BuyOrder {
+	return BuyOrder{
+		BaseOrder:       NewBaseOrder(address, amount),
+		MaxPrices:       spend,
+		Spend:           true,
+		RequestedAmount: amount,
 	}
 }
 
+// SellOrder is an order to sell a fixed amount of bond tokens. If min returns
+// are specified, the order is cancelled if the returns after fees are less.
 type SellOrder struct {
 	BaseOrder
+	MinReturns sdk.Coins `json:"min_returns" yaml:"min_returns"`
 }
 
-func NewSellOrder(address sdk.AccAddress, amount sdk.Coin) SellOrder {
+func NewSellOrder(address sdk.AccAddress, amount sdk.Coin, minReturns sdk.Coins) SellOrder {
 	return SellOrder{
-		BaseOrder: NewBaseOrder(address, amount),
+		BaseOrder:  NewBaseOrder(address, amount),
+		MinReturns: minReturns,
 	}
 }
 
+// SwapOrder is an order to swap a fixed amount of one reserve token for another.
+// If min returns are specified, the order is cancelled if the returns are less.
 type SwapOrder struct {
 	BaseOrder
-	ToToken string `json:"to_token" yaml:"to_token"`
+	ToToken    string    `json:"to_token" yaml:"to_token"`
+	MinReturns sdk.Coins `json:"min_returns" yaml:"min_returns"`
 }
 
-func NewSwapOrder(address sdk.AccAddress, from sdk.Coin, toToken string) SwapOrder {
+func NewSwapOrder(address sdk.AccAddress, from sdk.Coin, toToken string, minReturns sdk.Coins) SwapOrder {
 	return SwapOrder{
-		BaseOrder: NewBaseOrder(address, from),
-		ToToken:   toToken,
+		BaseOrder:  NewBaseOrder(address, from),
+		ToToken:    toToken,
+		MinReturns: minReturns,
 	}
 }
+
+// RolledOverOrders are a bond's orders that did not fit in the bond's batch
+// when they were placed, and that are added to the following batches by
+// ascending order ID as soon as there is room for them.
+type RolledOverOrders struct {
+	Token string      `json:"token" yaml:"token"`
+	Buys  []BuyOrder  `json:"buys" yaml:"buys"`
+	Sells []SellOrder `json:"sells" yaml:"sells"`
+	Swaps []SwapOrder `json:"swaps" yaml:"swaps"`
+}
+
+func NewRolledOverOrders(token string) RolledOverOrders {
+	return RolledOverOrders{Token: token}
+}
+
+// IsEmpty indicates whether there are no rolled-over buys, sells or swaps
+func (r RolledOverOrders) IsEmpty() bool {
+	return len(r.Buys) == 0 && len(r.Sells) == 0 && len(r.Swaps) == 0
+}
@@ -10,7 +10,7 @@ import (
 func TestMoreEqualBuysSells(t *testing.T) {
 	zero := sdk.NewInt64Coin("token", 0)
 	one := sdk.NewInt64Coin("token", 1)
-	batch := NewBatch("token", sdk.OneUint())
+	batch := NewBatch("token", 1, sdk.OneUint(), sdk.ZeroUint())
 	testCases := []struct {
 		buys      sdk.Coin
 		sells     sdk.Coin
@@ -32,6 +32,32 @@ func TestMoreEqualBuysSells(t *testing.T) {
 	}
 }
 
+func TestBatchUpdateSchedule(t *testing.T) {
+	batchBlocks := sdk.NewUint(5)
+	revealBlocks := sdk.NewUint(2)
+	testCases := []struct {
+		executionHeight int64
+		height          int64
+		revealBlocks    sdk.Uint
+		expectedHeight  int64
+		expectedPhase   string
+	}{
+		{10, 7, revealBlocks, 10, CommitPhase},    // 4 blocks remain
+		{10, 9, revealBlocks, 10, RevealPhase},    // 2 blocks remain
+		{10, 10, revealBlocks, 10, RevealPhase},   // 1 block remains
+		{10, 11, revealBlocks, 15, CommitPhase},   // moved forward by 1 period
+		{10, 15, revealBlocks, 15, RevealPhase},   // moved forward by 1 period
+		{10, 16, revealBlocks, 20, CommitPhase},   // moved forward by 2 periods
+		{10, 10, sdk.ZeroUint(), 10, CommitPhase}, // no reveal phase
+	}
+	for i, tc := range testCases {
+		batch := NewBatch("token", tc.executionHeight, batchBlocks, tc.revealBlocks)
+		batch = batch.UpdateSchedule(tc.height)
+		require.Equal(t, tc.expectedHeight, batch.ExecutionHeight, "test case #%d", i)
+		require.Equal(t, tc.expectedPhase, batch.Phase, "test case #%d", i)
+	}
+}
+
 func TestBaseOrderIsCancelled(t *testing.T) {
 	testCases := []struct {
 		order       BaseOrder
@@ -50,11 +76,16 @@ func TestBaseOrderIsCancelled(t *testing.T) {
 
 func TestNewBatchDefaultValues(t *testing.T) {
 	token := "token"
-	blocksRemaining := sdk.NewUint(100)
-	batch := NewBatch(token, blocksRemaining)
+	executionHeight := int64(100)
+	batchBlocks := sdk.NewUint(5)
+	revealBlocks := sdk.NewUint(2)
+	batch := NewBatch(token, executionHeight, batchBlocks, revealBlocks)
 
 	require.Equal(t, token, batch.Token)
-	require.Equal(t, blocksRemaining, batch.BlocksRemaining)
+	require.Equal(t, executionHeight, batch.ExecutionHeight)
+	require.Equal(t, CommitPhase, batch.Phase)
+	require.Equal(t, batchBlocks, batch.BatchBlocks)
+	require.Equal(t, revealBlocks, batch.RevealBlocks)
 	require.Equal(t, sdk.NewInt64Coin(token, 0), batch.TotalBuyAmount)
 	require.Equal(t, sdk.NewInt64Coin(token, 0), batch.TotalSellAmount)
 	require.Nil(t, batch.BuyPrices)
@@ -64,6 +95,42 @@ func TestNewBatchDefaultValues(t *testing.T) {
 	require.Nil(t, batch.Swaps)
 }
 
+func TestNewBatchRecord(t *testing.T) {
+	address := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	batch := NewBatch("token", 100, sdk.OneUint(), sdk.ZeroUint())
+	batch.BuyPrices = sdk.NewDecCoins(sdk.NewInt64DecCoin("res", 2))
+	batch.SellPrices = sdk.NewDecCoins(sdk.NewInt64DecCoin("res", 1))
+	batch.TotalBuyAmount = sdk.NewInt64Coin("token", 10)
+	batch.TotalSellAmount = sdk.NewInt64Coin("token", 5)
+
+	// Two buys (one cancelled), one sell, two swaps (one cancelled)
+	cancelledBuy := NewBuyOrder(address, sdk.NewInt64Coin("token", 3), nil)
+	cancelledBuy.Cancelled = true
+	cancelledSwap := NewSwapOrder(address, sdk.NewInt64Coin("res", 4), "rez", nil)
+	cancelledSwap.Cancelled = true
+	batch.Buys = []BuyOrder{
+		NewBuyOrder(address, sdk.NewInt64Coin("token", 10), nil), cancelledBuy}
+	batch.Sells = []SellOrder{
+		NewSellOrder(address, sdk.NewInt64Coin("token", 5), nil)}
+	batch.Swaps = []SwapOrder{
+		NewSwapOrder(address, sdk.NewInt64Coin("res", 7), "rez", nil), cancelledSwap}
+
+	record := NewBatchRecord(batch)
+	require.Equal(t, batch.Token, record.Token)
+	require.Equal(t, batch.ExecutionHeight, record.Height)
+	require.Equal(t, batch.BuyPrices, record.BuyPrices)
+	require.Equal(t, batch.SellPrices, record.SellPrices)
+	require.Equal(t, batch.TotalBuyAmount, record.TotalBuyAmount)
+	require.Equal(t, batch.TotalSellAmount, record.TotalSellAmount)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("res", 7)), record.TotalSwapAmount)
+	require.Equal(t, uint64(2), record.Buys)
+	require.Equal(t, uint64(1), record.Sells)
+	require.Equal(t, uint64(2), record.Swaps)
+	require.Equal(t, uint64(1), record.CancelledBuys)
+	require.Equal(t, uint64(0), record.CancelledSells)
+	require.Equal(t, uint64(1), record.CancelledSwaps)
+}
+
 func TestNewBaseOrderDefaultValues(t *testing.T) {
 	address := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
 	amount := sdk.NewInt64Coin("token", 1000)
@@ -93,7 +160,7 @@ func TestNewBuyOrderDefaultValues(t *testing.T) {
 func TestNewSellOrderDefaultValues(t *testing.T) {
 	address := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
 	amount := sdk.NewInt64Coin("token", 1000)
-	order := NewSellOrder(address, amount)
+	order := NewSellOrder(address, amount, nil)
 
 	require.Equal(t, address, order.Address)
 	require.Equal(t, amount, order.Amount)
@@ -105,7 +172,7 @@ func TestNewSwapOrderDefaultValues(t *testing.T) {
 	address := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
 	fromAmount := sdk.NewInt64Coin("token1", 1000)
 	toToken := "token2"
-	order := NewSwapOrder(address, fromAmount, toToken)
+	order := NewSwapOrder(address, fromAmount, toToken, nil)
 
 	require.Equal(t, address, order.Address)
 	require.Equal(t, fromAmount, order.Amount)
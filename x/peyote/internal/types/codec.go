@@ -21,6 +21,14 @@ func RegisterCodec(cdc *codec.Codec) {
 	cdc.RegisterConcrete(&BuyOrder{}, "peyote/BuyOrder", nil)
 	cdc.RegisterConcrete(&SellOrder{}, "peyote/SellOrder", nil)
 	cdc.RegisterConcrete(&SwapOrder{}, "peyote/SwapOrder", nil)
+	cdc.RegisterConcrete(&LimitOrder{}, "peyote/LimitOrder", nil)
+	cdc.RegisterConcrete(&OrderCommitment{}, "peyote/OrderCommitment", nil)
+	cdc.RegisterConcrete(&RecurringOrder{}, "peyote/RecurringOrder", nil)
+	cdc.RegisterConcrete(&SettlementSnapshot{}, "peyote/SettlementSnapshot", nil)
+	cdc.RegisterConcrete(&HolderSnapshot{}, "peyote/HolderSnapshot", nil)
+	cdc.RegisterConcrete(&ShareDistribution{}, "peyote/ShareDistribution", nil)
+	cdc.RegisterConcrete(&TrancheDistribution{}, "peyote/TrancheDistribution", nil)
+	cdc.RegisterConcrete(&OutcomeTranche{}, "peyote/OutcomeTranche", nil)
 	cdc.RegisterConcrete(MsgCreateBond{}, "peyote/MsgCreateBond", nil)
 	cdc.RegisterConcrete(MsgEditBond{}, "peyote/MsgEditBond", nil)
 	cdc.RegisterConcrete(MsgBuy{}, "peyote/MsgBuy", nil)
@@ -28,4 +36,13 @@ func RegisterCodec(cdc *codec.Codec) {
 	cdc.RegisterConcrete(MsgSwap{}, "peyote/MsgSwap", nil)
 	cdc.RegisterConcrete(MsgMakeOutcomePayment{}, "peyote/MsgMakeOutcomePayment", nil)
 	cdc.RegisterConcrete(MsgWithdrawShare{}, "peyote/MsgWithdrawShare", nil)
+	cdc.RegisterConcrete(MsgSettleBond{}, "peyote/MsgSettleBond", nil)
+	cdc.RegisterConcrete(MsgLimitBuy{}, "peyote/MsgLimitBuy", nil)
+	cdc.RegisterConcrete(MsgLimitSell{}, "peyote/MsgLimitSell", nil)
+	cdc.RegisterConcrete(MsgSpendBuy{}, "peyote/MsgSpendBuy", nil)
+	cdc.RegisterConcrete(MsgCancelOrder{}, "peyote/MsgCancelOrder", nil)
+	cdc.RegisterConcrete(MsgCommitOrder{}, "peyote/MsgCommitOrder", nil)
+	cdc.RegisterConcrete(MsgRevealOrder{}, "peyote/MsgRevealOrder", nil)
+	cdc.RegisterConcrete(MsgCreateRecurringOrder{}, "peyote/MsgCreateRecurringOrder", nil)
+	cdc.RegisterConcrete(MsgCancelRecurringOrder{}, "peyote/MsgCancelRecurringOrder", nil)
 }
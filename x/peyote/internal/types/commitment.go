@@ -0,0 +1,88 @@
+package types
+
+import (
+	"crypto/sha256"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	tmbytes "github.com/tendermint/tendermint/libs/bytes"
+)
+
+// OrderCommitment is a hash of an order that is committed to in the commit
+// phase of a bond's batch, together with an escrow deposit, so that the order
+// is hidden until it is revealed in the batch's reveal phase. The deposit is
+// returned once the order is revealed, and is otherwise either forfeited to
+// the bond's fee address or refunded, depending on the bond.
+type OrderCommitment struct {
+	Id        uint64           `json:"id" yaml:"id"`
+	BondToken string           `json:"bond_token" yaml:"bond_token"`
+	Address   sdk.AccAddress   `json:"address" yaml:"address"`
+	Hash      tmbytes.HexBytes `json:"hash" yaml:"hash"`
+	Deposit   sdk.Coins        `json:"deposit" yaml:"deposit"`
+}
+
+func NewOrderCommitment(bondToken string, address sdk.AccAddress,
+	hash tmbytes.HexBytes, deposit sdk.Coins) OrderCommitment {
+	return OrderCommitment{
+		BondToken: bondToken,
+		Address:   address,
+		Hash:      hash,
+		Deposit:   deposit,
+	}
+}
+
+// RevealedOrder is the order behind an order commitment. The prices are the
+// max prices of a buy or the min returns of a sell or swap, and the to token
+// is only used by swaps. The salt is chosen by the order's owner so that the
+// commitment hash cannot be matched against guessed orders.
+type RevealedOrder struct {
+	OrderType string    `json:"order_type" yaml:"order_type"`
+	Amount    sdk.Coin  `json:"amount" yaml:"amount"`
+	Prices    sdk.Coins `json:"prices" yaml:"prices"`
+	ToToken   string    `json:"to_token" yaml:"to_token"`
+	Salt      string    `json:"salt" yaml:"salt"`
+}
+
+func NewRevealedOrder(orderType string, amount sdk.Coin, prices sdk.Coins,
+	toToken, salt string) RevealedOrder {
+	return RevealedOrder{
+		OrderType: orderType,
+		Amount:    amount,
+		Prices:    prices,
+		ToToken:   toToken,
+		Salt:      salt,
+	}
+}
+
+// GetCommitmentHash returns the hash that commits the address to the order
+// for the specified bond, i.e. the SHA-256 hash of the JSON-encoded address,
+// bond token and order.
+func (o RevealedOrder) GetCommitmentHash(address sdk.AccAddress, bondToken string) tmbytes.HexBytes {
+	bz := sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(struct {
+		Address   sdk.AccAddress `json:"address"`
+		BondToken string         `json:"bond_token"`
+		Order     RevealedOrder  `json:"order"`
+	}{address, bondToken, o}))
+	hash := sha256.Sum256(bz)
+	return hash[:]
+}
+
+// ToMsg returns the buy, sell, or swap message that the order is performed
+// as once it is revealed by the address.
+func (o RevealedOrder) ToMsg(address sdk.AccAddress, bondToken string) (sdk.Msg, error) {
+	switch o.OrderType {
+	case AttributeValueBuyOrder:
+		if o.Amount.Denom != bondToken {
+			return nil, sdkerrors.Wrap(ErrOrderNotForBond, o.Amount.Denom)
+		}
+		return NewMsgBuy(address, o.Amount, o.Prices, nil), nil
+	case AttributeValueSellOrder:
+		if o.Amount.Denom != bondToken {
+			return nil, sdkerrors.Wrap(ErrOrderNotForBond, o.Amount.Denom)
+		}
+		return NewMsgSell(address, o.Amount, o.Prices, nil), nil
+	case AttributeValueSwapOrder:
+		return NewMsgSwap(address, bondToken, o.Amount, o.ToToken, o.Prices, nil), nil
+	default:
+		return nil, sdkerrors.Wrap(ErrInvalidOrderType, o.OrderType)
+	}
+}
@@ -0,0 +1,76 @@
+package types
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"testing"
+)
+
+func TestGetCommitmentHashDependsOnAllFields(t *testing.T) {
+	address := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	otherAddress := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	order := newValidRevealedOrder()
+	hash := order.GetCommitmentHash(address, initToken)
+
+	require.Len(t, hash, 32)
+	require.Equal(t, hash, order.GetCommitmentHash(address, initToken))
+	require.NotEqual(t, hash, order.GetCommitmentHash(otherAddress, initToken))
+	require.NotEqual(t, hash, order.GetCommitmentHash(address, "othertoken"))
+
+	otherSalt := order
+	otherSalt.Salt = "other salt"
+	require.NotEqual(t, hash, otherSalt.GetCommitmentHash(address, initToken))
+
+	otherAmount := order
+	otherAmount.Amount = sdk.NewInt64Coin(initToken, 11)
+	require.NotEqual(t, hash, otherAmount.GetCommitmentHash(address, initToken))
+}
+
+func TestRevealedOrderToMsg(t *testing.T) {
+	address := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	amount := sdk.NewInt64Coin(initToken, 10)
+	prices := sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 50))
+	from := sdk.NewInt64Coin(reserveToken, 10)
+	minReturns := sdk.NewCoins(sdk.NewInt64Coin(reserveToken2, 5))
+
+	testCases := []struct {
+		order    RevealedOrder
+		expected sdk.Msg
+		isValid  bool
+	}{
+		{
+			NewRevealedOrder(AttributeValueBuyOrder, amount, prices, "", "salt"),
+			NewMsgBuy(address, amount, prices, nil), true,
+		},
+		{
+			NewRevealedOrder(AttributeValueSellOrder, amount, prices, "", "salt"),
+			NewMsgSell(address, amount, prices, nil), true,
+		},
+		{
+			NewRevealedOrder(AttributeValueSwapOrder, from, minReturns, reserveToken2, "salt"),
+			NewMsgSwap(address, initToken, from, reserveToken2, minReturns, nil), true,
+		},
+		{
+			NewRevealedOrder(AttributeValueBuyOrder, from, prices, "", "salt"),
+			nil, false, // buy amount not in bond token
+		},
+		{
+			NewRevealedOrder(AttributeValueSellOrder, from, nil, "", "salt"),
+			nil, false, // sell amount not in bond token
+		},
+		{
+			NewRevealedOrder("invalid", amount, prices, "", "salt"),
+			nil, false, // invalid order type
+		},
+	}
+	for _, tc := range testCases {
+		msg, err := tc.order.ToMsg(address, initToken)
+		if tc.isValid {
+			require.Nil(t, err)
+			require.Equal(t, tc.expected, msg)
+		} else {
+			require.NotNil(t, err)
+		}
+	}
+}
@@ -28,7 +28,18 @@ var (
 	initAllowSell              = true
 	initSigners                = []sdk.AccAddress{initCreator}
 	initBatchBlocks            = sdk.NewUint(10)
+	initRevealBlocks           = sdk.ZeroUint()
+	initForfeitUnrevealed      = false
+	initMaxBatchOrders         = sdk.ZeroUint()
+	initMaxBatchVolume         = sdk.ZeroUint()
+	initRollOverOrders         = false
 	initOutcomePayment         = sdk.Coins(nil)
+	initOutcomeTranches        = []OutcomeTranche(nil)
+	initOutcomePayers          = []sdk.AccAddress(nil)
+	initEvaluators             = []sdk.AccAddress(nil)
+	initAutoDistribute         = false
+	initClaimBlocks            = sdk.ZeroUint()
+	initSweepToCommunityPool   = false
 	initState                  = OpenState
 
 	// 9223372036854775807
@@ -63,7 +74,7 @@ func functionParametersAugmentedFull() FunctionParams {
 
 	R0 := baseMap["d0"].Mul(sdk.OneDec().Sub(baseMap["theta"]))
 	S0 := baseMap["d0"].Quo(baseMap["p0"])
-	V0 := Invariant(R0, S0, baseMap["kappa"].TruncateInt64())
+	V0, _ := Invariant(R0, S0, baseMap["kappa"])
 	extras := FunctionParams{
 		NewFunctionParam("R0", R0),
 		NewFunctionParam("S0", S0),
@@ -72,6 +83,12 @@ func functionParametersAugmentedFull() FunctionParams {
 	return append(base, extras...)
 }
 
+func functionParametersBancor() FunctionParams {
+	return FunctionParams{
+		NewFunctionParam("crr", sdk.MustNewDecFromStr("0.5")),
+		NewFunctionParam("p0", sdk.NewDec(2))}
+}
+
 func functionParametersPowerHuge() FunctionParams {
 	return FunctionParams{
 		NewFunctionParam("m", sdk.NewDec(1)),
@@ -98,7 +115,10 @@ func getValidPowerFunctionBond() Bond {
 		functionType, functionParams, reserveTokens, initTxFeePercentage,
 		initExitFeePercentage, initFeeAddress, initMaxSupply,
 		initOrderQuantityLimits, initSanityRate, initSanityMarginPercentage,
-		initAllowSell, initSigners, initBatchBlocks, initOutcomePayment, initState)
+		initAllowSell, initSigners, initBatchBlocks, initRevealBlocks,
+		initForfeitUnrevealed, initMaxBatchOrders, initMaxBatchVolume,
+		initRollOverOrders, initOutcomePayment, initOutcomeTranches,
+		initOutcomePayers, initEvaluators, initAutoDistribute, initClaimBlocks, initSweepToCommunityPool, initState)
 }
 
 func getValidBond() Bond {
@@ -131,7 +151,10 @@ func newValidMsgCreateBond() MsgCreateBond {
 		functionType, functionParams, reserveTokens, initTxFeePercentage,
 		initExitFeePercentage, initFeeAddress, initMaxSupply,
 		initOrderQuantityLimits, initSanityRate, initSanityMarginPercentage,
-		initAllowSell, initSigners, initBatchBlocks, initOutcomePayment)
+		initAllowSell, initSigners, initBatchBlocks, initRevealBlocks,
+		initForfeitUnrevealed, initMaxBatchOrders, initMaxBatchVolume,
+		initRollOverOrders, initOutcomePayment, initOutcomeTranches,
+		initOutcomePayers, initEvaluators, initAutoDistribute, initClaimBlocks, initSweepToCommunityPool)
 }
 
 func newValidMsgCreateSwapperBond() MsgCreateBond {
@@ -156,17 +179,89 @@ func newValidMsgBuy() MsgBuy {
 	buyer := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
 	amount, _ := sdk.ParseCoin("10" + initToken)
 	maxPrices, _ := sdk.ParseCoins("50" + initToken)
-	return NewMsgBuy(buyer, amount, maxPrices)
+	return NewMsgBuy(buyer, amount, maxPrices, nil)
 }
 
 func newValidMsgSell() MsgSell {
 	seller := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
 	amount, _ := sdk.ParseCoin("10" + initToken)
-	return NewMsgSell(seller, amount)
+	return NewMsgSell(seller, amount, nil, nil)
 }
 
 func newValidMsgSwap() MsgSwap {
 	swapper := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
 	from := sdk.NewInt64Coin(reserveToken, 10)
-	return NewMsgSwap(swapper, initToken, from, reserveToken2)
+	return NewMsgSwap(swapper, initToken, from, reserveToken2, nil, nil)
+}
+
+func newValidMsgLimitBuy() MsgLimitBuy {
+	buyer := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	amount, _ := sdk.ParseCoin("10" + initToken)
+	return NewMsgLimitBuy(buyer, amount, sdk.NewDec(5), 100)
+}
+
+func newValidMsgLimitSell() MsgLimitSell {
+	seller := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	amount, _ := sdk.ParseCoin("10" + initToken)
+	return NewMsgLimitSell(seller, amount, sdk.NewDec(5), 100)
+}
+
+func newValidMsgSpendBuy() MsgSpendBuy {
+	buyer := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	spend := sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 1000))
+	return NewMsgSpendBuy(buyer, initToken, spend)
+}
+
+func newValidMsgCancelOrder() MsgCancelOrder {
+	sender := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	return NewMsgCancelOrder(sender, initToken, 1)
+}
+
+func newValidRevealedOrder() RevealedOrder {
+	amount, _ := sdk.ParseCoin("10" + initToken)
+	maxPrices, _ := sdk.ParseCoins("50" + reserveToken)
+	return NewRevealedOrder(AttributeValueBuyOrder, amount, maxPrices, "", "salt")
+}
+
+func newValidMsgCommitOrder() MsgCommitOrder {
+	sender := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	hash := newValidRevealedOrder().GetCommitmentHash(sender, initToken)
+	deposit := sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 10))
+	return NewMsgCommitOrder(sender, initToken, hash, deposit)
+}
+
+func newValidMsgRevealOrder() MsgRevealOrder {
+	sender := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	return NewMsgRevealOrder(sender, initToken, 1, newValidRevealedOrder())
+}
+
+func newValidMsgCreateRecurringOrder() MsgCreateRecurringOrder {
+	owner := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	amount := sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 100))
+	budget := sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 1000))
+	return NewMsgCreateRecurringOrder(owner, initToken, AttributeValueBuyOrder,
+		amount, budget, sdk.NewUint(2), 100)
+}
+
+func newValidMsgCancelRecurringOrder() MsgCancelRecurringOrder {
+	owner := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	return NewMsgCancelRecurringOrder(owner, 1)
+}
+
+func newValidMsgWithdrawShare() MsgWithdrawShare {
+	recipient := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	amount := sdk.NewCoins(sdk.NewInt64Coin(initToken, 10))
+	return NewMsgWithdrawShare(recipient, initToken, amount)
+}
+
+func newValidMsgSettleBond() MsgSettleBond {
+	return NewMsgSettleBond(initToken, initCreator, initSigners)
+}
+
+func newValidOutcomeTranches() []OutcomeTranche {
+	payer := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	return []OutcomeTranche{
+		NewOutcomeTranche(sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 10)), nil, false),
+		NewOutcomeTranche(sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 20)), payer, true),
+	}
 }
@@ -49,4 +49,44 @@ var (
 	ErrArgumentMissingOrNonUInteger         = sdkerrors.Register(ModuleName, 338, "argument is missing or is not an unsigned integer")
 	ErrArgumentMissingOrNonBoolean          = sdkerrors.Register(ModuleName, 339, "argument is missing or is not true or false")
 	ErrReservedBondToken                    = sdkerrors.Register(ModuleName, 340, "bond token is reserved")
+	ErrCurveEvaluationFailed                = sdkerrors.Register(ModuleName, 341, "bonding curve could not be evaluated")
+	ErrNegativeCurveResult                  = sdkerrors.Register(ModuleName, 342, "bonding curve evaluated to a negative value")
+	ErrInsufficientReserveForBurn           = sdkerrors.Register(ModuleName, 343, "not enough reserve available for burn")
+	ErrCurveFailsSanityCheck                = sdkerrors.Register(ModuleName, 344, "bonding curve fails sanity check")
+	ErrOrderFailed                          = sdkerrors.Register(ModuleName, 345, "order could not be performed")
+	ErrBondAccountingInconsistent           = sdkerrors.Register(ModuleName, 346, "bond accounting is inconsistent")
+	ErrExpiryHeightMustBeInFuture           = sdkerrors.Register(ModuleName, 347, "expiry height must be greater than the current block height")
+	ErrLimitPriceNotMet                     = sdkerrors.Register(ModuleName, 348, "limit price cannot be met")
+	ErrSpendTooSmallToBuyAnyTokens          = sdkerrors.Register(ModuleName, 349, "spend amount too small to buy any tokens")
+	ErrMinReturnsNotMet                     = sdkerrors.Register(ModuleName, 350, "returns are less than the min returns")
+	ErrOrderNotFound                        = sdkerrors.Register(ModuleName, 351, "order not found in batch")
+	ErrOrderAlreadyCancelled                = sdkerrors.Register(ModuleName, 352, "order has already been cancelled")
+	ErrOrderNotOwnedBySender                = sdkerrors.Register(ModuleName, 353, "order does not belong to sender")
+	ErrRevealBlocksNotLessThanBatchBlocks   = sdkerrors.Register(ModuleName, 354, "reveal blocks must be less than batch blocks")
+	ErrOrderCommitmentsNotAccepted          = sdkerrors.Register(ModuleName, 355, "bond does not accept order commitments")
+	ErrInvalidBatchPhase                    = sdkerrors.Register(ModuleName, 356, "action not allowed in current batch phase")
+	ErrOrderCommitmentNotFound              = sdkerrors.Register(ModuleName, 357, "order commitment not found")
+	ErrOrderCommitmentHashMismatch          = sdkerrors.Register(ModuleName, 358, "revealed order does not match commitment")
+	ErrInvalidOrderType                     = sdkerrors.Register(ModuleName, 359, "invalid order type")
+	ErrOrderNotForBond                      = sdkerrors.Register(ModuleName, 360, "order is not for the specified bond")
+	ErrInvalidOrderCommitmentHash           = sdkerrors.Register(ModuleName, 361, "invalid order commitment hash")
+	ErrBatchOrderLimitReached               = sdkerrors.Register(ModuleName, 362, "batch has reached its maximum number of orders")
+	ErrBatchVolumeLimitReached              = sdkerrors.Register(ModuleName, 363, "batch has reached its maximum volume")
+	ErrOrderExceedsBatchVolumeLimit         = sdkerrors.Register(ModuleName, 364, "order amount exceeds the maximum batch volume")
+	ErrBudgetDoesNotCoverOrder              = sdkerrors.Register(ModuleName, 365, "budget does not cover a single order")
+	ErrEndHeightMustBeInFuture              = sdkerrors.Register(ModuleName, 366, "end height must be greater than the current block height")
+	ErrRecurringOrderNotFound               = sdkerrors.Register(ModuleName, 367, "recurring order not found")
+	ErrSettlementSnapshotNotFound           = sdkerrors.Register(ModuleName, 368, "settlement snapshot not found")
+	ErrAmountExceedsShare                   = sdkerrors.Register(ModuleName, 369, "amount exceeds the share that can be withdrawn")
+	ErrCannotReuseBondToken                 = sdkerrors.Register(ModuleName, 370, "bond token cannot be reused")
+	ErrInvalidOutcomeTranche                = sdkerrors.Register(ModuleName, 371, "invalid outcome tranche")
+	ErrOutcomePaymentAndTranches            = sdkerrors.Register(ModuleName, 372, "bond cannot have both an outcome payment and outcome tranches")
+	ErrBondHasNoOutcomeTranches             = sdkerrors.Register(ModuleName, 373, "bond does not have outcome tranches")
+	ErrOutcomePaymentNotAttested            = sdkerrors.Register(ModuleName, 374, "outcome payment has not been attested by a quorum of evaluators")
+	ErrCurveOverflow                        = sdkerrors.Register(ModuleName, 375, "bonding curve arithmetic overflows")
+	ErrLimitOrderAmountTooSmall             = sdkerrors.Register(ModuleName, 376, "limit order amount is below the minimum")
+	ErrMaxRecurringOrdersReached            = sdkerrors.Register(ModuleName, 377, "bond has reached its maximum number of recurring orders")
+	ErrExpiryHeightTooFar                   = sdkerrors.Register(ModuleName, 378, "expiry height exceeds the maximum limit order expiry")
+	ErrDepositBelowMinimum                  = sdkerrors.Register(ModuleName, 379, "deposit is below the minimum order commitment deposit")
+	ErrHoldersBeingRecorded                 = sdkerrors.Register(ModuleName, 380, "holders of the bond are still being recorded")
 )
@@ -1,17 +1,39 @@
 package types
 
 const (
-	EventTypeCreateBond         = "create_bond"
-	EventTypeEditBond           = "edit_bond"
-	EventTypeInitSwapper        = "init_swapper"
-	EventTypeBuy                = "buy"
-	EventTypeSell               = "sell"
-	EventTypeSwap               = "swap"
-	EventTypeMakeOutcomePayment = "make_outcome_payment"
-	EventTypeWithdrawShare      = "withdraw_share"
-	EventTypeOrderCancel        = "order_cancel"
-	EventTypeOrderFulfill       = "order_fulfill"
-	EventTypeStateChange        = "state_change"
+	EventTypeCreateBond           = "create_bond"
+	EventTypeEditBond             = "edit_bond"
+	EventTypeInitSwapper          = "init_swapper"
+	EventTypeBuy                  = "buy"
+	EventTypeSell                 = "sell"
+	EventTypeSwap                 = "swap"
+	EventTypeMakeOutcomePayment   = "make_outcome_payment"
+	EventTypeOutcomeFailed        = "outcome_failed"
+	EventTypeWithdrawShare        = "withdraw_share"
+	EventTypeOrderCancel          = "order_cancel"
+	EventTypeOrderFulfill         = "order_fulfill"
+	EventTypeStateChange          = "state_change"
+	EventTypeLimitBuy             = "limit_buy"
+	EventTypeLimitSell            = "limit_sell"
+	EventTypeLimitOrderMatch      = "limit_order_match"
+	EventTypeSpendBuy             = "spend_buy"
+	EventTypeCancelOrder          = "cancel_order"
+	EventTypeCommitOrder          = "commit_order"
+	EventTypeRevealOrder          = "reveal_order"
+	EventTypeRevealPhase          = "reveal_phase"
+	EventTypeUnrevealedOrder      = "unrevealed_order"
+	EventTypeCreateRecurringOrder = "create_recurring_order"
+	EventTypeCancelRecurringOrder = "cancel_recurring_order"
+	EventTypeRecurringOrderPlace  = "recurring_order_place"
+	EventTypeRecurringOrderSkip   = "recurring_order_skip"
+	EventTypeRecurringOrderEnd    = "recurring_order_end"
+	EventTypeDistributeShare      = "distribute_share"
+	EventTypeDistributionEnd      = "distribution_end"
+	EventTypeDistributeTranche    = "distribute_tranche"
+	EventTypeTrancheDistributed   = "tranche_distributed"
+	EventTypeSweepReserve         = "sweep_reserve"
+	EventTypeSettleBond           = "settle_bond"
+	EventTypeHoldersRecorded      = "holders_recorded"
 
 	AttributeKeyBond                   = "bond"
 	AttributeKeyName                   = "name"
@@ -29,7 +51,18 @@ const (
 	AttributeKeyAllowSells             = "allow_sells"
 	AttributeKeySigners                = "signers"
 	AttributeKeyBatchBlocks            = "batch_blocks"
+	AttributeKeyRevealBlocks           = "reveal_blocks"
+	AttributeKeyForfeitUnrevealed      = "forfeit_unrevealed"
+	AttributeKeyMaxBatchOrders         = "max_batch_orders"
+	AttributeKeyMaxBatchVolume         = "max_batch_volume"
+	AttributeKeyRollOverOrders         = "roll_over_orders"
 	AttributeKeyOutcomePayment         = "outcome_payment"
+	AttributeKeyOutcomeTranches        = "outcome_tranches"
+	AttributeKeyOutcomePayers          = "outcome_payers"
+	AttributeKeyEvaluators             = "evaluators"
+	AttributeKeyAutoDistribute         = "auto_distribute"
+	AttributeKeyClaimBlocks            = "claim_blocks"
+	AttributeKeySweepToCommunityPool   = "sweep_to_community_pool"
 	AttributeKeyState                  = "state"
 	AttributeKeyMaxPrices              = "max_prices"
 	AttributeKeySwapFromToken          = "from_token"
@@ -37,6 +70,7 @@ const (
 	AttributeKeyOrderType              = "order_type"
 	AttributeKeyAddress                = "address"
 	AttributeKeyCancelReason           = "cancel_reason"
+	AttributeKeySkipReason             = "skip_reason"
 	AttributeKeyTokensMinted           = "tokens_minted"
 	AttributeKeyTokensBurned           = "tokens_burned"
 	AttributeKeyTokensSwapped          = "tokens_swapped"
@@ -48,9 +82,35 @@ const (
 	AttributeKeyNewBondTokenBalance    = "new_bond_token_balance"
 	AttributeKeyOldState               = "old_state"
 	AttributeKeyNewState               = "new_state"
+	AttributeKeyOrderId                = "order_id"
+	AttributeKeyLimitPrice             = "limit_price"
+	AttributeKeyExpiryHeight           = "expiry_height"
+	AttributeKeySpend                  = "spend"
+	AttributeKeyMinReturns             = "min_returns"
+	AttributeKeyCommitmentId           = "commitment_id"
+	AttributeKeyCommitmentHash         = "commitment_hash"
+	AttributeKeyDeposit                = "deposit"
+	AttributeKeyForfeited              = "forfeited"
+	AttributeKeyFilledAmount           = "filled_amount"
+	AttributeKeyRequestedAmount        = "requested_amount"
+	AttributeKeyRolledOver             = "rolled_over"
+	AttributeKeyRecipient              = "recipient"
+	AttributeKeyRecurringOrderId       = "recurring_order_id"
+	AttributeKeyBudget                 = "budget"
+	AttributeKeyInterval               = "interval"
+	AttributeKeyEndHeight              = "end_height"
+	AttributeKeyWithdrawnShare         = "withdrawn_share"
+	AttributeKeySnapshotHeight         = "snapshot_height"
+	AttributeKeySnapshotHolders        = "snapshot_holders"
+	AttributeKeyHoldersPaid            = "holders_paid"
+	AttributeKeyClaimDeadline          = "claim_deadline"
+	AttributeKeyTranchesPaid           = "tranches_paid"
+	AttributeKeyTranche                = "tranche"
+	AttributeKeyAttestedPercentage     = "attested_percentage"
 
-	AttributeValueBuyOrder  = "buy"
-	AttributeValueSellOrder = "sell"
-	AttributeValueSwapOrder = "swap"
-	AttributeValueCategory  = ModuleName
+	AttributeValueBuyOrder      = "buy"
+	AttributeValueSellOrder     = "sell"
+	AttributeValueSwapOrder     = "swap"
+	AttributeValueCommunityPool = "community_pool"
+	AttributeValueCategory      = ModuleName
 )
@@ -0,0 +1,21 @@
+package types
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// OutcomesKeeper defines the expected outcomes keeper, which holds the
+// evaluators' attestations that the outcome payments of bonds with evaluators
+// are scaled by.
+type OutcomesKeeper interface {
+	// GetAttestedPercentage returns the percentage (from 0 to 100) of the
+	// bond's outcome payment with the given index (i.e. the number of outcome
+	// tranches paid before it) that is attested by the bond's evaluators, and
+	// false if the payment has not been attested by a quorum of evaluators.
+	GetAttestedPercentage(ctx sdk.Context, bondToken string, payment uint64) (percentage sdk.Dec, found bool)
+
+	// RemoveAttestations removes all of the bond's attestations. It is called
+	// when a closed bond's token is released, so that a new bond with the same
+	// token does not inherit the closed bond's attestations.
+	RemoveAttestations(ctx sdk.Context, bondToken string)
+}
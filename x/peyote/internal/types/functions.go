@@ -0,0 +1,212 @@
+package types
+
+import (
+	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"math/rand"
+	"sort"
+)
+
+// BondingFunction defines the behaviour of a bond's function type. A new curve
+// is added by implementing this interface and registering the implementation
+// under its function type using RegisterFunctionType.
+type BondingFunction interface {
+	// RequiredParams returns the parameters that a bond of this type requires.
+	RequiredParams() []string
+
+	// NoOfReserveTokens returns the number of reserve tokens that a bond of
+	// this type requires, or AnyNumberOfReserveTokens if not restricted.
+	NoOfReserveTokens() int
+
+	// ValidateParams checks any function-specific parameter restrictions on
+	// top of the presence and non-negativity checks common to all types.
+	ValidateParams(paramsMap map[string]sdk.Dec) error
+
+	// PricesAtSupply returns the price per token at the specified supply.
+	PricesAtSupply(bond Bond, supply sdk.Int) (sdk.DecCoins, error)
+
+	// CurrentPricesPT returns the current price per token.
+	CurrentPricesPT(bond Bond, reserveBalances sdk.Coins) (sdk.DecCoins, error)
+
+	// ReserveAtSupply returns the reserve that backs the specified supply.
+	ReserveAtSupply(bond Bond, supply sdk.Int) (sdk.Dec, error)
+
+	// SupplyAtReserve returns the supply backed by the specified reserve,
+	// i.e. the inverse of ReserveAtSupply.
+	SupplyAtReserve(bond Bond, reserve sdk.Dec) (sdk.Dec, error)
+
+	// PricesToMint returns the reserve to be paid (excl. fees) to mint tokens.
+	PricesToMint(bond Bond, mint sdk.Int, reserveBalances sdk.Coins) (sdk.DecCoins, error)
+
+	// AmountToMint returns the most tokens that can be minted for the specified
+	// reserve (excl. fees), i.e. the inverse of PricesToMint.
+	AmountToMint(bond Bond, prices sdk.DecCoins, reserveBalances sdk.Coins) (sdk.Int, error)
+
+	// ReturnsForBurn returns the reserve returned (excl. fees) to burn tokens.
+	ReturnsForBurn(bond Bond, burn sdk.Int, reserveBalances sdk.Coins) (sdk.DecCoins, error)
+
+	// ReturnsForSwap returns the result of swapping between reserve tokens.
+	ReturnsForSwap(bond Bond, from sdk.Coin, toToken string, reserveBalances sdk.Coins) (sdk.Coins, sdk.Coin, error)
+
+	// ReturnsForBatchSwap returns the result of swapping between reserve tokens
+	// as one of a batch of swaps that are all settled at a single clearing rate,
+	// given the batch's total swap inputs (excl. fees) per reserve token.
+	ReturnsForBatchSwap(bond Bond, from sdk.Coin, toToken string, totalInputs, reserveBalances sdk.Coins) (sdk.Coins, sdk.Coin, error)
+
+	// EnforcesReserveInvariant indicates whether the reserve balance should
+	// always be at least the value returned by ReserveAtSupply.
+	EnforcesReserveInvariant() bool
+
+	// IsLiquidityPool indicates whether a bond of this type is a liquidity
+	// pool between its reserve tokens, with the bond token as liquidity shares.
+	// The first buy into such a bond sets its initial reserve, its reserve
+	// tokens can be swapped, and limit and recurring orders are not available
+	// since its prices do not depend on its supply.
+	IsLiquidityPool() bool
+
+	// RandomParams returns random parameters that are valid for a bond of this
+	// type, for use in simulations.
+	RandomParams(r *rand.Rand) FunctionParams
+}
+
+// HatchFunction is implemented by bonding functions whose bonds start off in a
+// hatch phase, during which sells are not allowed, until a certain supply is
+// reached, after which the bond is open.
+type HatchFunction interface {
+	BondingFunction
+
+	// HatchParams returns the parameters that are derived from the specified
+	// parameters when a bond is created, and stored along with them.
+	HatchParams(paramsMap map[string]sdk.Dec) (FunctionParams, error)
+
+	// HatchSupply returns the supply at which the bond's hatch phase ends.
+	HatchSupply(bond Bond) sdk.Dec
+
+	// HatchReserve returns the reserve that backs the specified supply during
+	// the hatch phase. The rest of what buyers pay goes to the funding pool.
+	HatchReserve(bond Bond, supply sdk.Int) sdk.Dec
+}
+
+var functionRegistry = make(map[string]BondingFunction)
+
+func init() {
+	RegisterFunctionType(PowerFunction, powerFunction{})
+	RegisterFunctionType(SigmoidFunction, sigmoidFunction{})
+	RegisterFunctionType(SwapperFunction, swapperFunction{})
+	RegisterFunctionType(AugmentedFunction, augmentedFunction{})
+	RegisterFunctionType(BancorFunction, bancorFunction{})
+}
+
+// RegisterFunctionType makes a bonding function available under the specified
+// function type. It panics if the function type has already been registered.
+func RegisterFunctionType(functionType string, fn BondingFunction) {
+	if _, ok := functionRegistry[functionType]; ok {
+		panic(fmt.Sprintf("function type %s already registered", functionType))
+	}
+	functionRegistry[functionType] = fn
+}
+
+func GetFunction(functionType string) (BondingFunction, error) {
+	fn, ok := functionRegistry[functionType]
+	if !ok {
+		return nil, sdkerrors.Wrap(ErrUnrecognizedFunctionType, functionType)
+	}
+	return fn, nil
+}
+
+func MustGetFunction(functionType string) BondingFunction {
+	fn, err := GetFunction(functionType)
+	if err != nil {
+		panic(err)
+	}
+	return fn
+}
+
+// GetHatchFunction returns the bonding function registered under the specified
+// function type, if bonds of that type have a hatch phase.
+func GetHatchFunction(functionType string) (HatchFunction, bool) {
+	fn, ok := functionRegistry[functionType].(HatchFunction)
+	return fn, ok
+}
+
+// FunctionTypes returns all registered function types in sorted order.
+func FunctionTypes() (functionTypes []string) {
+	for ft := range functionRegistry {
+		functionTypes = append(functionTypes, ft)
+	}
+	sort.Strings(functionTypes)
+	return functionTypes
+}
+
+func commonReserveBalance(reserveBalances sdk.Coins) sdk.Dec {
+	if reserveBalances.Empty() {
+		return sdk.ZeroDec()
+	}
+	// Reserve balances should all be equal given that we are always
+	// applying the same additions/subtractions to all reserve balances.
+	// Thus we can pick the first reserve balance as the global balance.
+	return reserveBalances[0].Amount.ToDec()
+}
+
+func curvePricesToMint(bond Bond, reserveAtNewSupply sdk.Dec, reserveBalances sdk.Coins) sdk.DecCoins {
+	priceToMint := reserveAtNewSupply.Sub(commonReserveBalance(reserveBalances))
+	if priceToMint.IsNegative() {
+		// Negative priceToMint means that the previous buyer overpaid
+		// to the point that the price for this buyer is covered. However,
+		// we still charge this buyer at least one token.
+		priceToMint = sdk.OneDec()
+	}
+	return bond.GetNewReserveDecCoins(priceToMint)
+}
+
+// commonPrice returns the smallest of the prices in the bond's reserve tokens.
+// Since all reserve tokens are priced equally by curves, this is the price that
+// limits how many tokens can be minted.
+func commonPrice(bond Bond, prices sdk.DecCoins) sdk.Dec {
+	price := sdk.ZeroDec()
+	for i, r := range bond.ReserveTokens {
+		if amount := prices.AmountOf(r); i == 0 || amount.LT(price) {
+			price = amount
+		}
+	}
+	return price
+}
+
+// curveAmountToMint returns the most tokens that can be minted for the
+// specified prices, using the inverse of the curve's reserve function.
+func curveAmountToMint(bond Bond, fn BondingFunction, prices sdk.DecCoins, reserveBalances sdk.Coins) (sdk.Int, error) {
+	price := commonPrice(bond, prices)
+	if !price.IsPositive() {
+		return sdk.ZeroInt(), nil
+	}
+
+	newReserve := commonReserveBalance(reserveBalances).Add(price)
+	newSupply, err := fn.SupplyAtReserve(bond, newReserve)
+	if err != nil {
+		return sdk.Int{}, err
+	}
+
+	mint := newSupply.TruncateInt().Sub(bond.CurrentSupply.Amount)
+	if mint.IsNegative() {
+		return sdk.ZeroInt(), nil
+	}
+	return mint, nil
+}
+
+func curveReturnsForBurn(bond Bond, reserveAtNewSupply sdk.Dec, reserveBalances sdk.Coins) (sdk.DecCoins, error) {
+	reserveBalance := commonReserveBalance(reserveBalances)
+	if reserveAtNewSupply.GT(reserveBalance) {
+		return nil, sdkerrors.Wrapf(ErrInsufficientReserveForBurn,
+			"reserve required %s exceeds reserve %s", reserveAtNewSupply, reserveBalance)
+	}
+	return bond.GetNewReserveDecCoins(reserveBalance.Sub(reserveAtNewSupply)), nil
+}
+
+func curveEvaluationFailed(err error) error {
+	return sdkerrors.Wrap(ErrCurveEvaluationFailed, err.Error())
+}
+
+func swapsNotAvailable(bond Bond) (sdk.Coins, sdk.Coin, error) {
+	return nil, sdk.Coin{}, sdkerrors.Wrap(ErrFunctionNotAvailableForFunctionType, bond.FunctionType)
+}
@@ -0,0 +1,74 @@
+package types
+
+import (
+	"github.com/stretchr/testify/require"
+	"math/rand"
+	"testing"
+)
+
+func TestGetFunction(t *testing.T) {
+	for _, ft := range FunctionTypes() {
+		fn, err := GetFunction(ft)
+		require.Nil(t, err)
+		require.NotNil(t, fn)
+	}
+
+	_, err := GetFunction("dummy_function")
+	require.Error(t, err)
+	require.Panics(t, func() { MustGetFunction("dummy_function") })
+}
+
+func TestRegisterFunctionTypeTwicePanics(t *testing.T) {
+	require.Panics(t, func() {
+		RegisterFunctionType(PowerFunction, powerFunction{})
+	})
+}
+
+func TestFunctionTypes(t *testing.T) {
+	require.Equal(t, []string{
+		AugmentedFunction,
+		BancorFunction,
+		PowerFunction,
+		SigmoidFunction,
+		SwapperFunction,
+	}, FunctionTypes())
+}
+
+func TestRandomParamsAreValid(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, ft := range FunctionTypes() {
+		for i := 0; i < 10; i++ {
+			params := MustGetFunction(ft).RandomParams(r)
+			require.Nil(t, params.Validate(ft), ft)
+		}
+	}
+}
+
+func TestGetHatchFunction(t *testing.T) {
+	for _, ft := range FunctionTypes() {
+		_, ok := GetHatchFunction(ft)
+		require.Equal(t, ft == AugmentedFunction, ok, ft)
+	}
+
+	_, ok := GetHatchFunction("dummy_function")
+	require.False(t, ok)
+}
+
+func TestAugmentedHatch(t *testing.T) {
+	fn, _ := GetHatchFunction(AugmentedFunction)
+
+	// R0, S0 and V0 are derived from the parameters
+	hatchParams, err := fn.HatchParams(functionParametersAugmented().AsMap())
+	require.Nil(t, err)
+	require.Equal(t, functionParametersAugmentedFull(),
+		append(functionParametersAugmented(), hatchParams...))
+
+	bond := getValidBond()
+	bond.FunctionType = AugmentedFunction
+	bond.FunctionParameters = functionParametersAugmentedFull()
+
+	// Hatch ends at S0, at which the reserve is R0
+	paramsMap := bond.FunctionParameters.AsMap()
+	require.Equal(t, paramsMap["S0"], fn.HatchSupply(bond))
+	require.Equal(t, paramsMap["R0"], fn.HatchReserve(bond, paramsMap["S0"].TruncateInt()))
+}
@@ -1,16 +1,36 @@
 package types
 
 type GenesisState struct {
-	Bonds   []Bond  `json:"peyote" yaml:"peyote"`
-	Batches []Batch `json:"batches" yaml:"batches"`
-	Params  Params  `json:"params" yaml:"params"`
+	Bonds                []Bond                `json:"peyote" yaml:"peyote"`
+	Batches              []Batch               `json:"batches" yaml:"batches"`
+	LimitOrders          []LimitOrder          `json:"limit_orders" yaml:"limit_orders"`
+	OrderCommitments     []OrderCommitment     `json:"order_commitments" yaml:"order_commitments"`
+	BatchHistory         []BatchRecord         `json:"batch_history" yaml:"batch_history"`
+	RolledOverOrders     []RolledOverOrders    `json:"rolled_over_orders" yaml:"rolled_over_orders"`
+	RecurringOrders      []RecurringOrder      `json:"recurring_orders" yaml:"recurring_orders"`
+	SettlementSnapshots  []SettlementSnapshot  `json:"settlement_snapshots" yaml:"settlement_snapshots"`
+	ShareDistributions   []ShareDistribution   `json:"share_distributions" yaml:"share_distributions"`
+	TrancheDistributions []TrancheDistribution `json:"tranche_distributions" yaml:"tranche_distributions"`
+	Params               Params                `json:"params" yaml:"params"`
 }
 
-func NewGenesisState(peyote []Bond, batches []Batch, params Params) GenesisState {
+func NewGenesisState(peyote []Bond, batches []Batch, limitOrders []LimitOrder,
+	orderCommitments []OrderCommitment, batchHistory []BatchRecord,
+	rolledOverOrders []RolledOverOrders, recurringOrders []RecurringOrder,
+	settlementSnapshots []SettlementSnapshot, shareDistributions []ShareDistribution,
+	trancheDistributions []TrancheDistribution, params Params) GenesisState {
 	return GenesisState{
-		Bonds:   peyote,
-		Batches: batches,
-		Params:  params,
+		Bonds:                peyote,
+		Batches:              batches,
+		LimitOrders:          limitOrders,
+		OrderCommitments:     orderCommitments,
+		BatchHistory:         batchHistory,
+		RolledOverOrders:     rolledOverOrders,
+		RecurringOrders:      recurringOrders,
+		SettlementSnapshots:  settlementSnapshots,
+		ShareDistributions:   shareDistributions,
+		TrancheDistributions: trancheDistributions,
+		Params:               params,
 	}
 }
 
@@ -24,8 +44,16 @@ func ValidateGenesis(data GenesisState) error {
 
 func DefaultGenesisState() GenesisState {
 	return GenesisState{
-		Bonds:   nil,
-		Batches: nil,
-		Params:  DefaultParams(),
+		Bonds:                nil,
+		Batches:              nil,
+		LimitOrders:          nil,
+		OrderCommitments:     nil,
+		BatchHistory:         nil,
+		RolledOverOrders:     nil,
+		RecurringOrders:      nil,
+		SettlementSnapshots:  nil,
+		ShareDistributions:   nil,
+		TrancheDistributions: nil,
+		Params:               DefaultParams(),
 	}
 }
@@ -1,5 +1,9 @@
 package types
 
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
 const (
 	// ModuleName is the name of this module
 	ModuleName = "peyote"
@@ -26,21 +30,83 @@ const (
 	RouterKey = ModuleName
 )
 
-// Bonds and batches are stored as follow:
+// Bonds, batches, and limit orders are stored as follow:
 //
 // - Bonds: 0x00<bond_token_bytes>
-// - Batches: 0x01<bond_token_bytes>
+// - Batch headers: 0x01<bond_token_bytes>
 // - Last batches: 0x02<bond_token_bytes>
+// - Limit orders: 0x03<order_id_bytes>
+// - Limit order book: 0x04<bond_token_bytes>0x00<side_byte><price_bytes><order_id_bytes>
+// - Limit order expiries: 0x05<expiry_height_bytes><order_id_bytes>
+// - Last limit order ID: 0x06
+// - Last order ID: 0x07
+// - Order commitments: 0x08<bond_token_bytes>0x00<commitment_id_bytes>
+// - Batch orders: 0x09<bond_token_bytes>0x00<order_type_byte><order_id_bytes>
+// - Batch queue: 0x0A<height_bytes><bond_token_bytes>
+// - Bond accounting: 0x0B<bond_token_bytes>
+// - Batch history: 0x0C<bond_token_bytes>0x00<height_bytes>
+// - Batch history heights: 0x0D<height_bytes><bond_token_bytes>
+// - Rolled-over orders: 0x0E<bond_token_bytes>0x00<order_id_bytes><order_type_byte>
+// - Recurring orders: 0x0F<order_id_bytes>
+// - Recurring orders by owner: 0x10<owner_address_bytes><order_id_bytes>
+// - Recurring orders by bond: 0x11<bond_token_bytes>0x00<order_id_bytes>
+// - Recurring order end heights: 0x12<end_height_bytes><order_id_bytes>
+// - Last recurring order ID: 0x13
+// - Settlement snapshots: 0x14<bond_token_bytes>
+// - Holder snapshots: 0x15<bond_token_bytes>0x00<holder_address_bytes>
+// - Share distributions: 0x16<bond_token_bytes>
+// - Claim deadlines: 0x17<deadline_height_bytes><bond_token_bytes>
+// - Holders: 0x18<bond_token_bytes>0x00<holder_address_bytes>
+// - Tranche distributions: 0x19<bond_token_bytes>0x00<tranche_bytes>
+// - Tranche holders: 0x1A<bond_token_bytes>0x00<tranche_bytes><holder_address_bytes>
+// - Settlement snapshots being recorded: 0x1B<bond_token_bytes>
+// - Recurring order counts: 0x1C<bond_token_bytes>
 var (
-	BondsKeyPrefix       = []byte{0x00} // key for peyote
-	BatchesKeyPrefix     = []byte{0x01} // key for batches
-	LastBatchesKeyPrefix = []byte{0x02} // key for last batches
+	BondsKeyPrefix                = []byte{0x00} // key for peyote
+	BatchesKeyPrefix              = []byte{0x01} // key for batches
+	LastBatchesKeyPrefix          = []byte{0x02} // key for last batches
+	LimitOrdersKeyPrefix          = []byte{0x03} // key for limit orders
+	LimitOrderBookKeyPrefix       = []byte{0x04} // key for limit order book entries
+	LimitOrderExpiryKeyPrefix     = []byte{0x05} // key for limit order expiries
+	LastOrderIdKey                = []byte{0x07} // key for last order ID
+	OrderCommitmentsKeyPrefix     = []byte{0x08} // key for order commitments
+	BatchOrdersKeyPrefix          = []byte{0x09} // key for batch orders
+	BatchQueueKeyPrefix           = []byte{0x0A} // key for batch queue
+	BondAccountingKeyPrefix       = []byte{0x0B} // key for bond accounting
+	BatchHistoryKeyPrefix         = []byte{0x0C} // key for batch history
+	BatchHistoryHeightsPrefix     = []byte{0x0D} // key for batch history heights
+	RolledOverOrdersPrefix        = []byte{0x0E} // key for rolled-over orders
+	RecurringOrdersKeyPrefix      = []byte{0x0F} // key for recurring orders
+	RecurringOrderOwnersKeyPrefix = []byte{0x10} // key for recurring orders by owner
+	RecurringOrderBondsKeyPrefix  = []byte{0x11} // key for recurring orders by bond
+	RecurringOrderEndsKeyPrefix   = []byte{0x12} // key for recurring order end heights
+	LastRecurringOrderIdKey       = []byte{0x13} // key for last recurring order ID
+	SettlementSnapshotsKeyPrefix  = []byte{0x14} // key for settlement snapshots
+	HolderSnapshotsKeyPrefix      = []byte{0x15} // key for holder snapshots
+	ShareDistributionsKeyPrefix   = []byte{0x16} // key for share distributions
+	ClaimDeadlinesKeyPrefix       = []byte{0x17} // key for claim deadlines
+	HoldersKeyPrefix              = []byte{0x18} // key for bond token holders
+	TrancheDistributionsKeyPrefix = []byte{0x19} // key for tranche distributions
+	TrancheHoldersKeyPrefix       = []byte{0x1A} // key for tranche distribution holders
+	RecordingSnapshotsKeyPrefix   = []byte{0x1B} // key for settlement snapshots being recorded
+	RecurringOrderCountsKeyPrefix = []byte{0x1C} // key for number of recurring orders by bond
+
+	limitBuySideByte  = byte(0x00)
+	limitSellSideByte = byte(0x01)
+
+	BatchBuyOrderByte  = byte(0x00)
+	BatchSellOrderByte = byte(0x01)
+	BatchSwapOrderByte = byte(0x02)
 )
 
 func GetBondKey(token string) []byte {
 	return append(BondsKeyPrefix, []byte(token)...)
 }
 
+func GetBondAccountingKey(token string) []byte {
+	return append(BondAccountingKeyPrefix, []byte(token)...)
+}
+
 func GetBatchKey(token string) []byte {
 	return append(BatchesKeyPrefix, []byte(token)...)
 }
@@ -48,3 +114,225 @@ func GetBatchKey(token string) []byte {
 func GetLastBatchKey(token string) []byte {
 	return append(LastBatchesKeyPrefix, []byte(token)...)
 }
+
+func GetLimitOrderKey(id uint64) []byte {
+	return append(LimitOrdersKeyPrefix, sdk.Uint64ToBigEndian(id)...)
+}
+
+// GetLimitOrderBookPrefixKey returns the prefix of the bond's limit buys or
+// limit sells in the order book, which are sorted by limit price. The bond
+// token is terminated by a zero byte, which cannot appear in a denomination.
+func GetLimitOrderBookPrefixKey(token string, buys bool) []byte {
+	side := limitSellSideByte
+	if buys {
+		side = limitBuySideByte
+	}
+	key := append(LimitOrderBookKeyPrefix, []byte(token)...)
+	return append(key, 0x00, side)
+}
+
+// GetLimitOrderBookKey returns the order book key of the limit order. Since
+// limit buys are iterated in reverse (by descending limit price), the bits of
+// their IDs are flipped so that equally-priced orders are still iterated by
+// ascending ID.
+func GetLimitOrderBookKey(order LimitOrder) []byte {
+	key := GetLimitOrderBookPrefixKey(order.Amount.Denom, order.IsBuy())
+	key = append(key, sdk.SortableDecBytes(order.LimitPrice)...)
+	if order.IsBuy() {
+		return append(key, sdk.Uint64ToBigEndian(^order.Id)...)
+	}
+	return append(key, sdk.Uint64ToBigEndian(order.Id)...)
+}
+
+func GetLimitOrderExpiryPrefixKey(height int64) []byte {
+	return append(LimitOrderExpiryKeyPrefix, sdk.Uint64ToBigEndian(uint64(height))...)
+}
+
+func GetLimitOrderExpiryKey(order LimitOrder) []byte {
+	key := GetLimitOrderExpiryPrefixKey(order.ExpiryHeight)
+	return append(key, sdk.Uint64ToBigEndian(order.Id)...)
+}
+
+// GetOrderCommitmentsPrefixKey returns the prefix of the bond's order
+// commitments. As in the order book, the bond token is terminated by a zero
+// byte so that the prefix of one bond does not match that of another.
+func GetOrderCommitmentsPrefixKey(token string) []byte {
+	key := append(OrderCommitmentsKeyPrefix, []byte(token)...)
+	return append(key, 0x00)
+}
+
+func GetOrderCommitmentKey(token string, id uint64) []byte {
+	return append(GetOrderCommitmentsPrefixKey(token), sdk.Uint64ToBigEndian(id)...)
+}
+
+// GetBatchOrdersPrefixKey returns the prefix of the orders in the bond's
+// current batch. As in the order book, the bond token is terminated by a zero
+// byte so that the prefix of one bond does not match that of another.
+func GetBatchOrdersPrefixKey(token string) []byte {
+	key := append(BatchOrdersKeyPrefix, []byte(token)...)
+	return append(key, 0x00)
+}
+
+// GetBatchOrdersOfTypePrefixKey returns the prefix of the buys, sells or swaps
+// in the bond's current batch, which are sorted by order ID and thus in the
+// order that they were added to the batch.
+func GetBatchOrdersOfTypePrefixKey(token string, orderType byte) []byte {
+	return append(GetBatchOrdersPrefixKey(token), orderType)
+}
+
+func GetBatchOrderKey(token string, orderType byte, id uint64) []byte {
+	return append(GetBatchOrdersOfTypePrefixKey(token, orderType), sdk.Uint64ToBigEndian(id)...)
+}
+
+func GetBatchQueuePrefixKey(height int64) []byte {
+	return append(BatchQueueKeyPrefix, sdk.Uint64ToBigEndian(uint64(height))...)
+}
+
+func GetBatchQueueKey(height int64, token string) []byte {
+	return append(GetBatchQueuePrefixKey(height), []byte(token)...)
+}
+
+// GetBatchHistoryPrefixKey returns the prefix of the bond's batch records,
+// which are sorted by height. As in the order book, the bond token is
+// terminated by a zero byte so that the prefix of one bond does not match that
+// of another.
+func GetBatchHistoryPrefixKey(token string) []byte {
+	key := append(BatchHistoryKeyPrefix, []byte(token)...)
+	return append(key, 0x00)
+}
+
+func GetBatchRecordKey(token string, height int64) []byte {
+	return append(GetBatchHistoryPrefixKey(token), sdk.Uint64ToBigEndian(uint64(height))...)
+}
+
+func GetBatchHistoryHeightPrefixKey(height int64) []byte {
+	return append(BatchHistoryHeightsPrefix, sdk.Uint64ToBigEndian(uint64(height))...)
+}
+
+func GetBatchHistoryHeightKey(token string, height int64) []byte {
+	return append(GetBatchHistoryHeightPrefixKey(height), []byte(token)...)
+}
+
+// GetRolledOverOrdersPrefixKey returns the prefix of the bond's rolled-over
+// orders. As in the order book, the bond token is terminated by a zero byte so
+// that the prefix of one bond does not match that of another.
+func GetRolledOverOrdersPrefixKey(token string) []byte {
+	key := append(RolledOverOrdersPrefix, []byte(token)...)
+	return append(key, 0x00)
+}
+
+// GetRolledOverOrderKey returns the key of a rolled-over buy, sell or swap.
+// The order type follows the order ID, so that the bond's rolled-over orders
+// are sorted by order ID irrespective of their type.
+func GetRolledOverOrderKey(token string, id uint64, orderType byte) []byte {
+	key := append(GetRolledOverOrdersPrefixKey(token), sdk.Uint64ToBigEndian(id)...)
+	return append(key, orderType)
+}
+
+func GetRecurringOrderKey(id uint64) []byte {
+	return append(RecurringOrdersKeyPrefix, sdk.Uint64ToBigEndian(id)...)
+}
+
+func GetRecurringOrderOwnerPrefixKey(owner sdk.AccAddress) []byte {
+	return append(RecurringOrderOwnersKeyPrefix, owner.Bytes()...)
+}
+
+func GetRecurringOrderOwnerKey(order RecurringOrder) []byte {
+	key := GetRecurringOrderOwnerPrefixKey(order.Address)
+	return append(key, sdk.Uint64ToBigEndian(order.Id)...)
+}
+
+// GetRecurringOrderBondPrefixKey returns the prefix of the bond's recurring
+// orders. As in the order book, the bond token is terminated by a zero byte so
+// that the prefix of one bond does not match that of another.
+func GetRecurringOrderBondPrefixKey(token string) []byte {
+	key := append(RecurringOrderBondsKeyPrefix, []byte(token)...)
+	return append(key, 0x00)
+}
+
+func GetRecurringOrderBondKey(order RecurringOrder) []byte {
+	key := GetRecurringOrderBondPrefixKey(order.BondToken)
+	return append(key, sdk.Uint64ToBigEndian(order.Id)...)
+}
+
+func GetRecurringOrderCountKey(token string) []byte {
+	return append(RecurringOrderCountsKeyPrefix, []byte(token)...)
+}
+
+func GetRecurringOrderEndPrefixKey(height int64) []byte {
+	return append(RecurringOrderEndsKeyPrefix, sdk.Uint64ToBigEndian(uint64(height))...)
+}
+
+func GetRecurringOrderEndKey(order RecurringOrder) []byte {
+	key := GetRecurringOrderEndPrefixKey(order.EndHeight)
+	return append(key, sdk.Uint64ToBigEndian(order.Id)...)
+}
+
+func GetSettlementSnapshotKey(token string) []byte {
+	return append(SettlementSnapshotsKeyPrefix, []byte(token)...)
+}
+
+// GetHolderSnapshotsPrefixKey returns the prefix of the holder snapshots of
+// the bond. As in the order book, the bond token is terminated by a zero byte
+// so that the prefix of one bond does not match that of another.
+func GetHolderSnapshotsPrefixKey(token string) []byte {
+	key := append(HolderSnapshotsKeyPrefix, []byte(token)...)
+	return append(key, 0x00)
+}
+
+func GetHolderSnapshotKey(token string, address sdk.AccAddress) []byte {
+	return append(GetHolderSnapshotsPrefixKey(token), address.Bytes()...)
+}
+
+// GetHoldersPrefixKey returns the prefix of the bond's holder index. As in the
+// order book, the bond token is terminated by a zero byte so that the prefix
+// of one bond does not match that of another.
+func GetHoldersPrefixKey(token string) []byte {
+	key := append(HoldersKeyPrefix, []byte(token)...)
+	return append(key, 0x00)
+}
+
+func GetHolderKey(token string, address sdk.AccAddress) []byte {
+	return append(GetHoldersPrefixKey(token), address.Bytes()...)
+}
+
+func GetShareDistributionKey(token string) []byte {
+	return append(ShareDistributionsKeyPrefix, []byte(token)...)
+}
+
+// GetTrancheDistributionsPrefixKey returns the prefix of the bond's tranche
+// distributions, which are sorted by tranche. As in the order book, the bond
+// token is terminated by a zero byte so that the prefix of one bond does not
+// match that of another.
+func GetTrancheDistributionsPrefixKey(token string) []byte {
+	key := append(TrancheDistributionsKeyPrefix, []byte(token)...)
+	return append(key, 0x00)
+}
+
+func GetTrancheDistributionKey(token string, tranche uint64) []byte {
+	return append(GetTrancheDistributionsPrefixKey(token), sdk.Uint64ToBigEndian(tranche)...)
+}
+
+// GetTrancheHoldersPrefixKey returns the prefix of the holders that the bond's
+// tranche is still to be distributed to, which are sorted by address.
+func GetTrancheHoldersPrefixKey(token string, tranche uint64) []byte {
+	key := append(TrancheHoldersKeyPrefix, []byte(token)...)
+	key = append(key, 0x00)
+	return append(key, sdk.Uint64ToBigEndian(tranche)...)
+}
+
+func GetTrancheHolderKey(token string, tranche uint64, address sdk.AccAddress) []byte {
+	return append(GetTrancheHoldersPrefixKey(token, tranche), address.Bytes()...)
+}
+
+func GetRecordingSnapshotKey(token string) []byte {
+	return append(RecordingSnapshotsKeyPrefix, []byte(token)...)
+}
+
+func GetClaimDeadlinePrefixKey(height int64) []byte {
+	return append(ClaimDeadlinesKeyPrefix, sdk.Uint64ToBigEndian(uint64(height))...)
+}
+
+func GetClaimDeadlineKey(height int64, token string) []byte {
+	return append(GetClaimDeadlinePrefixKey(height), []byte(token)...)
+}
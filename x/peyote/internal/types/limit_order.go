@@ -0,0 +1,53 @@
+package types
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	LimitBuyOrderType  = "limit_buy"
+	LimitSellOrderType = "limit_sell"
+)
+
+// LimitOrder is a good-till-block order that is kept in a bond's order book
+// until the limit price can be met by a batch, at which point it is added to
+// the batch as a regular order, or until the expiry height is reached, at
+// which point the escrowed coins are refunded. The limit price is the price
+// per bond token in each of the bond's reserve tokens (excluding fees).
+type LimitOrder struct {
+	Id           uint64         `json:"id" yaml:"id"`
+	OrderType    string         `json:"order_type" yaml:"order_type"`
+	Address      sdk.AccAddress `json:"address" yaml:"address"`
+	Amount       sdk.Coin       `json:"amount" yaml:"amount"`
+	LimitPrice   sdk.Dec        `json:"limit_price" yaml:"limit_price"`
+	Escrow       sdk.Coins      `json:"escrow" yaml:"escrow"`
+	ExpiryHeight int64          `json:"expiry_height" yaml:"expiry_height"`
+}
+
+func NewLimitOrder(orderType string, address sdk.AccAddress, amount sdk.Coin,
+	limitPrice sdk.Dec, escrow sdk.Coins, expiryHeight int64) LimitOrder {
+	return LimitOrder{
+		OrderType:    orderType,
+		Address:      address,
+		Amount:       amount,
+		LimitPrice:   limitPrice,
+		Escrow:       escrow,
+		ExpiryHeight: expiryHeight,
+	}
+}
+
+func (lo LimitOrder) IsBuy() bool {
+	return lo.OrderType == LimitBuyOrderType
+}
+
+// GetLimitBuyEscrow returns the reserve tokens that need to be escrowed for a
+// limit buy, i.e. the (rounded) prices plus tx fees for buying the specified
+// amount of bond tokens at the limit price. This is the maximum that the buy
+// can cost when added to a batch with a buy price that is at most the limit.
+func (bond Bond) GetLimitBuyEscrow(amount sdk.Int, limitPrice sdk.Dec) sdk.Coins {
+	reservePrices := MultiplyDecCoinsByInt(
+		bond.GetNewReserveDecCoins(limitPrice), amount)
+	reservePricesRounded := RoundReservePrices(reservePrices)
+	txFees := bond.GetTxFees(reservePrices)
+	return reservePricesRounded.Add(txFees...)
+}
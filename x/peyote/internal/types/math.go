@@ -0,0 +1,205 @@
+package types
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+)
+
+// maxFractionalPowerBits is the number of binary digits of the fractional part
+// of an exponent that are evaluated by ApproxPower. Since 2^-60 < 1e-18, any
+// further digits are beyond the precision of sdk.Dec.
+const maxFractionalPowerBits = 60
+
+// maxInversionIterations is the maximum number of iterations performed by
+// invertConvex. Since it starts from an upper bound that is within a small
+// factor of the inverse and converges quadratically, it normally needs far
+// fewer iterations, so this only bounds the cost of pathological curves.
+const maxInversionIterations = 32
+
+// CurveEvaluationGasCost is the gas consumed for every evaluation of a bond's
+// batch prices, which involves a bounded but non-trivial amount of curve
+// arithmetic (e.g. fractional powers and inverses).
+const CurveEvaluationGasCost = 1000
+
+// maxCurveBitLen is the maximum bit length of the (internal) integer of any
+// sdk.Dec value in bonding curve arithmetic. This is two bits less than the
+// maximum of sdk.Dec, so that a sum of up to four such values cannot overflow.
+const maxCurveBitLen = 255 + sdk.DecimalPrecisionBits - 2
+
+// checkCurveValue returns an error if the value is too large to be used in
+// bonding curve arithmetic.
+func checkCurveValue(d sdk.Dec) error {
+	if d.BitLen() > maxCurveBitLen {
+		return sdkerrors.Wrapf(ErrCurveOverflow, "%s is too large", d)
+	}
+	return nil
+}
+
+// mulDec returns a*b, or an error if the product is too large for bonding
+// curve arithmetic. Since the product of the integers of a and b is divided by
+// 10^18 > 2^59 (and then rounded), its bit length is at most la+lb-58.
+func mulDec(a, b sdk.Dec) (sdk.Dec, error) {
+	if a.BitLen()+b.BitLen()-58 > maxCurveBitLen {
+		return sdk.Dec{}, sdkerrors.Wrapf(ErrCurveOverflow, "%s * %s", a, b)
+	}
+	return a.Mul(b), nil
+}
+
+// quoDec returns a/b, or an error if b is zero or if the quotient is too large
+// for bonding curve arithmetic. Since the integer of a is multiplied by
+// 10^18 < 2^60 and divided by the integer of b, which is at least 2^(lb-1),
+// the bit length of the (rounded) quotient is at most la-lb+62.
+func quoDec(a, b sdk.Dec) (sdk.Dec, error) {
+	if b.IsZero() {
+		return sdk.Dec{}, sdkerrors.Wrapf(ErrCurveEvaluationFailed, "%s / 0", a)
+	} else if a.BitLen()-b.BitLen()+62 > maxCurveBitLen {
+		return sdk.Dec{}, sdkerrors.Wrapf(ErrCurveOverflow, "%s / %s", a, b)
+	}
+	return a.Quo(b), nil
+}
+
+// powerDec returns base^exp for an integer exponent in the same way as
+// sdk.Dec.Power, i.e. by repeated squaring, but returns an error instead of
+// panicking if any of the intermediate products is too large.
+func powerDec(base sdk.Dec, exp uint64) (result sdk.Dec, err error) {
+	if exp == 0 {
+		return sdk.OneDec(), nil
+	}
+	tmp := sdk.OneDec()
+	for i := exp; i > 1; {
+		if i%2 == 0 {
+			i /= 2
+		} else {
+			if tmp, err = mulDec(tmp, base); err != nil {
+				return sdk.Dec{}, err
+			}
+			i = (i - 1) / 2
+		}
+		if base, err = mulDec(base, base); err != nil {
+			return sdk.Dec{}, err
+		}
+	}
+	return mulDec(base, tmp)
+}
+
+// mulInt returns a*b, or an error if the product could overflow sdk.Int.
+func mulInt(a, b sdk.Int) (sdk.Int, error) {
+	if a.BigInt().BitLen()+b.BigInt().BitLen() > 255 {
+		return sdk.Int{}, sdkerrors.Wrapf(ErrCurveOverflow, "%s * %s", a, b)
+	}
+	return a.Mul(b), nil
+}
+
+// ApproxPower returns base^exp for a non-negative base and an exponent that is
+// not necessarily an integer. Integer exponents are evaluated exactly using
+// sdk.Dec.Power. For the fractional part f of the exponent, base^f is evaluated
+// deterministically from the binary expansion f = b1/2 + b2/4 + b3/8 + ... as
+// the product of base^(1/2^i) for every set bit bi, where each base^(1/2^i) is
+// obtained by taking the square root of base^(1/2^(i-1)). Since the error of a
+// square root is halved by the following square root, the relative error of
+// the result is bounded by roughly maxFractionalPowerBits * 1e-18.
+func ApproxPower(base, exp sdk.Dec) (sdk.Dec, error) {
+	if base.IsNegative() {
+		return sdk.Dec{}, sdkerrors.Wrap(ErrArgumentCannotBeNegative, "base")
+	}
+
+	// Negative exponents are evaluated as 1/base^|exp|
+	if exp.IsNegative() {
+		if base.IsZero() {
+			return sdk.Dec{}, sdkerrors.Wrap(ErrArgumentMustBePositive,
+				"base (for negative exponent)")
+		}
+		result, err := ApproxPower(base, exp.Neg())
+		if err != nil {
+			return sdk.Dec{}, err
+		}
+		return quoDec(sdk.OneDec(), result)
+	}
+
+	intPart := exp.TruncateInt()
+	if !intPart.IsUint64() {
+		return sdk.Dec{}, sdkerrors.Wrap(ErrArgumentMustBeBetween, "exponent too large")
+	}
+	result, err := powerDec(base, intPart.Uint64())
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+
+	fracPart := exp.Sub(intPart.ToDec())
+	root := base
+	for i := 0; i < maxFractionalPowerBits && fracPart.IsPositive(); i++ {
+		root, err = root.ApproxSqrt()
+		if err != nil {
+			return sdk.Dec{}, err
+		}
+
+		// Shift the next binary digit of the fractional part into the units
+		fracPart = fracPart.MulInt64(2)
+		if fracPart.GTE(sdk.OneDec()) {
+			if result, err = mulDec(result, root); err != nil {
+				return sdk.Dec{}, err
+			}
+			fracPart = fracPart.Sub(sdk.OneDec())
+		}
+	}
+
+	return result, nil
+}
+
+// ApproxRoot returns the root-th root of a non-negative base, i.e. base^(1/root).
+// Integer roots are evaluated using sdk.Dec.ApproxRoot, while other roots are
+// evaluated using ApproxPower.
+func ApproxRoot(base, root sdk.Dec) (sdk.Dec, error) {
+	if !root.IsPositive() {
+		return sdk.Dec{}, sdkerrors.Wrap(ErrArgumentMustBePositive, "root")
+	} else if base.IsNegative() {
+		return sdk.Dec{}, sdkerrors.Wrap(ErrArgumentCannotBeNegative, "base")
+	}
+
+	if root.TruncateDec().Equal(root) && root.TruncateInt().IsUint64() {
+		return base.ApproxRoot(root.TruncateInt().Uint64())
+	}
+	return ApproxPower(base, sdk.OneDec().Quo(root))
+}
+
+// invertConvex returns the non-negative x for which f(x) = y, where f is an
+// increasing convex function with f(0) = 0, df is its derivative, and hi is an
+// upper bound for x. Starting from hi, Newton's method approaches x from above
+// and converges quadratically, so that a small fixed number of iterations is
+// enough to reach the precision of sdk.Dec. The result does not exceed x by
+// more than the precision of sdk.Dec.
+func invertConvex(f, df func(x sdk.Dec) (sdk.Dec, error), y, hi sdk.Dec) (sdk.Dec, error) {
+	if !y.IsPositive() {
+		return sdk.ZeroDec(), nil
+	}
+
+	x := hi
+	for i := 0; i < maxInversionIterations; i++ {
+		fX, err := f(x)
+		if err != nil {
+			return sdk.Dec{}, err
+		} else if fX.LTE(y) {
+			return x, nil
+		}
+
+		dfX, err := df(x)
+		if err != nil {
+			return sdk.Dec{}, err
+		} else if !dfX.IsPositive() {
+			return sdk.Dec{}, sdkerrors.Wrap(ErrCurveEvaluationFailed,
+				"function to invert is not increasing")
+		}
+
+		// Since f(x) > y, a zero step means that x is within the precision of
+		// sdk.Dec from the inverse, so step down to stay below the inverse
+		step, err := quoDec(fX.Sub(y), dfX)
+		if err != nil {
+			return sdk.Dec{}, err
+		} else if step.IsZero() {
+			step = sdk.SmallestDec()
+		}
+		x = sdk.MaxDec(x.Sub(step), sdk.ZeroDec())
+	}
+	return sdk.Dec{}, sdkerrors.Wrap(ErrCurveEvaluationFailed,
+		"inverse did not converge")
+}
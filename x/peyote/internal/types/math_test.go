@@ -0,0 +1,121 @@
+package types
+
+import (
+	"errors"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+func TestApproxPower(t *testing.T) {
+	// Tolerance of 1e-15 relative to the expected result
+	tolerance := sdk.NewDecWithPrec(1, 15)
+
+	testCases := []struct {
+		base     string
+		exp      string
+		expected string
+	}{
+		{"2", "3", "8"},                                        // integer exponent
+		{"2", "0", "1"},                                        // zero exponent
+		{"0", "0.5", "0"},                                      // zero base
+		{"4", "0.5", "2"},                                      // square root
+		{"27", "0.333333333333333333", "3"},                    // cube root
+		{"2", "0.5", "1.414213562373095049"},                   // sqrt(2)
+		{"16", "1.25", "32"},                                   // 16^(5/4)
+		{"100", "2.5", "100000"},                               // 100^(5/2)
+		{"0.25", "1.5", "0.125"},                               // base < 1
+		{"2", "-1", "0.5"},                                     // negative exponent
+		{"1000000", "0.75", "31622.776601683793319989"},        // large base
+		{"123.456", "1.7", "3593.939341116631643331"},          // arbitrary
+		{"9", "-0.5", "0.333333333333333333"},                  // negative fraction
+		{"1", "1234.5678", "1"},                                // unit base
+		{"10", "0.000000000000000001", "1.000000000000000002"}, // tiny exponent
+	}
+
+	for _, tc := range testCases {
+		base := sdk.MustNewDecFromStr(tc.base)
+		exp := sdk.MustNewDecFromStr(tc.exp)
+		expected := sdk.MustNewDecFromStr(tc.expected)
+
+		actual, err := ApproxPower(base, exp)
+		require.Nil(t, err)
+
+		diff := actual.Sub(expected).Abs()
+		require.True(t, diff.LTE(expected.Mul(tolerance).Add(sdk.NewDecWithPrec(2, 18))),
+			"%s^%s: expected %s, got %s", tc.base, tc.exp, expected, actual)
+	}
+}
+
+func TestApproxPowerInvalidArguments(t *testing.T) {
+	_, err := ApproxPower(sdk.NewDec(-1), sdk.NewDec(2))
+	require.Error(t, err)
+
+	_, err = ApproxPower(sdk.ZeroDec(), sdk.NewDec(-2))
+	require.Error(t, err)
+}
+
+func TestApproxRoot(t *testing.T) {
+	// Integer roots use sdk.Dec.ApproxRoot
+	actual, err := ApproxRoot(sdk.NewDec(27), sdk.NewDec(3))
+	require.Nil(t, err)
+	require.Equal(t, sdk.NewDec(3), actual)
+
+	// Fractional roots use ApproxPower, i.e. 32^(1/2.5) = 4
+	actual, err = ApproxRoot(sdk.NewDec(32), sdk.MustNewDecFromStr("2.5"))
+	require.Nil(t, err)
+	require.True(t, actual.Sub(sdk.NewDec(4)).Abs().LTE(sdk.NewDecWithPrec(1, 15)))
+
+	_, err = ApproxRoot(sdk.NewDec(32), sdk.ZeroDec())
+	require.Error(t, err)
+}
+
+func TestInvertConvex(t *testing.T) {
+	// f(x) = x^3 + x, so that f(2) = 10, starting from a loose upper bound
+	f := func(x sdk.Dec) (sdk.Dec, error) { return x.Power(3).Add(x), nil }
+	df := func(x sdk.Dec) (sdk.Dec, error) { return x.Power(2).MulInt64(3).Add(sdk.OneDec()), nil }
+
+	actual, err := invertConvex(f, df, sdk.NewDec(10), sdk.NewDec(10))
+	require.Nil(t, err)
+	require.True(t, actual.LTE(sdk.NewDec(2)))
+	require.True(t, sdk.NewDec(2).Sub(actual).LTE(sdk.NewDecWithPrec(1, 15)))
+
+	// Zero is the inverse of zero
+	actual, err = invertConvex(f, df, sdk.ZeroDec(), sdk.NewDec(10))
+	require.Nil(t, err)
+	require.Equal(t, sdk.ZeroDec(), actual)
+
+	// An upper bound that is too far from the inverse does not converge
+	_, err = invertConvex(f, df, sdk.NewDec(10), sdk.NewDec(1000000000000))
+	require.Error(t, err)
+}
+
+func TestCheckedArithmeticOverflowReturnsError(t *testing.T) {
+	large := sdk.NewDecFromBigInt(new(big.Int).Lsh(big.NewInt(1), 150))
+
+	// 2^150 * 2^150 overflows, but 2^150 * 2 does not
+	_, err := mulDec(large, large)
+	require.True(t, errors.Is(err, ErrCurveOverflow))
+	actual, err := mulDec(large, sdk.NewDec(2))
+	require.Nil(t, err)
+	require.Equal(t, large.MulInt64(2), actual)
+
+	// 2^150 / 10^-18 does not overflow, but 2^200 / 10^-18 does
+	_, err = quoDec(large, sdk.SmallestDec())
+	require.Nil(t, err)
+	larger := sdk.NewDecFromBigInt(new(big.Int).Lsh(big.NewInt(1), 200))
+	_, err = quoDec(larger, sdk.SmallestDec())
+	require.True(t, errors.Is(err, ErrCurveOverflow))
+
+	// Division by zero is an error
+	_, err = quoDec(large, sdk.ZeroDec())
+	require.True(t, errors.Is(err, ErrCurveEvaluationFailed))
+
+	// Integer powers match sdk.Dec.Power unless they overflow
+	actual, err = powerDec(sdk.NewDec(3), 5)
+	require.Nil(t, err)
+	require.Equal(t, sdk.NewDec(3).Power(5), actual)
+	_, err = powerDec(sdk.NewDec(2), 300)
+	require.True(t, errors.Is(err, ErrCurveOverflow))
+}
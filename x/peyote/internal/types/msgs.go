@@ -1,19 +1,30 @@
 package types
 
 import (
+	"crypto/sha256"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	tmbytes "github.com/tendermint/tendermint/libs/bytes"
 	"strings"
 )
 
 const (
-	TypeMsgCreateBond         = "create_bond"
-	TypeMsgEditBond           = "edit_bond"
-	TypeMsgBuy                = "buy"
-	TypeMsgSell               = "sell"
-	TypeMsgSwap               = "swap"
-	TypeMsgMakeOutcomePayment = "make_outcome_payment"
-	TypeMsgWithdrawShare      = "withdraw_share"
+	TypeMsgCreateBond           = "create_bond"
+	TypeMsgEditBond             = "edit_bond"
+	TypeMsgBuy                  = "buy"
+	TypeMsgSell                 = "sell"
+	TypeMsgSwap                 = "swap"
+	TypeMsgMakeOutcomePayment   = "make_outcome_payment"
+	TypeMsgWithdrawShare        = "withdraw_share"
+	TypeMsgSettleBond           = "settle_bond"
+	TypeMsgLimitBuy             = "limit_buy"
+	TypeMsgLimitSell            = "limit_sell"
+	TypeMsgSpendBuy             = "spend_buy"
+	TypeMsgCancelOrder          = "cancel_order"
+	TypeMsgCommitOrder          = "commit_order"
+	TypeMsgRevealOrder          = "reveal_order"
+	TypeMsgCreateRecurringOrder = "create_recurring_order"
+	TypeMsgCancelRecurringOrder = "cancel_recurring_order"
 )
 
 type MsgCreateBond struct {
@@ -34,15 +45,29 @@ type MsgCreateBond struct {
 	AllowSells             bool             `json:"allow_sells" yaml:"allow_sells"`
 	Signers                []sdk.AccAddress `json:"signers" yaml:"signers"`
 	BatchBlocks            sdk.Uint         `json:"batch_blocks" yaml:"batch_blocks"`
+	RevealBlocks           sdk.Uint         `json:"reveal_blocks" yaml:"reveal_blocks"`
+	ForfeitUnrevealed      bool             `json:"forfeit_unrevealed" yaml:"forfeit_unrevealed"`
+	MaxBatchOrders         sdk.Uint         `json:"max_batch_orders" yaml:"max_batch_orders"`
+	MaxBatchVolume         sdk.Uint         `json:"max_batch_volume" yaml:"max_batch_volume"`
+	RollOverOrders         bool             `json:"roll_over_orders" yaml:"roll_over_orders"`
 	OutcomePayment         sdk.Coins        `json:"outcome_payment" yaml:"outcome_payment"`
+	OutcomeTranches        []OutcomeTranche `json:"outcome_tranches" yaml:"outcome_tranches"`
+	OutcomePayers          []sdk.AccAddress `json:"outcome_payers" yaml:"outcome_payers"`
+	Evaluators             []sdk.AccAddress `json:"evaluators" yaml:"evaluators"`
+	AutoDistribute         bool             `json:"auto_distribute" yaml:"auto_distribute"`
+	ClaimBlocks            sdk.Uint         `json:"claim_blocks" yaml:"claim_blocks"`
+	SweepToCommunityPool   bool             `json:"sweep_to_community_pool" yaml:"sweep_to_community_pool"`
 }
 
 func NewMsgCreateBond(token, name, description string, creator sdk.AccAddress,
 	functionType string, functionParameters FunctionParams, reserveTokens []string,
 	txFeePercentage, exitFeePercentage sdk.Dec, feeAddress sdk.AccAddress, maxSupply sdk.Coin,
 	orderQuantityLimits sdk.Coins, sanityRate, sanityMarginPercentage sdk.Dec,
-	allowSell bool, signers []sdk.AccAddress, batchBlocks sdk.Uint,
-	outcomePayment sdk.Coins) MsgCreateBond {
+	allowSell bool, signers []sdk.AccAddress, batchBlocks, revealBlocks sdk.Uint,
+	forfeitUnrevealed bool, maxBatchOrders, maxBatchVolume sdk.Uint,
+	rollOverOrders bool, outcomePayment sdk.Coins,
+	outcomeTranches []OutcomeTranche, outcomePayers, evaluators []sdk.AccAddress,
+	autoDistribute bool, claimBlocks sdk.Uint, sweepToCommunityPool bool) MsgCreateBond {
 	return MsgCreateBond{
 		Token:                  token,
 		Name:                   name,
@@ -61,7 +86,18 @@ func NewMsgCreateBond(token, name, description string, creator sdk.AccAddress,
 		AllowSells:             allowSell,
 		Signers:                signers,
 		BatchBlocks:            batchBlocks,
+		RevealBlocks:           revealBlocks,
+		ForfeitUnrevealed:      forfeitUnrevealed,
+		MaxBatchOrders:         maxBatchOrders,
+		MaxBatchVolume:         maxBatchVolume,
+		RollOverOrders:         rollOverOrders,
 		OutcomePayment:         outcomePayment,
+		OutcomeTranches:        outcomeTranches,
+		OutcomePayers:          outcomePayers,
+		Evaluators:             evaluators,
+		AutoDistribute:         autoDistribute,
+		ClaimBlocks:            claimBlocks,
+		SweepToCommunityPool:   sweepToCommunityPool,
 	}
 }
 
@@ -82,7 +118,8 @@ func (msg MsgCreateBond) ValidateBasic() error {
 	} else if strings.TrimSpace(msg.FunctionType) == "" {
 		return sdkerrors.Wrap(ErrArgumentCannotBeEmpty, "Function Type")
 	}
-	// Note: FunctionParameters and OutcomePayment can be empty
+	// Note: FunctionParameters, OutcomePayment, OutcomeTranches, OutcomePayers
+	// and Evaluators can be empty
 
 	// Check that bond token is a valid token name
 	err := CheckCoinDenom(msg.Token)
@@ -139,6 +176,52 @@ func (msg MsgCreateBond) ValidateBasic() error {
 		return sdkerrors.Wrap(ErrArgumentMustBePositive, "MaxSupply")
 	}
 
+	// Check that the reveal phase leaves at least one block for commitments
+	if msg.RevealBlocks.GTE(msg.BatchBlocks) {
+		return sdkerrors.Wrapf(ErrRevealBlocksNotLessThanBatchBlocks,
+			"%s >= %s", msg.RevealBlocks, msg.BatchBlocks)
+	}
+
+	// Validate outcome tranches, which replace the outcome payment
+	for i, tranche := range msg.OutcomeTranches {
+		if err := tranche.Validate(); err != nil {
+			return sdkerrors.Wrapf(err, "outcome tranche %d", i)
+		}
+	}
+	if !msg.OutcomePayment.Empty() && len(msg.OutcomeTranches) != 0 {
+		return ErrOutcomePaymentAndTranches
+	}
+
+	// Check that there is an outcome payment to distribute automatically
+	if msg.AutoDistribute && msg.OutcomePayment.Empty() && len(msg.OutcomeTranches) == 0 {
+		return sdkerrors.Wrap(ErrArgumentCannotBeEmpty,
+			"OutcomePayment or OutcomeTranches (required for automatic distribution)")
+	}
+
+	// Check that outcome payers and evaluators are valid and that there is an
+	// outcome payment for them to make or attest
+	for _, p := range msg.OutcomePayers {
+		if p.Empty() {
+			return sdkerrors.Wrap(ErrArgumentCannotBeEmpty, "Outcome Payer")
+		}
+	}
+	for _, e := range msg.Evaluators {
+		if e.Empty() {
+			return sdkerrors.Wrap(ErrArgumentCannotBeEmpty, "Evaluator")
+		}
+	}
+	if (len(msg.OutcomePayers) != 0 || len(msg.Evaluators) != 0) &&
+		msg.OutcomePayment.Empty() && len(msg.OutcomeTranches) == 0 {
+		return sdkerrors.Wrap(ErrArgumentCannotBeEmpty,
+			"OutcomePayment or OutcomeTranches (required for outcome payers and evaluators)")
+	}
+
+	// Check that there is a claim deadline after which to sweep the reserve
+	if msg.SweepToCommunityPool && msg.ClaimBlocks.IsZero() {
+		return sdkerrors.Wrap(ErrArgumentMustBePositive,
+			"ClaimBlocks (required for sweeping to the community pool)")
+	}
+
 	// Note: uniqueness of reserve tokens checked when parsing
 
 	return nil
@@ -231,17 +314,23 @@ func (msg MsgEditBond) Route() string { return RouterKey }
 
 func (msg MsgEditBond) Type() string { return TypeMsgEditBond }
 
+// MsgBuy is a buy of bond tokens paid for by the buyer. If a recipient is
+// specified, the bond tokens bought are sent to the recipient rather than to
+// the buyer, who still receives any unused max prices and refunds.
 type MsgBuy struct {
 	Buyer     sdk.AccAddress `json:"buyer" yaml:"buyer"`
 	Amount    sdk.Coin       `json:"amount" yaml:"amount"`
 	MaxPrices sdk.Coins      `json:"max_prices" yaml:"max_prices"`
+	Recipient sdk.AccAddress `json:"recipient,omitempty" yaml:"recipient"`
 }
 
-func NewMsgBuy(buyer sdk.AccAddress, amount sdk.Coin, maxPrices sdk.Coins) MsgBuy {
+func NewMsgBuy(buyer sdk.AccAddress, amount sdk.Coin, maxPrices sdk.Coins,
+	recipient sdk.AccAddress) MsgBuy {
 	return MsgBuy{
 		Buyer:     buyer,
 		Amount:    amount,
 		MaxPrices: maxPrices,
+		Recipient: recipient,
 	}
 }
 
@@ -263,7 +352,7 @@ func (msg MsgBuy) ValidateBasic() error {
 		return sdkerrors.Wrap(sdkerrors.ErrInvalidCoins, "maxprices is invalid")
 	}
 
-	return nil
+	return ValidateRecipient(msg.Recipient)
 }
 
 func (msg MsgBuy) GetSignBytes() []byte {
@@ -278,15 +367,23 @@ func (msg MsgBuy) Route() string { return RouterKey }
 
 func (msg MsgBuy) Type() string { return TypeMsgBuy }
 
+// MsgSell is a sell of the seller's bond tokens. If a recipient is specified,
+// the returns of the sell are sent to the recipient rather than to the seller,
+// who still receives any refunds.
 type MsgSell struct {
-	Seller sdk.AccAddress `json:"seller" yaml:"seller"`
-	Amount sdk.Coin       `json:"amount" yaml:"amount"`
+	Seller     sdk.AccAddress `json:"seller" yaml:"seller"`
+	Amount     sdk.Coin       `json:"amount" yaml:"amount"`
+	MinReturns sdk.Coins      `json:"min_returns" yaml:"min_returns"`
+	Recipient  sdk.AccAddress `json:"recipient,omitempty" yaml:"recipient"`
 }
 
-func NewMsgSell(seller sdk.AccAddress, amount sdk.Coin) MsgSell {
+func NewMsgSell(seller sdk.AccAddress, amount sdk.Coin, minReturns sdk.Coins,
+	recipient sdk.AccAddress) MsgSell {
 	return MsgSell{
-		Seller: seller,
-		Amount: amount,
+		Seller:     seller,
+		Amount:     amount,
+		MinReturns: minReturns,
+		Recipient:  recipient,
 	}
 }
 
@@ -303,7 +400,12 @@ func (msg MsgSell) ValidateBasic() error {
 		return sdkerrors.Wrap(ErrArgumentMustBePositive, "Amount")
 	}
 
-	return nil
+	// Check that min returns valid (can be empty)
+	if !msg.MinReturns.IsValid() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidCoins, "min returns is invalid")
+	}
+
+	return ValidateRecipient(msg.Recipient)
 }
 
 func (msg MsgSell) GetSignBytes() []byte {
@@ -318,19 +420,27 @@ func (msg MsgSell) Route() string { return RouterKey }
 
 func (msg MsgSell) Type() string { return TypeMsgSell }
 
+// MsgSwap is a swap of the swapper's reserve tokens. If a recipient is
+// specified, the returns of the swap are sent to the recipient rather than to
+// the swapper, who still receives any refunds.
 type MsgSwap struct {
-	Swapper   sdk.AccAddress `json:"swapper" yaml:"swapper"`
-	BondToken string         `json:"bond_token" yaml:"bond_token"`
-	From      sdk.Coin       `json:"from" yaml:"from"`
-	ToToken   string         `json:"to_token" yaml:"to_token"`
+	Swapper    sdk.AccAddress `json:"swapper" yaml:"swapper"`
+	BondToken  string         `json:"bond_token" yaml:"bond_token"`
+	From       sdk.Coin       `json:"from" yaml:"from"`
+	ToToken    string         `json:"to_token" yaml:"to_token"`
+	MinReturns sdk.Coins      `json:"min_returns" yaml:"min_returns"`
+	Recipient  sdk.AccAddress `json:"recipient,omitempty" yaml:"recipient"`
 }
 
-func NewMsgSwap(swapper sdk.AccAddress, bondToken string, from sdk.Coin, toToken string) MsgSwap {
+func NewMsgSwap(swapper sdk.AccAddress, bondToken string, from sdk.Coin,
+	toToken string, minReturns sdk.Coins, recipient sdk.AccAddress) MsgSwap {
 	return MsgSwap{
-		Swapper:   swapper,
-		BondToken: bondToken,
-		From:      from,
-		ToToken:   toToken,
+		Swapper:    swapper,
+		BondToken:  bondToken,
+		From:       from,
+		ToToken:    toToken,
+		MinReturns: minReturns,
+		Recipient:  recipient,
 	}
 }
 
@@ -365,8 +475,19 @@ func (msg MsgSwap) ValidateBasic() error {
 		return sdkerrors.Wrap(ErrArgumentMustBePositive, "FromAmount")
 	}
 
+	// Check that min returns valid (can be empty) and only in the to token
+	if !msg.MinReturns.IsValid() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidCoins, "min returns is invalid")
+	}
+	for _, r := range msg.MinReturns {
+		if r.Denom != msg.ToToken {
+			return sdkerrors.Wrapf(sdkerrors.ErrInvalidCoins,
+				"min returns %s are not in the to token %s", msg.MinReturns, msg.ToToken)
+		}
+	}
+
 	// Note: From denom and amount must be valid since sdk.Coin
-	return nil
+	return ValidateRecipient(msg.Recipient)
 }
 
 func (msg MsgSwap) GetSignBytes() []byte {
@@ -381,6 +502,22 @@ func (msg MsgSwap) Route() string { return RouterKey }
 
 func (msg MsgSwap) Type() string { return TypeMsgSwap }
 
+// ValidateRecipient checks that the recipient of an order, which is optional,
+// is a valid address if specified
+func ValidateRecipient(recipient sdk.AccAddress) error {
+	if recipient.Empty() {
+		return nil
+	}
+	err := sdk.VerifyAddressFormat(recipient)
+	if err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "recipient: %s", err)
+	}
+	return nil
+}
+
+// MsgMakeOutcomePayment pays the bond's outcome payment, which settles the
+// bond, or the next of its outcome tranches, of which only the last one
+// settles the bond.
 type MsgMakeOutcomePayment struct {
 	Sender    sdk.AccAddress `json:"sender" yaml:"sender"`
 	BondToken string         `json:"bond_token" yaml:"bond_token"`
@@ -422,15 +559,21 @@ func (msg MsgMakeOutcomePayment) Route() string { return RouterKey }
 
 func (msg MsgMakeOutcomePayment) Type() string { return TypeMsgMakeOutcomePayment }
 
+// MsgWithdrawShare withdraws the recipient's share of a settled bond's reserve
+// for an amount of the bond tokens that the recipient held when the bond was
+// settled. If no amount is specified, the recipient's entire remaining share
+// is withdrawn.
 type MsgWithdrawShare struct {
 	Recipient sdk.AccAddress `json:"recipient" yaml:"recipient"`
 	BondToken string         `json:"bond_token" yaml:"bond_token"`
+	Amount    sdk.Coins      `json:"amount" yaml:"amount"`
 }
 
-func NewMsgWithdrawShare(recipient sdk.AccAddress, bondToken string) MsgWithdrawShare {
+func NewMsgWithdrawShare(recipient sdk.AccAddress, bondToken string, amount sdk.Coins) MsgWithdrawShare {
 	return MsgWithdrawShare{
 		Recipient: recipient,
 		BondToken: bondToken,
+		Amount:    amount,
 	}
 }
 
@@ -448,6 +591,15 @@ func (msg MsgWithdrawShare) ValidateBasic() error {
 		return err
 	}
 
+	// Validate amount, if specified, which has to be in the bond token
+	if !msg.Amount.Empty() {
+		if !msg.Amount.IsValid() {
+			return sdkerrors.Wrap(sdkerrors.ErrInvalidCoins, "amount is invalid")
+		} else if len(msg.Amount) != 1 || msg.Amount[0].Denom != msg.BondToken {
+			return sdkerrors.Wrap(ErrOrderNotForBond, msg.Amount.String())
+		}
+	}
+
 	return nil
 }
 
@@ -462,3 +614,502 @@ func (msg MsgWithdrawShare) GetSigners() []sdk.AccAddress {
 func (msg MsgWithdrawShare) Route() string { return RouterKey }
 
 func (msg MsgWithdrawShare) Type() string { return TypeMsgWithdrawShare }
+
+// MsgSettleBond settles a bond whose outcome payment is made as a schedule of
+// outcome tranches before all of the tranches have been paid. Like MsgEditBond,
+// it must be signed by all of the bond's signers.
+type MsgSettleBond struct {
+	BondToken string           `json:"bond_token" yaml:"bond_token"`
+	Settler   sdk.AccAddress   `json:"settler" yaml:"settler"`
+	Signers   []sdk.AccAddress `json:"signers" yaml:"signers"`
+}
+
+func NewMsgSettleBond(bondToken string, settler sdk.AccAddress,
+	signers []sdk.AccAddress) MsgSettleBond {
+	return MsgSettleBond{
+		BondToken: bondToken,
+		Settler:   settler,
+		Signers:   signers,
+	}
+}
+
+func (msg MsgSettleBond) ValidateBasic() error {
+	// Check if empty
+	if strings.TrimSpace(msg.BondToken) == "" {
+		return sdkerrors.Wrap(ErrArgumentCannotBeEmpty, "BondToken")
+	} else if msg.Settler.Empty() {
+		return sdkerrors.Wrap(ErrArgumentCannotBeEmpty, "Settler")
+	} else if len(msg.Signers) == 0 {
+		return sdkerrors.Wrap(ErrArgumentCannotBeEmpty, "Signers")
+	}
+
+	// Validate bond token
+	err := CheckCoinDenom(msg.BondToken)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (msg MsgSettleBond) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+func (msg MsgSettleBond) GetSigners() []sdk.AccAddress {
+	return msg.Signers
+}
+
+func (msg MsgSettleBond) Route() string { return RouterKey }
+
+func (msg MsgSettleBond) Type() string { return TypeMsgSettleBond }
+
+type MsgLimitBuy struct {
+	Buyer        sdk.AccAddress `json:"buyer" yaml:"buyer"`
+	Amount       sdk.Coin       `json:"amount" yaml:"amount"`
+	LimitPrice   sdk.Dec        `json:"limit_price" yaml:"limit_price"`
+	ExpiryHeight int64          `json:"expiry_height" yaml:"expiry_height"`
+}
+
+func NewMsgLimitBuy(buyer sdk.AccAddress, amount sdk.Coin, limitPrice sdk.Dec,
+	expiryHeight int64) MsgLimitBuy {
+	return MsgLimitBuy{
+		Buyer:        buyer,
+		Amount:       amount,
+		LimitPrice:   limitPrice,
+		ExpiryHeight: expiryHeight,
+	}
+}
+
+func (msg MsgLimitBuy) ValidateBasic() error {
+	// Check if empty
+	if msg.Buyer.Empty() {
+		return sdkerrors.Wrap(ErrArgumentCannotBeEmpty, "Buyer")
+	}
+
+	// Check that amount valid and non zero
+	if !msg.Amount.IsValid() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidCoins, "amount is invalid")
+	} else if msg.Amount.Amount.IsZero() {
+		return sdkerrors.Wrap(ErrArgumentMustBePositive, "Amount")
+	}
+
+	// Check that limit price and expiry height are positive
+	if msg.LimitPrice.IsNil() || !msg.LimitPrice.IsPositive() {
+		return sdkerrors.Wrap(ErrArgumentMustBePositive, "LimitPrice")
+	} else if msg.ExpiryHeight <= 0 {
+		return sdkerrors.Wrap(ErrArgumentMustBePositive, "ExpiryHeight")
+	}
+
+	return nil
+}
+
+func (msg MsgLimitBuy) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+func (msg MsgLimitBuy) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Buyer}
+}
+
+func (msg MsgLimitBuy) Route() string { return RouterKey }
+
+func (msg MsgLimitBuy) Type() string { return TypeMsgLimitBuy }
+
+type MsgLimitSell struct {
+	Seller       sdk.AccAddress `json:"seller" yaml:"seller"`
+	Amount       sdk.Coin       `json:"amount" yaml:"amount"`
+	LimitPrice   sdk.Dec        `json:"limit_price" yaml:"limit_price"`
+	ExpiryHeight int64          `json:"expiry_height" yaml:"expiry_height"`
+}
+
+func NewMsgLimitSell(seller sdk.AccAddress, amount sdk.Coin, limitPrice sdk.Dec,
+	expiryHeight int64) MsgLimitSell {
+	return MsgLimitSell{
+		Seller:       seller,
+		Amount:       amount,
+		LimitPrice:   limitPrice,
+		ExpiryHeight: expiryHeight,
+	}
+}
+
+func (msg MsgLimitSell) ValidateBasic() error {
+	// Check if empty
+	if msg.Seller.Empty() {
+		return sdkerrors.Wrap(ErrArgumentCannotBeEmpty, "Seller")
+	}
+
+	// Check that amount valid and non zero
+	if !msg.Amount.IsValid() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidCoins, "amount is invalid")
+	} else if msg.Amount.Amount.IsZero() {
+		return sdkerrors.Wrap(ErrArgumentMustBePositive, "Amount")
+	}
+
+	// Check that limit price is not negative and expiry height is positive
+	if msg.LimitPrice.IsNil() || msg.LimitPrice.IsNegative() {
+		return sdkerrors.Wrap(ErrArgumentCannotBeNegative, "LimitPrice")
+	} else if msg.ExpiryHeight <= 0 {
+		return sdkerrors.Wrap(ErrArgumentMustBePositive, "ExpiryHeight")
+	}
+
+	return nil
+}
+
+func (msg MsgLimitSell) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+func (msg MsgLimitSell) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Seller}
+}
+
+func (msg MsgLimitSell) Route() string { return RouterKey }
+
+func (msg MsgLimitSell) Type() string { return TypeMsgLimitSell }
+
+type MsgSpendBuy struct {
+	Buyer     sdk.AccAddress `json:"buyer" yaml:"buyer"`
+	BondToken string         `json:"bond_token" yaml:"bond_token"`
+	Spend     sdk.Coins      `json:"spend" yaml:"spend"`
+}
+
+func NewMsgSpendBuy(buyer sdk.AccAddress, bondToken string, spend sdk.Coins) MsgSpendBuy {
+	return MsgSpendBuy{
+		Buyer:     buyer,
+		BondToken: bondToken,
+		Spend:     spend,
+	}
+}
+
+func (msg MsgSpendBuy) ValidateBasic() error {
+	// Check if empty
+	if msg.Buyer.Empty() {
+		return sdkerrors.Wrap(ErrArgumentCannotBeEmpty, "Buyer")
+	} else if strings.TrimSpace(msg.BondToken) == "" {
+		return sdkerrors.Wrap(ErrArgumentCannotBeEmpty, "BondToken")
+	}
+
+	// Validate bond token
+	err := CheckCoinDenom(msg.BondToken)
+	if err != nil {
+		return err
+	}
+
+	// Check that spend valid and non zero
+	if !msg.Spend.IsValid() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidCoins, "spend is invalid")
+	} else if msg.Spend.IsZero() {
+		return sdkerrors.Wrap(ErrArgumentMustBePositive, "Spend")
+	}
+
+	return nil
+}
+
+func (msg MsgSpendBuy) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+func (msg MsgSpendBuy) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Buyer}
+}
+
+func (msg MsgSpendBuy) Route() string { return RouterKey }
+
+func (msg MsgSpendBuy) Type() string { return TypeMsgSpendBuy }
+
+type MsgCancelOrder struct {
+	Sender    sdk.AccAddress `json:"sender" yaml:"sender"`
+	BondToken string         `json:"bond_token" yaml:"bond_token"`
+	OrderId   uint64         `json:"order_id" yaml:"order_id"`
+}
+
+func NewMsgCancelOrder(sender sdk.AccAddress, bondToken string, orderId uint64) MsgCancelOrder {
+	return MsgCancelOrder{
+		Sender:    sender,
+		BondToken: bondToken,
+		OrderId:   orderId,
+	}
+}
+
+func (msg MsgCancelOrder) ValidateBasic() error {
+	// Check if empty
+	if msg.Sender.Empty() {
+		return sdkerrors.Wrap(ErrArgumentCannotBeEmpty, "Sender")
+	} else if strings.TrimSpace(msg.BondToken) == "" {
+		return sdkerrors.Wrap(ErrArgumentCannotBeEmpty, "BondToken")
+	}
+
+	// Validate bond token
+	err := CheckCoinDenom(msg.BondToken)
+	if err != nil {
+		return err
+	}
+
+	// Check that order ID is non zero (IDs start from 1)
+	if msg.OrderId == 0 {
+		return sdkerrors.Wrap(ErrArgumentMustBePositive, "OrderId")
+	}
+
+	return nil
+}
+
+func (msg MsgCancelOrder) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+func (msg MsgCancelOrder) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Sender}
+}
+
+func (msg MsgCancelOrder) Route() string { return RouterKey }
+
+func (msg MsgCancelOrder) Type() string { return TypeMsgCancelOrder }
+
+type MsgCommitOrder struct {
+	Sender    sdk.AccAddress   `json:"sender" yaml:"sender"`
+	BondToken string           `json:"bond_token" yaml:"bond_token"`
+	Hash      tmbytes.HexBytes `json:"hash" yaml:"hash"`
+	Deposit   sdk.Coins        `json:"deposit" yaml:"deposit"`
+}
+
+func NewMsgCommitOrder(sender sdk.AccAddress, bondToken string,
+	hash tmbytes.HexBytes, deposit sdk.Coins) MsgCommitOrder {
+	return MsgCommitOrder{
+		Sender:    sender,
+		BondToken: bondToken,
+		Hash:      hash,
+		Deposit:   deposit,
+	}
+}
+
+func (msg MsgCommitOrder) ValidateBasic() error {
+	// Check if empty
+	if msg.Sender.Empty() {
+		return sdkerrors.Wrap(ErrArgumentCannotBeEmpty, "Sender")
+	} else if strings.TrimSpace(msg.BondToken) == "" {
+		return sdkerrors.Wrap(ErrArgumentCannotBeEmpty, "BondToken")
+	} else if msg.Deposit.Empty() {
+		return sdkerrors.Wrap(ErrArgumentCannotBeEmpty, "Deposit")
+	}
+
+	// Validate bond token
+	err := CheckCoinDenom(msg.BondToken)
+	if err != nil {
+		return err
+	}
+
+	// Check that hash is a SHA-256 hash
+	if len(msg.Hash) != sha256.Size {
+		return sdkerrors.Wrapf(ErrInvalidOrderCommitmentHash,
+			"expected %d bytes but got %d", sha256.Size, len(msg.Hash))
+	}
+
+	// Validate deposit
+	if !msg.Deposit.IsValid() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidCoins, msg.Deposit.String())
+	}
+
+	return nil
+}
+
+func (msg MsgCommitOrder) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+func (msg MsgCommitOrder) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Sender}
+}
+
+func (msg MsgCommitOrder) Route() string { return RouterKey }
+
+func (msg MsgCommitOrder) Type() string { return TypeMsgCommitOrder }
+
+type MsgRevealOrder struct {
+	Sender       sdk.AccAddress `json:"sender" yaml:"sender"`
+	BondToken    string         `json:"bond_token" yaml:"bond_token"`
+	CommitmentId uint64         `json:"commitment_id" yaml:"commitment_id"`
+	Order        RevealedOrder  `json:"order" yaml:"order"`
+}
+
+func NewMsgRevealOrder(sender sdk.AccAddress, bondToken string,
+	commitmentId uint64, order RevealedOrder) MsgRevealOrder {
+	return MsgRevealOrder{
+		Sender:       sender,
+		BondToken:    bondToken,
+		CommitmentId: commitmentId,
+		Order:        order,
+	}
+}
+
+func (msg MsgRevealOrder) ValidateBasic() error {
+	// Check if empty
+	if msg.Sender.Empty() {
+		return sdkerrors.Wrap(ErrArgumentCannotBeEmpty, "Sender")
+	} else if strings.TrimSpace(msg.BondToken) == "" {
+		return sdkerrors.Wrap(ErrArgumentCannotBeEmpty, "BondToken")
+	}
+
+	// Validate bond token
+	err := CheckCoinDenom(msg.BondToken)
+	if err != nil {
+		return err
+	}
+
+	// Check that commitment ID is non zero (IDs start from 1)
+	if msg.CommitmentId == 0 {
+		return sdkerrors.Wrap(ErrArgumentMustBePositive, "CommitmentId")
+	}
+
+	// Validate the order as it will be performed
+	orderMsg, err := msg.Order.ToMsg(msg.Sender, msg.BondToken)
+	if err != nil {
+		return err
+	}
+	return orderMsg.ValidateBasic()
+}
+
+func (msg MsgRevealOrder) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+func (msg MsgRevealOrder) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Sender}
+}
+
+func (msg MsgRevealOrder) Route() string { return RouterKey }
+
+func (msg MsgRevealOrder) Type() string { return TypeMsgRevealOrder }
+
+// MsgCreateRecurringOrder creates a recurring order that places a spend-limited
+// buy (with the amount as the spend) or a sell (of the amount) into every Nth
+// batch of a bond, where N is the interval, until the budget no longer covers
+// the amount or until the end height (if non-zero) is reached.
+type MsgCreateRecurringOrder struct {
+	Owner     sdk.AccAddress `json:"owner" yaml:"owner"`
+	BondToken string         `json:"bond_token" yaml:"bond_token"`
+	OrderType string         `json:"order_type" yaml:"order_type"`
+	Amount    sdk.Coins      `json:"amount" yaml:"amount"`
+	Budget    sdk.Coins      `json:"budget" yaml:"budget"`
+	Interval  sdk.Uint       `json:"interval" yaml:"interval"`
+	EndHeight int64          `json:"end_height" yaml:"end_height"`
+}
+
+func NewMsgCreateRecurringOrder(owner sdk.AccAddress, bondToken, orderType string,
+	amount, budget sdk.Coins, interval sdk.Uint, endHeight int64) MsgCreateRecurringOrder {
+	return MsgCreateRecurringOrder{
+		Owner:     owner,
+		BondToken: bondToken,
+		OrderType: orderType,
+		Amount:    amount,
+		Budget:    budget,
+		Interval:  interval,
+		EndHeight: endHeight,
+	}
+}
+
+func (msg MsgCreateRecurringOrder) ValidateBasic() error {
+	// Check if empty
+	if msg.Owner.Empty() {
+		return sdkerrors.Wrap(ErrArgumentCannotBeEmpty, "Owner")
+	} else if strings.TrimSpace(msg.BondToken) == "" {
+		return sdkerrors.Wrap(ErrArgumentCannotBeEmpty, "BondToken")
+	}
+
+	// Validate bond token
+	err := CheckCoinDenom(msg.BondToken)
+	if err != nil {
+		return err
+	}
+
+	// Check that amount and budget valid and non zero
+	if !msg.Amount.IsValid() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidCoins, "amount is invalid")
+	} else if msg.Amount.IsZero() {
+		return sdkerrors.Wrap(ErrArgumentMustBePositive, "Amount")
+	} else if !msg.Budget.IsValid() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidCoins, "budget is invalid")
+	} else if msg.Budget.IsZero() {
+		return sdkerrors.Wrap(ErrArgumentMustBePositive, "Budget")
+	}
+
+	// Check that a sell amount is in bond tokens, and that a buy amount (i.e.
+	// the spend) is not, since it is checked against the reserve tokens
+	switch msg.OrderType {
+	case AttributeValueBuyOrder:
+		if !msg.Amount.AmountOf(msg.BondToken).IsZero() {
+			return sdkerrors.Wrap(ErrTokenIsNotAValidReserveToken, msg.BondToken)
+		}
+	case AttributeValueSellOrder:
+		if len(msg.Amount) != 1 || msg.Amount[0].Denom != msg.BondToken {
+			return sdkerrors.Wrap(ErrOrderNotForBond, msg.Amount.String())
+		}
+	default:
+		return sdkerrors.Wrap(ErrInvalidOrderType, msg.OrderType)
+	}
+
+	// Check that budget is in the amount's denoms and covers at least one order
+	if !msg.Budget.DenomsSubsetOf(msg.Amount) || !msg.Budget.IsAllGTE(msg.Amount) {
+		return sdkerrors.Wrapf(ErrBudgetDoesNotCoverOrder,
+			"budget %s, amount %s", msg.Budget, msg.Amount)
+	}
+
+	// Check that interval is positive and end height is not negative
+	if msg.Interval.IsZero() {
+		return sdkerrors.Wrap(ErrArgumentMustBePositive, "Interval")
+	} else if msg.EndHeight < 0 {
+		return sdkerrors.Wrap(ErrArgumentCannotBeNegative, "EndHeight")
+	}
+
+	return nil
+}
+
+func (msg MsgCreateRecurringOrder) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+func (msg MsgCreateRecurringOrder) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Owner}
+}
+
+func (msg MsgCreateRecurringOrder) Route() string { return RouterKey }
+
+func (msg MsgCreateRecurringOrder) Type() string { return TypeMsgCreateRecurringOrder }
+
+type MsgCancelRecurringOrder struct {
+	Owner   sdk.AccAddress `json:"owner" yaml:"owner"`
+	OrderId uint64         `json:"order_id" yaml:"order_id"`
+}
+
+func NewMsgCancelRecurringOrder(owner sdk.AccAddress, orderId uint64) MsgCancelRecurringOrder {
+	return MsgCancelRecurringOrder{
+		Owner:   owner,
+		OrderId: orderId,
+	}
+}
+
+func (msg MsgCancelRecurringOrder) ValidateBasic() error {
+	// Check if empty
+	if msg.Owner.Empty() {
+		return sdkerrors.Wrap(ErrArgumentCannotBeEmpty, "Owner")
+	}
+
+	// Check that order ID is non zero (IDs start from 1)
+	if msg.OrderId == 0 {
+		return sdkerrors.Wrap(ErrArgumentMustBePositive, "OrderId")
+	}
+
+	return nil
+}
+
+func (msg MsgCancelRecurringOrder) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+func (msg MsgCancelRecurringOrder) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Owner}
+}
+
+func (msg MsgCancelRecurringOrder) Route() string { return RouterKey }
+
+func (msg MsgCancelRecurringOrder) Type() string { return TypeMsgCancelRecurringOrder }
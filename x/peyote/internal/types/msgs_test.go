@@ -1,6 +1,7 @@
 package types
 
 import (
+	"errors"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/stretchr/testify/require"
 	"testing"
@@ -272,6 +273,130 @@ func TestValidateBasicMsgCreateZeroMaxSupplyGivesError(t *testing.T) {
 	require.NotNil(t, err)
 }
 
+// MsgCreateBond: Reveal blocks must be less than batch blocks
+
+func TestValidateBasicMsgCreateRevealBlocksNotLessThanBatchBlocksGivesError(t *testing.T) {
+	message := newValidMsgCreateBond()
+	message.RevealBlocks = message.BatchBlocks
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+func TestValidateBasicMsgCreateRevealBlocksLessThanBatchBlocksGivesNoError(t *testing.T) {
+	message := newValidMsgCreateBond()
+	message.RevealBlocks = message.BatchBlocks.Sub(sdk.OneUint())
+
+	err := message.ValidateBasic()
+	require.Nil(t, err)
+}
+
+// MsgCreateBond: Automatic distribution requires an outcome payment
+
+func TestValidateBasicMsgCreateBondAutoDistributeWithoutOutcomePaymentGivesError(t *testing.T) {
+	message := newValidMsgCreateBond()
+	message.AutoDistribute = true
+	message.OutcomePayment = nil
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+func TestValidateBasicMsgCreateBondAutoDistributeWithOutcomeTranchesGivesNoError(t *testing.T) {
+	message := newValidMsgCreateBond()
+	message.AutoDistribute = true
+	message.OutcomePayment = nil
+	message.OutcomeTranches = newValidOutcomeTranches()
+
+	err := message.ValidateBasic()
+	require.Nil(t, err)
+}
+
+// MsgCreateBond: Outcome tranches replace the outcome payment
+
+func TestValidateBasicMsgCreateBondOutcomePaymentAndTranchesGivesError(t *testing.T) {
+	message := newValidMsgCreateBond()
+	message.OutcomePayment = sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 10))
+	message.OutcomeTranches = newValidOutcomeTranches()
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+	require.True(t, errors.Is(err, ErrOutcomePaymentAndTranches))
+}
+
+func TestValidateBasicMsgCreateBondEmptyOutcomeTrancheGivesError(t *testing.T) {
+	message := newValidMsgCreateBond()
+	message.OutcomeTranches = newValidOutcomeTranches()
+	message.OutcomeTranches[1].Amount = nil
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+	require.True(t, errors.Is(err, ErrInvalidOutcomeTranche))
+}
+
+func TestValidateBasicMsgCreateBondInvalidOutcomeTrancheGivesError(t *testing.T) {
+	message := newValidMsgCreateBond()
+	message.OutcomeTranches = newValidOutcomeTranches()
+	message.OutcomeTranches[0].Amount = sdk.Coins{sdk.Coin{Denom: reserveToken, Amount: sdk.NewInt(-10)}}
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+	require.True(t, errors.Is(err, ErrInvalidOutcomeTranche))
+}
+
+func TestValidateBasicMsgCreateBondWithOutcomeTranchesGivesNoError(t *testing.T) {
+	message := newValidMsgCreateBond()
+	message.OutcomePayment = nil
+	message.OutcomeTranches = newValidOutcomeTranches()
+
+	err := message.ValidateBasic()
+	require.Nil(t, err)
+}
+
+// MsgCreateBond: Outcome payers and evaluators require an outcome payment
+
+func TestValidateBasicMsgCreateBondEvaluatorsWithoutOutcomePaymentGivesError(t *testing.T) {
+	message := newValidMsgCreateBond()
+	message.OutcomePayment = nil
+	message.Evaluators = []sdk.AccAddress{initCreator}
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+	require.True(t, errors.Is(err, ErrArgumentCannotBeEmpty))
+}
+
+func TestValidateBasicMsgCreateBondEmptyOutcomePayerGivesError(t *testing.T) {
+	message := newValidMsgCreateBond()
+	message.OutcomePayment = sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 10))
+	message.OutcomePayers = []sdk.AccAddress{initCreator, nil}
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+	require.True(t, errors.Is(err, ErrArgumentCannotBeEmpty))
+}
+
+func TestValidateBasicMsgCreateBondWithPayersAndEvaluatorsGivesNoError(t *testing.T) {
+	message := newValidMsgCreateBond()
+	message.OutcomePayment = nil
+	message.OutcomeTranches = newValidOutcomeTranches()
+	message.OutcomePayers = []sdk.AccAddress{initCreator}
+	message.Evaluators = []sdk.AccAddress{initCreator}
+
+	err := message.ValidateBasic()
+	require.Nil(t, err)
+}
+
+// MsgCreateBond: Sweeping to the community pool requires a claim deadline
+
+func TestValidateBasicMsgCreateBondSweepToCommunityPoolWithoutClaimBlocksGivesError(t *testing.T) {
+	message := newValidMsgCreateBond()
+	message.SweepToCommunityPool = true
+	message.ClaimBlocks = sdk.ZeroUint()
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
 // MsgCreateBond: Valid bond creation
 
 func TestValidateBasicMsgCreateBondCorrectlyGivesNoError(t *testing.T) {
@@ -395,6 +520,14 @@ func TestValidateBasicMsgBuyMaxPricesInvalidGivesError(t *testing.T) {
 	require.NotNil(t, err)
 }
 
+func TestValidateBasicMsgBuyInvalidRecipientGivesError(t *testing.T) {
+	message := newValidMsgBuy()
+	message.Recipient = sdk.AccAddress{0x01, 0x02, 0x03}
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
 // MsgBuy: correct buy
 
 func TestValidateBasicMsgBuyCorrectlyGivesNoError(t *testing.T) {
@@ -404,6 +537,14 @@ func TestValidateBasicMsgBuyCorrectlyGivesNoError(t *testing.T) {
 	require.Nil(t, err)
 }
 
+func TestValidateBasicMsgBuyWithRecipientCorrectlyGivesNoError(t *testing.T) {
+	message := newValidMsgBuy()
+	message.Recipient = initFeeAddress
+
+	err := message.ValidateBasic()
+	require.Nil(t, err)
+}
+
 // MsgSell: missing arguments
 
 func TestValidateBasicMsgSellSellerArgumentMissingGivesError(t *testing.T) {
@@ -432,6 +573,22 @@ func TestValidateBasicMsgSellZeroAmountGivesError(t *testing.T) {
 	require.NotNil(t, err)
 }
 
+func TestValidateBasicMsgSellInvalidMinReturnsGivesError(t *testing.T) {
+	message := newValidMsgSell()
+	message.MinReturns = sdk.Coins{sdk.Coin{Denom: reserveToken, Amount: sdk.NewInt(-1)}}
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+func TestValidateBasicMsgSellInvalidRecipientGivesError(t *testing.T) {
+	message := newValidMsgSell()
+	message.Recipient = sdk.AccAddress{0x01, 0x02, 0x03}
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
 // MsgSell: correct sell
 
 func TestValidateBasicMsgSellCorrectlyGivesNoError(t *testing.T) {
@@ -441,6 +598,14 @@ func TestValidateBasicMsgSellCorrectlyGivesNoError(t *testing.T) {
 	require.Nil(t, err)
 }
 
+func TestValidateBasicMsgSellWithMinReturnsCorrectlyGivesNoError(t *testing.T) {
+	message := newValidMsgSell()
+	message.MinReturns = sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 10))
+
+	err := message.ValidateBasic()
+	require.Nil(t, err)
+}
+
 // MsgSwap: missing arguments
 
 func TestValidateBasicMsgSwapSwapperArgumentMissingGivesError(t *testing.T) {
@@ -485,6 +650,14 @@ func TestValidateBasicMsgSwapInvalidToTokenGivesError(t *testing.T) {
 	require.NotNil(t, err)
 }
 
+func TestValidateBasicMsgSwapInvalidRecipientGivesError(t *testing.T) {
+	message := newValidMsgSwap()
+	message.Recipient = sdk.AccAddress{0x01, 0x02, 0x03}
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
 func TestValidateBasicMsgSwapZeroFromAmountGivesError(t *testing.T) {
 	message := newValidMsgSwap()
 	message.From.Amount = sdk.ZeroInt()
@@ -504,6 +677,16 @@ func TestValidateBasicMsgSwapFromAndToSameTokenGivesError(t *testing.T) {
 	require.NotNil(t, err)
 }
 
+// MsgSwap: min returns not in to token
+
+func TestValidateBasicMsgSwapMinReturnsNotInToTokenGivesError(t *testing.T) {
+	message := newValidMsgSwap()
+	message.MinReturns = sdk.NewCoins(sdk.NewInt64Coin(message.From.Denom, 10))
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
 // MsgSwap: correct swap
 
 func TestValidateBasicMsgSwapCorrectlyGivesNoError(t *testing.T) {
@@ -512,3 +695,493 @@ func TestValidateBasicMsgSwapCorrectlyGivesNoError(t *testing.T) {
 	err := message.ValidateBasic()
 	require.Nil(t, err)
 }
+
+func TestValidateBasicMsgSwapWithMinReturnsCorrectlyGivesNoError(t *testing.T) {
+	message := newValidMsgSwap()
+	message.MinReturns = sdk.NewCoins(sdk.NewInt64Coin(message.ToToken, 10))
+
+	err := message.ValidateBasic()
+	require.Nil(t, err)
+}
+
+// MsgLimitBuy: missing arguments
+
+func TestValidateBasicMsgLimitBuyBuyerArgumentMissingGivesError(t *testing.T) {
+	message := newValidMsgLimitBuy()
+	message.Buyer = sdk.AccAddress{}
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+// MsgLimitBuy: invalid arguments
+
+func TestValidateBasicMsgLimitBuyZeroAmountGivesError(t *testing.T) {
+	message := newValidMsgLimitBuy()
+	message.Amount.Amount = sdk.ZeroInt()
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+func TestValidateBasicMsgLimitBuyZeroLimitPriceGivesError(t *testing.T) {
+	message := newValidMsgLimitBuy()
+	message.LimitPrice = sdk.ZeroDec()
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+func TestValidateBasicMsgLimitBuyZeroExpiryHeightGivesError(t *testing.T) {
+	message := newValidMsgLimitBuy()
+	message.ExpiryHeight = 0
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+// MsgLimitBuy: correct limit buy
+
+func TestValidateBasicMsgLimitBuyCorrectlyGivesNoError(t *testing.T) {
+	message := newValidMsgLimitBuy()
+
+	err := message.ValidateBasic()
+	require.Nil(t, err)
+}
+
+// MsgLimitSell: missing arguments
+
+func TestValidateBasicMsgLimitSellSellerArgumentMissingGivesError(t *testing.T) {
+	message := newValidMsgLimitSell()
+	message.Seller = sdk.AccAddress{}
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+// MsgLimitSell: invalid arguments
+
+func TestValidateBasicMsgLimitSellNegativeLimitPriceGivesError(t *testing.T) {
+	message := newValidMsgLimitSell()
+	message.LimitPrice = sdk.NewDec(-1)
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+func TestValidateBasicMsgLimitSellZeroExpiryHeightGivesError(t *testing.T) {
+	message := newValidMsgLimitSell()
+	message.ExpiryHeight = 0
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+// MsgLimitSell: correct limit sell
+
+func TestValidateBasicMsgLimitSellCorrectlyGivesNoError(t *testing.T) {
+	message := newValidMsgLimitSell()
+
+	err := message.ValidateBasic()
+	require.Nil(t, err)
+}
+
+func TestValidateBasicMsgLimitSellZeroLimitPriceGivesNoError(t *testing.T) {
+	message := newValidMsgLimitSell()
+	message.LimitPrice = sdk.ZeroDec()
+
+	err := message.ValidateBasic()
+	require.Nil(t, err)
+}
+
+// MsgSpendBuy: missing arguments
+
+func TestValidateBasicMsgSpendBuyBuyerArgumentMissingGivesError(t *testing.T) {
+	message := newValidMsgSpendBuy()
+	message.Buyer = sdk.AccAddress{}
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+func TestValidateBasicMsgSpendBuyBondTokenArgumentMissingGivesError(t *testing.T) {
+	message := newValidMsgSpendBuy()
+	message.BondToken = ""
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+// MsgSpendBuy: invalid arguments
+
+func TestValidateBasicMsgSpendBuyInvalidBondTokenGivesError(t *testing.T) {
+	message := newValidMsgSpendBuy()
+	message.BondToken = "123abc" // starts with number
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+func TestValidateBasicMsgSpendBuyZeroSpendGivesError(t *testing.T) {
+	message := newValidMsgSpendBuy()
+	message.Spend = sdk.Coins{}
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+// MsgSpendBuy: correct spend buy
+
+func TestValidateBasicMsgSpendBuyCorrectlyGivesNoError(t *testing.T) {
+	message := newValidMsgSpendBuy()
+
+	err := message.ValidateBasic()
+	require.Nil(t, err)
+}
+
+// MsgCancelOrder: missing arguments
+
+func TestValidateBasicMsgCancelOrderSenderArgumentMissingGivesError(t *testing.T) {
+	message := newValidMsgCancelOrder()
+	message.Sender = sdk.AccAddress{}
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+func TestValidateBasicMsgCancelOrderBondTokenArgumentMissingGivesError(t *testing.T) {
+	message := newValidMsgCancelOrder()
+	message.BondToken = ""
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+// MsgCancelOrder: invalid arguments
+
+func TestValidateBasicMsgCancelOrderZeroOrderIdGivesError(t *testing.T) {
+	message := newValidMsgCancelOrder()
+	message.OrderId = 0
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+// MsgCancelOrder: correct cancel order
+
+func TestValidateBasicMsgCancelOrderCorrectlyGivesNoError(t *testing.T) {
+	message := newValidMsgCancelOrder()
+
+	err := message.ValidateBasic()
+	require.Nil(t, err)
+}
+
+// MsgCommitOrder: missing arguments
+
+func TestValidateBasicMsgCommitOrderSenderArgumentMissingGivesError(t *testing.T) {
+	message := newValidMsgCommitOrder()
+	message.Sender = sdk.AccAddress{}
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+func TestValidateBasicMsgCommitOrderBondTokenArgumentMissingGivesError(t *testing.T) {
+	message := newValidMsgCommitOrder()
+	message.BondToken = ""
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+func TestValidateBasicMsgCommitOrderDepositArgumentMissingGivesError(t *testing.T) {
+	message := newValidMsgCommitOrder()
+	message.Deposit = nil
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+// MsgCommitOrder: invalid arguments
+
+func TestValidateBasicMsgCommitOrderInvalidHashGivesError(t *testing.T) {
+	message := newValidMsgCommitOrder()
+	message.Hash = message.Hash[1:] // too short
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+// MsgCommitOrder: correct commit order
+
+func TestValidateBasicMsgCommitOrderCorrectlyGivesNoError(t *testing.T) {
+	message := newValidMsgCommitOrder()
+
+	err := message.ValidateBasic()
+	require.Nil(t, err)
+}
+
+// MsgRevealOrder: missing arguments
+
+func TestValidateBasicMsgRevealOrderSenderArgumentMissingGivesError(t *testing.T) {
+	message := newValidMsgRevealOrder()
+	message.Sender = sdk.AccAddress{}
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+func TestValidateBasicMsgRevealOrderBondTokenArgumentMissingGivesError(t *testing.T) {
+	message := newValidMsgRevealOrder()
+	message.BondToken = ""
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+// MsgRevealOrder: invalid arguments
+
+func TestValidateBasicMsgRevealOrderZeroCommitmentIdGivesError(t *testing.T) {
+	message := newValidMsgRevealOrder()
+	message.CommitmentId = 0
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+func TestValidateBasicMsgRevealOrderInvalidOrderTypeGivesError(t *testing.T) {
+	message := newValidMsgRevealOrder()
+	message.Order.OrderType = "invalid"
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+func TestValidateBasicMsgRevealOrderBuyOfOtherTokenGivesError(t *testing.T) {
+	message := newValidMsgRevealOrder()
+	message.Order.Amount = sdk.NewInt64Coin(reserveToken, 10)
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+func TestValidateBasicMsgRevealOrderZeroAmountGivesError(t *testing.T) {
+	message := newValidMsgRevealOrder()
+	message.Order.Amount = sdk.NewInt64Coin(initToken, 0)
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+// MsgRevealOrder: correct reveal order
+
+func TestValidateBasicMsgRevealOrderCorrectlyGivesNoError(t *testing.T) {
+	message := newValidMsgRevealOrder()
+
+	err := message.ValidateBasic()
+	require.Nil(t, err)
+}
+
+// MsgCreateRecurringOrder: missing arguments
+
+func TestValidateBasicMsgCreateRecurringOrderOwnerArgumentMissingGivesError(t *testing.T) {
+	message := newValidMsgCreateRecurringOrder()
+	message.Owner = sdk.AccAddress{}
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+func TestValidateBasicMsgCreateRecurringOrderBondTokenArgumentMissingGivesError(t *testing.T) {
+	message := newValidMsgCreateRecurringOrder()
+	message.BondToken = ""
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+// MsgCreateRecurringOrder: invalid arguments
+
+func TestValidateBasicMsgCreateRecurringOrderInvalidOrderTypeGivesError(t *testing.T) {
+	message := newValidMsgCreateRecurringOrder()
+	message.OrderType = AttributeValueSwapOrder
+
+	err := message.ValidateBasic()
+	require.True(t, errors.Is(err, ErrInvalidOrderType))
+}
+
+func TestValidateBasicMsgCreateRecurringOrderZeroAmountGivesError(t *testing.T) {
+	message := newValidMsgCreateRecurringOrder()
+	message.Amount = sdk.Coins{}
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+func TestValidateBasicMsgCreateRecurringOrderBuyInBondTokenGivesError(t *testing.T) {
+	message := newValidMsgCreateRecurringOrder()
+	message.Amount = sdk.NewCoins(sdk.NewInt64Coin(initToken, 100))
+	message.Budget = sdk.NewCoins(sdk.NewInt64Coin(initToken, 1000))
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+func TestValidateBasicMsgCreateRecurringOrderSellOfOtherTokenGivesError(t *testing.T) {
+	message := newValidMsgCreateRecurringOrder()
+	message.OrderType = AttributeValueSellOrder
+
+	err := message.ValidateBasic()
+	require.True(t, errors.Is(err, ErrOrderNotForBond))
+}
+
+func TestValidateBasicMsgCreateRecurringOrderBudgetLessThanAmountGivesError(t *testing.T) {
+	message := newValidMsgCreateRecurringOrder()
+	message.Budget = sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 99))
+
+	err := message.ValidateBasic()
+	require.True(t, errors.Is(err, ErrBudgetDoesNotCoverOrder))
+}
+
+func TestValidateBasicMsgCreateRecurringOrderBudgetInOtherDenomGivesError(t *testing.T) {
+	message := newValidMsgCreateRecurringOrder()
+	message.Budget = message.Budget.Add(sdk.NewInt64Coin(reserveToken2, 1000))
+
+	err := message.ValidateBasic()
+	require.True(t, errors.Is(err, ErrBudgetDoesNotCoverOrder))
+}
+
+func TestValidateBasicMsgCreateRecurringOrderZeroIntervalGivesError(t *testing.T) {
+	message := newValidMsgCreateRecurringOrder()
+	message.Interval = sdk.ZeroUint()
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+func TestValidateBasicMsgCreateRecurringOrderNegativeEndHeightGivesError(t *testing.T) {
+	message := newValidMsgCreateRecurringOrder()
+	message.EndHeight = -1
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+// MsgCreateRecurringOrder: correct recurring order
+
+func TestValidateBasicMsgCreateRecurringOrderCorrectlyGivesNoError(t *testing.T) {
+	message := newValidMsgCreateRecurringOrder()
+
+	err := message.ValidateBasic()
+	require.Nil(t, err)
+}
+
+func TestValidateBasicMsgCreateRecurringSellWithoutEndHeightCorrectlyGivesNoError(t *testing.T) {
+	message := newValidMsgCreateRecurringOrder()
+	message.OrderType = AttributeValueSellOrder
+	message.Amount = sdk.NewCoins(sdk.NewInt64Coin(initToken, 10))
+	message.Budget = sdk.NewCoins(sdk.NewInt64Coin(initToken, 25))
+	message.EndHeight = 0
+
+	err := message.ValidateBasic()
+	require.Nil(t, err)
+}
+
+// MsgCancelRecurringOrder
+
+func TestValidateBasicMsgCancelRecurringOrderOwnerArgumentMissingGivesError(t *testing.T) {
+	message := newValidMsgCancelRecurringOrder()
+	message.Owner = sdk.AccAddress{}
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+func TestValidateBasicMsgCancelRecurringOrderZeroOrderIdGivesError(t *testing.T) {
+	message := newValidMsgCancelRecurringOrder()
+	message.OrderId = 0
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+func TestValidateBasicMsgCancelRecurringOrderCorrectlyGivesNoError(t *testing.T) {
+	message := newValidMsgCancelRecurringOrder()
+
+	err := message.ValidateBasic()
+	require.Nil(t, err)
+}
+
+// MsgWithdrawShare
+
+func TestValidateBasicMsgWithdrawShareRecipientArgumentMissingGivesError(t *testing.T) {
+	message := newValidMsgWithdrawShare()
+	message.Recipient = sdk.AccAddress{}
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+func TestValidateBasicMsgWithdrawShareInvalidAmountGivesError(t *testing.T) {
+	message := newValidMsgWithdrawShare()
+	message.Amount = sdk.Coins{sdk.Coin{Denom: initToken, Amount: sdk.NewInt(-10)}}
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+func TestValidateBasicMsgWithdrawShareAmountOfOtherTokenGivesError(t *testing.T) {
+	message := newValidMsgWithdrawShare()
+	message.Amount = sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 10))
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+func TestValidateBasicMsgWithdrawShareCorrectlyGivesNoError(t *testing.T) {
+	message := newValidMsgWithdrawShare()
+
+	err := message.ValidateBasic()
+	require.Nil(t, err)
+}
+
+func TestValidateBasicMsgWithdrawShareWithoutAmountCorrectlyGivesNoError(t *testing.T) {
+	message := newValidMsgWithdrawShare()
+	message.Amount = nil
+
+	err := message.ValidateBasic()
+	require.Nil(t, err)
+}
+
+// MsgSettleBond
+
+func TestValidateBasicMsgSettleBondTokenArgumentMissingGivesError(t *testing.T) {
+	message := newValidMsgSettleBond()
+	message.BondToken = ""
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+func TestValidateBasicMsgSettleBondSettlerArgumentMissingGivesError(t *testing.T) {
+	message := newValidMsgSettleBond()
+	message.Settler = sdk.AccAddress{}
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+func TestValidateBasicMsgSettleBondSignersArgumentMissingGivesError(t *testing.T) {
+	message := newValidMsgSettleBond()
+	message.Signers = nil
+
+	err := message.ValidateBasic()
+	require.NotNil(t, err)
+}
+
+func TestValidateBasicMsgSettleBondCorrectlyGivesNoError(t *testing.T) {
+	message := newValidMsgSettleBond()
+
+	err := message.ValidateBasic()
+	require.Nil(t, err)
+}
@@ -0,0 +1,40 @@
+package types
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+)
+
+// OutcomeTranche is one of the payments in a bond's outcome payment schedule,
+// which are made one after the other (e.g. as the bond's milestones are
+// verified) while the bond is open. If a payer is specified, only the payer
+// can make the payment. The payment is either sent to the reserve or, if the
+// tranche is distributed to holders, paid out to the bond's holders directly.
+type OutcomeTranche struct {
+	Amount              sdk.Coins      `json:"amount" yaml:"amount"`
+	Payer               sdk.AccAddress `json:"payer,omitempty" yaml:"payer"`
+	DistributeToHolders bool           `json:"distribute_to_holders" yaml:"distribute_to_holders"`
+}
+
+func NewOutcomeTranche(amount sdk.Coins, payer sdk.AccAddress,
+	distributeToHolders bool) OutcomeTranche {
+	return OutcomeTranche{
+		Amount:              amount,
+		Payer:               payer,
+		DistributeToHolders: distributeToHolders,
+	}
+}
+
+func (ot OutcomeTranche) Validate() error {
+	if !ot.Amount.IsValid() || ot.Amount.Empty() {
+		return sdkerrors.Wrapf(ErrInvalidOutcomeTranche,
+			"amount [%s] is invalid or empty", ot.Amount)
+	}
+	return nil
+}
+
+// CanBePaidBy returns true if the tranche has no specific payer, or if the
+// address is the tranche's payer.
+func (ot OutcomeTranche) CanBePaidBy(address sdk.AccAddress) bool {
+	return ot.Payer.Empty() || ot.Payer.Equals(address)
+}
@@ -2,17 +2,66 @@ package types
 
 import (
 	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/x/params"
 )
 
 // Parameter store keys
 var (
-	KeyReservedBondTokens = []byte("ReservedBondTokens")
+	KeyReservedBondTokens    = []byte("ReservedBondTokens")
+	KeyBatchHistoryRetention = []byte("BatchHistoryRetention")
+	KeyMaxBatchOrders        = []byte("MaxBatchOrders")
+	KeyMaxBatchVolume        = []byte("MaxBatchVolume")
+	KeyMaxDistributions      = []byte("MaxDistributions")
+	KeyAllowBondTokenReuse   = []byte("AllowBondTokenReuse")
+	KeyMaxLimitOrderMatches  = []byte("MaxLimitOrderMatches")
+	KeyMinLimitOrderAmount   = []byte("MinLimitOrderAmount")
+	KeyMaxRecurringOrders    = []byte("MaxRecurringOrders")
+	KeyMaxLimitOrderExpiry   = []byte("MaxLimitOrderExpiry")
+	KeyMinCommitmentDeposit  = []byte("MinCommitmentDeposit")
 )
 
+// Default number of blocks for which the records of performed batches are
+// kept, which is around one week of 6-second blocks
+const DefaultBatchHistoryRetention uint64 = 100800
+
+// Default maximum number of orders (including order commitments) in the batch
+// of any bond
+const DefaultMaxBatchOrders uint64 = 1000
+
+// Default maximum amount of bond tokens bought and sold in the batch of any bond
+const DefaultMaxBatchVolume uint64 = 1000000000000000000
+
+// Default maximum number of holders to which settled bonds' reserves are
+// distributed automatically at the end of each block
+const DefaultMaxDistributions uint64 = 100
+
+// Default maximum number of limit orders that each batch attempts to match
+const DefaultMaxLimitOrderMatches uint64 = 100
+
+// Default minimum amount of bond tokens bought or sold by a limit order
+const DefaultMinLimitOrderAmount uint64 = 1
+
+// Default maximum number of recurring orders for any bond
+const DefaultMaxRecurringOrders uint64 = 100
+
+// Default maximum number of blocks after which a limit order expires, which is
+// around one week of 6-second blocks
+const DefaultMaxLimitOrderExpiry uint64 = 100800
+
 // peyote parameters
 type Params struct {
-	ReservedBondTokens []string `json:"reserved_bond_tokens" yaml:"reserved_bond_tokens"`
+	ReservedBondTokens    []string  `json:"reserved_bond_tokens" yaml:"reserved_bond_tokens"`
+	BatchHistoryRetention uint64    `json:"batch_history_retention" yaml:"batch_history_retention"`
+	MaxBatchOrders        uint64    `json:"max_batch_orders" yaml:"max_batch_orders"`
+	MaxBatchVolume        uint64    `json:"max_batch_volume" yaml:"max_batch_volume"`
+	MaxDistributions      uint64    `json:"max_distributions" yaml:"max_distributions"`
+	AllowBondTokenReuse   bool      `json:"allow_bond_token_reuse" yaml:"allow_bond_token_reuse"`
+	MaxLimitOrderMatches  uint64    `json:"max_limit_order_matches" yaml:"max_limit_order_matches"`
+	MinLimitOrderAmount   uint64    `json:"min_limit_order_amount" yaml:"min_limit_order_amount"`
+	MaxRecurringOrders    uint64    `json:"max_recurring_orders" yaml:"max_recurring_orders"`
+	MaxLimitOrderExpiry   uint64    `json:"max_limit_order_expiry" yaml:"max_limit_order_expiry"`
+	MinCommitmentDeposit  sdk.Coins `json:"min_commitment_deposit" yaml:"min_commitment_deposit"`
 }
 
 // ParamTable for peyote module.
@@ -20,9 +69,22 @@ func ParamKeyTable() params.KeyTable {
 	return params.NewKeyTable().RegisterParamSet(&Params{})
 }
 
-func NewParams(reservedBondTokens []string) Params {
+func NewParams(reservedBondTokens []string, batchHistoryRetention,
+	maxBatchOrders, maxBatchVolume, maxDistributions uint64, allowBondTokenReuse bool,
+	maxLimitOrderMatches, minLimitOrderAmount, maxRecurringOrders,
+	maxLimitOrderExpiry uint64, minCommitmentDeposit sdk.Coins) Params {
 	return Params{
-		ReservedBondTokens: reservedBondTokens,
+		ReservedBondTokens:    reservedBondTokens,
+		BatchHistoryRetention: batchHistoryRetention,
+		MaxBatchOrders:        maxBatchOrders,
+		MaxBatchVolume:        maxBatchVolume,
+		MaxDistributions:      maxDistributions,
+		AllowBondTokenReuse:   allowBondTokenReuse,
+		MaxLimitOrderMatches:  maxLimitOrderMatches,
+		MinLimitOrderAmount:   minLimitOrderAmount,
+		MaxRecurringOrders:    maxRecurringOrders,
+		MaxLimitOrderExpiry:   maxLimitOrderExpiry,
+		MinCommitmentDeposit:  minCommitmentDeposit,
 	}
 
 }
@@ -30,20 +92,71 @@ func NewParams(reservedBondTokens []string) Params {
 // default peyote module parameters
 func DefaultParams() Params {
 	return Params{
-		ReservedBondTokens: []string{}, // no reserved bond tokens
+		ReservedBondTokens:    []string{}, // no reserved bond tokens
+		BatchHistoryRetention: DefaultBatchHistoryRetention,
+		MaxBatchOrders:        DefaultMaxBatchOrders,
+		MaxBatchVolume:        DefaultMaxBatchVolume,
+		MaxDistributions:      DefaultMaxDistributions,
+		AllowBondTokenReuse:   false, // closed bonds' tokens cannot be reused
+		MaxLimitOrderMatches:  DefaultMaxLimitOrderMatches,
+		MinLimitOrderAmount:   DefaultMinLimitOrderAmount,
+		MaxRecurringOrders:    DefaultMaxRecurringOrders,
+		MaxLimitOrderExpiry:   DefaultMaxLimitOrderExpiry,
+		MinCommitmentDeposit:  sdk.Coins{}, // no minimum deposit
 	}
 }
 
 // validate params
 func ValidateParams(params Params) error {
-	return nil
+	err := validateMaxBatchOrders(params.MaxBatchOrders)
+	if err != nil {
+		return err
+	}
+	err = validateMaxBatchVolume(params.MaxBatchVolume)
+	if err != nil {
+		return err
+	}
+	err = validateMaxDistributions(params.MaxDistributions)
+	if err != nil {
+		return err
+	}
+	err = validateMaxLimitOrderMatches(params.MaxLimitOrderMatches)
+	if err != nil {
+		return err
+	}
+	err = validateMinLimitOrderAmount(params.MinLimitOrderAmount)
+	if err != nil {
+		return err
+	}
+	err = validateMaxRecurringOrders(params.MaxRecurringOrders)
+	if err != nil {
+		return err
+	}
+	err = validateMaxLimitOrderExpiry(params.MaxLimitOrderExpiry)
+	if err != nil {
+		return err
+	}
+	return validateMinCommitmentDeposit(params.MinCommitmentDeposit)
 }
 
 func (p Params) String() string {
 	return fmt.Sprintf(`Bonds Params:
-  Reserved Bond Tokens: %s
+  Reserved Bond Tokens:    %s
+  Batch History Retention: %d
+  Max Batch Orders:        %d
+  Max Batch Volume:        %d
+  Max Distributions:       %d
+  Allow Bond Token Reuse:  %t
+  Max Limit Order Matches: %d
+  Min Limit Order Amount:  %d
+  Max Recurring Orders:    %d
+  Max Limit Order Expiry:  %d
+  Min Commitment Deposit:  %s
 `,
-		p.ReservedBondTokens)
+		p.ReservedBondTokens, p.BatchHistoryRetention, p.MaxBatchOrders,
+		p.MaxBatchVolume, p.MaxDistributions, p.AllowBondTokenReuse, p.MaxLimitOrderMatches,
+		p.MinLimitOrderAmount, p.MaxRecurringOrders, p.MaxLimitOrderExpiry,
+		p.MinCommitmentDeposit)
 }
 
 func validateReservedBondTokens(i interface{}) error {
@@ -54,9 +167,115 @@ func validateReservedBondTokens(i interface{}) error {
 	return nil
 }
 
+func validateBatchHistoryRetention(i interface{}) error {
+	_, ok := i.(uint64)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	return nil
+}
+
+func validateMaxBatchOrders(i interface{}) error {
+	v, ok := i.(uint64)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	} else if v == 0 {
+		return fmt.Errorf("max batch orders must be positive")
+	}
+	return nil
+}
+
+func validateMaxBatchVolume(i interface{}) error {
+	v, ok := i.(uint64)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	} else if v == 0 {
+		return fmt.Errorf("max batch volume must be positive")
+	}
+	return nil
+}
+
+func validateMaxDistributions(i interface{}) error {
+	v, ok := i.(uint64)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	} else if v == 0 {
+		return fmt.Errorf("max distributions must be positive")
+	}
+	return nil
+}
+
+func validateAllowBondTokenReuse(i interface{}) error {
+	_, ok := i.(bool)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	return nil
+}
+
+func validateMaxLimitOrderMatches(i interface{}) error {
+	v, ok := i.(uint64)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	} else if v == 0 {
+		return fmt.Errorf("max limit order matches must be positive")
+	}
+	return nil
+}
+
+func validateMinLimitOrderAmount(i interface{}) error {
+	v, ok := i.(uint64)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	} else if v == 0 {
+		return fmt.Errorf("min limit order amount must be positive")
+	}
+	return nil
+}
+
+func validateMaxRecurringOrders(i interface{}) error {
+	v, ok := i.(uint64)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	} else if v == 0 {
+		return fmt.Errorf("max recurring orders must be positive")
+	}
+	return nil
+}
+
+func validateMaxLimitOrderExpiry(i interface{}) error {
+	v, ok := i.(uint64)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	} else if v == 0 {
+		return fmt.Errorf("max limit order expiry must be positive")
+	}
+	return nil
+}
+
+func validateMinCommitmentDeposit(i interface{}) error {
+	v, ok := i.(sdk.Coins)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	} else if !v.IsValid() {
+		return fmt.Errorf("invalid min commitment deposit: %s", v)
+	}
+	return nil
+}
+
 // Implements params.ParamSet
 func (p *Params) ParamSetPairs() params.ParamSetPairs {
 	return params.ParamSetPairs{
 		{KeyReservedBondTokens, &p.ReservedBondTokens, validateReservedBondTokens},
+		{KeyBatchHistoryRetention, &p.BatchHistoryRetention, validateBatchHistoryRetention},
+		{KeyMaxBatchOrders, &p.MaxBatchOrders, validateMaxBatchOrders},
+		{KeyMaxBatchVolume, &p.MaxBatchVolume, validateMaxBatchVolume},
+		{KeyMaxDistributions, &p.MaxDistributions, validateMaxDistributions},
+		{KeyAllowBondTokenReuse, &p.AllowBondTokenReuse, validateAllowBondTokenReuse},
+		{KeyMaxLimitOrderMatches, &p.MaxLimitOrderMatches, validateMaxLimitOrderMatches},
+		{KeyMinLimitOrderAmount, &p.MinLimitOrderAmount, validateMinLimitOrderAmount},
+		{KeyMaxRecurringOrders, &p.MaxRecurringOrders, validateMaxRecurringOrders},
+		{KeyMaxLimitOrderExpiry, &p.MaxLimitOrderExpiry, validateMaxLimitOrderExpiry},
+		{KeyMinCommitmentDeposit, &p.MinCommitmentDeposit, validateMinCommitmentDeposit},
 	}
 }
@@ -2,7 +2,7 @@ package types
 
 import (
 	"encoding/json"
-	"fmt"
+	"errors"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
 	"sort"
@@ -13,41 +13,25 @@ const (
 	SigmoidFunction   = "sigmoid_function"
 	SwapperFunction   = "swapper_function"
 	AugmentedFunction = "augmented_function"
+	BancorFunction    = "bancor_function"
 
-	HatchState  = "HATCH"
-	OpenState   = "OPEN"
-	SettleState = "SETTLE"
+	HatchState      = "HATCH"
+	OpenState       = "OPEN"
+	SettleState     = "SETTLE"
+	QuarantineState = "QUARANTINE"
+	ClosedState     = "CLOSED"
 
 	DoNotModifyField = "[do-not-modify]"
 
 	AnyNumberOfReserveTokens = -1
+
+	// Number of evenly-spaced supplies up to the max supply (inclusive) at
+	// which a bond's curve is evaluated when the bond is created
+	NoOfCurveCheckpoints = 10
 )
 
 type FunctionParamRestrictions func(paramsMap map[string]sdk.Dec) error
 
-var (
-	RequiredParamsForFunctionType = map[string][]string{
-		PowerFunction:     {"m", "n", "c"},
-		SigmoidFunction:   {"a", "b", "c"},
-		SwapperFunction:   nil,
-		AugmentedFunction: {"d0", "p0", "theta", "kappa"},
-	}
-
-	NoOfReserveTokensForFunctionType = map[string]int{
-		PowerFunction:     AnyNumberOfReserveTokens,
-		SigmoidFunction:   AnyNumberOfReserveTokens,
-		SwapperFunction:   2,
-		AugmentedFunction: AnyNumberOfReserveTokens,
-	}
-
-	ExtraParameterRestrictions = map[string]FunctionParamRestrictions{
-		PowerFunction:     powerParameterRestrictions,
-		SigmoidFunction:   sigmoidParameterRestrictions,
-		SwapperFunction:   nil,
-		AugmentedFunction: augmentedParameterRestrictions,
-	}
-)
-
 type FunctionParam struct {
 	Param string  `json:"param" yaml:"param"`
 	Value sdk.Dec `json:"value" yaml:"value"`
@@ -116,70 +100,6 @@ func (fps FunctionParams) AsMap() (paramsMap map[string]sdk.Dec) {
 	return paramsMap
 }
 
-func powerParameterRestrictions(paramsMap map[string]sdk.Dec) error {
-	// Power exception 1: n must be an integer, otherwise x^n loop does not work
-	val, ok := paramsMap["n"]
-	if !ok {
-		panic("did not find parameter n for power function")
-	} else if !val.TruncateDec().Equal(val) {
-		return sdkerrors.Wrap(ErrArgumentMustBeInteger, "FunctionParams:n")
-	}
-	return nil
-}
-
-func sigmoidParameterRestrictions(paramsMap map[string]sdk.Dec) error {
-	// Sigmoid exception 1: c != 0, otherwise we run into divisions by zero
-	val, ok := paramsMap["c"]
-	if !ok {
-		panic("did not find parameter c for sigmoid function")
-	} else if !val.IsPositive() {
-		return sdkerrors.Wrap(ErrArgumentMustBePositive, "FunctionParams:c")
-	}
-	return nil
-}
-
-func augmentedParameterRestrictions(paramsMap map[string]sdk.Dec) error {
-	// Augmented exception 1.1: d0 must be an integer, since it is a token amount
-	// Augmented exception 1.2: d0 != 0, otherwise we run into divisions by zero
-	val, ok := paramsMap["d0"]
-	if !ok {
-		panic("did not find parameter d0 for augmented function")
-	} else if !val.TruncateDec().Equal(val) {
-		return sdkerrors.Wrap(ErrArgumentMustBeInteger, "FunctionParams:d0")
-	} else if !val.IsPositive() {
-		return sdkerrors.Wrap(ErrArgumentMustBePositive, "FunctionParams:d0")
-	}
-
-	// Augmented exception 2: p0 != 0, otherwise we run into divisions by zero
-	val, ok = paramsMap["p0"]
-	if !ok {
-		panic("did not find parameter p0 for augmented function")
-	} else if !val.IsPositive() {
-		return sdkerrors.Wrap(ErrArgumentMustBePositive, "FunctionParams:p0")
-	}
-
-	// Augmented exception 3: theta must be from 0 to 1 (excluding 1)
-	val, ok = paramsMap["theta"]
-	if !ok {
-		panic("did not find parameter theta for augmented function")
-	} else if val.LT(sdk.ZeroDec()) || val.GTE(sdk.OneDec()) {
-		return sdkerrors.Wrapf(ErrArgumentMustBeBetween, "%s argument must be between %s and %s", "FunctionParams:theta", "0", "1")
-	}
-
-	// Augmented exception 4.1: kappa must be an integer, since we use it for powers
-	// Augmented exception 4.2: kappa != 0, otherwise we run into divisions by zero
-	val, ok = paramsMap["kappa"]
-	if !ok {
-		panic("did not find parameter kappa for augmented function")
-	} else if !val.TruncateDec().Equal(val) {
-		return sdkerrors.Wrap(ErrArgumentMustBeInteger, "FunctionParams:kappa")
-	} else if !val.IsPositive() {
-		return sdkerrors.Wrap(ErrArgumentMustBePositive, "FunctionParams:kappa")
-	}
-
-	return nil
-}
-
 type Bond struct {
 	Token                  string           `json:"token" yaml:"token"`
 	Name                   string           `json:"name" yaml:"name"`
@@ -200,7 +120,19 @@ type Bond struct {
 	AllowSells             bool             `json:"allow_sells" yaml:"allow_sells"`
 	Signers                []sdk.AccAddress `json:"signers" yaml:"signers"`
 	BatchBlocks            sdk.Uint         `json:"batch_blocks" yaml:"batch_blocks"`
+	RevealBlocks           sdk.Uint         `json:"reveal_blocks" yaml:"reveal_blocks"`
+	ForfeitUnrevealed      bool             `json:"forfeit_unrevealed" yaml:"forfeit_unrevealed"`
+	MaxBatchOrders         sdk.Uint         `json:"max_batch_orders" yaml:"max_batch_orders"`
+	MaxBatchVolume         sdk.Uint         `json:"max_batch_volume" yaml:"max_batch_volume"`
+	RollOverOrders         bool             `json:"roll_over_orders" yaml:"roll_over_orders"`
 	OutcomePayment         sdk.Coins        `json:"outcome_payment" yaml:"outcome_payment"`
+	OutcomeTranches        []OutcomeTranche `json:"outcome_tranches" yaml:"outcome_tranches"`
+	OutcomePayers          []sdk.AccAddress `json:"outcome_payers" yaml:"outcome_payers"`
+	Evaluators             []sdk.AccAddress `json:"evaluators" yaml:"evaluators"`
+	AutoDistribute         bool             `json:"auto_distribute" yaml:"auto_distribute"`
+	ClaimBlocks            sdk.Uint         `json:"claim_blocks" yaml:"claim_blocks"`
+	SweepToCommunityPool   bool             `json:"sweep_to_community_pool" yaml:"sweep_to_community_pool"`
+	TranchesPaid           uint64           `json:"tranches_paid" yaml:"tranches_paid"`
 	State                  string           `json:"state" yaml:"state"`
 }
 
@@ -209,7 +141,11 @@ func NewBond(token, name, description string, creator sdk.AccAddress,
 	txFeePercentage, exitFeePercentage sdk.Dec, feeAddress sdk.AccAddress,
 	maxSupply sdk.Coin, orderQuantityLimits sdk.Coins, sanityRate,
 	sanityMarginPercentage sdk.Dec, allowSells bool, signers []sdk.AccAddress,
-	batchBlocks sdk.Uint, outcomePayment sdk.Coins, state string) Bond {
+	batchBlocks, revealBlocks sdk.Uint, forfeitUnrevealed bool,
+	maxBatchOrders, maxBatchVolume sdk.Uint, rollOverOrders bool,
+	outcomePayment sdk.Coins, outcomeTranches []OutcomeTranche,
+	outcomePayers, evaluators []sdk.AccAddress, autoDistribute bool, claimBlocks sdk.Uint, sweepToCommunityPool bool,
+	state string) Bond {
 
 	// Ensure tokens and coins are sorted
 	sort.Strings(reserveTokens)
@@ -235,11 +171,150 @@ func NewBond(token, name, description string, creator sdk.AccAddress,
 		AllowSells:             allowSells,
 		Signers:                signers,
 		BatchBlocks:            batchBlocks,
+		RevealBlocks:           revealBlocks,
+		ForfeitUnrevealed:      forfeitUnrevealed,
+		MaxBatchOrders:         maxBatchOrders,
+		MaxBatchVolume:         maxBatchVolume,
+		RollOverOrders:         rollOverOrders,
 		OutcomePayment:         outcomePayment,
+		OutcomeTranches:        outcomeTranches,
+		OutcomePayers:          outcomePayers,
+		Evaluators:             evaluators,
+		AutoDistribute:         autoDistribute,
+		ClaimBlocks:            claimBlocks,
+		SweepToCommunityPool:   sweepToCommunityPool,
+		TranchesPaid:           0,
 		State:                  state,
 	}
 }
 
+// BondAccounting holds the parts of a bond that change as its orders are
+// performed and its outcome payments are made. It is stored separately from
+// the rest of the bond, which only changes when the bond is created or edited,
+// so that updating a bond's supply, reserve or state does not involve decoding
+// and encoding the whole bond.
+type BondAccounting struct {
+	CurrentSupply  sdk.Coin  `json:"current_supply" yaml:"current_supply"`
+	CurrentReserve sdk.Coins `json:"current_reserve" yaml:"current_reserve"`
+	TranchesPaid   uint64    `json:"tranches_paid" yaml:"tranches_paid"`
+	State          string    `json:"state" yaml:"state"`
+}
+
+func NewBondAccounting(currentSupply sdk.Coin, currentReserve sdk.Coins,
+	tranchesPaid uint64, state string) BondAccounting {
+	return BondAccounting{
+		CurrentSupply:  currentSupply,
+		CurrentReserve: currentReserve,
+		TranchesPaid:   tranchesPaid,
+		State:          state,
+	}
+}
+
+// Accounting returns the bond's current supply, current reserve, number of
+// outcome tranches paid, and state.
+func (bond Bond) Accounting() BondAccounting {
+	return NewBondAccounting(bond.CurrentSupply, bond.CurrentReserve,
+		bond.TranchesPaid, bond.State)
+}
+
+// WithAccounting returns the bond with its current supply, current reserve,
+// number of outcome tranches paid, and state replaced by those in the
+// accounting.
+func (bond Bond) WithAccounting(accounting BondAccounting) Bond {
+	bond.CurrentSupply = accounting.CurrentSupply
+	bond.CurrentReserve = accounting.CurrentReserve
+	bond.TranchesPaid = accounting.TranchesPaid
+	bond.State = accounting.State
+	return bond
+}
+
+// HasOutcomePayment returns true if an outcome payment can be made to the
+// bond, either as a single payment or as a schedule of outcome tranches.
+func (bond Bond) HasOutcomePayment() bool {
+	return !bond.OutcomePayment.Empty() || bond.HasOutcomeTranches()
+}
+
+// HasOutcomeTranches returns true if the bond's outcome payment is made as a
+// schedule of outcome tranches rather than as a single payment.
+func (bond Bond) HasOutcomeTranches() bool {
+	return len(bond.OutcomeTranches) != 0
+}
+
+// GetNextOutcomeTranche returns the first of the bond's outcome tranches that
+// has not been paid yet, and whether it is the last tranche, i.e. whether
+// paying it settles the bond. It returns false if there is no such tranche.
+func (bond Bond) GetNextOutcomeTranche() (tranche OutcomeTranche, last bool, found bool) {
+	if bond.TranchesPaid >= uint64(len(bond.OutcomeTranches)) {
+		return OutcomeTranche{}, false, false
+	}
+	tranche = bond.OutcomeTranches[bond.TranchesPaid]
+	return tranche, bond.TranchesPaid+1 == uint64(len(bond.OutcomeTranches)), true
+}
+
+// IsOutcomePayer returns true if the bond has no authorised outcome payers,
+// in which case any account can make its outcome payment, or if the address
+// is one of the authorised outcome payers.
+func (bond Bond) IsOutcomePayer(address sdk.AccAddress) bool {
+	if len(bond.OutcomePayers) == 0 {
+		return true
+	}
+	for _, p := range bond.OutcomePayers {
+		if p.Equals(address) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasEvaluators returns true if the bond's outcome payments have to be
+// attested by its evaluators, and are scaled by the attested result.
+func (bond Bond) HasEvaluators() bool {
+	return len(bond.Evaluators) != 0
+}
+
+// IsEvaluator returns true if the address is one of the bond's evaluators.
+func (bond Bond) IsEvaluator(address sdk.AccAddress) bool {
+	for _, e := range bond.Evaluators {
+		if e.Equals(address) {
+			return true
+		}
+	}
+	return false
+}
+
+// AcceptsOrderCommitments indicates whether orders for the bond can be placed
+// as commitments in a batch's commit phase and revealed in its reveal phase,
+// which is the case if the bond has a reveal phase of at least one block.
+func (bond Bond) AcceptsOrderCommitments() bool {
+	return !bond.RevealBlocks.IsZero()
+}
+
+// GetMaxBatchOrders returns the maximum number of orders (including order
+// commitments) in the bond's batch, i.e. the bond's own limit if it has one
+// that is lower than the limit for all bonds (moduleMax).
+func (bond Bond) GetMaxBatchOrders(moduleMax uint64) uint64 {
+	if !bond.MaxBatchOrders.IsZero() && bond.MaxBatchOrders.LT(sdk.NewUint(moduleMax)) {
+		return bond.MaxBatchOrders.Uint64()
+	}
+	return moduleMax
+}
+
+// HasMaxBatchVolume indicates whether the total amount of bond tokens bought
+// and sold in the bond's batch is limited, i.e. if MaxBatchVolume is non-zero.
+func (bond Bond) HasMaxBatchVolume() bool {
+	return !bond.MaxBatchVolume.IsZero()
+}
+
+// GetMaxBatchVolume returns the maximum amount of bond tokens bought and sold
+// in the bond's batch, i.e. the bond's own limit if it has one that is lower
+// than the limit for all bonds (moduleMax).
+func (bond Bond) GetMaxBatchVolume(moduleMax uint64) sdk.Int {
+	if bond.HasMaxBatchVolume() && bond.MaxBatchVolume.LT(sdk.NewUint(moduleMax)) {
+		return sdk.NewIntFromBigInt(bond.MaxBatchVolume.BigInt())
+	}
+	return sdk.NewIntFromUint64(moduleMax)
+}
+
 //noinspection GoNilness
 func (bond Bond) GetNewReserveDecCoins(amount sdk.Dec) (coins sdk.DecCoins) {
 	for _, r := range bond.ReserveTokens {
@@ -248,313 +323,290 @@ func (bond Bond) GetNewReserveDecCoins(amount sdk.Dec) (coins sdk.DecCoins) {
 	return coins
 }
 
-func (bond Bond) GetPricesAtSupply(supply sdk.Int) (result sdk.DecCoins, err error) {
-	if supply.IsNegative() {
-		panic(fmt.Sprintf("negative supply for bond %s", bond.Token))
-	}
-
-	args := bond.FunctionParameters.AsMap()
-	x := supply.ToDec()
-	switch bond.FunctionType {
-	case PowerFunction:
-		m := args["m"]
-		n64 := args["n"].TruncateInt64() // enforced by powerParameterRestrictions
-		c := args["c"]
-		result = bond.GetNewReserveDecCoins(
-			x.Power(uint64(n64)).Mul(m).Add(c))
-	case SigmoidFunction:
-		a := args["a"]
-		b := args["b"]
-		c := args["c"]
-		temp1 := x.Sub(b)
-		temp2 := temp1.Mul(temp1).Add(c)
-		temp3, err := temp2.ApproxSqrt()
-		if err != nil {
-			panic(err) // TODO: consider error handling
+// checkCurveArguments returns an error if the bond's supply or function
+// parameters are too large for bonding curve arithmetic, so that evaluating
+// the bond's curve returns an error rather than overflowing.
+func (bond Bond) checkCurveArguments() error {
+	err := checkCurveValue(bond.CurrentSupply.Amount.ToDec())
+	if err != nil {
+		return sdkerrors.Wrapf(err, "supply of bond %s", bond.Token)
+	}
+	for _, p := range bond.FunctionParameters {
+		if err := checkCurveValue(p.Value); err != nil {
+			return sdkerrors.Wrapf(err, "parameter %s of bond %s", p.Param, bond.Token)
 		}
-		result = bond.GetNewReserveDecCoins(
-			a.Mul(temp1.Quo(temp3).Add(sdk.OneDec())))
-	case AugmentedFunction:
-		// Note: during the hatch phase, this function returns the hatch price
-		// p0 even if the supply argument is greater than the initial supply S0
-		switch bond.State {
-		case HatchState:
-			result = bond.GetNewReserveDecCoins(args["p0"])
-		case OpenState:
-			kappa := args["kappa"].TruncateInt64()
-			res := Reserve(x, kappa, args["V0"])
-			// If reserve < 1, default to zero price to avoid calculation issues
-			if res.LT(sdk.OneDec()) {
-				result = bond.GetNewReserveDecCoins(sdk.ZeroDec())
-			} else {
-				spotPriceDec := SpotPrice(res, kappa, args["V0"])
-				result = bond.GetNewReserveDecCoins(spotPriceDec)
-			}
-		default:
-			panic("unrecognized bond state")
+	}
+	return nil
+}
+
+// checkCurveAmount returns an error if an amount used in bonding curve
+// arithmetic is negative or too large.
+func (bond Bond) checkCurveAmount(name string, amount sdk.Dec) error {
+	if amount.IsNegative() {
+		return sdkerrors.Wrapf(ErrArgumentCannotBeNegative, "%s for bond %s", name, bond.Token)
+	} else if err := checkCurveValue(amount); err != nil {
+		return sdkerrors.Wrapf(err, "%s for bond %s", name, bond.Token)
+	}
+	return nil
+}
+
+// checkCurveCoins returns an error if any of the amounts of the coins used in
+// bonding curve arithmetic is negative or too large.
+func (bond Bond) checkCurveCoins(name string, coins sdk.Coins) error {
+	for _, c := range coins {
+		if err := bond.checkCurveAmount(name, c.Amount.ToDec()); err != nil {
+			return err
 		}
-	case SwapperFunction:
-		return nil, sdkerrors.Wrap(ErrFunctionNotAvailableForFunctionType, bond.FunctionType)
-	default:
-		panic("unrecognized function type")
 	}
+	return nil
+}
 
-	if result.IsAnyNegative() {
+// checkCurveDecCoins returns an error if any of the amounts of the coins used
+// in bonding curve arithmetic is negative or too large.
+func (bond Bond) checkCurveDecCoins(name string, coins sdk.DecCoins) error {
+	for _, c := range coins {
+		if err := bond.checkCurveAmount(name, c.Amount); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (bond Bond) GetPricesAtSupply(supply sdk.Int) (sdk.DecCoins, error) {
+	if err := bond.checkCurveArguments(); err != nil {
+		return nil, err
+	} else if err := bond.checkCurveAmount("supply", supply.ToDec()); err != nil {
+		return nil, err
+	}
+
+	result, err := MustGetFunction(bond.FunctionType).PricesAtSupply(bond, supply)
+	if err != nil {
+		return nil, err
+	} else if result.IsAnyNegative() {
 		// assumes that the curve is above the x-axis and does not intersect it
-		panic(fmt.Sprintf("negative price result for bond %s", bond.Token))
+		return nil, sdkerrors.Wrapf(ErrNegativeCurveResult, "price for bond %s", bond.Token)
 	}
 	return result, nil
 }
 
 func (bond Bond) GetCurrentPricesPT(reserveBalances sdk.Coins) (sdk.DecCoins, error) {
 	// Note: PT stands for "per token"
-	switch bond.FunctionType {
-	case PowerFunction:
-		fallthrough
-	case SigmoidFunction:
-		fallthrough
-	case AugmentedFunction:
-		return bond.GetPricesAtSupply(bond.CurrentSupply.Amount)
-	case SwapperFunction:
-		return bond.GetPricesToMint(sdk.OneInt(), reserveBalances)
-	default:
-		panic("unrecognized function type")
-	}
-}
-
-func (bond Bond) ReserveAtSupply(supply sdk.Int) (result sdk.Dec) {
-	if supply.IsNegative() {
-		panic(fmt.Sprintf("negative supply for bond %s", bond.Token))
-	}
-
-	args := bond.FunctionParameters.AsMap()
-	x := supply.ToDec()
-	switch bond.FunctionType {
-	case PowerFunction:
-		m := args["m"]
-		n, n64 := args["n"], args["n"].TruncateInt64() // enforced by powerParameterRestrictions
-		c := args["c"]
-		temp1 := x.Power(uint64(n64 + 1))
-		temp2 := temp1.Mul(m).Quo(n.Add(sdk.OneDec()))
-		temp3 := x.Mul(c)
-		result = temp2.Add(temp3)
-	case SigmoidFunction:
-		a := args["a"]
-		b := args["b"]
-		c := args["c"]
-		temp1 := x.Sub(b)
-		temp2 := temp1.Mul(temp1).Add(c)
-		temp3, err := temp2.ApproxSqrt()
-		if err != nil {
-			panic(err) // TODO: consider error handling
-		}
-		temp5 := a.Mul(temp3.Add(x))
-		approx, err := (b.Mul(b).Add(c)).ApproxSqrt()
-		if err != nil {
-			panic(err) // TODO: consider error handling
-		}
-		constant := a.Mul(approx)
+	if err := bond.checkCurveArguments(); err != nil {
+		return nil, err
+	} else if err := bond.checkCurveCoins("reserve balance", reserveBalances); err != nil {
+		return nil, err
+	}
+
+	return MustGetFunction(bond.FunctionType).CurrentPricesPT(bond, reserveBalances)
+}
 
-		result = temp5.Sub(constant)
-	case AugmentedFunction:
-		kappa := args["kappa"].TruncateInt64()
-		V0 := args["V0"]
-		result = Reserve(x, kappa, V0)
-	case SwapperFunction:
-		panic("invalid function for function type")
-	default:
-		panic("unrecognized function type")
+func (bond Bond) ReserveAtSupply(supply sdk.Int) (sdk.Dec, error) {
+	if err := bond.checkCurveArguments(); err != nil {
+		return sdk.Dec{}, err
+	} else if err := bond.checkCurveAmount("supply", supply.ToDec()); err != nil {
+		return sdk.Dec{}, err
 	}
 
-	if result.IsNegative() {
+	result, err := MustGetFunction(bond.FunctionType).ReserveAtSupply(bond, supply)
+	if err != nil {
+		return sdk.Dec{}, err
+	} else if result.IsNegative() {
 		// For vanilla bonding curves, we assume that the curve does not
 		// intersect the x-axis and is greater than zero throughout
-		panic(fmt.Sprintf("negative reserve result for bond %s", bond.Token))
-	}
-	return result
-}
-
-func (bond Bond) GetReserveDeltaForLiquidityDelta(mintOrBurn sdk.Int, reserveBalances sdk.Coins) sdk.DecCoins {
-	if mintOrBurn.IsNegative() {
-		panic(fmt.Sprintf("negative liquidity delta for bond %s", bond.Token))
-	} else if reserveBalances.IsAnyNegative() {
-		panic(fmt.Sprintf("negative reserve balance for bond %s", bond.Token))
-	}
-
-	switch bond.FunctionType {
-	case PowerFunction:
-		fallthrough
-	case SigmoidFunction:
-		fallthrough
-	case AugmentedFunction:
-		panic("invalid function for function type")
-	case SwapperFunction:
-		resToken1 := bond.ReserveTokens[0]
-		resToken2 := bond.ReserveTokens[1]
-		resBalance1 := reserveBalances.AmountOf(resToken1).ToDec()
-		resBalance2 := reserveBalances.AmountOf(resToken2).ToDec()
-
-		// Using Uniswap formulae: x' = (1+-α)x = x +- Δx, where α = Δx/x
-		// Where x is any of the two reserve balances or the current supply
-		// and x' is any of the updated reserve balances or the updated supply
-		// By making Δx subject of the formula: Δx = αx
-		alpha := mintOrBurn.ToDec().Quo(bond.CurrentSupply.Amount.ToDec())
-
-		result := sdk.DecCoins{
-			sdk.NewDecCoinFromDec(resToken1, alpha.Mul(resBalance1)),
-			sdk.NewDecCoinFromDec(resToken2, alpha.Mul(resBalance2)),
-		}
-		if result.IsAnyNegative() {
-			panic(fmt.Sprintf("negative reserve delta result for bond %s", bond.Token))
-		}
-		return result
-	default:
-		panic("unrecognized function type")
+		return sdk.Dec{}, sdkerrors.Wrapf(ErrNegativeCurveResult, "reserve for bond %s", bond.Token)
 	}
+	return result, nil
 }
 
-func (bond Bond) GetPricesToMint(mint sdk.Int, reserveBalances sdk.Coins) (sdk.DecCoins, error) {
-	if mint.IsNegative() {
-		panic(fmt.Sprintf("negative mint amount for bond %s", bond.Token))
-	} else if reserveBalances.IsAnyNegative() {
-		panic(fmt.Sprintf("negative reserve balance for bond %s", bond.Token))
-	}
-
-	// If hatch phase for augmented function, use fixed p0 price
-	if bond.FunctionType == AugmentedFunction && bond.State == HatchState {
-		args := bond.FunctionParameters.AsMap()
-		if bond.State == HatchState {
-			price := args["p0"].Mul(mint.ToDec())
-			return bond.GetNewReserveDecCoins(price), nil
-		}
+func (bond Bond) SupplyAtReserve(reserve sdk.Dec) (sdk.Dec, error) {
+	if err := bond.checkCurveArguments(); err != nil {
+		return sdk.Dec{}, err
+	} else if err := bond.checkCurveAmount("reserve", reserve); err != nil {
+		return sdk.Dec{}, err
 	}
 
-	switch bond.FunctionType {
-	case PowerFunction:
-		fallthrough
-	case SigmoidFunction:
-		fallthrough
-	case AugmentedFunction:
-		var priceToMint sdk.Dec
-		result := bond.ReserveAtSupply(bond.CurrentSupply.Amount.Add(mint))
-		if reserveBalances.Empty() {
-			priceToMint = result
-		} else {
-			// Reserve balances should all be equal given that we are always
-			// applying the same additions/subtractions to all reserve balances.
-			// Thus we can pick the first reserve balance as the global balance.
-			commonReserveBalance := reserveBalances[0].Amount.ToDec()
-			priceToMint = result.Sub(commonReserveBalance)
-		}
-		if priceToMint.IsNegative() {
-			// Negative priceToMint means that the previous buyer overpaid
-			// to the point that the price for this buyer is covered. However,
-			// we still charge this buyer at least one token.
-			priceToMint = sdk.OneDec()
-		}
-		return bond.GetNewReserveDecCoins(priceToMint), nil
-	case SwapperFunction:
-		if bond.CurrentSupply.Amount.IsZero() {
-			return nil, sdkerrors.Wrap(ErrFunctionRequiresNonZeroCurrentSupply, bond.CurrentSupply.Amount.String())
-		}
-		return bond.GetReserveDeltaForLiquidityDelta(mint, reserveBalances), nil
-	default:
-		panic("unrecognized function type")
+	result, err := MustGetFunction(bond.FunctionType).SupplyAtReserve(bond, reserve)
+	if err != nil {
+		return sdk.Dec{}, err
+	} else if result.IsNegative() {
+		return sdk.Dec{}, sdkerrors.Wrapf(ErrNegativeCurveResult, "supply for bond %s", bond.Token)
+	}
+	return result, nil
+}
+
+func (bond Bond) GetReserveDeltaForLiquidityDelta(mintOrBurn sdk.Int, reserveBalances sdk.Coins) (sdk.DecCoins, error) {
+	if err := bond.checkCurveArguments(); err != nil {
+		return nil, err
+	} else if err := bond.checkCurveAmount("liquidity delta", mintOrBurn.ToDec()); err != nil {
+		return nil, err
+	} else if err := bond.checkCurveCoins("reserve balance", reserveBalances); err != nil {
+		return nil, err
+	} else if !MustGetFunction(bond.FunctionType).IsLiquidityPool() {
+		return nil, sdkerrors.Wrap(ErrFunctionNotAvailableForFunctionType, bond.FunctionType)
+	}
+
+	resToken1 := bond.ReserveTokens[0]
+	resToken2 := bond.ReserveTokens[1]
+	resBalance1 := reserveBalances.AmountOf(resToken1).ToDec()
+	resBalance2 := reserveBalances.AmountOf(resToken2).ToDec()
+
+	// Using Uniswap formulae: x' = (1+-α)x = x +- Δx, where α = Δx/x
+	// Where x is any of the two reserve balances or the current supply
+	// and x' is any of the updated reserve balances or the updated supply
+	// By making Δx subject of the formula: Δx = αx
+	alpha, err := quoDec(mintOrBurn.ToDec(), bond.CurrentSupply.Amount.ToDec())
+	if err != nil {
+		return nil, err
+	}
+	delta1, err := mulDec(alpha, resBalance1)
+	if err != nil {
+		return nil, err
+	}
+	delta2, err := mulDec(alpha, resBalance2)
+	if err != nil {
+		return nil, err
+	}
+
+	result := sdk.DecCoins{
+		sdk.NewDecCoinFromDec(resToken1, delta1),
+		sdk.NewDecCoinFromDec(resToken2, delta2),
+	}
+	if result.IsAnyNegative() {
+		return nil, sdkerrors.Wrapf(ErrNegativeCurveResult, "reserve delta for bond %s", bond.Token)
+	}
+	return result, nil
+}
+
+func (bond Bond) GetPricesToMint(mint sdk.Int, reserveBalances sdk.Coins) (sdk.DecCoins, error) {
+	if err := bond.checkCurveArguments(); err != nil {
+		return nil, err
+	} else if err := bond.checkCurveAmount("mint amount", mint.ToDec()); err != nil {
+		return nil, err
+	} else if err := bond.checkCurveCoins("reserve balance", reserveBalances); err != nil {
+		return nil, err
 	}
+
 	// Note: fees have to be added to these prices to get actual prices
+	return MustGetFunction(bond.FunctionType).PricesToMint(bond, mint, reserveBalances)
 }
 
-func (bond Bond) GetReturnsForBurn(burn sdk.Int, reserveBalances sdk.Coins) sdk.DecCoins {
-	if burn.IsNegative() {
-		panic(fmt.Sprintf("negative burn amount for bond %s", bond.Token))
-	} else if reserveBalances.IsAnyNegative() {
-		panic(fmt.Sprintf("negative reserve balance for bond %s", bond.Token))
-	}
-
-	switch bond.FunctionType {
-	case PowerFunction:
-		fallthrough
-	case SigmoidFunction:
-		fallthrough
-	case AugmentedFunction:
-		result := bond.ReserveAtSupply(bond.CurrentSupply.Amount.Sub(burn))
-
-		var reserveBalance sdk.Dec
-		if reserveBalances.Empty() {
-			reserveBalance = sdk.ZeroDec()
-		} else {
-			// Reserve balances should all be equal given that we are always
-			// applying the same additions/subtractions to all reserve balances.
-			// Thus we can pick the first reserve balance as the global balance.
-			reserveBalance = reserveBalances[0].Amount.ToDec()
-		}
+func (bond Bond) GetAmountToMint(prices sdk.DecCoins, reserveBalances sdk.Coins) (sdk.Int, error) {
+	if err := bond.checkCurveArguments(); err != nil {
+		return sdk.Int{}, err
+	} else if err := bond.checkCurveDecCoins("prices", prices); err != nil {
+		return sdk.Int{}, err
+	} else if err := bond.checkCurveCoins("reserve balance", reserveBalances); err != nil {
+		return sdk.Int{}, err
+	}
 
-		if result.GT(reserveBalance) {
-			panic("not enough reserve available for burn")
-		} else {
-			returnForBurn := reserveBalance.Sub(result)
-			return bond.GetNewReserveDecCoins(returnForBurn)
-			// TODO: investigate possibility of negative returnForBurn
-		}
-	case SwapperFunction:
-		return bond.GetReserveDeltaForLiquidityDelta(burn, reserveBalances)
-	default:
-		panic("unrecognized function type")
+	// Note: fees have to be deducted from the prices before getting the amount
+	return MustGetFunction(bond.FunctionType).AmountToMint(bond, prices, reserveBalances)
+}
+
+func (bond Bond) GetReturnsForBurn(burn sdk.Int, reserveBalances sdk.Coins) (sdk.DecCoins, error) {
+	if err := bond.checkCurveArguments(); err != nil {
+		return nil, err
+	} else if err := bond.checkCurveAmount("burn amount", burn.ToDec()); err != nil {
+		return nil, err
+	} else if err := bond.checkCurveCoins("reserve balance", reserveBalances); err != nil {
+		return nil, err
 	}
+
 	// Note: fees have to be deducted from these returns to get actual returns
+	result, err := MustGetFunction(bond.FunctionType).ReturnsForBurn(bond, burn, reserveBalances)
+	if err != nil {
+		return nil, err
+	} else if result.IsAnyNegative() {
+		return nil, sdkerrors.Wrapf(ErrNegativeCurveResult, "returns for bond %s", bond.Token)
+	}
+	return result, nil
 }
 
 func (bond Bond) GetReturnsForSwap(from sdk.Coin, toToken string, reserveBalances sdk.Coins) (returns sdk.Coins, txFee sdk.Coin, err error) {
-	if from.IsNegative() {
-		panic(fmt.Sprintf("negative from amount for bond %s", bond.Token))
-	} else if reserveBalances.IsAnyNegative() {
-		panic(fmt.Sprintf("negative reserve balance for bond %s", bond.Token))
-	}
-
-	switch bond.FunctionType {
-	case PowerFunction:
-		fallthrough
-	case SigmoidFunction:
-		fallthrough
-	case AugmentedFunction:
-		return nil, sdk.Coin{}, sdkerrors.Wrap(ErrFunctionNotAvailableForFunctionType, bond.FunctionType)
-	case SwapperFunction:
-		// Check that from and to are reserve tokens
-		if from.Denom != bond.ReserveTokens[0] && from.Denom != bond.ReserveTokens[1] {
-			return nil, sdk.Coin{}, sdkerrors.Wrap(ErrTokenIsNotAValidReserveToken, from.Denom)
-		} else if toToken != bond.ReserveTokens[0] && toToken != bond.ReserveTokens[1] {
-			return nil, sdk.Coin{}, sdkerrors.Wrap(ErrTokenIsNotAValidReserveToken, toToken)
-		}
+	if err := bond.checkCurveAmount("from amount", from.Amount.ToDec()); err != nil {
+		return nil, sdk.Coin{}, err
+	} else if err := bond.checkCurveCoins("reserve balance", reserveBalances); err != nil {
+		return nil, sdk.Coin{}, err
+	}
 
-		inAmt := from.Amount
-		inRes := reserveBalances.AmountOf(from.Denom)
-		outRes := reserveBalances.AmountOf(toToken)
+	return MustGetFunction(bond.FunctionType).ReturnsForSwap(bond, from, toToken, reserveBalances)
+}
 
-		// Calculate fee to get the adjusted input amount
-		txFee = bond.GetTxFee(sdk.NewDecCoinFromCoin(from))
-		inAmt = inAmt.Sub(txFee.Amount) // adjusted input
+// GetReturnsForBatchSwap returns the returns and tx fee for a swap that is
+// settled together with the other swaps in its batch at a single clearing
+// rate. The total inputs are the batch's fee-deducted swap inputs (including
+// that of this swap) per reserve token.
+func (bond Bond) GetReturnsForBatchSwap(from sdk.Coin, toToken string, totalInputs, reserveBalances sdk.Coins) (returns sdk.Coins, txFee sdk.Coin, err error) {
+	if err := bond.checkCurveAmount("from amount", from.Amount.ToDec()); err != nil {
+		return nil, sdk.Coin{}, err
+	} else if err := bond.checkCurveCoins("total swap inputs", totalInputs); err != nil {
+		return nil, sdk.Coin{}, err
+	} else if err := bond.checkCurveCoins("reserve balance", reserveBalances); err != nil {
+		return nil, sdk.Coin{}, err
+	}
+
+	return MustGetFunction(bond.FunctionType).ReturnsForBatchSwap(
+		bond, from, toToken, totalInputs, reserveBalances)
+}
 
-		// Check that at least 1 token is going in
-		if inAmt.IsZero() {
-			return nil, sdk.Coin{}, sdkerrors.Wrapf(ErrSwapAmountTooSmallToGiveAnyReturn, "%s - %s", from.Denom, toToken)
+// ValidateCurve performs a pre-flight check of the bond's curve by evaluating
+// the prices and reserve at zero supply, at evenly-spaced checkpoints and at the
+// max supply. It checks that the curve can be evaluated (e.g. without overflow)
+// and that the prices and reserve are non-negative and non-decreasing.
+func (bond Bond) ValidateCurve() error {
+	// A bond in the hatch state is also checked as it will be once open
+	bonds := []Bond{bond}
+	if bond.State == HatchState {
+		openBond := bond
+		openBond.State = OpenState
+		bonds = append(bonds, openBond)
+	}
+
+	for _, b := range bonds {
+		err := b.validateCurveAtCheckpoints()
+		if err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		// Calculate output amount using Uniswap formula: Δy = (Δx*y)/(x+Δx)
-		outAmt := inAmt.Mul(outRes).Quo(inRes.Add(inAmt))
+func (bond Bond) validateCurveAtCheckpoints() error {
+	var prevPrices sdk.DecCoins
+	var prevReserve sdk.Dec
+	for i := int64(0); i <= NoOfCurveCheckpoints; i++ {
+		supply := bond.MaxSupply.Amount.MulRaw(i).QuoRaw(NoOfCurveCheckpoints)
+
+		// Check that the curve can be evaluated and is non-negative
+		prices, err := bond.GetPricesAtSupply(supply)
+		if errors.Is(err, ErrFunctionNotAvailableForFunctionType) {
+			return nil // function type does not define prices in terms of supply
+		} else if err != nil {
+			return sdkerrors.Wrapf(err, "price at supply %s", supply)
+		}
+		reserve, err := bond.ReserveAtSupply(supply)
+		if err != nil {
+			return sdkerrors.Wrapf(err, "reserve at supply %s", supply)
+		}
 
-		// Check that not giving out all of the available outRes or nothing at all
-		if outAmt.Equal(outRes) {
-			return nil, sdk.Coin{}, sdkerrors.Wrapf(ErrSwapAmountCausesReserveDepletion, "%s - %s", from.Denom, toToken)
-		} else if outAmt.IsZero() {
-			return nil, sdk.Coin{}, sdkerrors.Wrapf(ErrSwapAmountTooSmallToGiveAnyReturn, "%s - %s", from.Denom, toToken)
-		} else if outAmt.IsNegative() {
-			panic(fmt.Sprintf("negative return for swap result for bond %s", bond.Token))
+		// Check that the curve is non-decreasing
+		if i > 0 {
+			for _, r := range bond.ReserveTokens {
+				if prices.AmountOf(r).LT(prevPrices.AmountOf(r)) {
+					return sdkerrors.Wrapf(ErrCurveFailsSanityCheck,
+						"price decreases at supply %s", supply)
+				}
+			}
+			if reserve.LT(prevReserve) {
+				return sdkerrors.Wrapf(ErrCurveFailsSanityCheck,
+					"reserve decreases at supply %s", supply)
+			}
 		}
 
-		return sdk.Coins{sdk.NewCoin(toToken, outAmt)}, txFee, nil
-	default:
-		panic("unrecognized function type")
+		prevPrices = prices
+		prevReserve = reserve
 	}
+	return nil
 }
 
 func (bond Bond) GetFee(reserveAmount sdk.DecCoin, percentage sdk.Dec) sdk.Coin {
@@ -587,6 +639,13 @@ func (bond Bond) GetExitFees(reserveAmounts sdk.DecCoins) (fees sdk.Coins) {
 	return bond.GetFees(reserveAmounts, bond.ExitFeePercentage)
 }
 
+// GetSpendExcludingTxFees returns the part of the spend that is left for the
+// reserve after tx fees, i.e. the reserve amounts r such that r+fee(r)=spend.
+func (bond Bond) GetSpendExcludingTxFees(spend sdk.Coins) sdk.DecCoins {
+	feeMultiplier := sdk.OneDec().Add(bond.TxFeePercentage.QuoInt64(100))
+	return DivideDecCoinsByDec(sdk.NewDecCoinsFromCoins(spend...), feeMultiplier)
+}
+
 func (bond Bond) SignersEqualTo(signers []sdk.AccAddress) bool {
 	if len(bond.Signers) != len(signers) {
 		return false
@@ -616,6 +675,25 @@ func (bond Bond) ReserveDenomsEqualTo(coins sdk.Coins) bool {
 	return true
 }
 
+// ReserveDenomsInclude returns true if all of the coins' denoms are reserve
+// tokens, but unlike ReserveDenomsEqualTo, not all reserve tokens need to be
+// present in the coins.
+func (bond Bond) ReserveDenomsInclude(coins sdk.Coins) bool {
+	for _, c := range coins {
+		found := false
+		for _, r := range bond.ReserveTokens {
+			if c.Denom == r {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
 func (bond Bond) AnyOrderQuantityLimitsExceeded(amounts sdk.Coins) bool {
 	return amounts.IsAnyGT(bond.OrderQuantityLimits)
 }
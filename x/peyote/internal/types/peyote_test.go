@@ -1,15 +1,18 @@
 package types
 
 import (
+	"errors"
 	"fmt"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/stretchr/testify/require"
 	"github.com/tendermint/tendermint/crypto/ed25519"
+	"math/big"
 	"testing"
 )
 
 func TestExtraParameterRestrictions_Power(t *testing.T) {
-	paramRestrictions := ExtraParameterRestrictions[PowerFunction]
+	paramRestrictions, err := GetExceptionsForFunctionType(PowerFunction)
+	require.Nil(t, err)
 
 	testCases := []struct {
 		m           string
@@ -20,7 +23,7 @@ func TestExtraParameterRestrictions_Power(t *testing.T) {
 		{"10", "10", "10", false},       // integers allowed for all
 		{"0", "0", "0", false},          // zeroes allowed for all
 		{"10.10", "10", "10.10", false}, // float m and c allowed
-		{"10", "10.10", "10", true},     // float n not allowed
+		{"10", "10.10", "10", false},    // float n allowed
 	}
 
 	for _, tc := range testCases {
@@ -42,7 +45,8 @@ func TestExtraParameterRestrictions_Power(t *testing.T) {
 }
 
 func TestExtraParameterRestrictions_Sigmoid(t *testing.T) {
-	paramRestrictions := ExtraParameterRestrictions[SigmoidFunction]
+	paramRestrictions, err := GetExceptionsForFunctionType(SigmoidFunction)
+	require.Nil(t, err)
 
 	testCases := []struct {
 		a           string
@@ -75,7 +79,8 @@ func TestExtraParameterRestrictions_Sigmoid(t *testing.T) {
 }
 
 func TestExtraParameterRestrictions_Augmented(t *testing.T) {
-	paramRestrictions := ExtraParameterRestrictions[AugmentedFunction]
+	paramRestrictions, err := GetExceptionsForFunctionType(AugmentedFunction)
+	require.Nil(t, err)
 
 	testCases := []struct {
 		d0          string
@@ -93,7 +98,7 @@ func TestExtraParameterRestrictions_Augmented(t *testing.T) {
 		{"10", "10", "1.1", "10", true},       // theta can NOT be >1
 		{"10", "10.10", "0.5", "10", false},   // p0 and theta can be floats
 		{"10.10", "10.10", "0.5", "10", true}, // d0 can NOT be a float
-		{"10", "10.10", "0.5", "10.10", true}, // kappa can NOT be a float
+		{"10", "10.10", "0.5", "10.10", false}, // kappa can be a float
 	}
 
 	for _, tc := range testCases {
@@ -116,6 +121,40 @@ func TestExtraParameterRestrictions_Augmented(t *testing.T) {
 	}
 }
 
+func TestExtraParameterRestrictions_Bancor(t *testing.T) {
+	paramRestrictions, err := GetExceptionsForFunctionType(BancorFunction)
+	require.Nil(t, err)
+
+	testCases := []struct {
+		crr         string
+		p0          string
+		expectError bool
+	}{
+		{"0.5", "10", false},   // valid values
+		{"1", "10", false},     // crr can be 1
+		{"0.2", "0.01", false}, // p0 can be a float
+		{"0", "10", true},      // crr can NOT be 0
+		{"1.5", "10", true},    // crr can NOT be >1
+		{"0.3", "10", false},   // 1/crr can be a float
+		{"0.5", "0", true},     // p0 can NOT be 0
+	}
+
+	for _, tc := range testCases {
+		crrDec := sdk.MustNewDecFromStr(tc.crr)
+		p0Dec := sdk.MustNewDecFromStr(tc.p0)
+		err := paramRestrictions(FunctionParams{
+			NewFunctionParam("crr", crrDec),
+			NewFunctionParam("p0", p0Dec),
+		}.AsMap())
+
+		if tc.expectError {
+			require.Error(t, err)
+		} else {
+			require.Nil(t, err)
+		}
+	}
+}
+
 func TestFunctionParamsAsMap(t *testing.T) {
 	actualResult := functionParametersPower().AsMap()
 	expectedResult := map[string]sdk.Dec{
@@ -166,7 +205,10 @@ func TestNewBondDefaultValuesAndSorting(t *testing.T) {
 		PowerFunction, functionParametersPower(), customReserveTokens,
 		initTxFeePercentage, initExitFeePercentage, initFeeAddress, initMaxSupply,
 		customOrderQuantityLimits, initSanityRate, initSanityMarginPercentage,
-		initAllowSell, initSigners, initBatchBlocks, initOutcomePayment, initState)
+		initAllowSell, initSigners, initBatchBlocks, initRevealBlocks,
+		initForfeitUnrevealed, initMaxBatchOrders, initMaxBatchVolume,
+		initRollOverOrders, initOutcomePayment, initOutcomeTranches,
+		initOutcomePayers, initEvaluators, initAutoDistribute, initClaimBlocks, initSweepToCommunityPool, initState)
 
 	expectedCurrentSupply := sdk.NewInt64Coin(bond.Token, 0)
 
@@ -352,7 +394,8 @@ func TestReserveAtSupply(t *testing.T) {
 		bond.FunctionType = tc.functionType
 		bond.FunctionParameters = tc.functionParams
 
-		actualResult := bond.ReserveAtSupply(tc.supply)
+		actualResult, err := bond.ReserveAtSupply(tc.supply)
+		require.Nil(t, err)
 		expectedResult := sdk.MustNewDecFromStr(tc.expected)
 		require.Equal(t, expectedResult, actualResult)
 	}
@@ -378,8 +421,9 @@ func TestGetReserveDeltaForLiquidityDelta(t *testing.T) {
 	for _, tc := range testCases {
 		bond.CurrentSupply = sdk.NewCoin(bond.Token, tc.currentSupply)
 
-		actualResult := bond.GetReserveDeltaForLiquidityDelta(
+		actualResult, err := bond.GetReserveDeltaForLiquidityDelta(
 			tc.liquidityDelta, reserveBalances)
+		require.Nil(t, err)
 		expectedResult := newDecMultitokenReserveFromInt(50000)
 		require.Equal(t, expectedResult, actualResult)
 	}
@@ -403,10 +447,10 @@ func TestGetPricesToMint(t *testing.T) {
 	baseMap := functionParametersAugmented().AsMap()
 	R0 := baseMap["d0"].Mul(sdk.OneDec().Sub(baseMap["theta"]))
 	S0 := baseMap["d0"].Quo(baseMap["p0"])
-	kappa := baseMap["kappa"].TruncateInt64()
-	V0 := Invariant(R0, S0, kappa)
-	augmentedSupplyForReserve10000 :=
-		Supply(sdk.NewDec(tenK), kappa, V0).Ceil().TruncateInt()
+	kappa := baseMap["kappa"]
+	V0, _ := Invariant(R0, S0, kappa)
+	augmentedSupplyForReserve10000Dec, _ := Supply(sdk.NewDec(tenK), kappa, V0)
+	augmentedSupplyForReserve10000 := augmentedSupplyForReserve10000Dec.Ceil().TruncateInt()
 
 	testCases := []struct {
 		functionType    string
@@ -465,6 +509,115 @@ func TestGetPricesToMint(t *testing.T) {
 	}
 }
 
+func TestSupplyAtReserve(t *testing.T) {
+	bond := getValidBond()
+
+	testCases := []struct {
+		functionType   string
+		functionParams FunctionParams
+		supply         sdk.Int
+	}{
+		// Power
+		{PowerFunction, functionParametersPower(), sdk.NewInt(1)},
+		{PowerFunction, functionParametersPower(), sdk.NewInt(100)},
+		{PowerFunction, functionParametersPower(), sdk.NewInt(1000000)},
+		// Sigmoid
+		{SigmoidFunction, functionParametersSigmoid(), sdk.NewInt(1)},
+		{SigmoidFunction, functionParametersSigmoid(), sdk.NewInt(100)},
+		{SigmoidFunction, functionParametersSigmoid(), sdk.NewInt(1000000)},
+		// Augmented
+		{AugmentedFunction, functionParametersAugmentedFull(), sdk.NewInt(100)},
+		{AugmentedFunction, functionParametersAugmentedFull(), sdk.NewInt(50000)},
+		{AugmentedFunction, functionParametersAugmentedFull(), sdk.NewInt(1000000)},
+	}
+	for _, tc := range testCases {
+		bond.FunctionType = tc.functionType
+		bond.FunctionParameters = tc.functionParams
+
+		// SupplyAtReserve is the inverse of ReserveAtSupply
+		reserve, err := bond.ReserveAtSupply(tc.supply)
+		require.Nil(t, err)
+		actualResult, err := bond.SupplyAtReserve(reserve)
+		require.Nil(t, err)
+		require.Equal(t, tc.supply, actualResult.RoundInt())
+	}
+}
+
+func TestSupplyAtReserveSwapperFails(t *testing.T) {
+	bond := getValidBond()
+	bond.FunctionType = SwapperFunction
+	bond.FunctionParameters = FunctionParams{}
+
+	_, err := bond.SupplyAtReserve(sdk.NewDec(100))
+	require.Error(t, err)
+}
+
+func TestGetAmountToMint(t *testing.T) {
+	bond := getValidBond()
+
+	reserveBalances10000 := sdk.NewCoins(
+		sdk.NewInt64Coin(reserveToken, 10000),
+		sdk.NewInt64Coin(reserveToken2, 10000),
+	)
+
+	testCases := []struct {
+		functionType    string
+		functionParams  FunctionParams
+		reserveTokens   []string
+		reserveBalances sdk.Coins
+		currentSupply   sdk.Int
+		amount          sdk.Int
+		state           string
+	}{
+		// Power
+		{PowerFunction, functionParametersPower(), multitokenReserve(),
+			nil, sdk.ZeroInt(), sdk.NewInt(100), OpenState},
+		{PowerFunction, functionParametersPower(), multitokenReserve(),
+			reserveBalances10000, sdk.NewInt(10), sdk.NewInt(100), OpenState},
+		// Sigmoid
+		{SigmoidFunction, functionParametersSigmoid(), multitokenReserve(),
+			nil, sdk.ZeroInt(), sdk.NewInt(100), OpenState},
+		// Augmented
+		{AugmentedFunction, functionParametersAugmentedFull(), multitokenReserve(),
+			nil, sdk.ZeroInt(), sdk.NewInt(5000), HatchState},
+		{AugmentedFunction, functionParametersAugmentedFull(), multitokenReserve(),
+			nil, sdk.ZeroInt(), sdk.NewInt(5000), OpenState},
+		// Swapper
+		{SwapperFunction, FunctionParams{}, swapperReserves(),
+			reserveBalances10000, sdk.NewInt(2), sdk.NewInt(10), OpenState},
+	}
+	for _, tc := range testCases {
+		bond.FunctionType = tc.functionType
+		bond.FunctionParameters = tc.functionParams
+		bond.ReserveTokens = tc.reserveTokens
+		bond.State = tc.state
+		bond.CurrentSupply = sdk.NewCoin(bond.Token, tc.currentSupply)
+
+		// GetAmountToMint is the inverse of GetPricesToMint
+		prices, err := bond.GetPricesToMint(tc.amount, tc.reserveBalances)
+		require.Nil(t, err)
+		actualResult, err := bond.GetAmountToMint(prices, tc.reserveBalances)
+		require.Nil(t, err)
+		require.Equal(t, tc.amount, actualResult)
+
+		// Slightly lower prices are not enough to mint the amount
+		lowerPrices := prices.Sub(newDecMultitokenReserveFromDec(sdk.NewDecWithPrec(1, 6)))
+		actualResult, err = bond.GetAmountToMint(lowerPrices, tc.reserveBalances)
+		require.Nil(t, err)
+		require.Equal(t, tc.amount.SubRaw(1), actualResult)
+	}
+}
+
+func TestGetAmountToMintSwapperWithZeroSupplyFails(t *testing.T) {
+	bond := getValidBond()
+	bond.FunctionType = SwapperFunction
+	bond.FunctionParameters = FunctionParams{}
+	bond.ReserveTokens = swapperReserves()
+
+	_, err := bond.GetAmountToMint(newDecMultitokenReserveFromInt(100), nil)
+	require.Error(t, err)
+}
+
 func TestGetReturnsForBurn(t *testing.T) {
 	bond := getValidBond()
 	// TODO: add more test cases
@@ -489,10 +642,10 @@ func TestGetReturnsForBurn(t *testing.T) {
 	baseMap := functionParametersAugmented().AsMap()
 	R0 := baseMap["d0"].Mul(sdk.OneDec().Sub(baseMap["theta"]))
 	S0 := baseMap["d0"].Quo(baseMap["p0"])
-	kappa := baseMap["kappa"].TruncateInt64()
-	V0 := Invariant(R0, S0, kappa)
-	augmentedSupplyForReserve10000 :=
-		Supply(sdk.NewDec(tenK), kappa, V0).Ceil().TruncateInt()
+	kappa := baseMap["kappa"]
+	V0, _ := Invariant(R0, S0, kappa)
+	augmentedSupplyForReserve10000Dec, _ := Supply(sdk.NewDec(tenK), kappa, V0)
+	augmentedSupplyForReserve10000 := augmentedSupplyForReserve10000Dec.Ceil().TruncateInt()
 
 	testCases := []struct {
 		functionType    string
@@ -523,13 +676,93 @@ func TestGetReturnsForBurn(t *testing.T) {
 		bond.ReserveTokens = tc.reserveTokens
 		bond.CurrentSupply = sdk.NewCoin(bond.Token, tc.currentSupply)
 
-		actualResult := bond.GetReturnsForBurn(tc.amount, tc.reserveBalances)
+		actualResult, err := bond.GetReturnsForBurn(tc.amount, tc.reserveBalances)
+		require.Nil(t, err)
 		expectedDec := sdk.MustNewDecFromStr(tc.expectedReturn)
 		expectedResult := newDecMultitokenReserveFromDec(expectedDec)
 		require.Equal(t, expectedResult, actualResult)
 	}
 }
 
+func TestCurveOverflowReturnsError(t *testing.T) {
+	bond := getValidBond()
+	bond.FunctionType = PowerFunction
+	bond.FunctionParameters = functionParametersPowerHuge()
+	reserveBalances := sdk.NewCoins(sdk.NewCoin(reserveToken, maxInt64))
+
+	// x^(n+1) overflows sdk.Dec for n=100 and x=maxInt64
+	_, err := bond.ReserveAtSupply(maxInt64)
+	require.True(t, errors.Is(err, ErrCurveEvaluationFailed))
+
+	_, err = bond.GetPricesAtSupply(maxInt64)
+	require.True(t, errors.Is(err, ErrCurveEvaluationFailed))
+
+	_, err = bond.GetPricesToMint(maxInt64, reserveBalances)
+	require.True(t, errors.Is(err, ErrCurveEvaluationFailed))
+}
+
+func TestValidateCurve(t *testing.T) {
+	testCases := []struct {
+		functionType   string
+		functionParams FunctionParams
+		maxSupply      sdk.Int
+		expectedErr    error
+	}{
+		{PowerFunction, functionParametersPower(), initMaxSupply.Amount, nil},
+		{SigmoidFunction, functionParametersSigmoid(), initMaxSupply.Amount, nil},
+		{BancorFunction, functionParametersBancor(), initMaxSupply.Amount, nil},
+		{SwapperFunction, nil, initMaxSupply.Amount, nil},
+		{PowerFunction, functionParametersPowerHuge(), maxInt64, ErrCurveEvaluationFailed},
+		{PowerFunction, FunctionParams{
+			NewFunctionParam("m", sdk.NewDec(-1)),
+			NewFunctionParam("n", sdk.NewDec(1)),
+			NewFunctionParam("c", sdk.NewDec(100000))},
+			initMaxSupply.Amount, ErrCurveFailsSanityCheck},
+	}
+
+	for _, tc := range testCases {
+		bond := getValidBond()
+		bond.FunctionType = tc.functionType
+		bond.FunctionParameters = tc.functionParams
+		bond.MaxSupply = sdk.NewCoin(bond.Token, tc.maxSupply)
+
+		err := bond.ValidateCurve()
+		if tc.expectedErr == nil {
+			require.Nil(t, err)
+		} else {
+			require.True(t, errors.Is(err, tc.expectedErr))
+		}
+	}
+}
+
+func TestValidateCurveChecksHatchBondAsOpen(t *testing.T) {
+	bond := getValidBond()
+	bond.FunctionType = AugmentedFunction
+	bond.FunctionParameters = functionParametersAugmentedFull()
+	bond.State = HatchState
+
+	// Valid since the open curve can be evaluated up to the max supply
+	require.Nil(t, bond.ValidateCurve())
+
+	// Invalid since S^kappa overflows at the max supply once open
+	bond.MaxSupply = sdk.NewCoin(bond.Token, maxInt64)
+	bond.FunctionParameters[3] = NewFunctionParam("kappa", sdk.NewDec(100))
+	err := bond.ValidateCurve()
+	require.True(t, errors.Is(err, ErrCurveEvaluationFailed))
+}
+
+func TestGetReturnsForBurnInsufficientReserve(t *testing.T) {
+	bond := getValidBond()
+	bond.FunctionType = PowerFunction
+	bond.FunctionParameters = functionParametersPower()
+	bond.CurrentSupply = sdk.NewCoin(bond.Token, sdk.NewInt(10))
+
+	// Reserve at supply 9 is 12*(9^3)/3+100*9 = 3816, but only 1 in reserve
+	reserveBalances := sdk.NewCoins(sdk.NewCoin(reserveToken, sdk.OneInt()))
+	_, err := bond.GetReturnsForBurn(sdk.OneInt(), reserveBalances)
+	require.True(t, errors.Is(err, ErrInsufficientReserveForBurn))
+}
+
 func TestGetReturnsForSwap(t *testing.T) {
 	bond := getValidBond()
 	bond.FunctionType = SwapperFunction
@@ -607,6 +840,54 @@ func TestGetReturnsForSwapNonSwapperFunctionFails(t *testing.T) {
 	}
 }
 
+func TestGetReturnsForBatchSwap(t *testing.T) {
+	bond := getValidBond()
+	bond.FunctionType = SwapperFunction
+	bond.FunctionParameters = nil
+	bond.ReserveTokens = swapperReserves()
+	bond.TxFeePercentage = sdk.ZeroDec()
+
+	reserveBalances := sdk.NewCoins(
+		sdk.NewInt64Coin(reserveToken, 10000),
+		sdk.NewInt64Coin(reserveToken2, 10000),
+	)
+
+	// A swap on its own gets the same returns as with GetReturnsForSwap
+	from := sdk.NewInt64Coin(reserveToken, 1000)
+	expected, _, err := bond.GetReturnsForSwap(from, reserveToken2, reserveBalances)
+	require.NoError(t, err)
+	actual, _, err := bond.GetReturnsForBatchSwap(
+		from, reserveToken2, sdk.Coins{from}, reserveBalances)
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+
+	// With total inputs of 3000res and 1000rez, the clearing rate from res to
+	// rez is (10000+1000)/(10000+3000), so 1000res gives 846rez, and the rate
+	// from rez to res is (10000+3000)/(10000+1000), so 1000rez gives 1181res
+	totalInputs := sdk.NewCoins(
+		sdk.NewInt64Coin(reserveToken, 3000),
+		sdk.NewInt64Coin(reserveToken2, 1000),
+	)
+	actual, _, err = bond.GetReturnsForBatchSwap(
+		sdk.NewInt64Coin(reserveToken, 1000), reserveToken2, totalInputs, reserveBalances)
+	require.NoError(t, err)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin(reserveToken2, 846)), actual)
+	actual, _, err = bond.GetReturnsForBatchSwap(
+		sdk.NewInt64Coin(reserveToken2, 1000), reserveToken, totalInputs, reserveBalances)
+	require.NoError(t, err)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 1181)), actual)
+
+	// Invalid reserve token
+	_, _, err = bond.GetReturnsForBatchSwap(
+		sdk.NewInt64Coin(reserveToken, 1000), "dummytoken", totalInputs, reserveBalances)
+	require.True(t, errors.Is(err, ErrTokenIsNotAValidReserveToken))
+
+	// Non-swapper function
+	bond.FunctionType = PowerFunction
+	_, _, err = bond.GetReturnsForBatchSwap(from, reserveToken2, sdk.Coins{from}, reserveBalances)
+	require.True(t, errors.Is(err, ErrFunctionNotAvailableForFunctionType))
+}
+
 func TestBondGetTxFee(t *testing.T) {
 	bond := Bond{}
 	zeroPointOne := sdk.MustNewDecFromStr("0.1")
@@ -718,6 +999,20 @@ func TestBondGetExitFees(t *testing.T) {
 	require.Equal(t, expected, bond.GetExitFees(inputTokens))
 }
 
+func TestBondGetSpendExcludingTxFees(t *testing.T) {
+	bond := getValidBond()
+	bond.TxFeePercentage = sdk.MustNewDecFromStr("25")
+
+	// 25% fee, so 1000 spend is 800 reserve plus 200 fee
+	spend := sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 1000))
+	expected := sdk.NewDecCoinsFromCoins(sdk.NewInt64Coin(reserveToken, 800))
+	require.Equal(t, expected, bond.GetSpendExcludingTxFees(spend))
+
+	// No fee, so the whole spend goes to the reserve
+	bond.TxFeePercentage = sdk.ZeroDec()
+	require.Equal(t, sdk.NewDecCoinsFromCoins(spend...), bond.GetSpendExcludingTxFees(spend))
+}
+
 func TestSignersEqualTo(t *testing.T) {
 	bond := getValidBond()
 
@@ -768,6 +1063,33 @@ func TestReserveDenomsEqualTo(t *testing.T) {
 	}
 }
 
+func TestReserveDenomsInclude(t *testing.T) {
+	bond := getValidBond()
+
+	denom1 := reserveToken
+	denom2 := reserveToken2
+	denom3 := reserveToken3
+	bond.ReserveTokens = []string{denom1, denom2}
+
+	testCases := []struct {
+		toCompareTo     []string
+		expectedInclude bool
+	}{
+		{[]string{}, true},                        // None
+		{[]string{denom1}, true},                  // One missing (allowed)
+		{[]string{denom1, denom2}, true},          // Equal
+		{[]string{denom1, denom3}, false},         // One different
+		{[]string{denom1, denom2, denom3}, false}, // One extra
+	}
+	for _, tc := range testCases {
+		coins := sdk.Coins{}
+		for _, res := range tc.toCompareTo {
+			coins = coins.Add(sdk.NewCoin(res, sdk.OneInt()))
+		}
+		require.Equal(t, tc.expectedInclude, bond.ReserveDenomsInclude(coins))
+	}
+}
+
 func TestAnyOrderQuantityLimitsExceeded(t *testing.T) {
 	bond := getValidBond()
 	bond.OrderQuantityLimits, _ = sdk.ParseCoins("100aaa,200bbb")
@@ -836,3 +1158,106 @@ func TestReservesViolateSanityRateReturnsFalseWhenSanityRateIsZero(t *testing.T)
 		require.Equal(t, tc.violates, actualResult)
 	}
 }
+
+func TestGetMaxBatchOrders(t *testing.T) {
+	testCases := []struct {
+		bondMax     uint64
+		moduleMax   uint64
+		expectedMax uint64
+	}{
+		{0, 100, 100},   // bond uses module maximum
+		{10, 100, 10},   // bond maximum lower than module maximum
+		{100, 100, 100}, // bond maximum same as module maximum
+		{200, 100, 100}, // bond maximum capped by module maximum
+	}
+	for _, tc := range testCases {
+		bond := getValidBond()
+		bond.MaxBatchOrders = sdk.NewUint(tc.bondMax)
+		require.Equal(t, tc.expectedMax, bond.GetMaxBatchOrders(tc.moduleMax))
+	}
+}
+
+func TestGetMaxBatchVolume(t *testing.T) {
+	testCases := []struct {
+		bondMax     uint64
+		moduleMax   uint64
+		expectedMax int64
+	}{
+		{0, 100, 100},   // bond uses module maximum
+		{10, 100, 10},   // bond maximum lower than module maximum
+		{100, 100, 100}, // bond maximum same as module maximum
+		{200, 100, 100}, // bond maximum capped by module maximum
+	}
+	for _, tc := range testCases {
+		bond := getValidBond()
+		bond.MaxBatchVolume = sdk.NewUint(tc.bondMax)
+		require.Equal(t, sdk.NewInt(tc.expectedMax), bond.GetMaxBatchVolume(tc.moduleMax))
+	}
+}
+
+func TestGetNextOutcomeTranche(t *testing.T) {
+	bond := getValidBond()
+	bond.OutcomeTranches = newValidOutcomeTranches()
+	require.True(t, bond.HasOutcomePayment())
+	require.True(t, bond.HasOutcomeTranches())
+
+	// First tranche is not the last
+	tranche, last, found := bond.GetNextOutcomeTranche()
+	require.True(t, found)
+	require.False(t, last)
+	require.Equal(t, bond.OutcomeTranches[0], tranche)
+
+	// Second tranche is the last
+	bond.TranchesPaid = 1
+	tranche, last, found = bond.GetNextOutcomeTranche()
+	require.True(t, found)
+	require.True(t, last)
+	require.Equal(t, bond.OutcomeTranches[1], tranche)
+
+	// No tranches left once all have been paid
+	bond.TranchesPaid = 2
+	_, _, found = bond.GetNextOutcomeTranche()
+	require.False(t, found)
+
+	// Bond without tranches has no next tranche
+	bond = getValidBond()
+	require.False(t, bond.HasOutcomeTranches())
+	_, _, found = bond.GetNextOutcomeTranche()
+	require.False(t, found)
+}
+
+func TestOutcomeTrancheCanBePaidBy(t *testing.T) {
+	payer := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	other := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	amount := sdk.NewCoins(sdk.NewInt64Coin(reserveToken, 10))
+
+	// Tranche without a payer can be paid by anyone
+	tranche := NewOutcomeTranche(amount, nil, false)
+	require.True(t, tranche.CanBePaidBy(payer))
+	require.True(t, tranche.CanBePaidBy(other))
+
+	// Tranche with a payer can only be paid by the payer
+	tranche = NewOutcomeTranche(amount, payer, false)
+	require.True(t, tranche.CanBePaidBy(payer))
+	require.False(t, tranche.CanBePaidBy(other))
+}
+
+func TestCurveInvalidArgumentsReturnError(t *testing.T) {
+	bond := getValidBond()
+	bond.FunctionType = PowerFunction
+	bond.FunctionParameters = functionParametersPower()
+
+	// Negative amounts give an error rather than a panic
+	_, err := bond.ReserveAtSupply(sdk.NewInt(-1))
+	require.True(t, errors.Is(err, ErrArgumentCannotBeNegative))
+	_, err = bond.SupplyAtReserve(sdk.NewDec(-1))
+	require.True(t, errors.Is(err, ErrArgumentCannotBeNegative))
+	_, err = bond.GetReturnsForBurn(sdk.NewInt(-1), nil)
+	require.True(t, errors.Is(err, ErrArgumentCannotBeNegative))
+
+	// Parameters that are too large for curve arithmetic give an error
+	huge := sdk.NewDecFromBigInt(new(big.Int).Lsh(big.NewInt(1), 254))
+	bond.FunctionParameters[0] = NewFunctionParam("m", huge)
+	_, err = bond.GetPricesAtSupply(sdk.OneInt())
+	require.True(t, errors.Is(err, ErrCurveOverflow))
+}
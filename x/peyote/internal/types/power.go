@@ -0,0 +1,175 @@
+package types
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/x/simulation"
+	"math/rand"
+)
+
+// powerFunction is the bonding curve y = mx^n + c
+type powerFunction struct{}
+
+var _ BondingFunction = powerFunction{}
+
+func (powerFunction) RequiredParams() []string { return []string{"m", "n", "c"} }
+
+func (powerFunction) NoOfReserveTokens() int { return AnyNumberOfReserveTokens }
+
+func (powerFunction) ValidateParams(map[string]sdk.Dec) error {
+	// Note: n can be fractional, since x^n is evaluated using ApproxPower
+	return nil
+}
+
+func (powerFunction) PricesAtSupply(bond Bond, supply sdk.Int) (sdk.DecCoins, error) {
+	args := bond.FunctionParameters.AsMap()
+	x := supply.ToDec()
+	m := args["m"]
+	n := args["n"]
+	c := args["c"]
+	temp, err := ApproxPower(x, n)
+	if err != nil {
+		return nil, curveEvaluationFailed(err)
+	}
+	temp, err = mulDec(temp, m)
+	if err != nil {
+		return nil, err
+	}
+	return bond.GetNewReserveDecCoins(temp.Add(c)), nil
+}
+
+func (fn powerFunction) CurrentPricesPT(bond Bond, _ sdk.Coins) (sdk.DecCoins, error) {
+	return fn.PricesAtSupply(bond, bond.CurrentSupply.Amount)
+}
+
+func (powerFunction) ReserveAtSupply(bond Bond, supply sdk.Int) (sdk.Dec, error) {
+	args := bond.FunctionParameters.AsMap()
+	x := supply.ToDec()
+	m := args["m"]
+	n := args["n"]
+	c := args["c"]
+	return powerReserve(x, m, n.Add(sdk.OneDec()), c)
+}
+
+// powerReserve returns the reserve mx^(n+1)/(n+1) + cx, given n+1 as n1.
+func powerReserve(x, m, n1, c sdk.Dec) (sdk.Dec, error) {
+	temp1, err := ApproxPower(x, n1)
+	if err != nil {
+		return sdk.Dec{}, curveEvaluationFailed(err)
+	}
+	temp2, err := mulDec(temp1, m)
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+	temp2, err = quoDec(temp2, n1)
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+	temp3, err := mulDec(x, c)
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+	return temp2.Add(temp3), nil
+}
+
+func (fn powerFunction) SupplyAtReserve(bond Bond, reserve sdk.Dec) (sdk.Dec, error) {
+	args := bond.FunctionParameters.AsMap()
+	m := args["m"]
+	n := args["n"]
+	c := args["c"]
+
+	if m.IsZero() && c.IsZero() {
+		return sdk.Dec{}, sdkerrors.Wrap(ErrCurveEvaluationFailed,
+			"cannot invert a curve with a constant zero price")
+	} else if m.IsZero() {
+		// Reserve is linear: y = cx
+		return quoDec(reserve, c)
+	}
+
+	// Reserve is a pure power: y = mx^(n+1)/(n+1)
+	n1 := n.Add(sdk.OneDec())
+	temp, err := mulDec(reserve, n1)
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+	temp, err = quoDec(temp, m)
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+	powerInverse, err := ApproxRoot(temp, n1)
+	if err != nil {
+		return sdk.Dec{}, curveEvaluationFailed(err)
+	} else if c.IsZero() {
+		return powerInverse, nil
+	}
+
+	// Otherwise, the supply is found numerically since the reserve is convex.
+	// The supply is bounded by the supply at which each of the two terms of
+	// the reserve alone reaches the reserve, i.e. by the closed-form inverses.
+	linearInverse, err := quoDec(reserve, c)
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+	hi := sdk.MinDec(powerInverse, linearInverse)
+
+	// The reserve is mx^(n+1)/(n+1) + cx and the price is mx^n + c
+	reserveAt := func(x sdk.Dec) (sdk.Dec, error) {
+		return powerReserve(x, m, n1, c)
+	}
+	priceAt := func(x sdk.Dec) (sdk.Dec, error) {
+		temp, err := ApproxPower(x, n)
+		if err != nil {
+			return sdk.Dec{}, curveEvaluationFailed(err)
+		}
+		temp, err = mulDec(temp, m)
+		if err != nil {
+			return sdk.Dec{}, err
+		}
+		return temp.Add(c), nil
+	}
+	return invertConvex(reserveAt, priceAt, reserve, hi)
+}
+
+func (fn powerFunction) PricesToMint(bond Bond, mint sdk.Int, reserveBalances sdk.Coins) (sdk.DecCoins, error) {
+	newSupply := bond.CurrentSupply.Amount.Add(mint)
+	reserveAtNewSupply, err := fn.ReserveAtSupply(bond, newSupply)
+	if err != nil {
+		return nil, err
+	}
+	return curvePricesToMint(bond, reserveAtNewSupply, reserveBalances), nil
+}
+
+func (fn powerFunction) AmountToMint(bond Bond, prices sdk.DecCoins, reserveBalances sdk.Coins) (sdk.Int, error) {
+	return curveAmountToMint(bond, fn, prices, reserveBalances)
+}
+
+func (fn powerFunction) ReturnsForBurn(bond Bond, burn sdk.Int, reserveBalances sdk.Coins) (sdk.DecCoins, error) {
+	newSupply := bond.CurrentSupply.Amount.Sub(burn)
+	reserveAtNewSupply, err := fn.ReserveAtSupply(bond, newSupply)
+	if err != nil {
+		return nil, err
+	}
+	return curveReturnsForBurn(bond, reserveAtNewSupply, reserveBalances)
+}
+
+func (powerFunction) ReturnsForSwap(bond Bond, _ sdk.Coin, _ string, _ sdk.Coins) (sdk.Coins, sdk.Coin, error) {
+	return swapsNotAvailable(bond)
+}
+
+func (powerFunction) ReturnsForBatchSwap(bond Bond, _ sdk.Coin, _ string, _, _ sdk.Coins) (sdk.Coins, sdk.Coin, error) {
+	return swapsNotAvailable(bond)
+}
+
+func (powerFunction) EnforcesReserveInvariant() bool { return true }
+
+func (powerFunction) IsLiquidityPool() bool { return false }
+
+func (powerFunction) RandomParams(r *rand.Rand) FunctionParams {
+	m := simulation.RandIntBetween(r, 1, 100)
+	n := simulation.RandIntBetween(r, 1, 5)
+	c := simulation.RandIntBetween(r, 1, 1000)
+	return FunctionParams{
+		NewFunctionParam("m", sdk.NewDec(int64(m))),
+		NewFunctionParam("n", sdk.NewDec(int64(n))),
+		NewFunctionParam("c", sdk.NewDec(int64(c)))}
+}
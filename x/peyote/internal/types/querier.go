@@ -32,3 +32,27 @@ type QuerySwapReturn struct {
 	TotalReturns sdk.Coins `json:"total_returns" yaml:"total_returns"`
 	TotalFees    sdk.Coins `json:"total_fees" yaml:"total_fees"`
 }
+
+type QueryLimitOrders struct {
+	Buys  []LimitOrder `json:"buys" yaml:"buys"`
+	Sells []LimitOrder `json:"sells" yaml:"sells"`
+}
+
+// MaxBatchHistoryLimit is the maximum number of batch records returned by a
+// single batch history query.
+const MaxBatchHistoryLimit = 100
+
+// QueryBatchHistoryParams are the parameters of a bond's batch history query,
+// which returns the records of the bond's performed batches from the most
+// recent one backwards, a page at a time.
+type QueryBatchHistoryParams struct {
+	Page  int `json:"page" yaml:"page"`
+	Limit int `json:"limit" yaml:"limit"`
+}
+
+func NewQueryBatchHistoryParams(page, limit int) QueryBatchHistoryParams {
+	return QueryBatchHistoryParams{
+		Page:  page,
+		Limit: limit,
+	}
+}
@@ -0,0 +1,54 @@
+package types
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// RecurringOrder is a schedule of orders that places a spend-limited buy (or a
+// sell of a fixed amount of bond tokens) into every Nth batch of a bond that is
+// performed, where N is the interval. The budget is escrowed when the order is
+// created, and the amount of each order is taken from it. The schedule ends
+// (and the remaining budget is refunded) once the budget no longer covers the
+// amount of an order, once the end height (if any) is reached, or once it is
+// cancelled by its owner.
+type RecurringOrder struct {
+	Id            uint64         `json:"id" yaml:"id"`
+	OrderType     string         `json:"order_type" yaml:"order_type"`
+	Address       sdk.AccAddress `json:"address" yaml:"address"`
+	BondToken     string         `json:"bond_token" yaml:"bond_token"`
+	Amount        sdk.Coins      `json:"amount" yaml:"amount"`
+	Budget        sdk.Coins      `json:"budget" yaml:"budget"`
+	Interval      sdk.Uint       `json:"interval" yaml:"interval"`
+	EndHeight     int64          `json:"end_height" yaml:"end_height"`
+	BatchesToSkip uint64         `json:"batches_to_skip" yaml:"batches_to_skip"`
+	OrdersPlaced  uint64         `json:"orders_placed" yaml:"orders_placed"`
+}
+
+func NewRecurringOrder(orderType string, address sdk.AccAddress, bondToken string,
+	amount, budget sdk.Coins, interval sdk.Uint, endHeight int64) RecurringOrder {
+	return RecurringOrder{
+		OrderType: orderType,
+		Address:   address,
+		BondToken: bondToken,
+		Amount:    amount,
+		Budget:    budget,
+		Interval:  interval,
+		EndHeight: endHeight,
+	}
+}
+
+func (ro RecurringOrder) IsBuy() bool {
+	return ro.OrderType == AttributeValueBuyOrder
+}
+
+// HasEndHeight returns true if the recurring order ends at a specific height
+// rather than only once its budget has been spent.
+func (ro RecurringOrder) HasEndHeight() bool {
+	return ro.EndHeight != 0
+}
+
+// CanPlaceOrder returns true if the remaining budget covers the amount of
+// another order.
+func (ro RecurringOrder) CanPlaceOrder() bool {
+	return ro.Budget.IsAllGTE(ro.Amount)
+}
@@ -0,0 +1,181 @@
+package types
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/x/simulation"
+	"math/rand"
+)
+
+// sigmoidFunction is the bonding curve y = a(((x-b)/sqrt((x-b)^2+c))+1)
+type sigmoidFunction struct{}
+
+var _ BondingFunction = sigmoidFunction{}
+
+func (sigmoidFunction) RequiredParams() []string { return []string{"a", "b", "c"} }
+
+func (sigmoidFunction) NoOfReserveTokens() int { return AnyNumberOfReserveTokens }
+
+func (sigmoidFunction) ValidateParams(paramsMap map[string]sdk.Dec) error {
+	// Sigmoid exception 1: c != 0, otherwise we run into divisions by zero
+	val, ok := paramsMap["c"]
+	if !ok {
+		panic("did not find parameter c for sigmoid function")
+	} else if !val.IsPositive() {
+		return sdkerrors.Wrap(ErrArgumentMustBePositive, "FunctionParams:c")
+	}
+	return nil
+}
+
+func (sigmoidFunction) PricesAtSupply(bond Bond, supply sdk.Int) (sdk.DecCoins, error) {
+	args := bond.FunctionParameters.AsMap()
+	x := supply.ToDec()
+	a := args["a"]
+	b := args["b"]
+	c := args["c"]
+	temp1 := x.Sub(b)
+	temp2, err := mulDec(temp1, temp1)
+	if err != nil {
+		return nil, err
+	}
+	temp3, err := temp2.Add(c).ApproxSqrt()
+	if err != nil {
+		return nil, curveEvaluationFailed(err)
+	}
+	temp4, err := quoDec(temp1, temp3)
+	if err != nil {
+		return nil, err
+	}
+	price, err := mulDec(a, temp4.Add(sdk.OneDec()))
+	if err != nil {
+		return nil, err
+	}
+	return bond.GetNewReserveDecCoins(price), nil
+}
+
+func (fn sigmoidFunction) CurrentPricesPT(bond Bond, _ sdk.Coins) (sdk.DecCoins, error) {
+	return fn.PricesAtSupply(bond, bond.CurrentSupply.Amount)
+}
+
+func (sigmoidFunction) ReserveAtSupply(bond Bond, supply sdk.Int) (sdk.Dec, error) {
+	args := bond.FunctionParameters.AsMap()
+	x := supply.ToDec()
+	a := args["a"]
+	b := args["b"]
+	c := args["c"]
+	temp1 := x.Sub(b)
+	temp2, err := mulDec(temp1, temp1)
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+	temp3, err := temp2.Add(c).ApproxSqrt()
+	if err != nil {
+		return sdk.Dec{}, curveEvaluationFailed(err)
+	}
+	temp5, err := mulDec(a, temp3.Add(x))
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+	approx, err := sigmoidSqrtB2PlusC(b, c)
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+	constant, err := mulDec(a, approx)
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+	return temp5.Sub(constant), nil
+}
+
+// sigmoidSqrtB2PlusC returns sqrt(b^2+c), i.e. the sigmoid's sqrt((x-b)^2+c)
+// at a zero supply.
+func sigmoidSqrtB2PlusC(b, c sdk.Dec) (sdk.Dec, error) {
+	temp, err := mulDec(b, b)
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+	result, err := temp.Add(c).ApproxSqrt()
+	if err != nil {
+		return sdk.Dec{}, curveEvaluationFailed(err)
+	}
+	return result, nil
+}
+
+func (sigmoidFunction) SupplyAtReserve(bond Bond, reserve sdk.Dec) (sdk.Dec, error) {
+	args := bond.FunctionParameters.AsMap()
+	a := args["a"]
+	b := args["b"]
+	c := args["c"]
+	if a.IsZero() {
+		return sdk.Dec{}, sdkerrors.Wrap(ErrCurveEvaluationFailed,
+			"cannot invert a curve with a constant zero price")
+	}
+
+	// Solving y = a(sqrt((x-b)^2+c) + x) - a*sqrt(b^2+c) for x gives
+	// x = (k^2-b^2-c)/(2(k-b)), where k = y/a + sqrt(b^2+c). Note that
+	// k > b, since sqrt(b^2+c) > |b| given that c > 0.
+	approx, err := sigmoidSqrtB2PlusC(b, c)
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+	k, err := quoDec(reserve, a)
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+	k = k.Add(approx)
+	kSquared, err := mulDec(k, k)
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+	bSquared, err := mulDec(b, b)
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+	temp1 := kSquared.Sub(bSquared).Sub(c)
+	temp2 := k.Sub(b).MulInt64(2)
+	return quoDec(temp1, temp2)
+}
+
+func (fn sigmoidFunction) PricesToMint(bond Bond, mint sdk.Int, reserveBalances sdk.Coins) (sdk.DecCoins, error) {
+	newSupply := bond.CurrentSupply.Amount.Add(mint)
+	reserveAtNewSupply, err := fn.ReserveAtSupply(bond, newSupply)
+	if err != nil {
+		return nil, err
+	}
+	return curvePricesToMint(bond, reserveAtNewSupply, reserveBalances), nil
+}
+
+func (fn sigmoidFunction) AmountToMint(bond Bond, prices sdk.DecCoins, reserveBalances sdk.Coins) (sdk.Int, error) {
+	return curveAmountToMint(bond, fn, prices, reserveBalances)
+}
+
+func (fn sigmoidFunction) ReturnsForBurn(bond Bond, burn sdk.Int, reserveBalances sdk.Coins) (sdk.DecCoins, error) {
+	newSupply := bond.CurrentSupply.Amount.Sub(burn)
+	reserveAtNewSupply, err := fn.ReserveAtSupply(bond, newSupply)
+	if err != nil {
+		return nil, err
+	}
+	return curveReturnsForBurn(bond, reserveAtNewSupply, reserveBalances)
+}
+
+func (sigmoidFunction) ReturnsForSwap(bond Bond, _ sdk.Coin, _ string, _ sdk.Coins) (sdk.Coins, sdk.Coin, error) {
+	return swapsNotAvailable(bond)
+}
+
+func (sigmoidFunction) ReturnsForBatchSwap(bond Bond, _ sdk.Coin, _ string, _, _ sdk.Coins) (sdk.Coins, sdk.Coin, error) {
+	return swapsNotAvailable(bond)
+}
+
+func (sigmoidFunction) EnforcesReserveInvariant() bool { return true }
+
+func (sigmoidFunction) IsLiquidityPool() bool { return false }
+
+func (sigmoidFunction) RandomParams(r *rand.Rand) FunctionParams {
+	a := simulation.RandIntBetween(r, 1, 10)
+	b := simulation.RandIntBetween(r, 1, 10)
+	c := simulation.RandIntBetween(r, 1, 10)
+	return FunctionParams{
+		NewFunctionParam("a", sdk.NewDec(int64(a))),
+		NewFunctionParam("b", sdk.NewDec(int64(b))),
+		NewFunctionParam("c", sdk.NewDec(int64(c)))}
+}
@@ -0,0 +1,155 @@
+package types
+
+import (
+	"bytes"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// SettlementSnapshot records the bond token balances of a bond's holders at
+// the height at which the bond entered the SETTLE state. The share of the
+// reserve owed to each holder is based on the snapshot rather than on the
+// holder's current balance, so that bond tokens transferred after settlement
+// do not change who is owed what. The holders are stored separately from the
+// rest of the snapshot, and are only included when exporting genesis. If the
+// bond has a claim window, any reserve that has not been withdrawn by the
+// claim deadline is swept and the bond is closed. No shares can be withdrawn
+// until all of the holders have been recorded.
+type SettlementSnapshot struct {
+	BondToken      string           `json:"bond_token" yaml:"bond_token"`
+	Height         int64            `json:"height" yaml:"height"`
+	ClaimDeadline  int64            `json:"claim_deadline" yaml:"claim_deadline"`
+	TotalBalance   sdk.Int          `json:"total_balance" yaml:"total_balance"`
+	TotalWithdrawn sdk.Int          `json:"total_withdrawn" yaml:"total_withdrawn"`
+	Recording      HolderRecording  `json:"recording" yaml:"recording"`
+	Holders        []HolderSnapshot `json:"holders" yaml:"holders"`
+}
+
+func NewSettlementSnapshot(bondToken string, height int64) SettlementSnapshot {
+	return SettlementSnapshot{
+		BondToken:      bondToken,
+		Height:         height,
+		TotalBalance:   sdk.ZeroInt(),
+		TotalWithdrawn: sdk.ZeroInt(),
+	}
+}
+
+// Remaining returns the total amount of bond tokens whose share of the
+// reserve has not been withdrawn yet.
+func (s SettlementSnapshot) Remaining() sdk.Int {
+	return s.TotalBalance.Sub(s.TotalWithdrawn)
+}
+
+// HasClaimDeadline returns true if the reserve can only be withdrawn up to a
+// specific height rather than indefinitely.
+func (s SettlementSnapshot) HasClaimDeadline() bool {
+	return s.ClaimDeadline != 0
+}
+
+// HolderRecording records the progress of recording the bond token balances
+// of a bond's holders for a settlement snapshot or tranche distribution, which
+// is done a limited number of holders at a time, in order of address, at the
+// end of each block (as with share distributions). The last holder visited is
+// recorded so that the recording resumes after it in the next block. Until it
+// is visited, any holder whose balance is about to change has its balance
+// recorded as it was before the change, so that the recorded balances are
+// those at the height at which the recording started.
+type HolderRecording struct {
+	InProgress  bool           `json:"in_progress" yaml:"in_progress"`
+	LastHolder  sdk.AccAddress `json:"last_holder" yaml:"last_holder"`
+	HolderCount uint64         `json:"holder_count" yaml:"holder_count"`
+}
+
+// NewHolderRecording returns a recording that has not visited any holders.
+func NewHolderRecording() HolderRecording {
+	return HolderRecording{InProgress: true}
+}
+
+// Visited returns true if the recording has already visited the holder, i.e.
+// if the holder's balance can no longer be recorded.
+func (r HolderRecording) Visited(address sdk.AccAddress) bool {
+	return !r.InProgress ||
+		(!r.LastHolder.Empty() && bytes.Compare(address, r.LastHolder) <= 0)
+}
+
+// HolderSnapshot is the bond token balance of a holder at the height at which
+// the bond was settled, along with the amount whose share of the reserve the
+// holder has already withdrawn.
+type HolderSnapshot struct {
+	Address   sdk.AccAddress `json:"address" yaml:"address"`
+	Balance   sdk.Int        `json:"balance" yaml:"balance"`
+	Withdrawn sdk.Int        `json:"withdrawn" yaml:"withdrawn"`
+}
+
+func NewHolderSnapshot(address sdk.AccAddress, balance sdk.Int) HolderSnapshot {
+	return HolderSnapshot{
+		Address:   address,
+		Balance:   balance,
+		Withdrawn: sdk.ZeroInt(),
+	}
+}
+
+// Remaining returns the amount of bond tokens whose share of the reserve the
+// holder can still withdraw.
+func (h HolderSnapshot) Remaining() sdk.Int {
+	return h.Balance.Sub(h.Withdrawn)
+}
+
+// ShareDistribution records the progress of distributing a settled bond's
+// reserve to the holders in its settlement snapshot, which is done a limited
+// number of holders at a time, in order of address. The last holder visited
+// is recorded so that the distribution resumes after it in the next block.
+type ShareDistribution struct {
+	BondToken   string         `json:"bond_token" yaml:"bond_token"`
+	LastHolder  sdk.AccAddress `json:"last_holder" yaml:"last_holder"`
+	HoldersPaid uint64         `json:"holders_paid" yaml:"holders_paid"`
+}
+
+func NewShareDistribution(bondToken string) ShareDistribution {
+	return ShareDistribution{
+		BondToken: bondToken,
+	}
+}
+
+// TrancheDistribution records the progress of distributing an outcome tranche
+// to the holders of a bond, in proportion to their bond token balances when
+// the tranche was paid. The tranche is escrowed when it is paid and is then
+// distributed a limited number of holders at a time, in order of address, as
+// with share distributions. Each holder is removed once it has been visited,
+// and whatever remains of the tranche once all holders have been visited
+// (e.g. due to rounding) is sent to the bond's reserve. No holders are paid
+// until all of them have been recorded. The holders are stored separately
+// from the rest of the distribution, and are only included when exporting
+// genesis.
+type TrancheDistribution struct {
+	BondToken    string           `json:"bond_token" yaml:"bond_token"`
+	Tranche      uint64           `json:"tranche" yaml:"tranche"`
+	Amount       sdk.Coins        `json:"amount" yaml:"amount"`
+	Remaining    sdk.Coins        `json:"remaining" yaml:"remaining"`
+	TotalBalance sdk.Int          `json:"total_balance" yaml:"total_balance"`
+	HoldersPaid  uint64           `json:"holders_paid" yaml:"holders_paid"`
+	Recording    HolderRecording  `json:"recording" yaml:"recording"`
+	Holders      []HolderSnapshot `json:"holders" yaml:"holders"`
+}
+
+func NewTrancheDistribution(bondToken string, tranche uint64, amount sdk.Coins) TrancheDistribution {
+	return TrancheDistribution{
+		BondToken:    bondToken,
+		Tranche:      tranche,
+		Amount:       amount,
+		Remaining:    amount,
+		TotalBalance: sdk.ZeroInt(),
+	}
+}
+
+// GetShare returns the share of the tranche owed to a holder with the
+// specified bond token balance, which is rounded down.
+func (d TrancheDistribution) GetShare(balance sdk.Int) (share sdk.Coins) {
+	for _, c := range d.Amount {
+		owed := c.Amount.Mul(balance).Quo(d.TotalBalance)
+		if owed.IsPositive() {
+			share = share.Add(sdk.NewCoin(c.Denom, owed))
+		}
+	}
+	return share
+}
@@ -0,0 +1,158 @@
+package types
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"math/rand"
+)
+
+// swapperFunction is the constant product (x*y=k) function used for swaps
+// between its two reserve tokens, with the bond token used as liquidity shares
+type swapperFunction struct{}
+
+var _ BondingFunction = swapperFunction{}
+
+func (swapperFunction) RequiredParams() []string { return nil }
+
+func (swapperFunction) NoOfReserveTokens() int { return 2 }
+
+func (swapperFunction) ValidateParams(map[string]sdk.Dec) error { return nil }
+
+func (swapperFunction) PricesAtSupply(bond Bond, _ sdk.Int) (sdk.DecCoins, error) {
+	return nil, sdkerrors.Wrap(ErrFunctionNotAvailableForFunctionType, bond.FunctionType)
+}
+
+func (fn swapperFunction) CurrentPricesPT(bond Bond, reserveBalances sdk.Coins) (sdk.DecCoins, error) {
+	return fn.PricesToMint(bond, sdk.OneInt(), reserveBalances)
+}
+
+func (swapperFunction) ReserveAtSupply(bond Bond, _ sdk.Int) (sdk.Dec, error) {
+	return sdk.Dec{}, sdkerrors.Wrap(ErrFunctionNotAvailableForFunctionType, bond.FunctionType)
+}
+
+func (swapperFunction) SupplyAtReserve(bond Bond, _ sdk.Dec) (sdk.Dec, error) {
+	return sdk.Dec{}, sdkerrors.Wrap(ErrFunctionNotAvailableForFunctionType, bond.FunctionType)
+}
+
+func (swapperFunction) PricesToMint(bond Bond, mint sdk.Int, reserveBalances sdk.Coins) (sdk.DecCoins, error) {
+	if bond.CurrentSupply.Amount.IsZero() {
+		return nil, sdkerrors.Wrap(ErrFunctionRequiresNonZeroCurrentSupply, bond.CurrentSupply.Amount.String())
+	}
+	return bond.GetReserveDeltaForLiquidityDelta(mint, reserveBalances)
+}
+
+func (swapperFunction) AmountToMint(bond Bond, prices sdk.DecCoins, reserveBalances sdk.Coins) (sdk.Int, error) {
+	if bond.CurrentSupply.Amount.IsZero() {
+		return sdk.Int{}, sdkerrors.Wrap(ErrFunctionRequiresNonZeroCurrentSupply, bond.CurrentSupply.Amount.String())
+	}
+
+	// Inverting the Uniswap formula Δx = αx used by PricesToMint, the
+	// liquidity that can be added is limited by the smallest α = Δx/x
+	alpha := sdk.Dec{}
+	for _, r := range bond.ReserveTokens {
+		resBalance := reserveBalances.AmountOf(r)
+		if !resBalance.IsPositive() {
+			return sdk.ZeroInt(), nil
+		}
+		temp := prices.AmountOf(r).QuoInt(resBalance)
+		if alpha.IsNil() || temp.LT(alpha) {
+			alpha = temp
+		}
+	}
+	amount, err := mulDec(alpha, bond.CurrentSupply.Amount.ToDec())
+	if err != nil {
+		return sdk.Int{}, err
+	}
+	return amount.TruncateInt(), nil
+}
+
+func (swapperFunction) ReturnsForBurn(bond Bond, burn sdk.Int, reserveBalances sdk.Coins) (sdk.DecCoins, error) {
+	return bond.GetReserveDeltaForLiquidityDelta(burn, reserveBalances)
+}
+
+func (swapperFunction) ReturnsForSwap(bond Bond, from sdk.Coin, toToken string, reserveBalances sdk.Coins) (returns sdk.Coins, txFee sdk.Coin, err error) {
+	// Check that from and to are reserve tokens
+	if from.Denom != bond.ReserveTokens[0] && from.Denom != bond.ReserveTokens[1] {
+		return nil, sdk.Coin{}, sdkerrors.Wrap(ErrTokenIsNotAValidReserveToken, from.Denom)
+	} else if toToken != bond.ReserveTokens[0] && toToken != bond.ReserveTokens[1] {
+		return nil, sdk.Coin{}, sdkerrors.Wrap(ErrTokenIsNotAValidReserveToken, toToken)
+	}
+
+	inAmt := from.Amount
+	inRes := reserveBalances.AmountOf(from.Denom)
+	outRes := reserveBalances.AmountOf(toToken)
+
+	// Calculate fee to get the adjusted input amount
+	txFee = bond.GetTxFee(sdk.NewDecCoinFromCoin(from))
+	inAmt = inAmt.Sub(txFee.Amount) // adjusted input
+
+	// Check that at least 1 token is going in
+	if inAmt.IsZero() {
+		return nil, sdk.Coin{}, sdkerrors.Wrapf(ErrSwapAmountTooSmallToGiveAnyReturn, "%s - %s", from.Denom, toToken)
+	}
+
+	// Calculate output amount using Uniswap formula: Δy = (Δx*y)/(x+Δx)
+	outAmt, err := mulInt(inAmt, outRes)
+	if err != nil {
+		return nil, sdk.Coin{}, err
+	}
+	outAmt = outAmt.Quo(inRes.Add(inAmt))
+
+	// Check that not giving out all of the available outRes or nothing at all
+	if outAmt.Equal(outRes) {
+		return nil, sdk.Coin{}, sdkerrors.Wrapf(ErrSwapAmountCausesReserveDepletion, "%s - %s", from.Denom, toToken)
+	} else if outAmt.IsZero() {
+		return nil, sdk.Coin{}, sdkerrors.Wrapf(ErrSwapAmountTooSmallToGiveAnyReturn, "%s - %s", from.Denom, toToken)
+	} else if outAmt.IsNegative() {
+		return nil, sdk.Coin{}, sdkerrors.Wrapf(ErrNegativeCurveResult, "swap return for bond %s", bond.Token)
+	}
+
+	return sdk.Coins{sdk.NewCoin(toToken, outAmt)}, txFee, nil
+}
+
+func (swapperFunction) ReturnsForBatchSwap(bond Bond, from sdk.Coin, toToken string, totalInputs, reserveBalances sdk.Coins) (returns sdk.Coins, txFee sdk.Coin, err error) {
+	// Check that from and to are reserve tokens
+	if from.Denom != bond.ReserveTokens[0] && from.Denom != bond.ReserveTokens[1] {
+		return nil, sdk.Coin{}, sdkerrors.Wrap(ErrTokenIsNotAValidReserveToken, from.Denom)
+	} else if toToken != bond.ReserveTokens[0] && toToken != bond.ReserveTokens[1] {
+		return nil, sdk.Coin{}, sdkerrors.Wrap(ErrTokenIsNotAValidReserveToken, toToken)
+	}
+
+	// Calculate fee to get the adjusted input amount
+	txFee = bond.GetTxFee(sdk.NewDecCoinFromCoin(from))
+	inAmt := from.Amount.Sub(txFee.Amount) // adjusted input
+
+	// Check that at least 1 token is going in
+	if inAmt.IsZero() {
+		return nil, sdk.Coin{}, sdkerrors.Wrapf(ErrSwapAmountTooSmallToGiveAnyReturn, "%s - %s", from.Denom, toToken)
+	}
+
+	// With reserves x,y and total inputs a,b, swaps in opposite directions are
+	// netted against each other and the residual is settled against the pool
+	// at the clearing rate (y+b)/(x+a), which leaves the product x*y unchanged.
+	// Each swap from x to y thus gets the same output Δy = Δx*(y+b)/(x+a).
+	inTotal := reserveBalances.AmountOf(from.Denom).Add(totalInputs.AmountOf(from.Denom))
+	outTotal := reserveBalances.AmountOf(toToken).Add(totalInputs.AmountOf(toToken))
+	outAmt, err := mulInt(inAmt, outTotal)
+	if err != nil {
+		return nil, sdk.Coin{}, err
+	}
+	outAmt = outAmt.Quo(inTotal)
+
+	// Check that not giving out all of the available outRes or nothing at all
+	if outAmt.GTE(outTotal) {
+		return nil, sdk.Coin{}, sdkerrors.Wrapf(ErrSwapAmountCausesReserveDepletion, "%s - %s", from.Denom, toToken)
+	} else if outAmt.IsZero() {
+		return nil, sdk.Coin{}, sdkerrors.Wrapf(ErrSwapAmountTooSmallToGiveAnyReturn, "%s - %s", from.Denom, toToken)
+	} else if outAmt.IsNegative() {
+		return nil, sdk.Coin{}, sdkerrors.Wrapf(ErrNegativeCurveResult, "swap return for bond %s", bond.Token)
+	}
+
+	return sdk.Coins{sdk.NewCoin(toToken, outAmt)}, txFee, nil
+}
+
+func (swapperFunction) EnforcesReserveInvariant() bool { return false }
+
+func (swapperFunction) IsLiquidityPool() bool { return true }
+
+func (swapperFunction) RandomParams(*rand.Rand) FunctionParams { return nil }
@@ -33,10 +33,11 @@ func CheckReserveTokenNames(resTokens []string, token string) error {
 
 func CheckNoOfReserveTokens(resTokens []string, fnType string) error {
 	// Come up with number of expected reserve tokens
-	expectedNoOfTokens, ok := NoOfReserveTokensForFunctionType[fnType]
-	if !ok {
-		return sdkerrors.Wrap(ErrUnrecognizedFunctionType, fnType)
+	fn, err := GetFunction(fnType)
+	if err != nil {
+		return err
 	}
+	expectedNoOfTokens := fn.NoOfReserveTokens()
 
 	// Check that number of reserve tokens is correct (if expecting a specific number of tokens)
 	if expectedNoOfTokens != AnyNumberOfReserveTokens && len(resTokens) != expectedNoOfTokens {
@@ -57,17 +58,17 @@ func CheckCoinDenom(denom string) (err error) {
 }
 
 func GetRequiredParamsForFunctionType(fnType string) (fnParams []string, err error) {
-	expectedParams, ok := RequiredParamsForFunctionType[fnType]
-	if !ok {
-		return nil, sdkerrors.Wrap(ErrUnrecognizedFunctionType, fnType)
+	fn, err := GetFunction(fnType)
+	if err != nil {
+		return nil, err
 	}
-	return expectedParams, nil
+	return fn.RequiredParams(), nil
 }
 
 func GetExceptionsForFunctionType(fnType string) (restrictions FunctionParamRestrictions, err error) {
-	restrictions, ok := ExtraParameterRestrictions[fnType]
-	if !ok {
-		return nil, sdkerrors.Wrap(ErrUnrecognizedFunctionType, fnType)
+	fn, err := GetFunction(fnType)
+	if err != nil {
+		return nil, err
 	}
-	return restrictions, nil
+	return fn.ValidateParams, nil
 }
@@ -75,6 +75,14 @@ func DivideDecCoinsByDec(dcs sdk.DecCoins, scale sdk.Dec) (scaled sdk.DecCoins)
 	return scaled
 }
 
+// ScaleCoinsByPercentage returns the coins multiplied by the percentage (from
+// 0 to 100), rounded down.
+func ScaleCoinsByPercentage(coins sdk.Coins, percentage sdk.Dec) sdk.Coins {
+	scaled, _ := MultiplyDecCoinsByDec(
+		sdk.NewDecCoinsFromCoins(coins...), percentage.QuoInt64(100)).TruncateDecimal()
+	return scaled
+}
+
 func AdjustFees(fees sdk.Coins, maxFees sdk.Coins) sdk.Coins {
 
 	// List of extra fees to deduct at the end
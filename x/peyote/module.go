@@ -106,9 +106,16 @@ func (am AppModule) NewQuerierHandler() sdk.Querier {
 	return NewQuerier(am.keeper)
 }
 
-func (am AppModule) BeginBlock(_ sdk.Context, _ abci.RequestBeginBlock) {}
+// BeginBlock clears the bond cache, which only holds the bonds read or written
+// in the current block.
+func (am AppModule) BeginBlock(_ sdk.Context, _ abci.RequestBeginBlock) {
+	am.keeper.ClearBondCache()
+}
 
+// EndBlock clears the bond cache once the EndBlocker has run, so that no bonds
+// are cached between blocks.
 func (am AppModule) EndBlock(ctx sdk.Context, _ abci.RequestEndBlock) []abci.ValidatorUpdate {
+	defer am.keeper.ClearBondCache()
 	return EndBlocker(ctx, am.keeper)
 }
 
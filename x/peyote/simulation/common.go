@@ -2,6 +2,7 @@ package simulation
 
 import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/warmage-sports/peyote/x/peyote/internal/types"
 )
 
 var (
@@ -9,13 +10,20 @@ var (
 
 	blankOrderQuantityLimits    = sdk.Coins{}
 	blankOutcomePayment         = sdk.Coins{}
+	blankOutcomeTranches        []types.OutcomeTranche
+	blankOutcomePayers          []sdk.AccAddress // any address can pay
+	blankEvaluators             []sdk.AccAddress // no attestations
 	blankSanityRate             = sdk.MustNewDecFromStr("0")
 	blankSanityMarginPercentage = sdk.MustNewDecFromStr("0")
+	blankRevealBlocks           = sdk.ZeroUint() // no commit-reveal orders
+	blankMaxBatchOrders         = sdk.ZeroUint() // module's limit
+	blankMaxBatchVolume         = sdk.ZeroUint() // no volume limit
+	blankClaimBlocks            = sdk.ZeroUint() // no claim deadline
 
 	tokenPrefix    = "token"
 	totalBondCount = 0 // Updated for each bond created
 	maxBondCount   = 0 // Set during genesis creation
 	gas            = uint64(100000000)
 
-	swapperBonds []string
+	liquidityPoolBonds []string
 )
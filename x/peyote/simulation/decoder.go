@@ -2,6 +2,7 @@ package simulation
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
 
 	tmkv "github.com/tendermint/tendermint/libs/kv"
@@ -19,6 +20,12 @@ func DecodeStore(cdc *codec.Codec, kvA, kvB tmkv.Pair) string {
 		cdc.MustUnmarshalBinaryBare(kvB.Value, &bondB)
 		return fmt.Sprintf("%v\n%v", bondA, bondB)
 
+	case bytes.Equal(kvA.Key[:1], types.BondAccountingKeyPrefix):
+		var accountingA, accountingB types.BondAccounting
+		cdc.MustUnmarshalBinaryBare(kvA.Value, &accountingA)
+		cdc.MustUnmarshalBinaryBare(kvB.Value, &accountingB)
+		return fmt.Sprintf("%v\n%v", accountingA, accountingB)
+
 	case bytes.Equal(kvA.Key[:1], types.BatchesKeyPrefix):
 		var batchA, batchB types.Batch
 		cdc.MustUnmarshalBinaryBare(kvA.Value, &batchA)
@@ -31,6 +38,102 @@ func DecodeStore(cdc *codec.Codec, kvA, kvB tmkv.Pair) string {
 		cdc.MustUnmarshalBinaryBare(kvB.Value, &batchB)
 		return fmt.Sprintf("%v\n%v", batchA, batchB)
 
+	case bytes.Equal(kvA.Key[:1], types.LimitOrdersKeyPrefix):
+		var orderA, orderB types.LimitOrder
+		cdc.MustUnmarshalBinaryBare(kvA.Value, &orderA)
+		cdc.MustUnmarshalBinaryBare(kvB.Value, &orderB)
+		return fmt.Sprintf("%v\n%v", orderA, orderB)
+
+	case bytes.Equal(kvA.Key[:1], types.OrderCommitmentsKeyPrefix):
+		var commitmentA, commitmentB types.OrderCommitment
+		cdc.MustUnmarshalBinaryBare(kvA.Value, &commitmentA)
+		cdc.MustUnmarshalBinaryBare(kvB.Value, &commitmentB)
+		return fmt.Sprintf("%v\n%v", commitmentA, commitmentB)
+
+	case bytes.Equal(kvA.Key[:1], types.BatchOrdersKeyPrefix),
+		bytes.Equal(kvA.Key[:1], types.RolledOverOrdersPrefix):
+		// The order type byte precedes the order ID at the end of the key of a
+		// batch order, and follows the order ID in that of a rolled-over order
+		orderType := kvA.Key[len(kvA.Key)-9]
+		if bytes.Equal(kvA.Key[:1], types.RolledOverOrdersPrefix) {
+			orderType = kvA.Key[len(kvA.Key)-1]
+		}
+		switch orderType {
+		case types.BatchBuyOrderByte:
+			var orderA, orderB types.BuyOrder
+			cdc.MustUnmarshalBinaryBare(kvA.Value, &orderA)
+			cdc.MustUnmarshalBinaryBare(kvB.Value, &orderB)
+			return fmt.Sprintf("%v\n%v", orderA, orderB)
+		case types.BatchSellOrderByte:
+			var orderA, orderB types.SellOrder
+			cdc.MustUnmarshalBinaryBare(kvA.Value, &orderA)
+			cdc.MustUnmarshalBinaryBare(kvB.Value, &orderB)
+			return fmt.Sprintf("%v\n%v", orderA, orderB)
+		case types.BatchSwapOrderByte:
+			var orderA, orderB types.SwapOrder
+			cdc.MustUnmarshalBinaryBare(kvA.Value, &orderA)
+			cdc.MustUnmarshalBinaryBare(kvB.Value, &orderB)
+			return fmt.Sprintf("%v\n%v", orderA, orderB)
+		default:
+			panic(fmt.Sprintf("invalid %s order key %X", types.ModuleName, kvA.Key))
+		}
+
+	case bytes.Equal(kvA.Key[:1], types.RecurringOrdersKeyPrefix):
+		var orderA, orderB types.RecurringOrder
+		cdc.MustUnmarshalBinaryBare(kvA.Value, &orderA)
+		cdc.MustUnmarshalBinaryBare(kvB.Value, &orderB)
+		return fmt.Sprintf("%v\n%v", orderA, orderB)
+
+	case bytes.Equal(kvA.Key[:1], types.BatchHistoryKeyPrefix):
+		var recordA, recordB types.BatchRecord
+		cdc.MustUnmarshalBinaryBare(kvA.Value, &recordA)
+		cdc.MustUnmarshalBinaryBare(kvB.Value, &recordB)
+		return fmt.Sprintf("%v\n%v", recordA, recordB)
+
+	case bytes.Equal(kvA.Key[:1], types.SettlementSnapshotsKeyPrefix):
+		var snapshotA, snapshotB types.SettlementSnapshot
+		cdc.MustUnmarshalBinaryBare(kvA.Value, &snapshotA)
+		cdc.MustUnmarshalBinaryBare(kvB.Value, &snapshotB)
+		return fmt.Sprintf("%v\n%v", snapshotA, snapshotB)
+
+	case bytes.Equal(kvA.Key[:1], types.HolderSnapshotsKeyPrefix),
+		bytes.Equal(kvA.Key[:1], types.TrancheHoldersKeyPrefix):
+		var holderA, holderB types.HolderSnapshot
+		cdc.MustUnmarshalBinaryBare(kvA.Value, &holderA)
+		cdc.MustUnmarshalBinaryBare(kvB.Value, &holderB)
+		return fmt.Sprintf("%v\n%v", holderA, holderB)
+
+	case bytes.Equal(kvA.Key[:1], types.ShareDistributionsKeyPrefix):
+		var distributionA, distributionB types.ShareDistribution
+		cdc.MustUnmarshalBinaryBare(kvA.Value, &distributionA)
+		cdc.MustUnmarshalBinaryBare(kvB.Value, &distributionB)
+		return fmt.Sprintf("%v\n%v", distributionA, distributionB)
+
+	case bytes.Equal(kvA.Key[:1], types.TrancheDistributionsKeyPrefix):
+		var distributionA, distributionB types.TrancheDistribution
+		cdc.MustUnmarshalBinaryBare(kvA.Value, &distributionA)
+		cdc.MustUnmarshalBinaryBare(kvB.Value, &distributionB)
+		return fmt.Sprintf("%v\n%v", distributionA, distributionB)
+
+	case bytes.Equal(kvA.Key[:1], types.BatchQueueKeyPrefix),
+		bytes.Equal(kvA.Key[:1], types.BatchHistoryHeightsPrefix),
+		bytes.Equal(kvA.Key[:1], types.ClaimDeadlinesKeyPrefix),
+		bytes.Equal(kvA.Key[:1], types.HoldersKeyPrefix),
+		bytes.Equal(kvA.Key[:1], types.RecordingSnapshotsKeyPrefix):
+		return fmt.Sprintf("%s\n%s", kvA.Value, kvB.Value)
+
+	case bytes.Equal(kvA.Key[:1], types.LimitOrderBookKeyPrefix),
+		bytes.Equal(kvA.Key[:1], types.LimitOrderExpiryKeyPrefix),
+		bytes.Equal(kvA.Key[:1], types.LastOrderIdKey),
+		bytes.Equal(kvA.Key[:1], types.RecurringOrderOwnersKeyPrefix),
+		bytes.Equal(kvA.Key[:1], types.RecurringOrderBondsKeyPrefix),
+		bytes.Equal(kvA.Key[:1], types.RecurringOrderEndsKeyPrefix),
+		bytes.Equal(kvA.Key[:1], types.LastRecurringOrderIdKey),
+		bytes.Equal(kvA.Key[:1], types.RecurringOrderCountsKeyPrefix):
+		idA := binary.BigEndian.Uint64(kvA.Value)
+		idB := binary.BigEndian.Uint64(kvB.Value)
+		return fmt.Sprintf("%d\n%d", idA, idB)
+
 	default:
 		panic(fmt.Sprintf("invalid %s key prefix %X", types.ModuleName, kvA.Key[:1]))
 	}
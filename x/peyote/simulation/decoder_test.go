@@ -58,17 +58,84 @@ func TestDecodeStore(t *testing.T) {
 	bond := types.NewBond(token, name, description, creator, functionType,
 		functionParameters, reserveTokens, txFeePercentage, exitFeePercentage,
 		feeAddress, maxSupply, orderQuantityLimits, sanityRate, sanityMarginPercentage,
-		allowSell, signers, batchBlocks, outcomePayment, state)
-	batch := types.NewBatch(bond.Token, bond.BatchBlocks)
-	lastBatch := types.NewBatch(bond.Token, bond.BatchBlocks)
+		allowSell, signers, batchBlocks, sdk.ZeroUint(), false, sdk.ZeroUint(),
+		sdk.ZeroUint(), false, outcomePayment, nil, nil, nil, false, sdk.ZeroUint(), false,
+		state)
+	batch := types.NewBatch(bond.Token, int64(bond.BatchBlocks.Uint64()),
+		bond.BatchBlocks, bond.RevealBlocks)
+	lastBatch := types.NewBatch(bond.Token, int64(bond.BatchBlocks.Uint64()),
+		bond.BatchBlocks, bond.RevealBlocks)
+	limitOrder := types.NewLimitOrder(types.LimitBuyOrderType, creator,
+		sdk.NewInt64Coin(token, 10), sdk.NewDec(5),
+		sdk.NewCoins(sdk.NewInt64Coin("reservetoken", 55)), 100)
+	limitOrder.Id = 7
+	commitment := types.NewOrderCommitment(token, creator, make([]byte, 32),
+		sdk.NewCoins(sdk.NewInt64Coin("reservetoken", 1)))
+	commitment.Id = 8
+	buyOrder := types.NewBuyOrder(creator, sdk.NewInt64Coin(token, 10),
+		sdk.NewCoins(sdk.NewInt64Coin("reservetoken", 100)))
+	buyOrder.Id = 9
+	sellOrder := types.NewSellOrder(creator, sdk.NewInt64Coin(token, 10), nil)
+	sellOrder.Id = 10
+	swapOrder := types.NewSwapOrder(creator, sdk.NewInt64Coin("reservetoken", 10), "reservetoken2", nil)
+	swapOrder.Id = 11
+	snapshot := types.NewSettlementSnapshot(token, 50)
+	holder := types.NewHolderSnapshot(creator, sdk.NewInt(10))
+	distribution := types.NewShareDistribution(token)
+	trancheDistribution := types.NewTrancheDistribution(token, 1,
+		sdk.NewCoins(sdk.NewInt64Coin("reservetoken", 100)))
 
 	kvPairs := tmkv.Pairs{
 		tmkv.Pair{Key: types.GetBondKey(token),
 			Value: cdc.MustMarshalBinaryBare(bond)},
+		tmkv.Pair{Key: types.GetBondAccountingKey(token),
+			Value: cdc.MustMarshalBinaryBare(bond.Accounting())},
 		tmkv.Pair{Key: types.GetBatchKey(token),
 			Value: cdc.MustMarshalBinaryBare(batch)},
 		tmkv.Pair{Key: types.GetLastBatchKey(token),
 			Value: cdc.MustMarshalBinaryBare(lastBatch)},
+		tmkv.Pair{Key: types.GetLimitOrderKey(limitOrder.Id),
+			Value: cdc.MustMarshalBinaryBare(limitOrder)},
+		tmkv.Pair{Key: types.GetLimitOrderBookKey(limitOrder),
+			Value: sdk.Uint64ToBigEndian(limitOrder.Id)},
+		tmkv.Pair{Key: types.GetLimitOrderExpiryKey(limitOrder),
+			Value: sdk.Uint64ToBigEndian(limitOrder.Id)},
+		tmkv.Pair{Key: types.LastOrderIdKey,
+			Value: sdk.Uint64ToBigEndian(3)},
+		tmkv.Pair{Key: types.GetOrderCommitmentKey(token, commitment.Id),
+			Value: cdc.MustMarshalBinaryBare(commitment)},
+		tmkv.Pair{Key: types.GetBatchOrderKey(token, types.BatchBuyOrderByte, buyOrder.Id),
+			Value: cdc.MustMarshalBinaryBare(buyOrder)},
+		tmkv.Pair{Key: types.GetBatchOrderKey(token, types.BatchSellOrderByte, sellOrder.Id),
+			Value: cdc.MustMarshalBinaryBare(sellOrder)},
+		tmkv.Pair{Key: types.GetBatchOrderKey(token, types.BatchSwapOrderByte, swapOrder.Id),
+			Value: cdc.MustMarshalBinaryBare(swapOrder)},
+		tmkv.Pair{Key: types.GetBatchQueueKey(batch.ExecutionHeight, token),
+			Value: []byte(token)},
+		tmkv.Pair{Key: types.GetBatchRecordKey(token, batch.ExecutionHeight),
+			Value: cdc.MustMarshalBinaryBare(types.NewBatchRecord(batch))},
+		tmkv.Pair{Key: types.GetBatchHistoryHeightKey(token, batch.ExecutionHeight),
+			Value: []byte(token)},
+		tmkv.Pair{Key: types.GetRolledOverOrderKey(token, buyOrder.Id, types.BatchBuyOrderByte),
+			Value: cdc.MustMarshalBinaryBare(buyOrder)},
+		tmkv.Pair{Key: types.GetRolledOverOrderKey(token, swapOrder.Id, types.BatchSwapOrderByte),
+			Value: cdc.MustMarshalBinaryBare(swapOrder)},
+		tmkv.Pair{Key: types.GetSettlementSnapshotKey(token),
+			Value: cdc.MustMarshalBinaryBare(snapshot)},
+		tmkv.Pair{Key: types.GetHolderSnapshotKey(token, creator),
+			Value: cdc.MustMarshalBinaryBare(holder)},
+		tmkv.Pair{Key: types.GetShareDistributionKey(token),
+			Value: cdc.MustMarshalBinaryBare(distribution)},
+		tmkv.Pair{Key: types.GetClaimDeadlineKey(60, token),
+			Value: []byte(token)},
+		tmkv.Pair{Key: types.GetTrancheDistributionKey(token, 1),
+			Value: cdc.MustMarshalBinaryBare(trancheDistribution)},
+		tmkv.Pair{Key: types.GetTrancheHolderKey(token, 1, creator),
+			Value: cdc.MustMarshalBinaryBare(holder)},
+		tmkv.Pair{Key: types.GetRecordingSnapshotKey(token),
+			Value: []byte(token)},
+		tmkv.Pair{Key: types.GetRecurringOrderCountKey(token),
+			Value: sdk.Uint64ToBigEndian(2)},
 		tmkv.Pair{Key: []byte{0x99}, Value: []byte{0x99}},
 	}
 
@@ -77,8 +144,30 @@ func TestDecodeStore(t *testing.T) {
 		expectedLog string
 	}{
 		{"peyote", fmt.Sprintf("%v\n%v", bond, bond)},
+		{"bondAccounting", fmt.Sprintf("%v\n%v", bond.Accounting(), bond.Accounting())},
 		{"batches", fmt.Sprintf("%v\n%v", batch, batch)},
 		{"lastBatches", fmt.Sprintf("%v\n%v", lastBatch, lastBatch)},
+		{"limitOrders", fmt.Sprintf("%v\n%v", limitOrder, limitOrder)},
+		{"limitOrderBook", "7\n7"},
+		{"limitOrderExpiries", "7\n7"},
+		{"lastOrderId", "3\n3"},
+		{"orderCommitments", fmt.Sprintf("%v\n%v", commitment, commitment)},
+		{"batchBuyOrders", fmt.Sprintf("%v\n%v", buyOrder, buyOrder)},
+		{"batchSellOrders", fmt.Sprintf("%v\n%v", sellOrder, sellOrder)},
+		{"batchSwapOrders", fmt.Sprintf("%v\n%v", swapOrder, swapOrder)},
+		{"batchQueue", fmt.Sprintf("%s\n%s", token, token)},
+		{"batchHistory", fmt.Sprintf("%v\n%v", types.NewBatchRecord(batch), types.NewBatchRecord(batch))},
+		{"batchHistoryHeights", fmt.Sprintf("%s\n%s", token, token)},
+		{"rolledOverBuyOrders", fmt.Sprintf("%v\n%v", buyOrder, buyOrder)},
+		{"rolledOverSwapOrders", fmt.Sprintf("%v\n%v", swapOrder, swapOrder)},
+		{"settlementSnapshots", fmt.Sprintf("%v\n%v", snapshot, snapshot)},
+		{"holderSnapshots", fmt.Sprintf("%v\n%v", holder, holder)},
+		{"shareDistributions", fmt.Sprintf("%v\n%v", distribution, distribution)},
+		{"claimDeadlines", fmt.Sprintf("%s\n%s", token, token)},
+		{"trancheDistributions", fmt.Sprintf("%v\n%v", trancheDistribution, trancheDistribution)},
+		{"trancheHolders", fmt.Sprintf("%v\n%v", holder, holder)},
+		{"recordingSnapshots", fmt.Sprintf("%s\n%s", token, token)},
+		{"recurringOrderCounts", "2\n2"},
 		{"other", ""},
 	}
 
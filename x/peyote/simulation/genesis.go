@@ -64,18 +64,10 @@ func RandomizedGenState(simState *module.SimulationState) {
 
 		functionType := getRandomFunctionType(r)
 
-		var reserveTokens []string
-		switch functionType {
-		case types.SwapperFunction:
-			reserveToken1, ok1 := getRandomBondName(r)
-			reserveToken2, ok2 := getRandomBondNameExcept(r, reserveToken1)
-			if !ok1 || !ok2 {
-				initialBonds -= 1 // Ignore this iteration
-				continue
-			}
-			reserveTokens = []string{reserveToken1, reserveToken2}
-		default:
-			reserveTokens = defaultReserveTokens
+		reserveTokens, ok := getRandomReserveTokens(r, types.MustGetFunction(functionType))
+		if !ok {
+			initialBonds -= 1 // Ignore this iteration
+			continue
 		}
 		functionParameters := getRandomFunctionParameters(r, functionType, true)
 
@@ -99,19 +91,30 @@ func RandomizedGenState(simState *module.SimulationState) {
 			functionParameters, reserveTokens, txFeePercentage,
 			exitFeePercentage, feeAddress, maxSupply, blankOrderQuantityLimits,
 			blankSanityRate, blankSanityMarginPercentage, allowSells, signers,
-			batchBlocks, outcomePayment, state)
-		batch := types.NewBatch(bond.Token, bond.BatchBlocks)
+			batchBlocks, blankRevealBlocks, false, blankMaxBatchOrders,
+			blankMaxBatchVolume, false, outcomePayment, blankOutcomeTranches,
+			blankOutcomePayers, blankEvaluators, false, blankClaimBlocks, false,
+			state)
+		if bond.ValidateCurve() != nil {
+			initialBonds -= 1 // Ignore this iteration
+			continue
+		}
+		batch := types.NewBatch(bond.Token, int64(bond.BatchBlocks.Uint64()),
+			bond.BatchBlocks, bond.RevealBlocks)
 
 		peyote = append(peyote, bond)
 		batches = append(batches, batch)
 		incrementBondCount()
-		if bond.FunctionType == types.SwapperFunction {
-			newSwapperBond(bond.Token)
+		if types.MustGetFunction(bond.FunctionType).IsLiquidityPool() {
+			newLiquidityPoolBond(bond.Token)
 		}
 	}
 
-	peyoteGenesis := types.NewGenesisState(peyote, batches,
-		types.Params{ReservedBondTokens: defaultReserveTokens})
+	peyoteGenesis := types.NewGenesisState(peyote, batches, nil, nil, nil, nil, nil, nil, nil, nil,
+		types.NewParams(defaultReserveTokens, types.DefaultBatchHistoryRetention,
+			types.DefaultMaxBatchOrders, types.DefaultMaxBatchVolume, types.DefaultMaxDistributions, false,
+			types.DefaultMaxLimitOrderMatches, types.DefaultMinLimitOrderAmount, types.DefaultMaxRecurringOrders,
+			types.DefaultMaxLimitOrderExpiry, sdk.Coins{}))
 
 	fmt.Printf("Selected randomly generated peyote genesis state:\n%s\n", codec.MustMarshalJSONIndent(simState.Cdc, peyoteGenesis))
 	simState.GenState[types.ModuleName] = simState.Cdc.MustMarshalJSON(peyoteGenesis)
@@ -116,17 +116,9 @@ func SimulateMsgCreateBond(ak auth.AccountKeeper) simulation.Operation {
 
 		functionType := getRandomFunctionType(r)
 
-		var reserveTokens []string
-		switch functionType {
-		case types.SwapperFunction:
-			reserveToken1, ok1 := getRandomBondName(r)
-			reserveToken2, ok2 := getRandomBondNameExcept(r, reserveToken1)
-			if !ok1 || !ok2 {
-				return simulation.NoOpMsg(types.ModuleName), nil, nil
-			}
-			reserveTokens = []string{reserveToken1, reserveToken2}
-		default:
-			reserveTokens = defaultReserveTokens
+		reserveTokens, ok := getRandomReserveTokens(r, types.MustGetFunction(functionType))
+		if !ok {
+			return simulation.NoOpMsg(types.ModuleName), nil, nil
 		}
 		functionParameters := getRandomFunctionParameters(r, functionType, false)
 
@@ -153,10 +145,18 @@ func SimulateMsgCreateBond(ak auth.AccountKeeper) simulation.Operation {
 		batchBlocks := sdk.NewUint(uint64(
 			simulation.RandIntBetween(r, 1, 10)))
 
+		// Skip bonds whose curve cannot be evaluated up to the max supply
+		if !curveIsValid(token, functionType, functionParameters, reserveTokens, maxSupply) {
+			return simulation.NoOpMsg(types.ModuleName), nil, nil
+		}
+
 		msg := types.NewMsgCreateBond(token, name, desc, creator, functionType,
 			functionParameters, reserveTokens, txFeePercentage, exitFeePercentage,
 			feeAddress, maxSupply, blankOrderQuantityLimits, blankSanityRate,
-			blankSanityMarginPercentage, allowSells, signers, batchBlocks, blankOutcomePayment)
+			blankSanityMarginPercentage, allowSells, signers, batchBlocks,
+			blankRevealBlocks, false, blankMaxBatchOrders, blankMaxBatchVolume,
+			false, blankOutcomePayment, blankOutcomeTranches, blankOutcomePayers,
+			blankEvaluators, false, blankClaimBlocks, false)
 		if msg.ValidateBasic() != nil {
 			return simulation.NoOpMsg(types.ModuleName), nil,
 				fmt.Errorf("expected msg to pass ValidateBasic: %s", msg.GetSignBytes())
@@ -178,8 +178,8 @@ func SimulateMsgCreateBond(ak auth.AccountKeeper) simulation.Operation {
 		}
 
 		incrementBondCount() // since successfully created
-		if msg.FunctionType == types.SwapperFunction {
-			newSwapperBond(msg.Token)
+		if types.MustGetFunction(msg.FunctionType).IsLiquidityPool() {
+			newLiquidityPoolBond(msg.Token)
 		}
 		return simulation.NewOperationMsg(msg, true, ""), nil, nil
 	}
@@ -236,26 +236,21 @@ func SimulateMsgEditBond(ak auth.AccountKeeper, k keeper.Keeper) simulation.Oper
 	}
 }
 
-func getBuyIntoSwapper(r *rand.Rand, ctx sdk.Context, k keeper.Keeper,
+func getBuyIntoLiquidityPool(r *rand.Rand, ctx sdk.Context, k keeper.Keeper,
 	bond types.Bond, account exported.Account) (msg types.MsgBuy, err error, ok bool) {
 	address := account.GetAddress()
 	spendable := account.SpendableCoins(ctx.BlockTime())
 
 	// Come up with max prices based on what is spendable
-	spendableReserve1 := spendable.AmountOf(bond.ReserveTokens[0])
-	spendableReserve2 := spendable.AmountOf(bond.ReserveTokens[1])
-	maxPriceInt1, err := simulation.RandPositiveInt(r, spendableReserve1)
-	if err != nil {
-		return types.MsgBuy{}, err, false
-	}
-	maxPriceInt2, err := simulation.RandPositiveInt(r, spendableReserve2)
-	if err != nil {
-		return types.MsgBuy{}, err, false
+	var maxPrices sdk.Coins
+	for _, reserveToken := range bond.ReserveTokens {
+		spendableReserve := spendable.AmountOf(reserveToken)
+		maxPriceInt, err := simulation.RandPositiveInt(r, spendableReserve)
+		if err != nil {
+			return types.MsgBuy{}, err, false
+		}
+		maxPrices = maxPrices.Add(sdk.NewCoin(reserveToken, maxPriceInt))
 	}
-	maxPrices := sdk.NewCoins(
-		sdk.NewCoin(bond.ReserveTokens[0], maxPriceInt1),
-		sdk.NewCoin(bond.ReserveTokens[1], maxPriceInt2),
-	)
 
 	// Get lesser of max possible increase in supply and max order quantity
 	var maxBuyAmount sdk.Int
@@ -286,10 +281,10 @@ func getBuyIntoSwapper(r *rand.Rand, ctx sdk.Context, k keeper.Keeper,
 		}
 	}
 
-	return types.NewMsgBuy(address, amountToBuy, maxPrices), nil, true
+	return types.NewMsgBuy(address, amountToBuy, maxPrices, nil), nil, true
 }
 
-func getBuyIntoNonSwapper(r *rand.Rand, ctx sdk.Context, k keeper.Keeper,
+func getBuyIntoNonLiquidityPool(r *rand.Rand, ctx sdk.Context, k keeper.Keeper,
 	bond types.Bond, account exported.Account) (msg types.MsgBuy, err error, ok bool) {
 	address := account.GetAddress()
 	spendable := account.SpendableCoins(ctx.BlockTime())
@@ -323,8 +318,9 @@ func getBuyIntoNonSwapper(r *rand.Rand, ctx sdk.Context, k keeper.Keeper,
 	//
 	// If not an augmented function in hatch state, just pick a random amount.
 	var toBuyInt sdk.Int
-	if bond.FunctionType == types.AugmentedFunction && bond.State == types.HatchState {
-		S0 := bond.FunctionParameters.AsMap()["S0"].Ceil().TruncateInt()
+	hatchFn, isHatchFn := types.GetHatchFunction(bond.FunctionType)
+	if isHatchFn && bond.State == types.HatchState {
+		S0 := hatchFn.HatchSupply(bond).Ceil().TruncateInt()
 		remainingForS0 := S0.Sub(bond.CurrentSupply.Amount)
 		if remainingForS0.LTE(maxBuyAmount) && simulation.RandIntBetween(r, 1, 2) == 1 {
 			toBuyInt = remainingForS0
@@ -351,7 +347,7 @@ func getBuyIntoNonSwapper(r *rand.Rand, ctx sdk.Context, k keeper.Keeper,
 		return types.MsgBuy{}, err, true
 	}
 
-	return types.NewMsgBuy(address, toBuy, maxPrices), nil, true
+	return types.NewMsgBuy(address, toBuy, maxPrices, nil), nil, true
 }
 
 func SimulateMsgBuy(ak auth.AccountKeeper, k keeper.Keeper) simulation.Operation {
@@ -386,10 +382,10 @@ func SimulateMsgBuy(ak auth.AccountKeeper, k keeper.Keeper) simulation.Operation
 		account := ak.GetAccount(ctx, simAccount.Address)
 
 		var msg types.MsgBuy
-		if bond.FunctionType == types.SwapperFunction {
-			msg, err, ok = getBuyIntoSwapper(r, ctx, k, bond, account)
+		if types.MustGetFunction(bond.FunctionType).IsLiquidityPool() {
+			msg, err, ok = getBuyIntoLiquidityPool(r, ctx, k, bond, account)
 		} else {
-			msg, err, ok = getBuyIntoNonSwapper(r, ctx, k, bond, account)
+			msg, err, ok = getBuyIntoNonLiquidityPool(r, ctx, k, bond, account)
 		}
 
 		// If ok, err is not something that should stop the simulation
@@ -460,7 +456,7 @@ func SimulateMsgSell(ak auth.AccountKeeper, k keeper.Keeper) simulation.Operatio
 		}
 		amountToSell := sdk.NewCoin(bond.Token, toSellInt)
 
-		msg := types.NewMsgSell(address, amountToSell)
+		msg := types.NewMsgSell(address, amountToSell, nil, nil)
 		if msg.ValidateBasic() != nil {
 			return simulation.NoOpMsg(types.ModuleName), nil, fmt.Errorf("expected msg to pass ValidateBasic: %s", msg.GetSignBytes())
 		}
@@ -488,9 +484,9 @@ func SimulateMsgSwap(ak auth.AccountKeeper, k keeper.Keeper) simulation.Operatio
 	return func(r *rand.Rand, app *baseapp.BaseApp, ctx sdk.Context,
 		accs []simulation.Account, chainID string) (opMsg simulation.OperationMsg, fOps []simulation.FutureOperation, err error) {
 
-		// Get swapper function peyote with some reserve
+		// Get liquidity pool bonds with some reserve
 		var filteredBonds []string
-		for _, sbToken := range swapperBonds {
+		for _, sbToken := range liquidityPoolBonds {
 			if !k.GetReserveBalances(ctx, sbToken).IsZero() {
 				filteredBonds = append(filteredBonds, sbToken)
 			}
@@ -504,8 +500,9 @@ func SimulateMsgSwap(ak auth.AccountKeeper, k keeper.Keeper) simulation.Operatio
 		token := filteredBonds[simulation.RandIntBetween(r, 0, len(filteredBonds))]
 		bond := k.MustGetBond(ctx, token)
 
-		fromIndex := simulation.RandIntBetween(r, 0, 1)
-		toIndex := 1 - fromIndex
+		noOfReserveTokens := len(bond.ReserveTokens)
+		fromIndex := simulation.RandIntBetween(r, 0, noOfReserveTokens)
+		toIndex := (fromIndex + simulation.RandIntBetween(r, 1, noOfReserveTokens)) % noOfReserveTokens
 
 		fromToken := bond.ReserveTokens[fromIndex]
 		toToken := bond.ReserveTokens[toIndex]
@@ -534,7 +531,7 @@ func SimulateMsgSwap(ak auth.AccountKeeper, k keeper.Keeper) simulation.Operatio
 		}
 		amountToSwap := sdk.NewCoin(fromToken, toSwapInt)
 
-		msg := types.NewMsgSwap(address, token, amountToSwap, toToken)
+		msg := types.NewMsgSwap(address, token, amountToSwap, toToken, nil, nil)
 		if msg.ValidateBasic() != nil {
 			return simulation.NoOpMsg(types.ModuleName), nil, fmt.Errorf("expected msg to pass ValidateBasic: %s", msg.GetSignBytes())
 		}
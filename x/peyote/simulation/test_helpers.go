@@ -16,8 +16,8 @@ func incrementBondCount() {
 	totalBondCount += 1
 }
 
-func newSwapperBond(token string) {
-	swapperBonds = append(swapperBonds, token)
+func newLiquidityPoolBond(token string) {
+	liquidityPoolBonds = append(liquidityPoolBonds, token)
 }
 
 func getRandomBondName(r *rand.Rand) (bondName string, ok bool) {
@@ -31,24 +31,25 @@ func getRandomBondName(r *rand.Rand) (bondName string, ok bool) {
 	}
 }
 
-func getRandomBondNameExcept(r *rand.Rand, except string) (bondName string, ok bool) {
-	if totalBondCount == 0 {
-		return "", false
-	} else if totalBondCount == 1 {
-		token1 := tokenPrefix + "1"
-		if except == token1 {
-			return "", false // except string is the only token that exists
-		} else {
-			return tokenPrefix + "1", true // except string is not a token
-		}
-	} else {
-		token := except
-		for token == except {
-			randInt := simulation.RandIntBetween(r, 1, totalBondCount+1)
-			token = tokenPrefix + strconv.Itoa(randInt)
+func getRandomReserveTokens(r *rand.Rand, fn types.BondingFunction) (reserveTokens []string, ok bool) {
+	noOfReserveTokens := fn.NoOfReserveTokens()
+	if noOfReserveTokens == types.AnyNumberOfReserveTokens {
+		return defaultReserveTokens, true
+	} else if noOfReserveTokens > totalBondCount {
+		return nil, false // not enough bonds to use as reserve tokens
+	}
+
+	// Pick distinct bond tokens as the reserve tokens
+	picked := make(map[string]bool)
+	for len(reserveTokens) < noOfReserveTokens {
+		randInt := simulation.RandIntBetween(r, 1, totalBondCount+1)
+		token := tokenPrefix + strconv.Itoa(randInt)
+		if !picked[token] {
+			picked[token] = true
+			reserveTokens = append(reserveTokens, token)
 		}
-		return token, true
 	}
+	return reserveTokens, true
 }
 
 func getRandomNonEmptyString(r *rand.Rand) string {
@@ -56,66 +57,48 @@ func getRandomNonEmptyString(r *rand.Rand) string {
 }
 
 func getRandomFunctionType(r *rand.Rand) string {
-	switch simulation.RandIntBetween(r, 0, 4) {
-	case 0:
-		return types.PowerFunction
-	case 1:
-		return types.SigmoidFunction
-	case 2:
-		return types.SwapperFunction
-	case 3:
-		return types.AugmentedFunction
-	default:
-		panic("function type integer out of bounds")
-	}
+	functionTypes := types.FunctionTypes()
+	return functionTypes[simulation.RandIntBetween(r, 0, len(functionTypes))]
 }
 
 func getRandomFunctionParameters(r *rand.Rand, functionType string, genesis bool) types.FunctionParams {
-	switch functionType {
-	case types.PowerFunction:
-		m := simulation.RandIntBetween(r, 1, 100)
-		n := simulation.RandIntBetween(r, 1, 5)
-		c := simulation.RandIntBetween(r, 1, 1000)
-		return types.FunctionParams{
-			types.NewFunctionParam("m", sdk.NewDec(int64(m))),
-			types.NewFunctionParam("n", sdk.NewDec(int64(n))),
-			types.NewFunctionParam("c", sdk.NewDec(int64(c)))}
-	case types.SigmoidFunction:
-		a := simulation.RandIntBetween(r, 1, 10)
-		b := simulation.RandIntBetween(r, 1, 10)
-		c := simulation.RandIntBetween(r, 1, 10)
-		return types.FunctionParams{
-			types.NewFunctionParam("a", sdk.NewDec(int64(a))),
-			types.NewFunctionParam("b", sdk.NewDec(int64(b))),
-			types.NewFunctionParam("c", sdk.NewDec(int64(c)))}
-	case types.AugmentedFunction:
-		d0 := sdk.NewDec(int64(simulation.RandIntBetween(r, 1, 1000000)))
-		p0 := simulation.RandomDecAmount(r, sdk.NewDec(10)).Add(sdk.SmallestDec())
-		theta := simulation.RandomDecAmount(r, sdk.MustNewDecFromStr("0.9")).Add(sdk.SmallestDec())
-		kappa := sdk.NewDec(int64(simulation.RandIntBetween(r, 1, 4)))
-		functionParams := types.FunctionParams{
-			types.NewFunctionParam("d0", d0),
-			types.NewFunctionParam("p0", p0),
-			types.NewFunctionParam("theta", theta),
-			types.NewFunctionParam("kappa", kappa)}
-		if genesis {
-			R0 := d0.Mul(sdk.OneDec().Sub(theta))
-			S0 := d0.Quo(p0)
-			V0 := types.Invariant(R0, S0, kappa.TruncateInt64())
-
-			functionParams = append(functionParams,
-				types.FunctionParams{
-					types.NewFunctionParam("R0", R0),
-					types.NewFunctionParam("S0", S0),
-					types.NewFunctionParam("V0", V0),
-				}...)
+	fn := types.MustGetFunction(functionType)
+	functionParams := fn.RandomParams(r)
+
+	// Bonds created at genesis do not go through the handler, so any hatch
+	// parameters have to be added here
+	if hatchFn, ok := fn.(types.HatchFunction); ok && genesis {
+		hatchParams, err := hatchFn.HatchParams(functionParams.AsMap())
+		if err != nil {
+			panic(err)
 		}
-		return functionParams
-	case types.SwapperFunction:
-		return nil
-	default:
-		panic("unrecognized function type")
+		functionParams = append(functionParams, hatchParams...)
+	}
+	return functionParams
+}
+
+// curveIsValid checks that the curve of a bond with the specified function can
+// be evaluated up to the max supply, since the bond is otherwise rejected
+func curveIsValid(token, functionType string, functionParams types.FunctionParams,
+	reserveTokens []string, maxSupply sdk.Coin) bool {
+	if hatchFn, ok := types.GetHatchFunction(functionType); ok {
+		hatchParams, err := hatchFn.HatchParams(functionParams.AsMap())
+		if err != nil {
+			return false
+		}
+		functionParams = append(functionParams, hatchParams...)
+	}
+
+	bond := types.Bond{
+		Token:              token,
+		FunctionType:       functionType,
+		FunctionParameters: functionParams,
+		ReserveTokens:      reserveTokens,
+		MaxSupply:          maxSupply,
+		CurrentSupply:      sdk.NewCoin(token, sdk.ZeroInt()),
+		State:              getInitialBondState(functionType),
 	}
+	return bond.ValidateCurve() == nil
 }
 
 func getRandomAllowSellsValue(r *rand.Rand) bool {
@@ -127,12 +110,10 @@ func getRandomAllowSellsValue(r *rand.Rand) bool {
 }
 
 func getInitialBondState(functionType string) string {
-	switch functionType {
-	case types.AugmentedFunction:
+	if _, ok := types.GetHatchFunction(functionType); ok {
 		return types.HatchState
-	default:
-		return types.OpenState
 	}
+	return types.OpenState
 }
 
 //noinspection GoNilness